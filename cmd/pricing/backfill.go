@@ -0,0 +1,146 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// backfillAttempts bounds the tries per token while the upstream is rate
+// limiting
+const backfillAttempts = 5
+
+// backfillResult is the outcome of backfilling one token
+type backfillResult struct {
+	Token  string `json:"token"`
+	Status string `json:"status"`         // "stored", "skipped" or "failed"
+	Days   int    `json:"days,omitempty"` // days stored
+	Error  string `json:"error,omitempty"`
+}
+
+// runBackfill imports daily history of tokens into the history store.
+// Tokens the store already covers are skipped, so an interrupted run
+// resumes where it stopped.
+func runBackfill(args []string) error {
+	cf := newCLIFlags("backfill")
+	tokens := cf.fs.String("tokens", "", "comma-separated token ids to import")
+	days := cf.fs.Int("days", history.MaxDays, fmt.Sprintf("days of history to import (max %d)", history.MaxDays))
+	currency := cf.fs.String("currency", "usd", "quote currency")
+	pace := cf.fs.Duration("pace", 2*time.Second, "pause between upstream requests")
+	force := cf.fs.Bool("force", false, "refetch tokens already stored")
+	_, engine, err := cf.parse(args)
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	var ids []string
+	for _, id := range strings.Split(*tokens, ",") {
+		if id = strings.ToLower(strings.TrimSpace(id)); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return errors.New("usage: pricing backfill -tokens bitcoin,ethereum [-days 365] [-currency usd]")
+	}
+	if *days < 1 || *days > history.MaxDays {
+		return fmt.Errorf("days must be between 1 and %d", history.MaxDays)
+	}
+	hist := engine.History()
+	if hist.Store() == nil {
+		return errors.New("HISTORY_DIR is not set; backfill imports into the history store")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx = providers.WithPriority(ctx, providers.PriorityBackfill)
+
+	results := make([]backfillResult, 0, len(ids))
+	var failed int
+	fetched := false
+	for i, id := range ids {
+		res := backfillResult{Token: id}
+		if !*force && hist.Store().Covers(id, *currency, *days) {
+			res.Status = "skipped"
+		} else {
+			if fetched && !sleepCtx(ctx, *pace) {
+				break
+			}
+			fetched = true
+			res.Days, err = backfillToken(ctx, hist, id, *currency, *days, *cf.timeout)
+			if ctx.Err() != nil {
+				break
+			}
+			res.Status = "stored"
+			if err != nil {
+				res.Status, res.Error = "failed", err.Error()
+				failed++
+			}
+		}
+		results = append(results, res)
+		if !*cf.json {
+			fmt.Printf("[%d/%d] %s: %s", i+1, len(ids), id, res.Status)
+			switch {
+			case res.Error != "":
+				fmt.Printf(": %s", res.Error)
+			case res.Days > 0:
+				fmt.Printf(" (%d days)", res.Days)
+			}
+			fmt.Println()
+		}
+	}
+
+	if *cf.json {
+		if err := printJSON(os.Stdout, results); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return errors.New("interrupted; run again to resume")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tokens failed; run again to retry them", failed, len(ids))
+	}
+	return nil
+}
+
+// backfillToken stores one token's history, waiting out rate limiting
+func backfillToken(ctx context.Context, hist *history.Service, id, currency string, days int, timeout time.Duration) (int, error) {
+	for attempt := 1; ; attempt++ {
+		reqCtx, cancel := context.WithTimeout(ctx, timeout)
+		n, err := hist.Backfill(reqCtx, id, currency, days)
+		cancel()
+
+		var limited *providers.RateLimitError
+		if !errors.As(err, &limited) || attempt == backfillAttempts {
+			return n, err
+		}
+		wait := max(time.Until(limited.Until), time.Second)
+		fmt.Fprintf(os.Stderr, "Rate limited; waiting %s before retrying %s\n", wait.Round(time.Second), id)
+		if !sleepCtx(ctx, wait) {
+			return 0, ctx.Err()
+		}
+	}
+}
+
+// sleepCtx waits for d, returning false if ctx is done first
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/api"
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// BenchConfig configures the load-test harness
+type BenchConfig struct {
+	Duration        time.Duration
+	Concurrency     int
+	MixFile         string
+	CacheTTL        time.Duration
+	UpstreamLatency time.Duration
+}
+
+// benchRequest is one entry in a weighted request mix
+type benchRequest struct {
+	weight int
+	path   string
+}
+
+// defaultBenchMix approximates production traffic: mostly single lookups
+// of popular tokens, some batch and CoinGecko-compatible requests
+var defaultBenchMix = []benchRequest{
+	{40, "/v1/price/bitcoin"},
+	{20, "/v1/price/ethereum"},
+	{10, "/v1/price/lux-network"},
+	{5, "/v1/price/solana?currency=eur"},
+	{10, "/v1/prices?ids=bitcoin,ethereum,solana,lux-network&currency=usd"},
+	{5, "/v1/prices?ids=bitcoin,ethereum,tether,usd-coin,binancecoin,ripple,cardano,dogecoin"},
+	{10, "/v1/simple/price?ids=bitcoin,ethereum&vs_currencies=usd,eur,jpy"},
+}
+
+// loadBenchMix reads "weight path" lines; blank lines and # comments are
+// skipped and a missing weight counts as 1
+func loadBenchMix(path string) ([]benchRequest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var mix []benchRequest
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		req := benchRequest{weight: 1, path: line}
+		if w, p, ok := strings.Cut(line, " "); ok {
+			n, err := strconv.Atoi(w)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid weight in %q", line)
+			}
+			req = benchRequest{weight: n, path: strings.TrimSpace(p)}
+		}
+		mix = append(mix, req)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(mix) == 0 {
+		return nil, fmt.Errorf("request mix %s is empty", path)
+	}
+	return mix, nil
+}
+
+// newMockProvider serves the mock CoinGecko after a fixed delay,
+// counting calls
+func newMockProvider(latency time.Duration, calls *atomic.Int64) *httptest.Server {
+	mock := providers.NewMockHandler()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		time.Sleep(latency)
+		mock.ServeHTTP(w, r)
+	}))
+}
+
+// percentile returns the p-th percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(float64(len(sorted)-1) * p)
+	return sorted[i]
+}
+
+// RunBench replays a request mix against an in-process server backed by a
+// mock provider and reports latency percentiles and cache hit rate
+func RunBench(cfg BenchConfig, out io.Writer) error {
+	mix := defaultBenchMix
+	if cfg.MixFile != "" {
+		var err error
+		if mix, err = loadBenchMix(cfg.MixFile); err != nil {
+			return err
+		}
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+
+	var upstreamCalls atomic.Int64
+	provider := newMockProvider(cfg.UpstreamLatency, &upstreamCalls)
+	defer provider.Close()
+
+	coingecko := providers.NewCoinGecko("bench", nil)
+	coingecko.BaseURL = provider.URL + "/api/v3"
+	priceCache := cache.NewPriceCache(coingecko)
+	priceCache.SetTTL(cfg.CacheTTL)
+
+	server := api.NewServer(api.Options{Cache: priceCache})
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	// Expand weights into a lookup table for uniform sampling
+	var table []string
+	for _, req := range mix {
+		for i := 0; i < req.weight; i++ {
+			table = append(table, req.path)
+		}
+	}
+
+	client := &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: cfg.Concurrency}}
+	deadline := time.Now().Add(cfg.Duration)
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		statuses  = make(map[int]int)
+		failures  int
+		wg        sync.WaitGroup
+	)
+
+	for i := 0; i < cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed))
+			var local []time.Duration
+			localStatuses := make(map[int]int)
+			localFailures := 0
+
+			for time.Now().Before(deadline) {
+				path := table[rng.Intn(len(table))]
+				start := time.Now()
+				resp, err := client.Get(ts.URL + path)
+				if err != nil {
+					localFailures++
+					continue
+				}
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				local = append(local, time.Since(start))
+				localStatuses[resp.StatusCode]++
+			}
+
+			mu.Lock()
+			latencies = append(latencies, local...)
+			for code, n := range localStatuses {
+				statuses[code] += n
+			}
+			failures += localFailures
+			mu.Unlock()
+		}(int64(i))
+	}
+	wg.Wait()
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	stats := priceCache.Stats()
+	hitRate := 0.0
+	if total := stats.Hits + stats.Misses; total > 0 {
+		hitRate = float64(stats.Hits) / float64(total) * 100
+	}
+
+	fmt.Fprintf(out, "requests:        %d in %v (%.0f req/s)\n", len(latencies), cfg.Duration, float64(len(latencies))/cfg.Duration.Seconds())
+	fmt.Fprintf(out, "latency p50:     %v\n", percentile(latencies, 0.50))
+	fmt.Fprintf(out, "latency p95:     %v\n", percentile(latencies, 0.95))
+	fmt.Fprintf(out, "latency p99:     %v\n", percentile(latencies, 0.99))
+	fmt.Fprintf(out, "cache hit rate:  %.1f%% (%d hits, %d misses)\n", hitRate, stats.Hits, stats.Misses)
+	fmt.Fprintf(out, "upstream calls:  %d\n", upstreamCalls.Load())
+	codes := make([]int, 0, len(statuses))
+	for code := range statuses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		fmt.Fprintf(out, "status %d:      %d\n", code, statuses[code])
+	}
+	if failures > 0 {
+		fmt.Fprintf(out, "transport errors: %d\n", failures)
+	}
+	return nil
+}
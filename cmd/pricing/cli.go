@@ -0,0 +1,278 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/luxfi/pricing"
+	"github.com/luxfi/pricing/pkg/config"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// symbolIDs maps common ticker symbols to CoinGecko ids for convert
+var symbolIDs = map[string]string{
+	"btc":  "bitcoin",
+	"eth":  "ethereum",
+	"usdt": "tether",
+	"usdc": "usd-coin",
+	"bnb":  "binancecoin",
+	"sol":  "solana",
+	"xrp":  "ripple",
+	"ada":  "cardano",
+	"doge": "dogecoin",
+	"avax": "avalanche-2",
+	"dot":  "polkadot",
+	"lux":  "lux",
+}
+
+// marketSorts orders markets by a field, largest first
+var marketSorts = map[string]func(a, b providers.Price) bool{
+	"market_cap": func(a, b providers.Price) bool { return a.MarketCap > b.MarketCap },
+	"volume":     func(a, b providers.Price) bool { return a.TotalVolume > b.TotalVolume },
+	"price":      func(a, b providers.Price) bool { return a.CurrentPrice > b.CurrentPrice },
+	"change": func(a, b providers.Price) bool {
+		return a.PriceChangePercentage24h > b.PriceChangePercentage24h
+	},
+}
+
+// cliFlags holds flags shared by the query commands
+type cliFlags struct {
+	fs      *flag.FlagSet
+	loader  *config.Loader
+	json    *bool
+	timeout *time.Duration
+}
+
+func newCLIFlags(name string) *cliFlags {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	return &cliFlags{
+		fs:      fs,
+		loader:  config.Bind(fs),
+		json:    fs.Bool("json", false, "print JSON instead of text"),
+		timeout: fs.Duration("timeout", 30*time.Second, "give up after this long"),
+	}
+}
+
+// parse parses flags that may appear before or after positional arguments
+// and builds an engine from the configuration
+func (c *cliFlags) parse(args []string) ([]string, *pricing.Engine, error) {
+	var positional []string
+	for {
+		c.fs.Parse(args)
+		args = c.fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	cfg, err := c.loader.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	engine, err := pricing.NewEngine(cfg)
+	return positional, engine, err
+}
+
+func (c *cliFlags) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), *c.timeout)
+}
+
+// runPrice prints the price of one token
+func runPrice(args []string) error {
+	cf := newCLIFlags("price")
+	currency := cf.fs.String("currency", "usd", "quote currency")
+	positional, engine, err := cf.parse(args)
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+	if len(positional) != 1 {
+		return errors.New("usage: pricing price <token> [-currency usd]")
+	}
+
+	ctx, cancel := cf.context()
+	defer cancel()
+
+	price, err := engine.GetPrice(ctx, positional[0], *currency)
+	if err != nil {
+		return err
+	}
+	if *cf.json {
+		return printJSON(os.Stdout, price)
+	}
+	fmt.Printf("%s (%s): %s %s (%+.2f%% 24h)\n", price.Name, strings.ToUpper(price.Symbol),
+		formatAmount(price.Price), strings.ToUpper(price.Currency), price.Change24h)
+	return nil
+}
+
+// marketRow is a market listing with the protocol's TVL, if requested
+type marketRow struct {
+	providers.Price
+	TVL *float64 `json:"tvl,omitempty"`
+}
+
+// runMarkets lists the largest tokens by market cap
+func runMarkets(args []string) error {
+	cf := newCLIFlags("markets")
+	currency := cf.fs.String("currency", "usd", "quote currency")
+	limit := cf.fs.Int("limit", 20, "number of tokens (max 250)")
+	sortBy := cf.fs.String("sort", "market_cap", "sort by market_cap, volume, price or change")
+	withTVL := cf.fs.Bool("tvl", false, "add protocol TVL from DefiLlama")
+	_, engine, err := cf.parse(args)
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+	less, ok := marketSorts[*sortBy]
+	if !ok {
+		return fmt.Errorf("unknown sort field %q (market_cap, volume, price, change)", *sortBy)
+	}
+
+	ctx, cancel := cf.context()
+	defer cancel()
+
+	markets, err := engine.CoinGecko().FetchTopMarkets(ctx, strings.ToLower(*currency), *limit)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(markets, func(i, j int) bool { return less(markets[i], markets[j]) })
+
+	rows := make([]marketRow, len(markets))
+	for i, m := range markets {
+		rows[i].Price = m
+	}
+	if *withTVL {
+		ids := make([]string, len(markets))
+		for i, m := range markets {
+			ids[i] = m.ID
+		}
+		tvls, err := engine.TVL().TokenTVLs(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("tvl: %w", err)
+		}
+		for i := range rows {
+			if v, ok := tvls[rows[i].ID]; ok {
+				rows[i].TVL = &v
+			}
+		}
+	}
+
+	if *cf.json {
+		return printJSON(os.Stdout, rows)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := "SYMBOL\tPRICE\t24H\tMARKET CAP\tVOLUME\t"
+	if *withTVL {
+		header += "TVL\t"
+	}
+	fmt.Fprintln(tw, header)
+	for _, m := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t%+.2f%%\t%.0f\t%.0f\t", strings.ToUpper(m.Symbol),
+			formatAmount(m.CurrentPrice), m.PriceChangePercentage24h, m.MarketCap, m.TotalVolume)
+		if *withTVL {
+			if m.TVL != nil {
+				fmt.Fprintf(tw, "%.0f\t", *m.TVL)
+			} else {
+				fmt.Fprint(tw, "-\t")
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// runConvert converts an amount of one token into another token or a
+// quote currency
+func runConvert(args []string) error {
+	cf := newCLIFlags("convert")
+	positional, engine, err := cf.parse(args)
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+	if len(positional) != 3 {
+		return errors.New("usage: pricing convert <amount> <from> <to>")
+	}
+	amount, err := strconv.ParseFloat(positional[0], 64)
+	if err != nil {
+		return fmt.Errorf("invalid amount %q", positional[0])
+	}
+	from, to := resolveSymbol(positional[1]), resolveSymbol(positional[2])
+
+	ctx, cancel := cf.context()
+	defer cancel()
+
+	var rate float64
+	if _, isSymbol := symbolIDs[strings.ToLower(positional[2])]; !isSymbol {
+		// Try the target as a quote currency first (usd, eur, ...)
+		if p, err := engine.GetPrice(ctx, from, to); err == nil {
+			rate = p.Price
+		}
+	}
+	if rate == 0 {
+		// Cross both tokens through USD
+		prices, err := engine.GetPrices(ctx, []string{from, to}, "usd")
+		if err != nil {
+			return err
+		}
+		fromPrice, toPrice := prices.Prices[from], prices.Prices[to]
+		if fromPrice == nil {
+			return fmt.Errorf("token not found: %s", from)
+		}
+		if toPrice == nil || toPrice.Price == 0 {
+			return fmt.Errorf("token or currency not found: %s", to)
+		}
+		rate = fromPrice.Price / toPrice.Price
+	}
+
+	result := amount * rate
+	if *cf.json {
+		return printJSON(os.Stdout, map[string]interface{}{
+			"amount": amount,
+			"from":   from,
+			"to":     to,
+			"rate":   rate,
+			"result": result,
+		})
+	}
+	fmt.Printf("%s %s = %s %s\n", positional[0], strings.ToUpper(positional[1]),
+		formatAmount(result), strings.ToUpper(positional[2]))
+	return nil
+}
+
+// resolveSymbol maps a ticker symbol to its CoinGecko id if known
+func resolveSymbol(s string) string {
+	s = strings.ToLower(s)
+	if id, ok := symbolIDs[s]; ok {
+		return id
+	}
+	return s
+}
+
+// formatAmount prints enough significant digits for small prices
+func formatAmount(v float64) string {
+	if v != 0 && v < 1 && v > -1 {
+		return strconv.FormatFloat(v, 'g', 6, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,260 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Command pricing runs the Lux pricing API server.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing"
+	"github.com/luxfi/pricing/pkg/api"
+	"github.com/luxfi/pricing/pkg/config"
+)
+
+const usage = `Usage: pricing [command] [flags]
+
+Commands:
+  serve                       Run the API server (default)
+  price <token>               Print the price of a token
+  markets                     List the largest tokens by market cap
+  convert <amount> <from> <to>
+                              Convert an amount between tokens or currencies
+  backfill -tokens <ids>      Import daily price history into HISTORY_DIR
+  openapi                     Print the OpenAPI spec
+
+Run "pricing <command> -h" for command flags.
+`
+
+func main() {
+	cmd, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+
+	var err error
+	switch cmd {
+	case "serve":
+		serve(args)
+	case "price":
+		err = runPrice(args)
+	case "markets":
+		err = runMarkets(args)
+	case "convert":
+		err = runConvert(args)
+	case "backfill":
+		err = runBackfill(args)
+	case "openapi":
+		_, err = os.Stdout.Write(append(api.OpenAPISpec(), '\n'))
+	case "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pricing %s: %v\n", cmd, err)
+		os.Exit(1)
+	}
+}
+
+// serve runs the API server
+func serve(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	loader := config.Bind(fs)
+	bench := fs.Bool("bench", false, "run the load-test harness against a mock provider and exit")
+	benchCfg := BenchConfig{}
+	fs.DurationVar(&benchCfg.Duration, "bench-duration", 10*time.Second, "load-test duration")
+	fs.IntVar(&benchCfg.Concurrency, "bench-concurrency", 32, "concurrent load-test clients")
+	fs.StringVar(&benchCfg.MixFile, "bench-mix", "", "request mix file (\"weight path\" per line); built-in mix if empty")
+	fs.DurationVar(&benchCfg.CacheTTL, "bench-ttl", 5*time.Second, "cache TTL during the load test")
+	fs.DurationVar(&benchCfg.UpstreamLatency, "bench-upstream-latency", 50*time.Millisecond, "simulated provider latency")
+	fs.Parse(args)
+
+	if *bench {
+		if err := RunBench(benchCfg, os.Stdout); err != nil {
+			log.Fatalf("Benchmark failed: %v", err)
+		}
+		return
+	}
+
+	// Defaults < config file < environment < flags
+	cfg, err := loader.Load()
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+
+	engine, err := pricing.NewEngine(cfg)
+	if err != nil {
+		log.Fatalf("Failed to start: %v", err)
+	}
+	defer engine.Close()
+
+	engine.Start(context.Background())
+
+	// Reloadable settings follow the config file and POST /admin/reload
+	engine.SetConfigSource(loader.Load)
+	if path := loader.File(); path != "" {
+		go engine.WatchConfig(context.Background(), path, 5*time.Second)
+	}
+
+	switch cfg.Provider {
+	case "mock":
+		log.Printf("Serving mock price data; CoinGecko is not called")
+	case "demo":
+		log.Printf("Serving demo price data (seed %d); CoinGecko is not called", cfg.Demo.Seed)
+	}
+	if dir := cfg.Upstream.Record; dir != "" {
+		log.Printf("Recording upstream responses into %s", dir)
+	}
+	if dir := cfg.Upstream.Replay; dir != "" {
+		log.Printf("Replaying upstream responses from %s", dir)
+	}
+	if signer := engine.Signer(); signer != nil {
+		log.Printf("Response signing enabled (key id %s)", signer.KeyID())
+	}
+	if signer := engine.Quotes().Signer(); signer != "" {
+		log.Printf("EIP-712 quote signing enabled (signer %s)", signer)
+	}
+	if q := engine.Quorum(); q != nil {
+		log.Printf("Quotes and oracle updates require %d of %d providers to agree within %g%%", q.Min(), q.Len(), cfg.Quorum.Tolerance*100)
+	}
+	switch cfg.Replica.Role {
+	case "primary":
+		log.Printf("Replicating refreshes to secondaries on %s", cfg.Stream.RedisChannel)
+	case "secondary":
+		log.Printf("Serving refreshes replicated on %s; misses are fetched from the primary at %s", cfg.Stream.RedisChannel, cfg.Replica.PrimaryURL)
+	}
+	if engine.Snapshots() != nil {
+		log.Printf("Exporting snapshots every %v", cfg.Snapshot.Interval.Duration)
+	}
+	if cfg.Email.SMTPAddr != "" {
+		log.Printf("Email channels enabled via %s, batched every %v", cfg.Email.SMTPAddr, cfg.Email.Batch.Duration)
+	}
+	if n := len(cfg.Reports.Channels); n > 0 {
+		log.Printf("Delivering %s reports to %d channels at %02d:00 UTC", cfg.Reports.Period, n, cfg.Reports.Hour)
+	}
+
+	if f := cfg.Features; len(f.Enabled) > 0 || len(f.Disabled) > 0 {
+		var off []string
+		for _, name := range api.FeatureGroups() {
+			if !f.On(name) {
+				off = append(off, name)
+			}
+		}
+		log.Printf("Endpoint groups disabled: %s", strings.Join(off, ", "))
+	}
+
+	port := cfg.Port
+	log.Printf("Starting pricing API server on port %s", port)
+	log.Printf("Cache TTL: %v", engine.Cache().TTL())
+	log.Printf("Endpoints:")
+	log.Printf("  GET /health - Health check")
+	if engine.SLO() != nil {
+		log.Printf("  GET /v1/slo?format=prometheus - Availability, latency and error budget per route")
+	}
+	log.Printf("  GET /v1/status - Degradation clients can show a delay banner for")
+	log.Printf("  GET /openapi.json, /docs - API description and Swagger UI")
+	log.Printf("  GET /v1/price/{token_id}?currency=usd - Get single token price")
+	log.Printf("  GET /v1/prices?ids=bitcoin,ethereum&currency=usd - Get multiple prices")
+	log.Printf("  GET /v1/rounding - Display precision of rounded prices")
+	log.Printf("  GET /v1/simple/price?ids=bitcoin&vs_currencies=usd - CoinGecko compatible")
+	log.Printf("  GET /v1/token-price/{chain}/{contract} - Token price by contract (%s)", strings.Join(engine.TokenPrices().Chains(), ", "))
+	log.Printf("  GET /v1/coins/markets?vs_currency=usd, /v1/coins/{id} - CoinGecko compatible market data")
+	log.Printf("  GET /v1/markets?limit=100&format=csv - Largest tokens by market cap")
+	if engine.Categories() != nil {
+		log.Printf("  GET /v1/categories, /v1/markets?category=layer-1 - Token categories")
+	}
+	if v := engine.Supply(); v != nil {
+		log.Printf("  GET /v1/supply/{token} - Reported supply checked on chain (%s)", strings.Join(v.Tokens(), ", "))
+	}
+	if engine.SupplyChanges() != nil {
+		log.Printf("  GET /v1/supply/{token}/changes, /v1/supply/changes - Circulating supply changes")
+	}
+	if engine.Risk() != nil {
+		log.Printf("  GET /v1/risk - Tokens flagged as risky before swaps")
+	}
+	log.Printf("  GET /v1/history/{id}?days=30&format=csv - Price history")
+	log.Printf("  GET /v1/twap/{token}?window=1h - TWAP and VWAP of recorded prices")
+	log.Printf("  GET /v1/close/{token}?tz=America/New_York&date=2024-06-01 - Daily close in a time zone")
+	log.Printf("  GET /v1/changes?since={cursor} - Prices changed since the previous poll")
+	if engine.Stream() != nil {
+		log.Printf("  GET /v1/stream?ids=bitcoin,ethereum - Price updates as server-sent events")
+	}
+	log.Printf("  POST /v1/portfolio/performance - Portfolio value, PnL and returns")
+	log.Printf("  POST /v1/portfolio/allocation - Portfolio allocation by category, chain, stability and staking")
+	log.Printf("  POST /v1/tax/lots?format=csv - Cost basis and value of tax lots")
+	log.Printf("  GET /v1/analytics/{token}?metrics=volatility_30d,sharpe_180d - Risk metrics")
+	log.Printf("  GET /v1/indicators/{token}?set=sma_50,rsi_14 - Moving averages and RSI")
+	log.Printf("  GET /v1/returns/{token}?periods=1d,7d,30d,90d,1y - Returns per period")
+	log.Printf("  GET /v1/extremes/{token} - All-time and 52-week highs and lows")
+	log.Printf("  GET /v1/listings/changes?since=... - Coins listed and delisted")
+	log.Printf("  GET /v1/analytics/{token}/vs?benchmark=bitcoin&days=90 - Performance against a benchmark")
+	log.Printf("  GET /v1/analytics/correlation?ids=bitcoin,ethereum&days=90 - Return correlation matrix")
+	log.Printf("  GET /v1/reports/latest?format=markdown - Latest %s market report", engine.Reports().Period())
+	log.Printf("  GET /v1/global?currency=usd - Total market cap, volume and dominance")
+	log.Printf("  GET /v1/trending - Trending tokens")
+	log.Printf("  GET /v1/movers?window=24h&limit=10 - Top gainers and losers")
+	log.Printf("  GET /v1/usage - Usage for the calling tenant")
+	log.Printf("  GET, POST /v1/alerts; GET, PUT, DELETE /v1/alerts/{id} - Price alerts")
+	log.Printf("  POST /v1/alerts/test - Backtest an alert condition")
+	log.Printf("  GET /v1/webhooks/{id}/deliveries - Alert deliveries and their attempts")
+	if engine.FX() != nil {
+		log.Printf("  GET /v1/fx?base=usd&symbols=eur,gbp - Fiat exchange rates")
+	}
+	if names := engine.Indices().Names(); len(names) > 0 {
+		log.Printf("  GET /v1/index/{name} - Token index value (%s)", strings.Join(names, ", "))
+	}
+	log.Printf("  GET /v1/derivatives/{token} - Perpetual funding rates and open interest")
+	log.Printf("  GET /v1/nft/{collection} - NFT collection floor price")
+	log.Printf("  GET /v1/tvl/{protocol} - DeFi protocol TVL")
+	log.Printf("  GET /v1/token/{id}/logo?size=64 - Token logo, proxied and cached")
+	if oracle := engine.Gas(); oracle != nil {
+		log.Printf("  GET /v1/gas/{chain} - Gas fee estimates (%s)", strings.Join(oracle.Chains(), ", "))
+	}
+	if o := engine.Onramps(); o != nil {
+		log.Printf("  GET /v1/onramp/quote?token=lux&fiat=usd&amount=100 - On-ramp offers (%s)", strings.Join(o.Providers(), ", "))
+	}
+	if b := engine.Bridge(); b != nil {
+		log.Printf("  GET /v1/bridge/rates - Bridge exchange rates (%s)", strings.Join(b.Pairs(), ", "))
+	}
+	if engine.Stablecoins() != nil {
+		log.Printf("  GET /v1/stablecoins - Stablecoin peg deviation")
+	}
+	log.Printf("  GET /v1/quote/{token}?signed=eip712 - EIP-712 price quote")
+	if engine.Signer() != nil || engine.Quotes().Signer() != "" {
+		log.Printf("  GET /v1/signing/keys - Keys for ?signed=true responses and EIP-712 quotes")
+	}
+	if len(cfg.Admin.APIKeys) > 0 {
+		log.Printf("  POST /v1/admin/cache/flush?token=bitcoin - Flush cache (admin)")
+		log.Printf("  GET /v1/admin/audit - Audit log (admin)")
+		log.Printf("  GET /v1/admin/tenants - Tenant usage (admin)")
+		log.Printf("  GET /v1/admin/quarantine - Price updates held back as anomalies (admin)")
+		log.Printf("  GET /v1/admin/schema - Upstream fields that drifted from their schema (admin)")
+		if engine.Balancer() != nil {
+			log.Printf("  GET /v1/admin/routing - Latency and routing per balanced provider (admin)")
+		}
+		log.Printf("  POST /v1/admin/tenants/keys?tenant=wallet - Create tenant API key (admin)")
+		log.Printf("  POST /v1/admin/reload - Reload configuration (admin)")
+		log.Printf("  GET|PUT|DELETE /v1/admin/overrides - Token metadata overrides (admin)")
+		log.Printf("  GET|POST|DELETE /v1/admin/dead-letters - Failed deliveries and replay (admin)")
+		log.Printf("  GET|POST /v1/admin/bundle - Export or import aliases, indices and alerts (admin)")
+		if engine.Treasury() != nil {
+			log.Printf("  GET|POST /v1/admin/treasury - Treasury valuations (admin)")
+		}
+		if engine.Deviation() != nil {
+			log.Printf("  GET /v1/admin/deviation - Price deviation between providers (admin)")
+		}
+	}
+
+	if err := http.ListenAndServe(":"+port, engine.Handler()); err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
@@ -1,3 +1,5 @@
 module github.com/luxfi/pricing
 
 go 1.21
+
+require golang.org/x/sync v0.11.0
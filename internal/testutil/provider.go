@@ -0,0 +1,151 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package testutil
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// Provider is a providers.Provider answering from scripted prices, with
+// failures and latency injected on demand. Prices are quoted the same in
+// every currency. It is safe for concurrent use.
+type Provider struct {
+	name string
+
+	mu      sync.Mutex
+	prices  map[string]providers.Price
+	next    []error // returned by the next calls, in order
+	failing error   // returned by every call while set
+	delay   time.Duration
+	calls   int
+	fetched []string
+}
+
+// NewProvider creates a provider named name that knows no tokens
+func NewProvider(name string) *Provider {
+	return &Provider{name: name, prices: make(map[string]providers.Price)}
+}
+
+// Name identifies the provider
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// Set quotes a token at price, replacing any earlier quote
+func (p *Provider) Set(tokenID string, price float64) {
+	p.SetPrice(providers.Price{ID: tokenID, Symbol: tokenID, Name: tokenID, CurrentPrice: price})
+}
+
+// SetPrice quotes a token with every field of pr
+func (p *Provider) SetPrice(pr providers.Price) {
+	pr.ID = strings.ToLower(pr.ID)
+	if pr.Source == "" {
+		pr.Source = p.name
+	}
+	p.mu.Lock()
+	p.prices[pr.ID] = pr
+	p.mu.Unlock()
+}
+
+// Remove forgets a token, so it is answered as not found
+func (p *Provider) Remove(tokenID string) {
+	p.mu.Lock()
+	delete(p.prices, strings.ToLower(tokenID))
+	p.mu.Unlock()
+}
+
+// FailNext fails the next calls with errs, one call each
+func (p *Provider) FailNext(errs ...error) {
+	p.mu.Lock()
+	p.next = append(p.next, errs...)
+	p.mu.Unlock()
+}
+
+// FailAll fails every call with err until it is called again with nil
+func (p *Provider) FailAll(err error) {
+	p.mu.Lock()
+	p.failing = err
+	p.mu.Unlock()
+}
+
+// SetDelay makes every call take d, or until its context is done
+func (p *Provider) SetDelay(d time.Duration) {
+	p.mu.Lock()
+	p.delay = d
+	p.mu.Unlock()
+}
+
+// Calls returns how many calls have been made
+func (p *Provider) Calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
+
+// Fetched returns the token ids asked for, in order, across all calls
+func (p *Provider) Fetched() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.fetched...)
+}
+
+// call counts a call for tokenIDs and returns the error to fail it with,
+// if any, after the injected delay
+func (p *Provider) call(ctx context.Context, tokenIDs []string) error {
+	p.mu.Lock()
+	p.calls++
+	p.fetched = append(p.fetched, tokenIDs...)
+	delay, err := p.delay, p.failing
+	if len(p.next) > 0 {
+		err, p.next = p.next[0], p.next[1:]
+	}
+	p.mu.Unlock()
+
+	if delay > 0 {
+		t := time.NewTimer(delay)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	return err
+}
+
+// FetchPrice returns a token's scripted price
+func (p *Provider) FetchPrice(ctx context.Context, tokenID, currency string) (*providers.Price, error) {
+	if err := p.call(ctx, []string{tokenID}); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	pr, ok := p.prices[strings.ToLower(tokenID)]
+	p.mu.Unlock()
+	if !ok {
+		return nil, &providers.NotFoundError{Token: tokenID}
+	}
+	return &pr, nil
+}
+
+// FetchPrices returns the scripted prices of the tokens known, leaving out
+// the others as CoinGecko does
+func (p *Provider) FetchPrices(ctx context.Context, tokenIDs []string, currency string) ([]providers.Price, error) {
+	if err := p.call(ctx, tokenIDs); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	prices := make([]providers.Price, 0, len(tokenIDs))
+	for _, id := range tokenIDs {
+		if pr, ok := p.prices[strings.ToLower(id)]; ok {
+			prices = append(prices, pr)
+		}
+	}
+	return prices, nil
+}
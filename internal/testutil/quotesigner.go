@@ -0,0 +1,233 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package testutil
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/luxfi/pricing/pkg/evm"
+	"github.com/luxfi/pricing/pkg/signing"
+)
+
+// secp256k1 curve parameters
+var (
+	curveP  = hexInt("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f")
+	curveN  = hexInt("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141")
+	curveGx = hexInt("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	curveGy = hexInt("483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8")
+)
+
+// QuoteSigner is a JSON-RPC signer, like Clef, holding one secp256k1 key.
+// It answers eth_signTypedData_v4 for quotes by signing their digest in the
+// requested domain. The arithmetic is plain math/big: slow and not constant
+// time, for tests only.
+type QuoteSigner struct {
+	URL     string
+	Address string // checksum-free, lowercase
+
+	key *big.Int
+
+	mu   sync.Mutex
+	rawV bool // answer v as 0 or 1 instead of 27 or 28
+}
+
+// NewQuoteSigner starts a signer holding the private key keccak256(seed),
+// stopped when the test ends
+func NewQuoteSigner(tb testing.TB, seed string) *QuoteSigner {
+	tb.Helper()
+	key := new(big.Int).SetBytes(evm.Keccak256([]byte(seed)))
+	key.Mod(key, curveN)
+	s := &QuoteSigner{key: key, Address: PrivateKeyAddress(key)}
+	ts := httptest.NewServer(http.HandlerFunc(s.serve))
+	tb.Cleanup(ts.Close)
+	s.URL = ts.URL
+	return s
+}
+
+// Options returns quoter options signing with s in domain
+func (s *QuoteSigner) Options(domain signing.EIP712Domain) signing.QuoterOptions {
+	return signing.QuoterOptions{Domain: domain, Decimals: 8, Signer: s.Address, SignerURL: s.URL}
+}
+
+// RawV makes the signer answer v as 0 or 1, as some signers do, instead of
+// 27 or 28
+func (s *QuoteSigner) RawV(raw bool) {
+	s.mu.Lock()
+	s.rawV = raw
+	s.mu.Unlock()
+}
+
+func (s *QuoteSigner) serve(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	reply := func(result any, err error) {
+		resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		if err != nil {
+			resp["error"] = map[string]any{"code": -32000, "message": err.Error()}
+		} else {
+			resp["result"] = result
+		}
+		json.NewEncoder(w).Encode(resp)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Method != "eth_signTypedData_v4" || len(req.Params) != 2 {
+		reply(nil, fmt.Errorf("unsupported method %s", req.Method))
+		return
+	}
+	var addr string
+	var td signing.TypedData
+	if err := json.Unmarshal(req.Params[0], &addr); err != nil || !strings.EqualFold(addr, s.Address) {
+		reply(nil, fmt.Errorf("unknown account %s", req.Params[0]))
+		return
+	}
+	if err := json.Unmarshal(req.Params[1], &td); err != nil || td.PrimaryType != "Quote" {
+		reply(nil, errors.New("typed data is not a quote"))
+		return
+	}
+	digest := signing.NewQuoter(signing.QuoterOptions{Domain: td.Domain}).Digest(td.Message)
+	sig := sign(s.key, digest)
+	s.mu.Lock()
+	if !s.rawV {
+		sig[64] += 27
+	}
+	s.mu.Unlock()
+	reply("0x"+hex.EncodeToString(sig), nil)
+}
+
+// Ecrecover returns the lowercase address whose key made sig, an r ‖ s ‖ v
+// signature in hex with v 27 or 28, over digest
+func Ecrecover(digest []byte, sig string) (string, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(sig, "0x"))
+	if err != nil || len(b) != 65 {
+		return "", fmt.Errorf("malformed signature %q", sig)
+	}
+	if b[64] != 27 && b[64] != 28 {
+		return "", fmt.Errorf("signature v = %d, want 27 or 28", b[64])
+	}
+	r, sv := new(big.Int).SetBytes(b[:32]), new(big.Int).SetBytes(b[32:64])
+	if r.Sign() == 0 || sv.Sign() == 0 || r.Cmp(curveN) >= 0 || sv.Cmp(curveN) >= 0 {
+		return "", errors.New("signature r or s out of range")
+	}
+
+	// R is the point with x = r and the parity of y that v records
+	y2 := new(big.Int).Exp(r, big.NewInt(3), curveP)
+	y2.Add(y2, big.NewInt(7)).Mod(y2, curveP)
+	y := new(big.Int).Exp(y2, new(big.Int).Rsh(new(big.Int).Add(curveP, big.NewInt(1)), 2), curveP)
+	if new(big.Int).Exp(y, big.NewInt(2), curveP).Cmp(y2) != 0 {
+		return "", errors.New("signature r is not on the curve")
+	}
+	if y.Bit(0) != uint(b[64]-27) {
+		y.Sub(curveP, y)
+	}
+
+	// Q = r⁻¹(s·R − z·G)
+	z := new(big.Int).SetBytes(digest)
+	rInv := new(big.Int).ModInverse(r, curveN)
+	u1 := new(big.Int).Mul(new(big.Int).Sub(curveN, z.Mod(z, curveN)), rInv)
+	u2 := new(big.Int).Mul(sv, rInv)
+	q := pointAdd(scalarMult(u1.Mod(u1, curveN), &point{curveGx, curveGy}), scalarMult(u2.Mod(u2, curveN), &point{r, y}))
+	if q == nil {
+		return "", errors.New("signature recovers to no key")
+	}
+	return pointAddress(q), nil
+}
+
+// PrivateKeyAddress returns the lowercase address of a private key
+func PrivateKeyAddress(key *big.Int) string {
+	return pointAddress(scalarMult(key, &point{curveGx, curveGy}))
+}
+
+// sign signs digest with key, returning r ‖ s ‖ v with v 0 or 1 and s in
+// the lower half of the order, as Ethereum requires. The nonce is derived
+// from the key and digest, so signatures are deterministic.
+func sign(key *big.Int, digest []byte) []byte {
+	z := new(big.Int).SetBytes(digest)
+	nm1 := new(big.Int).Sub(curveN, big.NewInt(1))
+	k := new(big.Int).SetBytes(evm.Keccak256(key.FillBytes(make([]byte, 32)), digest))
+	k.Mod(k, nm1).Add(k, big.NewInt(1))
+
+	p := scalarMult(k, &point{curveGx, curveGy})
+	r := new(big.Int).Mod(p.x, curveN)
+	s := new(big.Int).Mul(r, key)
+	s.Add(s, z).Mul(s, new(big.Int).ModInverse(k, curveN)).Mod(s, curveN)
+	v := byte(p.y.Bit(0))
+	if s.Cmp(new(big.Int).Rsh(curveN, 1)) > 0 {
+		s.Sub(curveN, s)
+		v ^= 1
+	}
+	sig := make([]byte, 65)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:64])
+	sig[64] = v
+	return sig
+}
+
+// point is an affine point on secp256k1; nil is the point at infinity
+type point struct {
+	x, y *big.Int
+}
+
+func pointAdd(a, b *point) *point {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	var l *big.Int
+	if a.x.Cmp(b.x) == 0 {
+		if new(big.Int).Add(a.y, b.y).Mod(new(big.Int).Add(a.y, b.y), curveP).Sign() == 0 {
+			return nil
+		}
+		// tangent: 3x² / 2y
+		l = new(big.Int).Mul(a.x, a.x)
+		l.Mul(l, big.NewInt(3))
+		l.Mul(l, new(big.Int).ModInverse(new(big.Int).Lsh(a.y, 1), curveP))
+	} else {
+		l = new(big.Int).Sub(b.y, a.y)
+		l.Mul(l, new(big.Int).ModInverse(new(big.Int).Mod(new(big.Int).Sub(b.x, a.x), curveP), curveP))
+	}
+	l.Mod(l, curveP)
+	x := new(big.Int).Mul(l, l)
+	x.Sub(x, a.x).Sub(x, b.x).Mod(x, curveP)
+	y := new(big.Int).Sub(a.x, x)
+	y.Mul(y, l).Sub(y, a.y).Mod(y, curveP)
+	return &point{x, y}
+}
+
+func scalarMult(k *big.Int, p *point) *point {
+	var acc *point
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		acc = pointAdd(acc, acc)
+		if k.Bit(i) == 1 {
+			acc = pointAdd(acc, p)
+		}
+	}
+	return acc
+}
+
+// pointAddress is the last 20 bytes of keccak256(x ‖ y)
+func pointAddress(p *point) string {
+	return "0x" + hex.EncodeToString(evm.Keccak256(p.x.FillBytes(make([]byte, 32)), p.y.FillBytes(make([]byte, 32)))[12:])
+}
+
+func hexInt(s string) *big.Int {
+	n, _ := new(big.Int).SetString(s, 16)
+	return n
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package testutil spins up the API and fake upstreams in process, so
+// endpoints can be tested end to end without mocking an http.Client:
+//
+//	srv := testutil.NewServer(t)
+//	srv.Provider.Set("bitcoin", 65000)
+//	var price cache.PriceResponse
+//	if code := srv.GetJSON(t, "/v1/price/bitcoin", &price); code != http.StatusOK {
+//		t.Fatalf("status %d", code)
+//	}
+//
+//	srv.Provider.FailAll(errors.New("upstream down"))
+package testutil
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/api"
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/client"
+)
+
+// AdminKey is the admin API key the servers of NewServer accept, for the
+// actor "test"
+const AdminKey = "test-admin-key"
+
+// Server is the API on a local port in front of a fresh cache, priced by
+// a scripted Provider
+type Server struct {
+	*httptest.Server
+
+	API      *api.Server
+	Cache    *cache.PriceCache
+	Provider *Provider
+}
+
+// NewServer starts the API with a Provider named "fake" that knows no
+// tokens yet. Each configure func may change the options, e.g. to add the
+// service behind an endpoint, before the server is created. The server
+// is closed when the test ends.
+func NewServer(tb testing.TB, configure ...func(*api.Options)) *Server {
+	tb.Helper()
+	p := NewProvider("fake")
+	c := cache.NewPriceCache(p)
+	opts := api.Options{
+		Cache:     c,
+		AdminKeys: map[string]string{AdminKey: "test"},
+	}
+	for _, fn := range configure {
+		fn(&opts)
+	}
+	s := &Server{API: api.NewServer(opts), Cache: c, Provider: p}
+	s.Server = httptest.NewServer(s.API.Handler())
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// Client returns a Go client of the server with AdminKey and no retries,
+// so injected failures surface at once
+func (s *Server) Client(opts ...client.Option) *client.Client {
+	opts = append([]client.Option{
+		client.WithAdminKey(AdminKey),
+		client.WithRetries(0, time.Millisecond, time.Millisecond),
+	}, opts...)
+	return client.New(s.URL, "", opts...)
+}
+
+// Do sends a request to path with body, as an admin if admin is set, and
+// returns the status and body of the answer. It fails the test if the
+// request cannot be made.
+func (s *Server) Do(tb testing.TB, method, path string, body io.Reader, admin bool) (int, []byte) {
+	tb.Helper()
+	req, err := http.NewRequest(method, s.URL+path, body)
+	if err != nil {
+		tb.Fatalf("%s %s: %v", method, path, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if admin {
+		req.Header.Set("Authorization", "Bearer "+AdminKey)
+	}
+	resp, err := s.Server.Client().Do(req)
+	if err != nil {
+		tb.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		tb.Fatalf("%s %s: reading body: %v", method, path, err)
+	}
+	return resp.StatusCode, data
+}
+
+// Get sends a GET to path and returns the status and body of the answer
+func (s *Server) Get(tb testing.TB, path string) (int, []byte) {
+	tb.Helper()
+	return s.Do(tb, http.MethodGet, path, nil, false)
+}
+
+// GetJSON sends a GET to path, decodes the JSON answer into out and
+// returns its status. It fails the test if the body is not JSON.
+func (s *Server) GetJSON(tb testing.TB, path string, out interface{}) int {
+	tb.Helper()
+	code, data := s.Get(tb, path)
+	if err := json.Unmarshal(data, out); err != nil {
+		tb.Fatalf("GET %s: %d %q is not JSON: %v", path, code, data, err)
+	}
+	return code
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package testutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// Failure is an injected upstream answer: Status with an optional
+// Retry-After, after Delay. A zero Status answers normally after Delay.
+type Failure struct {
+	Status     int
+	RetryAfter time.Duration
+	Delay      time.Duration
+}
+
+// Upstream is a fake CoinGecko on a local port, serving the mock
+// provider's fixtures with failures injected on demand, for exercising
+// the HTTP providers with their retries, rate-limit pauses and breakers
+type Upstream struct {
+	*httptest.Server
+
+	// APIURL is the API root to point a provider's BaseURL at
+	APIURL string
+
+	handler http.Handler
+
+	mu       sync.Mutex
+	next     []Failure
+	failing  *Failure
+	requests []string
+}
+
+// NewUpstream starts a fake CoinGecko serving providers.NewMockHandler,
+// closed when the test ends
+func NewUpstream(tb testing.TB) *Upstream {
+	tb.Helper()
+	u := &Upstream{handler: providers.NewMockHandler()}
+	u.Server = httptest.NewServer(http.HandlerFunc(u.serve))
+	u.APIURL = u.URL + "/api/v3"
+	tb.Cleanup(u.Close)
+	return u
+}
+
+// CoinGecko returns a CoinGecko provider calling the fake through
+// transport, or a default transport if nil
+func (u *Upstream) CoinGecko(transport http.RoundTripper) *providers.CoinGecko {
+	cg := providers.NewCoinGecko("", transport)
+	cg.BaseURL = u.APIURL
+	return cg
+}
+
+// FailNext answers the next requests with fs, one request each
+func (u *Upstream) FailNext(fs ...Failure) {
+	u.mu.Lock()
+	u.next = append(u.next, fs...)
+	u.mu.Unlock()
+}
+
+// FailAll answers every request with f until it is called again with nil
+func (u *Upstream) FailAll(f *Failure) {
+	u.mu.Lock()
+	u.failing = f
+	u.mu.Unlock()
+}
+
+// Requests returns the path and query of every request received, in order
+func (u *Upstream) Requests() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]string(nil), u.requests...)
+}
+
+// serve answers a request with the next injected failure, if any, or
+// from the fixtures
+func (u *Upstream) serve(w http.ResponseWriter, r *http.Request) {
+	u.mu.Lock()
+	u.requests = append(u.requests, r.URL.RequestURI())
+	f := u.failing
+	if len(u.next) > 0 {
+		f, u.next = &u.next[0], u.next[1:]
+	}
+	u.mu.Unlock()
+
+	if f == nil {
+		u.handler.ServeHTTP(w, r)
+		return
+	}
+	if f.Delay > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(f.Delay):
+		}
+	}
+	if f.Status == 0 {
+		u.handler.ServeHTTP(w, r)
+		return
+	}
+	if f.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(f.RetryAfter.Seconds())))
+	}
+	http.Error(w, `{"error":"injected failure"}`, f.Status)
+}
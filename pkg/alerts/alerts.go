@@ -0,0 +1,542 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package alerts stores client price alerts and fires them when a refreshed
+// price crosses their threshold.
+package alerts
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+const (
+	// MaxPerTenant is the most alerts one tenant may register
+	MaxPerTenant = 100
+
+	// MaxCooldown is the longest cooldown an alert may set
+	MaxCooldown = 7 * 24 * time.Hour
+)
+
+var (
+	// ErrNotFound is returned for unknown alert ids
+	ErrNotFound = errors.New("alert not found")
+
+	// ErrLimit is returned when a tenant already has MaxPerTenant alerts
+	ErrLimit = fmt.Errorf("at most %d alerts per tenant", MaxPerTenant)
+)
+
+// Directions an alert fires in
+const (
+	Above = "above" // price rises to or above the threshold
+	Below = "below" // price falls to or below the threshold
+)
+
+// Channel is where an alert is delivered: a Webhook, Slack or Discord URL,
+// a Telegram chat or an Email address
+type Channel = wire.AlertChannel
+
+// retryPolicy returns c's retry policy, with def's values where it sets
+// none
+func retryPolicy(c Channel, def RetryPolicy) RetryPolicy {
+	if c.Attempts > 0 {
+		def.Attempts = c.Attempts
+	}
+	if d, err := time.ParseDuration(c.Backoff); err == nil && d > 0 {
+		def.Backoff = d
+	}
+	return def
+}
+
+// Spec is the client-supplied part of an alert
+type Spec = wire.AlertSpec
+
+// ValidateSpec normalizes s and reports the first invalid field. The
+// channel is checked by the store's Notifier.
+func ValidateSpec(s *Spec) error {
+	s.Token = strings.ToLower(strings.TrimSpace(s.Token))
+	s.Currency = strings.ToLower(strings.TrimSpace(s.Currency))
+	s.Direction = strings.ToLower(s.Direction)
+	if s.Currency == "" {
+		s.Currency = "usd"
+	}
+	switch {
+	case s.Token == "":
+		return errors.New("token required")
+	case s.Direction != Above && s.Direction != Below:
+		return fmt.Errorf("direction must be %s or %s", Above, Below)
+	case s.Threshold <= 0:
+		return errors.New("threshold must be positive")
+	}
+	if s.Cooldown != "" {
+		d, err := time.ParseDuration(s.Cooldown)
+		if err != nil || d < 0 || d > MaxCooldown {
+			return fmt.Errorf("cooldown must be a duration between 0s and %v", MaxCooldown)
+		}
+	}
+	s.Channel.Type = strings.ToLower(s.Channel.Type)
+	if s.Channel.Attempts < 0 || s.Channel.Attempts > MaxAttempts {
+		return fmt.Errorf("channel.attempts must be between 1 and %d", MaxAttempts)
+	}
+	if s.Channel.Backoff != "" {
+		d, err := time.ParseDuration(s.Channel.Backoff)
+		if err != nil || d < time.Second || d > MaxBackoff {
+			return fmt.Errorf("channel.backoff must be a duration between 1s and %v", MaxBackoff)
+		}
+	}
+	return nil
+}
+
+// Alert is a registered price alert. It fires once when the price crosses
+// the threshold and re-arms when the price moves back. A crossing during
+// the alert's cooldown or a mute window is held back and fires once
+// they end, if the price is still past the threshold.
+type Alert = wire.Alert
+
+// cooldownOf returns s's cooldown, or def if it sets none
+func cooldownOf(s *Spec, def time.Duration) time.Duration {
+	if s.Cooldown == "" {
+		return def
+	}
+	d, _ := time.ParseDuration(s.Cooldown)
+	return d
+}
+
+// cooling reports whether a fired less than its cooldown before now
+func cooling(a *Alert, def time.Duration, now time.Time) bool {
+	return a.LastTriggeredAt != nil && now.Sub(*a.LastTriggeredAt) < cooldownOf(&a.AlertSpec, def)
+}
+
+// dedupKey identifies alerts whose notifications are identical
+func dedupKey(a *Alert) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s|%s|%s|%s|%g", a.Tenant, a.Channel.Type, a.Channel.URL, a.Channel.ChatID,
+		a.Channel.Email, a.Token, a.Currency, a.Direction, a.Threshold)
+}
+
+// conditionMet reports whether price satisfies a's condition
+func conditionMet(a *Alert, price float64) bool {
+	if a.Direction == Above {
+		return price >= a.Threshold
+	}
+	return price <= a.Threshold
+}
+
+// Event is delivered to an alert's channel when it fires
+type Event struct {
+	Alert     Alert     `json:"alert"`
+	Price     float64   `json:"price"`
+	Change24h float64   `json:"change_24h"` // percent
+	Time      time.Time `json:"time"`
+}
+
+// Store holds alerts, persisting every change to a JSON file if a path is
+// set
+type Store struct {
+	path     string
+	notifier Notifier
+
+	mu     sync.Mutex
+	alerts map[string]*Alert
+	policy Policy
+	sent   map[string]time.Time // last notification by dedupKey
+}
+
+// NewStore loads alerts from path, or starts empty if it does not exist;
+// with an empty path alerts are kept in memory only. notifier validates
+// channels and delivers alerts that fire.
+func NewStore(path string, notifier Notifier) (*Store, error) {
+	s := &Store{path: path, notifier: notifier, alerts: make(map[string]*Alert), sent: make(map[string]time.Time)}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []*Alert
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("alerts file %s: %w", path, err)
+	}
+	for _, a := range list {
+		s.alerts[a.ID] = a
+	}
+	return s, nil
+}
+
+// SetPolicy sets the default cooldown and the mute schedule
+func (s *Store) SetPolicy(p Policy) {
+	s.mu.Lock()
+	s.policy = p
+	s.mu.Unlock()
+}
+
+// Policy returns the default cooldown and the mute schedule
+func (s *Store) Policy() Policy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.policy
+}
+
+// List returns a tenant's alerts, oldest first
+func (s *Store) List(tenant string) []Alert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []Alert{}
+	for _, a := range s.alerts {
+		if a.Tenant == tenant {
+			list = append(list, *a)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// All returns every tenant's alerts, by tenant and then oldest first
+func (s *Store) All() []Alert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]Alert, 0, len(s.alerts))
+	for _, a := range s.alerts {
+		list = append(list, *a)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		switch {
+		case list[i].Tenant != list[j].Tenant:
+			return list[i].Tenant < list[j].Tenant
+		case !list[i].CreatedAt.Equal(list[j].CreatedAt):
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// Import adds alerts, or replaces the specs of those whose id is already
+// registered to the same tenant, keeping ids so importing the same alerts
+// twice changes nothing. It returns how many alerts were created and
+// updated. Every alert is validated first and, if any is invalid or a
+// tenant would have more than MaxPerTenant, nothing is imported. With
+// dryRun nothing is imported either way.
+func (s *Store) Import(list []Alert, dryRun bool) (created, updated int, err error) {
+	for i := range list {
+		a := &list[i]
+		if a.ID == "" {
+			return 0, 0, fmt.Errorf("alerts[%d]: id required", i)
+		}
+		if err := s.validate(&a.AlertSpec); err != nil {
+			return 0, 0, fmt.Errorf("alerts[%d]: %w", i, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := make(map[string]int)
+	for _, a := range s.alerts {
+		counts[a.Tenant]++
+	}
+	seen := make(map[string]bool, len(list))
+	var add, replace []Alert
+	for i, a := range list {
+		if seen[a.ID] {
+			return 0, 0, fmt.Errorf("alerts[%d]: id %s listed twice", i, a.ID)
+		}
+		seen[a.ID] = true
+		existing, ok := s.alerts[a.ID]
+		switch {
+		case !ok:
+			counts[a.Tenant]++
+			if counts[a.Tenant] > MaxPerTenant {
+				return 0, 0, fmt.Errorf("tenant %q: %w", a.Tenant, ErrLimit)
+			}
+			add = append(add, a)
+		case existing.Tenant != a.Tenant:
+			return 0, 0, fmt.Errorf("alerts[%d]: id %s belongs to another tenant", i, a.ID)
+		case existing.AlertSpec != a.AlertSpec:
+			replace = append(replace, a)
+		}
+	}
+	if dryRun || len(add)+len(replace) == 0 {
+		return len(add), len(replace), nil
+	}
+
+	now := time.Now().UTC()
+	prev := make(map[string]*Alert, len(replace))
+	for _, a := range add {
+		at := a.CreatedAt
+		if at.IsZero() {
+			at = now
+		}
+		s.alerts[a.ID] = &Alert{ID: a.ID, Tenant: a.Tenant, AlertSpec: a.AlertSpec, CreatedAt: at, UpdatedAt: now}
+	}
+	for _, a := range replace {
+		existing := s.alerts[a.ID]
+		old := *existing
+		prev[a.ID] = &old
+		existing.AlertSpec = a.AlertSpec
+		existing.Triggered = false
+		existing.UpdatedAt = now
+	}
+	if err := s.save(); err != nil {
+		for _, a := range add {
+			delete(s.alerts, a.ID)
+		}
+		for id, old := range prev {
+			*s.alerts[id] = *old
+		}
+		return 0, 0, err
+	}
+	return len(add), len(replace), nil
+}
+
+// Get returns one of a tenant's alerts
+func (s *Store) Get(tenant, id string) (*Alert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.alerts[id]
+	if !ok || a.Tenant != tenant {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	out := *a
+	return &out, nil
+}
+
+// Create registers an alert for a tenant
+func (s *Store) Create(tenant string, spec Spec) (*Alert, error) {
+	if err := s.validate(&spec); err != nil {
+		return nil, err
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, a := range s.alerts {
+		if a.Tenant == tenant {
+			n++
+		}
+	}
+	if n >= MaxPerTenant {
+		return nil, ErrLimit
+	}
+
+	now := time.Now().UTC()
+	a := &Alert{ID: id, Tenant: tenant, AlertSpec: spec, CreatedAt: now, UpdatedAt: now}
+	s.alerts[id] = a
+	if err := s.save(); err != nil {
+		delete(s.alerts, id)
+		return nil, err
+	}
+	out := *a
+	return &out, nil
+}
+
+// Update replaces an alert's spec and re-arms it
+func (s *Store) Update(tenant, id string, spec Spec) (*Alert, error) {
+	if err := s.validate(&spec); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.alerts[id]
+	if !ok || a.Tenant != tenant {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	prev := *a
+	a.AlertSpec = spec
+	a.Triggered = false
+	a.UpdatedAt = time.Now().UTC()
+	if err := s.save(); err != nil {
+		*a = prev
+		return nil, err
+	}
+	out := *a
+	return &out, nil
+}
+
+// Delete removes one of a tenant's alerts
+func (s *Store) Delete(tenant, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.alerts[id]
+	if !ok || a.Tenant != tenant {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	delete(s.alerts, id)
+	if err := s.save(); err != nil {
+		s.alerts[id] = a
+		return err
+	}
+	return nil
+}
+
+// validate checks a spec and that its channel can be delivered to
+func (s *Store) validate(spec *Spec) error {
+	if err := ValidateSpec(spec); err != nil {
+		return err
+	}
+	return s.notifier.Validate(spec.Channel)
+}
+
+// Watched returns the tokens with alerts, grouped by currency
+func (s *Store) Watched() map[string][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := make(map[string]bool)
+	watched := make(map[string][]string)
+	for _, a := range s.alerts {
+		key := a.Token + ":" + a.Currency
+		if !seen[key] {
+			seen[key] = true
+			watched[a.Currency] = append(watched[a.Currency], a.Token)
+		}
+	}
+	return watched
+}
+
+// Evaluate checks the alerts on freshly fetched prices and notifies those
+// that crossed their threshold. Crossings are held back during an
+// alert's cooldown and mute windows, and an alert whose notification is
+// identical to one sent in the last DedupWindow fires without notifying.
+func (s *Store) Evaluate(currency string, prices []*cache.PriceResponse) {
+	byID := make(map[string]*cache.PriceResponse, len(prices))
+	for _, p := range prices {
+		byID[p.ID] = p
+	}
+
+	now := time.Now().UTC()
+	var fired []Event
+	changed := false
+
+	s.mu.Lock()
+	muted := s.policy.Mute.Active(now)
+	for key, at := range s.sent {
+		if now.Sub(at) >= DedupWindow {
+			delete(s.sent, key)
+		}
+	}
+	for _, a := range s.alerts {
+		p, ok := byID[a.Token]
+		if !ok || a.Currency != currency {
+			continue
+		}
+		a.LastPrice = p.Price
+		met := conditionMet(a, p.Price)
+		if met == a.Triggered {
+			continue
+		}
+		if met && (muted || cooling(a, s.policy.Cooldown, now)) {
+			continue
+		}
+		a.Triggered = met
+		changed = true
+		if met {
+			at := now
+			a.LastTriggeredAt = &at
+			key := dedupKey(a)
+			if _, dup := s.sent[key]; dup {
+				continue
+			}
+			s.sent[key] = now
+			fired = append(fired, Event{Alert: *a, Price: p.Price, Change24h: p.Change24h, Time: now})
+		}
+	}
+	if changed {
+		if err := s.save(); err != nil {
+			log.Printf("Saving alerts: %v", err)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, e := range fired {
+		s.notifier.Notify(e)
+	}
+}
+
+// Run refreshes the watched prices every interval until ctx is done, so
+// alerts fire even for tokens no client is requesting. refresh is
+// typically PriceCache.GetMultiplePrices, whose refresh hook calls
+// Evaluate.
+func (s *Store) Run(ctx context.Context, interval time.Duration, refresh func(ctx context.Context, tokenIDs []string, currency string) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for currency, tokens := range s.Watched() {
+			if err := refresh(ctx, tokens, currency); err != nil {
+				log.Printf("Refreshing alert prices in %s: %v", currency, err)
+			}
+		}
+	}
+}
+
+// save writes every alert to the file, replacing it atomically. The
+// caller holds s.mu.
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	list := make([]*Alert, 0, len(s.alerts))
+	for _, a := range s.alerts {
+		list = append(list, a)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// newID returns a random alert id
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
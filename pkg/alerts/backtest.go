@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package alerts
+
+import (
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// Firing is a point at which a backtested alert would have fired
+type Firing = wire.AlertFiring
+
+// Backtest is how an alert condition would have behaved over a token's
+// price history
+type Backtest = wire.AlertBacktest
+
+// Replay runs spec's condition over series, firing and re-arming as
+// Evaluate would at each point under policy. Moves between points are not
+// seen, so on long histories with hourly or daily points an alert may
+// fire more often live than in the backtest.
+func Replay(spec Spec, series *history.Series, policy Policy) *Backtest {
+	cooldown := cooldownOf(&spec, policy.Cooldown)
+	b := &Backtest{
+		Token:     spec.Token,
+		Currency:  spec.Currency,
+		Direction: spec.Direction,
+		Threshold: spec.Threshold,
+		Cooldown:  cooldown.String(),
+		Days:      series.Days,
+		Points:    len(series.Points),
+		Firings:   []Firing{},
+	}
+	a := Alert{AlertSpec: spec}
+	for _, p := range series.Points {
+		met := conditionMet(&a, p.Price)
+		if met == a.Triggered {
+			continue
+		}
+		if met && (policy.Mute.Active(p.Time) || cooling(&a, policy.Cooldown, p.Time)) {
+			continue
+		}
+		a.Triggered = met
+		if met {
+			at := p.Time
+			a.LastTriggeredAt = &at
+			b.Firings = append(b.Firings, Firing{Time: p.Time, Price: p.Price})
+		}
+	}
+	b.Triggered = a.Triggered
+	if n := len(series.Points); n > 0 {
+		b.From, b.To = series.Points[0].Time, series.Points[n-1].Time
+	}
+	return b
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package alerts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+const (
+	// DefaultAttempts is how many times a delivery is tried in all when
+	// neither its channel nor the notifier sets it
+	DefaultAttempts = 3
+
+	// DefaultBackoff is the wait before the first retry when neither the
+	// channel nor the notifier sets it; it doubles after each retry
+	DefaultBackoff = 5 * time.Second
+
+	// MaxAttempts bounds the attempts a channel may set
+	MaxAttempts = 10
+
+	// MaxBackoff bounds the backoff a channel may set, and any wait
+	// between two attempts
+	MaxBackoff = time.Hour
+
+	// deliveriesPerAlert is how many recent deliveries are kept per alert
+	deliveriesPerAlert = 50
+
+	// maxDeadLetters bounds the dead letters kept; the oldest are dropped
+	maxDeadLetters = 1000
+)
+
+// ErrNoDeadLetter is returned for unknown dead letter ids
+var ErrNoDeadLetter = errors.New("dead letter not found")
+
+// Delivery statuses
+const (
+	Pending   = "pending"   // being tried, or waiting to be retried
+	Delivered = "delivered" // the channel accepted it
+	Failed    = "failed"    // every attempt failed; kept as a dead letter
+)
+
+// RetryPolicy is how a failed delivery is retried
+type RetryPolicy struct {
+	Attempts int           // tries in all, including the first
+	Backoff  time.Duration // wait before the first retry, doubling after each
+}
+
+// Attempt is one try of a delivery
+type Attempt = wire.DeliveryAttempt
+
+// Delivery is a notification POSTed to a webhook, Slack, Discord or
+// Telegram channel, and how its attempts went
+type Delivery = wire.Delivery
+
+// DeliveryLog keeps the recent deliveries of each alert, and the
+// deliveries that failed every attempt as dead letters to be replayed.
+// Dead letters are saved to a file if one is set.
+type DeliveryLog struct {
+	file string
+
+	mu     sync.Mutex
+	recent map[string][]*Delivery // by alert id, oldest first
+	dead   []*Delivery            // oldest first
+}
+
+// NewDeliveryLog loads dead letters from file, or starts empty if it does
+// not exist; with an empty file they are kept in memory only
+func NewDeliveryLog(file string) (*DeliveryLog, error) {
+	l := &DeliveryLog{file: file, recent: make(map[string][]*Delivery)}
+	if file == "" {
+		return l, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &l.dead); err != nil {
+		return nil, fmt.Errorf("dead letter file %s: %w", file, err)
+	}
+	return l, nil
+}
+
+// start records a new delivery, giving it an id
+func (l *DeliveryLog) start(d *Delivery) error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	d.ID = id
+	if d.Alert != "" {
+		recent := append(l.recent[d.Alert], d)
+		if len(recent) > deliveriesPerAlert {
+			recent = append(recent[:0:0], recent[len(recent)-deliveriesPerAlert:]...)
+		}
+		l.recent[d.Alert] = recent
+	}
+	return nil
+}
+
+// attempted records an attempt of a delivery and its status after it,
+// keeping it as a dead letter if it failed
+func (l *DeliveryLog) attempted(d *Delivery, a Attempt, status string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	d.Attempts = append(d.Attempts, a)
+	d.Status = status
+	d.UpdatedAt = a.Time
+	if status != Failed {
+		return nil
+	}
+	l.dead = append(l.dead, d)
+	if len(l.dead) > maxDeadLetters {
+		l.dead = append(l.dead[:0:0], l.dead[len(l.dead)-maxDeadLetters:]...)
+	}
+	return l.save()
+}
+
+// Deliveries returns an alert's recent deliveries, newest first
+func (l *DeliveryLog) Deliveries(alertID string) []Delivery {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	recent := l.recent[alertID]
+	list := make([]Delivery, 0, len(recent))
+	for i := len(recent) - 1; i >= 0; i-- {
+		list = append(list, copyDelivery(recent[i]))
+	}
+	return list
+}
+
+// DeadLetters returns the deliveries that failed every attempt, newest
+// first
+func (l *DeliveryLog) DeadLetters() []Delivery {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	list := make([]Delivery, 0, len(l.dead))
+	for i := len(l.dead) - 1; i >= 0; i-- {
+		list = append(list, copyDelivery(l.dead[i]))
+	}
+	return list
+}
+
+// Remove drops a dead letter and returns it
+func (l *DeliveryLog) Remove(id string) (Delivery, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	i := slices.IndexFunc(l.dead, func(d *Delivery) bool { return d.ID == id })
+	if i < 0 {
+		return Delivery{}, fmt.Errorf("%w: %s", ErrNoDeadLetter, id)
+	}
+	prev, d := l.dead, l.dead[i]
+	l.dead = append(l.dead[:i:i], l.dead[i+1:]...)
+	if err := l.save(); err != nil {
+		l.dead = prev
+		return Delivery{}, err
+	}
+	return copyDelivery(d), nil
+}
+
+// copyDelivery copies a delivery so its attempts can be read while it is
+// retried. The caller holds l.mu.
+func copyDelivery(d *Delivery) Delivery {
+	out := *d
+	out.Attempts = append([]Attempt{}, d.Attempts...)
+	return out
+}
+
+// save writes the dead letters to the file, replacing it atomically. The
+// caller holds l.mu.
+func (l *DeliveryLog) save() error {
+	if l.file == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(l.dead, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.file), filepath.Base(l.file)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), l.file)
+}
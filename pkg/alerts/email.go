@@ -0,0 +1,170 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package alerts
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// DefaultEmailTemplate renders a batch as a plain text email. Templates
+// output header lines, a blank line and the body; From, To, Date and the
+// MIME headers are added.
+const DefaultEmailTemplate = `Subject: {{subject .}}
+
+{{range $i, $m := .Messages}}{{if $i}}
+---
+
+{{end}}{{$m.Text}}
+{{end}}`
+
+// MailerOptions configures SMTP delivery
+type MailerOptions struct {
+	Addr     string // SMTP server host:port
+	Username string // PLAIN auth if set
+	Password string
+	From     string
+
+	// Template is text/template source rendering a Batch;
+	// DefaultEmailTemplate if empty
+	Template string
+
+	// Batch is how long notifications to one address are collected into
+	// a single email; 0 sends each at once
+	Batch time.Duration
+}
+
+// Mail is a notification queued for email
+type Mail struct {
+	Name    string      `json:"name"` // e.g. "Alert 1f2e"
+	Text    string      `json:"text"`
+	Payload interface{} `json:"payload"` // the Event, or the report
+}
+
+// Batch is what an email template renders
+type Batch struct {
+	From     string
+	To       string
+	Messages []Mail
+}
+
+// Mailer sends notifications by SMTP, batching those to the same address
+type Mailer struct {
+	opts MailerOptions
+	tmpl *template.Template
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+	mu      sync.Mutex
+	pending map[string][]Mail
+}
+
+// NewMailer checks opts and parses the template
+func NewMailer(opts MailerOptions) (*Mailer, error) {
+	if _, _, err := net.SplitHostPort(opts.Addr); err != nil {
+		return nil, fmt.Errorf("smtp address %q: want host:port", opts.Addr)
+	}
+	if _, err := mail.ParseAddress(opts.From); err != nil {
+		return nil, fmt.Errorf("from address %q: %w", opts.From, err)
+	}
+	if opts.Template == "" {
+		opts.Template = DefaultEmailTemplate
+	}
+	tmpl, err := template.New("email").Funcs(template.FuncMap{"subject": subject}).Parse(opts.Template)
+	if err != nil {
+		return nil, fmt.Errorf("email template: %w", err)
+	}
+	return &Mailer{opts: opts, tmpl: tmpl, send: smtp.SendMail, pending: make(map[string][]Mail)}, nil
+}
+
+// Queue sends msg to an address, after the batch window if one is set
+func (m *Mailer) Queue(to string, msg Mail) {
+	if m.opts.Batch <= 0 {
+		go m.deliver(to, []Mail{msg})
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.pending[to]) == 0 {
+		time.AfterFunc(m.opts.Batch, func() { m.flush(to) })
+	}
+	m.pending[to] = append(m.pending[to], msg)
+}
+
+// flush sends the notifications queued for an address
+func (m *Mailer) flush(to string) {
+	m.mu.Lock()
+	batch := m.pending[to]
+	delete(m.pending, to)
+	m.mu.Unlock()
+	if len(batch) > 0 {
+		m.deliver(to, batch)
+	}
+}
+
+// deliver renders and sends one email; failures are logged
+func (m *Mailer) deliver(to string, batch []Mail) {
+	msg, err := m.render(to, batch)
+	if err == nil {
+		var auth smtp.Auth
+		if m.opts.Username != "" {
+			host, _, _ := net.SplitHostPort(m.opts.Addr)
+			auth = smtp.PlainAuth("", m.opts.Username, m.opts.Password, host)
+		}
+		from, _ := mail.ParseAddress(m.opts.From)
+		err = m.send(m.opts.Addr, auth, from.Address, []string{to}, msg)
+	}
+	if err != nil {
+		log.Printf("Email of %d notifications to %s failed: %v", len(batch), to, err)
+	}
+}
+
+// render executes the template and adds the envelope headers
+func (m *Mailer) render(to string, batch []Mail) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := m.tmpl.Execute(&buf, Batch{From: m.opts.From, To: to, Messages: batch}); err != nil {
+		return nil, err
+	}
+	head, body, ok := strings.Cut(strings.ReplaceAll(buf.String(), "\r\n", "\n"), "\n\n")
+	if !ok {
+		return nil, errors.New("email template: no blank line after the headers")
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "From: %s\r\nTo: %s\r\nDate: %s\r\n", m.opts.From, to, time.Now().Format(time.RFC1123Z))
+	out.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n")
+	for _, line := range strings.Split(head, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("email template: invalid header %q", line)
+		}
+		fmt.Fprintf(&out, "%s: %s\r\n", key, mime.QEncoding.Encode("utf-8", strings.TrimSpace(value)))
+	}
+	out.WriteString("\r\n")
+	out.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(out.String()), nil
+}
+
+// subject is the first line of the first notification, without Markdown
+// heading marks, and how many more the batch holds
+func subject(b Batch) string {
+	if len(b.Messages) == 0 {
+		return ""
+	}
+	s, _, _ := strings.Cut(b.Messages[0].Text, "\n")
+	s = strings.TrimSpace(strings.TrimLeft(s, "# "))
+	if n := len(b.Messages) - 1; n > 0 {
+		s += fmt.Sprintf(" (and %d more)", n)
+	}
+	return s
+}
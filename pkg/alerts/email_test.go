@@ -0,0 +1,195 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package alerts_test
+
+import (
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/alerts"
+)
+
+// session is what the fake server was told in one SMTP session
+type session struct {
+	commands []string // every command line but the message
+	data     string   // the message, dot-unstuffed
+}
+
+// smtpServer accepts SMTP sessions on a loopback port, advertising AUTH
+// PLAIN, and sends each finished session on the channel returned
+func smtpServer(t *testing.T) (string, <-chan session) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	sessions := make(chan session, 4)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, sessions)
+		}
+	}()
+	return ln.Addr().String(), sessions
+}
+
+func serveSMTP(conn net.Conn, sessions chan<- session) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	var s session
+	tp.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		s.commands = append(s.commands, line)
+		verb, _, _ := strings.Cut(strings.ToUpper(line), " ")
+		switch verb {
+		case "EHLO":
+			tp.PrintfLine("250-localhost")
+			tp.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			tp.PrintfLine("235 2.7.0 Authentication successful")
+		case "DATA":
+			tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			b, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.data = string(b)
+			tp.PrintfLine("250 2.0.0 Ok: queued")
+		case "QUIT":
+			tp.PrintfLine("221 2.0.0 Bye")
+			sessions <- s
+			return
+		default:
+			tp.PrintfLine("250 2.1.0 Ok")
+		}
+	}
+}
+
+// receive waits for the next session
+func receive(t *testing.T, sessions <-chan session) session {
+	t.Helper()
+	select {
+	case s := <-sessions:
+		return s
+	case <-time.After(5 * time.Second):
+		t.Fatal("no email was sent")
+		return session{}
+	}
+}
+
+func TestMailerDialogue(t *testing.T) {
+	addr, sessions := smtpServer(t)
+	m, err := alerts.NewMailer(alerts.MailerOptions{
+		Addr:     addr,
+		Username: "alerts",
+		Password: "secret",
+		From:     "Lux Pricing <alerts@lux.network>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.Queue("ops@example.com", alerts.Mail{Name: "Alert 1f2e", Text: "# Bitcoin above 100000\n\n.leading dot"})
+	s := receive(t, sessions)
+
+	// "\x00alerts\x00secret", base64-encoded
+	want := []string{
+		"EHLO localhost",
+		"AUTH PLAIN AGFsZXJ0cwBzZWNyZXQ=",
+		"MAIL FROM:<alerts@lux.network>",
+		"RCPT TO:<ops@example.com>",
+		"DATA",
+		"QUIT",
+	}
+	if len(s.commands) != len(want) {
+		t.Fatalf("commands %q, want %q", s.commands, want)
+	}
+	for i := range want {
+		if got := s.commands[i]; got != want[i] {
+			t.Errorf("command %d = %q, want %q", i, got, want[i])
+		}
+	}
+
+	head, body, ok := strings.Cut(s.data, "\n\n")
+	if !ok {
+		t.Fatalf("message %q has no blank line after the headers", s.data)
+	}
+	for _, h := range []string{
+		"From: Lux Pricing <alerts@lux.network>",
+		"To: ops@example.com",
+		"Subject: Bitcoin above 100000",
+		"Content-Type: text/plain; charset=utf-8",
+	} {
+		if !strings.Contains(head+"\n", h+"\n") {
+			t.Errorf("headers %q lack %q", head, h)
+		}
+	}
+	if !strings.Contains(body, "\n.leading dot\n") {
+		t.Errorf("body %q lost the line starting with a dot", body)
+	}
+}
+
+func TestMailerBatches(t *testing.T) {
+	addr, sessions := smtpServer(t)
+	m, err := alerts.NewMailer(alerts.MailerOptions{
+		Addr:  addr,
+		From:  "alerts@lux.network",
+		Batch: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.Queue("ops@example.com", alerts.Mail{Text: "Bitcoin above 100000"})
+	m.Queue("ops@example.com", alerts.Mail{Text: "Ether below 3000"})
+	m.Queue("risk@example.com", alerts.Mail{Text: "USDC off peg"})
+
+	got := map[string]string{}
+	for i := 0; i < 2; i++ {
+		s := receive(t, sessions)
+		got[s.commands[len(s.commands)-3]] = s.data
+	}
+	select {
+	case s := <-sessions:
+		t.Fatalf("unexpected third email %q", s.data)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	ops := got["RCPT TO:<ops@example.com>"]
+	if !strings.Contains(ops, "Subject: Bitcoin above 100000 (and 1 more)\n") ||
+		!strings.Contains(ops, "Bitcoin above 100000\n\n---\n\nEther below 3000\n") {
+		t.Errorf("ops email %q, want both notifications", ops)
+	}
+	if risk := got["RCPT TO:<risk@example.com>"]; !strings.Contains(risk, "Subject: USDC off peg\n") {
+		t.Errorf("risk email %q, want its own notification", risk)
+	}
+}
+
+func TestNewMailerRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		opts alerts.MailerOptions
+	}{
+		{"no port", alerts.MailerOptions{Addr: "smtp.example.com", From: "a@b.c"}},
+		{"bad from", alerts.MailerOptions{Addr: "smtp.example.com:25", From: "not an address"}},
+		{"bad template", alerts.MailerOptions{Addr: "smtp.example.com:25", From: "a@b.c", Template: "{{.Nope"}},
+	}
+	for _, tt := range tests {
+		if _, err := alerts.NewMailer(tt.opts); err == nil {
+			t.Errorf("%s: NewMailer accepted %+v", tt.name, tt.opts)
+		}
+	}
+}
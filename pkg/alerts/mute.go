@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package alerts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Policy limits how often alerts are delivered
+type Policy struct {
+	// Cooldown is the least time between two firings of an alert, for
+	// alerts that don't set their own
+	Cooldown time.Duration
+
+	// Mute holds back every alert while it is active
+	Mute Schedule
+}
+
+// DedupWindow is the least time between identical notifications: alerts
+// of one tenant with the same condition and channel
+const DedupWindow = time.Minute
+
+// Schedule is a set of recurring UTC windows
+type Schedule []Window
+
+// Window is a daily or weekly UTC time range. A window ending before it
+// starts runs past midnight into the next day.
+type Window struct {
+	Days  [7]bool // indexed by time.Weekday; the day the window starts
+	Start int     // minutes after midnight
+	End   int     // minutes after midnight, up to 24:00
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseSchedule parses windows such as "22:00-07:00" (every day),
+// "sat 00:00-24:00" or "mon-fri 12:00-13:00"
+func ParseSchedule(specs []string) (Schedule, error) {
+	var s Schedule
+	for _, spec := range specs {
+		w, err := parseWindow(strings.ToLower(strings.TrimSpace(spec)))
+		if err != nil {
+			return nil, fmt.Errorf("mute window %q: %w", spec, err)
+		}
+		s = append(s, w)
+	}
+	return s, nil
+}
+
+// parseWindow parses "[day[-day] ]HH:MM-HH:MM"
+func parseWindow(spec string) (Window, error) {
+	var w Window
+	days, span, ok := strings.Cut(spec, " ")
+	if !ok {
+		days, span = "", spec
+	}
+	if days == "" {
+		for i := range w.Days {
+			w.Days[i] = true
+		}
+	} else {
+		from, to, isRange := strings.Cut(days, "-")
+		if !isRange {
+			to = from
+		}
+		first, ok1 := weekdays[from]
+		last, ok2 := weekdays[to]
+		if !ok1 || !ok2 {
+			return w, fmt.Errorf("unknown day %q", days)
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			w.Days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+
+	start, end, ok := strings.Cut(strings.TrimSpace(span), "-")
+	if !ok {
+		return w, fmt.Errorf("want HH:MM-HH:MM")
+	}
+	var err error
+	if w.Start, err = clock(start); err != nil {
+		return w, err
+	}
+	if w.End, err = clock(end); err != nil {
+		return w, err
+	}
+	if w.Start == w.End || w.Start == 24*60 {
+		return w, fmt.Errorf("empty window")
+	}
+	return w, nil
+}
+
+// clock parses HH:MM into minutes after midnight
+func clock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err == nil {
+		return t.Hour()*60 + t.Minute(), nil
+	}
+	if s == "24:00" {
+		return 24 * 60, nil
+	}
+	return 0, fmt.Errorf("invalid time %q", s)
+}
+
+// Active reports whether t falls in any window
+func (s Schedule) Active(t time.Time) bool {
+	t = t.UTC()
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+	for _, w := range s {
+		if w.Start < w.End {
+			if w.Days[day] && minute >= w.Start && minute < w.End {
+				return true
+			}
+			continue
+		}
+		if (w.Days[day] && minute >= w.Start) || (w.Days[(day+6)%7] && minute < w.End) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TelegramURL is the Telegram Bot API root
+const TelegramURL = "https://api.telegram.org"
+
+// Channel types
+const (
+	Webhook  = "webhook"  // POST the Event as JSON to URL
+	Slack    = "slack"    // Slack incoming webhook URL
+	Discord  = "discord"  // Discord channel webhook URL
+	Telegram = "telegram" // message ChatID from the configured bot
+	Email    = "email"    // send to Email through the configured Mailer
+)
+
+// Notifier delivers fired alerts to their channels
+type Notifier interface {
+	// Validate reports whether the notifier can deliver to c
+	Validate(c Channel) error
+	Notify(e Event)
+}
+
+// Channels delivers to webhooks, Slack, Discord, Telegram and email.
+// Deliveries run in the background and failed ones are retried by the
+// channel's policy; failures are logged.
+type Channels struct {
+	// TelegramURL is the Bot API root, without a trailing slash
+	TelegramURL string
+
+	// Mailer sends email channels; they are unavailable if nil
+	Mailer *Mailer
+
+	// Retry is the policy of channels that set none of their own
+	Retry RetryPolicy
+
+	// Deliveries records deliveries and keeps those that fail as dead
+	// letters, if set
+	Deliveries *DeliveryLog
+
+	client        *http.Client
+	telegramToken string
+}
+
+// NewChannels creates a notifier. Telegram channels are available only
+// with a bot token. Requests use client, or a default client if nil.
+func NewChannels(telegramToken string, client *http.Client) *Channels {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Channels{
+		TelegramURL:   TelegramURL,
+		Retry:         RetryPolicy{Attempts: DefaultAttempts, Backoff: DefaultBackoff},
+		client:        client,
+		telegramToken: telegramToken,
+	}
+}
+
+// Validate reports whether c is a complete channel this notifier serves
+func (n *Channels) Validate(c Channel) error {
+	switch c.Type {
+	case Webhook, Slack, Discord:
+		if !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+			return errors.New("channel.url must be an http(s) URL")
+		}
+	case Telegram:
+		if n.telegramToken == "" {
+			return errors.New("telegram channels are not configured")
+		}
+		if c.ChatID == "" {
+			return errors.New("channel.chat_id required")
+		}
+	case Email:
+		if n.Mailer == nil {
+			return errors.New("email channels are not configured")
+		}
+		if _, err := mail.ParseAddress(c.Email); err != nil {
+			return errors.New("channel.email must be an email address")
+		}
+	default:
+		return fmt.Errorf("channel.type must be %s, %s, %s, %s or %s", Webhook, Slack, Discord, Telegram, Email)
+	}
+	return nil
+}
+
+// Notify delivers e to its alert's channel
+func (n *Channels) Notify(e Event) {
+	n.send(e.Alert.Channel, "Alert "+e.Alert.ID, e.Alert.ID, Message(e), e)
+}
+
+// Send delivers text to a chat channel, or payload as JSON to a webhook.
+// name identifies the delivery in logs, e.g. "Alert 1f2e".
+func (n *Channels) Send(c Channel, name, text string, payload interface{}) {
+	n.send(c, name, "", text, payload)
+}
+
+// send delivers to a channel on behalf of the alert with id alertID, if
+// any
+func (n *Channels) send(c Channel, name, alertID, text string, payload interface{}) {
+	var body interface{}
+	switch c.Type {
+	case Webhook:
+		body = payload
+	case Slack:
+		body = map[string]string{"text": text}
+	case Discord:
+		body = map[string]string{"content": text}
+	case Telegram:
+		body = map[string]string{"chat_id": c.ChatID, "text": text}
+	case Email:
+		if n.Mailer != nil {
+			n.Mailer.Queue(c.Email, Mail{Name: name, Text: text, Payload: payload})
+		}
+		return
+	default:
+		return
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return
+	}
+	n.start(&Delivery{Name: name, Alert: alertID, Channel: c, Payload: data})
+}
+
+// Replay removes a dead letter and delivers its payload again as a new
+// delivery, retried by its channel's policy
+func (n *Channels) Replay(id string) (Delivery, error) {
+	if n.Deliveries == nil {
+		return Delivery{}, fmt.Errorf("%w: %s", ErrNoDeadLetter, id)
+	}
+	dead, err := n.Deliveries.Remove(id)
+	if err != nil {
+		return Delivery{}, err
+	}
+	return n.start(&Delivery{Name: dead.Name, Alert: dead.Alert, Channel: dead.Channel, Payload: dead.Payload}), nil
+}
+
+// start records a delivery and tries it in the background, returning it
+// as started
+func (n *Channels) start(d *Delivery) Delivery {
+	now := time.Now().UTC()
+	d.Status, d.Attempts, d.CreatedAt, d.UpdatedAt = Pending, []Attempt{}, now, now
+	if n.Deliveries != nil {
+		if err := n.Deliveries.start(d); err != nil {
+			log.Printf("%s %s delivery: %v", d.Name, d.Channel.Type, err)
+		}
+	}
+	started := *d
+	go n.deliver(d)
+	return started
+}
+
+// deliver tries a delivery until the channel accepts it, rejects it or
+// the attempts run out
+func (n *Channels) deliver(d *Delivery) {
+	policy := retryPolicy(d.Channel, n.Retry)
+	backoff := policy.Backoff
+	for attempt := 1; ; attempt++ {
+		a, retry := n.post(d)
+		status := Pending
+		switch {
+		case a.Error == "":
+			status = Delivered
+		case !retry || attempt >= policy.Attempts:
+			status = Failed
+			log.Printf("%s %s delivery failed after %d attempts: %s", d.Name, d.Channel.Type, attempt, a.Error)
+		default:
+			log.Printf("%s %s delivery: %s; retrying in %v", d.Name, d.Channel.Type, a.Error, backoff)
+		}
+		if n.Deliveries != nil {
+			if err := n.Deliveries.attempted(d, a, status); err != nil {
+				log.Printf("Saving dead letters: %v", err)
+			}
+		}
+		if status != Pending {
+			return
+		}
+		time.Sleep(backoff)
+		backoff = min(2*backoff, MaxBackoff)
+	}
+}
+
+// post makes one attempt of a delivery, reporting whether a failure is
+// worth retrying: it isn't if the channel rejected the payload
+func (n *Channels) post(d *Delivery) (Attempt, bool) {
+	a := Attempt{Time: time.Now().UTC()}
+	target := d.Channel.URL
+	if d.Channel.Type == Telegram {
+		target = n.TelegramURL + "/bot" + n.telegramToken + "/sendMessage"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", target, bytes.NewReader(d.Payload))
+	if err != nil {
+		a.Error = deliveryError(err)
+		return a, false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		a.Error = deliveryError(err)
+		return a, true
+	}
+	resp.Body.Close()
+	a.Status = resp.StatusCode
+	if resp.StatusCode < 300 {
+		return a, false
+	}
+	a.Error = fmt.Sprintf("status %d", resp.StatusCode)
+	code := resp.StatusCode
+	return a, code >= 500 || code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+}
+
+// deliveryError describes a failed request without its URL, since the
+// Telegram URL carries the bot token
+func deliveryError(err error) string {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err.Error()
+	}
+	return err.Error()
+}
+
+// Message formats an event for chat channels, e.g. "bitcoin rose above
+// 100000 USD: now 100250 USD (+3.1% in 24h)"
+func Message(e Event) string {
+	a := e.Alert
+	verb := "rose above"
+	if a.Direction == Below {
+		verb = "fell below"
+	}
+	cur := strings.ToUpper(a.Currency)
+	return fmt.Sprintf("%s %s %s %s: now %s %s (%+.1f%% in 24h)",
+		a.Token, verb, formatPrice(a.Threshold), cur, formatPrice(e.Price), cur, e.Change24h)
+}
+
+// formatPrice prints a price with enough precision for sub-cent tokens
+func formatPrice(p float64) string {
+	if p >= 1 {
+		return strconv.FormatFloat(p, 'f', 2, 64)
+	}
+	return strconv.FormatFloat(p, 'g', 4, 64)
+}
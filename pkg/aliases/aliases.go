@@ -0,0 +1,262 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package aliases maps alternative token ids, such as old ids renamed
+// upstream, to the ids providers know
+package aliases
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Table maps aliases to token ids. Aliases from the config file can be
+// added to or replaced at runtime; runtime changes are saved to a file if
+// one is set.
+type Table struct {
+	mu      sync.RWMutex
+	file    string
+	aliases map[string]string
+	added   map[string]string // runtime aliases, saved to file
+}
+
+// NewTable creates a table from the configured aliases, overlaid with
+// those saved in file if it exists. The result must be free of chains, as
+// Set requires.
+func NewTable(configured map[string]string, file string) (*Table, error) {
+	t := &Table{file: file, aliases: make(map[string]string), added: make(map[string]string)}
+	for alias, id := range configured {
+		t.aliases[normalize(alias)] = normalize(id)
+	}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err == nil {
+			if err := json.Unmarshal(data, &t.added); err != nil {
+				return nil, fmt.Errorf("aliases file %s: %w", file, err)
+			}
+		}
+		for alias, id := range t.added {
+			t.aliases[alias] = id
+		}
+	}
+	if err := checkChains(t.aliases); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// checkChains returns an error if an alias is empty, stands for itself or
+// stands for another alias
+func checkChains(aliases map[string]string) error {
+	for alias, id := range aliases {
+		switch {
+		case alias == "" || id == "":
+			return errors.New("alias and id required")
+		case alias == id:
+			return fmt.Errorf("alias %s and its id must differ", alias)
+		}
+		if _, ok := aliases[id]; ok {
+			return fmt.Errorf("alias %s stands for %s, which is itself an alias", alias, id)
+		}
+	}
+	return nil
+}
+
+func normalize(id string) string {
+	return strings.ToLower(strings.TrimSpace(id))
+}
+
+// Resolve returns the token id an alias stands for, or id itself if it
+// is not an alias
+func (t *Table) Resolve(id string) string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if target, ok := t.aliases[normalize(id)]; ok {
+		return target
+	}
+	return id
+}
+
+// All returns a copy of every alias and its token id
+func (t *Table) All() map[string]string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	all := make(map[string]string, len(t.aliases))
+	for alias, id := range t.aliases {
+		all[alias] = id
+	}
+	return all
+}
+
+// Suggest returns up to n aliases and token ids in the table that look
+// like id, closest first, for answering a lookup of an unknown token
+func (t *Table) Suggest(id string, n int) []string {
+	id = normalize(id)
+	t.mu.RLock()
+	names := make(map[string]bool, 2*len(t.aliases))
+	for alias, target := range t.aliases {
+		names[alias] = true
+		names[target] = true
+	}
+	t.mu.RUnlock()
+
+	type match struct {
+		name string
+		dist int
+	}
+	limit := max(1, len(id)/3)
+	var matches []match
+	for name := range names {
+		if name == id {
+			continue
+		}
+		d := distance(id, name)
+		if d > limit && !(len(id) >= 3 && (strings.HasPrefix(name, id) || strings.HasPrefix(id, name))) {
+			continue
+		}
+		matches = append(matches, match{name, d})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].dist != matches[j].dist {
+			return matches[i].dist < matches[j].dist
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	suggestions := make([]string, 0, min(n, len(matches)))
+	for _, m := range matches[:min(n, len(matches))] {
+		suggestions = append(suggestions, m.name)
+	}
+	return suggestions
+}
+
+// distance is the Levenshtein edit distance between a and b
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// Set makes alias stand for id. Aliases do not chain: id may not itself
+// be an alias, and alias may not be the target of another alias.
+func (t *Table) Set(alias, id string) error {
+	alias, id = normalize(alias), normalize(id)
+	if alias == "" || id == "" {
+		return errors.New("alias and id required")
+	}
+	if alias == id {
+		return errors.New("alias and id must differ")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.aliases[id]; ok {
+		return fmt.Errorf("%s is itself an alias", id)
+	}
+	for other, target := range t.aliases {
+		if target == alias && other != alias {
+			return fmt.Errorf("%s is the target of alias %s", alias, other)
+		}
+	}
+	t.aliases[alias] = id
+	t.added[alias] = id
+	return t.save()
+}
+
+// Import sets several aliases at once, as Set does, and returns how many
+// were added or changed. The table must stay free of chains once all are
+// set; if it would not, nothing is set. With dryRun nothing is set either
+// way.
+func (t *Table) Import(aliases map[string]string, dryRun bool) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	next := make(map[string]string, len(t.aliases)+len(aliases))
+	for alias, id := range t.aliases {
+		next[alias] = id
+	}
+	changed := make(map[string]string)
+	for alias, id := range aliases {
+		alias, id = normalize(alias), normalize(id)
+		switch {
+		case alias == "" || id == "":
+			return 0, errors.New("alias and id required")
+		case alias == id:
+			return 0, fmt.Errorf("alias %s and its id must differ", alias)
+		}
+		if next[alias] != id {
+			changed[alias] = id
+		}
+		next[alias] = id
+	}
+	if err := checkChains(next); err != nil {
+		return 0, err
+	}
+	if dryRun || len(changed) == 0 {
+		return len(changed), nil
+	}
+
+	prev := make(map[string]string, len(t.added))
+	for alias, id := range t.added {
+		prev[alias] = id
+	}
+	for alias, id := range changed {
+		t.added[alias] = id
+	}
+	if err := t.save(); err != nil {
+		t.added = prev
+		return 0, err
+	}
+	t.aliases = next
+	return len(changed), nil
+}
+
+// save writes the runtime aliases to the file, replacing it atomically.
+// The caller holds t.mu.
+func (t *Table) save() error {
+	if t.file == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(t.added, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(t.file), filepath.Base(t.file)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), t.file)
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package aliases_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/luxfi/pricing/pkg/aliases"
+)
+
+func TestNewTableLoads(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "aliases.json")
+	if err := os.WriteFile(file, []byte(`{"xbt":"bitcoin","eth":"ethereum-classic"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// The saved aliases overlay the configured ones
+	table, err := aliases.NewTable(map[string]string{" BTC ": "Bitcoin", "eth": "ethereum"}, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"btc": "bitcoin", "xbt": "bitcoin", "eth": "ethereum-classic"}
+	if got := table.All(); !reflect.DeepEqual(got, want) {
+		t.Errorf("All() = %v, want %v", got, want)
+	}
+	for id, want := range map[string]string{"BTC": "bitcoin", "xbt": "bitcoin", "solana": "solana"} {
+		if got := table.Resolve(id); got != want {
+			t.Errorf("Resolve(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestNewTableMissingFile(t *testing.T) {
+	table, err := aliases.NewTable(map[string]string{"btc": "bitcoin"}, filepath.Join(t.TempDir(), "aliases.json"))
+	if err != nil {
+		t.Fatalf("NewTable with no file yet: %v", err)
+	}
+	if got := table.Resolve("btc"); got != "bitcoin" {
+		t.Errorf("Resolve(btc) = %q, want bitcoin", got)
+	}
+}
+
+func TestNewTableRejects(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured map[string]string
+		saved      string
+	}{
+		{"invalid file", nil, `["btc"]`},
+		{"chain", map[string]string{"xbt": "btc", "btc": "bitcoin"}, ""},
+		{"cycle", map[string]string{"btc": "xbt", "xbt": "btc"}, ""},
+		{"self", map[string]string{"btc": "BTC"}, ""},
+		{"chain through the file", map[string]string{"btc": "bitcoin"}, `{"xbt":"btc"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := ""
+			if tt.saved != "" {
+				file = filepath.Join(t.TempDir(), "aliases.json")
+				if err := os.WriteFile(file, []byte(tt.saved), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := aliases.NewTable(tt.configured, file); err == nil {
+				t.Error("NewTable succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestSetSaves(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "aliases.json")
+	table, err := aliases.NewTable(map[string]string{"btc": "bitcoin"}, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Set("ETH", "ethereum"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only runtime aliases are saved; a reload gets the rest from config
+	reloaded, err := aliases.NewTable(nil, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := reloaded.All(), map[string]string{"eth": "ethereum"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("reloaded %v, want %v", got, want)
+	}
+}
+
+func TestSetRejectsChains(t *testing.T) {
+	table, err := aliases.NewTable(map[string]string{"btc": "bitcoin"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name, alias, id string
+	}{
+		{"empty", "", "bitcoin"},
+		{"self", "eth", "ETH"},
+		{"id is an alias", "xbt", "btc"},
+		{"alias is a target", "bitcoin", "btc-legacy"},
+	}
+	for _, tt := range tests {
+		if err := table.Set(tt.alias, tt.id); err == nil {
+			t.Errorf("%s: Set(%q, %q) succeeded", tt.name, tt.alias, tt.id)
+		}
+	}
+	// Repointing an alias is not a chain
+	if err := table.Set("btc", "wrapped-bitcoin"); err != nil {
+		t.Errorf("repointing btc: %v", err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	table, err := aliases.NewTable(map[string]string{"btc": "bitcoin"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := table.Import(map[string]string{"btc": "bitcoin", "eth": "ethereum"}, true)
+	if err != nil || n != 1 {
+		t.Fatalf("dry run = %d, %v; want 1 change", n, err)
+	}
+	if got := table.Resolve("eth"); got != "eth" {
+		t.Errorf("dry run set eth to %q", got)
+	}
+
+	// Conflicting entries leave the table as it was
+	if _, err := table.Import(map[string]string{"eth": "ethereum", "ether": "eth"}, false); err == nil {
+		t.Error("Import of a chain succeeded")
+	}
+	if _, err := table.Import(map[string]string{"bitcoin": "btc"}, false); err == nil {
+		t.Error("Import of a cycle succeeded")
+	}
+	if got, want := table.All(), map[string]string{"btc": "bitcoin"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after failed imports %v, want %v", got, want)
+	}
+
+	// An import may repoint an alias in the same batch that makes its old
+	// target an alias
+	n, err = table.Import(map[string]string{"btc": "wrapped-bitcoin", "bitcoin": "wrapped-bitcoin"}, false)
+	if err != nil || n != 2 {
+		t.Fatalf("Import = %d, %v; want 2 changes", n, err)
+	}
+	if got := table.Resolve("bitcoin"); got != "wrapped-bitcoin" {
+		t.Errorf("Resolve(bitcoin) = %q, want wrapped-bitcoin", got)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	table, err := aliases.NewTable(map[string]string{"btc": "bitcoin", "eth": "ethereum"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := table.Suggest("bitcon", 3), []string{"bitcoin"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest(bitcon) = %v, want %v", got, want)
+	}
+	if got := table.Suggest("solana", 3); len(got) != 0 {
+		t.Errorf("Suggest(solana) = %v, want none", got)
+	}
+}
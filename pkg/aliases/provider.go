@@ -0,0 +1,75 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package aliases
+
+import (
+	"context"
+
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// Provider wraps a price provider so aliases are fetched as the ids they
+// stand for, while prices keep the id they were requested as
+type Provider struct {
+	inner providers.Provider
+	table *Table
+}
+
+// NewProvider resolves aliases in table before calling inner
+func NewProvider(inner providers.Provider, table *Table) *Provider {
+	return &Provider{inner: inner, table: table}
+}
+
+// Name identifies the wrapped provider
+func (p *Provider) Name() string {
+	return p.inner.Name()
+}
+
+// FetchPrice fetches the price of the token an alias stands for
+func (p *Provider) FetchPrice(ctx context.Context, tokenID, currency string) (*providers.Price, error) {
+	price, err := p.inner.FetchPrice(ctx, p.table.Resolve(tokenID), currency)
+	if err != nil {
+		return nil, err
+	}
+	price.ID = tokenID
+	return price, nil
+}
+
+// FetchPrices fetches each token once, however many of the requested ids
+// stand for it, and returns a price per requested id
+func (p *Provider) FetchPrices(ctx context.Context, tokenIDs []string, currency string) ([]providers.Price, error) {
+	requested := make(map[string][]string, len(tokenIDs))
+	ids := make([]string, 0, len(tokenIDs))
+	for _, id := range tokenIDs {
+		target := p.table.Resolve(id)
+		if _, ok := requested[target]; !ok {
+			ids = append(ids, target)
+		}
+		requested[target] = append(requested[target], id)
+	}
+
+	fetched, err := p.inner.FetchPrices(ctx, ids, currency)
+	prices := make([]providers.Price, 0, len(tokenIDs))
+	for _, price := range fetched {
+		as, ok := requested[price.ID]
+		if !ok {
+			prices = append(prices, price)
+			continue
+		}
+		for _, id := range as {
+			price.ID = id
+			prices = append(prices, price)
+		}
+	}
+	return prices, err
+}
+
+// Fetcher wraps a history fetcher so aliases are fetched as the ids they
+// stand for
+func Fetcher(fetch history.Fetcher, table *Table) history.Fetcher {
+	return func(ctx context.Context, tokenID, currency string, days int) (*providers.MarketChart, error) {
+		return fetch(ctx, table.Resolve(tokenID), currency, days)
+	}
+}
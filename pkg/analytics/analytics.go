@@ -0,0 +1,222 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package analytics computes statistics over token price history.
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// ErrInsufficientHistory is returned when tokens share too few days of
+// prices to compute a statistic from
+var ErrInsufficientHistory = errors.New("insufficient price history")
+
+// HistoryFunc returns a token's price history, e.g. history.Service.History
+type HistoryFunc func(ctx context.Context, tokenID, currency string, days int) (*history.Series, error)
+
+// Service computes analytics from price history
+type Service struct {
+	history      HistoryFunc
+	riskFreeRate float64
+
+	// Indices returns the constituent weights of a named index, e.g.
+	// index.Service.Weights, so indices can serve as benchmarks
+	Indices func(name string) (map[string]float64, error)
+}
+
+// NewService creates an analytics service; riskFreeRate is the annual
+// rate Sharpe ratios are measured against, e.g. 0.04
+func NewService(history HistoryFunc, riskFreeRate float64) *Service {
+	return &Service{history: history, riskFreeRate: riskFreeRate}
+}
+
+// dailyClose is a token's last price on a UTC day
+type dailyClose struct {
+	Day   time.Time
+	Price float64
+}
+
+// closes fetches the daily closes of each token over the last days,
+// concurrently
+func (s *Service) closes(ctx context.Context, ids []string, currency string, days int) ([][]dailyClose, error) {
+	out := make([][]dailyClose, len(ids))
+	errs := make([]error, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			series, err := s.history(ctx, id, currency, days)
+			if errors.Is(err, providers.ErrTokenNotFound) {
+				errs[i] = err
+				return
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("%s history: %w", id, err)
+				return
+			}
+			out[i] = dailyCloses(series.Points)
+		}(i, id)
+	}
+	wg.Wait()
+	return out, errors.Join(errs...)
+}
+
+// dailyCloses reduces points to the last price of each UTC day, oldest
+// first
+func dailyCloses(points []history.Point) []dailyClose {
+	var out []dailyClose
+	for _, p := range points {
+		day := p.Time.UTC().Truncate(24 * time.Hour)
+		if n := len(out); n > 0 && out[n-1].Day.Equal(day) {
+			out[n-1].Price = p.Price
+			continue
+		}
+		out = append(out, dailyClose{Day: day, Price: p.Price})
+	}
+	return out
+}
+
+// alignedReturns returns the daily returns of each token over the days
+// all of them have closes for
+func alignedReturns(closes [][]dailyClose) [][]float64 {
+	_, prices := alignedCloses(closes)
+	out := make([][]float64, len(prices))
+	for i, p := range prices {
+		for d := 1; d < len(p); d++ {
+			if p[d-1] == 0 {
+				out[i] = append(out[i], 0)
+				continue
+			}
+			out[i] = append(out[i], p[d]/p[d-1]-1)
+		}
+	}
+	return out
+}
+
+// alignedCloses returns the days all tokens have closes for, oldest
+// first, and each token's closes on them
+func alignedCloses(closes [][]dailyClose) ([]time.Time, [][]float64) {
+	count := make(map[time.Time]int)
+	for _, cs := range closes {
+		for _, c := range cs {
+			count[c.Day]++
+		}
+	}
+	var days []time.Time
+	for day, n := range count {
+		if n == len(closes) {
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	prices := make([][]float64, len(closes))
+	for i, cs := range closes {
+		byDay := make(map[time.Time]float64, len(cs))
+		for _, c := range cs {
+			byDay[c.Day] = c.Price
+		}
+		prices[i] = make([]float64, len(days))
+		for d, day := range days {
+			prices[i][d] = byDay[day]
+		}
+	}
+	return days, prices
+}
+
+// pearson is the correlation of two equally long samples, or false if
+// either has no variance
+func pearson(x, y []float64) (float64, bool) {
+	n := float64(len(x))
+	if len(x) < 2 || len(x) != len(y) {
+		return 0, false
+	}
+	var mx, my float64
+	for i := range x {
+		mx += x[i]
+		my += y[i]
+	}
+	mx, my = mx/n, my/n
+	var cov, vx, vy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return 0, false
+	}
+	return cov / math.Sqrt(vx*vy), true
+}
+
+const (
+	// MaxCorrelationIDs is the most tokens in one correlation matrix
+	MaxCorrelationIDs = 20
+
+	// MinCorrelationDays is the shortest correlation period, leaving
+	// enough daily returns to correlate
+	MinCorrelationDays = minObservations + 1
+
+	// minObservations is the fewest common daily returns a correlation
+	// is computed from
+	minObservations = 5
+)
+
+// Correlation is the pairwise correlation of tokens' daily returns
+type Correlation = wire.Correlation
+
+// Correlation correlates the daily returns of ids over the last days,
+// using only the days every token has a price for
+func (s *Service) Correlation(ctx context.Context, ids []string, currency string, days int) (*Correlation, error) {
+	switch {
+	case len(ids) < 2 || len(ids) > MaxCorrelationIDs:
+		return nil, fmt.Errorf("between 2 and %d ids required", MaxCorrelationIDs)
+	case days < MinCorrelationDays || days > history.MaxDays:
+		return nil, fmt.Errorf("days must be between %d and %d", MinCorrelationDays, history.MaxDays)
+	}
+	currency = strings.ToLower(currency)
+
+	closes, err := s.closes(ctx, ids, currency, days)
+	if err != nil {
+		return nil, err
+	}
+	returns := alignedReturns(closes)
+
+	c := &Correlation{
+		Currency:     currency,
+		Days:         days,
+		IDs:          ids,
+		Matrix:       make([][]*float64, len(ids)),
+		Observations: len(returns[0]),
+		UpdatedAt:    time.Now().UTC(),
+	}
+	if c.Observations < minObservations {
+		return nil, fmt.Errorf("%w: %d daily returns common to every token, %d required", ErrInsufficientHistory, c.Observations, minObservations)
+	}
+	for i := range ids {
+		c.Matrix[i] = make([]*float64, len(ids))
+	}
+	for i := range ids {
+		for j := i; j < len(ids); j++ {
+			if r, ok := pearson(returns[i], returns[j]); ok {
+				r := r
+				c.Matrix[i][j], c.Matrix[j][i] = &r, &r
+			}
+		}
+	}
+	return c, nil
+}
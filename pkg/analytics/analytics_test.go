@@ -0,0 +1,127 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package analytics_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/analytics"
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// start is the first day of every fixed series
+var start = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// closes is a token's daily closes from start, skipping days that are NaN
+type closes []float64
+
+// fixedHistory answers every token in series with its closes and any
+// other token as not found
+func fixedHistory(series map[string]closes) analytics.HistoryFunc {
+	return func(ctx context.Context, tokenID, currency string, days int) (*history.Series, error) {
+		cs, ok := series[tokenID]
+		if !ok {
+			return nil, providers.ErrTokenNotFound
+		}
+		s := &history.Series{ID: tokenID, Currency: currency, Days: days}
+		for d, p := range cs {
+			if !math.IsNaN(p) {
+				s.Points = append(s.Points, history.Point{Time: start.AddDate(0, 0, d), Price: p})
+			}
+		}
+		return s, nil
+	}
+}
+
+// fromReturns are closes from 100 moving by each daily return in turn
+func fromReturns(rs ...float64) closes {
+	cs := closes{100}
+	for _, r := range rs {
+		cs = append(cs, cs[len(cs)-1]*(1+r))
+	}
+	return cs
+}
+
+// near reports whether got is within float rounding of want
+func near(got *float64, want float64) bool {
+	return got != nil && math.Abs(*got-want) < 1e-9
+}
+
+func TestCorrelation(t *testing.T) {
+	x := fromReturns(0.1, -0.1, 0.05, 0, -0.05)
+	// x's returns less their mean of 0, and y's less theirs of 0.01:
+	//   dx 0.1, -0.1, 0.05, 0, -0.05
+	//   dy 0.01, -0.01, 0, 0.02, -0.02
+	// cov 0.003, var 0.025 and 0.001: r = 0.003 / √(0.025 × 0.001) = 0.6
+	y := fromReturns(0.02, 0, 0.01, 0.03, -0.01)
+	svc := analytics.NewService(fixedHistory(map[string]closes{
+		"x":       x,
+		"y":       y,
+		"inverse": fromReturns(-0.1, 0.1, -0.05, 0, 0.05),
+		"flat":    {5, 5, 5, 5, 5, 5},
+	}), 0)
+
+	c, err := svc.Correlation(context.Background(), []string{"x", "y", "inverse", "flat"}, "USD", 30)
+	if err != nil {
+		t.Fatalf("Correlation: %v", err)
+	}
+	if c.Observations != 5 || c.Currency != "usd" {
+		t.Errorf("observations, currency = %d, %s; want 5, usd", c.Observations, c.Currency)
+	}
+	want := [][]float64{
+		{1, 0.6, -1},
+		{0.6, 1, -0.6},
+		{-1, -0.6, 1},
+	}
+	for i := range want {
+		for j := range want[i] {
+			if !near(c.Matrix[i][j], want[i][j]) {
+				t.Errorf("matrix[%d][%d] = %v, want %v", i, j, c.Matrix[i][j], want[i][j])
+			}
+		}
+	}
+	// A price that never moves correlates with nothing
+	for i := range c.IDs {
+		if c.Matrix[3][i] != nil || c.Matrix[i][3] != nil {
+			t.Errorf("flat correlates with %s: %v", c.IDs[i], c.Matrix[3][i])
+		}
+	}
+
+	// gappy has a close a day before x's first, which is left out
+	svc = analytics.NewService(fixedHistory(map[string]closes{
+		"x":     append(closes{math.NaN()}, x...),
+		"gappy": append(closes{7}, y...),
+	}), 0)
+	c, err = svc.Correlation(context.Background(), []string{"x", "gappy"}, "usd", 30)
+	if err != nil {
+		t.Fatalf("Correlation with a gap: %v", err)
+	}
+	if c.Observations != 5 || !near(c.Matrix[0][1], 0.6) {
+		t.Errorf("correlation with a gap = %v over %d returns, want 0.6 over 5", c.Matrix[0][1], c.Observations)
+	}
+}
+
+func TestCorrelationTooFewDays(t *testing.T) {
+	svc := analytics.NewService(fixedHistory(map[string]closes{
+		"x": fromReturns(0.1, -0.1, 0.05, 0),
+		"y": fromReturns(0.02, 0, 0.01, 0.03),
+	}), 0)
+	if _, err := svc.Correlation(context.Background(), []string{"x", "y"}, "usd", 30); !errors.Is(err, analytics.ErrInsufficientHistory) {
+		t.Errorf("Correlation of 4 returns: %v, want ErrInsufficientHistory", err)
+	}
+	if _, err := svc.Correlation(context.Background(), []string{"x"}, "usd", 30); err == nil {
+		t.Errorf("Correlation of one token succeeded, want an error")
+	}
+	if _, err := svc.Correlation(context.Background(), []string{"x", "y"}, "usd", analytics.MinCorrelationDays-1); err == nil {
+		t.Errorf("Correlation over too few days succeeded, want an error")
+	}
+	if _, err := svc.Correlation(context.Background(), []string{"x", "nosuchcoin"}, "usd", 30); !errors.Is(err, providers.ErrTokenNotFound) {
+		t.Errorf("Correlation with an unknown token: %v, want ErrTokenNotFound", err)
+	}
+}
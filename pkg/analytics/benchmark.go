@@ -0,0 +1,137 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// IndexPrefix marks a benchmark that is a configured index, e.g.
+// index:defi
+const IndexPrefix = "index:"
+
+// ErrNoIndices is returned for index benchmarks when no indices are
+// configured
+var ErrNoIndices = errors.New("indices not configured")
+
+// TrackingPoint is a token and its benchmark at a daily close, both
+// rebased to 100 at the start of the period
+type TrackingPoint = wire.TrackingPoint
+
+// Relative is a token's performance against a benchmark
+type Relative = wire.RelativePerformance
+
+// Relative compares a token's daily closes over the last days with a
+// benchmark token, or with a configured index given as index:<name>,
+// whose value is the weighted sum of its constituents' closes
+func (s *Service) Relative(ctx context.Context, tokenID, benchmark, currency string, days int) (*Relative, error) {
+	if days < MinCorrelationDays || days > history.MaxDays {
+		return nil, fmt.Errorf("days must be between %d and %d", MinCorrelationDays, history.MaxDays)
+	}
+	currency = strings.ToLower(currency)
+
+	// The token comes first, then the benchmark's constituents
+	ids := []string{tokenID}
+	weights := []float64{1}
+	if name, ok := strings.CutPrefix(benchmark, IndexPrefix); ok {
+		if s.Indices == nil {
+			return nil, ErrNoIndices
+		}
+		basket, err := s.Indices(name)
+		if err != nil {
+			return nil, err
+		}
+		tokens := make([]string, 0, len(basket))
+		for token := range basket {
+			tokens = append(tokens, token)
+		}
+		sort.Strings(tokens)
+		for _, token := range tokens {
+			ids = append(ids, token)
+			weights = append(weights, basket[token])
+		}
+	} else {
+		ids = append(ids, benchmark)
+		weights = append(weights, 1)
+	}
+
+	closes, err := s.closes(ctx, ids, currency, days)
+	if err != nil {
+		return nil, err
+	}
+	dates, prices := alignedCloses(closes)
+	if len(dates) < minObservations+1 {
+		return nil, fmt.Errorf("%w: %d daily closes common to %s and %s, %d required",
+			ErrInsufficientHistory, len(dates), tokenID, benchmark, minObservations+1)
+	}
+	token := prices[0]
+	bench := make([]float64, len(dates))
+	for i := 1; i < len(ids); i++ {
+		for d, p := range prices[i] {
+			bench[d] += weights[i] * p
+		}
+	}
+	if token[0] <= 0 || bench[0] <= 0 {
+		return nil, fmt.Errorf("%w: no price at the start of the period", ErrInsufficientHistory)
+	}
+
+	rt, rb := returns(token), returns(bench)
+	if len(rt) != len(rb) {
+		return nil, fmt.Errorf("%w: zero prices in the period", ErrInsufficientHistory)
+	}
+	excess := make([]float64, len(rt))
+	for i := range rt {
+		excess[i] = rt[i] - rb[i]
+	}
+	_, te, _ := meanStdDev(excess)
+
+	last := len(dates) - 1
+	rel := &Relative{
+		Token:           tokenID,
+		Benchmark:       benchmark,
+		Currency:        currency,
+		Days:            days,
+		Return:          (token[last]/token[0] - 1) * 100,
+		BenchmarkReturn: (bench[last]/bench[0] - 1) * 100,
+		TrackingError:   te * math.Sqrt(daysPerYear),
+		Observations:    len(rt),
+		Series:          make([]TrackingPoint, len(dates)),
+		UpdatedAt:       time.Now().UTC(),
+	}
+	rel.Excess = rel.Return - rel.BenchmarkReturn
+	for d, day := range dates {
+		p := TrackingPoint{Time: day, Token: token[d] / token[0] * 100, Benchmark: bench[d] / bench[0] * 100}
+		if p.Benchmark > 0 {
+			p.Relative = p.Token / p.Benchmark * 100
+		}
+		rel.Series[d] = p
+	}
+
+	mt, _, _ := meanStdDev(rt)
+	mb, sb, _ := meanStdDev(rb)
+	if sb > 0 {
+		var cov float64
+		for i := range rt {
+			cov += (rt[i] - mt) * (rb[i] - mb)
+		}
+		cov /= float64(len(rt) - 1)
+		beta := cov / (sb * sb)
+		rf := s.riskFreeRate / daysPerYear
+		alpha := ((mt - rf) - beta*(mb-rf)) * daysPerYear
+		rel.Beta, rel.Alpha = &beta, &alpha
+		if c, ok := pearson(rt, rb); ok {
+			rel.Correlation = &c
+		}
+	}
+	return rel, nil
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// Indicator names, each computed over a period of daily closes, e.g.
+// sma_50
+const (
+	SMA = "sma" // simple moving average
+	EMA = "ema" // exponential moving average
+	RSI = "rsi" // Wilder's relative strength index, 0 to 100
+)
+
+// DefaultIndicators are computed when none are requested
+var DefaultIndicators = []string{"sma_50", "sma_200", "rsi_14"}
+
+const (
+	// MaxIndicators is the most indicators in one request
+	MaxIndicators = 20
+
+	// MaxPeriod is the longest indicator period, in days
+	MaxPeriod = 200
+
+	// warmup is how many periods of closes EMA and RSI are seeded over
+	// before the latest value, so it barely depends on where history
+	// starts
+	warmup = 3
+)
+
+// Indicator is a parsed indicator name
+type Indicator struct {
+	Name   string
+	Period int
+}
+
+// String returns the indicator's name, e.g. sma_50
+func (i Indicator) String() string {
+	return fmt.Sprintf("%s_%d", i.Name, i.Period)
+}
+
+// days is how many days of closes the indicator is computed from
+func (i Indicator) days() int {
+	if i.Name == SMA {
+		return i.Period
+	}
+	return min(warmup*i.Period, history.MaxDays)
+}
+
+// ParseIndicator parses an indicator name such as sma_50 or rsi_14
+func ParseIndicator(s string) (Indicator, error) {
+	name, period, ok := strings.Cut(s, "_")
+	if !ok {
+		return Indicator{}, fmt.Errorf("invalid indicator %s, want <name>_<period>", s)
+	}
+	switch name {
+	case SMA, EMA, RSI:
+	default:
+		return Indicator{}, fmt.Errorf("unknown indicator %s", s)
+	}
+	n, err := strconv.Atoi(period)
+	if err != nil || n < 2 || n > MaxPeriod {
+		return Indicator{}, fmt.Errorf("indicator %s: period must be between 2 and %d", s, MaxPeriod)
+	}
+	return Indicator{Name: name, Period: n}, nil
+}
+
+// Indicators are technical indicators of a token's daily closes
+type Indicators = wire.Indicators
+
+// Indicators computes the indicators of a token from one fetch of daily
+// closes covering the longest period
+func (s *Service) Indicators(ctx context.Context, tokenID, currency string, indicators []Indicator) (*Indicators, error) {
+	if len(indicators) == 0 || len(indicators) > MaxIndicators {
+		return nil, fmt.Errorf("between 1 and %d indicators required", MaxIndicators)
+	}
+	currency = strings.ToLower(currency)
+	days := 0
+	for _, ind := range indicators {
+		days = max(days, ind.days())
+	}
+
+	closes, err := s.closes(ctx, []string{tokenID}, currency, days)
+	if err != nil {
+		return nil, err
+	}
+	prices := make([]float64, len(closes[0]))
+	for i, c := range closes[0] {
+		prices[i] = c.Price
+	}
+
+	out := &Indicators{
+		Token:      tokenID,
+		Currency:   currency,
+		Indicators: make(map[string]*float64, len(indicators)),
+		UpdatedAt:  time.Now().UTC(),
+	}
+	if len(prices) > 0 {
+		out.Price = prices[len(prices)-1]
+	}
+	for _, ind := range indicators {
+		var v float64
+		var ok bool
+		switch ind.Name {
+		case SMA:
+			v, ok = sma(prices, ind.Period)
+		case EMA:
+			v, ok = ema(prices, ind.Period)
+		case RSI:
+			v, ok = rsi(prices, ind.Period)
+		}
+		if ok {
+			v := v
+			out.Indicators[ind.String()] = &v
+		} else {
+			out.Indicators[ind.String()] = nil
+		}
+	}
+	return out, nil
+}
+
+// sma is the mean of the last n prices
+func sma(prices []float64, n int) (float64, bool) {
+	if len(prices) < n {
+		return 0, false
+	}
+	var sum float64
+	for _, p := range prices[len(prices)-n:] {
+		sum += p
+	}
+	return sum / float64(n), true
+}
+
+// ema is the exponential moving average of prices with smoothing 2/(n+1),
+// seeded with the SMA of the first n
+func ema(prices []float64, n int) (float64, bool) {
+	v, ok := sma(prices[:min(n, len(prices))], n)
+	if !ok {
+		return 0, false
+	}
+	k := 2 / float64(n+1)
+	for _, p := range prices[n:] {
+		v += k * (p - v)
+	}
+	return v, true
+}
+
+// rsi is Wilder's relative strength index of prices over n periods,
+// seeded with the mean gain and loss of the first n changes
+func rsi(prices []float64, n int) (float64, bool) {
+	if len(prices) < n+1 {
+		return 0, false
+	}
+	var gain, loss float64
+	for i := 1; i <= n; i++ {
+		gain += max(prices[i]-prices[i-1], 0)
+		loss += max(prices[i-1]-prices[i], 0)
+	}
+	gain, loss = gain/float64(n), loss/float64(n)
+	for i := n + 1; i < len(prices); i++ {
+		gain = (gain*float64(n-1) + max(prices[i]-prices[i-1], 0)) / float64(n)
+		loss = (loss*float64(n-1) + max(prices[i-1]-prices[i], 0)) / float64(n)
+	}
+	if gain+loss == 0 {
+		return 50, true
+	}
+	return 100 * gain / (gain + loss), true
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package analytics_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/luxfi/pricing/pkg/analytics"
+)
+
+func TestIndicators(t *testing.T) {
+	svc := analytics.NewService(fixedHistory(map[string]closes{
+		// Changes +1, -0.5, +1, -0.5. RSI over 3: seeded with gains of 2/3
+		// and losses of 1/6 a day, then smoothed by the last change to
+		// 4/9 and 5/18, so 100 × (4/9) / (4/9 + 5/18) = 800/13.
+		// EMA over 3, k = 0.5: seeded at 10.5, then 11, 11.
+		"token":  {10, 11, 10.5, 11.5, 11},
+		"rising": {1, 2, 3, 4, 5},
+		"flat":   {5, 5, 5, 5, 5},
+		"short":  {10, 11, 12},
+	}), 0)
+	indicators := []analytics.Indicator{
+		{Name: analytics.RSI, Period: 3},
+		{Name: analytics.SMA, Period: 3},
+		{Name: analytics.EMA, Period: 3},
+	}
+
+	tests := []struct {
+		token string
+		price float64
+		want  map[string]float64 // missing: null
+	}{
+		{token: "token", price: 11, want: map[string]float64{"rsi_3": 800.0 / 13, "sma_3": 11, "ema_3": 11}},
+		// Only gains: 100
+		{token: "rising", price: 5, want: map[string]float64{"rsi_3": 100, "sma_3": 4, "ema_3": 4}},
+		// No change at all: 50
+		{token: "flat", price: 5, want: map[string]float64{"rsi_3": 50, "sma_3": 5, "ema_3": 5}},
+		// Three changes are needed for RSI over 3, and there are two
+		{token: "short", price: 12, want: map[string]float64{"sma_3": 11, "ema_3": 11}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			out, err := svc.Indicators(context.Background(), tt.token, "usd", indicators)
+			if err != nil {
+				t.Fatalf("Indicators: %v", err)
+			}
+			if out.Price != tt.price {
+				t.Errorf("price = %v, want %v", out.Price, tt.price)
+			}
+			for name, got := range out.Indicators {
+				want, ok := tt.want[name]
+				switch {
+				case !ok && got != nil:
+					t.Errorf("%s = %v, want null", name, *got)
+				case ok && !near(got, want):
+					t.Errorf("%s = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
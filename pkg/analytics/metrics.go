@@ -0,0 +1,169 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// Metric names, each computed over a window of days, e.g. volatility_30d
+const (
+	Volatility  = "volatility"   // annualized standard deviation of daily returns
+	MaxDrawdown = "max_drawdown" // largest fall from a peak close, as a fraction
+	Sharpe      = "sharpe"       // annualized excess daily return per unit of volatility
+)
+
+// DefaultMetrics are computed when none are requested
+var DefaultMetrics = []string{"volatility_30d", "max_drawdown_90d", "sharpe_180d"}
+
+// MaxMetrics is the most metrics in one request
+const MaxMetrics = 20
+
+// daysPerYear annualizes daily statistics; tokens trade every day
+const daysPerYear = 365
+
+// Metric is a parsed metric name
+type Metric struct {
+	Name string
+	Days int
+}
+
+// String returns the metric's name, e.g. volatility_30d
+func (m Metric) String() string {
+	return fmt.Sprintf("%s_%dd", m.Name, m.Days)
+}
+
+// ParseMetric parses a metric name such as volatility_30d or
+// max_drawdown_90d
+func ParseMetric(s string) (Metric, error) {
+	i := strings.LastIndexByte(s, '_')
+	if i < 0 || !strings.HasSuffix(s, "d") {
+		return Metric{}, fmt.Errorf("invalid metric %s, want <name>_<days>d", s)
+	}
+	m := Metric{Name: s[:i]}
+	switch m.Name {
+	case Volatility, MaxDrawdown, Sharpe:
+	default:
+		return Metric{}, fmt.Errorf("unknown metric %s", s)
+	}
+	days, err := strconv.Atoi(s[i+1 : len(s)-1])
+	if err != nil || days < 2 || days > history.MaxDays {
+		return Metric{}, fmt.Errorf("metric %s: days must be between 2 and %d", s, history.MaxDays)
+	}
+	m.Days = days
+	return m, nil
+}
+
+// Metrics are risk metrics of a token's daily closes
+type Metrics = wire.RiskMetrics
+
+// Metrics computes the metrics of a token from one fetch of daily closes
+// covering the longest window
+func (s *Service) Metrics(ctx context.Context, tokenID, currency string, metrics []Metric) (*Metrics, error) {
+	if len(metrics) == 0 || len(metrics) > MaxMetrics {
+		return nil, fmt.Errorf("between 1 and %d metrics required", MaxMetrics)
+	}
+	currency = strings.ToLower(currency)
+	days := 0
+	for _, m := range metrics {
+		days = max(days, m.Days)
+	}
+
+	closes, err := s.closes(ctx, []string{tokenID}, currency, days)
+	if err != nil {
+		return nil, err
+	}
+	prices := make([]float64, len(closes[0]))
+	for i, c := range closes[0] {
+		prices[i] = c.Price
+	}
+
+	out := &Metrics{
+		Token:        tokenID,
+		Currency:     currency,
+		RiskFreeRate: s.riskFreeRate,
+		Metrics:      make(map[string]*float64, len(metrics)),
+		UpdatedAt:    time.Now().UTC(),
+	}
+	for _, m := range metrics {
+		// A window of n days spans n daily returns between n+1 closes
+		window := prices[max(0, len(prices)-m.Days-1):]
+		var v float64
+		var ok bool
+		switch m.Name {
+		case Volatility:
+			if _, sd, n := meanStdDev(returns(window)); n >= 2 {
+				v, ok = sd*math.Sqrt(daysPerYear), true
+			}
+		case MaxDrawdown:
+			v, ok = maxDrawdown(window)
+		case Sharpe:
+			if mean, sd, n := meanStdDev(returns(window)); n >= 2 && sd > 0 {
+				v, ok = (mean-s.riskFreeRate/daysPerYear)/sd*math.Sqrt(daysPerYear), true
+			}
+		}
+		if ok {
+			v := v
+			out.Metrics[m.String()] = &v
+		} else {
+			out.Metrics[m.String()] = nil
+		}
+	}
+	return out, nil
+}
+
+// returns are the simple returns between consecutive prices
+func returns(prices []float64) []float64 {
+	var out []float64
+	for i := 1; i < len(prices); i++ {
+		if prices[i-1] > 0 {
+			out = append(out, prices[i]/prices[i-1]-1)
+		}
+	}
+	return out
+}
+
+// meanStdDev returns the mean and sample standard deviation of xs
+func meanStdDev(xs []float64) (mean, sd float64, n int) {
+	n = len(xs)
+	if n == 0 {
+		return 0, 0, 0
+	}
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(n)
+	if n < 2 {
+		return mean, 0, n
+	}
+	var ss float64
+	for _, x := range xs {
+		ss += (x - mean) * (x - mean)
+	}
+	return mean, math.Sqrt(ss / float64(n-1)), n
+}
+
+// maxDrawdown is the largest fall from a running peak, as a fraction of
+// the peak
+func maxDrawdown(prices []float64) (float64, bool) {
+	if len(prices) < 2 {
+		return 0, false
+	}
+	var peak, dd float64
+	for _, p := range prices {
+		peak = max(peak, p)
+		if peak > 0 {
+			dd = max(dd, (peak-p)/peak)
+		}
+	}
+	return dd, true
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package analytics_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/luxfi/pricing/pkg/analytics"
+)
+
+func TestMetrics(t *testing.T) {
+	svc := analytics.NewService(fixedHistory(map[string]closes{
+		// Returns 0.1, -0.1, 0.1, -0.1: mean 0, sample variance 0.04/3
+		"swing": fromReturns(0.1, -0.1, 0.1, -0.1),
+		// Returns 0.1, 0, 0.1, 0: mean 0.05, sample variance 0.01/3
+		"climb": fromReturns(0.1, 0, 0.1, 0),
+		"flat":  {5, 5, 5, 5, 5},
+		"two":   {100, 110},
+		"one":   {100},
+	}), 0.0365) // 0.0001 a day
+
+	tests := []struct {
+		token string
+		want  map[string]float64 // missing: null
+	}{
+		{
+			token: "swing",
+			want: map[string]float64{
+				"volatility_4d": math.Sqrt(0.04/3) * math.Sqrt(365),
+				"sharpe_4d":     -0.0001 / math.Sqrt(0.04/3) * math.Sqrt(365),
+				// From the peak of 110 to 98.01
+				"max_drawdown_4d": (110 - 98.01) / 110,
+				// The last two returns only, 0.1 and -0.1: variance 0.02
+				"volatility_2d": math.Sqrt(0.02) * math.Sqrt(365),
+			},
+		},
+		{
+			token: "climb",
+			want: map[string]float64{
+				"volatility_4d":   math.Sqrt(0.01/3) * math.Sqrt(365),
+				"sharpe_4d":       (0.05 - 0.0001) / math.Sqrt(0.01/3) * math.Sqrt(365),
+				"max_drawdown_4d": 0,
+				"volatility_2d":   math.Sqrt(0.005) * math.Sqrt(365),
+			},
+		},
+		{
+			// No variance: no volatility, and no Sharpe ratio to divide by it
+			token: "flat",
+			want:  map[string]float64{"volatility_4d": 0, "max_drawdown_4d": 0, "volatility_2d": 0},
+		},
+		{
+			// One return: no standard deviation
+			token: "two",
+			want:  map[string]float64{"max_drawdown_4d": 0},
+		},
+		{
+			token: "one",
+			want:  map[string]float64{},
+		},
+	}
+	metrics := []analytics.Metric{
+		{Name: analytics.Volatility, Days: 4},
+		{Name: analytics.Sharpe, Days: 4},
+		{Name: analytics.MaxDrawdown, Days: 4},
+		{Name: analytics.Volatility, Days: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			m, err := svc.Metrics(context.Background(), tt.token, "usd", metrics)
+			if err != nil {
+				t.Fatalf("Metrics: %v", err)
+			}
+			if len(m.Metrics) != len(metrics) || m.RiskFreeRate != 0.0365 {
+				t.Errorf("metrics = %v at %v, want %d at 0.0365", m.Metrics, m.RiskFreeRate, len(metrics))
+			}
+			for name, got := range m.Metrics {
+				want, ok := tt.want[name]
+				switch {
+				case !ok && got != nil:
+					t.Errorf("%s = %v, want null", name, *got)
+				case ok && !near(got, want):
+					t.Errorf("%s = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseMetric(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    analytics.Metric
+		wantErr bool
+	}{
+		{in: "volatility_30d", want: analytics.Metric{Name: analytics.Volatility, Days: 30}},
+		{in: "max_drawdown_90d", want: analytics.Metric{Name: analytics.MaxDrawdown, Days: 90}},
+		{in: "sharpe_365d", want: analytics.Metric{Name: analytics.Sharpe, Days: 365}},
+		{in: "sharpe_1d", wantErr: true},
+		{in: "sharpe_366d", wantErr: true},
+		{in: "sharpe_30", wantErr: true},
+		{in: "sortino_30d", wantErr: true},
+		{in: "volatility", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := analytics.ParseMetric(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseMetric(%q) = %+v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want || got.String() != tt.in {
+			t.Errorf("ParseMetric(%q) = %+v, %v, want %+v", tt.in, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// DefaultPeriods are the returns computed when none are requested
+var DefaultPeriods = []string{"1d", "7d", "30d", "90d", "1y"}
+
+// MaxPeriods is the most return periods in one request
+const MaxPeriods = 20
+
+// Period is a parsed return period
+type Period struct {
+	Name string // as requested, e.g. 1y
+	Days int
+}
+
+// ParsePeriod parses a return period of days, weeks or years, e.g. 1d,
+// 2w or 1y, up to a year
+func ParsePeriod(s string) (Period, error) {
+	if len(s) < 2 {
+		return Period{}, fmt.Errorf("invalid period %s, want e.g. 7d, 2w or 1y", s)
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n < 1 {
+		return Period{}, fmt.Errorf("invalid period %s, want e.g. 7d, 2w or 1y", s)
+	}
+	p := Period{Name: s}
+	switch s[len(s)-1] {
+	case 'd':
+		p.Days = n
+	case 'w':
+		p.Days = 7 * n
+	case 'y':
+		p.Days = 365 * n
+	default:
+		return Period{}, fmt.Errorf("invalid period %s, want e.g. 7d, 2w or 1y", s)
+	}
+	if p.Days > history.MaxDays {
+		return Period{}, fmt.Errorf("period %s: at most %d days", s, history.MaxDays)
+	}
+	return p, nil
+}
+
+// Returns are a token's price changes over periods ending now
+type Returns = wire.Returns
+
+// Returns computes a token's percent returns over periods ending at its
+// latest price. Each period is measured on the finest history covering
+// it, e.g. 5-minutely for 1d and hourly up to 90d.
+func (s *Service) Returns(ctx context.Context, tokenID, currency string, periods []Period) (*Returns, error) {
+	if len(periods) == 0 || len(periods) > MaxPeriods {
+		return nil, fmt.Errorf("between 1 and %d periods required", MaxPeriods)
+	}
+	currency = strings.ToLower(currency)
+
+	series := make(map[int]*history.Series)
+	for _, p := range periods {
+		series[p.Days] = nil
+	}
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	for days := range series {
+		wg.Add(1)
+		go func(days int) {
+			defer wg.Done()
+			got, err := s.history(ctx, tokenID, currency, days)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, providers.ErrTokenNotFound):
+				errs = append(errs, err)
+			case err != nil:
+				errs = append(errs, fmt.Errorf("%s history: %w", tokenID, err))
+			default:
+				series[days] = got
+			}
+		}(days)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	// The shortest period's series ends at the most recent price
+	shortest := periods[0].Days
+	for _, p := range periods {
+		shortest = min(shortest, p.Days)
+	}
+	points := series[shortest].Points
+	if len(points) == 0 {
+		return nil, &providers.NotFoundError{Token: tokenID}
+	}
+	end := points[len(points)-1]
+
+	out := &Returns{
+		Token:     tokenID,
+		Currency:  currency,
+		Price:     end.Price,
+		Returns:   make(map[string]*float64, len(periods)),
+		UpdatedAt: time.Now().UTC(),
+	}
+	for _, p := range periods {
+		out.Returns[p.Name] = nil
+		length := time.Duration(p.Days) * 24 * time.Hour
+		start, ok := priceAt(series[p.Days].Points, end.Time.Add(-length), min(length/10, 24*time.Hour))
+		if ok && start > 0 {
+			r := (end.Price/start - 1) * 100
+			out.Returns[p.Name] = &r
+		}
+	}
+	return out, nil
+}
+
+// priceAt returns the price at t: the last point at or before it, or the
+// first point if it follows t by at most slack
+func priceAt(points []history.Point, t time.Time, slack time.Duration) (float64, bool) {
+	i := sort.Search(len(points), func(i int) bool { return points[i].Time.After(t) })
+	switch {
+	case i > 0:
+		return points[i-1].Price, true
+	case len(points) > 0 && points[0].Time.Sub(t) <= slack:
+		return points[0].Price, true
+	}
+	return 0, false
+}
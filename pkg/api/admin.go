@@ -0,0 +1,245 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/audit"
+	"github.com/luxfi/pricing/pkg/config"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+type adminActorKey struct{}
+
+// adminActor returns the authenticated admin actor for a request
+func adminActor(r *http.Request) string {
+	actor, _ := r.Context().Value(adminActorKey{}).(string)
+	return actor
+}
+
+// remoteIP returns the client address without the port
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// requireAdmin authenticates admin requests by bearer token
+func (s *Server) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminKeys := s.current().adminKeys
+		if len(adminKeys) == 0 {
+			http.Error(w, `{"error":"admin API disabled"}`, http.StatusNotFound)
+			return
+		}
+
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		for key, actor := range adminKeys {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(key)) == 1 {
+				ctx := context.WithValue(r.Context(), adminActorKey{}, actor)
+				next(w, r.WithContext(ctx))
+				return
+			}
+		}
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+	}
+}
+
+// audit records an admin action, logging if the write fails
+func (s *Server) audit(r *http.Request, action string, params map[string]string) error {
+	_, err := s.auditLog.Record(adminActor(r), action, remoteIP(r), params)
+	if err != nil {
+		return fmt.Errorf("audit log write failed: %w", err)
+	}
+	return nil
+}
+
+// handleCacheFlush drops cached prices, optionally for a single token
+func (s *Server) handleCacheFlush(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	tokenID := r.URL.Query().Get("token")
+	if err := s.audit(r, "cache.flush", map[string]string{"token": tokenID}); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+		return
+	}
+	flushed := s.cache.Flush(tokenID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(flushResponse{Flushed: flushed})
+}
+
+// handleCacheStats reports cache lookups since startup and its size
+func (s *Server) handleCacheStats(w http.ResponseWriter, r *http.Request) {
+	resp := cacheStatsResponse{Stats: s.cache.Stats(), TTLSeconds: int64(s.cache.TTL().Seconds())}
+	if lookups := resp.Hits + resp.Misses; lookups > 0 {
+		resp.HitRatio = float64(resp.Hits) / float64(lookups)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleAudit returns audit log entries
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := audit.Filter{
+		Actor:  q.Get("actor"),
+		Action: q.Get("action"),
+		Limit:  100,
+	}
+
+	if since := q.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			http.Error(w, `{"error":"since must be RFC3339"}`, http.StatusBadRequest)
+			return
+		}
+		filter.Since = t
+	}
+	if limit := q.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			http.Error(w, `{"error":"limit must be a positive integer"}`, http.StatusBadRequest)
+			return
+		}
+		filter.Limit = n
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(auditResponse{Entries: s.auditLog.Query(filter)})
+}
+
+// handleQuarantine lists fetched prices held back because they moved too
+// far in one refresh
+func (s *Server) handleQuarantine(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(quarantineResponse{
+		MaxChange: s.cache.MaxChange(),
+		Prices:    s.cache.Quarantined(),
+	})
+}
+
+// handleBreakers lists each price provider's circuit breaker state
+func (s *Server) handleBreakers(w http.ResponseWriter, r *http.Request) {
+	statuses := make([]providers.BreakerStatus, 0, len(s.breakers))
+	for _, b := range s.breakers {
+		statuses = append(statuses, b.Status())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(breakersResponse{Providers: statuses})
+}
+
+// handleRouting reports the latency, health and routing decisions of the
+// balanced providers, fastest first
+func (s *Server) handleRouting(w http.ResponseWriter, r *http.Request) {
+	if s.balancer == nil {
+		http.Error(w, `{"error":"no alternate providers configured"}`, http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(routingResponse{Providers: s.balancer.Status()})
+}
+
+// handleRateLimits lists upstream hosts that have answered 429 and
+// whether requests to them are paused, and the pacing of hosts with a
+// quota
+func (s *Server) handleRateLimits(w http.ResponseWriter, r *http.Request) {
+	if s.limits == nil {
+		http.Error(w, `{"error":"rate limit tracking not configured"}`, http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	resp := rateLimitsResponse{Hosts: s.limits.Status(), Schedule: []providers.ScheduleStatus{}}
+	if s.schedule != nil {
+		resp.Schedule = s.schedule.Status()
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleUpstream reports upstream requests in flight and queued
+func (s *Server) handleUpstream(w http.ResponseWriter, r *http.Request) {
+	if s.inFlight == nil {
+		http.Error(w, `{"error":"upstream request cap not configured"}`, http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(s.inFlight.Status())
+}
+
+// handleSchema reports upstream response fields that did not match their
+// expected schema
+func (s *Server) handleSchema(w http.ResponseWriter, r *http.Request) {
+	if s.schemas == nil {
+		http.Error(w, `{"error":"schema checks not configured"}`, http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(s.schemas.Status())
+}
+
+// handleOracle reports the on-chain oracle feeds and their pending updates
+func (s *Server) handleOracle(w http.ResponseWriter, r *http.Request) {
+	if s.oracle == nil {
+		http.Error(w, `{"error":"oracle pushing not configured"}`, http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(s.oracle.Status())
+}
+
+// handleReload re-reads configuration and applies it: POST /admin/reload
+func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+	if s.reload == nil {
+		http.Error(w, `{"error":"configuration reload not available"}`, http.StatusNotImplemented)
+		return
+	}
+
+	if err := s.audit(r, "config.reload", nil); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if err := s.reload(); err != nil {
+		status := http.StatusBadRequest
+		var restart *config.RestartError
+		if errors.As(err, &restart) {
+			status = http.StatusConflict
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reloadResponse{Status: "reloaded"})
+}
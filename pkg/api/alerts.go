@@ -0,0 +1,234 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/alerts"
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// maxAlertBody bounds alert request bodies
+const maxAlertBody = 16 << 10
+
+// alertsResponse lists a tenant's alerts
+type alertsResponse struct {
+	Alerts []alerts.Alert `json:"alerts"`
+}
+
+// alertTenant returns the calling tenant's name, or writes an error and
+// returns "" if the request has no API key. Alerts are private to a
+// tenant, so anonymous callers, who all share the default tenant, cannot
+// use them.
+func (s *Server) alertTenant(w http.ResponseWriter, r *http.Request) string {
+	if s.alerts == nil {
+		http.Error(w, `{"error":"alerts not configured"}`, http.StatusNotFound)
+		return ""
+	}
+	tenant := tenantFrom(r.Context())
+	if tenant == nil || tenant.Name == defaultTenantName {
+		http.Error(w, `{"error":"alerts require an API key"}`, http.StatusUnauthorized)
+		return ""
+	}
+	return tenant.Name
+}
+
+// decodeAlertSpec reads an alert spec from the request body
+func (s *Server) decodeAlertSpec(w http.ResponseWriter, r *http.Request) (alerts.Spec, bool) {
+	var spec alerts.Spec
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAlertBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"invalid alert: %s"}`, err.Error()), http.StatusBadRequest)
+		return spec, false
+	}
+	if err := alerts.ValidateSpec(&spec); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"invalid alert: %s"}`, err.Error()), http.StatusBadRequest)
+		return spec, false
+	}
+	return spec, s.checkTokensAllowed(w, r, spec.Token)
+}
+
+// writeAlertError maps store errors to status codes
+func writeAlertError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, alerts.ErrNotFound):
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusNotFound)
+	case errors.Is(err, alerts.ErrLimit):
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusConflict)
+	default:
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+	}
+}
+
+// handleListAlerts returns the calling tenant's alerts
+func (s *Server) handleListAlerts(w http.ResponseWriter, r *http.Request) {
+	tenant := s.alertTenant(w, r)
+	if tenant == "" {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(alertsResponse{Alerts: s.alerts.List(tenant)})
+}
+
+// handleCreateAlert registers an alert for the calling tenant
+func (s *Server) handleCreateAlert(w http.ResponseWriter, r *http.Request) {
+	tenant := s.alertTenant(w, r)
+	if tenant == "" {
+		return
+	}
+	spec, ok := s.decodeAlertSpec(w, r)
+	if !ok {
+		return
+	}
+
+	alert, err := s.alerts.Create(tenant, spec)
+	if err != nil {
+		writeAlertError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/"+APIVersion+"/alerts/"+alert.ID)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(alert)
+}
+
+// alertID parses the id from /alerts/{id}
+func alertID(w http.ResponseWriter, r *http.Request) string {
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/alerts/"), "/")
+	if id == "" {
+		http.Error(w, `{"error":"alert id required"}`, http.StatusBadRequest)
+	}
+	return id
+}
+
+// handleGetAlert returns one of the calling tenant's alerts
+func (s *Server) handleGetAlert(w http.ResponseWriter, r *http.Request) {
+	tenant := s.alertTenant(w, r)
+	if tenant == "" {
+		return
+	}
+	id := alertID(w, r)
+	if id == "" {
+		return
+	}
+
+	alert, err := s.alerts.Get(tenant, id)
+	if err != nil {
+		writeAlertError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(alert)
+}
+
+// handleUpdateAlert replaces an alert's condition and channel
+func (s *Server) handleUpdateAlert(w http.ResponseWriter, r *http.Request) {
+	tenant := s.alertTenant(w, r)
+	if tenant == "" {
+		return
+	}
+	id := alertID(w, r)
+	if id == "" {
+		return
+	}
+	spec, ok := s.decodeAlertSpec(w, r)
+	if !ok {
+		return
+	}
+
+	alert, err := s.alerts.Update(tenant, id, spec)
+	if err != nil {
+		writeAlertError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(alert)
+}
+
+// handleDeleteAlert removes one of the calling tenant's alerts
+func (s *Server) handleDeleteAlert(w http.ResponseWriter, r *http.Request) {
+	tenant := s.alertTenant(w, r)
+	if tenant == "" {
+		return
+	}
+	id := alertID(w, r)
+	if id == "" {
+		return
+	}
+
+	if err := s.alerts.Delete(tenant, id); err != nil {
+		writeAlertError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// alertTestRequest is an alert condition to backtest over the last Days.
+// The channel, if given, is ignored.
+type alertTestRequest = wire.AlertTestRequest
+
+// handleTestAlert replays an alert condition against price history and
+// returns when it would have fired
+func (s *Server) handleTestAlert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+	if s.alertTenant(w, r) == "" {
+		return
+	}
+	if s.history == nil {
+		http.Error(w, `{"error":"price history not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	var req alertTestRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAlertBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"invalid alert: %s"}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	if err := alerts.ValidateSpec(&req.AlertSpec); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"invalid alert: %s"}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	if req.Days == 0 {
+		req.Days = 30
+	}
+	if req.Days < 1 || req.Days > history.MaxDays {
+		http.Error(w, fmt.Sprintf(`{"error":"days must be between 1 and %d"}`, history.MaxDays), http.StatusBadRequest)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, req.Token) {
+		return
+	}
+
+	series, err := s.history.History(r.Context(), req.Token, req.Currency, req.Days)
+	if errors.Is(err, providers.ErrTokenNotFound) {
+		s.writeNotFound(w, req.Token, err)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching %s history: %v", req.Token, err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(alerts.Replay(req.AlertSpec, series, s.alerts.Policy()))
+}
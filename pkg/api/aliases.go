@@ -0,0 +1,52 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// handleAliases lists token id aliases: GET /admin/aliases
+func (s *Server) handleAliases(w http.ResponseWriter, r *http.Request) {
+	if s.aliases == nil {
+		http.Error(w, `{"error":"aliases not configured"}`, http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(aliasesResponse{Aliases: s.aliases.All()})
+}
+
+// handleSetAlias adds or replaces an alias and drops prices and not-found
+// results cached under it: POST /admin/aliases?alias=matic&id=polygon-ecosystem-token
+func (s *Server) handleSetAlias(w http.ResponseWriter, r *http.Request) {
+	if s.aliases == nil {
+		http.Error(w, `{"error":"aliases not configured"}`, http.StatusNotFound)
+		return
+	}
+	q := r.URL.Query()
+	alias, id := q.Get("alias"), q.Get("id")
+	if alias == "" || id == "" {
+		http.Error(w, `{"error":"alias and id required"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := s.audit(r, "aliases.set", map[string]string{"alias": alias, "id": id}); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if err := s.aliases.Set(alias, id); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	s.cache.Flush(alias)
+	if s.history != nil {
+		s.history.Forget(alias)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(aliasesResponse{Aliases: s.aliases.All()})
+}
@@ -0,0 +1,313 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/analytics"
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/index"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// handleAnalytics returns risk metrics of a token's daily closes
+func (s *Server) handleAnalytics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+	if s.analytics == nil {
+		http.Error(w, `{"error":"analytics not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	token := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/analytics/"), "/")
+	if vs, ok := strings.CutSuffix(token, "/vs"); ok {
+		s.handleRelative(w, r, vs)
+		return
+	}
+	if token == "" || strings.Contains(token, "/") {
+		http.Error(w, `{"error":"token id required"}`, http.StatusBadRequest)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, token) {
+		return
+	}
+
+	q := r.URL.Query()
+	names := analytics.DefaultMetrics
+	if v := q.Get("metrics"); v != "" {
+		names = strings.Split(v, ",")
+	}
+	if len(names) > analytics.MaxMetrics {
+		http.Error(w, fmt.Sprintf(`{"error":"at most %d metrics"}`, analytics.MaxMetrics), http.StatusBadRequest)
+		return
+	}
+	metrics := make([]analytics.Metric, len(names))
+	for i, name := range names {
+		m, err := analytics.ParseMetric(strings.TrimSpace(name))
+		if err != nil {
+			http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+			return
+		}
+		metrics[i] = m
+	}
+	currency := queryCurrency(q)
+
+	m, err := s.analytics.Metrics(r.Context(), token, currency, metrics)
+	if errors.Is(err, providers.ErrTokenNotFound) {
+		s.writeNotFound(w, token, err)
+		return
+	}
+	if err != nil {
+		log.Printf("Error computing %s analytics: %v", token, err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m)
+}
+
+// handleRelative returns a token's performance against a benchmark token
+// or index; it serves /analytics/{token}/vs
+func (s *Server) handleRelative(w http.ResponseWriter, r *http.Request, token string) {
+	if token == "" || strings.Contains(token, "/") {
+		http.Error(w, `{"error":"token id required"}`, http.StatusBadRequest)
+		return
+	}
+
+	q := r.URL.Query()
+	benchmark := strings.ToLower(q.Get("benchmark"))
+	if benchmark == "" {
+		benchmark = "bitcoin"
+	}
+	tokens := []string{token}
+	if name, ok := strings.CutPrefix(benchmark, analytics.IndexPrefix); ok {
+		if s.indices == nil {
+			http.Error(w, `{"error":"indices not configured"}`, http.StatusNotFound)
+			return
+		}
+		constituents, err := s.indices.Tokens(name)
+		if errors.Is(err, index.ErrNotFound) {
+			http.Error(w, fmt.Sprintf(`{"error":"index not found: %s"}`, name), http.StatusNotFound)
+			return
+		}
+		tokens = append(tokens, constituents...)
+	} else {
+		tokens = append(tokens, benchmark)
+	}
+	if !s.checkTokensAllowed(w, r, tokens...) {
+		return
+	}
+
+	days := 90
+	if v := q.Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < analytics.MinCorrelationDays || n > history.MaxDays {
+			http.Error(w, fmt.Sprintf(`{"error":"days must be between %d and %d"}`, analytics.MinCorrelationDays, history.MaxDays), http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	currency := queryCurrency(q)
+
+	rel, err := s.analytics.Relative(r.Context(), token, benchmark, currency, days)
+	if errors.Is(err, providers.ErrTokenNotFound) {
+		s.writeNotFound(w, "", err)
+		return
+	}
+	if errors.Is(err, analytics.ErrInsufficientHistory) {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error comparing %s with %s: %v", token, benchmark, err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rel)
+}
+
+// handleIndicators returns technical indicators of a token's daily closes
+func (s *Server) handleIndicators(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+	if s.analytics == nil {
+		http.Error(w, `{"error":"indicators not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	token := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/indicators/"), "/")
+	if token == "" {
+		http.Error(w, `{"error":"token id required"}`, http.StatusBadRequest)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, token) {
+		return
+	}
+
+	q := r.URL.Query()
+	names := analytics.DefaultIndicators
+	if v := q.Get("set"); v != "" {
+		names = strings.Split(v, ",")
+	}
+	if len(names) > analytics.MaxIndicators {
+		http.Error(w, fmt.Sprintf(`{"error":"at most %d indicators"}`, analytics.MaxIndicators), http.StatusBadRequest)
+		return
+	}
+	indicators := make([]analytics.Indicator, len(names))
+	for i, name := range names {
+		ind, err := analytics.ParseIndicator(strings.TrimSpace(name))
+		if err != nil {
+			http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+			return
+		}
+		indicators[i] = ind
+	}
+	currency := queryCurrency(q)
+
+	ind, err := s.analytics.Indicators(r.Context(), token, currency, indicators)
+	if errors.Is(err, providers.ErrTokenNotFound) {
+		s.writeNotFound(w, token, err)
+		return
+	}
+	if err != nil {
+		log.Printf("Error computing %s indicators: %v", token, err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ind)
+}
+
+// handleReturns returns a token's percent returns over periods ending
+// now
+func (s *Server) handleReturns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+	if s.analytics == nil {
+		http.Error(w, `{"error":"returns not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	token := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/returns/"), "/")
+	if token == "" {
+		http.Error(w, `{"error":"token id required"}`, http.StatusBadRequest)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, token) {
+		return
+	}
+
+	q := r.URL.Query()
+	names := analytics.DefaultPeriods
+	if v := q.Get("periods"); v != "" {
+		names = strings.Split(v, ",")
+	}
+	if len(names) > analytics.MaxPeriods {
+		http.Error(w, fmt.Sprintf(`{"error":"at most %d periods"}`, analytics.MaxPeriods), http.StatusBadRequest)
+		return
+	}
+	periods := make([]analytics.Period, len(names))
+	for i, name := range names {
+		p, err := analytics.ParsePeriod(strings.TrimSpace(name))
+		if err != nil {
+			http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+			return
+		}
+		periods[i] = p
+	}
+	currency := queryCurrency(q)
+
+	ret, err := s.analytics.Returns(r.Context(), token, currency, periods)
+	if errors.Is(err, providers.ErrTokenNotFound) {
+		s.writeNotFound(w, token, err)
+		return
+	}
+	if err != nil {
+		log.Printf("Error computing %s returns: %v", token, err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+// handleCorrelation returns the pairwise correlation of tokens' daily
+// returns over the last days
+func (s *Server) handleCorrelation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+	if s.analytics == nil {
+		http.Error(w, `{"error":"analytics not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	q := r.URL.Query()
+	ids := q.Get("ids")
+	if ids == "" {
+		http.Error(w, `{"error":"ids query parameter required"}`, http.StatusBadRequest)
+		return
+	}
+	tokenIDs := strings.Split(ids, ",")
+	if len(tokenIDs) < 2 || len(tokenIDs) > analytics.MaxCorrelationIDs {
+		http.Error(w, fmt.Sprintf(`{"error":"between 2 and %d ids required"}`, analytics.MaxCorrelationIDs), http.StatusBadRequest)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, tokenIDs...) {
+		return
+	}
+
+	days := 90
+	if v := q.Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < analytics.MinCorrelationDays || n > history.MaxDays {
+			http.Error(w, fmt.Sprintf(`{"error":"days must be between %d and %d"}`, analytics.MinCorrelationDays, history.MaxDays), http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	currency := queryCurrency(q)
+
+	c, err := s.analytics.Correlation(r.Context(), tokenIDs, currency, days)
+	if errors.Is(err, providers.ErrTokenNotFound) {
+		s.writeNotFound(w, "", err)
+		return
+	}
+	if errors.Is(err, analytics.ErrInsufficientHistory) {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error correlating %s: %v", ids, err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
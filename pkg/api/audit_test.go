@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/internal/testutil"
+	"github.com/luxfi/pricing/pkg/audit"
+)
+
+func TestAdminAudit(t *testing.T) {
+	srv := testutil.NewServer(t)
+	srv.Provider.Set("bitcoin", 65000)
+	start := time.Now().UTC().Add(-time.Second)
+
+	for _, token := range []string{"bitcoin", "ethereum", ""} {
+		if code, body := srv.Do(t, http.MethodPost, "/admin/cache/flush?token="+token, nil, true); code != http.StatusOK {
+			t.Fatalf("flush %q: %d %s", token, code, body)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string // token params, newest first
+	}{
+		{"all", "", []string{"", "ethereum", "bitcoin"}},
+		{"action", "?action=cache.flush", []string{"", "ethereum", "bitcoin"}},
+		{"actor", "?actor=test", []string{"", "ethereum", "bitcoin"}},
+		{"other actor", "?actor=someone", []string{}},
+		{"other action", "?action=config.reload", []string{}},
+		{"limit", "?limit=2", []string{"", "ethereum"}},
+		{"since", "?since=" + url.QueryEscape(start.Format(time.RFC3339)), []string{"", "ethereum", "bitcoin"}},
+		{"since later", "?since=" + url.QueryEscape(start.Add(time.Hour).Format(time.RFC3339)), []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := srv.Do(t, http.MethodGet, "/admin/audit"+tt.query, nil, true)
+			if code != http.StatusOK {
+				t.Fatalf("status %d: %s", code, body)
+			}
+			var resp struct {
+				Entries []audit.Entry `json:"entries"`
+			}
+			if err := json.Unmarshal(body, &resp); err != nil {
+				t.Fatalf("body %s: %v", body, err)
+			}
+			if resp.Entries == nil {
+				t.Fatalf("body %s: entries is null, want an array", body)
+			}
+			var got []string
+			for _, e := range resp.Entries {
+				if e.Actor != "test" || e.Action != "cache.flush" || e.RemoteIP == "" {
+					t.Errorf("entry = %+v, want a cache.flush by test with its address", e)
+				}
+				got = append(got, e.Params["token"])
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("tokens = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("tokens = %q, want %q", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestAdminAuditRejects(t *testing.T) {
+	srv := testutil.NewServer(t)
+
+	tests := []struct {
+		name  string
+		query string
+		admin bool
+		want  int
+	}{
+		{"no admin key", "", false, http.StatusUnauthorized},
+		{"bad since", "?since=yesterday", true, http.StatusBadRequest},
+		{"zero limit", "?limit=0", true, http.StatusBadRequest},
+		{"bad limit", "?limit=ten", true, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, body := srv.Do(t, http.MethodGet, "/admin/audit"+tt.query, nil, tt.admin); code != tt.want {
+				t.Errorf("status %d (%s), want %d", code, body, tt.want)
+			}
+		})
+	}
+}
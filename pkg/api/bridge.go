@@ -0,0 +1,90 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/bridge"
+)
+
+// bridgeErrorResponse lists why each pair has no rate
+type bridgeErrorResponse struct {
+	Error string            `json:"error"`
+	Pairs map[string]string `json:"pairs"`
+}
+
+// handleBridgeRates returns the bridge's exchange rates. Any pair that
+// can't be priced from prices no older than the maximum age fails the
+// whole request, so the bridge never converts at a stale rate.
+func (s *Server) handleBridgeRates(w http.ResponseWriter, r *http.Request) {
+	if s.bridge == nil {
+		http.Error(w, `{"error":"bridge rates not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	q := r.URL.Query()
+	var pairs []string
+	if v := q.Get("pairs"); v != "" {
+		pairs = strings.Split(v, ",")
+	}
+	var maxAge time.Duration
+	if v := q.Get("max_age"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, `{"error":"max_age must be a positive number of seconds"}`, http.StatusBadRequest)
+			return
+		}
+		maxAge = time.Duration(n) * time.Second
+	}
+
+	rates, err := s.bridge.Rates(r.Context(), pairs, maxAge)
+	if errors.Is(err, bridge.ErrUnknownPair) {
+		writeParamError(w, "pairs", fmt.Errorf("%w; pairs are %s", err, strings.Join(s.bridge.Pairs(), ", ")))
+		return
+	}
+	if err != nil {
+		resp := bridgeErrorResponse{Error: "rates unavailable", Pairs: make(map[string]string)}
+		for _, e := range unjoin(err) {
+			var (
+				pe    *bridge.PairError
+				stale *bridge.StaleError
+			)
+			if !errors.As(e, &pe) {
+				continue
+			}
+			if errors.As(pe.Err, &stale) {
+				resp.Pairs[pe.Pair] = stale.Error()
+			} else {
+				log.Printf("Error pricing bridge pair %s: %v", pe.Pair, pe.Err)
+				resp.Pairs[pe.Pair] = "price unavailable"
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(rates)
+}
+
+// unjoin returns the errors joined in err, or err alone
+func unjoin(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
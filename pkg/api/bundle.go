@@ -0,0 +1,213 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/alerts"
+)
+
+// bundleVersion is the version of configuration bundles written and read
+const bundleVersion = 1
+
+// maxBundleBody bounds imported bundles
+const maxBundleBody = 4 << 20
+
+// configBundle is the runtime configuration of a deployment, exported
+// from one and imported into another
+type configBundle struct {
+	Version    int                           `json:"version"`
+	ExportedAt time.Time                     `json:"exported_at"`
+	Aliases    map[string]string             `json:"aliases"`
+	Indices    map[string]map[string]float64 `json:"indices"` // name -> token -> weight
+	Alerts     []alerts.Alert                `json:"alerts"`
+}
+
+// bundleImportResponse counts what an import changed. Indices are set in
+// the config file, not at runtime; those that differ from the bundle are
+// listed for the operator to update.
+type bundleImportResponse struct {
+	DryRun        bool     `json:"dry_run"`
+	Aliases       int      `json:"aliases"` // added or changed
+	AlertsCreated int      `json:"alerts_created"`
+	AlertsUpdated int      `json:"alerts_updated"`
+	IndicesDiffer []string `json:"indices_differ"`
+}
+
+// handleExportBundle exports aliases, index definitions and every
+// tenant's alerts as one bundle: GET /admin/bundle
+func (s *Server) handleExportBundle(w http.ResponseWriter, r *http.Request) {
+	if err := s.audit(r, "bundle.export", nil); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	bundle := configBundle{
+		Version:    bundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Aliases:    map[string]string{},
+		Indices:    s.indexDefinitions(),
+		Alerts:     []alerts.Alert{},
+	}
+	if s.aliases != nil {
+		bundle.Aliases = s.aliases.All()
+	}
+	if s.alerts != nil {
+		bundle.Alerts = s.alerts.All()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Disposition", `attachment; filename="pricing-bundle.json"`)
+	json.NewEncoder(w).Encode(bundle)
+}
+
+// handleImportBundle applies an exported bundle: aliases and alerts are
+// added or updated, never removed, so importing twice changes nothing.
+// Everything is validated before anything is applied: POST /admin/bundle
+func (s *Server) handleImportBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+	var bundle configBundle
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBundleBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&bundle); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"invalid bundle: %s"}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	if bundle.Version != bundleVersion {
+		http.Error(w, fmt.Sprintf(`{"error":"unsupported bundle version %d, want %d"}`, bundle.Version, bundleVersion), http.StatusBadRequest)
+		return
+	}
+	switch {
+	case len(bundle.Aliases) > 0 && s.aliases == nil:
+		http.Error(w, `{"error":"aliases not configured"}`, http.StatusNotFound)
+		return
+	case len(bundle.Alerts) > 0 && s.alerts == nil:
+		http.Error(w, `{"error":"alerts not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	// Validate both parts before applying either
+	resp := bundleImportResponse{DryRun: dryRun, IndicesDiffer: s.indicesDiffer(bundle.Indices)}
+	if s.aliases != nil {
+		if _, err := s.aliases.Import(bundle.Aliases, true); err != nil {
+			http.Error(w, fmt.Sprintf(`{"error":"invalid bundle: aliases: %s"}`, err.Error()), http.StatusBadRequest)
+			return
+		}
+	}
+	if s.alerts != nil {
+		if _, _, err := s.alerts.Import(bundle.Alerts, true); err != nil {
+			http.Error(w, fmt.Sprintf(`{"error":"invalid bundle: %s"}`, err.Error()), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if !dryRun {
+		err := s.audit(r, "bundle.import", map[string]string{
+			"aliases": strconv.Itoa(len(bundle.Aliases)),
+			"alerts":  strconv.Itoa(len(bundle.Alerts)),
+		})
+		if err != nil {
+			http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+			return
+		}
+	}
+	var err error
+	if s.aliases != nil {
+		if resp.Aliases, err = s.aliases.Import(bundle.Aliases, dryRun); err != nil {
+			http.Error(w, fmt.Sprintf(`{"error":"aliases: %s"}`, err.Error()), http.StatusInternalServerError)
+			return
+		}
+		if !dryRun {
+			for alias := range bundle.Aliases {
+				s.cache.Flush(alias)
+				if s.history != nil {
+					s.history.Forget(alias)
+				}
+			}
+		}
+	}
+	if s.alerts != nil {
+		if resp.AlertsCreated, resp.AlertsUpdated, err = s.alerts.Import(bundle.Alerts, dryRun); err != nil {
+			http.Error(w, fmt.Sprintf(`{"error":"alerts: %s"}`, err.Error()), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// indexDefinitions returns every index's normalized weights
+func (s *Server) indexDefinitions() map[string]map[string]float64 {
+	defs := map[string]map[string]float64{}
+	if s.indices == nil {
+		return defs
+	}
+	for _, name := range s.indices.Names() {
+		if weights, err := s.indices.Weights(name); err == nil {
+			defs[name] = weights
+		}
+	}
+	return defs
+}
+
+// indicesDiffer lists, sorted, the indices defined differently here and in
+// a bundle, comparing weights once normalized
+func (s *Server) indicesDiffer(bundled map[string]map[string]float64) []string {
+	ours := make(map[string]map[string]float64)
+	for name, weights := range s.indexDefinitions() {
+		ours[strings.ToLower(name)] = weights
+	}
+	theirs := make(map[string]map[string]float64, len(bundled))
+	for name, weights := range bundled {
+		var total float64
+		for _, w := range weights {
+			total += w
+		}
+		normalized := make(map[string]float64, len(weights))
+		for token, w := range weights {
+			normalized[strings.ToLower(token)] = w / total
+		}
+		theirs[strings.ToLower(name)] = normalized
+	}
+
+	same := func(a, b map[string]float64) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for token, w := range a {
+			if v, ok := b[token]; !ok || math.Abs(v-w) > 1e-9 {
+				return false
+			}
+		}
+		return true
+	}
+	differ := []string{}
+	for name, weights := range theirs {
+		if !same(weights, ours[name]) {
+			differ = append(differ, name)
+		}
+	}
+	for name := range ours {
+		if _, ok := theirs[name]; !ok {
+			differ = append(differ, name)
+		}
+	}
+	sort.Strings(differ)
+	return differ
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+)
+
+// Endpoint names used to select cache policies
+const (
+	EndpointPrice       = "price"
+	EndpointPrices      = "prices"
+	EndpointSimplePrice = "simple_price"
+)
+
+// CachePolicy controls the Cache-Control header for an endpoint. MaxAge and
+// SMaxAge are caps: the advertised values never exceed the remaining
+// freshness of the data being served.
+type CachePolicy struct {
+	NoStore              bool
+	Private              bool
+	MaxAge               time.Duration
+	SMaxAge              time.Duration
+	StaleWhileRevalidate time.Duration
+	StaleIfError         time.Duration
+}
+
+// DefaultCachePolicies caps browser and CDN caching at the cache TTL
+func DefaultCachePolicies() map[string]CachePolicy {
+	return map[string]CachePolicy{
+		EndpointPrice:       {MaxAge: cache.DefaultTTL},
+		EndpointPrices:      {MaxAge: cache.DefaultTTL},
+		EndpointSimplePrice: {MaxAge: cache.DefaultTTL},
+	}
+}
+
+// ParseCachePolicy parses Cache-Control style directives, e.g.
+// "max-age=300, s-maxage=600, stale-while-revalidate=60"
+func ParseCachePolicy(spec string) (CachePolicy, error) {
+	var p CachePolicy
+	for _, directive := range strings.Split(spec, ",") {
+		name, value, hasValue := strings.Cut(strings.TrimSpace(directive), "=")
+		name = strings.ToLower(name)
+
+		var d time.Duration
+		if hasValue {
+			secs, err := strconv.Atoi(value)
+			if err != nil || secs < 0 {
+				return p, fmt.Errorf("invalid %s value %q", name, value)
+			}
+			d = time.Duration(secs) * time.Second
+		}
+
+		switch name {
+		case "":
+		case "no-store":
+			p.NoStore = true
+		case "private":
+			p.Private = true
+		case "public":
+		case "max-age":
+			p.MaxAge = d
+		case "s-maxage":
+			p.SMaxAge = d
+		case "stale-while-revalidate":
+			p.StaleWhileRevalidate = d
+		case "stale-if-error":
+			p.StaleIfError = d
+		default:
+			return p, fmt.Errorf("unsupported directive %q", name)
+		}
+	}
+	return p, nil
+}
+
+// header renders the policy for data with the given remaining freshness
+func (p CachePolicy) header(remaining time.Duration) string {
+	if p.NoStore {
+		return "no-store"
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	capped := func(limit time.Duration) int {
+		if remaining < limit {
+			return int(remaining.Seconds())
+		}
+		return int(limit.Seconds())
+	}
+
+	directives := []string{"public"}
+	if p.Private {
+		directives[0] = "private"
+	}
+	directives = append(directives, fmt.Sprintf("max-age=%d", capped(p.MaxAge)))
+	if p.SMaxAge > 0 && !p.Private {
+		directives = append(directives, fmt.Sprintf("s-maxage=%d", capped(p.SMaxAge)))
+	}
+	if p.StaleWhileRevalidate > 0 {
+		directives = append(directives, fmt.Sprintf("stale-while-revalidate=%d", int(p.StaleWhileRevalidate.Seconds())))
+	}
+	if p.StaleIfError > 0 {
+		directives = append(directives, fmt.Sprintf("stale-if-error=%d", int(p.StaleIfError.Seconds())))
+	}
+	return strings.Join(directives, ", ")
+}
+
+// setCacheControl sets Cache-Control for an endpoint from the age of the
+// oldest price in the response and the TTL that applies to the request
+func (s *Server) setCacheControl(w http.ResponseWriter, r *http.Request, endpoint string, oldest time.Time) {
+	policy, ok := s.current().cachePolicies[endpoint]
+	if !ok {
+		policy = CachePolicy{MaxAge: s.cache.TTL()}
+	}
+
+	remaining := cache.TTLFromContext(r.Context(), s.cache.TTL())
+	if !oldest.IsZero() {
+		remaining -= time.Since(oldest)
+	}
+	w.Header().Set("Cache-Control", policy.header(remaining))
+}
+
+// oldestUpdate returns the earliest UpdatedAt across prices
+func oldestUpdate(prices map[string]*cache.PriceResponse) time.Time {
+	var oldest time.Time
+	for _, p := range prices {
+		if oldest.IsZero() || p.UpdatedAt.Before(oldest) {
+			oldest = p.UpdatedAt
+		}
+	}
+	return oldest
+}
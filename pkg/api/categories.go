@@ -0,0 +1,20 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// handleCategories lists the token categories /markets can be filtered by
+func (s *Server) handleCategories(w http.ResponseWriter, r *http.Request) {
+	if s.categories == nil {
+		http.Error(w, `{"error":"token categories not configured"}`, http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(s.categories.List())
+}
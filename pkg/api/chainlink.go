@@ -0,0 +1,150 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// maxChainlinkBody bounds a Chainlink adapter request
+const maxChainlinkBody = 16 << 10
+
+// chainlinkRequest is a Chainlink external adapter request. The token may
+// be given as from, base or coin and the currency as to, quote or market,
+// as the common adapters accept.
+type chainlinkRequest struct {
+	ID   string          `json:"id"`
+	Data chainlinkParams `json:"data"`
+}
+
+type chainlinkParams struct {
+	From   string `json:"from,omitempty"`
+	Base   string `json:"base,omitempty"`
+	Coin   string `json:"coin,omitempty"`
+	To     string `json:"to,omitempty"`
+	Quote  string `json:"quote,omitempty"`
+	Market string `json:"market,omitempty"`
+}
+
+// chainlinkResponse is a Chainlink external adapter response
+type chainlinkResponse struct {
+	JobRunID   string          `json:"jobRunID"`
+	Data       *chainlinkData  `json:"data,omitempty"`
+	Result     json.Number     `json:"result,omitempty"`
+	StatusCode int             `json:"statusCode"`
+	Status     string          `json:"status,omitempty"` // "errored" on failure
+	Error      *chainlinkError `json:"error,omitempty"`
+}
+
+type chainlinkData struct {
+	Result json.Number `json:"result"` // exact decimal price
+	From   string      `json:"from"`
+	To     string      `json:"to"`
+	Source string      `json:"source"`
+}
+
+type chainlinkError struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+// handleChainlink prices a token in the Chainlink external adapter format,
+// so node jobs can use this service as a bridge:
+//
+//	{"id": "1", "data": {"from": "lux", "to": "usd"}}
+//	→ {"jobRunID": "1", "data": {"result": 1.23, ...}, "result": 1.23, "statusCode": 200}
+func (s *Server) handleChainlink(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req chainlinkRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxChainlinkBody)).Decode(&req); err != nil {
+		writeChainlinkError(w, "1", http.StatusBadRequest, "AdapterInputError", "invalid request: "+err.Error())
+		return
+	}
+	if req.ID == "" {
+		req.ID = "1"
+	}
+	from := strings.ToLower(firstOf(req.Data.From, req.Data.Base, req.Data.Coin))
+	to := strings.ToLower(firstOf(req.Data.To, req.Data.Quote, req.Data.Market, "usd"))
+	if from == "" {
+		writeChainlinkError(w, req.ID, http.StatusBadRequest, "AdapterInputError", "data.from required")
+		return
+	}
+	if err := checkID(from); err != nil {
+		writeChainlinkError(w, req.ID, http.StatusBadRequest, "AdapterInputError", "data.from: "+err.Error())
+		return
+	}
+	if err := checkCurrency(to); err != nil {
+		writeChainlinkError(w, req.ID, http.StatusBadRequest, "AdapterInputError", "data.to: "+err.Error())
+		return
+	}
+	if !s.checkTokensAllowed(w, r, from) {
+		return
+	}
+
+	price, err := s.cache.GetPrice(r.Context(), from, to)
+	switch {
+	case errors.Is(err, providers.ErrTokenNotFound):
+		msg := "token not found: " + from
+		if s.aliases != nil {
+			if suggestions := s.aliases.Suggest(from, maxSuggestions); len(suggestions) > 0 {
+				msg += fmt.Sprintf(" (did you mean %s?)", strings.Join(suggestions, ", "))
+			}
+		}
+		writeChainlinkError(w, req.ID, http.StatusBadRequest, "AdapterInputError", msg)
+		return
+	case errors.Is(err, providers.ErrOverloaded):
+		w.Header().Set("Retry-After", "1")
+		writeChainlinkError(w, req.ID, http.StatusServiceUnavailable, "AdapterError", "too many upstream requests; retry shortly")
+		return
+	case err != nil:
+		writeChainlinkError(w, req.ID, http.StatusBadGateway, "AdapterDataProviderError", "price unavailable")
+		return
+	}
+
+	result := json.Number(price.PriceStr)
+	if result == "" {
+		result = json.Number(fmt.Sprint(price.Price))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(chainlinkResponse{
+		JobRunID:   req.ID,
+		Data:       &chainlinkData{Result: result, From: from, To: to, Source: price.Source},
+		Result:     result,
+		StatusCode: http.StatusOK,
+	})
+}
+
+// writeChainlinkError answers in the Chainlink external adapter error
+// format, with the HTTP status matching statusCode
+func writeChainlinkError(w http.ResponseWriter, jobRunID string, code int, name, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(chainlinkResponse{
+		JobRunID:   jobRunID,
+		StatusCode: code,
+		Status:     "errored",
+		Error:      &chainlinkError{Name: name, Message: message},
+	})
+}
+
+// firstOf returns the first non-empty value
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/luxfi/pricing/pkg/changes"
+)
+
+// checkCursor accepts a cursor as returned by /changes
+func checkCursor(v string) error {
+	if err := changes.CheckCursor(v); err != nil {
+		return errors.New("since must be a cursor returned by /changes")
+	}
+	return nil
+}
+
+// handleChanges returns the prices changed since a cursor, so pollers
+// only download what moved
+func (s *Server) handleChanges(w http.ResponseWriter, r *http.Request) {
+	if s.changes == nil {
+		http.Error(w, `{"error":"changes not enabled"}`, http.StatusNotFound)
+		return
+	}
+
+	q := r.URL.Query()
+	currency := queryCurrency(q)
+	limit := changes.DefaultLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > changes.MaxLimit {
+			http.Error(w, fmt.Sprintf(`{"error":"limit must be between 1 and %d"}`, changes.MaxLimit), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	var allow func(string) bool
+	if tenant := tenantFrom(r.Context()); tenant != nil {
+		allow = tenant.Allows
+	}
+
+	out, err := s.changes.Since(q.Get("since"), currency, limit, allow)
+	if err != nil {
+		http.Error(w, `{"error":"since must be a cursor returned by /changes"}`, http.StatusBadRequest)
+		return
+	}
+	for i := range out.Prices {
+		s.addOverride(&out.Prices[i])
+		s.addRiskFlags(&out.Prices[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(out)
+}
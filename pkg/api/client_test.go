@@ -0,0 +1,308 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api_test
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/internal/testutil"
+	"github.com/luxfi/pricing/pkg/alerts"
+	"github.com/luxfi/pricing/pkg/api"
+	"github.com/luxfi/pricing/pkg/client"
+	"github.com/luxfi/pricing/pkg/decimal"
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/portfolio"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/signing"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+func TestClientPrice(t *testing.T) {
+	srv := testutil.NewServer(t)
+	srv.Provider.Set("bitcoin", 65000)
+	c := srv.Client()
+	ctx := context.Background()
+
+	price, err := c.Price(ctx, "bitcoin", "usd")
+	if err != nil {
+		t.Fatalf("Price: %v", err)
+	}
+	if price.Price != 65000 || price.PriceStr != "65000" || price.Currency != "usd" || price.Cached {
+		t.Errorf("first price = %+v, want 65000 usd fetched", price)
+	}
+
+	// Currencies are case-insensitive and share one cache entry
+	price, err = c.Price(ctx, "bitcoin", "USD")
+	if err != nil {
+		t.Fatalf("Price: %v", err)
+	}
+	if !price.Cached || price.Currency != "usd" {
+		t.Errorf("second price = %+v, want usd from cache", price)
+	}
+	if n := srv.Provider.Calls(); n != 1 {
+		t.Errorf("provider called %d times, want 1", n)
+	}
+}
+
+func TestClientPriceErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		fail     error
+		wantCode int
+		wantErr  string
+	}{
+		{name: "unknown token", wantCode: http.StatusNotFound, wantErr: api.CodeTokenNotFound},
+		{name: "upstream down", fail: errors.New("upstream down"), wantCode: http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := testutil.NewServer(t)
+			srv.Provider.FailAll(tt.fail)
+
+			_, err := srv.Client().Price(context.Background(), "nope", "usd")
+			var apiErr *client.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want an APIError", err)
+			}
+			if apiErr.StatusCode != tt.wantCode || apiErr.Code != tt.wantErr {
+				t.Errorf("err = %d %q, want %d %q", apiErr.StatusCode, apiErr.Code, tt.wantCode, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClientFlushCache(t *testing.T) {
+	srv := testutil.NewServer(t)
+	srv.Provider.Set("ethereum", 3200)
+	c := srv.Client()
+	ctx := context.Background()
+
+	if _, err := c.Price(ctx, "ethereum", "usd"); err != nil {
+		t.Fatalf("Price: %v", err)
+	}
+	flushed, err := c.FlushCache(ctx, "ethereum")
+	if err != nil {
+		t.Fatalf("FlushCache: %v", err)
+	}
+	if flushed != 1 {
+		t.Errorf("flushed %d prices, want 1", flushed)
+	}
+
+	srv.Provider.Set("ethereum", 3300)
+	price, err := c.Price(ctx, "ethereum", "usd")
+	if err != nil {
+		t.Fatalf("Price: %v", err)
+	}
+	if price.Cached || price.Price != 3300 {
+		t.Errorf("price after flush = %+v, want 3300 refetched", price)
+	}
+}
+
+func TestClientStatus(t *testing.T) {
+	srv := testutil.NewServer(t)
+
+	status, err := srv.Client().Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.Status != "ok" || status.Banner != "" {
+		t.Errorf("status = %q %q, want ok with no banner", status.Status, status.Banner)
+	}
+}
+
+func TestClientQuote(t *testing.T) {
+	domain := signing.EIP712Domain{Name: "Lux Pricing", Version: "1", ChainID: 96369}
+	srv := testutil.NewServer(t, func(o *api.Options) {
+		o.Quotes = signing.NewQuoter(signing.QuoterOptions{Domain: domain, TTL: time.Minute, Decimals: 8})
+	})
+	srv.Provider.Set("bitcoin", 65000)
+
+	quote, err := srv.Client().Quote(context.Background(), "bitcoin", "usd", false)
+	if err != nil {
+		t.Fatalf("Quote: %v", err)
+	}
+	if quote.PriceStr != "65000" || quote.Quote.Price != "6500000000000" || quote.Quote.Decimals != 8 {
+		t.Errorf("quote = %+v, want 65000 with 8 decimals", quote)
+	}
+	if quote.Domain != wire.EIP712Domain(domain) || !strings.HasPrefix(quote.Digest, "0x") || quote.Signature != "" {
+		t.Errorf("domain, digest, signature = %+v, %q, %q, want the server's domain unsigned", quote.Domain, quote.Digest, quote.Signature)
+	}
+}
+
+func TestClientSignedQuote(t *testing.T) {
+	domain := signing.EIP712Domain{Name: "Lux Pricing", Version: "1", ChainID: 96369, VerifyingContract: "0x5FbDB2315678afecb367f032d93F642f64180aa3"}
+	signer := testutil.NewQuoteSigner(t, "quote signer")
+	srv := testutil.NewServer(t, func(o *api.Options) {
+		opts := signer.Options(domain)
+		opts.TTL = time.Minute
+		o.Quotes = signing.NewQuoter(opts)
+	})
+	srv.Provider.Set("bitcoin", 65000)
+	c := srv.Client()
+	ctx := context.Background()
+
+	keys, err := c.SigningKeys(ctx)
+	if err != nil {
+		t.Fatalf("SigningKeys: %v", err)
+	}
+	var published string
+	for _, k := range keys {
+		if k.KeyID == "eip712" {
+			published = k.Address
+		}
+	}
+	if published != signer.Address {
+		t.Fatalf("published eip712 address = %q, want %s", published, signer.Address)
+	}
+
+	quote, err := c.Quote(ctx, "bitcoin", "usd", true)
+	if err != nil {
+		t.Fatalf("Quote: %v", err)
+	}
+	digest := signing.NewQuoter(signing.QuoterOptions{Domain: signing.EIP712Domain(quote.Domain)}).Digest(quote.Quote)
+	if quote.Digest != "0x"+hex.EncodeToString(digest) {
+		t.Errorf("digest = %s, want %x from the returned quote and domain", quote.Digest, digest)
+	}
+	got, err := testutil.Ecrecover(digest, quote.Signature)
+	if err != nil || got != published || quote.Signer != published {
+		t.Errorf("signature by %s recovers to %s, %v, want the published %s", quote.Signer, got, err, published)
+	}
+}
+
+func TestClientHistoryAndPortfolio(t *testing.T) {
+	fetch := func(ctx context.Context, tokenID, currency string, days int) (*providers.MarketChart, error) {
+		if tokenID != "bitcoin" {
+			return nil, providers.ErrTokenNotFound
+		}
+		chart := &providers.MarketChart{}
+		now := time.Now().UTC()
+		for d := days; d >= 0; d-- {
+			ms := float64(now.AddDate(0, 0, -d).UnixMilli())
+			chart.Prices = append(chart.Prices, [2]float64{ms, 100})
+			chart.MarketCaps = append(chart.MarketCaps, [2]float64{ms, 1e9})
+			chart.TotalVolumes = append(chart.TotalVolumes, [2]float64{ms, 1e6})
+		}
+		return chart, nil
+	}
+	hist := history.NewService(fetch, time.Minute)
+	prices := func(ctx context.Context, tokenIDs []string, currency string) (map[string]decimal.Decimal, error) {
+		return map[string]decimal.Decimal{"bitcoin": decimal.FromFloat(120)}, nil
+	}
+	srv := testutil.NewServer(t, func(o *api.Options) {
+		o.History = hist
+		o.Portfolio = portfolio.NewService(hist.History, prices)
+	})
+	c := srv.Client()
+	ctx := context.Background()
+
+	series, err := c.History(ctx, "bitcoin", "usd", 7)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if series.ID != "bitcoin" || series.Days != 7 || len(series.Points) == 0 || series.Points[0].Price != 100 {
+		t.Errorf("history = %+v, want 7 days of bitcoin at 100", series)
+	}
+
+	basis := 50.0
+	perf, err := c.PortfolioPerformance(ctx, client.PortfolioRequest{
+		Currency: "USD",
+		Days:     7,
+		Holdings: []client.PortfolioHolding{{Token: "Bitcoin", Amount: 2, CostBasis: &basis}},
+	})
+	if err != nil {
+		t.Fatalf("PortfolioPerformance: %v", err)
+	}
+	if perf.Currency != "usd" || perf.ValueStr != "240" || perf.Cost != 100 || perf.UnrealizedPnL != 140 {
+		t.Errorf("performance = %+v, want 240 usd on a cost of 100", perf)
+	}
+	if len(perf.Positions) != 1 || perf.Positions[0].Token != "bitcoin" || perf.Positions[0].PriceStr != "120" {
+		t.Errorf("positions = %+v, want bitcoin at 120", perf.Positions)
+	}
+
+	_, err = c.PortfolioPerformance(ctx, client.PortfolioRequest{})
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || !strings.Contains(apiErr.Message, "holdings required") {
+		t.Errorf("empty portfolio: %v, want 400 holdings required", err)
+	}
+}
+
+// nopNotifier accepts every channel and delivers nothing
+type nopNotifier struct{}
+
+func (nopNotifier) Validate(alerts.Channel) error { return nil }
+func (nopNotifier) Notify(alerts.Event)           {}
+
+func TestClientAlerts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenants.json")
+	if err := os.WriteFile(path, []byte(`[{"name":"wallet","api_keys":["wallet-key"]}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tenants, err := api.NewTenantRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := alerts.NewStore("", nopNotifier{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := testutil.NewServer(t, func(o *api.Options) {
+		o.Tenants = tenants
+		o.Alerts = store
+	})
+	ctx := context.Background()
+
+	var apiErr *client.APIError
+	if _, err := srv.Client().Alerts(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Alerts without a key: %v, want 401", err)
+	}
+
+	c := client.New(srv.URL, "wallet-key", client.WithRetries(0, time.Millisecond, time.Millisecond))
+	spec := client.AlertSpec{Token: "bitcoin", Direction: "above", Threshold: 100000,
+		Channel: client.AlertChannel{Type: "webhook", URL: "https://example.com/hook"}}
+	created, err := c.CreateAlert(ctx, spec)
+	if err != nil {
+		t.Fatalf("CreateAlert: %v", err)
+	}
+	if created.ID == "" || created.Tenant != "wallet" || created.Currency != "usd" || created.Channel.URL != spec.Channel.URL {
+		t.Errorf("created = %+v, want a usd alert of wallet", created)
+	}
+
+	spec.Threshold = 120000
+	updated, err := c.UpdateAlert(ctx, created.ID, spec)
+	if err != nil {
+		t.Fatalf("UpdateAlert: %v", err)
+	}
+	if updated.Threshold != 120000 {
+		t.Errorf("updated threshold = %v, want 120000", updated.Threshold)
+	}
+	got, err := c.Alert(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Alert: %v", err)
+	}
+	if got.ID != created.ID || got.Threshold != 120000 {
+		t.Errorf("alert = %+v, want the update", got)
+	}
+	list, err := c.Alerts(ctx)
+	if err != nil {
+		t.Fatalf("Alerts: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != created.ID {
+		t.Errorf("alerts = %+v, want the one created", list)
+	}
+
+	if err := c.DeleteAlert(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteAlert: %v", err)
+	}
+	if _, err := c.Alert(ctx, created.ID); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Alert after delete: %v, want 404", err)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/ticks"
+)
+
+// checkTimeZone accepts an IANA time zone name, e.g. America/New_York
+func checkTimeZone(v string) error {
+	if _, err := time.LoadLocation(v); err != nil {
+		return fmt.Errorf("unknown time zone %s", v)
+	}
+	return nil
+}
+
+// checkDate accepts a YYYY-MM-DD date
+func checkDate(v string) error {
+	if _, err := time.Parse("2006-01-02", v); err != nil {
+		return errors.New("date must be YYYY-MM-DD")
+	}
+	return nil
+}
+
+// handleClose returns a token's daily close in a time zone, from recorded
+// prices: GET /close/{token}?tz=America/New_York&date=2024-06-01
+func (s *Server) handleClose(w http.ResponseWriter, r *http.Request) {
+	if s.ticks == nil {
+		http.Error(w, `{"error":"daily closes not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	token := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/close/"), "/")
+	if token == "" {
+		http.Error(w, `{"error":"token id required"}`, http.StatusBadRequest)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, token) {
+		return
+	}
+
+	q := r.URL.Query()
+	loc := time.UTC
+	if tz := q.Get("tz"); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			http.Error(w, fmt.Sprintf(`{"error":"unknown time zone %s"}`, tz), http.StatusBadRequest)
+			return
+		}
+		loc = l
+	}
+	// The last full day in the time zone unless a date is given
+	now := time.Now().In(loc)
+	date := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, loc)
+	if v := q.Get("date"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, loc)
+		if err != nil {
+			http.Error(w, `{"error":"date must be YYYY-MM-DD"}`, http.StatusBadRequest)
+			return
+		}
+		if d.After(now) {
+			http.Error(w, fmt.Sprintf(`{"error":"%s has not started in %s"}`, v, loc), http.StatusBadRequest)
+			return
+		}
+		date = d
+	}
+	currency := queryCurrency(q)
+
+	c, err := s.ticks.Close(token, currency, date)
+	if errors.Is(err, ticks.ErrNoTicks) {
+		http.Error(w, fmt.Sprintf(`{"error":"no %s prices recorded for %s on %s in %s"}`, currency, token, date.Format("2006-01-02"), loc), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if c.Final {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+	} else {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	json.NewEncoder(w).Encode(c)
+}
@@ -0,0 +1,324 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/markets"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// coinCurrencies are the currencies /coins/{id} reports market data in
+var coinCurrencies = []string{"usd", "eur", "gbp", "jpy", "btc", "eth"}
+
+// coinMarketOrders sort /coins/markets, CoinGecko's default first
+var coinMarketOrders = map[string]func(a, b coinMarket) bool{
+	"market_cap_desc": func(a, b coinMarket) bool { return a.MarketCap > b.MarketCap },
+	"market_cap_asc":  func(a, b coinMarket) bool { return a.MarketCap < b.MarketCap },
+	"volume_desc":     func(a, b coinMarket) bool { return a.TotalVolume > b.TotalVolume },
+	"volume_asc":      func(a, b coinMarket) bool { return a.TotalVolume < b.TotalVolume },
+	"id_asc":          func(a, b coinMarket) bool { return a.ID < b.ID },
+	"id_desc":         func(a, b coinMarket) bool { return a.ID > b.ID },
+}
+
+// coinMarket is an entry of CoinGecko's /coins/markets. Fields we don't
+// track, such as supply and all-time highs, are left out.
+type coinMarket struct {
+	ID                       string    `json:"id"`
+	Symbol                   string    `json:"symbol"`
+	Name                     string    `json:"name"`
+	CurrentPrice             float64   `json:"current_price"`
+	MarketCap                float64   `json:"market_cap"`
+	MarketCapRank            *int      `json:"market_cap_rank"`
+	TotalVolume              float64   `json:"total_volume"`
+	PriceChange24h           float64   `json:"price_change_24h"`
+	PriceChangePercentage24h float64   `json:"price_change_percentage_24h"`
+	LastUpdated              time.Time `json:"last_updated"`
+
+	// Set when asked for with price_change_percentage=24h,7d
+	PriceChangePercentage24hInCurrency *float64 `json:"price_change_percentage_24h_in_currency,omitempty"`
+	PriceChangePercentage7dInCurrency  *float64 `json:"price_change_percentage_7d_in_currency,omitempty"`
+
+	change7d *float64
+}
+
+// coinDetail is the market part of CoinGecko's /coins/{id}
+type coinDetail struct {
+	ID            string         `json:"id"`
+	Symbol        string         `json:"symbol"`
+	Name          string         `json:"name"`
+	MarketCapRank *int           `json:"market_cap_rank"`
+	MarketData    coinMarketData `json:"market_data"`
+	LastUpdated   time.Time      `json:"last_updated"`
+}
+
+// coinMarketData holds per-currency figures keyed by currency
+type coinMarketData struct {
+	CurrentPrice                       map[string]float64 `json:"current_price"`
+	MarketCap                          map[string]float64 `json:"market_cap"`
+	MarketCapRank                      *int               `json:"market_cap_rank"`
+	TotalVolume                        map[string]float64 `json:"total_volume"`
+	PriceChange24h                     float64            `json:"price_change_24h"`
+	PriceChangePercentage24h           float64            `json:"price_change_percentage_24h"`
+	PriceChangePercentage7d            *float64           `json:"price_change_percentage_7d"`
+	PriceChange24hInCurrency           map[string]float64 `json:"price_change_24h_in_currency"`
+	PriceChangePercentage24hInCurrency map[string]float64 `json:"price_change_percentage_24h_in_currency"`
+	LastUpdated                        time.Time          `json:"last_updated"`
+}
+
+// handleCoinMarkets serves CoinGecko's /coins/markets from the market list,
+// or from the price cache for the tokens named in ids
+func (s *Server) handleCoinMarkets(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	currency := strings.ToLower(q.Get("vs_currency"))
+	if currency == "" {
+		http.Error(w, `{"error":"vs_currency query parameter required"}`, http.StatusBadRequest)
+		return
+	}
+	order := q.Get("order")
+	if order == "" {
+		order = "market_cap_desc"
+	}
+	less, ok := coinMarketOrders[order]
+	if !ok {
+		writeParamError(w, "order", fmt.Errorf("unsupported order %q", order))
+		return
+	}
+	perPage, page := 100, 1
+	if v := q.Get("per_page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > markets.MaxLimit {
+			writeParamError(w, "per_page", fmt.Errorf("must be between 1 and %d", markets.MaxLimit))
+			return
+		}
+		perPage = n
+	}
+	if v := q.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeParamError(w, "page", errors.New("must be a positive integer"))
+			return
+		}
+		page = n
+	}
+
+	var coins []coinMarket
+	if ids := q.Get("ids"); ids != "" {
+		tokenIDs := strings.Split(strings.ToLower(ids), ",")
+		if !s.checkTokensAllowed(w, r, tokenIDs...) {
+			return
+		}
+		resp, err := s.cache.GetMultiplePrices(r.Context(), tokenIDs, currency)
+		if err != nil && len(resp.Prices) == 0 {
+			writeUpstreamError(w, r, "prices unavailable", err)
+			return
+		}
+		ranked := s.rankedAssets(r.Context(), currency)
+		for _, p := range resp.Prices {
+			coins = append(coins, newCoinMarket(p, ranked[p.ID]))
+		}
+		s.setCacheControl(w, r, EndpointPrices, oldestUpdate(resp.Prices))
+	} else {
+		if s.markets == nil {
+			http.Error(w, `{"error":"market data not configured"}`, http.StatusNotFound)
+			return
+		}
+		list, err := s.markets.Top(r.Context(), currency, markets.MaxLimit)
+		if err != nil {
+			writeUpstreamError(w, r, "markets unavailable", err)
+			return
+		}
+		tenant := tenantFrom(r.Context())
+		for i, a := range list.Assets {
+			if tenant != nil && !tenant.Allows(a.ID) {
+				continue
+			}
+			coins = append(coins, coinMarketFromAsset(&list.Assets[i]))
+		}
+		w.Header().Set("Cache-Control", "public, max-age=60")
+	}
+
+	sort.SliceStable(coins, func(i, j int) bool { return less(coins[i], coins[j]) })
+	start := (page - 1) * perPage
+	if start > len(coins) {
+		start = len(coins)
+	}
+	coins = coins[start:min(start+perPage, len(coins))]
+
+	windows := strings.Split(q.Get("price_change_percentage"), ",")
+	for i := range coins {
+		for _, window := range windows {
+			switch strings.TrimSpace(window) {
+			case "24h":
+				change := coins[i].PriceChangePercentage24h
+				coins[i].PriceChangePercentage24hInCurrency = &change
+			case "7d":
+				coins[i].PriceChangePercentage7dInCurrency = coins[i].change7d
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(coins)
+}
+
+// handleCoin serves the market data of CoinGecko's /coins/{id} from the
+// price cache, in each of coinCurrencies that can be priced
+func (s *Server) handleCoin(w http.ResponseWriter, r *http.Request) {
+	tokenID := strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/coins/"), "/"))
+	if tokenID == "" {
+		http.Error(w, `{"error":"id required"}`, http.StatusBadRequest)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, tokenID) {
+		return
+	}
+
+	prices := make([]*cache.PriceResponse, len(coinCurrencies))
+	errs := make([]error, len(coinCurrencies))
+	var g errgroup.Group
+	g.SetLimit(currencyFetches)
+	for i, currency := range coinCurrencies {
+		i, currency := i, currency
+		g.Go(func() error {
+			prices[i], errs[i] = s.cache.GetPrice(r.Context(), tokenID, currency)
+			return nil
+		})
+	}
+	g.Wait()
+
+	// The token is priced in USD or not at all
+	usd := prices[0]
+	if errors.Is(errs[0], providers.ErrTokenNotFound) {
+		s.writeNotFound(w, tokenID, errs[0])
+		return
+	}
+	if errs[0] != nil {
+		writeUpstreamError(w, r, "price unavailable", errs[0])
+		return
+	}
+
+	data := coinMarketData{
+		CurrentPrice:                       make(map[string]float64),
+		MarketCap:                          make(map[string]float64),
+		TotalVolume:                        make(map[string]float64),
+		PriceChange24h:                     priceChange(usd.Price, usd.Change24h),
+		PriceChangePercentage24h:           usd.Change24h,
+		PriceChange24hInCurrency:           make(map[string]float64),
+		PriceChangePercentage24hInCurrency: make(map[string]float64),
+		LastUpdated:                        usd.UpdatedAt.UTC(),
+	}
+	var failed []string
+	for i, p := range prices {
+		if errs[i] != nil {
+			if !errors.Is(errs[i], context.Canceled) {
+				log.Printf("Error pricing %s in %s: %v", tokenID, coinCurrencies[i], errs[i])
+			}
+			failed = append(failed, coinCurrencies[i])
+			continue
+		}
+		data.CurrentPrice[p.Currency] = p.Price
+		data.MarketCap[p.Currency] = p.MarketCap
+		data.TotalVolume[p.Currency] = p.Volume24h
+		data.PriceChange24hInCurrency[p.Currency] = priceChange(p.Price, p.Change24h)
+		data.PriceChangePercentage24hInCurrency[p.Currency] = p.Change24h
+	}
+	if len(failed) > 0 {
+		w.Header().Set("X-Failed-Currencies", strings.Join(failed, ","))
+	}
+
+	coin := coinDetail{ID: tokenID, Symbol: usd.Symbol, Name: usd.Name, LastUpdated: data.LastUpdated}
+	if a := s.rankedAssets(r.Context(), "usd")[tokenID]; a != nil {
+		coin.Symbol, coin.Name = a.Symbol, a.Name
+		coin.MarketCapRank = &a.Rank
+		data.MarketCapRank = &a.Rank
+		data.PriceChangePercentage7d = &a.Change7d
+	}
+	if coin.Symbol == "" {
+		coin.Symbol, coin.Name = tokenID, tokenID
+	}
+	coin.MarketData = data
+
+	s.setCacheControl(w, r, EndpointPrice, usd.UpdatedAt)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(coin)
+}
+
+// rankedAssets indexes the market list in currency by token id, or is
+// empty if market data is unavailable
+func (s *Server) rankedAssets(ctx context.Context, currency string) map[string]*markets.MarketAsset {
+	ranked := make(map[string]*markets.MarketAsset)
+	if s.markets == nil {
+		return ranked
+	}
+	list, err := s.markets.Top(ctx, currency, markets.MaxLimit)
+	if err != nil {
+		return ranked
+	}
+	for i := range list.Assets {
+		ranked[list.Assets[i].ID] = &list.Assets[i]
+	}
+	return ranked
+}
+
+// newCoinMarket builds a /coins/markets entry from a cached price, ranked
+// if the token is in the market list
+func newCoinMarket(p *cache.PriceResponse, a *markets.MarketAsset) coinMarket {
+	c := coinMarket{
+		ID:                       p.ID,
+		Symbol:                   p.Symbol,
+		Name:                     p.Name,
+		CurrentPrice:             p.Price,
+		MarketCap:                p.MarketCap,
+		TotalVolume:              p.Volume24h,
+		PriceChange24h:           priceChange(p.Price, p.Change24h),
+		PriceChangePercentage24h: p.Change24h,
+		LastUpdated:              p.UpdatedAt.UTC(),
+	}
+	if a != nil {
+		c.Symbol, c.Name = a.Symbol, a.Name
+		c.MarketCapRank = &a.Rank
+		c.change7d = &a.Change7d
+	}
+	return c
+}
+
+// coinMarketFromAsset builds a /coins/markets entry from the market list
+func coinMarketFromAsset(a *markets.MarketAsset) coinMarket {
+	return coinMarket{
+		ID:                       a.ID,
+		Symbol:                   a.Symbol,
+		Name:                     a.Name,
+		CurrentPrice:             a.Price,
+		MarketCap:                a.MarketCap,
+		MarketCapRank:            &a.Rank,
+		TotalVolume:              a.Volume24h,
+		PriceChange24h:           priceChange(a.Price, a.Change24h),
+		PriceChangePercentage24h: a.Change24h,
+		LastUpdated:              a.UpdatedAt,
+		change7d:                 &a.Change7d,
+	}
+}
+
+// priceChange returns the absolute 24h change of a price that moved
+// percent over the period
+func priceChange(price, percent float64) float64 {
+	if percent <= -100 {
+		return 0
+	}
+	return price - price/(1+percent/100)
+}
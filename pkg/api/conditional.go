@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+)
+
+// etagBuilder accumulates price data into a weak ETag. Weak validators are
+// used because the same data is served both compressed and uncompressed and
+// with fields (cached, response time) that don't change its meaning.
+type etagBuilder struct {
+	h hash.Hash
+}
+
+func newETagBuilder() *etagBuilder {
+	return &etagBuilder{h: sha256.New()}
+}
+
+// addPrice mixes the fields that identify a price observation and the
+// metadata served with it
+func (b *etagBuilder) addPrice(p *cache.PriceResponse) {
+	fmt.Fprintf(b.h, "%s|%s|%v|%v|%v|%v|%d|%s\n",
+		p.ID, p.Currency, p.Price, p.Change24h, p.MarketCap, p.Volume24h, p.UpdatedAt.UnixNano(), priceMetadata(p))
+	for _, f := range p.RiskFlags {
+		fmt.Fprintf(b.h, "risk|%s|%s|%s\n", f.Type, f.Severity, f.Detail)
+	}
+}
+
+// priceMetadata returns the fields of a price that a metadata override
+// changes without a new observation
+func priceMetadata(p *cache.PriceResponse) string {
+	decimals := ""
+	if p.Decimals != nil {
+		decimals = strconv.Itoa(*p.Decimals)
+	}
+	return p.Name + "|" + p.Symbol + "|" + decimals + "|" + strings.Join(p.Tags, ",")
+}
+
+// addBytes mixes raw bytes
+func (b *etagBuilder) addBytes(data []byte) {
+	b.h.Write(data)
+}
+
+func (b *etagBuilder) String() string {
+	return `W/"` + hex.EncodeToString(b.h.Sum(nil)[:16]) + `"`
+}
+
+// multiPriceETag builds an ETag over a set of prices and their errors in
+// stable order
+func multiPriceETag(resp *cache.MultiPriceResponse) (string, time.Time) {
+	ids := make([]string, 0, len(resp.Prices))
+	for id := range resp.Prices {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	b := newETagBuilder()
+	var lastModified time.Time
+	for _, id := range ids {
+		p := resp.Prices[id]
+		b.addPrice(p)
+		if p.UpdatedAt.After(lastModified) {
+			lastModified = p.UpdatedAt
+		}
+	}
+
+	failed := make([]string, 0, len(resp.Errors))
+	for id := range resp.Errors {
+		failed = append(failed, id)
+	}
+	sort.Strings(failed)
+	for _, id := range failed {
+		fmt.Fprintf(b.h, "error|%s|%s\n", id, resp.Errors[id].Code)
+	}
+	return b.String(), lastModified
+}
+
+// etagMatches reports whether an If-None-Match header matches etag using
+// weak comparison
+func etagMatches(header, etag string) bool {
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNotModified sets ETag and Last-Modified and writes a 304 if the
+// request's conditional headers show the client already has this
+// representation. If-None-Match takes precedence over If-Modified-Since.
+func checkNotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	notModified := false
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		notModified = etag != "" && etagMatches(inm, etag)
+	} else if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		if t, err := http.ParseTime(ims); err == nil {
+			notModified = !lastModified.Truncate(time.Second).After(t)
+		}
+	}
+
+	if notModified {
+		w.WriteHeader(http.StatusNotModified)
+	}
+	return notModified
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/internal/testutil"
+)
+
+// getWith sends a GET to path with headers and returns the answer, its
+// body read
+func getWith(t *testing.T, srv *testutil.Server, path string, headers map[string]string) (*http.Response, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := srv.Server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("GET %s: reading body: %v", path, err)
+	}
+	return resp, body
+}
+
+func TestPriceConditional(t *testing.T) {
+	const path = "/v1/price/bitcoin"
+	srv := testutil.NewServer(t)
+	srv.Provider.Set("bitcoin", 65000)
+
+	first, _ := getWith(t, srv, path, nil)
+	etag, lastModified := first.Header.Get("ETag"), first.Header.Get("Last-Modified")
+	if first.StatusCode != http.StatusOK || etag == "" || lastModified == "" {
+		t.Fatalf("first GET = %d ETag %q Last-Modified %q, want 200 with both", first.StatusCode, etag, lastModified)
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		t.Fatalf("Last-Modified %q: %v", lastModified, err)
+	}
+	before := modified.Add(-time.Second).Format(http.TimeFormat)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{"unconditional", nil, http.StatusOK},
+		{"etag matches", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+		{"strong form matches", map[string]string{"If-None-Match": strings.TrimPrefix(etag, "W/")}, http.StatusNotModified},
+		{"etag in list", map[string]string{"If-None-Match": `"other", ` + etag}, http.StatusNotModified},
+		{"any etag", map[string]string{"If-None-Match": "*"}, http.StatusNotModified},
+		{"etag differs", map[string]string{"If-None-Match": `"other"`}, http.StatusOK},
+		{"not modified since", map[string]string{"If-Modified-Since": lastModified}, http.StatusNotModified},
+		{"modified since", map[string]string{"If-Modified-Since": before}, http.StatusOK},
+		{"etag takes precedence", map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": lastModified}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := getWith(t, srv, path, tt.headers)
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+			if got := resp.Header.Get("ETag"); got != etag {
+				t.Errorf("ETag = %q, want %q", got, etag)
+			}
+			if tt.want == http.StatusNotModified && len(body) != 0 {
+				t.Errorf("304 has body %q", body)
+			}
+			if tt.want == http.StatusOK && len(body) == 0 {
+				t.Error("200 has no body")
+			}
+		})
+	}
+}
+
+func TestPriceETagChangesWithPrice(t *testing.T) {
+	const path = "/v1/price/bitcoin"
+	srv := testutil.NewServer(t)
+	srv.Provider.Set("bitcoin", 65000)
+
+	first, _ := getWith(t, srv, path, nil)
+	etag := first.Header.Get("ETag")
+
+	srv.Cache.Flush("bitcoin")
+	srv.Provider.Set("bitcoin", 66000)
+	resp, _ := getWith(t, srv, path, map[string]string{"If-None-Match": etag})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d after the price changed, want 200", resp.StatusCode)
+	}
+	if got := resp.Header.Get("ETag"); got == etag {
+		t.Errorf("ETag %q unchanged after the price changed", got)
+	}
+}
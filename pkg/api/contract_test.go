@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/internal/testutil"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// TestV1Contract pins the bodies of the frozen /v1 price endpoints to
+// testdata/*.golden, so renaming or dropping a field fails. Run with
+// -update to accept an intended change.
+func TestV1Contract(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"v1_price", "/v1/price/bitcoin"},
+		{"v1_prices", "/v1/prices?ids=bitcoin,ethereum,unknown-token"},
+		{"v1_simple_price", "/v1/simple/price?ids=bitcoin,ethereum&vs_currencies=usd,eur"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := testutil.NewServer(t)
+			srv.Provider.SetPrice(providers.Price{
+				ID: "bitcoin", Symbol: "btc", Name: "Bitcoin",
+				CurrentPrice: 65000.5, MarketCap: 1.28e12, TotalVolume: 3.1e10, PriceChangePercentage24h: -1.25,
+			})
+			srv.Provider.SetPrice(providers.Price{
+				ID: "ethereum", Symbol: "eth", Name: "Ethereum",
+				CurrentPrice: 3200, MarketCap: 3.85e11, TotalVolume: 1.5e10, PriceChangePercentage24h: 2.5,
+			})
+
+			code, body := srv.Get(t, tt.path)
+			if code != http.StatusOK {
+				t.Fatalf("GET %s: %d %s", tt.path, code, body)
+			}
+			got := normalize(t, body)
+
+			golden := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("GET %s body changed:\n%s\nwant:\n%s", tt.path, got, want)
+			}
+		})
+	}
+}
+
+// normalize indents a JSON body with its keys sorted and every RFC 3339
+// updated_at replaced by a placeholder, as timestamps differ from run to
+// run. One in another format is kept, so the golden comparison fails.
+func normalize(t *testing.T, body []byte) []byte {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		t.Fatalf("body %q is not JSON: %v", body, err)
+	}
+	maskTimes(v)
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(out, '\n')
+}
+
+func maskTimes(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if s, ok := child.(string); ok && k == "updated_at" {
+				if _, err := time.Parse(time.RFC3339Nano, s); err == nil {
+					v[k] = "RFC3339"
+				}
+				continue
+			}
+			maskTimes(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			maskTimes(child)
+		}
+	}
+}
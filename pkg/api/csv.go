@@ -0,0 +1,48 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// formatParam selects JSON or CSV output
+var formatParam = param{Name: "format", In: "query", Type: "string", Description: "json (default) or csv"}
+
+// wantsCSV reports whether ?format=csv was requested, writing a 400 and
+// returning ok=false for unknown formats
+func wantsCSV(w http.ResponseWriter, r *http.Request) (csv, ok bool) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		return false, true
+	case "csv":
+		return true, true
+	default:
+		http.Error(w, fmt.Sprintf(`{"error":"unsupported format: %s"}`, format), http.StatusBadRequest)
+		return false, false
+	}
+}
+
+// writeCSV writes a header row and rows as a CSV attachment named filename
+func writeCSV(w http.ResponseWriter, filename string, header []string, rows [][]string) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	cw.WriteAll(rows)
+}
+
+// csvFloat formats a number without exponent or trailing zeros
+func csvFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// csvTime formats a time as RFC 3339 in UTC
+func csvTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
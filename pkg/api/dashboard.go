@@ -0,0 +1,22 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// dashboardPage shows prices, stablecoin pegs and, given an admin key,
+// cache and provider health, all fetched from the API by the browser
+//
+//go:embed dashboard.html
+var dashboardPage []byte
+
+// handleDashboard serves the dashboard
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Write(dashboardPage)
+}
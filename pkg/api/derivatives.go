@@ -0,0 +1,48 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/derivatives"
+)
+
+// handleDerivatives returns perpetual funding rates and open interest for
+// a token across venues
+func (s *Server) handleDerivatives(w http.ResponseWriter, r *http.Request) {
+	if s.derivs == nil {
+		http.Error(w, `{"error":"derivatives data not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	token := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/derivatives/"), "/")
+	if token == "" {
+		http.Error(w, `{"error":"token required"}`, http.StatusBadRequest)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, token) {
+		return
+	}
+
+	summary, err := s.derivs.Summary(r.Context(), token)
+	if errors.Is(err, derivatives.ErrNotFound) {
+		http.Error(w, fmt.Sprintf(`{"error":"no perpetual markets for %s"}`, token), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching derivatives for %s: %v", token, err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	json.NewEncoder(w).Encode(summary)
+}
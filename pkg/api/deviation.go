@@ -0,0 +1,32 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/luxfi/pricing/pkg/deviation"
+)
+
+// deviationResponse lists how far providers' quotes are apart per token
+type deviationResponse struct {
+	Threshold float64            `json:"threshold"`
+	Tokens    []deviation.Status `json:"tokens"`
+}
+
+// handleDeviation returns the latest cross-provider comparison per token
+func (s *Server) handleDeviation(w http.ResponseWriter, r *http.Request) {
+	if s.devs == nil {
+		http.Error(w, `{"error":"deviation monitoring not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(deviationResponse{
+		Threshold: s.devs.Threshold(),
+		Tokens:    s.devs.Snapshot(),
+	})
+}
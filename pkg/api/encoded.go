@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+)
+
+// Max pre-encoded responses kept before entries are evicted
+const maxEncodedResponses = 10000
+
+// encodedResponse is a serialized response body with its validator
+type encodedResponse struct {
+	updatedAt time.Time
+	etag      string
+	body      []byte
+}
+
+// encodedCache keeps serialized JSON for hot responses so repeated hits on
+// the same cached price skip json.Marshal and ETag hashing. Entries are
+// keyed by request and only valid for the price observation they encode.
+type encodedCache struct {
+	mu      sync.RWMutex
+	entries map[string]*encodedResponse
+}
+
+func newEncodedCache() *encodedCache {
+	return &encodedCache{entries: make(map[string]*encodedResponse)}
+}
+
+// get returns the encoded response for key if it was built from the
+// observation at updatedAt
+func (c *encodedCache) get(key string, updatedAt time.Time) *encodedResponse {
+	c.mu.RLock()
+	e := c.entries[key]
+	c.mu.RUnlock()
+
+	if e == nil || !e.updatedAt.Equal(updatedAt) {
+		return nil
+	}
+	return e
+}
+
+// put stores an encoded response, evicting an arbitrary entry when full
+func (c *encodedCache) put(key string, e *encodedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= maxEncodedResponses {
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+	c.entries[key] = e
+}
+
+// encodePrice serializes a price response and computes its ETag
+func encodePrice(p *cache.PriceResponse) (*encodedResponse, error) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	etag := newETagBuilder()
+	etag.addPrice(p)
+	return &encodedResponse{
+		updatedAt: p.UpdatedAt,
+		etag:      etag.String(),
+		body:      append(body, '\n'),
+	}, nil
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// handleExtremes returns a token's all-time and 52-week highs and lows
+func (s *Server) handleExtremes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+	if s.extremes == nil {
+		http.Error(w, `{"error":"extremes not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	token := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/extremes/"), "/")
+	if token == "" {
+		http.Error(w, `{"error":"token id required"}`, http.StatusBadRequest)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, token) {
+		return
+	}
+	currency := queryCurrency(r.URL.Query())
+
+	ext, err := s.extremes.Get(r.Context(), token, currency)
+	if errors.Is(err, providers.ErrTokenNotFound) {
+		s.writeNotFound(w, token, err)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching %s extremes: %v", token, err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ext)
+}
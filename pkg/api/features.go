@@ -0,0 +1,54 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import "sort"
+
+const (
+	// FeatureAdmin is the group of admin routes. Disabling it also
+	// disables admin routes of other groups, such as /admin/oracle.
+	FeatureAdmin = "admin"
+
+	// FeatureDashboard is the status dashboard served on /
+	FeatureDashboard = "dashboard"
+
+	// featureSystem is the group of health checks, which are always served
+	featureSystem = "system"
+)
+
+// feature returns the group a route is enabled and disabled with: its
+// Feature, or its Tag if it has none
+func (rt route) feature() string {
+	if rt.Feature != "" {
+		return rt.Feature
+	}
+	return rt.Tag
+}
+
+// FeatureGroups returns the names of the endpoint groups a deployment can
+// enable or disable, sorted
+func FeatureGroups() []string {
+	seen := map[string]bool{FeatureDashboard: true}
+	for _, rt := range routes {
+		seen[rt.feature()] = true
+	}
+	delete(seen, featureSystem)
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// featureOn reports whether the server serves the feature group name
+func (s *Server) featureOn(name string) bool {
+	return name == featureSystem || s.features == nil || s.features(name)
+}
+
+// serves reports whether the server serves a route: its group must be
+// enabled, and so must the admin group if it is an admin route
+func (s *Server) serves(rt route) bool {
+	return s.featureOn(rt.feature()) && (!rt.Admin || s.featureOn(FeatureAdmin))
+}
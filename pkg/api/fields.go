@@ -0,0 +1,180 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/markets"
+)
+
+// fieldsParam trims each token's object to the listed fields
+var fieldsParam = param{Name: "fields", In: "query", Type: "string", Description: "Comma-separated fields to return for each token, e.g. price,change_24h (default all)"}
+
+// fieldSelection is the JSON fields a client asked for with ?fields=, in
+// declaration order
+type fieldSelection []string
+
+// parseFields reads ?fields= as a selection of the JSON fields of item's
+// type, answering 400 for unknown fields. A nil selection keeps every
+// field.
+func parseFields(w http.ResponseWriter, r *http.Request, item any) (fieldSelection, bool) {
+	v := r.URL.Query().Get("fields")
+	if v == "" {
+		return nil, true
+	}
+	known := jsonFields(reflect.TypeOf(item))
+	want := make(map[string]bool)
+	for _, f := range strings.Split(v, ",") {
+		f = strings.TrimSpace(f)
+		if !slices.Contains(known, f) {
+			writeParamError(w, "fields", fmt.Errorf("unknown field %q; fields are %s", f, strings.Join(known, ", ")))
+			return nil, false
+		}
+		want[f] = true
+	}
+	var sel fieldSelection
+	for _, f := range known {
+		if want[f] {
+			sel = append(sel, f)
+		}
+	}
+	return sel, true
+}
+
+// jsonFields returns the JSON field names of a struct type in declaration
+// order, flattening untagged embedded structs as encoding/json does
+func jsonFields(t reflect.Type) []string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			names = append(names, jsonFields(f.Type)...)
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// object encodes v keeping only the selected fields. Fields v omits, such
+// as empty omitempty ones, stay omitted.
+func (sel fieldSelection) object(v any) (json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, errors.New("fields can only be selected from an object")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, name := range sel {
+		value, ok := fields[name]
+		if !ok {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// variantETag derives the ETag of a response variant, such as one trimmed
+// to selected fields, from the full response's, so each variant validates
+// separately
+func variantETag(etag, variant string) string {
+	b := newETagBuilder()
+	b.addBytes([]byte(etag + "|" + variant))
+	return b.String()
+}
+
+// encodeVariant encodes a price trimmed to sel, or whole if sel is nil,
+// under the ETag of the named variant
+func encodeVariant(p *cache.PriceResponse, sel fieldSelection, variant string) (*encodedResponse, error) {
+	encoded, err := encodePrice(p)
+	if err != nil {
+		return nil, err
+	}
+	if sel != nil {
+		body, err := sel.object(p)
+		if err != nil {
+			return nil, err
+		}
+		encoded.body = append(body, '\n')
+	}
+	encoded.etag = variantETag(encoded.etag, variant)
+	return encoded, nil
+}
+
+// selectedPrices is a cache.MultiPriceResponse with each price trimmed to
+// the selected fields
+type selectedPrices struct {
+	Prices    map[string]json.RawMessage   `json:"prices"`
+	Errors    map[string]*cache.TokenError `json:"errors,omitempty"`
+	UpdatedAt time.Time                    `json:"updated_at"`
+}
+
+// prices trims each price of resp to the selection
+func (sel fieldSelection) prices(resp *cache.MultiPriceResponse) (*selectedPrices, error) {
+	out := &selectedPrices{Prices: make(map[string]json.RawMessage, len(resp.Prices)), Errors: resp.Errors, UpdatedAt: resp.UpdatedAt}
+	for id, p := range resp.Prices {
+		obj, err := sel.object(p)
+		if err != nil {
+			return nil, err
+		}
+		out.Prices[id] = obj
+	}
+	return out, nil
+}
+
+// selectedMarkets is a markets.List with each asset trimmed to the
+// selected fields
+type selectedMarkets struct {
+	Currency  string            `json:"currency"`
+	Assets    []json.RawMessage `json:"assets"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Cached    bool              `json:"cached"`
+}
+
+// markets trims each asset of list to the selection
+func (sel fieldSelection) markets(list *markets.List) (*selectedMarkets, error) {
+	out := &selectedMarkets{Currency: list.Currency, Assets: make([]json.RawMessage, len(list.Assets)), UpdatedAt: list.UpdatedAt, Cached: list.Cached}
+	for i, a := range list.Assets {
+		obj, err := sel.object(a)
+		if err != nil {
+			return nil, err
+		}
+		out.Assets[i] = obj
+	}
+	return out, nil
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/decimal"
+	"github.com/luxfi/pricing/pkg/format"
+	"github.com/luxfi/pricing/pkg/markets"
+)
+
+// Parameters that add display-ready strings to each token or round its
+// price for display
+var (
+	formattedParam = param{Name: "formatted", In: "query", Type: "boolean", Description: "Add a formatted object of display-ready strings to each token"}
+	localeParam    = param{Name: "locale", In: "query", Type: "string", Description: "Locale of formatted strings, e.g. de-DE (default en-US); implies formatted"}
+	roundedParam   = param{Name: "rounded", In: "query", Type: "boolean", Description: "Round prices to the display precision of their magnitude (see /rounding)"}
+)
+
+// parseFormat reads ?formatted=true and ?locale= as the locale to format
+// values in, answering 400 for unsupported locales. A nil locale leaves
+// values unformatted.
+func parseFormat(w http.ResponseWriter, r *http.Request) (*format.Locale, bool) {
+	q := r.URL.Query()
+	name := q.Get("locale")
+	if v := q.Get("formatted"); name == "" && v != "true" && v != "1" {
+		return nil, true
+	}
+	if name == "" {
+		name = format.DefaultLocale
+	}
+	loc, err := format.ParseLocale(name)
+	if err != nil {
+		writeParamError(w, "locale", err)
+		return nil, false
+	}
+	return loc, true
+}
+
+// wantsRounded reports whether ?rounded=true was requested
+func wantsRounded(r *http.Request) bool {
+	v := r.URL.Query().Get("rounded")
+	return v == "true" || v == "1"
+}
+
+// roundPrices rounds each price to the display precision of its
+// magnitude, from its exact decimal where there is one
+func roundPrices(policy format.Policy, prices ...*cache.PriceResponse) {
+	for _, p := range prices {
+		d, err := decimal.Parse(p.PriceStr)
+		if err != nil {
+			d = decimal.FromFloat(p.Price)
+		}
+		d = policy.Round(d)
+		p.Price, p.PriceStr = d.Float64(), d.String()
+	}
+}
+
+// roundMarkets rounds the price of each asset of list, copying the assets
+// so the cached list stays untouched
+func roundMarkets(policy format.Policy, list *markets.List) {
+	assets := make([]markets.MarketAsset, len(list.Assets))
+	for i, a := range list.Assets {
+		a.Price = policy.Round(decimal.FromFloat(a.Price)).Float64()
+		assets[i] = a
+	}
+	list.Assets = assets
+}
+
+// roundingResponse is the display precision policy of rounded prices
+type roundingResponse struct {
+	Policy string        `json:"policy"` // as ROUNDING_POLICY is written
+	Bands  []format.Band `json:"bands"`  // largest first
+}
+
+// handleRounding returns the display precision ?rounded=true applies, so
+// surfaces rounding prices themselves match the API: GET /rounding
+func (s *Server) handleRounding(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	json.NewEncoder(w).Encode(roundingResponse{Policy: s.rounding.String(), Bands: s.rounding})
+}
+
+// formatPrices adds display strings for loc to each price
+func formatPrices(loc *format.Locale, prices ...*cache.PriceResponse) {
+	for _, p := range prices {
+		p.Formatted = loc.Values(p.Currency, p.Price, p.Change24h, p.MarketCap, p.Volume24h)
+	}
+}
+
+// formatMarkets adds display strings for loc to each asset of list,
+// copying the assets so the cached list stays untouched
+func formatMarkets(loc *format.Locale, list *markets.List) {
+	assets := make([]markets.MarketAsset, len(list.Assets))
+	for i, a := range list.Assets {
+		a.Formatted = loc.Values(list.Currency, a.Price, a.Change24h, a.MarketCap, a.Volume24h)
+		assets[i] = a
+	}
+	list.Assets = assets
+}
+
+// responseVariant names the field selection, locale and rounding of a
+// response, or "" for the plain response
+func responseVariant(sel fieldSelection, loc *format.Locale, rounded bool) string {
+	var parts []string
+	if sel != nil {
+		parts = append(parts, "fields="+strings.Join(sel, ","))
+	}
+	if loc != nil {
+		parts = append(parts, "locale="+loc.Name)
+	}
+	if rounded {
+		parts = append(parts, "rounded")
+	}
+	return strings.Join(parts, "&")
+}
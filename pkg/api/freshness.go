@@ -0,0 +1,129 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+)
+
+// staleResponse is the 503 answer to a price older than its max age,
+// carrying the stale price for clients that can still use it
+type staleResponse struct {
+	Error  string               `json:"error"`
+	Code   string               `json:"code"`
+	MaxAge int64                `json:"max_age_seconds"`
+	Age    int64                `json:"age_seconds"`
+	Price  *cache.PriceResponse `json:"price"`
+}
+
+// checkMaxAge validates ?max_age=, a duration such as 30s or a number of
+// seconds, of at least a second
+func checkMaxAge(v string) error {
+	_, err := parseMaxAge(v)
+	return err
+}
+
+// parseMaxAge parses a max_age value
+func parseMaxAge(v string) (time.Duration, error) {
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		n, nerr := strconv.Atoi(v)
+		if nerr != nil {
+			return 0, errors.New("must be a duration such as 30s, or a number of seconds")
+		}
+		d = time.Duration(n) * time.Second
+	}
+	if d < time.Second {
+		return 0, errors.New("must be at least 1s")
+	}
+	return d, nil
+}
+
+// requestMaxAge returns the request's ?max_age=, 0 if none, and tightens
+// the cache TTL of the request context to it, so older prices are
+// refetched
+func (s *Server) requestMaxAge(r *http.Request) (time.Duration, *http.Request) {
+	maxAge, err := parseMaxAge(r.URL.Query().Get("max_age"))
+	if err != nil {
+		// Absent; values are validated with the route's params
+		return 0, r
+	}
+	if maxAge < cache.TTLFromContext(r.Context(), s.cache.TTL()) {
+		r = r.WithContext(cache.WithTTL(r.Context(), maxAge))
+	}
+	return maxAge, r
+}
+
+// maxAgeFor returns the oldest price of a token that may be served: the
+// tighter of the request's max age and the token's freshness SLA, or 0 if
+// neither is set
+func (s *Server) maxAgeFor(tokenID string, requested time.Duration) time.Duration {
+	sla := s.cache.MaxAge(tokenID)
+	if requested > 0 && (sla == 0 || requested < sla) {
+		return requested
+	}
+	return sla
+}
+
+// tooOld reports whether p is older than maxAge, if that is set
+func tooOld(p *cache.PriceResponse, maxAge time.Duration) bool {
+	return maxAge > 0 && time.Since(p.UpdatedAt) > maxAge
+}
+
+// ageSeconds is a price's age in whole seconds, rounded up
+func ageSeconds(p *cache.PriceResponse) int64 {
+	return int64(math.Ceil(time.Since(p.UpdatedAt).Seconds()))
+}
+
+// writeStaleHeader starts a 503 answer to prices that could not be
+// refreshed within their max age. The stale body is not to be cached.
+func writeStaleHeader(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Retry-After", "1")
+	w.WriteHeader(http.StatusServiceUnavailable)
+}
+
+// writeStalePrice answers 503 with a price older than maxAge
+func writeStalePrice(w http.ResponseWriter, p *cache.PriceResponse, maxAge time.Duration) {
+	writeStaleHeader(w)
+	json.NewEncoder(w).Encode(staleResponse{
+		Error:  fmt.Sprintf("%s price is older than %v and could not be refreshed", p.ID, maxAge),
+		Code:   cache.CodeTooOld,
+		MaxAge: int64(maxAge.Seconds()),
+		Age:    ageSeconds(p),
+		Price:  p,
+	})
+}
+
+// markTooOld lists the prices in resp older than their max age in its
+// errors, returning their ids, sorted
+func (s *Server) markTooOld(resp *cache.MultiPriceResponse, requested time.Duration) []string {
+	var old []string
+	for id, p := range resp.Prices {
+		maxAge := s.maxAgeFor(id, requested)
+		if !tooOld(p, maxAge) {
+			continue
+		}
+		old = append(old, id)
+		if resp.Errors == nil {
+			resp.Errors = make(map[string]*cache.TokenError)
+		}
+		resp.Errors[id] = &cache.TokenError{
+			Code:    cache.CodeTooOld,
+			Message: fmt.Sprintf("price is %ds old, more than %v, and could not be refreshed", ageSeconds(p), maxAge),
+		}
+	}
+	sort.Strings(old)
+	return old
+}
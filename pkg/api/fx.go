@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// fxResponse lists exchange rates from one base currency
+type fxResponse = wire.FXRates
+
+// handleFX returns fiat exchange rates from base to the requested symbols,
+// or to every known currency if symbols is omitted
+func (s *Server) handleFX(w http.ResponseWriter, r *http.Request) {
+	if s.fx == nil {
+		http.Error(w, `{"error":"exchange rates not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	base := strings.ToLower(r.URL.Query().Get("base"))
+	if base == "" {
+		base = "usd"
+	}
+
+	rates, err := s.fx.Rates(r.Context())
+	if err != nil {
+		log.Printf("Error fetching exchange rates: %v", err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+	baseRate, ok := rates.Rates[base]
+	if !ok || baseRate == 0 {
+		http.Error(w, fmt.Sprintf(`{"error":"unsupported base currency: %s"}`, base), http.StatusBadRequest)
+		return
+	}
+
+	var symbols []string
+	if v := r.URL.Query().Get("symbols"); v != "" {
+		for _, sym := range strings.Split(v, ",") {
+			if sym = strings.ToLower(strings.TrimSpace(sym)); sym != "" {
+				symbols = append(symbols, sym)
+			}
+		}
+	} else {
+		for sym := range rates.Rates {
+			symbols = append(symbols, sym)
+		}
+		sort.Strings(symbols)
+	}
+
+	resp := fxResponse{
+		Base:   base,
+		Rates:  make(map[string]float64, len(symbols)),
+		Source: s.fx.Source(),
+	}
+	for _, sym := range symbols {
+		if rate, ok := rates.Rates[sym]; ok {
+			resp.Rates[sym] = rate / baseRate
+		} else {
+			resp.Unsupported = append(resp.Unsupported, sym)
+		}
+	}
+	if !rates.UpdatedAt.IsZero() {
+		resp.UpdatedAt = rates.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	json.NewEncoder(w).Encode(resp)
+}
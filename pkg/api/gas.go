@@ -0,0 +1,44 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/gas"
+)
+
+// handleGas returns current fee estimates for a chain
+func (s *Server) handleGas(w http.ResponseWriter, r *http.Request) {
+	if s.gas == nil {
+		http.Error(w, `{"error":"gas estimates not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	chain := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/gas/"), "/")
+	if chain == "" {
+		http.Error(w, `{"error":"chain required"}`, http.StatusBadRequest)
+		return
+	}
+
+	estimate, err := s.gas.Estimate(r.Context(), chain)
+	if errors.Is(err, gas.ErrUnknownChain) {
+		http.Error(w, fmt.Sprintf(`{"error":"unknown chain: %s"}`, chain), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching gas for %s: %v", chain, err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=5")
+	json.NewEncoder(w).Encode(estimate)
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/luxfi/pricing/pkg/global"
+)
+
+// handleGlobal returns the total crypto market cap, volume and dominance
+func (s *Server) handleGlobal(w http.ResponseWriter, r *http.Request) {
+	if s.global == nil {
+		http.Error(w, `{"error":"market overview not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	currency := queryCurrency(r.URL.Query())
+
+	overview, err := s.global.Overview(r.Context(), currency)
+	if errors.Is(err, global.ErrUnsupportedCurrency) {
+		http.Error(w, fmt.Sprintf(`{"error":"unsupported currency: %s"}`, currency), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching market overview: %v", err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	json.NewEncoder(w).Encode(overview)
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// handleHistory returns a token's price, market cap and volume over the
+// last days
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if s.history == nil {
+		http.Error(w, `{"error":"price history not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/history/"), "/")
+	if id == "" {
+		http.Error(w, `{"error":"token id required"}`, http.StatusBadRequest)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, id) {
+		return
+	}
+	asCSV, ok := wantsCSV(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	days := 30
+	if v := q.Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > history.MaxDays {
+			http.Error(w, fmt.Sprintf(`{"error":"days must be between 1 and %d"}`, history.MaxDays), http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+
+	currency := queryCurrency(q)
+
+	series, err := s.history.History(r.Context(), id, currency, days)
+	if errors.Is(err, providers.ErrTokenNotFound) {
+		s.writeNotFound(w, id, err)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching %s history: %v", id, err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Add("Vary", "Accept")
+	if asCSV {
+		rows := make([][]string, len(series.Points))
+		for i, p := range series.Points {
+			rows[i] = []string{csvTime(p.Time), csvFloat(p.Price), csvFloat(p.MarketCap), csvFloat(p.Volume)}
+		}
+		writeCSV(w, fmt.Sprintf("%s-%s-%dd.csv", series.ID, series.Currency, series.Days),
+			[]string{"time", "price", "market_cap", "volume"}, rows)
+		return
+	}
+	if wantsNDJSON(r) {
+		nw := newNDJSONWriter(w)
+		for _, p := range series.Points {
+			nw.write(p)
+		}
+		nw.close()
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(series)
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/index"
+)
+
+// handleIndex returns the value and constituents of a token index
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if s.indices == nil {
+		http.Error(w, `{"error":"indices not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/index/"), "/")
+	if name == "" {
+		http.Error(w, `{"error":"index name required"}`, http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := s.indices.Tokens(name)
+	if errors.Is(err, index.ErrNotFound) {
+		http.Error(w, fmt.Sprintf(`{"error":"index not found: %s"}`, name), http.StatusNotFound)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, tokens...) {
+		return
+	}
+
+	currency := queryCurrency(r.URL.Query())
+
+	value, err := s.indices.Value(r.Context(), name, currency)
+	if err != nil {
+		log.Printf("Error computing index %s: %v", name, err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	s.setCacheControl(w, r, EndpointPrices, value.UpdatedAt)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MaxCallbackLength bounds a JSONP callback name
+const MaxCallbackLength = 64
+
+// callbackParam wraps a JSON response in a JSONP callback
+var callbackParam = param{Name: "callback", In: "query", Type: "string", Description: "Wrap the response in a call to this JavaScript function (JSONP); refused when CORS origins are restricted", check: checkCallback}
+
+// errJSONPRestricted refuses a callback while CORS has an origin allowlist
+var errJSONPRestricted = errors.New("JSONP is only served when CORS allows any origin")
+
+// checkCallback accepts a JavaScript function name, optionally dotted like
+// jQuery.cb: letters, digits, '_' and '$', not starting with a digit
+func checkCallback(v string) error {
+	if len(v) > MaxCallbackLength {
+		return fmt.Errorf("callback longer than %d characters", MaxCallbackLength)
+	}
+	for _, part := range strings.Split(v, ".") {
+		if part == "" || part[0] >= '0' && part[0] <= '9' {
+			return errors.New("callback must be a JavaScript function name")
+		}
+		for _, c := range part {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '$') {
+				return errors.New("callback must be a JavaScript function name")
+			}
+		}
+	}
+	return nil
+}
+
+// jsonpWriter buffers a response so it can be wrapped in a callback
+type jsonpWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (jw *jsonpWriter) WriteHeader(code int) {
+	if jw.status == 0 {
+		jw.status = code
+	}
+}
+
+func (jw *jsonpWriter) Write(b []byte) (int, error) {
+	if jw.status == 0 {
+		jw.status = http.StatusOK
+	}
+	return jw.body.Write(b)
+}
+
+// jsonpMiddleware serves JSON responses as JavaScript calling ?callback=
+// with them, for pages embedding prices with a script tag. Errors are
+// wrapped too but keep their status. Responses are not conditional, as
+// their validators describe the JSON.
+//
+// A script tag is not subject to CORS, so JSONP would let any page read
+// what an origin allowlist keeps from it. It is refused unless CORS
+// allows any origin.
+func (s *Server) jsonpMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		callback := r.URL.Query().Get("callback")
+		if callback == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if s.current().corsOrigins != nil {
+			writeParamError(w, callbackParam.Name, errJSONPRestricted)
+			return
+		}
+		r = r.Clone(r.Context())
+		r.Header.Del("If-None-Match")
+		r.Header.Del("If-Modified-Since")
+
+		jw := &jsonpWriter{ResponseWriter: w}
+		next.ServeHTTP(jw, r)
+		if jw.status == 0 {
+			jw.status = http.StatusOK
+		}
+
+		body := bytes.TrimSpace(jw.body.Bytes())
+		if !json.Valid(body) {
+			w.WriteHeader(jw.status)
+			w.Write(jw.body.Bytes())
+			return
+		}
+		h := w.Header()
+		h.Set("Content-Type", "application/javascript; charset=utf-8")
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Del("Content-Length")
+		h.Del("ETag")
+		h.Del("Last-Modified")
+		w.WriteHeader(jw.status)
+		// The comment guards against the Rosetta Flash content sniffing attack
+		fmt.Fprintf(w, "/**/%s(%s);\n", callback, body)
+	}
+}
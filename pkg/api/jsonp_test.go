@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/luxfi/pricing/internal/testutil"
+	"github.com/luxfi/pricing/pkg/api"
+)
+
+func TestJSONP(t *testing.T) {
+	srv := testutil.NewServer(t)
+	srv.Provider.Set("bitcoin", 65000)
+
+	resp, err := http.Get(srv.URL + "/v1/price/bitcoin?callback=jQuery.cb_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(string(body), "/**/jQuery.cb_1({") {
+		t.Fatalf("JSONP with CORS open to any origin: %d %s", resp.StatusCode, body)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/javascript") {
+		t.Errorf("Content-Type = %q, want application/javascript", ct)
+	}
+}
+
+func TestJSONPRefusedWithRestrictedOrigins(t *testing.T) {
+	srv := testutil.NewServer(t, func(o *api.Options) {
+		o.CORSOrigins = []string{"https://app.lux.network"}
+	})
+	srv.Provider.Set("bitcoin", 65000)
+
+	for _, path := range []string{
+		"/v1/price/bitcoin?callback=steal",
+		"/v1/prices?ids=bitcoin&callback=steal",
+		"/v1/simple/price?ids=bitcoin&vs_currencies=usd&callback=steal",
+	} {
+		code, body := srv.Get(t, path)
+		if code != http.StatusBadRequest || strings.Contains(string(body), "steal(") {
+			t.Errorf("GET %s with an origin allowlist: %d %s, want 400", path, code, body)
+		}
+	}
+
+	// Without a callback the JSON is served as usual
+	if code, body := srv.Get(t, "/v1/price/bitcoin"); code != http.StatusOK {
+		t.Errorf("GET /v1/price/bitcoin: %d %s", code, body)
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// handleListingChanges returns the coins CoinGecko listed and delisted
+// since a time, the last day by default
+func (s *Server) handleListingChanges(w http.ResponseWriter, r *http.Request) {
+	if s.listings == nil {
+		http.Error(w, `{"error":"listing tracking not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	since := time.Now().Add(-24 * time.Hour)
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, `{"error":"since must be RFC3339"}`, http.StatusBadRequest)
+			return
+		}
+		since = t
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	json.NewEncoder(w).Encode(s.listings.Changes(since))
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/logos"
+)
+
+// checkLogoSize accepts a logo size in pixels between logos.MinSize and
+// logos.MaxSize
+func checkLogoSize(v string) error {
+	if n, err := strconv.Atoi(v); err != nil || n < logos.MinSize || n > logos.MaxSize {
+		return fmt.Errorf("size must be between %d and %d", logos.MinSize, logos.MaxSize)
+	}
+	return nil
+}
+
+// handleLogo serves a token's logo from the proxy cache, optionally scaled
+// down: GET /token/{id}/logo?size=64
+func (s *Server) handleLogo(w http.ResponseWriter, r *http.Request) {
+	if s.logos == nil {
+		http.Error(w, `{"error":"logos not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	token, ok := strings.CutSuffix(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/token/"), "/"), "/logo")
+	if !ok || token == "" || strings.Contains(token, "/") {
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, token) {
+		return
+	}
+	size := 0
+	if v := r.URL.Query().Get("size"); v != "" {
+		size, _ = strconv.Atoi(v)
+	}
+
+	logo, err := s.logos.Logo(r.Context(), token, size)
+	if errors.Is(err, logos.ErrNotFound) {
+		msg, _ := json.Marshal("no logo for " + token)
+		http.Error(w, `{"error":`+string(msg)+`}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching logo of %s: %v", token, err)
+		http.Error(w, `{"error":"logo unavailable"}`, http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(s.logos.TTL().Seconds())))
+	if checkNotModified(w, r, logo.ETag, logo.FetchedAt) {
+		return
+	}
+	w.Header().Set("Content-Type", logo.ContentType)
+	// SVG logos can carry scripts; never run them in our origin
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	w.Header().Set("Content-Length", strconv.Itoa(len(logo.Data)))
+	w.Write(logo.Data)
+}
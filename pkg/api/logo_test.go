@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/luxfi/pricing/internal/testutil"
+	"github.com/luxfi/pricing/pkg/api"
+	"github.com/luxfi/pricing/pkg/logos"
+)
+
+// newLogoServer serves a 64 by 64 logo for bitcoin and none for other
+// tokens
+func newLogoServer(t *testing.T) *testutil.Server {
+	t.Helper()
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		png.Encode(w, image.NewGray(image.Rect(0, 0, 64, 64)))
+	}))
+	t.Cleanup(cdn.Close)
+	svc := logos.NewService(logos.Options{Hosts: []string{"127.0.0.1"}}, func(ctx context.Context, tokenID string) (string, error) {
+		if tokenID != "bitcoin" {
+			return "", nil
+		}
+		return cdn.URL + "/bitcoin.png", nil
+	}, nil)
+	return testutil.NewServer(t, func(o *api.Options) { o.Logos = svc })
+}
+
+func TestLogoSize(t *testing.T) {
+	srv := newLogoServer(t)
+	tests := []struct {
+		size string
+		code int
+	}{
+		{"", http.StatusOK},
+		{"16", http.StatusOK},
+		{"512", http.StatusOK},
+		{"15", http.StatusBadRequest},
+		{"513", http.StatusBadRequest},
+		{"0", http.StatusBadRequest},
+		{"-64", http.StatusBadRequest},
+		{"big", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		path := "/v1/token/bitcoin/logo"
+		if tt.size != "" {
+			path += "?size=" + tt.size
+		}
+		if code, body := srv.Get(t, path); code != tt.code {
+			t.Errorf("GET %s: %d %s, want %d", path, code, body, tt.code)
+		}
+	}
+}
+
+func TestLogoNotFoundBody(t *testing.T) {
+	srv := newLogoServer(t)
+	code, body := srv.Get(t, "/v1/token/dogecoin/logo")
+	if code != http.StatusNotFound {
+		t.Fatalf("logo of an unknown token: %d %s, want 404", code, body)
+	}
+	var resp map[string]string
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("body %s is not JSON: %v", body, err)
+	}
+	if resp["error"] != "no logo for dogecoin" {
+		t.Errorf("body %s, want the error naming the token", body)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/luxfi/pricing/pkg/markets"
+)
+
+// handleMarkets returns the largest tokens by market cap
+func (s *Server) handleMarkets(w http.ResponseWriter, r *http.Request) {
+	if s.markets == nil {
+		http.Error(w, `{"error":"market data not configured"}`, http.StatusNotFound)
+		return
+	}
+	asCSV, ok := wantsCSV(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	limit := 100
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > markets.MaxLimit {
+			http.Error(w, fmt.Sprintf(`{"error":"limit must be between 1 and %d"}`, markets.MaxLimit), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	currency := queryCurrency(q)
+	sel, ok := parseFields(w, r, markets.MarketAsset{})
+	if !ok {
+		return
+	}
+	if sel != nil && asCSV {
+		http.Error(w, `{"error":"fields apply to JSON responses only"}`, http.StatusBadRequest)
+		return
+	}
+	loc, ok := parseFormat(w, r)
+	if !ok {
+		return
+	}
+	if loc != nil && asCSV {
+		http.Error(w, `{"error":"formatted values apply to JSON responses only"}`, http.StatusBadRequest)
+		return
+	}
+
+	category := q.Get("category")
+	if category != "" && (s.categories == nil || !s.categories.Known(category)) {
+		writeParamError(w, "category", fmt.Errorf("unknown category %q", category))
+		return
+	}
+
+	// A category is filtered from the full list, then limited
+	top := limit
+	if category != "" {
+		top = markets.MaxLimit
+	}
+	list, err := s.markets.Top(r.Context(), currency, top)
+	if err != nil {
+		log.Printf("Error fetching %s markets: %v", currency, err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	// Tenants only see tokens they are allowed; ranks stay global
+	if tenant := tenantFrom(r.Context()); tenant != nil {
+		allowed := make([]markets.MarketAsset, 0, len(list.Assets))
+		for _, a := range list.Assets {
+			if tenant.Allows(a.ID) {
+				allowed = append(allowed, a)
+			}
+		}
+		list.Assets = allowed
+	}
+	if s.categories != nil {
+		tagged := make([]markets.MarketAsset, 0, len(list.Assets))
+		for _, a := range list.Assets {
+			a.Categories = s.categories.Of(a.ID)
+			if category == "" || slices.Contains(a.Categories, category) {
+				tagged = append(tagged, a)
+			}
+		}
+		if len(tagged) > limit {
+			tagged = tagged[:limit]
+		}
+		list.Assets = tagged
+	}
+	s.overrideMarkets(list)
+	if s.supply != nil {
+		for i, a := range list.Assets {
+			if c, ok := s.supply.Check(a.ID); ok {
+				list.Assets[i].SupplyCheck = &c
+			}
+		}
+	}
+	if s.risk != nil {
+		for i, a := range list.Assets {
+			var volume float64
+			if list.Currency == "usd" {
+				volume = a.Volume24h
+			}
+			list.Assets[i].RiskFlags = s.risk.Flags(a.ID, volume)
+		}
+	}
+	if wantsRounded(r) {
+		roundMarkets(s.rounding, list)
+	}
+	if loc != nil {
+		formatMarkets(loc, list)
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	w.Header().Add("Vary", "Accept")
+	if asCSV {
+		rows := make([][]string, len(list.Assets))
+		for i, a := range list.Assets {
+			rows[i] = []string{
+				strconv.Itoa(a.Rank), a.ID, a.Symbol, a.Name, list.Currency,
+				csvFloat(a.Price), csvFloat(a.MarketCap), csvFloat(a.Volume24h), csvFloat(a.Change24h), csvFloat(a.Change7d),
+				csvTime(a.UpdatedAt),
+			}
+		}
+		writeCSV(w, "markets-"+list.Currency+".csv",
+			[]string{"rank", "id", "symbol", "name", "currency", "price", "market_cap", "volume_24h", "change_24h", "change_7d", "updated_at"}, rows)
+		return
+	}
+	if wantsNDJSON(r) {
+		nw := newNDJSONWriter(w)
+		for _, a := range list.Assets {
+			if sel == nil {
+				nw.write(a)
+				continue
+			}
+			obj, err := sel.object(a)
+			if err != nil {
+				// Records already sent can't be taken back; stop here
+				log.Printf("Error selecting market fields: %v", err)
+				return
+			}
+			nw.write(obj)
+		}
+		nw.close()
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if sel != nil {
+		selected, err := sel.markets(list)
+		if err != nil {
+			http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(selected)
+		return
+	}
+	json.NewEncoder(w).Encode(list)
+}
@@ -0,0 +1,250 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// Middleware wraps a handler with cross-cutting behavior
+type Middleware func(http.Handler) http.Handler
+
+// Chain composes middleware so the first one runs outermost
+func Chain(mws ...Middleware) Middleware {
+	return func(h http.Handler) http.Handler {
+		for i := len(mws) - 1; i >= 0; i-- {
+			h = mws[i](h)
+		}
+		return h
+	}
+}
+
+// Per-route middleware stages, in the order they run. Routes opt out of a
+// stage by listing it in route.Skip.
+const (
+	StageLogging   = "logging"
+	StageShed      = "shed"
+	StageMetrics   = "metrics"
+	StageAuth      = "auth"
+	StageRateLimit = "ratelimit"
+	StageValidate  = "validate"
+	StageTimeout   = "timeout"
+	StageHedge     = "hedge"
+)
+
+// RequestObserver is called after every routed request with the route
+// pattern, response status and handler latency
+type RequestObserver func(route string, status int, elapsed time.Duration)
+
+// routeMiddleware builds the per-route chain for rt
+func (s *Server) routeMiddleware(rt route) Middleware {
+	stages := []struct {
+		name string
+		mw   Middleware
+	}{
+		{StageLogging, s.loggingMiddleware},
+		{StageShed, s.shedMiddleware(rt)},
+		{StageMetrics, s.metricsMiddleware(rt.Pattern)},
+		{StageAuth, s.authMiddleware(rt.Pattern)},
+		{StageRateLimit, s.rateLimitMiddleware},
+		{StageValidate, s.validateMiddleware(rt)},
+		{StageTimeout, s.timeoutMiddleware(rt.Pattern)},
+		{StageHedge, s.hedgeMiddleware(rt.Pattern)},
+	}
+
+	var mws []Middleware
+	for _, st := range stages {
+		if !rt.skips(st.name) {
+			mws = append(mws, st.mw)
+		}
+	}
+	return Chain(mws...)
+}
+
+// statusRecorder captures the status code and size of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (sr *statusRecorder) WriteHeader(code int) {
+	if sr.status == 0 {
+		sr.status = code
+	}
+	sr.ResponseWriter.WriteHeader(code)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	n, err := sr.ResponseWriter.Write(b)
+	sr.bytes += n
+	return n, err
+}
+
+func (sr *statusRecorder) Flush() {
+	if f, ok := sr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+func (sr *statusRecorder) code() int {
+	if sr.status == 0 {
+		return http.StatusOK
+	}
+	return sr.status
+}
+
+type accessLogKey struct{}
+
+// accessLogEntry collects fields set by inner stages for the access log
+type accessLogEntry struct {
+	tenant string
+}
+
+// loggingMiddleware writes one access log line per request
+func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accessLog := s.current().accessLog
+		if accessLog == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		entry := &accessLogEntry{tenant: "-"}
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), accessLogKey{}, entry)))
+
+		// RequestURI is the path as sent, before /v1 is stripped
+		uri := r.RequestURI
+		if uri == "" {
+			uri = r.URL.RequestURI()
+		}
+		accessLog.Printf("%s %s %s %d %dB %s tenant=%s",
+			remoteIP(r), r.Method, uri, rec.code(), rec.bytes,
+			time.Since(start).Round(time.Microsecond), entry.tenant)
+	})
+}
+
+// metricsMiddleware reports each request to the configured observer and
+// SLO tracker. Shed requests are not reported, so shedding does not
+// spend the error budget it protects.
+func (s *Server) metricsMiddleware(pattern string) Middleware {
+	return func(next http.Handler) http.Handler {
+		if s.observer == nil && s.slo == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			elapsed := time.Since(start)
+			if s.observer != nil {
+				s.observer(pattern, rec.code(), elapsed)
+			}
+			if s.slo != nil {
+				s.slo.Observe(pattern, rec.code(), elapsed)
+			}
+		})
+	}
+}
+
+// authMiddleware resolves the tenant from the API key and records usage
+// under the route pattern
+func (s *Server) authMiddleware(pattern string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenant := s.tenants.Resolve(requestAPIKey(r))
+			if tenant == nil {
+				http.Error(w, `{"error":"invalid API key"}`, http.StatusUnauthorized)
+				return
+			}
+
+			tenant.usage.record(pattern)
+			if entry, ok := r.Context().Value(accessLogKey{}).(*accessLogEntry); ok {
+				entry.tenant = tenant.Name
+			}
+
+			ctx := context.WithValue(r.Context(), tenantKey{}, tenant)
+			if tenant.CacheTTL.Duration > 0 {
+				ctx = cache.WithTTL(ctx, tenant.CacheTTL.Duration)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// requestTimeout returns the upstream time budget of a route pattern;
+// none if zero
+func (s *Server) requestTimeout(pattern string) time.Duration {
+	if timeout, ok := s.timeouts[pattern]; ok {
+		return timeout
+	}
+	return s.timeout
+}
+
+// rateLimitMiddleware enforces the request tenant's rate limit
+func (s *Server) rateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant := tenantFrom(r.Context())
+		if tenant == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if limiter := tenant.limiter.Load(); limiter != nil {
+			if ok, wait := limiter.allow(); !ok {
+				tenant.usage.rateLimited.Add(1)
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				http.Error(w, `{"error":"rate limit exceeded"}`, http.StatusTooManyRequests)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// hedgeMiddleware marks the provider calls of latency sensitive routes to
+// be hedged across the balanced providers
+func (s *Server) hedgeMiddleware(pattern string) Middleware {
+	return func(next http.Handler) http.Handler {
+		if !s.hedged[pattern] {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(providers.WithHedge(r.Context())))
+		})
+	}
+}
+
+// timeoutMiddleware puts the route's upstream time budget on the request
+// context, so provider calls made for it fail fast instead of waiting out
+// the providers' own timeout
+func (s *Server) timeoutMiddleware(pattern string) Middleware {
+	return func(next http.Handler) http.Handler {
+		timeout := s.requestTimeout(pattern)
+		if timeout <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ndjsonType is the media type of newline-delimited JSON
+const ndjsonType = "application/x-ndjson"
+
+// ndjsonFlushEvery is how many records are written between flushes
+const ndjsonFlushEvery = 100
+
+// wantsNDJSON reports whether the Accept header asks for newline-delimited
+// JSON, and does not refuse it with q=0
+func wantsNDJSON(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || mediaType != ndjsonType {
+			continue
+		}
+		q, err := strconv.ParseFloat(params["q"], 64)
+		return params["q"] == "" || err == nil && q > 0
+	}
+	return false
+}
+
+// ndjsonWriter streams records as newline-delimited JSON, one per line,
+// flushing every ndjsonFlushEvery records so clients can process them
+// before the response ends
+type ndjsonWriter struct {
+	rc  *http.ResponseController
+	enc *json.Encoder
+	n   int
+	err error
+}
+
+// newNDJSONWriter sets the NDJSON content type on w and returns a writer
+// of records to it
+func newNDJSONWriter(w http.ResponseWriter) *ndjsonWriter {
+	w.Header().Set("Content-Type", ndjsonType)
+	return &ndjsonWriter{rc: http.NewResponseController(w), enc: json.NewEncoder(w)}
+}
+
+// write encodes a record on its own line. Once a write fails, the rest
+// are dropped.
+func (nw *ndjsonWriter) write(v any) {
+	if nw.err != nil {
+		return
+	}
+	if nw.err = nw.enc.Encode(v); nw.err != nil {
+		return
+	}
+	if nw.n++; nw.n%ndjsonFlushEvery == 0 {
+		nw.rc.Flush()
+	}
+}
+
+// close flushes the records not yet flushed
+func (nw *ndjsonWriter) close() {
+	if nw.err == nil && nw.n%ndjsonFlushEvery != 0 {
+		nw.rc.Flush()
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// handleNFT returns an NFT collection's floor price, volume and owners
+func (s *Server) handleNFT(w http.ResponseWriter, r *http.Request) {
+	if s.nfts == nil {
+		http.Error(w, `{"error":"NFT data not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/nft/"), "/")
+	if id == "" {
+		http.Error(w, `{"error":"collection required"}`, http.StatusBadRequest)
+		return
+	}
+
+	collection, err := s.nfts.Collection(r.Context(), id)
+	if errors.Is(err, providers.ErrNFTNotFound) {
+		http.Error(w, fmt.Sprintf(`{"error":"collection not found: %s"}`, id), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching NFT collection %s: %v", id, err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	json.NewEncoder(w).Encode(collection)
+}
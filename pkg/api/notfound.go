@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// CodeTokenNotFound is the code of every 404 for a token no provider knows
+const CodeTokenNotFound = "token_not_found"
+
+// maxSuggestions is the most similar ids offered for an unknown token
+const maxSuggestions = 5
+
+// notFoundError is the body of a 404 for an unknown token
+type notFoundError struct {
+	Error       string   `json:"error"`
+	Code        string   `json:"code"`
+	Token       string   `json:"token"`
+	Suggestions []string `json:"suggestions"`
+}
+
+// writeNotFound answers 404 for an unknown token, suggesting similar ids
+// from the alias table. token is the id as requested; if empty, it is
+// taken from err.
+func (s *Server) writeNotFound(w http.ResponseWriter, token string, err error) {
+	var nf *providers.NotFoundError
+	if token == "" && errors.As(err, &nf) {
+		token = nf.Token
+	}
+	body := notFoundError{
+		Error:       "token not found: " + token,
+		Code:        CodeTokenNotFound,
+		Token:       token,
+		Suggestions: []string{},
+	}
+	if s.aliases != nil {
+		body.Suggestions = s.aliases.Suggest(token, maxSuggestions)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusNotFound)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+	"github.com/luxfi/pricing/pkg/onramp"
+)
+
+// maxOnrampAmount bounds the fiat amount of an on-ramp quote
+const maxOnrampAmount = 1_000_000
+
+// handleOnrampQuote compares the configured on-ramps' offers for buying a
+// token with an amount of fiat, fees included, lowest effective price
+// first
+func (s *Server) handleOnrampQuote(w http.ResponseWriter, r *http.Request) {
+	if s.onramps == nil {
+		http.Error(w, `{"error":"on-ramps not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	q := r.URL.Query()
+	token := strings.ToLower(q.Get("token"))
+	if token == "" {
+		http.Error(w, `{"error":"token required"}`, http.StatusBadRequest)
+		return
+	}
+	if s.aliases != nil {
+		token = s.aliases.Resolve(token)
+	}
+	if !s.checkTokensAllowed(w, r, token) {
+		return
+	}
+	fiat := q.Get("fiat")
+	if fiat == "" {
+		fiat = "usd"
+	}
+	amount, err := decimal.Parse(q.Get("amount"))
+	if err != nil || amount.Sign() <= 0 || amount.Sub(decimal.FromFloat(maxOnrampAmount)).Sign() > 0 {
+		http.Error(w, fmt.Sprintf(`{"error":"amount must be a positive number up to %d"}`, maxOnrampAmount), http.StatusBadRequest)
+		return
+	}
+
+	comparison, err := s.onramps.Compare(r.Context(), token, fiat, amount)
+	if errors.Is(err, onramp.ErrUnsupported) {
+		http.Error(w, fmt.Sprintf(`{"error":"no on-ramp sells %s"}`, token), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+	}
+	json.NewEncoder(w).Encode(comparison)
+}
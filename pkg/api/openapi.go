@@ -0,0 +1,298 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OpenAPIVersion is the version of the generated API description
+const OpenAPIVersion = "1.0.0"
+
+var (
+	specOnce sync.Once
+	specJSON []byte
+)
+
+// OpenAPISpec returns the OpenAPI 3 document for the versioned API,
+// generated from the route table
+func OpenAPISpec() []byte {
+	specOnce.Do(func() {
+		specJSON, _ = json.MarshalIndent(buildSpec(nil), "", "  ")
+	})
+	return specJSON
+}
+
+// buildSpec generates the spec of the routes include accepts, or of all
+// routes if it is nil
+func buildSpec(include func(route) bool) map[string]interface{} {
+	schemas := make(map[string]interface{})
+	paths := make(map[string]interface{})
+
+	for _, rt := range routes {
+		if include != nil && !include(rt) {
+			continue
+		}
+		path := "/" + APIVersion + rt.Path
+		if rt.Path == "/health" {
+			path = rt.Path
+		}
+
+		var params []interface{}
+		for _, p := range rt.Params {
+			param := map[string]interface{}{
+				"name":     p.Name,
+				"in":       p.In,
+				"required": p.Required || p.In == "path",
+				"schema":   map[string]string{"type": p.Type},
+			}
+			if p.Description != "" {
+				param["description"] = p.Description
+			}
+			params = append(params, param)
+		}
+
+		status := rt.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := map[string]interface{}{"description": http.StatusText(status)}
+		if rt.Response != nil {
+			success["content"] = map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": schemaFor(reflect.TypeOf(rt.Response), schemas),
+				},
+			}
+		}
+
+		if len(rt.Headers) > 0 {
+			headers := make(map[string]interface{})
+			for name, desc := range rt.Headers {
+				headers[name] = map[string]interface{}{
+					"description": desc,
+					"schema":      map[string]string{"type": "string"},
+				}
+			}
+			success["headers"] = headers
+		}
+
+		op := map[string]interface{}{
+			"summary":     rt.Summary,
+			"operationId": operationID(rt),
+			"tags":        []string{rt.Tag},
+			"responses": map[string]interface{}{
+				strconv.Itoa(status): success,
+				"default": map[string]interface{}{
+					"description": "Error",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{
+							"schema": map[string]string{"$ref": "#/components/schemas/Error"},
+						},
+					},
+				},
+			},
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+		if rt.Request != nil {
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": schemaFor(reflect.TypeOf(rt.Request), schemas),
+					},
+				},
+			}
+		}
+		if rt.Admin {
+			op["security"] = []map[string][]string{{"adminBearer": {}}}
+		} else if rt.Path != "/health" {
+			op["security"] = []map[string][]string{{}, {"apiKey": {}}}
+		}
+
+		item, _ := paths[path].(map[string]interface{})
+		if item == nil {
+			item = make(map[string]interface{})
+			paths[path] = item
+		}
+		item[strings.ToLower(rt.Method)] = op
+	}
+
+	// Invalid parameters add param; unknown tokens add code, token and
+	// suggestions
+	schemas["Error"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"error":       map[string]string{"type": "string"},
+			"param":       map[string]string{"type": "string"},
+			"code":        map[string]string{"type": "string"},
+			"token":       map[string]string{"type": "string"},
+			"suggestions": map[string]interface{}{"type": "array", "items": map[string]string{"type": "string"}},
+		},
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]string{
+			"title":   "Lux Pricing API",
+			"version": OpenAPIVersion,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"apiKey":      map[string]string{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"adminBearer": map[string]string{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+// operationID derives a stable camelCase id such as getPriceTokenId
+func operationID(rt route) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(rt.Method))
+	for _, part := range strings.FieldsFunc(rt.Path, func(r rune) bool {
+		return r == '/' || r == '{' || r == '}' || r == '_'
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	numberType = reflect.TypeOf(json.Number(""))
+)
+
+// schemaFor builds a JSON schema for t, registering named structs as
+// components
+func schemaFor(t reflect.Type, schemas map[string]interface{}) interface{} {
+	if t.Kind() == reflect.Ptr {
+		return schemaFor(t.Elem(), schemas)
+	}
+	if t == timeType {
+		return map[string]string{"type": "string", "format": "date-time"}
+	}
+	if t == numberType {
+		return map[string]string{"type": "number"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]string{"type": "string"}
+	case reflect.Bool:
+		return map[string]string{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]string{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]string{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaFor(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaFor(t.Elem(), schemas)}
+	case reflect.Struct:
+		name := componentName(t)
+		if _, ok := schemas[name]; !ok {
+			schemas[name] = nil // reserve the name before recursing
+			schemas[name] = structSchema(t, schemas)
+		}
+		return map[string]string{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]interface{}{}
+}
+
+func structSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	props := make(map[string]interface{})
+	var required []string
+	addFields(t, schemas, props, &required)
+	schema := map[string]interface{}{"type": "object", "properties": props}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// addFields adds the JSON fields of t to props, flattening untagged
+// embedded structs as encoding/json does
+func addFields(t reflect.Type, schemas, props map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			addFields(f.Type, schemas, props, required)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = schemaFor(f.Type, schemas)
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Ptr {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// componentName exports a type name for use as a schema component. Wire
+// types are named for what they hold already, so they keep their names.
+func componentName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+	name := t.Name()
+	if pkg == "api" || pkg == "wire" {
+		return strings.ToUpper(name[:1]) + name[1:]
+	}
+	return strings.ToUpper(pkg[:1]) + pkg[1:] + name
+}
+
+// handleOpenAPI serves the OpenAPI document
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	if s.spec != nil {
+		w.Write(s.spec)
+		return
+	}
+	w.Write(OpenAPISpec())
+}
+
+// docsPage renders Swagger UI for /openapi.json
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Lux Pricing API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+// handleDocs serves Swagger UI
+func (s *Server) handleDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write([]byte(docsPage))
+}
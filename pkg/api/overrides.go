@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/markets"
+	"github.com/luxfi/pricing/pkg/overrides"
+)
+
+// maxOverrideBody bounds a PUT /admin/overrides/{token_id} body
+const maxOverrideBody = 16 << 10
+
+// override returns the metadata override of a token, or of the token an
+// alias stands for
+func (s *Server) override(tokenID string) (overrides.Override, bool) {
+	if s.overrides == nil || s.overrides.Len() == 0 {
+		return overrides.Override{}, false
+	}
+	if s.aliases != nil {
+		tokenID = s.aliases.Resolve(tokenID)
+	}
+	return s.overrides.Get(tokenID)
+}
+
+// addOverride patches a price with its token's metadata override, if any
+func (s *Server) addOverride(p *cache.PriceResponse) {
+	o, ok := s.override(p.ID)
+	if !ok {
+		return
+	}
+	if o.Name != "" {
+		p.Name = o.Name
+	}
+	if o.Symbol != "" {
+		p.Symbol = o.Symbol
+	}
+	p.Decimals, p.Tags = o.Decimals, o.Tags
+}
+
+// overrideMarkets patches the assets of a market list with their tokens'
+// metadata overrides, copying them so the cached list is left as fetched
+func (s *Server) overrideMarkets(list *markets.List) {
+	if s.overrides == nil || s.overrides.Len() == 0 {
+		return
+	}
+	assets := make([]markets.MarketAsset, len(list.Assets))
+	for i, a := range list.Assets {
+		if o, ok := s.override(a.ID); ok {
+			if o.Name != "" {
+				a.Name = o.Name
+			}
+			if o.Symbol != "" {
+				a.Symbol = o.Symbol
+			}
+			a.Decimals, a.Tags = o.Decimals, o.Tags
+		}
+		assets[i] = a
+	}
+	list.Assets = assets
+}
+
+// overrideID returns the token id of an /admin/overrides/{token_id}
+// request, answering 400 if there is none
+func overrideID(w http.ResponseWriter, r *http.Request) string {
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/admin/overrides/"), "/")
+	if id == "" {
+		http.Error(w, `{"error":"token_id required"}`, http.StatusBadRequest)
+	}
+	return id
+}
+
+// handleOverrides lists token metadata overrides: GET /admin/overrides
+func (s *Server) handleOverrides(w http.ResponseWriter, r *http.Request) {
+	if s.overrides == nil {
+		http.Error(w, `{"error":"overrides not configured"}`, http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(overridesResponse{Overrides: s.overrides.All()})
+}
+
+// handleSetOverride replaces a token's metadata override and drops what
+// is cached of the token, so the next response carries it:
+// PUT /admin/overrides/{token_id}
+func (s *Server) handleSetOverride(w http.ResponseWriter, r *http.Request) {
+	if s.overrides == nil {
+		http.Error(w, `{"error":"overrides not configured"}`, http.StatusNotFound)
+		return
+	}
+	id := overrideID(w, r)
+	if id == "" {
+		return
+	}
+	var o overrides.Override
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxOverrideBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&o); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"invalid override: %s"}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	if err := o.Check(); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	fields, _ := json.Marshal(o)
+	if err := s.audit(r, "overrides.set", map[string]string{"token": id, "override": string(fields)}); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if err := s.overrides.Set(id, o); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+		return
+	}
+	s.forgetOverridden(id)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(o)
+}
+
+// handleDeleteOverride removes a token's metadata override:
+// DELETE /admin/overrides/{token_id}
+func (s *Server) handleDeleteOverride(w http.ResponseWriter, r *http.Request) {
+	if s.overrides == nil {
+		http.Error(w, `{"error":"overrides not configured"}`, http.StatusNotFound)
+		return
+	}
+	id := overrideID(w, r)
+	if id == "" {
+		return
+	}
+	if err := s.audit(r, "overrides.delete", map[string]string{"token": id}); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+		return
+	}
+	deleted, err := s.overrides.Delete(id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.Error(w, `{"error":"no override for token"}`, http.StatusNotFound)
+		return
+	}
+	s.forgetOverridden(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// forgetOverridden drops the prices and logos cached of a token whose
+// override changed
+func (s *Server) forgetOverridden(id string) {
+	s.cache.Flush(strings.ToLower(id))
+	if s.logos != nil {
+		s.logos.Forget(id)
+	}
+}
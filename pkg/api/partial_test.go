@@ -0,0 +1,73 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/luxfi/pricing/internal/testutil"
+	"github.com/luxfi/pricing/pkg/api"
+)
+
+// TestSimplePricePartialFailure checks that a currency which fails is left
+// out of the CoinGecko-shaped body and named in X-Failed-Currencies.
+func TestSimplePricePartialFailure(t *testing.T) {
+	srv := testutil.NewServer(t)
+	srv.Provider.Set("bitcoin", 65000)
+	srv.Provider.FailNext(errors.New("upstream down"))
+
+	resp, err := http.Get(srv.URL + "/v1/simple/price?ids=bitcoin&vs_currencies=usd,eur")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d, want 200", resp.StatusCode)
+	}
+	var body map[string]map[string]float64
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+
+	// Either currency may be the one fetched first, and so the one to fail
+	failed := resp.Header.Get("X-Failed-Currencies")
+	kept := map[string]string{"usd": "eur", "eur": "usd"}[failed]
+	if kept == "" {
+		t.Fatalf("X-Failed-Currencies = %q, want usd or eur", failed)
+	}
+	if _, ok := body["bitcoin"][failed]; ok {
+		t.Errorf("body %v has the failed currency %s", body, failed)
+	}
+	if body["bitcoin"][kept] != 65000 {
+		t.Errorf("body %v, want bitcoin priced in %s", body, kept)
+	}
+}
+
+// TestOpenAPIDocumentsFailedCurrencies checks that the spec documents the
+// partial-failure header of the routes that set it.
+func TestOpenAPIDocumentsFailedCurrencies(t *testing.T) {
+	var spec struct {
+		Paths map[string]map[string]struct {
+			Responses map[string]struct {
+				Headers map[string]interface{} `json:"headers"`
+			} `json:"responses"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(api.OpenAPISpec(), &spec); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/v1/simple/price", "/v1/coins/{id}"} {
+		op, ok := spec.Paths[path]["get"]
+		if !ok {
+			t.Errorf("spec has no GET %s", path)
+			continue
+		}
+		if _, ok := op.Responses["200"].Headers["X-Failed-Currencies"]; !ok {
+			t.Errorf("GET %s does not document X-Failed-Currencies", path)
+		}
+	}
+}
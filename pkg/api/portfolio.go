@@ -0,0 +1,179 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/luxfi/pricing/pkg/portfolio"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// maxPortfolioBody bounds portfolio request bodies
+const maxPortfolioBody = 256 << 10
+
+// handlePortfolioPerformance values holdings over time and returns their
+// PnL and returns
+func (s *Server) handlePortfolioPerformance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+	if s.portfolio == nil {
+		http.Error(w, `{"error":"portfolio not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	var req portfolio.Request
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPortfolioBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"invalid portfolio: %s"}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	if err := portfolio.ValidateRequest(&req); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"invalid portfolio: %s"}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	ids := make([]string, len(req.Holdings))
+	for i, h := range req.Holdings {
+		ids[i] = h.Token
+	}
+	if !s.checkTokensAllowed(w, r, ids...) {
+		return
+	}
+
+	perf, err := s.portfolio.Performance(r.Context(), req)
+	if errors.Is(err, providers.ErrTokenNotFound) {
+		s.writeNotFound(w, "", err)
+		return
+	}
+	if err != nil {
+		log.Printf("Error evaluating portfolio: %v", err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(perf)
+}
+
+// handlePortfolioAllocation breaks holdings down by token, category,
+// chain, stability and staking
+func (s *Server) handlePortfolioAllocation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+	if s.portfolio == nil {
+		http.Error(w, `{"error":"portfolio not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	var req portfolio.AllocationRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPortfolioBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"invalid portfolio: %s"}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	if err := portfolio.ValidateAllocationRequest(&req); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"invalid portfolio: %s"}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	ids := make([]string, len(req.Holdings))
+	for i, h := range req.Holdings {
+		ids[i] = h.Token
+	}
+	if !s.checkTokensAllowed(w, r, ids...) {
+		return
+	}
+
+	alloc, err := s.portfolio.Allocation(r.Context(), req)
+	if errors.Is(err, providers.ErrTokenNotFound) {
+		s.writeNotFound(w, "", err)
+		return
+	}
+	if err != nil {
+		log.Printf("Error allocating portfolio: %v", err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(alloc)
+}
+
+// handleTaxLots prices acquisitions at their time and now, as JSON or a
+// CSV export
+func (s *Server) handleTaxLots(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+	if s.portfolio == nil {
+		http.Error(w, `{"error":"portfolio not configured"}`, http.StatusNotFound)
+		return
+	}
+	asCSV, ok := wantsCSV(w, r)
+	if !ok {
+		return
+	}
+
+	var req portfolio.LotsRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPortfolioBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"invalid lots: %s"}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	if err := portfolio.ValidateLotsRequest(&req); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"invalid lots: %s"}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	ids := make([]string, len(req.Lots))
+	for i, l := range req.Lots {
+		ids[i] = l.Token
+	}
+	if !s.checkTokensAllowed(w, r, ids...) {
+		return
+	}
+
+	lots, err := s.portfolio.Lots(r.Context(), req)
+	if errors.Is(err, providers.ErrTokenNotFound) {
+		s.writeNotFound(w, "", err)
+		return
+	}
+	if err != nil {
+		log.Printf("Error pricing tax lots: %v", err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	if asCSV {
+		optional := func(v *float64) string {
+			if v == nil {
+				return ""
+			}
+			return csvFloat(*v)
+		}
+		rows := make([][]string, len(lots.Lots))
+		for i, l := range lots.Lots {
+			rows[i] = []string{l.ID, l.Token, csvTime(l.Time), csvFloat(l.Amount), optional(l.AcquisitionPrice),
+				optional(l.CostBasis), l.PriceStr, l.ValueStr, optional(l.Gain), optional(l.GainPercent),
+				strconv.Itoa(l.HoldingDays), l.Term}
+		}
+		writeCSV(w, fmt.Sprintf("tax-lots-%s.csv", lots.Currency),
+			[]string{"id", "token", "time", "amount", "acquisition_price", "cost_basis", "price", "value",
+				"gain", "gain_percent", "holding_days", "term"}, rows)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lots)
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/quorum"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// quoteResponse is a price quote as EIP-712 typed data. The digest is the
+// hash the signature is over, for checking a contract's encoding against.
+type quoteResponse = wire.QuoteResponse
+
+// noQuorumResponse explains a quote refused because too few providers
+// agree on the price
+type noQuorumResponse struct {
+	Error  string         `json:"error"`
+	Quorum *quorum.Result `json:"quorum"`
+}
+
+// handleQuote returns a price quote with an expiry, signed as EIP-712
+// typed data with ?signed=eip712, for contracts to accept off-chain. If a
+// quorum is required, the price is the one enough providers agree on and
+// a 503 explains who quoted what when they don't.
+func (s *Server) handleQuote(w http.ResponseWriter, r *http.Request) {
+	if s.quotes == nil {
+		http.Error(w, `{"error":"quotes not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	token := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/quote/"), "/")
+	if token == "" {
+		http.Error(w, `{"error":"token id required"}`, http.StatusBadRequest)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, token) {
+		return
+	}
+
+	q := r.URL.Query()
+	signed := false
+	switch q.Get("signed") {
+	case "":
+	case "eip712":
+		signed = true
+		if s.quotes.Signer() == "" {
+			http.Error(w, `{"error":"quote signing not configured"}`, http.StatusNotImplemented)
+			return
+		}
+	default:
+		http.Error(w, `{"error":"signed must be eip712"}`, http.StatusBadRequest)
+		return
+	}
+	currency := queryCurrency(q)
+
+	var resp quoteResponse
+	var exact decimal.Decimal
+	var updatedAt time.Time
+	if s.quorum != nil {
+		agreed, err := s.quorum.Check(r.Context(), token, currency)
+		var noQuorum *quorum.Error
+		switch {
+		case errors.As(err, &noQuorum):
+			log.Printf("Refusing quote: %v", err)
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Cache-Control", "no-store")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(noQuorumResponse{Error: "no quorum", Quorum: noQuorum.Result})
+			return
+		case errors.Is(err, providers.ErrTokenNotFound):
+			s.writeNotFound(w, token, err)
+			return
+		case err != nil:
+			writeUpstreamError(w, r, "price unavailable", err)
+			return
+		}
+		resp.Token, resp.Currency, resp.Source, resp.Quorum = agreed.Token, agreed.Currency, "quorum", agreed
+		exact, updatedAt = agreed.Price, agreed.CheckedAt
+	} else {
+		price, err := s.cache.GetPrice(r.Context(), token, currency)
+		if errors.Is(err, providers.ErrTokenNotFound) {
+			s.writeNotFound(w, token, err)
+			return
+		}
+		if err != nil {
+			writeUpstreamError(w, r, "price unavailable", err)
+			return
+		}
+		if exact, err = decimal.Parse(price.PriceStr); err != nil {
+			exact = decimal.FromFloat(price.Price)
+		}
+		resp.Token, resp.Currency, resp.Source = price.ID, price.Currency, price.Source
+		updatedAt = price.UpdatedAt
+	}
+
+	quote := s.quotes.Quote(resp.Token, resp.Currency, exact, updatedAt, time.Now())
+	resp.PriceStr = exact.String()
+	resp.Quote = quote
+	resp.Domain = wire.EIP712Domain(s.quotes.Domain())
+	resp.Digest = "0x" + hex.EncodeToString(s.quotes.Digest(quote))
+	if signed {
+		signature, err := s.quotes.Sign(r.Context(), quote)
+		if err != nil {
+			writeUpstreamError(w, r, "quote signing failed", err)
+			return
+		}
+		resp.Signature, resp.Signer = signature, s.quotes.Signer()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(resp)
+}
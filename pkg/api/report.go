@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/luxfi/pricing/pkg/report"
+)
+
+// handleReport returns the latest market report as JSON, Markdown or HTML
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	if s.reports == nil {
+		http.Error(w, `{"error":"reports not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "", "json", "markdown", "html":
+	default:
+		http.Error(w, fmt.Sprintf(`{"error":"unsupported format: %s"}`, format), http.StatusBadRequest)
+		return
+	}
+
+	rep, err := s.reports.Latest(r.Context())
+	if err != nil {
+		log.Printf("Error generating report: %v", err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	switch format {
+	case "markdown":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		io.WriteString(w, report.Markdown(rep))
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, report.HTML(rep))
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rep)
+	}
+}
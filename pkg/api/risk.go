@@ -0,0 +1,49 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/risk"
+)
+
+// handleRisk lists every flagged token the caller may see, the most
+// dangerous first
+func (s *Server) handleRisk(w http.ResponseWriter, r *http.Request) {
+	if s.risk == nil {
+		http.Error(w, `{"error":"risk flags not enabled"}`, http.StatusNotFound)
+		return
+	}
+
+	feed := s.risk.Feed()
+	if tenant := tenantFrom(r.Context()); tenant != nil {
+		allowed := make([]risk.TokenFlags, 0, len(feed.Tokens))
+		for _, t := range feed.Tokens {
+			if tenant.Allows(t.Token) {
+				allowed = append(allowed, t)
+			}
+		}
+		feed.Tokens = allowed
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	json.NewEncoder(w).Encode(feed)
+}
+
+// addRiskFlags adds a price's risk flags, if risk flags are enabled. The
+// volume only counts toward liquidity if it is in USD.
+func (s *Server) addRiskFlags(p *cache.PriceResponse) {
+	if s.risk == nil {
+		return
+	}
+	var volume float64
+	if p.Currency == "usd" {
+		volume = p.Volume24h
+	}
+	p.RiskFlags = s.risk.Flags(p.ID, volume)
+}
@@ -0,0 +1,932 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/alerts"
+	"github.com/luxfi/pricing/pkg/analytics"
+	"github.com/luxfi/pricing/pkg/audit"
+	"github.com/luxfi/pricing/pkg/bridge"
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/categories"
+	"github.com/luxfi/pricing/pkg/changes"
+	"github.com/luxfi/pricing/pkg/derivatives"
+	"github.com/luxfi/pricing/pkg/extremes"
+	"github.com/luxfi/pricing/pkg/gas"
+	"github.com/luxfi/pricing/pkg/global"
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/index"
+	"github.com/luxfi/pricing/pkg/listings"
+	"github.com/luxfi/pricing/pkg/markets"
+	"github.com/luxfi/pricing/pkg/nft"
+	"github.com/luxfi/pricing/pkg/onramp"
+	"github.com/luxfi/pricing/pkg/oracle"
+	"github.com/luxfi/pricing/pkg/overrides"
+	"github.com/luxfi/pricing/pkg/portfolio"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/report"
+	"github.com/luxfi/pricing/pkg/risk"
+	"github.com/luxfi/pricing/pkg/stream"
+	"github.com/luxfi/pricing/pkg/supply"
+	"github.com/luxfi/pricing/pkg/ticks"
+	"github.com/luxfi/pricing/pkg/tokenprice"
+	"github.com/luxfi/pricing/pkg/treasury"
+	"github.com/luxfi/pricing/pkg/trending"
+	"github.com/luxfi/pricing/pkg/tvl"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// route describes one endpoint. The table drives both the mux and the
+// OpenAPI spec, so documentation cannot drift from what is served.
+type route struct {
+	Method  string
+	Path    string // OpenAPI path relative to the version root
+	Pattern string // ServeMux pattern
+	Summary string
+	Tag     string
+	// Feature is the group the route is enabled and disabled with; Tag
+	// if empty
+	Feature string
+	Admin   bool
+	Skip    []string // middleware stages the route opts out of
+	Params  []param
+	// Request is a value of the JSON request body type, if any
+	Request interface{}
+	// Response is a value of the success response type; nil if there is
+	// no body
+	Response interface{}
+	// Status is the success status code; 200 if zero
+	Status int
+	// JSONP wraps the response in ?callback= if given
+	JSONP bool
+	// Headers documents the response headers the route may add, by name
+	Headers map[string]string
+
+	handler func(s *Server) http.HandlerFunc
+}
+
+// HasRoute reports whether pattern is the pattern of a route, such as
+// "/history/"
+func HasRoute(pattern string) bool {
+	for _, rt := range routes {
+		if rt.Pattern == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// skips reports whether the route opts out of a middleware stage
+func (rt route) skips(stage string) bool {
+	for _, s := range rt.Skip {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// Health checks and admin routes bypass tenancy; admin routes authenticate
+// with requireAdmin instead
+var skipTenancy = []string{StageAuth, StageRateLimit}
+
+// param is a documented path or query parameter
+type param struct {
+	Name        string
+	In          string // "path" or "query"
+	Type        string // "string", "integer" or "boolean"
+	Required    bool
+	Description string
+	// check validates a non-empty value before the handler runs
+	check func(string) error
+}
+
+var (
+	currencyParam = param{Name: "currency", In: "query", Type: "string", Description: "Quote currency (default usd)", check: checkCurrency}
+	idsParam      = param{Name: "ids", In: "query", Type: "string", Required: true, Description: "Comma-separated token ids", check: checkIDs}
+	signedParam   = param{Name: "signed", In: "query", Type: "boolean", Description: "Attach an Ed25519 signature to each price"}
+	alertIDParam  = param{Name: "id", In: "path", Type: "string", Required: true, Description: "Alert id"}
+	maxAgeParam   = param{Name: "max_age", In: "query", Type: "string", Description: "Oldest price served, e.g. 30s or 30 (seconds); older prices are refetched, or served stale with a 503 if that fails", check: checkMaxAge}
+)
+
+// failedCurrenciesHeader documents the header of routes that price several
+// currencies and leave the ones that fail out of a CoinGecko-shaped body
+var failedCurrenciesHeader = map[string]string{
+	"X-Failed-Currencies": "Comma-separated currencies that could not be priced and are missing from the body",
+}
+
+// Response shapes for handlers that encode ad-hoc maps
+type (
+	healthResponse      = wire.HealthResponse
+	signingKey          = wire.SigningKey
+	signingKeysResponse struct {
+		Keys []signingKey `json:"keys"`
+	}
+	flushResponse struct {
+		Flushed int `json:"flushed"`
+	}
+	cacheStatsResponse struct {
+		cache.Stats
+		HitRatio   float64 `json:"hit_ratio"`
+		TTLSeconds int64   `json:"ttl_seconds"`
+	}
+	auditResponse struct {
+		Entries []audit.Entry `json:"entries"`
+	}
+	tenantsResponse struct {
+		Tenants []TenantUsageSnapshot `json:"tenants"`
+	}
+	reloadResponse struct {
+		Status string `json:"status"`
+	}
+	tenantKeyResponse struct {
+		Tenant string `json:"tenant"`
+		APIKey string `json:"api_key"`
+	}
+	quarantineResponse struct {
+		MaxChange float64             `json:"max_change"`
+		Prices    []cache.Quarantined `json:"prices"`
+	}
+	breakersResponse struct {
+		Providers []providers.BreakerStatus `json:"providers"`
+	}
+	routingResponse struct {
+		Providers []providers.RouteStatus `json:"providers"`
+	}
+	supplyChangesResponse struct {
+		Token   string          `json:"token"`
+		Changes []supply.Change `json:"changes"` // oldest first
+	}
+	flaggedSupplyResponse struct {
+		Since   time.Time       `json:"since"`
+		Changes []supply.Change `json:"changes"` // newest first
+	}
+	aliasesResponse struct {
+		Aliases map[string]string `json:"aliases"`
+	}
+	overridesResponse struct {
+		Overrides map[string]overrides.Override `json:"overrides"`
+	}
+	rateLimitsResponse struct {
+		Hosts    []providers.RateLimitStatus `json:"hosts"`
+		Schedule []providers.ScheduleStatus  `json:"schedule"` // hosts paced to a quota
+	}
+)
+
+// routes lists every versioned endpoint
+var routes = []route{
+	{
+		Method: http.MethodGet, Path: "/health", Pattern: "/health",
+		Summary: "Health check", Tag: "system", Skip: skipTenancy,
+		Response: healthResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleHealth },
+	},
+	{
+		Method: http.MethodGet, Path: "/slo", Pattern: "/slo",
+		Summary: "Availability, latency and error budget per route", Tag: "system", Skip: skipTenancy,
+		Params: []param{
+			{Name: "format", In: "query", Type: "string", Description: "json (default) or prometheus", check: checkMetricsFormat},
+		},
+		Response: sloResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleSLO },
+	},
+	{
+		Method: http.MethodGet, Path: "/status", Pattern: "/status",
+		Summary: "Degradation clients can show a delay banner for", Tag: "system", Skip: skipTenancy,
+		Response: StatusResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleStatus },
+	},
+	{
+		Method: http.MethodGet, Path: "/price/{token_id}", Pattern: "/price/",
+		Summary: "Price of a single token", Tag: "prices",
+		Params: []param{
+			{Name: "token_id", In: "path", Type: "string", Required: true, Description: "CoinGecko token id, e.g. bitcoin", check: checkID},
+			currencyParam, maxAgeParam, signedParam, fieldsParam, formattedParam, localeParam, roundedParam, callbackParam,
+		},
+		Response: cache.PriceResponse{},
+		JSONP:    true,
+		handler:  func(s *Server) http.HandlerFunc { return s.handlePrice },
+	},
+	{
+		Method: http.MethodGet, Path: "/prices", Pattern: "/prices",
+		Summary: "Prices of several tokens", Tag: "prices",
+		Params:   []param{idsParam, currencyParam, maxAgeParam, signedParam, fieldsParam, formattedParam, localeParam, roundedParam, callbackParam},
+		Response: cache.MultiPriceResponse{},
+		JSONP:    true,
+		handler:  func(s *Server) http.HandlerFunc { return s.handlePrices },
+	},
+	{
+		Method: http.MethodGet, Path: "/rounding", Pattern: "/rounding",
+		Summary: "Display precision of rounded prices by magnitude", Tag: "prices",
+		Response: roundingResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleRounding },
+	},
+	{
+		Method: http.MethodGet, Path: "/widget/{token_id}", Pattern: "/widget/",
+		Summary: "Embeddable HTML price widget of a token", Tag: "prices", Feature: "widget",
+		Params: []param{
+			{Name: "token_id", In: "path", Type: "string", Required: true, Description: "CoinGecko token id, e.g. lux-network", check: checkID},
+			currencyParam,
+			{Name: "locale", In: "query", Type: "string", Description: "Locale of the displayed price, e.g. de-DE (default en-US)"},
+			{Name: "theme", In: "query", Type: "string", Description: "light (default) or dark"},
+		},
+		handler: func(s *Server) http.HandlerFunc { return s.handleWidget },
+	},
+	{
+		Method: http.MethodGet, Path: "/simple/price", Pattern: "/simple/price",
+		Summary: "CoinGecko-compatible prices (token -> currency -> price)", Tag: "prices",
+		Params: []param{
+			idsParam,
+			{Name: "vs_currencies", In: "query", Type: "string", Description: "Comma-separated quote currencies (default usd)", check: checkCurrencies},
+			maxAgeParam, callbackParam,
+		},
+		Response: map[string]map[string]float64{},
+		JSONP:    true,
+		Headers:  failedCurrenciesHeader,
+		handler:  func(s *Server) http.HandlerFunc { return s.handleSimplePrice },
+	},
+	{
+		Method: http.MethodGet, Path: "/coins/markets", Pattern: "/coins/markets",
+		Summary: "CoinGecko-compatible market list, served from cache", Tag: "market",
+		Params: []param{
+			{Name: "vs_currency", In: "query", Type: "string", Required: true, Description: "Quote currency, e.g. usd", check: checkCurrency},
+			{Name: "ids", In: "query", Type: "string", Description: "Comma-separated token ids (default the largest tokens by market cap)", check: checkIDs},
+			{Name: "order", In: "query", Type: "string", Description: "market_cap_desc (default), market_cap_asc, volume_desc, volume_asc, id_asc or id_desc"},
+			{Name: "per_page", In: "query", Type: "integer", Description: "Tokens per page (default 100, max 250)"},
+			{Name: "page", In: "query", Type: "integer", Description: "Page number (default 1)"},
+			{Name: "price_change_percentage", In: "query", Type: "string", Description: "Comma-separated windows to add *_in_currency changes for; 24h and 7d are supported"},
+		},
+		Response: []coinMarket{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleCoinMarkets },
+	},
+	{
+		Method: http.MethodGet, Path: "/coins/{id}", Pattern: "/coins/",
+		Summary: "CoinGecko-compatible token market data, served from cache", Tag: "market",
+		Params: []param{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "CoinGecko token id, e.g. bitcoin", check: checkID},
+		},
+		Response: coinDetail{},
+		Headers:  failedCurrenciesHeader,
+		handler:  func(s *Server) http.HandlerFunc { return s.handleCoin },
+	},
+	{
+		Method: http.MethodPost, Path: "/chainlink", Pattern: "/chainlink",
+		Summary: "Chainlink external adapter price request", Tag: "prices", Feature: "chainlink",
+		Request: chainlinkRequest{}, Response: chainlinkResponse{},
+		handler: func(s *Server) http.HandlerFunc { return s.handleChainlink },
+	},
+	{
+		Method: http.MethodGet, Path: "/bridge/rates", Pattern: "/bridge/rates",
+		Summary: "Exchange rates for the Lux bridge, never older than a maximum age", Tag: "prices",
+		Params: []param{
+			{Name: "pairs", In: "query", Type: "string", Description: "Comma-separated base/quote pairs (default all configured pairs)"},
+			{Name: "max_age", In: "query", Type: "integer", Description: "Oldest price in seconds a rate may use (default and at most BRIDGE_MAX_AGE)"},
+		},
+		Response: bridge.Rates{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleBridgeRates },
+	},
+	{
+		Method: http.MethodGet, Path: "/onramp/quote", Pattern: "/onramp/quote",
+		Summary: "Compare fiat on-ramp offers for buying a token", Tag: "prices",
+		Params: []param{
+			{Name: "token", In: "query", Type: "string", Required: true, Description: "Token id or alias, e.g. lux", check: checkID},
+			{Name: "fiat", In: "query", Type: "string", Description: "Fiat currency paid (default usd)", check: checkCurrency},
+			{Name: "amount", In: "query", Type: "number", Required: true, Description: "Fiat amount paid, fees included"},
+		},
+		Response: onramp.Comparison{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleOnrampQuote },
+	},
+	{
+		Method: http.MethodGet, Path: "/quote/{token}", Pattern: "/quote/",
+		Summary: "Price quote with an expiry as EIP-712 typed data, optionally signed", Tag: "prices",
+		Params: []param{
+			{Name: "token", In: "path", Type: "string", Required: true, Description: "CoinGecko token id, e.g. bitcoin", check: checkID},
+			currencyParam,
+			{Name: "signed", In: "query", Type: "string", Description: "eip712 to sign the quote for on-chain verification"},
+		},
+		Response: quoteResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleQuote },
+	},
+	{
+		Method: http.MethodGet, Path: "/markets", Pattern: "/markets",
+		Summary: "Largest tokens by market cap", Tag: "market",
+		Params: []param{
+			{Name: "limit", In: "query", Type: "integer", Description: "Tokens to return (default 100, max 250)"},
+			{Name: "category", In: "query", Type: "string", Description: "Only tokens of a category, e.g. layer-1, among the largest 250"},
+			currencyParam, formatParam, fieldsParam, formattedParam, localeParam, roundedParam,
+		},
+		Response: markets.List{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleMarkets },
+	},
+	{
+		Method: http.MethodGet, Path: "/categories", Pattern: "/categories",
+		Summary: "Token categories, their hierarchy and sizes", Tag: "market",
+		Response: categories.List{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleCategories },
+	},
+	{
+		Method: http.MethodGet, Path: "/supply/{token}", Pattern: "/supply/",
+		Summary: "Reported supply of a token checked against its contract", Tag: "market",
+		Params: []param{
+			{Name: "token", In: "path", Type: "string", Required: true, Description: "CoinGecko token id of a verified token", check: checkID},
+		},
+		Response: supply.Check{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleSupply },
+	},
+	{
+		Method: http.MethodGet, Path: "/supply/{token}/changes", Pattern: "/supply/",
+		Summary: "Day-over-day changes of a token's circulating supply", Tag: "market",
+		Params: []param{
+			{Name: "token", In: "path", Type: "string", Required: true, Description: "CoinGecko token id, e.g. lux-network", check: checkID},
+			{Name: "days", In: "query", Type: "integer", Description: "Days of changes (default 30, max 365)", check: checkDays},
+		},
+		Response: supplyChangesResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleSupply },
+	},
+	{
+		Method: http.MethodGet, Path: "/supply/changes", Pattern: "/supply/changes",
+		Summary: "Circulating supply changes beyond the threshold, such as unlocks and mints", Tag: "market",
+		Params: []param{
+			{Name: "since", In: "query", Type: "string", Description: "RFC3339 time changes are returned from (default a week ago)"},
+			{Name: "format", In: "query", Type: "string", Description: "json (default) or prometheus for each token's latest change", check: checkMetricsFormat},
+		},
+		Response: flaggedSupplyResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleFlaggedSupplyChanges },
+	},
+	{
+		Method: http.MethodGet, Path: "/risk", Pattern: "/risk",
+		Summary: "Tokens flagged as risky: honeypots, contract risks, extreme volatility, low liquidity and depegs", Tag: "market",
+		Response: risk.Feed{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleRisk },
+	},
+	{
+		Method: http.MethodGet, Path: "/history/{id}", Pattern: "/history/",
+		Summary: "Price, market cap and volume history of a token", Tag: "market",
+		Params: []param{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "CoinGecko token id, e.g. bitcoin", check: checkID},
+			{Name: "days", In: "query", Type: "integer", Description: "Days of history (default 30, max 365); 5-minutely for 1 day, hourly up to 90, daily beyond", check: checkDays},
+			currencyParam, formatParam,
+		},
+		Response: history.Series{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleHistory },
+	},
+	{
+		Method: http.MethodGet, Path: "/udf/config", Pattern: "/udf/config",
+		Summary: "TradingView UDF datafeed configuration", Tag: "charts",
+		Response: udfConfig{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleUDFConfig },
+	},
+	{
+		Method: http.MethodGet, Path: "/udf/symbols", Pattern: "/udf/symbols",
+		Summary: "TradingView UDF symbol info", Tag: "charts",
+		Params: []param{
+			{Name: "symbol", In: "query", Type: "string", Required: true, Description: "Token id, optionally with a currency, e.g. lux-network/usd"},
+		},
+		Response: udfSymbol{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleUDFSymbol },
+	},
+	{
+		Method: http.MethodGet, Path: "/udf/history", Pattern: "/udf/history",
+		Summary: "TradingView UDF price bars from price history", Tag: "charts",
+		Params: []param{
+			{Name: "symbol", In: "query", Type: "string", Required: true, Description: "Token id, optionally with a currency, e.g. lux-network/usd"},
+			{Name: "resolution", In: "query", Type: "string", Required: true, Description: "5, 15, 60, 240, 1D or 1W"},
+			{Name: "from", In: "query", Type: "integer", Required: true, Description: "Start of the range in unix seconds"},
+			{Name: "to", In: "query", Type: "integer", Required: true, Description: "End of the range in unix seconds, exclusive"},
+			{Name: "countback", In: "query", Type: "integer", Description: "Bars to return before to, instead of those from from"},
+		},
+		Response: udfBars{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleUDFHistory },
+	},
+	{
+		Method: http.MethodGet, Path: "/udf/time", Pattern: "/udf/time",
+		Summary: "Server time in unix seconds, as plain text", Tag: "charts",
+		Response: int64(0),
+		handler:  func(s *Server) http.HandlerFunc { return s.handleUDFTime },
+	},
+	{
+		Method: http.MethodGet, Path: "/token/{token_id}/logo", Pattern: "/token/",
+		Summary: "Token logo image, proxied and cached", Tag: "market", Feature: "logos",
+		Params: []param{
+			{Name: "token_id", In: "path", Type: "string", Required: true, Description: "CoinGecko token id, e.g. bitcoin", check: checkID},
+			{Name: "size", In: "query", Type: "integer", Description: "Largest width and height in pixels, 16 to 512; original size if omitted", check: checkLogoSize},
+		},
+		handler: func(s *Server) http.HandlerFunc { return s.handleLogo },
+	},
+	{
+		Method: http.MethodGet, Path: "/twap/{token}", Pattern: "/twap/",
+		Summary: "Time- and volume-weighted average price of a token", Tag: "market",
+		Params: []param{
+			{Name: "token", In: "path", Type: "string", Required: true, Description: "CoinGecko token id, e.g. bitcoin", check: checkID},
+			{Name: "window", In: "query", Type: "string", Description: "Averaging window ending now (default 1h, at most TICKS_RETENTION)"},
+			currencyParam,
+		},
+		Response: ticks.Average{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleTWAP },
+	},
+	{
+		Method: http.MethodGet, Path: "/close/{token}", Pattern: "/close/",
+		Summary: "Daily close of a token in a time zone, from recorded prices", Tag: "market",
+		Params: []param{
+			{Name: "token", In: "path", Type: "string", Required: true, Description: "CoinGecko token id, e.g. bitcoin", check: checkID},
+			{Name: "tz", In: "query", Type: "string", Description: "IANA time zone the day is in (default UTC), e.g. America/New_York", check: checkTimeZone},
+			{Name: "date", In: "query", Type: "string", Description: "Day as YYYY-MM-DD (default the last full day in tz)", check: checkDate},
+			currencyParam,
+		},
+		Response: ticks.Close{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleClose },
+	},
+	{
+		Method: http.MethodGet, Path: "/changes", Pattern: "/changes",
+		Summary: "Prices changed since a cursor, for polling without refetching every token", Tag: "prices",
+		Params: []param{
+			{Name: "since", In: "query", Type: "string", Description: "Cursor from the previous response; every price is returned with reset set if omitted or expired", check: checkCursor},
+			currencyParam,
+			{Name: "limit", In: "query", Type: "integer", Description: "Changes to return (default 1000, max 5000); more is set if some are left"},
+		},
+		Response: changes.Changes{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleChanges },
+	},
+	{
+		Method: http.MethodGet, Path: "/stream", Pattern: "/stream",
+		Summary: "Price updates of tokens as server-sent events", Tag: "prices", Feature: "stream",
+		// A stream outlives any request budget; handleStream bounds its
+		// snapshot fetch itself
+		Skip:     []string{StageTimeout, StageHedge},
+		Params:   []param{idsParam, currencyParam},
+		Response: cache.PriceResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleStream },
+	},
+	{
+		Method: http.MethodPost, Path: "/portfolio/performance", Pattern: "/portfolio/performance",
+		Summary: "Value over time, PnL and returns of token holdings", Tag: "portfolio",
+		Request: portfolio.Request{}, Response: portfolio.Performance{},
+		handler: func(s *Server) http.HandlerFunc { return s.handlePortfolioPerformance },
+	},
+	{
+		Method: http.MethodPost, Path: "/portfolio/allocation", Pattern: "/portfolio/allocation",
+		Summary: "Allocation of token holdings by token, category, chain, stability and staking", Tag: "portfolio",
+		Request: portfolio.AllocationRequest{}, Response: portfolio.Allocation{},
+		handler: func(s *Server) http.HandlerFunc { return s.handlePortfolioAllocation },
+	},
+	{
+		Method: http.MethodPost, Path: "/tax/lots", Pattern: "/tax/lots",
+		Summary: "Price acquisitions at their time and now for tax reports", Tag: "portfolio",
+		Params:  []param{formatParam},
+		Request: portfolio.LotsRequest{}, Response: portfolio.Lots{},
+		handler: func(s *Server) http.HandlerFunc { return s.handleTaxLots },
+	},
+	{
+		Method: http.MethodGet, Path: "/analytics/correlation", Pattern: "/analytics/correlation",
+		Summary: "Pairwise correlation of tokens' daily returns", Tag: "portfolio", Feature: "analytics",
+		Params: []param{
+			idsParam,
+			{Name: "days", In: "query", Type: "integer", Description: "Days of history (default 90, min 6, max 365)", check: checkDays},
+			currencyParam,
+		},
+		Response: analytics.Correlation{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleCorrelation },
+	},
+	{
+		Method: http.MethodGet, Path: "/analytics/{token}", Pattern: "/analytics/",
+		Summary: "Volatility, drawdown and Sharpe ratio of a token", Tag: "portfolio", Feature: "analytics",
+		Params: []param{
+			{Name: "token", In: "path", Type: "string", Required: true, Description: "CoinGecko token id, e.g. bitcoin", check: checkID},
+			{Name: "metrics", In: "query", Type: "string", Description: "Comma-separated volatility_<n>d, max_drawdown_<n>d or sharpe_<n>d, n up to 365 (default volatility_30d,max_drawdown_90d,sharpe_180d)"},
+			currencyParam,
+		},
+		Response: analytics.Metrics{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleAnalytics },
+	},
+	{
+		// Shares /analytics/ with the route above, which dispatches to
+		// handleRelative
+		Method: http.MethodGet, Path: "/analytics/{token}/vs", Pattern: "/analytics/",
+		Summary: "Beta, alpha and tracking series of a token against a benchmark", Tag: "portfolio", Feature: "analytics",
+		Params: []param{
+			{Name: "token", In: "path", Type: "string", Required: true, Description: "CoinGecko token id, e.g. lux-network", check: checkID},
+			{Name: "benchmark", In: "query", Type: "string", Description: "Benchmark token id, or index:<name> for a configured index (default bitcoin)", check: checkBenchmark},
+			{Name: "days", In: "query", Type: "integer", Description: "Days of history (default 90, min 6, max 365)", check: checkDays},
+			currencyParam,
+		},
+		Response: analytics.Relative{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleAnalytics },
+	},
+	{
+		Method: http.MethodGet, Path: "/indicators/{token}", Pattern: "/indicators/",
+		Summary: "Moving averages and RSI of a token's daily closes", Tag: "market", Feature: "analytics",
+		Params: []param{
+			{Name: "token", In: "path", Type: "string", Required: true, Description: "CoinGecko token id, e.g. bitcoin", check: checkID},
+			{Name: "set", In: "query", Type: "string", Description: "Comma-separated sma_<n>, ema_<n> or rsi_<n>, n up to 200 (default sma_50,sma_200,rsi_14)"},
+			currencyParam,
+		},
+		Response: analytics.Indicators{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleIndicators },
+	},
+	{
+		Method: http.MethodGet, Path: "/returns/{token}", Pattern: "/returns/",
+		Summary: "Percent returns of a token over periods ending now", Tag: "market", Feature: "analytics",
+		Params: []param{
+			{Name: "token", In: "path", Type: "string", Required: true, Description: "CoinGecko token id, e.g. bitcoin", check: checkID},
+			{Name: "periods", In: "query", Type: "string", Description: "Comma-separated periods of days, weeks or years up to 1y, e.g. 1d,2w (default 1d,7d,30d,90d,1y)"},
+			currencyParam,
+		},
+		Response: analytics.Returns{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleReturns },
+	},
+	{
+		Method: http.MethodGet, Path: "/extremes/{token}", Pattern: "/extremes/",
+		Summary: "All-time and 52-week highs and lows of a token", Tag: "market",
+		Params: []param{
+			{Name: "token", In: "path", Type: "string", Required: true, Description: "CoinGecko token id, e.g. bitcoin", check: checkID},
+			currencyParam,
+		},
+		Response: extremes.Extremes{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleExtremes },
+	},
+	{
+		Method: http.MethodGet, Path: "/listings/changes", Pattern: "/listings/changes",
+		Summary: "Coins CoinGecko listed and delisted since a time", Tag: "market",
+		Params: []param{
+			{Name: "since", In: "query", Type: "string", Description: "RFC3339 time changes are returned after (default a day ago)"},
+		},
+		Response: listings.Feed{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleListingChanges },
+	},
+	{
+		Method: http.MethodGet, Path: "/reports/latest", Pattern: "/reports/latest",
+		Summary: "Latest daily or weekly market report", Tag: "market",
+		Params: []param{
+			{Name: "format", In: "query", Type: "string", Description: "json (default), markdown or html"},
+		},
+		Response: report.Report{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleReport },
+	},
+	{
+		Method: http.MethodGet, Path: "/global", Pattern: "/global",
+		Summary: "Total crypto market cap, volume and dominance", Tag: "market",
+		Params:   []param{currencyParam},
+		Response: global.Overview{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleGlobal },
+	},
+	{
+		Method: http.MethodGet, Path: "/trending", Pattern: "/trending",
+		Summary: "Trending tokens", Tag: "market",
+		Response: trending.List{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleTrending },
+	},
+	{
+		Method: http.MethodGet, Path: "/movers", Pattern: "/movers",
+		Summary: "Top gainers and losers among cached prices", Tag: "market",
+		Params: []param{
+			{Name: "window", In: "query", Type: "string", Description: "Change window; only 24h is supported"},
+			{Name: "limit", In: "query", Type: "integer", Description: "Gainers and losers to return (default 10, max 100)"},
+			currencyParam,
+		},
+		Response: trending.Movers{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleMovers },
+	},
+	{
+		Method: http.MethodGet, Path: "/fx", Pattern: "/fx",
+		Summary: "Fiat exchange rates", Tag: "fx",
+		Params: []param{
+			{Name: "base", In: "query", Type: "string", Description: "Base currency (default usd)", check: checkCurrency},
+			{Name: "symbols", In: "query", Type: "string", Description: "Comma-separated quote currencies; all if omitted", check: checkCurrencies},
+		},
+		Response: fxResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleFX },
+	},
+	{
+		Method: http.MethodGet, Path: "/index/{name}", Pattern: "/index/",
+		Summary: "Value of a weighted token index", Tag: "index",
+		Params: []param{
+			{Name: "name", In: "path", Type: "string", Required: true, Description: "Index name from the config file", check: checkID},
+			currencyParam,
+		},
+		Response: index.Value{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleIndex },
+	},
+	{
+		Method: http.MethodGet, Path: "/derivatives/{token}", Pattern: "/derivatives/",
+		Summary: "Perpetual funding rates and open interest", Tag: "derivatives",
+		Params: []param{
+			{Name: "token", In: "path", Type: "string", Required: true, Description: "Token id or ticker symbol, e.g. bitcoin or btc", check: checkID},
+		},
+		Response: derivatives.Summary{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleDerivatives },
+	},
+	{
+		Method: http.MethodGet, Path: "/nft/{collection}", Pattern: "/nft/",
+		Summary: "NFT collection floor price, volume and owners", Tag: "nft",
+		Params: []param{
+			{Name: "collection", In: "path", Type: "string", Required: true, Description: "CoinGecko collection id, e.g. pudgy-penguins", check: checkID},
+		},
+		Response: nft.Collection{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleNFT },
+	},
+	{
+		Method: http.MethodGet, Path: "/tvl/{protocol}", Pattern: "/tvl/",
+		Summary: "DeFi protocol total value locked", Tag: "tvl",
+		Params: []param{
+			{Name: "protocol", In: "path", Type: "string", Required: true, Description: "DefiLlama slug or CoinGecko token id, e.g. aave", check: checkID},
+		},
+		Response: tvl.Protocol{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleTVL },
+	},
+	{
+		Method: http.MethodGet, Path: "/token-price/{chain}/{contract}", Pattern: "/token-price/",
+		Summary: "Price of a token by contract address, from CoinGecko or DEX pools", Tag: "prices",
+		Params: []param{
+			{Name: "chain", In: "path", Type: "string", Required: true, Description: "Chain name, e.g. ethereum, bsc or solana"},
+			{Name: "contract", In: "path", Type: "string", Required: true, Description: "Token contract address"},
+			currencyParam,
+		},
+		Response: tokenprice.Price{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleTokenPrice },
+	},
+	{
+		Method: http.MethodGet, Path: "/gas/{chain}", Pattern: "/gas/",
+		Summary: "Gas fee estimates for a chain", Tag: "gas",
+		Params: []param{
+			{Name: "chain", In: "path", Type: "string", Required: true, Description: "Chain name, e.g. ethereum or lux", check: checkID},
+		},
+		Response: gas.Estimate{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleGas },
+	},
+	{
+		Method: http.MethodGet, Path: "/stablecoins", Pattern: "/stablecoins",
+		Summary: "Stablecoin deviation from peg", Tag: "stablecoins",
+		Params: []param{
+			{Name: "history", In: "query", Type: "boolean", Description: "Include deviation samples for the history window"},
+		},
+		Response: stablecoinsResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleStablecoins },
+	},
+	{
+		Method: http.MethodGet, Path: "/alerts", Pattern: "/alerts",
+		Summary: "Price alerts of the calling tenant", Tag: "alerts",
+		Response: alertsResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleListAlerts },
+	},
+	{
+		Method: http.MethodPost, Path: "/alerts", Pattern: "/alerts",
+		Summary: "Register a price alert", Tag: "alerts",
+		Request: alerts.Spec{}, Response: alerts.Alert{}, Status: http.StatusCreated,
+		handler: func(s *Server) http.HandlerFunc { return s.handleCreateAlert },
+	},
+	{
+		Method: http.MethodPost, Path: "/alerts/test", Pattern: "/alerts/test",
+		Summary: "Backtest an alert condition against price history", Tag: "alerts",
+		Request: alertTestRequest{}, Response: alerts.Backtest{},
+		handler: func(s *Server) http.HandlerFunc { return s.handleTestAlert },
+	},
+	{
+		Method: http.MethodGet, Path: "/alerts/{id}", Pattern: "/alerts/",
+		Summary: "A price alert", Tag: "alerts",
+		Params:   []param{alertIDParam},
+		Response: alerts.Alert{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleGetAlert },
+	},
+	{
+		Method: http.MethodPut, Path: "/alerts/{id}", Pattern: "/alerts/",
+		Summary: "Replace a price alert's condition and channel", Tag: "alerts",
+		Params:  []param{alertIDParam},
+		Request: alerts.Spec{}, Response: alerts.Alert{},
+		handler: func(s *Server) http.HandlerFunc { return s.handleUpdateAlert },
+	},
+	{
+		Method: http.MethodDelete, Path: "/alerts/{id}", Pattern: "/alerts/",
+		Summary: "Delete a price alert", Tag: "alerts",
+		Params: []param{alertIDParam}, Status: http.StatusNoContent,
+		handler: func(s *Server) http.HandlerFunc { return s.handleDeleteAlert },
+	},
+	{
+		Method: http.MethodGet, Path: "/webhooks/{id}/deliveries", Pattern: "/webhooks/",
+		Summary: "Recent deliveries of a price alert and their attempts", Tag: "alerts",
+		Params:   []param{alertIDParam},
+		Response: deliveriesResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleWebhookDeliveries },
+	},
+	{
+		Method: http.MethodGet, Path: "/signing/keys", Pattern: "/signing/keys",
+		Summary: "Public keys for signed responses", Tag: "signing",
+		Response: signingKeysResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleSigningKeys },
+	},
+	{
+		Method: http.MethodGet, Path: "/usage", Pattern: "/usage",
+		Summary: "Usage for the calling tenant", Tag: "tenants",
+		Response: TenantUsageSnapshot{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleTenantUsage },
+	},
+	{
+		Method: http.MethodPost, Path: "/admin/cache/flush", Pattern: "/admin/cache/flush",
+		Summary: "Flush cached prices", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Params: []param{
+			{Name: "token", In: "query", Type: "string", Description: "Token id to flush; all tokens if omitted", check: checkID},
+		},
+		Response: flushResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleCacheFlush },
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/cache/stats", Pattern: "/admin/cache/stats",
+		Summary: "Cache hits, misses and size", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Response: cacheStatsResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleCacheStats },
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/audit", Pattern: "/admin/audit",
+		Summary: "Query the audit log, newest first", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Params: []param{
+			{Name: "actor", In: "query", Type: "string"},
+			{Name: "action", In: "query", Type: "string"},
+			{Name: "since", In: "query", Type: "string", Description: "RFC 3339 timestamp"},
+			{Name: "limit", In: "query", Type: "integer", Description: "Maximum entries (default 100)"},
+		},
+		Response: auditResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleAudit },
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/tenants", Pattern: "/admin/tenants",
+		Summary: "Usage for all tenants", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Response: tenantsResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleAdminTenants },
+	},
+	{
+		Method: http.MethodPost, Path: "/admin/tenants/keys", Pattern: "/admin/tenants/keys",
+		Summary: "Create an API key for a tenant", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Params: []param{
+			{Name: "tenant", In: "query", Type: "string", Required: true},
+		},
+		Response: tenantKeyResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleAdminTenantKey },
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/quarantine", Pattern: "/admin/quarantine",
+		Summary: "Price updates held back by the sanity bound", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Response: quarantineResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleQuarantine },
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/aliases", Pattern: "/admin/aliases",
+		Summary: "Token id aliases", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Response: aliasesResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleAliases },
+	},
+	{
+		Method: http.MethodPost, Path: "/admin/aliases", Pattern: "/admin/aliases",
+		Summary: "Add or replace a token id alias", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Params: []param{
+			{Name: "alias", In: "query", Type: "string", Required: true, Description: "Alias, e.g. matic", check: checkID},
+			{Name: "id", In: "query", Type: "string", Required: true, Description: "Token id it stands for, e.g. polygon-ecosystem-token", check: checkID},
+		},
+		Response: aliasesResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleSetAlias },
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/overrides", Pattern: "/admin/overrides",
+		Summary: "Token metadata overrides", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Response: overridesResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleOverrides },
+	},
+	{
+		Method: http.MethodPut, Path: "/admin/overrides/{token_id}", Pattern: "/admin/overrides/",
+		Summary: "Replace a token's metadata override", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Params: []param{
+			{Name: "token_id", In: "path", Type: "string", Required: true, Description: "CoinGecko token id, e.g. lux-network", check: checkID},
+		},
+		Request: overrides.Override{}, Response: overrides.Override{},
+		handler: func(s *Server) http.HandlerFunc { return s.handleSetOverride },
+	},
+	{
+		Method: http.MethodDelete, Path: "/admin/overrides/{token_id}", Pattern: "/admin/overrides/",
+		Summary: "Remove a token's metadata override", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Params: []param{
+			{Name: "token_id", In: "path", Type: "string", Required: true, Description: "CoinGecko token id, e.g. lux-network", check: checkID},
+		},
+		Status:  http.StatusNoContent,
+		handler: func(s *Server) http.HandlerFunc { return s.handleDeleteOverride },
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/dead-letters", Pattern: "/admin/dead-letters",
+		Summary: "Deliveries that failed every attempt, newest first", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Response: deadLettersResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleDeadLetters },
+	},
+	{
+		Method: http.MethodPost, Path: "/admin/dead-letters/{id}/replay", Pattern: "/admin/dead-letters/",
+		Summary: "Deliver a dead letter again", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Params: []param{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Dead letter id"},
+		},
+		Response: alerts.Delivery{}, Status: http.StatusAccepted,
+		handler: func(s *Server) http.HandlerFunc { return s.handleReplayDeadLetter },
+	},
+	{
+		Method: http.MethodDelete, Path: "/admin/dead-letters/{id}", Pattern: "/admin/dead-letters/",
+		Summary: "Discard a dead letter", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Params: []param{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Dead letter id"},
+		},
+		Status:  http.StatusNoContent,
+		handler: func(s *Server) http.HandlerFunc { return s.handleDeleteDeadLetter },
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/bundle", Pattern: "/admin/bundle",
+		Summary: "Export aliases, index definitions and alerts as one bundle", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Response: configBundle{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleExportBundle },
+	},
+	{
+		Method: http.MethodPost, Path: "/admin/bundle", Pattern: "/admin/bundle",
+		Summary: "Import a bundle exported from another deployment", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Params: []param{
+			{Name: "dry_run", In: "query", Type: "boolean", Description: "Validate the bundle and count changes without applying them"},
+		},
+		Request: configBundle{}, Response: bundleImportResponse{},
+		handler: func(s *Server) http.HandlerFunc { return s.handleImportBundle },
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/treasury", Pattern: "/admin/treasury",
+		Summary: "Latest treasury valuation and its change since the previous one", Tag: "admin", Feature: "treasury", Admin: true, Skip: skipTenancy,
+		Params: []param{
+			{Name: "live", In: "query", Type: "boolean", Description: "Value the holdings now instead, without recording the valuation"},
+			formatParam,
+		},
+		Response: treasury.Valuation{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleTreasury },
+	},
+	{
+		Method: http.MethodPost, Path: "/admin/treasury", Pattern: "/admin/treasury",
+		Summary: "Value the treasury now and record the valuation", Tag: "admin", Feature: "treasury", Admin: true, Skip: skipTenancy,
+		Response: treasury.Valuation{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleRecordTreasury },
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/treasury/history", Pattern: "/admin/treasury/history",
+		Summary: "Recorded treasury valuations, newest first", Tag: "admin", Feature: "treasury", Admin: true, Skip: skipTenancy,
+		Params: []param{
+			{Name: "limit", In: "query", Type: "integer", Description: "Most valuations returned (default all)"},
+		},
+		Response: treasuryHistoryResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleTreasuryHistory },
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/breakers", Pattern: "/admin/breakers",
+		Summary: "Circuit breaker state per price provider", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Response: breakersResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleBreakers },
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/routing", Pattern: "/admin/routing",
+		Summary: "Latency, health and routing decisions per balanced provider", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Response: routingResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleRouting },
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/rate-limits", Pattern: "/admin/rate-limits",
+		Summary: "Upstream hosts that have rate limited requests, and those paced to a quota", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Response: rateLimitsResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleRateLimits },
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/upstream", Pattern: "/admin/upstream",
+		Summary: "Upstream requests in flight and queued", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Response: providers.LimitStatus{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleUpstream },
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/schema", Pattern: "/admin/schema",
+		Summary: "Upstream response fields missing, mistyped or unexpected", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Response: providers.SchemaStatus{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleSchema },
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/stream", Pattern: "/admin/stream",
+		Summary: "Stream subscribers, tokens followed and evictions", Tag: "admin", Feature: "stream", Admin: true, Skip: skipTenancy,
+		Response: stream.Stats{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleStreamStats },
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/deviation", Pattern: "/admin/deviation",
+		Summary: "Price deviation between providers", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Response: deviationResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleDeviation },
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/oracle", Pattern: "/admin/oracle",
+		Summary: "On-chain oracle feeds and pending updates", Tag: "admin", Feature: "oracle", Admin: true, Skip: skipTenancy,
+		Response: oracle.Status{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleOracle },
+	},
+	{
+		Method: http.MethodPost, Path: "/admin/reload", Pattern: "/admin/reload",
+		Summary: "Reload configuration without restarting", Tag: "admin", Admin: true, Skip: skipTenancy,
+		Response: reloadResponse{},
+		handler:  func(s *Server) http.HandlerFunc { return s.handleReload },
+	},
+}
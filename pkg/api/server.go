@@ -0,0 +1,743 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package api serves the pricing HTTP API.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/luxfi/pricing/pkg/alerts"
+	"github.com/luxfi/pricing/pkg/aliases"
+	"github.com/luxfi/pricing/pkg/analytics"
+	"github.com/luxfi/pricing/pkg/audit"
+	"github.com/luxfi/pricing/pkg/bridge"
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/categories"
+	"github.com/luxfi/pricing/pkg/changes"
+	"github.com/luxfi/pricing/pkg/derivatives"
+	"github.com/luxfi/pricing/pkg/deviation"
+	"github.com/luxfi/pricing/pkg/extremes"
+	"github.com/luxfi/pricing/pkg/format"
+	"github.com/luxfi/pricing/pkg/fx"
+	"github.com/luxfi/pricing/pkg/gas"
+	"github.com/luxfi/pricing/pkg/global"
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/index"
+	"github.com/luxfi/pricing/pkg/listings"
+	"github.com/luxfi/pricing/pkg/logos"
+	"github.com/luxfi/pricing/pkg/markets"
+	"github.com/luxfi/pricing/pkg/nft"
+	"github.com/luxfi/pricing/pkg/onramp"
+	"github.com/luxfi/pricing/pkg/oracle"
+	"github.com/luxfi/pricing/pkg/overrides"
+	"github.com/luxfi/pricing/pkg/portfolio"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/quorum"
+	"github.com/luxfi/pricing/pkg/report"
+	"github.com/luxfi/pricing/pkg/risk"
+	"github.com/luxfi/pricing/pkg/signing"
+	"github.com/luxfi/pricing/pkg/slo"
+	"github.com/luxfi/pricing/pkg/stablecoins"
+	"github.com/luxfi/pricing/pkg/stream"
+	"github.com/luxfi/pricing/pkg/supply"
+	"github.com/luxfi/pricing/pkg/ticks"
+	"github.com/luxfi/pricing/pkg/tokenprice"
+	"github.com/luxfi/pricing/pkg/treasury"
+	"github.com/luxfi/pricing/pkg/trending"
+	"github.com/luxfi/pricing/pkg/tvl"
+)
+
+// Options configures a Server. Cache is required; other fields are optional.
+type Options struct {
+	Cache         *cache.PriceCache
+	AuditLog      *audit.Log
+	AdminKeys     map[string]string // admin API key -> actor name
+	Signer        *signing.Signer
+	Quotes        *signing.Quoter               // serves /quote/{token} if set
+	Quorum        *quorum.Checker               // prices quotes when several providers must agree
+	FX            *fx.Converter                 // serves /fx if set
+	Stablecoins   *stablecoins.Monitor          // serves /stablecoins if set
+	Deviation     *deviation.Monitor            // serves /admin/deviation if set
+	Gas           *gas.Oracle                   // serves /gas/{chain} if set
+	TokenPrices   *tokenprice.Service           // serves /token-price/{chain}/{contract} if set
+	Derivatives   *derivatives.Aggregator       // serves /derivatives/{token} if set
+	NFTs          *nft.Service                  // serves /nft/{collection} if set
+	TVL           *tvl.Service                  // serves /tvl/{protocol} if set
+	Logos         *logos.Service                // serves /token/{id}/logo if set
+	Indices       *index.Service                // serves /index/{name} if set
+	Global        *global.Service               // serves /global if set
+	Trending      *trending.Service             // serves /trending if set
+	Markets       *markets.Service              // serves /markets if set
+	Categories    *categories.Service           // tags /markets assets and serves /categories if set
+	Supply        *supply.Verifier              // flags /markets supply discrepancies and serves /supply/{token} if set
+	SupplyChanges *supply.ChangeTracker         // serves /supply/{token}/changes and /supply/changes if set
+	Risk          *risk.Checker                 // adds risk flags to prices and markets and serves /risk if set
+	History       *history.Service              // serves /history/{id} if set
+	Portfolio     *portfolio.Service            // serves /portfolio/performance if set
+	Analytics     *analytics.Service            // serves /analytics/* if set
+	Extremes      *extremes.Tracker             // serves /extremes/{token} if set
+	Listings      *listings.Tracker             // serves /listings/changes if set
+	Ticks         *ticks.Store                  // serves /twap/{token} if set
+	Changes       *changes.Journal              // serves /changes if set
+	Stream        *stream.Hub                   // serves /stream if set
+	Reports       *report.Generator             // serves /reports/latest if set
+	Treasury      *treasury.Service             // serves /admin/treasury if set
+	Alerts        *alerts.Store                 // serves /alerts if set
+	Channels      *alerts.Channels              // serves /webhooks/{id}/deliveries and /admin/dead-letters if set
+	Breakers      []*providers.Breaker          // listed by /admin/breakers
+	Balancer      *providers.Balancer           // serves /admin/routing if set
+	Aliases       *aliases.Table                // serves /admin/aliases if set
+	Overrides     *overrides.Table              // serves /admin/overrides and patches responses if set
+	RateLimits    *providers.RateLimitTransport // serves /admin/rate-limits if set
+	InFlight      *providers.LimitTransport     // serves /admin/upstream if set
+	Scheduler     *providers.SchedulerTransport // adds host quotas to /admin/rate-limits if set
+	Schemas       *providers.SchemaMonitor      // serves /admin/schema if set
+	Oracle        *oracle.Pusher                // serves /admin/oracle if set
+	Bridge        *bridge.Service               // serves /bridge/rates if set
+	Onramps       *onramp.Aggregator            // serves /onramp/quote if set
+	Tenants       *TenantRegistry
+	CachePolicies map[string]CachePolicy
+	CORSOrigins   []string        // allowed browser origins; "*" or empty allows any, and enables JSONP
+	LegacySunset  time.Time       // Sunset date for unversioned routes; none if zero
+	AccessLog     *log.Logger     // one line per request if set
+	Observer      RequestObserver // called after every routed request if set
+	Rounding      format.Policy   // display precision of ?rounded=true; format.DefaultPolicy if nil
+	SLO           *slo.Tracker    // counts every routed request and serves /slo if set
+
+	// Shed lists the feature groups and route patterns answered 503 while
+	// another route burns its error budget at the tracker's shed rate
+	Shed []string
+
+	// Features reports whether an endpoint group (see FeatureGroups) is
+	// served; all are if nil. Disabled groups are absent from the mux and
+	// the OpenAPI spec.
+	Features func(group string) bool
+
+	// RequestTimeout is the upstream time budget of a request, unless
+	// RouteTimeouts has one for its route pattern; none if zero
+	RequestTimeout time.Duration
+	RouteTimeouts  map[string]time.Duration
+
+	// HedgeRoutes are the route patterns whose provider calls are hedged
+	// across the balanced providers
+	HedgeRoutes []string
+	Reload      func() error // reloads configuration for POST /admin/reload
+}
+
+// Server holds the HTTP server and price cache
+type Server struct {
+	cache      *cache.PriceCache
+	auditLog   *audit.Log
+	signer     *signing.Signer
+	quotes     *signing.Quoter
+	quorum     *quorum.Checker
+	fx         *fx.Converter
+	pegs       *stablecoins.Monitor
+	devs       *deviation.Monitor
+	gas        *gas.Oracle
+	tokens     *tokenprice.Service
+	derivs     *derivatives.Aggregator
+	nfts       *nft.Service
+	tvl        *tvl.Service
+	logos      *logos.Service
+	indices    *index.Service
+	global     *global.Service
+	trending   *trending.Service
+	markets    *markets.Service
+	categories *categories.Service
+	supply     *supply.Verifier
+	issuance   *supply.ChangeTracker
+	risk       *risk.Checker
+	history    *history.Service
+	portfolio  *portfolio.Service
+	analytics  *analytics.Service
+	extremes   *extremes.Tracker
+	listings   *listings.Tracker
+	ticks      *ticks.Store
+	changes    *changes.Journal
+	stream     *stream.Hub
+	reports    *report.Generator
+	treasury   *treasury.Service
+	alerts     *alerts.Store
+	channels   *alerts.Channels
+	breakers   []*providers.Breaker
+	balancer   *providers.Balancer
+	aliases    *aliases.Table
+	overrides  *overrides.Table
+	limits     *providers.RateLimitTransport
+	inFlight   *providers.LimitTransport
+	schedule   *providers.SchedulerTransport
+	schemas    *providers.SchemaMonitor
+	oracle     *oracle.Pusher
+	bridge     *bridge.Service
+	onramps    *onramp.Aggregator
+	rounding   format.Policy
+	tenants    *TenantRegistry
+	encoded    *encodedCache
+	observer   RequestObserver
+	slo        *slo.Tracker
+	shed       map[string]bool // low-priority groups and patterns
+	shedRoutes map[string]bool // patterns of the routes shed
+	features   func(string) bool
+	spec       []byte // OpenAPI spec of the served routes; OpenAPISpec if nil
+	timeout    time.Duration
+	timeouts   map[string]time.Duration
+	hedged     map[string]bool
+	reload     func() error
+
+	settings atomic.Pointer[settings]
+}
+
+// settings are the options that Reconfigure can change at runtime
+type settings struct {
+	adminKeys     map[string]string
+	cachePolicies map[string]CachePolicy
+	corsOrigins   map[string]bool // nil allows any origin
+	legacySunset  time.Time
+	accessLog     *log.Logger
+}
+
+// NewServer creates a new server. Without an audit log, tenants or cache
+// policies it uses an in-memory audit log, a single unrestricted tenant and
+// the default cache policies; admin routes are disabled without admin keys.
+func NewServer(opts Options) *Server {
+	s := &Server{
+		cache:      opts.Cache,
+		auditLog:   opts.AuditLog,
+		signer:     opts.Signer,
+		quotes:     opts.Quotes,
+		quorum:     opts.Quorum,
+		fx:         opts.FX,
+		pegs:       opts.Stablecoins,
+		devs:       opts.Deviation,
+		gas:        opts.Gas,
+		tokens:     opts.TokenPrices,
+		derivs:     opts.Derivatives,
+		nfts:       opts.NFTs,
+		tvl:        opts.TVL,
+		logos:      opts.Logos,
+		indices:    opts.Indices,
+		global:     opts.Global,
+		trending:   opts.Trending,
+		markets:    opts.Markets,
+		categories: opts.Categories,
+		supply:     opts.Supply,
+		issuance:   opts.SupplyChanges,
+		risk:       opts.Risk,
+		history:    opts.History,
+		portfolio:  opts.Portfolio,
+		analytics:  opts.Analytics,
+		extremes:   opts.Extremes,
+		listings:   opts.Listings,
+		ticks:      opts.Ticks,
+		changes:    opts.Changes,
+		stream:     opts.Stream,
+		reports:    opts.Reports,
+		treasury:   opts.Treasury,
+		alerts:     opts.Alerts,
+		channels:   opts.Channels,
+		breakers:   opts.Breakers,
+		balancer:   opts.Balancer,
+		aliases:    opts.Aliases,
+		overrides:  opts.Overrides,
+		limits:     opts.RateLimits,
+		inFlight:   opts.InFlight,
+		schedule:   opts.Scheduler,
+		schemas:    opts.Schemas,
+		oracle:     opts.Oracle,
+		bridge:     opts.Bridge,
+		onramps:    opts.Onramps,
+		rounding:   opts.Rounding,
+		tenants:    opts.Tenants,
+		encoded:    newEncodedCache(),
+		observer:   opts.Observer,
+		slo:        opts.SLO,
+		shed:       make(map[string]bool, len(opts.Shed)),
+		features:   opts.Features,
+		timeout:    opts.RequestTimeout,
+		timeouts:   opts.RouteTimeouts,
+		hedged:     make(map[string]bool, len(opts.HedgeRoutes)),
+		reload:     opts.Reload,
+	}
+	for _, route := range opts.HedgeRoutes {
+		s.hedged[route] = true
+	}
+	for _, name := range opts.Shed {
+		s.shed[name] = true
+	}
+	s.shedRoutes = make(map[string]bool)
+	for _, rt := range routes {
+		if s.sheds(rt) {
+			s.shedRoutes[rt.Pattern] = true
+		}
+	}
+	if s.auditLog == nil {
+		s.auditLog, _ = audit.NewLog("")
+	}
+	if s.tenants == nil {
+		s.tenants, _ = NewTenantRegistry("")
+	}
+	if s.rounding == nil {
+		s.rounding = format.DefaultPolicy
+	}
+	if s.features != nil {
+		s.spec, _ = json.MarshalIndent(buildSpec(s.serves), "", "  ")
+	}
+	s.Reconfigure(opts)
+	return s
+}
+
+// Reconfigure applies AdminKeys, CachePolicies, CORSOrigins, LegacySunset
+// and AccessLog from opts to a running server. Other fields are ignored.
+func (s *Server) Reconfigure(opts Options) {
+	st := &settings{
+		adminKeys:     opts.AdminKeys,
+		cachePolicies: opts.CachePolicies,
+		legacySunset:  opts.LegacySunset,
+		accessLog:     opts.AccessLog,
+	}
+	if st.cachePolicies == nil {
+		st.cachePolicies = DefaultCachePolicies()
+	}
+	for _, origin := range opts.CORSOrigins {
+		if origin == "*" {
+			st.corsOrigins = nil
+			break
+		}
+		if st.corsOrigins == nil {
+			st.corsOrigins = make(map[string]bool)
+		}
+		st.corsOrigins[strings.TrimRight(origin, "/")] = true
+	}
+	s.settings.Store(st)
+}
+
+// current returns the active runtime settings
+func (s *Server) current() *settings {
+	return s.settings.Load()
+}
+
+// handleHealth returns health status
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(healthResponse{
+		Status: "ok",
+		Time:   time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// handlePrice returns price for a single token
+func (s *Server) handlePrice(w http.ResponseWriter, r *http.Request) {
+	// Parse token ID from path: /price/{token_id}
+	path := strings.TrimPrefix(r.URL.Path, "/price/")
+	tokenID := strings.TrimSuffix(path, "/")
+
+	if tokenID == "" {
+		http.Error(w, `{"error":"token_id required"}`, http.StatusBadRequest)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, tokenID) {
+		return
+	}
+
+	currency := queryCurrency(r.URL.Query())
+	sel, ok := parseFields(w, r, cache.PriceResponse{})
+	if !ok {
+		return
+	}
+	signed := wantsSignature(r)
+	if signed && sel != nil {
+		http.Error(w, `{"error":"fields cannot be selected from signed responses"}`, http.StatusBadRequest)
+		return
+	}
+	rounded := wantsRounded(r)
+	if signed && rounded {
+		http.Error(w, `{"error":"signed prices cannot be rounded"}`, http.StatusBadRequest)
+		return
+	}
+	loc, ok := parseFormat(w, r)
+	if !ok {
+		return
+	}
+	requested, r := s.requestMaxAge(r)
+
+	price, err := s.cache.GetPrice(r.Context(), tokenID, currency)
+	if errors.Is(err, providers.ErrTokenNotFound) {
+		s.writeNotFound(w, tokenID, err)
+		return
+	}
+	if err != nil {
+		writeUpstreamError(w, r, "price unavailable", err)
+		return
+	}
+	stale := s.maxAgeFor(price.ID, requested)
+	if !tooOld(price, stale) {
+		stale = 0
+	}
+
+	if signed {
+		if s.signer == nil {
+			http.Error(w, `{"error":"response signing not configured"}`, http.StatusNotImplemented)
+			return
+		}
+		s.signPrice(price)
+	}
+	if rounded {
+		roundPrices(s.rounding, price)
+	}
+	if loc != nil {
+		formatPrices(loc, price)
+	}
+	s.addOverride(price)
+	s.addRiskFlags(price)
+	if stale > 0 {
+		writeStalePrice(w, price, stale)
+		return
+	}
+
+	// Reuse the serialized body for repeated hits on the same cached price,
+	// metadata and risk flags
+	encodedKey := tokenID + ":" + currency + "|" + priceMetadata(price)
+	if len(price.RiskFlags) > 0 {
+		encodedKey += fmt.Sprint(price.RiskFlags)
+	}
+	variant := responseVariant(sel, loc, rounded)
+	reusable := price.Cached && !signed && variant == ""
+	var encoded *encodedResponse
+	if reusable {
+		encoded = s.encoded.get(encodedKey, price.UpdatedAt)
+	}
+	if variant != "" {
+		encoded, err = encodeVariant(price, sel, variant)
+		if err != nil {
+			http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+			return
+		}
+	} else if encoded == nil {
+		encoded, err = encodePrice(price)
+		if err != nil {
+			http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+			return
+		}
+		if reusable {
+			s.encoded.put(encodedKey, encoded)
+		}
+	}
+
+	s.setCacheControl(w, r, EndpointPrice, price.UpdatedAt)
+	if checkNotModified(w, r, encoded.etag, price.UpdatedAt) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(encoded.body)
+}
+
+// handlePrices returns prices for multiple tokens
+func (s *Server) handlePrices(w http.ResponseWriter, r *http.Request) {
+	// Get token IDs from query param
+	ids := r.URL.Query().Get("ids")
+	if ids == "" {
+		http.Error(w, `{"error":"ids query parameter required"}`, http.StatusBadRequest)
+		return
+	}
+
+	tokenIDs := strings.Split(ids, ",")
+	if !s.checkTokensAllowed(w, r, tokenIDs...) {
+		return
+	}
+
+	currency := queryCurrency(r.URL.Query())
+	sel, ok := parseFields(w, r, cache.PriceResponse{})
+	if !ok {
+		return
+	}
+	if sel != nil && wantsSignature(r) {
+		http.Error(w, `{"error":"fields cannot be selected from signed responses"}`, http.StatusBadRequest)
+		return
+	}
+	rounded := wantsRounded(r)
+	if rounded && wantsSignature(r) {
+		http.Error(w, `{"error":"signed prices cannot be rounded"}`, http.StatusBadRequest)
+		return
+	}
+	loc, ok := parseFormat(w, r)
+	if !ok {
+		return
+	}
+	requested, r := s.requestMaxAge(r)
+
+	// Tokens without a fresh price are explained in the errors section; a
+	// failure with nothing to serve is a 502
+	prices, err := s.cache.GetMultiplePrices(r.Context(), tokenIDs, currency)
+	if err != nil && len(prices.Prices) == 0 {
+		writeUpstreamError(w, r, "prices unavailable", err)
+		return
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Printf("Error fetching prices: %v", err)
+	}
+
+	if wantsSignature(r) {
+		if s.signer == nil {
+			http.Error(w, `{"error":"response signing not configured"}`, http.StatusNotImplemented)
+			return
+		}
+		for _, p := range prices.Prices {
+			s.signPrice(p)
+		}
+	}
+	for _, p := range prices.Prices {
+		if rounded {
+			roundPrices(s.rounding, p)
+		}
+		if loc != nil {
+			formatPrices(loc, p)
+		}
+		s.addOverride(p)
+		s.addRiskFlags(p)
+	}
+
+	// Prices that couldn't be refreshed within their max age make the
+	// whole response a 503, served with what is cached
+	if stale := s.markTooOld(prices, requested); len(stale) > 0 {
+		writeStaleHeader(w)
+	} else {
+		s.setCacheControl(w, r, EndpointPrices, oldestUpdate(prices.Prices))
+		etag, lastModified := multiPriceETag(prices)
+		if variant := responseVariant(sel, loc, rounded); variant != "" {
+			etag = variantETag(etag, variant)
+		}
+		if checkNotModified(w, r, etag, lastModified) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+	}
+	if sel != nil {
+		selected, err := sel.prices(prices)
+		if err != nil {
+			http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(selected)
+		return
+	}
+	json.NewEncoder(w).Encode(prices)
+}
+
+// writeUpstreamError answers a request whose upstream fetch failed with
+// nothing cached: 503 if the upstream request queue is full, 502
+// otherwise. Requests abandoned by the client are not logged.
+func writeUpstreamError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	if !errors.Is(err, context.Canceled) {
+		log.Printf("Error serving %s: %v", r.URL.Path, err)
+	}
+	if errors.Is(err, providers.ErrOverloaded) {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, fmt.Sprintf(`{"error":"%s: upstream busy"}`, message), http.StatusServiceUnavailable)
+		return
+	}
+	http.Error(w, fmt.Sprintf(`{"error":"%s"}`, message), http.StatusBadGateway)
+}
+
+// currencyFetches bounds how many currencies one request fetches at once
+const currencyFetches = 4
+
+// handleSimplePrice returns simple price map (CoinGecko compatible)
+func (s *Server) handleSimplePrice(w http.ResponseWriter, r *http.Request) {
+	ids := r.URL.Query().Get("ids")
+	if ids == "" {
+		http.Error(w, `{"error":"ids query parameter required"}`, http.StatusBadRequest)
+		return
+	}
+
+	tokenIDs := strings.Split(ids, ",")
+	currencies := splitCurrencies(r.URL.Query().Get("vs_currencies"))
+	if !s.checkTokensAllowed(w, r, tokenIDs...) {
+		return
+	}
+	requested, r := s.requestMaxAge(r)
+
+	// Fetch the currencies concurrently, currencyFetches at a time. Each
+	// currency keeps its own error so one failure doesn't drop the rest.
+	results := make([]*cache.MultiPriceResponse, len(currencies))
+	errs := make([]error, len(currencies))
+	var g errgroup.Group
+	g.SetLimit(currencyFetches)
+	for i, currency := range currencies {
+		i, currency := i, currency
+		g.Go(func() error {
+			results[i], errs[i] = s.cache.GetMultiplePrices(r.Context(), tokenIDs, currency)
+			return nil
+		})
+	}
+	g.Wait()
+
+	result := make(map[string]map[string]float64)
+	var lastModified, oldest time.Time
+	var failed []string
+	staleIDs := make(map[string]bool)
+
+	for i, currency := range currencies {
+		if errs[i] != nil {
+			if !errors.Is(errs[i], context.Canceled) {
+				log.Printf("Error fetching prices: %v", errs[i])
+			}
+			failed = append(failed, currency)
+		}
+		for _, id := range s.markTooOld(results[i], requested) {
+			staleIDs[id] = true
+		}
+		for id, p := range results[i].Prices {
+			if result[id] == nil {
+				result[id] = make(map[string]float64)
+			}
+			result[id][currency] = p.Price
+			if p.UpdatedAt.After(lastModified) {
+				lastModified = p.UpdatedAt
+			}
+			if oldest.IsZero() || p.UpdatedAt.Before(oldest) {
+				oldest = p.UpdatedAt
+			}
+		}
+	}
+
+	// Partial failures keep the CoinGecko-compatible body and are reported
+	// in a header; a complete failure with nothing cached is a 502
+	if len(failed) > 0 {
+		if len(result) == 0 {
+			writeUpstreamError(w, r, "prices unavailable", errors.Join(errs...))
+			return
+		}
+		w.Header().Set("X-Failed-Currencies", strings.Join(failed, ","))
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	// Prices that couldn't be refreshed within their max age are listed
+	// in a header of a 503, keeping the body
+	if len(staleIDs) > 0 {
+		stale := make([]string, 0, len(staleIDs))
+		for id := range staleIDs {
+			stale = append(stale, id)
+		}
+		sort.Strings(stale)
+		w.Header().Set("X-Stale-Tokens", strings.Join(stale, ","))
+		writeStaleHeader(w)
+		w.Write(append(body, '\n'))
+		return
+	}
+
+	s.setCacheControl(w, r, EndpointSimplePrice, oldest)
+	etag := newETagBuilder()
+	etag.addBytes(body)
+	if checkNotModified(w, r, etag.String(), lastModified) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(body, '\n'))
+}
+
+// corsMiddleware adds CORS headers. With an origin allowlist the request
+// origin is echoed back only if it is listed.
+func (s *Server) corsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		corsOrigins := s.current().corsOrigins
+		if corsOrigins == nil {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Add("Vary", "Origin")
+			if origin := r.Header.Get("Origin"); corsOrigins[origin] {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+		}
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, If-None-Match, If-Modified-Since")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, Last-Modified, X-Failed-Currencies, X-Stale-Tokens, Deprecation, Sunset, Link")
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// methodMux serves a pattern shared by routes with different methods.
+// A single route is returned as is and checks the method itself.
+func methodMux(handlers map[string]http.Handler) http.Handler {
+	if len(handlers) == 1 {
+		for _, h := range handlers {
+			return h
+		}
+	}
+	allow := make([]string, 0, len(handlers))
+	for method := range handlers {
+		allow = append(allow, method)
+	}
+	sort.Strings(allow)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := r.Method
+		if method == http.MethodHead {
+			method = http.MethodGet
+		}
+		h, ok := handlers[method]
+		if !ok {
+			w.Header().Set("Allow", strings.Join(allow, ", "))
+			http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Handler builds the HTTP handler with all endpoints and middleware.
+// Routes are served under /v1; unversioned paths still work but are
+// deprecated.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	byMethod := make(map[string]map[string]http.Handler)
+	var patterns []string
+	for _, rt := range routes {
+		if !s.serves(rt) {
+			continue
+		}
+		h := rt.handler(s)
+		if rt.JSONP {
+			h = s.jsonpMiddleware(h)
+		}
+		if rt.Admin {
+			h = s.requireAdmin(h)
+		}
+		if byMethod[rt.Pattern] == nil {
+			byMethod[rt.Pattern] = make(map[string]http.Handler)
+			patterns = append(patterns, rt.Pattern)
+		}
+		byMethod[rt.Pattern][rt.Method] = s.routeMiddleware(rt)(h)
+	}
+	for _, pattern := range patterns {
+		mux.Handle(pattern, methodMux(byMethod[pattern]))
+	}
+
+	// Routes carry their own logging, metrics, auth and rate limit stages;
+	// CORS, compression and versioning apply to every request
+	return Chain(s.corsMiddleware, compressMiddleware, s.versionRouter)(mux)
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/decimal"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// wantsSignature reports whether the client asked for a signed response
+func wantsSignature(r *http.Request) bool {
+	v := r.URL.Query().Get("signed")
+	return v == "true" || v == "1"
+}
+
+// signPrice signs the exact price p carries in price_str, so the payload
+// matches the response
+func (s *Server) signPrice(p *cache.PriceResponse) {
+	if p.PriceStr == "" {
+		p.PriceStr = decimal.FromFloat(p.Price).String()
+	}
+	p.Signature = s.signer.SignPrice(p.ID, p.Currency, p.PriceStr, p.UpdatedAt)
+}
+
+// handleSigningKeys publishes the public key used for signed responses and
+// the address EIP-712 quotes are signed by
+func (s *Server) handleSigningKeys(w http.ResponseWriter, r *http.Request) {
+	var keys []signingKey
+	if s.signer != nil {
+		keys = append(keys, signingKey{
+			KeyID:     s.signer.KeyID(),
+			Algorithm: "ed25519",
+			PublicKey: s.signer.PublicKey(),
+		})
+	}
+	if s.quotes != nil && s.quotes.Signer() != "" {
+		domain := wire.EIP712Domain(s.quotes.Domain())
+		keys = append(keys, signingKey{
+			KeyID:     "eip712",
+			Algorithm: "secp256k1",
+			Address:   s.quotes.Signer(),
+			Domain:    &domain,
+		})
+	}
+	if len(keys) == 0 {
+		http.Error(w, `{"error":"response signing not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	json.NewEncoder(w).Encode(signingKeysResponse{Keys: keys})
+}
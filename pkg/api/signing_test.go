@@ -0,0 +1,90 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api_test
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/luxfi/pricing/internal/testutil"
+	"github.com/luxfi/pricing/pkg/api"
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/signing"
+)
+
+// verifyPrice checks that a signed price verifies against the published
+// key and attests to exactly what the response says
+func verifyPrice(t *testing.T, keys map[string]string, p *cache.PriceResponse) {
+	t.Helper()
+	if p.Signature == nil {
+		t.Fatalf("%s: no signature", p.ID)
+	}
+	pub, _ := hex.DecodeString(keys[p.Signature.KeyID])
+	sig, _ := hex.DecodeString(p.Signature.Signature)
+	if len(pub) != ed25519.PublicKeySize || !ed25519.Verify(pub, []byte(p.Signature.Payload), sig) {
+		t.Fatalf("%s: signature by %s does not verify", p.ID, p.Signature.KeyID)
+	}
+
+	var signed struct {
+		Token     string `json:"token"`
+		Currency  string `json:"currency"`
+		Price     string `json:"price"`
+		Timestamp int64  `json:"timestamp"`
+	}
+	if err := json.Unmarshal([]byte(p.Signature.Payload), &signed); err != nil {
+		t.Fatalf("%s: payload: %v", p.ID, err)
+	}
+	if signed.Token != p.ID || signed.Currency != p.Currency || signed.Price != p.PriceStr || signed.Timestamp != p.UpdatedAt.Unix() {
+		t.Errorf("signed %+v, but the response says %s %s %s at %d", signed, p.ID, p.Currency, p.PriceStr, p.UpdatedAt.Unix())
+	}
+}
+
+func TestSignedPrices(t *testing.T) {
+	signer, err := signing.NewSigner("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := testutil.NewServer(t, func(o *api.Options) { o.Signer = signer })
+	// More digits than a float64 holds: the signature covers all of them
+	srv.Provider.SetPrice(providers.Price{ID: "bitcoin", CurrentPrice: 97234.12345678901, CurrentPriceText: "97234.123456789012345678"})
+	srv.Provider.Set("ethereum", 3200)
+
+	var published struct {
+		Keys []struct {
+			KeyID     string `json:"key_id"`
+			PublicKey string `json:"public_key"`
+		} `json:"keys"`
+	}
+	if code := srv.GetJSON(t, "/v1/signing/keys", &published); code != http.StatusOK {
+		t.Fatalf("GET /v1/signing/keys: %d", code)
+	}
+	keys := make(map[string]string)
+	for _, k := range published.Keys {
+		keys[k.KeyID] = k.PublicKey
+	}
+
+	var price cache.PriceResponse
+	if code := srv.GetJSON(t, "/v1/price/bitcoin?signed=true", &price); code != http.StatusOK {
+		t.Fatalf("GET /v1/price/bitcoin: %d", code)
+	}
+	if price.PriceStr != "97234.123456789012345678" {
+		t.Errorf("price_str = %s, want the exact price", price.PriceStr)
+	}
+	verifyPrice(t, keys, &price)
+
+	var prices cache.MultiPriceResponse
+	if code := srv.GetJSON(t, "/v1/prices?ids=bitcoin,ethereum&signed=true", &prices); code != http.StatusOK {
+		t.Fatalf("GET /v1/prices: %d", code)
+	}
+	if len(prices.Prices) != 2 {
+		t.Fatalf("prices = %+v, want bitcoin and ethereum", prices.Prices)
+	}
+	for _, p := range prices.Prices {
+		verifyPrice(t, keys, p)
+	}
+}
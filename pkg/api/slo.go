@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/luxfi/pricing/pkg/slo"
+)
+
+// sloResponse is every route's service level and whether low-priority
+// routes are being shed
+type sloResponse struct {
+	slo.Report
+	Shedding bool `json:"shedding"`
+}
+
+// checkMetricsFormat accepts the formats /slo and /supply/changes are
+// served in
+func checkMetricsFormat(v string) error {
+	if v != "json" && v != "prometheus" {
+		return fmt.Errorf("unsupported format: %s", v)
+	}
+	return nil
+}
+
+// handleSLO reports availability, latency and error budgets per route:
+// GET /slo?format=prometheus
+func (s *Server) handleSLO(w http.ResponseWriter, r *http.Request) {
+	if s.slo == nil {
+		http.Error(w, `{"error":"SLO tracking not configured"}`, http.StatusNotFound)
+		return
+	}
+	rep := s.slo.Report()
+	w.Header().Set("Cache-Control", "no-store")
+	if r.URL.Query().Get("format") == "prometheus" {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		slo.WritePrometheus(w, rep, s.shedding())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sloResponse{Report: rep, Shedding: s.shedding()})
+}
+
+// sheds reports whether a route is low priority, shed while other routes
+// burn their error budget
+func (s *Server) sheds(rt route) bool {
+	return !rt.Admin && (s.shed[rt.feature()] || s.shed[rt.Pattern])
+}
+
+// shedding reports whether a route other than the low-priority ones is
+// burning its error budget fast enough to shed them
+func (s *Server) shedding() bool {
+	if s.slo == nil || len(s.shed) == 0 {
+		return false
+	}
+	return s.slo.Burning(func(pattern string) bool { return s.shedRoutes[pattern] })
+}
+
+// shedMiddleware answers 503 on a low-priority route while shedding, so
+// capacity goes to the routes whose objectives are at risk
+func (s *Server) shedMiddleware(rt route) Middleware {
+	return func(next http.Handler) http.Handler {
+		if s.slo == nil || !s.sheds(rt) {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if s.shedding() {
+				w.Header().Set("Retry-After", "30")
+				http.Error(w, `{"error":"temporarily unavailable while higher priority endpoints recover"}`, http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
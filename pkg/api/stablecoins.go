@@ -0,0 +1,33 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// stablecoinsResponse lists the peg status of monitored stablecoins
+type stablecoinsResponse = wire.StablecoinPegs
+
+// handleStablecoins returns each stablecoin's deviation from its peg, with
+// deviation history if ?history=true
+func (s *Server) handleStablecoins(w http.ResponseWriter, r *http.Request) {
+	if s.pegs == nil {
+		http.Error(w, `{"error":"stablecoin monitoring not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	v := r.URL.Query().Get("history")
+	withHistory := v == "true" || v == "1"
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(stablecoinsResponse{
+		Threshold:   s.pegs.Threshold(),
+		Stablecoins: s.pegs.Snapshot(withHistory),
+	})
+}
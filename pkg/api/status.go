@@ -0,0 +1,109 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// Service states reported by /status
+const (
+	StatusOK       = "ok"       // nothing degraded
+	StatusDegraded = "degraded" // prices may be delayed or features shed
+	StatusDown     = "down"     // no provider can refresh prices
+)
+
+// Degradation codes reported by /status
+const (
+	DegradedProviderDown = "provider_down" // a provider's circuit breaker is open
+	DegradedStaleData    = "stale_data"    // refreshes have failed longer than the stale bound
+	DegradedRateLimited  = "rate_limited"  // a provider host paused requests after a 429
+	DegradedShedding     = "shedding"      // low-priority endpoints answer 503
+)
+
+// Degradation is one reason the service is degraded
+type Degradation = wire.Degradation
+
+// StatusResponse summarizes degradation for clients to show a banner
+// from: Banner is empty while the service is ok
+type StatusResponse = wire.StatusResponse
+
+// status collects what is degraded now
+func (s *Server) status() StatusResponse {
+	now := time.Now().UTC()
+	resp := StatusResponse{Status: StatusOK, Degradation: []Degradation{}, Upstream: s.cache.Upstream(), Time: now}
+
+	open := 0
+	for _, b := range s.breakers {
+		st := b.Status()
+		if st.State == providers.BreakerClosed {
+			continue
+		}
+		open++
+		resp.Degradation = append(resp.Degradation, Degradation{
+			Code:      DegradedProviderDown,
+			Component: st.Provider,
+			Message:   fmt.Sprintf("%s is failing; its circuit breaker is %s", st.Provider, st.State),
+			Since:     st.OpenedAt,
+		})
+	}
+	if u := resp.Upstream; u.Stale {
+		msg := "price refreshes have failed since " + u.FailingSince.Format(time.RFC3339)
+		if u.LastRefresh != nil {
+			msg = "price refreshes are failing; prices are from " + u.LastRefresh.Format(time.RFC3339) + " or earlier"
+		}
+		resp.Degradation = append(resp.Degradation, Degradation{
+			Code:    DegradedStaleData,
+			Message: msg,
+			Since:   u.FailingSince,
+		})
+	}
+	if s.limits != nil {
+		for _, h := range s.limits.Status() {
+			if h.PausedUntil == nil {
+				continue
+			}
+			resp.Degradation = append(resp.Degradation, Degradation{
+				Code:      DegradedRateLimited,
+				Component: h.Host,
+				Message:   h.Host + " is rate limiting requests; they are paused",
+				Until:     h.PausedUntil,
+			})
+		}
+	}
+	delayed := len(resp.Degradation) > 0
+	if s.shedding() {
+		resp.Degradation = append(resp.Degradation, Degradation{
+			Code:    DegradedShedding,
+			Message: "low-priority endpoints are unavailable while others recover",
+		})
+	}
+
+	switch {
+	case len(s.breakers) > 0 && open == len(s.breakers):
+		resp.Status, resp.Delayed = StatusDown, true
+		resp.Banner = "Prices are not updating right now."
+	case delayed:
+		resp.Status, resp.Delayed = StatusDegraded, true
+		resp.Banner = "Prices may be delayed."
+	case len(resp.Degradation) > 0:
+		resp.Status = StatusDegraded
+		resp.Banner = "Some features are temporarily unavailable."
+	}
+	return resp
+}
+
+// handleStatus reports what is degraded, if anything, so clients can show
+// a "prices may be delayed" banner: GET /status
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(s.status())
+}
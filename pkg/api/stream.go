@@ -0,0 +1,132 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/stream"
+)
+
+// streamHeartbeat is how often an idle stream sends a comment, so proxies
+// keep the connection open
+const streamHeartbeat = 15 * time.Second
+
+// handleStream streams price updates of tokens as server-sent events:
+// GET /stream?ids=bitcoin,ethereum&currency=usd
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	if s.stream == nil {
+		http.Error(w, `{"error":"streaming not enabled"}`, http.StatusNotFound)
+		return
+	}
+
+	q := r.URL.Query()
+	ids := strings.Split(q.Get("ids"), ",")
+	if len(ids) > stream.MaxTokens {
+		http.Error(w, fmt.Sprintf(`{"error":"at most %d ids per stream"}`, stream.MaxTokens), http.StatusBadRequest)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, ids...) {
+		return
+	}
+	currency := queryCurrency(q)
+
+	sub, err := s.stream.Subscribe(ids, currency)
+	if errors.Is(err, stream.ErrFull) {
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, `{"error":"too many stream clients"}`, http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+		return
+	}
+	defer sub.Close()
+
+	// Writes that stall longer than the hub's lag limit end the stream
+	rc := http.NewResponseController(w)
+	maxLag := s.stream.MaxLag()
+	send := func(event string, v interface{}) bool {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return false
+		}
+		rc.SetWriteDeadline(time.Now().Add(maxLag))
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+	sendPrice := func(p cache.PriceResponse) bool {
+		s.addOverride(&p)
+		s.addRiskFlags(&p)
+		return send("price", p)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Current prices first, then every refresh; the snapshot's own fetch
+	// may also arrive as an update. The route skips the timeout stage, so
+	// only the snapshot is held to the request budget.
+	ctx := r.Context()
+	if timeout := s.requestTimeout("/stream"); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	snapshot, err := s.cache.GetMultiplePrices(ctx, ids, currency)
+	if err == nil {
+		for _, id := range ids {
+			if p, ok := snapshot.Prices[id]; ok && !sendPrice(*p) {
+				return
+			}
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-sub.Done():
+			if err := sub.Err(); err != nil {
+				send("error", map[string]string{"error": err.Error()})
+			}
+			return
+		case <-heartbeat.C:
+			rc.SetWriteDeadline(time.Now().Add(maxLag))
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		case <-sub.Ready():
+			for _, p := range sub.Next() {
+				if !sendPrice(p) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// handleStreamStats reports the stream subscribers: GET /admin/stream
+func (s *Server) handleStreamStats(w http.ResponseWriter, r *http.Request) {
+	if s.stream == nil {
+		http.Error(w, `{"error":"streaming not enabled"}`, http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(s.stream.Stats())
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/internal/testutil"
+	"github.com/luxfi/pricing/pkg/api"
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/stream"
+)
+
+// TestStreamOutlivesRequestTimeout checks that a subscription keeps
+// receiving updates after the request budget has passed
+func TestStreamOutlivesRequestTimeout(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	hub := stream.NewHub(stream.Options{})
+	srv := testutil.NewServer(t, func(o *api.Options) {
+		o.Stream = hub
+		o.RequestTimeout = timeout
+	})
+	srv.Provider.Set("bitcoin", 65000)
+
+	resp, err := http.Get(srv.URL + "/v1/stream?ids=bitcoin&currency=usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /v1/stream: %d", resp.StatusCode)
+	}
+
+	events := make(chan string)
+	go func() {
+		defer close(events)
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			if data, ok := strings.CutPrefix(sc.Text(), "data: "); ok {
+				events <- data
+			}
+		}
+	}()
+	next := func() string {
+		t.Helper()
+		select {
+		case data, ok := <-events:
+			if !ok {
+				t.Fatal("stream ended")
+			}
+			return data
+		case <-time.After(5 * time.Second):
+			t.Fatal("no event")
+		}
+		return ""
+	}
+
+	if data := next(); !strings.Contains(data, `"price":65000`) {
+		t.Fatalf("snapshot %s, want bitcoin at 65000", data)
+	}
+	time.Sleep(3 * timeout)
+	hub.Publish("usd", []*cache.PriceResponse{{ID: "bitcoin", Price: 66000, UpdatedAt: time.Now()}})
+	if data := next(); !strings.Contains(data, `"price":66000`) {
+		t.Errorf("update %s, want bitcoin at 66000", data)
+	}
+}
+
+// TestStreamUnsubscribesOnDisconnect checks that a client going away
+// releases its subscription
+func TestStreamUnsubscribesOnDisconnect(t *testing.T) {
+	hub := stream.NewHub(stream.Options{})
+	srv := testutil.NewServer(t, func(o *api.Options) { o.Stream = hub })
+	srv.Provider.Set("bitcoin", 65000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/v1/stream?ids=bitcoin", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got := hub.Stats().Clients; got != 1 {
+		t.Fatalf("%d clients while streaming, want 1", got)
+	}
+
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for hub.Stats().Clients != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("subscription still open after the client disconnected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
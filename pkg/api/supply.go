@@ -0,0 +1,122 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/history"
+)
+
+// handleSupply returns a token's supply as reported by CoinGecko and as
+// read from its contract, flagging a discrepancy between the two
+func (s *Server) handleSupply(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/supply/"), "/")
+	if changed, ok := strings.CutSuffix(token, "/changes"); ok {
+		s.handleSupplyChanges(w, r, changed)
+		return
+	}
+	if s.supply == nil {
+		http.Error(w, `{"error":"supply verification not configured"}`, http.StatusNotFound)
+		return
+	}
+	if token == "" || strings.Contains(token, "/") {
+		http.Error(w, `{"error":"token id required"}`, http.StatusBadRequest)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, token) {
+		return
+	}
+	c, ok := s.supply.Check(token)
+	if !ok {
+		http.Error(w, `{"error":"supply of token not verified"}`, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(c)
+}
+
+// handleSupplyChanges returns a token's day-over-day changes of
+// circulating supply: GET /supply/{token}/changes?days=30
+func (s *Server) handleSupplyChanges(w http.ResponseWriter, r *http.Request, token string) {
+	if s.issuance == nil {
+		http.Error(w, `{"error":"supply change tracking not configured"}`, http.StatusNotFound)
+		return
+	}
+	if token == "" || strings.Contains(token, "/") {
+		http.Error(w, `{"error":"token id required"}`, http.StatusBadRequest)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, token) {
+		return
+	}
+	days := 30
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > history.MaxDays {
+			http.Error(w, fmt.Sprintf(`{"error":"days must be between 1 and %d"}`, history.MaxDays), http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	changes, ok := s.issuance.Changes(token, days)
+	if !ok {
+		http.Error(w, `{"error":"supply of token not tracked"}`, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(supplyChangesResponse{Token: token, Changes: changes})
+}
+
+// handleFlaggedSupplyChanges returns the supply changes beyond the
+// threshold of every tracked token since a time, the last week by
+// default, or their latest changes as Prometheus metrics:
+// GET /supply/changes?format=prometheus
+func (s *Server) handleFlaggedSupplyChanges(w http.ResponseWriter, r *http.Request) {
+	if s.issuance == nil {
+		http.Error(w, `{"error":"supply change tracking not configured"}`, http.StatusNotFound)
+		return
+	}
+	q := r.URL.Query()
+	if q.Get("format") == "prometheus" {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		s.issuance.WritePrometheus(w)
+		return
+	}
+
+	since := time.Now().AddDate(0, 0, -7)
+	if v := q.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, `{"error":"since must be RFC3339"}`, http.StatusBadRequest)
+			return
+		}
+		since = t
+	}
+
+	changes := s.issuance.Flagged(since)
+	if tenant := tenantFrom(r.Context()); tenant != nil {
+		allowed := changes[:0]
+		for _, c := range changes {
+			if tenant.Allows(c.Token) {
+				allowed = append(allowed, c)
+			}
+		}
+		changes = allowed
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	json.NewEncoder(w).Encode(flaggedSupplyResponse{Since: since.UTC(), Changes: changes})
+}
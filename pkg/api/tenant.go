@@ -0,0 +1,388 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/config"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// defaultTenantName is used for requests without an API key
+const defaultTenantName = "default"
+
+// RateLimit configures a token bucket
+type RateLimit struct {
+	RequestsPerMinute int `json:"requests_per_minute,omitempty"`
+	Burst             int `json:"burst,omitempty"`
+}
+
+// Tenant holds the policy for one API consumer
+type Tenant struct {
+	Name          string          `json:"name"`
+	APIKeys       []string        `json:"api_keys,omitempty"`
+	AllowedTokens []string        `json:"allowed_tokens,omitempty"`
+	CacheTTL      config.Duration `json:"cache_ttl,omitempty"`
+	RateLimit     RateLimit       `json:"rate_limit,omitempty"`
+
+	allowed map[string]bool
+	limiter atomic.Pointer[rateLimiter]
+	usage   *TenantUsage
+}
+
+// Allows reports whether the tenant may query a token
+func (t *Tenant) Allows(tokenID string) bool {
+	return len(t.allowed) == 0 || t.allowed[strings.ToLower(tokenID)]
+}
+
+// TenantUsage counts requests for a single tenant
+type TenantUsage struct {
+	requests    atomic.Int64
+	rateLimited atomic.Int64
+	forbidden   atomic.Int64
+
+	mu         sync.Mutex
+	byEndpoint map[string]int64
+}
+
+// TenantUsageSnapshot is the JSON view of TenantUsage
+type TenantUsageSnapshot = wire.TenantUsageSnapshot
+
+func (u *TenantUsage) record(endpoint string) {
+	u.requests.Add(1)
+	u.mu.Lock()
+	u.byEndpoint[endpoint]++
+	u.mu.Unlock()
+}
+
+func (u *TenantUsage) snapshot(name string) TenantUsageSnapshot {
+	u.mu.Lock()
+	byEndpoint := make(map[string]int64, len(u.byEndpoint))
+	for k, v := range u.byEndpoint {
+		byEndpoint[k] = v
+	}
+	u.mu.Unlock()
+
+	return TenantUsageSnapshot{
+		Tenant:      name,
+		Requests:    u.requests.Load(),
+		RateLimited: u.rateLimited.Load(),
+		Forbidden:   u.forbidden.Load(),
+		ByEndpoint:  byEndpoint,
+	}
+}
+
+// rateLimiter is a token bucket refilled continuously
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rl RateLimit) *rateLimiter {
+	if rl.RequestsPerMinute <= 0 {
+		return nil
+	}
+	burst := rl.Burst
+	if burst <= 0 {
+		burst = rl.RequestsPerMinute
+	}
+	return &rateLimiter{
+		rate:   float64(rl.RequestsPerMinute) / 60,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// allow takes a token, returning the wait until one is available if empty
+func (rl *rateLimiter) allow() (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	rl.tokens = math.Min(rl.burst, rl.tokens+now.Sub(rl.last).Seconds()*rl.rate)
+	rl.last = now
+
+	if rl.tokens >= 1 {
+		rl.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - rl.tokens) / rl.rate * float64(time.Second))
+	return false, wait
+}
+
+// TenantRegistry resolves API keys to tenants
+type TenantRegistry struct {
+	mu      sync.RWMutex
+	path    string
+	tenants map[string]*Tenant
+	byKey   map[string]*Tenant
+
+	defaultLimitFromFile bool // the tenants file sets the default tenant's limit
+}
+
+// NewTenantRegistry loads tenants from a JSON file. An empty path yields a
+// registry holding only an unrestricted default tenant.
+func NewTenantRegistry(path string) (*TenantRegistry, error) {
+	var tenants []*Tenant
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &tenants); err != nil {
+			return nil, fmt.Errorf("tenants file %s: %w", path, err)
+		}
+	}
+
+	tr := &TenantRegistry{
+		path:    path,
+		tenants: make(map[string]*Tenant),
+		byKey:   make(map[string]*Tenant),
+	}
+	for _, t := range tenants {
+		if t.Name == "" {
+			return nil, fmt.Errorf("tenants file %s: tenant without name", path)
+		}
+		if _, dup := tr.tenants[t.Name]; dup {
+			return nil, fmt.Errorf("tenants file %s: duplicate tenant %q", path, t.Name)
+		}
+		tr.add(t)
+		for _, key := range t.APIKeys {
+			if other, dup := tr.byKey[key]; dup && other != t {
+				return nil, fmt.Errorf("tenants file %s: API key shared by %q and %q", path, other.Name, t.Name)
+			}
+			tr.byKey[key] = t
+		}
+	}
+	if t, ok := tr.tenants[defaultTenantName]; ok {
+		tr.defaultLimitFromFile = t.RateLimit.RequestsPerMinute > 0
+	} else {
+		tr.add(&Tenant{Name: defaultTenantName})
+	}
+	return tr, nil
+}
+
+func (tr *TenantRegistry) add(t *Tenant) {
+	t.allowed = make(map[string]bool, len(t.AllowedTokens))
+	for _, id := range t.AllowedTokens {
+		t.allowed[strings.ToLower(id)] = true
+	}
+	t.limiter.Store(newRateLimiter(t.RateLimit))
+	t.usage = &TenantUsage{byEndpoint: make(map[string]int64)}
+	tr.tenants[t.Name] = t
+}
+
+// SetDefaultRateLimit limits requests made without an API key unless the
+// tenants file already configures a limit for the default tenant. It may
+// be called again to change the limit at runtime.
+func (tr *TenantRegistry) SetDefaultRateLimit(rl RateLimit) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	if tr.defaultLimitFromFile {
+		return
+	}
+	t := tr.tenants[defaultTenantName]
+	t.RateLimit = rl
+	t.limiter.Store(newRateLimiter(rl))
+}
+
+// Resolve returns the tenant for an API key, or the default tenant when
+// no key is given. Unknown keys resolve to nil.
+func (tr *TenantRegistry) Resolve(apiKey string) *Tenant {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	if apiKey == "" {
+		return tr.tenants[defaultTenantName]
+	}
+	return tr.byKey[apiKey]
+}
+
+// CreateKey generates a new API key for a tenant and persists the
+// registry. record is called with the key before it is stored; if it
+// fails, no key is created.
+func (tr *TenantRegistry) CreateKey(name string, record func(key string) error) (string, error) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	t, ok := tr.tenants[name]
+	if !ok {
+		return "", fmt.Errorf("unknown tenant: %s", name)
+	}
+	if name == defaultTenantName {
+		return "", fmt.Errorf("the default tenant does not use API keys")
+	}
+
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	key := "lux_" + hex.EncodeToString(b)
+	if err := record(key); err != nil {
+		return "", err
+	}
+
+	t.APIKeys = append(t.APIKeys, key)
+	tr.byKey[key] = t
+	if err := tr.save(); err != nil {
+		t.APIKeys = t.APIKeys[:len(t.APIKeys)-1]
+		delete(tr.byKey, key)
+		return "", err
+	}
+	return key, nil
+}
+
+// save writes the registry back to its file; callers hold tr.mu
+func (tr *TenantRegistry) save() error {
+	if tr.path == "" {
+		return nil
+	}
+
+	tenants := make([]*Tenant, 0, len(tr.tenants))
+	for _, t := range tr.tenants {
+		tenants = append(tenants, t)
+	}
+	data, err := json.MarshalIndent(tenants, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := tr.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, tr.path)
+}
+
+// Usage returns usage snapshots for all tenants
+func (tr *TenantRegistry) Usage() []TenantUsageSnapshot {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	result := make([]TenantUsageSnapshot, 0, len(tr.tenants))
+	for name, t := range tr.tenants {
+		result = append(result, t.usage.snapshot(name))
+	}
+	return result
+}
+
+type tenantKey struct{}
+
+// tenantFrom returns the tenant attached to a request context
+func tenantFrom(ctx context.Context) *Tenant {
+	t, _ := ctx.Value(tenantKey{}).(*Tenant)
+	return t
+}
+
+// requestAPIKey extracts the tenant API key from a request
+func requestAPIKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("api_key")
+}
+
+// checkTokensAllowed writes a 403 and returns false if the request tenant
+// may not query any of the given tokens. An alias is allowed if the token
+// it stands for is.
+func (s *Server) checkTokensAllowed(w http.ResponseWriter, r *http.Request, tokenIDs ...string) bool {
+	tenant := tenantFrom(r.Context())
+	if tenant == nil {
+		return true
+	}
+
+	var denied []string
+	for _, id := range tokenIDs {
+		if tenant.Allows(id) {
+			continue
+		}
+		if s.aliases == nil || !tenant.Allows(s.aliases.Resolve(id)) {
+			denied = append(denied, id)
+		}
+	}
+	if len(denied) == 0 {
+		return true
+	}
+
+	tenant.usage.forbidden.Add(1)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":  "tokens not allowed for tenant",
+		"tokens": denied,
+	})
+	return false
+}
+
+// handleTenantUsage returns usage for the calling tenant
+func (s *Server) handleTenantUsage(w http.ResponseWriter, r *http.Request) {
+	tenant := tenantFrom(r.Context())
+	if tenant == nil {
+		http.Error(w, `{"error":"tenant not resolved"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(tenant.usage.snapshot(tenant.Name))
+}
+
+// handleAdminTenants lists usage for all tenants
+func (s *Server) handleAdminTenants(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(tenantsResponse{Tenants: s.tenants.Usage()})
+}
+
+// handleAdminTenantKey creates an API key: POST /admin/tenants/keys?tenant=wallet
+func (s *Server) handleAdminTenantKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := r.URL.Query().Get("tenant")
+	if name == "" {
+		http.Error(w, `{"error":"tenant query parameter required"}`, http.StatusBadRequest)
+		return
+	}
+
+	// The audit entry is written before the key is stored, so a key that
+	// can be used always has a record. The key itself is not logged; its
+	// prefix identifies it in the audit trail.
+	var auditErr error
+	key, err := s.tenants.CreateKey(name, func(key string) error {
+		auditErr = s.audit(r, "tenant.key.create", map[string]string{"tenant": name, "key_prefix": key[:12]})
+		return auditErr
+	})
+	if auditErr != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, auditErr.Error()), http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tenantKeyResponse{Tenant: name, APIKey: key})
+}
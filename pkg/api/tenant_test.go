@@ -0,0 +1,171 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/luxfi/pricing/internal/testutil"
+	"github.com/luxfi/pricing/pkg/aliases"
+	"github.com/luxfi/pricing/pkg/api"
+	"github.com/luxfi/pricing/pkg/audit"
+)
+
+// newTenantServer starts a server whose tenants file holds a "wallet"
+// tenant without keys, auditing to log, and returns the file's path
+func newTenantServer(t *testing.T, log *audit.Log) (*testutil.Server, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tenants.json")
+	if err := os.WriteFile(path, []byte(`[{"name":"wallet"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tenants, err := api.NewTenantRegistry(path)
+	if err != nil {
+		t.Fatalf("NewTenantRegistry: %v", err)
+	}
+	srv := testutil.NewServer(t, func(o *api.Options) {
+		o.Tenants = tenants
+		o.AuditLog = log
+	})
+	return srv, path
+}
+
+// savedKeys returns the API keys the tenants file at path holds for name
+func savedKeys(t *testing.T, path, name string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tenants []struct {
+		Name    string   `json:"name"`
+		APIKeys []string `json:"api_keys"`
+	}
+	if err := json.Unmarshal(data, &tenants); err != nil {
+		t.Fatalf("tenants file %q: %v", data, err)
+	}
+	for _, tn := range tenants {
+		if tn.Name == name {
+			return tn.APIKeys
+		}
+	}
+	return nil
+}
+
+func TestTenantKeyAudited(t *testing.T) {
+	log, err := audit.NewLog("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, path := newTenantServer(t, log)
+
+	code, body := srv.Do(t, http.MethodPost, "/admin/tenants/keys?tenant=wallet", nil, true)
+	if code != http.StatusCreated {
+		t.Fatalf("create key: %d %s", code, body)
+	}
+	var resp struct {
+		APIKey string `json:"api_key"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if keys := savedKeys(t, path, "wallet"); len(keys) != 1 || keys[0] != resp.APIKey {
+		t.Errorf("saved keys = %v, want [%s]", keys, resp.APIKey)
+	}
+	entries := log.Query(audit.Filter{Action: "tenant.key.create"})
+	if len(entries) != 1 {
+		t.Fatalf("audit entries = %+v, want one", entries)
+	}
+	if e := entries[0]; e.Actor != "test" || e.Params["tenant"] != "wallet" || !strings.HasPrefix(resp.APIKey, e.Params["key_prefix"]) {
+		t.Errorf("audit entry = %+v, want actor test, tenant wallet and a prefix of %s", e, resp.APIKey)
+	}
+	if strings.Contains(string(mustJSON(t, entries)), resp.APIKey) {
+		t.Error("audit log holds the full API key")
+	}
+}
+
+func TestTenantKeyAuditFailure(t *testing.T) {
+	log, err := audit.NewLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Writes to a closed log fail
+	log.Close()
+	srv, path := newTenantServer(t, log)
+
+	code, body := srv.Do(t, http.MethodPost, "/admin/tenants/keys?tenant=wallet", nil, true)
+	if code != http.StatusInternalServerError {
+		t.Fatalf("create key with a failing audit log: %d %s, want 500", code, body)
+	}
+	if strings.Contains(string(body), "lux_") {
+		t.Errorf("body %s leaks the key", body)
+	}
+	if keys := savedKeys(t, path, "wallet"); len(keys) != 0 {
+		t.Errorf("saved keys = %v, want none", keys)
+	}
+}
+
+func TestTenantAllowsAliases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenants.json")
+	if err := os.WriteFile(path, []byte(`[{"name":"wallet","api_keys":["wallet-key"],"allowed_tokens":["bitcoin"]}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tenants, err := api.NewTenantRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := aliases.NewTable(map[string]string{"btc": "bitcoin", "eth": "ethereum"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := testutil.NewServer(t, func(o *api.Options) {
+		o.Tenants = tenants
+		o.Aliases = table
+	})
+	// The server's provider is not wrapped in aliases.NewProvider, so the
+	// aliases are quoted directly
+	srv.Provider.Set("bitcoin", 65000)
+	srv.Provider.Set("btc", 65000)
+	srv.Provider.Set("eth", 3200)
+
+	tests := []struct {
+		token string
+		code  int
+	}{
+		{"bitcoin", http.StatusOK},
+		{"btc", http.StatusOK},
+		{"BTC", http.StatusOK},
+		{"eth", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/v1/price/"+tt.token, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-API-Key", "wallet-key")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.code {
+			t.Errorf("GET /v1/price/%s as a bitcoin-only tenant: %d, want %d", tt.token, resp.StatusCode, tt.code)
+		}
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/tokenprice"
+)
+
+// handleTokenPrice prices a token by chain and contract address
+func (s *Server) handleTokenPrice(w http.ResponseWriter, r *http.Request) {
+	if s.tokens == nil {
+		http.Error(w, `{"error":"contract prices not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	rest := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/token-price/"), "/")
+	chain, contract, ok := strings.Cut(rest, "/")
+	if !ok || chain == "" || contract == "" {
+		http.Error(w, `{"error":"chain and contract address required"}`, http.StatusBadRequest)
+		return
+	}
+	if err := checkID(chain); err != nil {
+		writeParamError(w, "chain", err)
+		return
+	}
+	if err := checkContract(contract); err != nil {
+		writeParamError(w, "contract", err)
+		return
+	}
+	// Tenants limited to some tokens list contracts as chain:contract
+	if !s.checkTokensAllowed(w, r, chain+":"+strings.ToLower(contract)) {
+		return
+	}
+
+	currency := queryCurrency(r.URL.Query())
+
+	price, err := s.tokens.Price(r.Context(), chain, contract, currency)
+	switch {
+	case errors.Is(err, tokenprice.ErrUnknownChain):
+		http.Error(w, fmt.Sprintf(`{"error":"unknown chain: %s"}`, chain), http.StatusNotFound)
+		return
+	case errors.Is(err, providers.ErrTokenNotFound):
+		http.Error(w, fmt.Sprintf(`{"error":"no price for %s on %s"}`, contract, chain), http.StatusNotFound)
+		return
+	case err != nil:
+		log.Printf("Error pricing %s on %s: %v", contract, chain, err)
+		http.Error(w, `{"error":"price unavailable"}`, http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=30")
+	json.NewEncoder(w).Encode(price)
+}
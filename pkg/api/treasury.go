@@ -0,0 +1,102 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/luxfi/pricing/pkg/treasury"
+)
+
+// treasuryHistoryResponse lists recorded valuations, newest first
+type treasuryHistoryResponse struct {
+	Period     string             `json:"period"`
+	Valuations []treasury.Summary `json:"valuations"`
+}
+
+// handleTreasury returns the latest recorded treasury valuation, or values
+// the holdings now with ?live=true, as JSON or CSV:
+// GET /admin/treasury?format=csv
+func (s *Server) handleTreasury(w http.ResponseWriter, r *http.Request) {
+	if s.treasury == nil {
+		http.Error(w, `{"error":"treasury not configured"}`, http.StatusNotFound)
+		return
+	}
+	asCSV, ok := wantsCSV(w, r)
+	if !ok {
+		return
+	}
+
+	var v *treasury.Valuation
+	var err error
+	if live := r.URL.Query().Get("live"); live == "true" || live == "1" {
+		v, err = s.treasury.Value(r.Context())
+	} else {
+		v, err = s.treasury.Latest()
+	}
+	if errors.Is(err, treasury.ErrNoValuation) {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error valuing treasury: %v", err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if asCSV {
+		writeCSV(w, "treasury-"+v.At.Format("2006-01-02")+".csv", treasury.CSVHeader, v.Rows())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// handleRecordTreasury values the holdings now and records the valuation
+// as if it were scheduled, without delivering it: POST /admin/treasury
+func (s *Server) handleRecordTreasury(w http.ResponseWriter, r *http.Request) {
+	if s.treasury == nil {
+		http.Error(w, `{"error":"treasury not configured"}`, http.StatusNotFound)
+		return
+	}
+	if err := s.audit(r, "treasury.record", nil); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+		return
+	}
+	v, err := s.treasury.Record(r.Context())
+	if err != nil {
+		log.Printf("Error valuing treasury: %v", err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// handleTreasuryHistory lists recorded treasury valuations without their
+// positions: GET /admin/treasury/history?limit=12
+func (s *Server) handleTreasuryHistory(w http.ResponseWriter, r *http.Request) {
+	if s.treasury == nil {
+		http.Error(w, `{"error":"treasury not configured"}`, http.StatusNotFound)
+		return
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, `{"error":"limit must be a positive integer"}`, http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(treasuryHistoryResponse{Period: s.treasury.Period(), Valuations: s.treasury.History(limit)})
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/luxfi/pricing/pkg/trending"
+)
+
+// maxMovers bounds the limit parameter of /movers
+const maxMovers = 100
+
+// handleTrending returns the provider's trending tokens
+func (s *Server) handleTrending(w http.ResponseWriter, r *http.Request) {
+	if s.trending == nil {
+		http.Error(w, `{"error":"trending data not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	list, err := s.trending.Trending(r.Context())
+	if err != nil {
+		log.Printf("Error fetching trending tokens: %v", err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(list)
+}
+
+// handleMovers returns the largest gainers and losers among the prices
+// already in the cache, without calling upstream
+func (s *Server) handleMovers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if window := q.Get("window"); window != "" && window != "24h" {
+		http.Error(w, fmt.Sprintf(`{"error":"unsupported window: %s"}`, window), http.StatusBadRequest)
+		return
+	}
+
+	limit := 10
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxMovers {
+			http.Error(w, fmt.Sprintf(`{"error":"limit must be between 1 and %d"}`, maxMovers), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	currency := queryCurrency(q)
+
+	// Tenants only see tokens they are allowed
+	prices := s.cache.Cached(currency)
+	if tenant := tenantFrom(r.Context()); tenant != nil {
+		allowed := prices[:0]
+		for _, p := range prices {
+			if tenant.Allows(p.ID) {
+				allowed = append(allowed, p)
+			}
+		}
+		prices = allowed
+	}
+
+	movers := trending.TopMovers(prices, currency, limit)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	json.NewEncoder(w).Encode(movers)
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/tvl"
+)
+
+// handleTVL returns a DeFi protocol's total value locked
+func (s *Server) handleTVL(w http.ResponseWriter, r *http.Request) {
+	if s.tvl == nil {
+		http.Error(w, `{"error":"TVL data not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/tvl/"), "/")
+	if id == "" {
+		http.Error(w, `{"error":"protocol required"}`, http.StatusBadRequest)
+		return
+	}
+
+	protocol, err := s.tvl.Protocol(r.Context(), id)
+	if errors.Is(err, tvl.ErrNotFound) {
+		http.Error(w, fmt.Sprintf(`{"error":"protocol not found: %s"}`, id), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching TVL for %s: %v", id, err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(protocol)
+}
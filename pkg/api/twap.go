@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/ticks"
+)
+
+// handleTWAP returns a token's time- and volume-weighted average price
+// over a window of recorded prices
+func (s *Server) handleTWAP(w http.ResponseWriter, r *http.Request) {
+	if s.ticks == nil {
+		http.Error(w, `{"error":"TWAP not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	token := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/twap/"), "/")
+	if token == "" {
+		http.Error(w, `{"error":"token id required"}`, http.StatusBadRequest)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, token) {
+		return
+	}
+
+	q := r.URL.Query()
+	window, longest := time.Hour, s.ticks.MaxWindow()
+	if longest > 0 && window > longest {
+		window = longest
+	}
+	if v := q.Get("window"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 || longest > 0 && d > longest {
+			msg := "window must be a positive duration"
+			if longest > 0 {
+				msg = fmt.Sprintf("window must be a duration up to %v", longest)
+			}
+			http.Error(w, fmt.Sprintf(`{"error":"%s"}`, msg), http.StatusBadRequest)
+			return
+		}
+		window = d
+	}
+	currency := queryCurrency(q)
+
+	avg, err := s.ticks.Average(token, currency, window)
+	if errors.Is(err, ticks.ErrNoTicks) {
+		http.Error(w, fmt.Sprintf(`{"error":"no %s prices recorded for %s in the last %v"}`, currency, token, window), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(avg)
+}
@@ -0,0 +1,298 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// udfResolution is a chart resolution TradingView can ask for, with the
+// history window its bars are cut from. Intraday bars come from the
+// 5-minutely last day or the hourly last 90 days, so longer ranges are
+// only served at daily resolution and up.
+type udfResolution struct {
+	name  string
+	width time.Duration
+	days  int
+}
+
+var udfResolutions = []udfResolution{
+	{"5", 5 * time.Minute, 1},
+	{"15", 15 * time.Minute, 1},
+	{"60", time.Hour, 90},
+	{"240", 4 * time.Hour, 90},
+	{"1D", 24 * time.Hour, history.MaxDays},
+	{"1W", 7 * 24 * time.Hour, history.MaxDays},
+}
+
+// udfConfig is the TradingView UDF datafeed configuration
+type udfConfig struct {
+	SupportedResolutions   []string        `json:"supported_resolutions"`
+	SupportsGroupRequest   bool            `json:"supports_group_request"`
+	SupportsMarks          bool            `json:"supports_marks"`
+	SupportsSearch         bool            `json:"supports_search"`
+	SupportsTimescaleMarks bool            `json:"supports_timescale_marks"`
+	SupportsTime           bool            `json:"supports_time"`
+	SymbolsTypes           []udfSymbolType `json:"symbols_types"`
+}
+
+type udfSymbolType struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// udfSymbol is TradingView symbol info
+type udfSymbol struct {
+	Name                 string   `json:"name"`
+	Ticker               string   `json:"ticker"`
+	Description          string   `json:"description"`
+	Type                 string   `json:"type"`
+	Session              string   `json:"session"`
+	Timezone             string   `json:"timezone"`
+	Exchange             string   `json:"exchange"`
+	ListedExchange       string   `json:"listed_exchange"`
+	Currency             string   `json:"currency_code"`
+	MinMov               int      `json:"minmov"`
+	PriceScale           int64    `json:"pricescale"`
+	HasIntraday          bool     `json:"has_intraday"`
+	HasWeekly            bool     `json:"has_weekly_and_monthly"`
+	VisiblePlots         string   `json:"visible_plots_set"`
+	SupportedResolutions []string `json:"supported_resolutions"`
+	IntradayMultipliers  []string `json:"intraday_multipliers"`
+	DataStatus           string   `json:"data_status"`
+}
+
+// udfBars are chart bars in TradingView's columnar format. S is "ok",
+// "no_data" or "error".
+type udfBars struct {
+	S      string    `json:"s"`
+	ErrMsg string    `json:"errmsg,omitempty"`
+	T      []int64   `json:"t,omitempty"`
+	O      []float64 `json:"o,omitempty"`
+	H      []float64 `json:"h,omitempty"`
+	L      []float64 `json:"l,omitempty"`
+	C      []float64 `json:"c,omitempty"`
+}
+
+// handleUDFConfig describes the datafeed to TradingView
+func (s *Server) handleUDFConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	json.NewEncoder(w).Encode(udfConfig{
+		SupportedResolutions: udfResolutionNames(),
+		SupportsTime:         true,
+		SymbolsTypes:         []udfSymbolType{{Name: "Crypto", Value: "crypto"}},
+	})
+}
+
+// handleUDFTime returns the server time in unix seconds, which TradingView
+// aligns its requests to
+func (s *Server) handleUDFTime(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write([]byte(strconv.FormatInt(time.Now().Unix(), 10)))
+}
+
+// handleUDFSymbol resolves a symbol, a token id optionally followed by
+// "/currency", e.g. "lux-network/usd"
+func (s *Server) handleUDFSymbol(w http.ResponseWriter, r *http.Request) {
+	token, currency, err := parseUDFSymbol(r.URL.Query().Get("symbol"))
+	if err != nil {
+		writeUDFError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !s.checkTokensAllowed(w, r, token) {
+		return
+	}
+
+	price, err := s.cache.GetPrice(r.Context(), token, currency)
+	if errors.Is(err, providers.ErrTokenNotFound) {
+		writeUDFError(w, http.StatusNotFound, "unknown_symbol")
+		return
+	}
+	if err != nil {
+		writeUpstreamError(w, r, "price unavailable", err)
+		return
+	}
+
+	ticker := token + "/" + currency
+	name := price.Name
+	if name == "" {
+		name = token
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(udfSymbol{
+		Name:                 strings.ToUpper(ticker),
+		Ticker:               ticker,
+		Description:          name + " / " + strings.ToUpper(currency),
+		Type:                 "crypto",
+		Session:              "24x7",
+		Timezone:             "Etc/UTC",
+		Exchange:             "Lux",
+		ListedExchange:       "Lux",
+		Currency:             strings.ToUpper(currency),
+		MinMov:               1,
+		PriceScale:           priceScale(price.Price),
+		HasIntraday:          true,
+		HasWeekly:            true,
+		VisiblePlots:         "ohlc",
+		SupportedResolutions: udfResolutionNames(),
+		IntradayMultipliers:  []string{"5", "15", "60", "240"},
+		DataStatus:           "streaming",
+	})
+}
+
+// handleUDFHistory returns a symbol's bars from from to to, or the
+// countback bars before to
+func (s *Server) handleUDFHistory(w http.ResponseWriter, r *http.Request) {
+	if s.history == nil {
+		http.Error(w, `{"error":"price history not configured"}`, http.StatusNotFound)
+		return
+	}
+
+	q := r.URL.Query()
+	token, currency, err := parseUDFSymbol(q.Get("symbol"))
+	if err != nil {
+		writeUDFError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !s.checkTokensAllowed(w, r, token) {
+		return
+	}
+	res, ok := findUDFResolution(q.Get("resolution"))
+	if !ok {
+		writeUDFError(w, http.StatusBadRequest, "resolution must be one of "+strings.Join(udfResolutionNames(), ", "))
+		return
+	}
+	from, err1 := strconv.ParseInt(q.Get("from"), 10, 64)
+	to, err2 := strconv.ParseInt(q.Get("to"), 10, 64)
+	if err1 != nil || err2 != nil || from > to {
+		writeUDFError(w, http.StatusBadRequest, "from and to must be unix seconds with from <= to")
+		return
+	}
+	countback := 0
+	if v := q.Get("countback"); v != "" {
+		if countback, err = strconv.Atoi(v); err != nil || countback < 0 {
+			writeUDFError(w, http.StatusBadRequest, "countback must be a non-negative integer")
+			return
+		}
+	}
+
+	series, err := s.history.History(r.Context(), token, currency, res.days)
+	if errors.Is(err, providers.ErrTokenNotFound) {
+		writeUDFError(w, http.StatusNotFound, "unknown_symbol")
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching %s history: %v", token, err)
+		writeUDFError(w, http.StatusBadGateway, "history unavailable")
+		return
+	}
+
+	// Bars are taken from before to; all of them from from on, or the
+	// last countback if TradingView asks for a count
+	bars := history.Bars(series.Points, res.width)
+	end := len(bars)
+	for end > 0 && bars[end-1].Time.Unix() >= to {
+		end--
+	}
+	start := 0
+	if countback > 0 {
+		start = max(end-countback, 0)
+	} else {
+		for start < end && bars[start].Time.Unix() < from {
+			start++
+		}
+	}
+
+	resp := udfBars{S: "no_data"}
+	if start < end {
+		resp.S = "ok"
+		for _, b := range bars[start:end] {
+			resp.T = append(resp.T, b.Time.Unix())
+			resp.O = append(resp.O, b.Open)
+			resp.H = append(resp.H, b.High)
+			resp.L = append(resp.L, b.Low)
+			resp.C = append(resp.C, b.Close)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// parseUDFSymbol splits a symbol into a token id and currency, usd if
+// none is given. An "EXCHANGE:" prefix is ignored.
+func parseUDFSymbol(symbol string) (token, currency string, err error) {
+	if i := strings.LastIndexByte(symbol, ':'); i >= 0 {
+		symbol = symbol[i+1:]
+	}
+	token, currency, _ = strings.Cut(strings.ToLower(symbol), "/")
+	if token == "" {
+		return "", "", errors.New("symbol required")
+	}
+	if currency == "" {
+		currency = "usd"
+	}
+	if err := checkID(token); err != nil {
+		return "", "", err
+	}
+	if err := checkCurrency(currency); err != nil {
+		return "", "", err
+	}
+	return token, currency, nil
+}
+
+// writeUDFError answers in the UDF error format
+func writeUDFError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(udfBars{S: "error", ErrMsg: message})
+}
+
+func findUDFResolution(name string) (udfResolution, bool) {
+	// TradingView also spells daily and weekly as "D" and "W"
+	switch name {
+	case "D":
+		name = "1D"
+	case "W":
+		name = "1W"
+	}
+	for _, res := range udfResolutions {
+		if res.name == name {
+			return res, true
+		}
+	}
+	return udfResolution{}, false
+}
+
+func udfResolutionNames() []string {
+	names := make([]string, len(udfResolutions))
+	for i, res := range udfResolutions {
+		names[i] = res.name
+	}
+	return names
+}
+
+// priceScale returns the TradingView price scale showing about six
+// significant digits of price, and at least two decimals
+func priceScale(price float64) int64 {
+	decimals := 2
+	if price > 0 {
+		decimals = max(decimals, 5-int(math.Floor(math.Log10(price))))
+	}
+	return int64(math.Pow10(min(decimals, 12)))
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/analytics"
+	"github.com/luxfi/pricing/pkg/history"
+)
+
+// Input limits enforced before a request reaches its handler, so malformed
+// or abusive parameters never become upstream URLs
+const (
+	// MaxIDLength is the longest token id or slug accepted
+	MaxIDLength = 100
+
+	// MaxIDs is the most token ids in one ids list
+	MaxIDs = 250
+
+	// MaxCurrencies is the most currencies in one currency list
+	MaxCurrencies = 25
+)
+
+// paramError is the body of a 400 for an invalid parameter
+type paramError struct {
+	Error string `json:"error"`
+	Param string `json:"param"`
+}
+
+// writeParamError rejects a request because of one parameter
+func writeParamError(w http.ResponseWriter, name string, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(paramError{Error: err.Error(), Param: name})
+}
+
+// validateMiddleware checks the route's declared parameters that have a
+// check, answering 400 for the first invalid one. Empty values are left
+// to the handler, which knows whether they are required. A path parameter
+// is everything after the pattern but the route's literal suffix, so an
+// encoded slash cannot smuggle extra segments past the check.
+func (s *Server) validateMiddleware(rt route) Middleware {
+	var checked []param
+	for _, p := range rt.Params {
+		if p.check != nil {
+			checked = append(checked, p)
+		}
+	}
+	suffix := rt.Path[strings.LastIndex(rt.Path, "}")+1:]
+	return func(next http.Handler) http.Handler {
+		if len(checked) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query()
+			for _, p := range checked {
+				var v string
+				if p.In == "path" {
+					v = strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, rt.Pattern), "/")
+					v = strings.TrimSuffix(v, suffix)
+				} else {
+					v = q.Get(p.Name)
+				}
+				if v == "" {
+					continue
+				}
+				if err := p.check(v); err != nil {
+					writeParamError(w, p.Name, err)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// checkID accepts a token id or slug: letters, digits, '-', '_' and '.',
+// but not only dots, which would be path segments in upstream URLs
+func checkID(v string) error {
+	if len(v) > MaxIDLength {
+		return fmt.Errorf("id longer than %d characters", MaxIDLength)
+	}
+	if strings.Trim(v, ".") == "" {
+		return fmt.Errorf("invalid id %s", v)
+	}
+	for _, c := range v {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return fmt.Errorf("invalid id %s: only letters, digits, '-', '_' and '.' are allowed", v)
+		}
+	}
+	return nil
+}
+
+// checkContract accepts a contract address: an EVM 0x address, or the
+// letters and digits of another chain's, such as a Solana mint
+func checkContract(v string) error {
+	if len(v) > 64 {
+		return errors.New("contract address longer than 64 characters")
+	}
+	for _, c := range v {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return fmt.Errorf("invalid contract address %s", v)
+		}
+	}
+	return nil
+}
+
+// checkIDs accepts a comma-separated list of up to MaxIDs ids
+func checkIDs(v string) error {
+	ids := strings.Split(v, ",")
+	if len(ids) > MaxIDs {
+		return fmt.Errorf("at most %d ids allowed", MaxIDs)
+	}
+	for _, id := range ids {
+		if id == "" {
+			return errors.New("empty id in list")
+		}
+		if err := checkID(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkBenchmark accepts a token id or an index:<name> benchmark
+func checkBenchmark(v string) error {
+	return checkID(strings.TrimPrefix(strings.ToLower(v), analytics.IndexPrefix))
+}
+
+// checkCurrency accepts a 3 to 5 letter currency code in either case
+func checkCurrency(v string) error {
+	if len(v) < 3 || len(v) > 5 {
+		return errors.New("currency codes are 3 to 5 letters")
+	}
+	for _, c := range v {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			return fmt.Errorf("invalid currency %s: codes are 3 to 5 letters", v)
+		}
+	}
+	return nil
+}
+
+// checkCurrencies accepts a comma-separated list of up to MaxCurrencies
+// currency codes
+func checkCurrencies(v string) error {
+	codes := strings.Split(v, ",")
+	if len(codes) > MaxCurrencies {
+		return fmt.Errorf("at most %d currencies allowed", MaxCurrencies)
+	}
+	for _, code := range codes {
+		if err := checkCurrency(strings.TrimSpace(code)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryCurrency returns the currency query parameter trimmed and
+// lowercased, usd if none is given. Validation accepts either case, so
+// handlers normalize before the cache lookup to keep USD and usd one entry.
+func queryCurrency(q url.Values) string {
+	currency := strings.ToLower(strings.TrimSpace(q.Get("currency")))
+	if currency == "" {
+		return "usd"
+	}
+	return currency
+}
+
+// splitCurrencies splits a comma-separated currency list, trimming and
+// lowercasing each code; usd if the list is empty
+func splitCurrencies(v string) []string {
+	if strings.TrimSpace(v) == "" {
+		return []string{"usd"}
+	}
+	codes := strings.Split(v, ",")
+	for i, code := range codes {
+		codes[i] = strings.ToLower(strings.TrimSpace(code))
+	}
+	return codes
+}
+
+// checkDays accepts a whole number of days up to history.MaxDays; handlers
+// may bound it further
+func checkDays(v string) error {
+	if n, err := strconv.Atoi(v); err != nil || n < 1 || n > history.MaxDays {
+		return fmt.Errorf("days must be between 1 and %d", history.MaxDays)
+	}
+	return nil
+}
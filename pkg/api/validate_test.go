@@ -0,0 +1,47 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/luxfi/pricing/internal/testutil"
+	"github.com/luxfi/pricing/pkg/cache"
+)
+
+func TestCurrencySharesCacheEntry(t *testing.T) {
+	srv := testutil.NewServer(t)
+	srv.Provider.Set("bitcoin", 65000)
+
+	for i, currency := range []string{"usd", "USD", "Usd", ""} {
+		var price cache.PriceResponse
+		path := "/v1/price/bitcoin?currency=" + url.QueryEscape(currency)
+		if code := srv.GetJSON(t, path, &price); code != http.StatusOK {
+			t.Fatalf("GET %s: status %d", path, code)
+		}
+		if price.Currency != "usd" {
+			t.Errorf("GET %s: currency %q, want usd", path, price.Currency)
+		}
+		if cached := i > 0; price.Cached != cached {
+			t.Errorf("GET %s: cached = %v, want %v", path, price.Cached, cached)
+		}
+	}
+	if n := srv.Provider.Calls(); n != 1 {
+		t.Errorf("provider calls = %d, want 1 shared by every spelling of usd", n)
+	}
+
+	// The CoinGecko-compatible list is normalized the same way
+	var simple map[string]map[string]float64
+	if code := srv.GetJSON(t, "/v1/simple/price?ids=bitcoin&vs_currencies=USD", &simple); code != http.StatusOK {
+		t.Fatalf("simple price: status %d", code)
+	}
+	if simple["bitcoin"]["usd"] != 65000 {
+		t.Errorf("simple price = %v, want bitcoin.usd 65000", simple)
+	}
+	if n := srv.Provider.Calls(); n != 1 {
+		t.Errorf("provider calls after simple price = %d, want 1", n)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// APIVersion is the current API version. Responses under /v1 are frozen:
+// fields may be added but never renamed, retyped or removed.
+const APIVersion = "v1"
+
+// legacyDeprecatedAt is when unversioned routes were deprecated
+var legacyDeprecatedAt = time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+
+// versionRouter serves routes under /v1, rejects unreleased versions, and
+// serves unversioned legacy routes with deprecation headers. The API
+// description is served unversioned at /openapi.json and /docs, and the
+// dashboard at /.
+func (s *Server) versionRouter(routes http.Handler) http.Handler {
+	root := http.NewServeMux()
+	root.HandleFunc("/openapi.json", s.handleOpenAPI)
+	root.HandleFunc("/docs", s.handleDocs)
+	root.Handle("/"+APIVersion+"/", http.StripPrefix("/"+APIVersion, routes))
+	root.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"API version v2 is not available"}`, http.StatusNotFound)
+	})
+	legacy := s.deprecated(routes)
+	root.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" && (r.Method == http.MethodGet || r.Method == http.MethodHead) && s.featureOn(FeatureDashboard) {
+			s.handleDashboard(w, r)
+			return
+		}
+		legacy.ServeHTTP(w, r)
+	}))
+	return root
+}
+
+// deprecated marks unversioned routes with Deprecation, Sunset and a Link
+// to the versioned successor. Health checks are unversioned by design.
+func (s *Server) deprecated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" && r.Method != http.MethodOptions {
+			w.Header().Set("Deprecation", fmt.Sprintf("@%d", legacyDeprecatedAt.Unix()))
+			if sunset := s.current().legacySunset; !sunset.IsZero() {
+				w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+			w.Header().Add("Link", fmt.Sprintf(`</%s%s>; rel="successor-version"`, APIVersion, r.URL.Path))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/alerts"
+)
+
+// deliveriesResponse lists an alert's recent deliveries
+type deliveriesResponse struct {
+	Alert      string            `json:"alert"`
+	Deliveries []alerts.Delivery `json:"deliveries"` // newest first
+}
+
+// deadLettersResponse lists deliveries that failed every attempt
+type deadLettersResponse struct {
+	DeadLetters []alerts.Delivery `json:"dead_letters"` // newest first
+}
+
+// handleWebhookDeliveries returns the recent deliveries of one of the
+// calling tenant's alerts and each attempt made:
+// GET /webhooks/{id}/deliveries
+func (s *Server) handleWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := strings.CutSuffix(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/webhooks/"), "/"), "/deliveries")
+	if !ok || id == "" || strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	tenant := s.alertTenant(w, r)
+	if tenant == "" {
+		return
+	}
+	if _, err := s.alerts.Get(tenant, id); err != nil {
+		writeAlertError(w, err)
+		return
+	}
+
+	resp := deliveriesResponse{Alert: id, Deliveries: []alerts.Delivery{}}
+	if s.channels != nil && s.channels.Deliveries != nil {
+		resp.Deliveries = s.channels.Deliveries.Deliveries(id)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// deadLetters returns the delivery log, answering 404 if there is none
+func (s *Server) deadLetters(w http.ResponseWriter) *alerts.DeliveryLog {
+	if s.channels == nil || s.channels.Deliveries == nil {
+		http.Error(w, `{"error":"deliveries not configured"}`, http.StatusNotFound)
+		return nil
+	}
+	return s.channels.Deliveries
+}
+
+// handleDeadLetters lists deliveries that failed every attempt:
+// GET /admin/dead-letters
+func (s *Server) handleDeadLetters(w http.ResponseWriter, r *http.Request) {
+	deliveries := s.deadLetters(w)
+	if deliveries == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(deadLettersResponse{DeadLetters: deliveries.DeadLetters()})
+}
+
+// deadLetterID returns the id of an /admin/dead-letters/{id} request,
+// with suffix trimmed, answering 400 if there is none
+func deadLetterID(w http.ResponseWriter, r *http.Request, suffix string) string {
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/admin/dead-letters/"), "/")
+	id = strings.TrimSuffix(id, suffix)
+	if id == "" || strings.Contains(id, "/") {
+		http.Error(w, `{"error":"dead letter id required"}`, http.StatusBadRequest)
+		return ""
+	}
+	return id
+}
+
+// handleReplayDeadLetter delivers a dead letter again as a new delivery
+// and removes it: POST /admin/dead-letters/{id}/replay
+func (s *Server) handleReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if s.deadLetters(w) == nil {
+		return
+	}
+	if !strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/replay") {
+		http.NotFound(w, r)
+		return
+	}
+	id := deadLetterID(w, r, "/replay")
+	if id == "" {
+		return
+	}
+	if err := s.audit(r, "dead_letters.replay", map[string]string{"id": id}); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+		return
+	}
+	d, err := s.channels.Replay(id)
+	if err != nil {
+		writeDeadLetterError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(d)
+}
+
+// handleDeleteDeadLetter discards a dead letter:
+// DELETE /admin/dead-letters/{id}
+func (s *Server) handleDeleteDeadLetter(w http.ResponseWriter, r *http.Request) {
+	deliveries := s.deadLetters(w)
+	if deliveries == nil {
+		return
+	}
+	id := deadLetterID(w, r, "")
+	if id == "" {
+		return
+	}
+	if err := s.audit(r, "dead_letters.delete", map[string]string{"id": id}); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if _, err := deliveries.Remove(id); err != nil {
+		writeDeadLetterError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeDeadLetterError maps delivery log errors to status codes
+func writeDeadLetterError(w http.ResponseWriter, err error) {
+	if errors.Is(err, alerts.ErrNoDeadLetter) {
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusNotFound)
+		return
+	}
+	http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/format"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// widgetRefresh is how often an embedded widget refreshes its price
+const widgetRefresh = time.Minute
+
+// widgetPage is a one-token price badge for partner sites to embed in an
+// iframe. It is rendered with the current price and refreshes itself.
+//
+//go:embed widget.html
+var widgetHTML string
+
+var widgetPage = template.Must(template.New("widget").Parse(widgetHTML))
+
+// widgetData fills widgetPage
+type widgetData struct {
+	Locale        string
+	Theme         string
+	Name          string
+	Symbol        string
+	Price         string
+	Change        string
+	Direction     string // "up" or "down"
+	RefreshURL    string
+	RefreshMillis int64
+}
+
+// handleWidget serves the embeddable price widget of a token
+func (s *Server) handleWidget(w http.ResponseWriter, r *http.Request) {
+	tokenID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/widget/"), "/")
+	if tokenID == "" {
+		http.Error(w, `{"error":"token_id required"}`, http.StatusBadRequest)
+		return
+	}
+	if !s.checkTokensAllowed(w, r, tokenID) {
+		return
+	}
+
+	q := r.URL.Query()
+	currency := queryCurrency(q)
+	theme := q.Get("theme")
+	switch theme {
+	case "":
+		theme = "light"
+	case "light", "dark":
+	default:
+		writeParamError(w, "theme", errors.New("theme is light or dark"))
+		return
+	}
+	loc, err := format.ParseLocale(firstOf(q.Get("locale"), format.DefaultLocale))
+	if err != nil {
+		writeParamError(w, "locale", err)
+		return
+	}
+
+	price, err := s.cache.GetPrice(r.Context(), tokenID, currency)
+	if errors.Is(err, providers.ErrTokenNotFound) {
+		s.writeNotFound(w, tokenID, err)
+		return
+	}
+	if err != nil {
+		writeUpstreamError(w, r, "price unavailable", err)
+		return
+	}
+	formatPrices(loc, price)
+
+	// The page refreshes from /price, relative to its own path so it works
+	// with and without the version prefix
+	refresh := url.Values{"currency": {currency}, "locale": {loc.Name}, "fields": {"change_24h,formatted"}}
+	if key := q.Get("api_key"); key != "" {
+		refresh.Set("api_key", key)
+	}
+	refreshURL := "../price/" + url.PathEscape(tokenID) + "?" + refresh.Encode()
+	if strings.HasSuffix(r.URL.Path, "/") {
+		refreshURL = "../" + refreshURL
+	}
+
+	data := widgetData{
+		Locale:        loc.Name,
+		Theme:         theme,
+		Name:          firstOf(price.Name, price.ID),
+		Symbol:        strings.ToUpper(price.Symbol),
+		Price:         price.Formatted.Price,
+		Change:        price.Formatted.Change24h,
+		Direction:     "up",
+		RefreshURL:    refreshURL,
+		RefreshMillis: widgetRefresh.Milliseconds(),
+	}
+	if price.Change24h < 0 {
+		data.Direction = "down"
+	}
+	var buf bytes.Buffer
+	if err := widgetPage.Execute(&buf, data); err != nil {
+		http.Error(w, `{"error":"rendering widget failed"}`, http.StatusInternalServerError)
+		return
+	}
+
+	s.setCacheControl(w, r, EndpointPrice, price.UpdatedAt)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; script-src 'unsafe-inline'; connect-src 'self'")
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package audit records admin actions in an append-only log.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// Entry records a single admin action
+type Entry = wire.AuditEntry
+
+// Filter selects entries from the audit log
+type Filter = wire.AuditFilter
+
+// Log is an append-only log of admin actions. Entries are kept in
+// memory for querying and, when a path is configured, appended to a
+// JSON-lines file that survives restarts.
+type Log struct {
+	mu      sync.RWMutex
+	entries []Entry
+	nextID  int64
+	file    *os.File
+}
+
+// NewLog opens the audit log at path, loading any existing entries.
+// An empty path keeps the log in memory only.
+func NewLog(path string) (*Log, error) {
+	al := &Log{nextID: 1}
+	if path == "" {
+		return al, nil
+	}
+
+	if f, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var e Entry
+			if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+				f.Close()
+				return nil, fmt.Errorf("audit log %s: %w", path, err)
+			}
+			al.entries = append(al.entries, e)
+			if e.ID >= al.nextID {
+				al.nextID = e.ID + 1
+			}
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("audit log %s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	al.file = f
+	return al, nil
+}
+
+// Record appends an entry to the log
+func (al *Log) Record(actor, action, remoteIP string, params map[string]string) (Entry, error) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	entry := Entry{
+		ID:        al.nextID,
+		Actor:     actor,
+		Action:    action,
+		Params:    params,
+		RemoteIP:  remoteIP,
+		Timestamp: time.Now().UTC(),
+	}
+
+	if al.file != nil {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return Entry{}, err
+		}
+		if _, err := al.file.Write(append(line, '\n')); err != nil {
+			return Entry{}, err
+		}
+		if err := al.file.Sync(); err != nil {
+			return Entry{}, err
+		}
+	}
+
+	al.nextID++
+	al.entries = append(al.entries, entry)
+	return entry, nil
+}
+
+// Query returns matching entries, newest first
+func (al *Log) Query(f Filter) []Entry {
+	al.mu.RLock()
+	defer al.mu.RUnlock()
+
+	result := []Entry{}
+	for i := len(al.entries) - 1; i >= 0; i-- {
+		e := al.entries[i]
+		if f.Actor != "" && e.Actor != f.Actor {
+			continue
+		}
+		if f.Action != "" && e.Action != f.Action {
+			continue
+		}
+		if !f.Since.IsZero() && e.Timestamp.Before(f.Since) {
+			break
+		}
+		result = append(result, e)
+		if f.Limit > 0 && len(result) >= f.Limit {
+			break
+		}
+	}
+	return result
+}
+
+// Close closes the underlying file
+func (al *Log) Close() error {
+	if al.file == nil {
+		return nil
+	}
+	return al.file.Close()
+}
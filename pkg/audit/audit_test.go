@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ids returns the ids of entries, in order
+func ids(entries []Entry) []int64 {
+	result := make([]int64, len(entries))
+	for i, e := range entries {
+		result[i] = e.ID
+	}
+	return result
+}
+
+func equalIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLogReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	al, err := NewLog(path)
+	if err != nil {
+		t.Fatalf("NewLog: %v", err)
+	}
+	if _, err := al.Record("alice", "cache.flush", "10.0.0.1", map[string]string{"token": "bitcoin"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := al.Record("bob", "config.reload", "10.0.0.2", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := al.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	al, err = NewLog(path)
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	defer al.Close()
+
+	entries := al.Query(Filter{})
+	if got := ids(entries); !equalIDs(got, []int64{2, 1}) {
+		t.Fatalf("ids after reload = %v, want [2 1]", got)
+	}
+	if e := entries[1]; e.Actor != "alice" || e.Action != "cache.flush" || e.RemoteIP != "10.0.0.1" || e.Params["token"] != "bitcoin" {
+		t.Errorf("reloaded entry = %+v", e)
+	}
+
+	// Ids continue after the reloaded entries, and new entries are appended
+	e, err := al.Record("carol", "tenant.key.create", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.ID != 3 {
+		t.Errorf("id after reload = %d, want 3", e.ID)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "\n"); n != 3 {
+		t.Errorf("file holds %d lines, want 3", n)
+	}
+}
+
+func TestLogReloadErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := os.WriteFile(path, []byte("{\"id\":1}\nnot json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewLog(path); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("NewLog of a corrupt file: %v, want an error naming it", err)
+	}
+
+	if _, err := NewLog(filepath.Join(path, "not-a-dir", "audit.jsonl")); err == nil {
+		t.Error("NewLog under a file succeeded")
+	}
+}
+
+func TestLogMemoryOnly(t *testing.T) {
+	al, err := NewLog("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := al.Record("alice", "cache.flush", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(al.Query(Filter{})); !equalIDs(got, []int64{1}) {
+		t.Errorf("ids = %v, want [1]", got)
+	}
+	if err := al.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
+
+func TestLogQuery(t *testing.T) {
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	var lines []string
+	for i, e := range []struct{ actor, action string }{
+		{"alice", "cache.flush"},
+		{"bob", "config.reload"},
+		{"alice", "tenant.key.create"},
+		{"alice", "cache.flush"},
+		{"bob", "cache.flush"},
+	} {
+		ts := base.Add(time.Duration(i) * time.Hour).Format(time.RFC3339)
+		lines = append(lines, fmt.Sprintf(`{"id":%d,"actor":%q,"action":%q,"timestamp":%q}`, i+1, e.actor, e.action, ts))
+	}
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	al, err := NewLog(path)
+	if err != nil {
+		t.Fatalf("NewLog: %v", err)
+	}
+	defer al.Close()
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []int64
+	}{
+		{"all, newest first", Filter{}, []int64{5, 4, 3, 2, 1}},
+		{"actor", Filter{Actor: "alice"}, []int64{4, 3, 1}},
+		{"action", Filter{Action: "cache.flush"}, []int64{5, 4, 1}},
+		{"actor and action", Filter{Actor: "bob", Action: "cache.flush"}, []int64{5}},
+		{"since is inclusive", Filter{Since: base.Add(2 * time.Hour)}, []int64{5, 4, 3}},
+		{"since after all", Filter{Since: base.Add(5 * time.Hour)}, []int64{}},
+		{"limit", Filter{Limit: 2}, []int64{5, 4}},
+		{"limit counts matches only", Filter{Actor: "alice", Limit: 2}, []int64{4, 3}},
+		{"limit above matches", Filter{Action: "config.reload", Limit: 10}, []int64{2}},
+		{"since and actor", Filter{Actor: "bob", Since: base.Add(time.Hour)}, []int64{5, 2}},
+		{"no match", Filter{Actor: "mallory"}, []int64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := al.Query(tt.filter)
+			if got == nil {
+				t.Fatal("Query returned nil, want an empty slice")
+			}
+			if !equalIDs(ids(got), tt.want) {
+				t.Errorf("ids = %v, want %v", ids(got), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package bridge serves the exchange rates the Lux bridge converts
+// between wrapped and native assets at, rounded conservatively and never
+// older than a bound.
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// ErrUnknownPair is returned for pairs that are not configured
+var ErrUnknownPair = errors.New("unknown pair")
+
+// PriceFunc returns a token's price in currency no older than maxAge if
+// it can, and when it was fetched
+type PriceFunc func(ctx context.Context, token, currency string, maxAge time.Duration) (decimal.Decimal, time.Time, error)
+
+// Pair is a base asset priced in a quote asset, e.g. a wrapped token in
+// its native token
+type Pair struct {
+	Base  string
+	Quote string
+}
+
+// Name returns the pair as "base/quote"
+func (p Pair) Name() string {
+	return p.Base + "/" + p.Quote
+}
+
+// Rate is a pair's exchange rate. Rate and Inverse are rounded down, so a
+// conversion either way never credits more than the value received.
+type Rate = wire.BridgeRate
+
+// Rates are the requested pairs' rates, all no older than MaxAge
+type Rates = wire.BridgeRates
+
+// PairError explains why a pair has no rate
+type PairError struct {
+	Pair string
+	Err  error
+}
+
+func (e *PairError) Error() string {
+	return e.Pair + ": " + e.Err.Error()
+}
+
+func (e *PairError) Unwrap() error {
+	return e.Err
+}
+
+// StaleError is returned when a price is older than the allowed age
+type StaleError struct {
+	Token  string
+	Age    time.Duration
+	MaxAge time.Duration
+}
+
+func (e *StaleError) Error() string {
+	return fmt.Sprintf("%s price is %ds old, more than %ds", e.Token, int64(e.Age.Seconds()), int64(e.MaxAge.Seconds()))
+}
+
+// Options configures a Service
+type Options struct {
+	// Pairs are the pairs served. A quote equal to Currency is priced
+	// directly; any other quote is a token id and both sides are priced
+	// in Currency.
+	Pairs    []Pair
+	Currency string
+	MaxAge   time.Duration // oldest price a rate may be derived from
+	Decimals int           // fractional digits of rates
+}
+
+// Service computes bridge rates from current prices
+type Service struct {
+	opts  Options
+	pairs map[string]Pair // name -> pair
+	price PriceFunc
+}
+
+// NewService creates a service for the configured pairs
+func NewService(opts Options, price PriceFunc) *Service {
+	s := &Service{opts: opts, pairs: make(map[string]Pair, len(opts.Pairs)), price: price}
+	for _, p := range opts.Pairs {
+		p = Pair{Base: strings.ToLower(p.Base), Quote: strings.ToLower(p.Quote)}
+		s.pairs[p.Name()] = p
+	}
+	return s
+}
+
+// Pairs returns the configured pair names, sorted
+func (s *Service) Pairs() []string {
+	names := make([]string, 0, len(s.pairs))
+	for name := range s.pairs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MaxAge returns the oldest price rates may be derived from
+func (s *Service) MaxAge() time.Duration {
+	return s.opts.MaxAge
+}
+
+// Rates returns the rates of the named pairs, or of all pairs if none are
+// named, with no price older than maxAge (the configured bound if 0 or
+// larger). It fails rather than returning any stale or missing rate; the
+// error joins a *PairError for each pair without one.
+func (s *Service) Rates(ctx context.Context, names []string, maxAge time.Duration) (*Rates, error) {
+	if maxAge <= 0 || maxAge > s.opts.MaxAge {
+		maxAge = s.opts.MaxAge
+	}
+	if len(names) == 0 {
+		names = s.Pairs()
+	}
+	pairs := make([]Pair, len(names))
+	for i, name := range names {
+		p, ok := s.pairs[strings.ToLower(name)]
+		if !ok {
+			return nil, &PairError{Pair: name, Err: ErrUnknownPair}
+		}
+		pairs[i] = p
+	}
+
+	var (
+		wg    sync.WaitGroup
+		rates = make([]Rate, len(pairs))
+		errs  = make([]error, len(pairs))
+	)
+	for i, p := range pairs {
+		wg.Add(1)
+		go func(i int, p Pair) {
+			defer wg.Done()
+			rates[i], errs[i] = s.rate(ctx, p, maxAge)
+			if errs[i] != nil {
+				errs[i] = &PairError{Pair: p.Name(), Err: errs[i]}
+			}
+		}(i, p)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	out := &Rates{
+		Currency: s.opts.Currency,
+		MaxAge:   int64(maxAge.Seconds()),
+		Decimals: s.opts.Decimals,
+		Rates:    rates,
+		Time:     now,
+	}
+	for _, r := range rates {
+		if out.UpdatedAt.IsZero() || r.UpdatedAt.Before(out.UpdatedAt) {
+			out.UpdatedAt = r.UpdatedAt
+		}
+	}
+	return out, nil
+}
+
+// rate prices one pair from fresh prices of both sides
+func (s *Service) rate(ctx context.Context, p Pair, maxAge time.Duration) (Rate, error) {
+	base, updated, err := s.fresh(ctx, p.Base, maxAge)
+	if err != nil {
+		return Rate{}, err
+	}
+	quote := decimal.FromFloat(1)
+	if p.Quote != s.opts.Currency {
+		var quoteUpdated time.Time
+		if quote, quoteUpdated, err = s.fresh(ctx, p.Quote, maxAge); err != nil {
+			return Rate{}, err
+		}
+		if quoteUpdated.Before(updated) {
+			updated = quoteUpdated
+		}
+	}
+	if base.Sign() <= 0 || quote.Sign() <= 0 {
+		return Rate{}, errors.New("price is not positive")
+	}
+
+	rate := base.Quo(quote)
+	return Rate{
+		Pair:       p.Name(),
+		Base:       p.Base,
+		Quote:      p.Quote,
+		Rate:       rate.Floor(s.opts.Decimals),
+		Inverse:    quote.Quo(base).Floor(s.opts.Decimals),
+		BasePrice:  base,
+		QuotePrice: quote,
+		UpdatedAt:  updated.UTC(),
+		AgeSeconds: int64(time.Since(updated).Seconds()),
+	}, nil
+}
+
+// fresh returns a token's price in the reference currency, failing if it
+// is older than maxAge
+func (s *Service) fresh(ctx context.Context, token string, maxAge time.Duration) (decimal.Decimal, time.Time, error) {
+	price, updated, err := s.price(ctx, token, s.opts.Currency, maxAge)
+	if err != nil {
+		return decimal.Decimal{}, time.Time{}, err
+	}
+	if age := time.Since(updated); age > maxAge {
+		return decimal.Decimal{}, time.Time{}, &StaleError{Token: token, Age: age, MaxAge: maxAge}
+	}
+	return price, updated, nil
+}
@@ -0,0 +1,473 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package cache serves token prices from memory, refreshing them from an
+// upstream provider when they expire.
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// DefaultTTL - 1 hour
+const DefaultTTL = 1 * time.Hour
+
+// PriceCache holds cached price data
+type PriceCache struct {
+	prices   *priceStore
+	provider providers.Provider
+	ttl      atomic.Int64 // time.Duration
+
+	maxChange  atomic.Uint64 // float64 bits
+	quarantine quarantine
+
+	notFoundTTL atomic.Int64 // time.Duration
+	notFound    notFound
+
+	maxAges atomic.Pointer[map[string]time.Duration]
+
+	hits   atomic.Int64
+	misses atomic.Int64
+
+	staleAfter   atomic.Int64 // time.Duration
+	lastRefresh  atomic.Int64 // unix nanoseconds
+	failingSince atomic.Int64 // unix nanoseconds, 0 while refreshes succeed
+
+	onRefresh []RefreshFunc
+
+	applied atomic.Int64 // prices stored from other replicas
+}
+
+// RefreshFunc receives prices in currency just fetched from upstream
+type RefreshFunc func(currency string, prices []*PriceResponse)
+
+// Stats counts cache lookups and cached prices
+type Stats struct {
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	Entries int   `json:"entries"`           // token and currency pairs cached, expired included
+	Applied int64 `json:"applied,omitempty"` // prices stored from other replicas
+}
+
+// CachedPrice holds a single cached price entry
+type CachedPrice struct {
+	Symbol    string    `json:"symbol,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Price     float64   `json:"price"`
+	PriceStr  string    `json:"price_str"`
+	Source    string    `json:"source,omitempty"`
+	Currency  string    `json:"currency"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Change24h float64   `json:"change_24h,omitempty"`
+	MarketCap float64   `json:"market_cap,omitempty"`
+	Volume24h float64   `json:"volume_24h,omitempty"`
+}
+
+// Response shapes are defined in wire, shared with the Go client
+type (
+	PriceResponse      = wire.PriceResponse
+	TokenError         = wire.TokenError
+	MultiPriceResponse = wire.MultiPriceResponse
+)
+
+// Reasons a token in a batch has no fresh price
+const (
+	CodeNotFound      = wire.CodeNotFound      // no provider knows the token
+	CodeUpstreamError = wire.CodeUpstreamError // the fetch failed and nothing is cached
+	CodeStaleOnly     = wire.CodeStaleOnly     // no fresh price; prices has the expired cached one
+	CodeTooOld        = wire.CodeTooOld        // the price is older than the token's or request's max age
+)
+
+// fail records why tokenID has no fresh price
+func fail(r *MultiPriceResponse, tokenID, code, message string) {
+	if r.Errors == nil {
+		r.Errors = make(map[string]*TokenError)
+	}
+	r.Errors[tokenID] = &TokenError{Code: code, Message: message}
+}
+
+// NewPriceCache creates a new price cache backed by provider
+func NewPriceCache(provider providers.Provider) *PriceCache {
+	pc := &PriceCache{
+		prices:   newPriceStore(),
+		provider: provider,
+	}
+	pc.ttl.Store(int64(DefaultTTL))
+	pc.SetMaxChange(DefaultMaxChange)
+	pc.SetNotFoundTTL(DefaultNotFoundTTL)
+	pc.SetStaleAfter(DefaultStaleAfter)
+	return pc
+}
+
+// OnRefresh registers fn to be called after every upstream fetch. It must
+// be called before the cache is used.
+func (pc *PriceCache) OnRefresh(fn RefreshFunc) {
+	pc.onRefresh = append(pc.onRefresh, fn)
+}
+
+// refreshed runs the refresh hooks
+func (pc *PriceCache) refreshed(currency string, prices []*PriceResponse) {
+	if len(prices) == 0 {
+		return
+	}
+	for _, fn := range pc.onRefresh {
+		fn(currency, prices)
+	}
+}
+
+// SetTTL sets the default TTL for lookups without a context override. It
+// is safe to call while the cache is in use.
+func (pc *PriceCache) SetTTL(ttl time.Duration) {
+	if ttl > 0 {
+		pc.ttl.Store(int64(ttl))
+	}
+}
+
+// TTL returns the default TTL
+func (pc *PriceCache) TTL() time.Duration {
+	return time.Duration(pc.ttl.Load())
+}
+
+// Stats returns lookup counters since startup and the number of cached
+// prices
+func (pc *PriceCache) Stats() Stats {
+	stats := Stats{Hits: pc.hits.Load(), Misses: pc.misses.Load(), Applied: pc.applied.Load()}
+	pc.prices.each(func(string, *CachedPrice) { stats.Entries++ })
+	return stats
+}
+
+type ttlKey struct{}
+
+// WithTTL overrides the cache TTL for lookups made with ctx
+func WithTTL(ctx context.Context, ttl time.Duration) context.Context {
+	return context.WithValue(ctx, ttlKey{}, ttl)
+}
+
+// TTLFromContext returns the cache TTL set on ctx, or fallback if none
+func TTLFromContext(ctx context.Context, fallback time.Duration) time.Duration {
+	if ttl, ok := ctx.Value(ttlKey{}).(time.Duration); ok && ttl > 0 {
+		return ttl
+	}
+	return fallback
+}
+
+// GetPrice returns the price for a token, fetching if cache expired
+func (pc *PriceCache) GetPrice(ctx context.Context, tokenID, currency string) (*PriceResponse, error) {
+	cacheKey := fmt.Sprintf("%s:%s", tokenID, currency)
+	ttl := pc.ttlFor(tokenID, TTLFromContext(ctx, pc.TTL()))
+
+	// Check cache first
+	cached, exists := pc.prices.get(cacheKey)
+
+	// A quarantined price keeps the old one served until it is rechecked
+	if exists && (time.Since(cached.UpdatedAt) < ttl || pc.held(cacheKey)) {
+		pc.hits.Add(1)
+		return &PriceResponse{
+			ID:        tokenID,
+			Symbol:    cached.Symbol,
+			Name:      cached.Name,
+			Price:     cached.Price,
+			PriceStr:  cached.PriceStr,
+			Source:    cached.Source,
+			Currency:  cached.Currency,
+			Change24h: cached.Change24h,
+			MarketCap: cached.MarketCap,
+			Volume24h: cached.Volume24h,
+			UpdatedAt: cached.UpdatedAt,
+			Cached:    true,
+		}, nil
+	}
+
+	// Tokens recently not found are not asked for again until they expire
+	if !exists && pc.unknown(cacheKey) {
+		pc.hits.Add(1)
+		return nil, &providers.NotFoundError{Token: tokenID}
+	}
+
+	// Fetch from CoinGecko
+	pc.misses.Add(1)
+	price, err := pc.provider.FetchPrice(ctx, tokenID, currency)
+	if err != nil {
+		pc.fetched(ctx, 0, err)
+		// Return stale cache if available
+		if exists {
+			return &PriceResponse{
+				ID:        tokenID,
+				Symbol:    cached.Symbol,
+				Name:      cached.Name,
+				Price:     cached.Price,
+				PriceStr:  cached.PriceStr,
+				Source:    cached.Source,
+				Currency:  cached.Currency,
+				Change24h: cached.Change24h,
+				MarketCap: cached.MarketCap,
+				Volume24h: cached.Volume24h,
+				UpdatedAt: cached.UpdatedAt,
+				Cached:    true,
+			}, nil
+		}
+		if errors.Is(err, providers.ErrTokenNotFound) {
+			pc.markUnknown(cacheKey)
+		}
+		return nil, err
+	}
+
+	pc.fetched(ctx, 1, nil)
+	now := time.Now()
+	if !pc.screen(cacheKey, tokenID, currency, cached, price.CurrentPrice, now) {
+		return &PriceResponse{
+			ID:        tokenID,
+			Symbol:    price.Symbol,
+			Name:      price.Name,
+			Price:     cached.Price,
+			PriceStr:  cached.PriceStr,
+			Source:    cached.Source,
+			Currency:  cached.Currency,
+			Change24h: cached.Change24h,
+			MarketCap: cached.MarketCap,
+			Volume24h: cached.Volume24h,
+			UpdatedAt: cached.UpdatedAt,
+			Cached:    true,
+		}, nil
+	}
+
+	// Update cache
+	pc.prices.set(cacheKey, &CachedPrice{
+		Symbol:    price.Symbol,
+		Name:      price.Name,
+		Price:     price.CurrentPrice,
+		PriceStr:  price.Exact().String(),
+		Source:    price.Source,
+		Currency:  currency,
+		UpdatedAt: now,
+		Change24h: price.PriceChangePercentage24h,
+		MarketCap: price.MarketCap,
+		Volume24h: price.TotalVolume,
+	})
+
+	resp := &PriceResponse{
+		ID:        tokenID,
+		Symbol:    price.Symbol,
+		Name:      price.Name,
+		Price:     price.CurrentPrice,
+		PriceStr:  price.Exact().String(),
+		Source:    price.Source,
+		Currency:  currency,
+		Change24h: price.PriceChangePercentage24h,
+		MarketCap: price.MarketCap,
+		Volume24h: price.TotalVolume,
+		UpdatedAt: now,
+		Cached:    false,
+	}
+	pc.refreshed(currency, []*PriceResponse{resp})
+	return resp, nil
+}
+
+// Flush removes cached prices for a token, or all prices if tokenID is
+// empty. Quarantined prices and not-found results for the token are
+// dropped too, so the next fetch is accepted whatever it returns.
+func (pc *PriceCache) Flush(tokenID string) int {
+	prefix := ""
+	if tokenID != "" {
+		prefix = tokenID + ":"
+	}
+	pc.quarantine.release(prefix)
+	pc.notFound.release(prefix)
+	return pc.prices.deletePrefix(prefix)
+}
+
+// Cached returns every unexpired cached price in currency without
+// fetching, in no particular order
+func (pc *PriceCache) Cached(currency string) []*PriceResponse {
+	ttl := pc.TTL()
+	suffix := ":" + currency
+	var prices []*PriceResponse
+	pc.prices.each(func(key string, p *CachedPrice) {
+		if !strings.HasSuffix(key, suffix) || time.Since(p.UpdatedAt) >= ttl {
+			return
+		}
+		prices = append(prices, &PriceResponse{
+			ID:        strings.TrimSuffix(key, suffix),
+			Symbol:    p.Symbol,
+			Name:      p.Name,
+			Price:     p.Price,
+			PriceStr:  p.PriceStr,
+			Source:    p.Source,
+			Currency:  p.Currency,
+			Change24h: p.Change24h,
+			MarketCap: p.MarketCap,
+			Volume24h: p.Volume24h,
+			UpdatedAt: p.UpdatedAt,
+			Cached:    true,
+		})
+	})
+	return prices
+}
+
+// GetMultiplePrices fetches prices for multiple tokens. If the upstream
+// fetch fails, the prices served from cache, including stale ones for the
+// tokens that failed, are returned along with the error. Tokens recently
+// not found are not asked for again. Each token without a fresh price is
+// explained in the response's Errors.
+func (pc *PriceCache) GetMultiplePrices(ctx context.Context, tokenIDs []string, currency string) (*MultiPriceResponse, error) {
+	response := &MultiPriceResponse{
+		Prices:    make(map[string]*PriceResponse),
+		UpdatedAt: time.Now(),
+	}
+
+	ttl := TTLFromContext(ctx, pc.TTL())
+
+	// Check which tokens need fetching, remembering expired prices to fall
+	// back on
+	var toFetch []string
+	stale := make(map[string]*CachedPrice)
+	for _, id := range tokenIDs {
+		cacheKey := fmt.Sprintf("%s:%s", id, currency)
+
+		cached, exists := pc.prices.get(cacheKey)
+
+		if exists && (time.Since(cached.UpdatedAt) < pc.ttlFor(id, ttl) || pc.held(cacheKey)) {
+			pc.hits.Add(1)
+			response.Prices[id] = &PriceResponse{
+				ID:        id,
+				Symbol:    cached.Symbol,
+				Name:      cached.Name,
+				Price:     cached.Price,
+				PriceStr:  cached.PriceStr,
+				Source:    cached.Source,
+				Currency:  cached.Currency,
+				Change24h: cached.Change24h,
+				MarketCap: cached.MarketCap,
+				Volume24h: cached.Volume24h,
+				UpdatedAt: cached.UpdatedAt,
+				Cached:    true,
+			}
+		} else if !exists && pc.unknown(cacheKey) {
+			pc.hits.Add(1)
+			fail(response, id, CodeNotFound, "token not found")
+		} else {
+			pc.misses.Add(1)
+			toFetch = append(toFetch, id)
+			if exists {
+				stale[id] = cached
+			}
+		}
+	}
+
+	// Fetch missing prices in batch
+	if len(toFetch) > 0 {
+		prices, fetchErr := pc.provider.FetchPrices(ctx, toFetch, currency)
+		if fetchErr == nil {
+			// Tokens missing from an abandoned fetch are not known unknown
+			fetchErr = ctx.Err()
+		}
+		pc.fetched(ctx, len(prices), fetchErr)
+
+		// Chunks that succeeded are cached even if others failed
+		now := time.Now()
+		fetched := make([]*PriceResponse, 0, len(prices))
+		for _, p := range prices {
+			cacheKey := fmt.Sprintf("%s:%s", p.ID, currency)
+
+			// Quarantined prices are answered with the cached one
+			cached, _ := pc.prices.get(cacheKey)
+			if !pc.screen(cacheKey, p.ID, currency, cached, p.CurrentPrice, now) {
+				response.Prices[p.ID] = &PriceResponse{
+					ID:        p.ID,
+					Symbol:    p.Symbol,
+					Name:      p.Name,
+					Price:     cached.Price,
+					PriceStr:  cached.PriceStr,
+					Source:    cached.Source,
+					Currency:  cached.Currency,
+					Change24h: cached.Change24h,
+					MarketCap: cached.MarketCap,
+					Volume24h: cached.Volume24h,
+					UpdatedAt: cached.UpdatedAt,
+					Cached:    true,
+				}
+				continue
+			}
+
+			pc.prices.set(cacheKey, &CachedPrice{
+				Symbol:    p.Symbol,
+				Name:      p.Name,
+				Price:     p.CurrentPrice,
+				PriceStr:  p.Exact().String(),
+				Source:    p.Source,
+				Currency:  currency,
+				UpdatedAt: now,
+				Change24h: p.PriceChangePercentage24h,
+				MarketCap: p.MarketCap,
+				Volume24h: p.TotalVolume,
+			})
+
+			resp := &PriceResponse{
+				ID:        p.ID,
+				Symbol:    p.Symbol,
+				Name:      p.Name,
+				Price:     p.CurrentPrice,
+				PriceStr:  p.Exact().String(),
+				Source:    p.Source,
+				Currency:  currency,
+				Change24h: p.PriceChangePercentage24h,
+				MarketCap: p.MarketCap,
+				Volume24h: p.TotalVolume,
+				UpdatedAt: now,
+				Cached:    false,
+			}
+			response.Prices[p.ID] = resp
+			fetched = append(fetched, resp)
+		}
+		pc.refreshed(currency, fetched)
+
+		// Tokens left without a price keep an expired one if cached. A
+		// complete fetch omits only tokens the provider doesn't know.
+		for _, id := range toFetch {
+			if _, ok := response.Prices[id]; ok {
+				continue
+			}
+			cached := stale[id]
+			switch {
+			case cached != nil:
+				reason := "not returned by upstream"
+				if fetchErr != nil {
+					reason = "upstream fetch failed"
+				}
+				fail(response, id, CodeStaleOnly, reason+"; serving the price cached at "+cached.UpdatedAt.UTC().Format(time.RFC3339))
+				response.Prices[id] = &PriceResponse{
+					ID:        id,
+					Symbol:    cached.Symbol,
+					Name:      cached.Name,
+					Price:     cached.Price,
+					PriceStr:  cached.PriceStr,
+					Source:    cached.Source,
+					Currency:  cached.Currency,
+					Change24h: cached.Change24h,
+					MarketCap: cached.MarketCap,
+					Volume24h: cached.Volume24h,
+					UpdatedAt: cached.UpdatedAt,
+					Cached:    true,
+				}
+			case fetchErr != nil:
+				fail(response, id, CodeUpstreamError, "upstream fetch failed")
+			default:
+				pc.markUnknown(fmt.Sprintf("%s:%s", id, currency))
+				fail(response, id, CodeNotFound, "token not found")
+			}
+		}
+		if fetchErr != nil {
+			return response, fmt.Errorf("fetching %d %s prices: %w", len(toFetch), currency, fetchErr)
+		}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/internal/testutil"
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+var errUpstream = errors.New("upstream down")
+
+// expired is a context whose lookups treat every cached price as expired
+func expired() context.Context {
+	return cache.WithTTL(context.Background(), time.Nanosecond)
+}
+
+func TestGetPrice(t *testing.T) {
+	tests := []struct {
+		name   string
+		known  bool  // the provider quotes the token
+		warm   bool  // the price is cached first
+		expire bool  // the cached price has expired
+		fail   error // the lookup's fetch fails with fail
+
+		wantPrice  float64
+		wantCached bool
+		wantErr    error
+		wantCalls  int
+	}{
+		{name: "fetched", known: true, wantPrice: 65000, wantCalls: 1},
+		{name: "cached", known: true, warm: true, wantPrice: 65000, wantCached: true, wantCalls: 1},
+		{name: "expired refetched", known: true, warm: true, expire: true, wantPrice: 65000, wantCalls: 2},
+		{name: "stale on upstream error", known: true, warm: true, expire: true, fail: errUpstream, wantPrice: 65000, wantCached: true, wantCalls: 2},
+		{name: "upstream error uncached", known: true, fail: errUpstream, wantErr: errUpstream, wantCalls: 1},
+		{name: "not found", wantErr: providers.ErrTokenNotFound, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testutil.NewProvider("fake")
+			if tt.known {
+				p.Set("bitcoin", 65000)
+			}
+			pc := cache.NewPriceCache(p)
+			if tt.warm {
+				if _, err := pc.GetPrice(context.Background(), "bitcoin", "usd"); err != nil {
+					t.Fatalf("warming: %v", err)
+				}
+			}
+			ctx := context.Background()
+			if tt.expire {
+				ctx = expired()
+			}
+			if tt.fail != nil {
+				p.FailNext(tt.fail)
+			}
+
+			price, err := pc.GetPrice(ctx, "bitcoin", "usd")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (price.Price != tt.wantPrice || price.Cached != tt.wantCached) {
+				t.Errorf("price = %v cached %v, want %v cached %v", price.Price, price.Cached, tt.wantPrice, tt.wantCached)
+			}
+			if n := p.Calls(); n != tt.wantCalls {
+				t.Errorf("provider called %d times, want %d", n, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestGetPriceNotFoundRemembered(t *testing.T) {
+	p := testutil.NewProvider("fake")
+	pc := cache.NewPriceCache(p)
+	for i := 0; i < 2; i++ {
+		if _, err := pc.GetPrice(context.Background(), "nope", "usd"); !errors.Is(err, providers.ErrTokenNotFound) {
+			t.Fatalf("lookup %d: err = %v, want not found", i, err)
+		}
+	}
+	if n := p.Calls(); n != 1 {
+		t.Errorf("provider called %d times, want 1", n)
+	}
+}
+
+func TestGetMultiplePrices(t *testing.T) {
+	tests := []struct {
+		name   string
+		warm   bool   // both prices are cached first
+		expire bool   // the cached prices have expired
+		remove string // token the provider forgets after warming
+		fail   error  // the batch fetch fails with fail
+
+		wantPrices []string          // tokens priced, fresh or stale
+		wantCodes  map[string]string // error code per token
+		wantErr    bool
+	}{
+		{
+			name:       "fetched",
+			wantPrices: []string{"bitcoin", "ethereum"},
+		},
+		{
+			name:       "cached",
+			warm:       true,
+			wantPrices: []string{"bitcoin", "ethereum"},
+		},
+		{
+			name:       "unknown token",
+			remove:     "ethereum",
+			wantPrices: []string{"bitcoin"},
+			wantCodes:  map[string]string{"ethereum": cache.CodeNotFound},
+		},
+		{
+			name:      "upstream error uncached",
+			fail:      errUpstream,
+			wantCodes: map[string]string{"bitcoin": cache.CodeUpstreamError, "ethereum": cache.CodeUpstreamError},
+			wantErr:   true,
+		},
+		{
+			name:       "stale on upstream error",
+			warm:       true,
+			expire:     true,
+			fail:       errUpstream,
+			wantPrices: []string{"bitcoin", "ethereum"},
+			wantCodes:  map[string]string{"bitcoin": cache.CodeStaleOnly, "ethereum": cache.CodeStaleOnly},
+			wantErr:    true,
+		},
+		{
+			name:       "stale when not returned",
+			warm:       true,
+			expire:     true,
+			remove:     "ethereum",
+			wantPrices: []string{"bitcoin", "ethereum"},
+			wantCodes:  map[string]string{"ethereum": cache.CodeStaleOnly},
+		},
+	}
+	ids := []string{"bitcoin", "ethereum"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testutil.NewProvider("fake")
+			p.Set("bitcoin", 65000)
+			p.Set("ethereum", 3200)
+			pc := cache.NewPriceCache(p)
+			if tt.warm {
+				if _, err := pc.GetMultiplePrices(context.Background(), ids, "usd"); err != nil {
+					t.Fatalf("warming: %v", err)
+				}
+			}
+			if tt.remove != "" {
+				p.Remove(tt.remove)
+			}
+			ctx := context.Background()
+			if tt.expire {
+				ctx = expired()
+			}
+			if tt.fail != nil {
+				p.FailNext(tt.fail)
+			}
+
+			resp, err := pc.GetMultiplePrices(ctx, ids, "usd")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if len(resp.Prices) != len(tt.wantPrices) {
+				t.Errorf("priced %d tokens, want %v", len(resp.Prices), tt.wantPrices)
+			}
+			for _, id := range tt.wantPrices {
+				if resp.Prices[id] == nil {
+					t.Errorf("%s not priced", id)
+				}
+			}
+			if len(resp.Errors) != len(tt.wantCodes) {
+				t.Errorf("errors = %v, want codes %v", resp.Errors, tt.wantCodes)
+			}
+			for id, code := range tt.wantCodes {
+				if e := resp.Errors[id]; e == nil || e.Code != code {
+					t.Errorf("%s error = %+v, want code %s", id, e, code)
+				}
+			}
+		})
+	}
+}
+
+func TestGetPriceSlowUpstream(t *testing.T) {
+	p := testutil.NewProvider("fake")
+	p.Set("bitcoin", 65000)
+	pc := cache.NewPriceCache(p)
+	if _, err := pc.GetPrice(context.Background(), "bitcoin", "usd"); err != nil {
+		t.Fatalf("warming: %v", err)
+	}
+	p.SetDelay(time.Second)
+
+	// The caller's deadline bounds the refetch, and the stale price is served
+	ctx, cancel := context.WithTimeout(expired(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	price, err := pc.GetPrice(ctx, "bitcoin", "usd")
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("lookup took %s past a 50ms deadline", elapsed)
+	}
+	if err != nil {
+		t.Fatalf("err = %v, want the stale price", err)
+	}
+	if price.Price != 65000 || !price.Cached {
+		t.Errorf("price = %v cached %v, want stale 65000", price.Price, price.Cached)
+	}
+
+	// Without a cached price the deadline surfaces as the error
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	p.Set("ethereum", 3200)
+	if _, err := pc.GetPrice(ctx, "ethereum", "usd"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("uncached err = %v, want deadline exceeded", err)
+	}
+}
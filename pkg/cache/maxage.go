@@ -0,0 +1,39 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"strings"
+	"time"
+)
+
+// SetMaxAges sets per-token freshness SLAs: a token's cached price is
+// refetched once it is older than its max age, even within the TTL. It is
+// safe to call while the cache is in use.
+func (pc *PriceCache) SetMaxAges(ages map[string]time.Duration) {
+	m := make(map[string]time.Duration, len(ages))
+	for id, age := range ages {
+		if age > 0 {
+			m[strings.ToLower(id)] = age
+		}
+	}
+	pc.maxAges.Store(&m)
+}
+
+// MaxAge returns the oldest price of a token that may be served, or 0 if
+// the token has no freshness SLA
+func (pc *PriceCache) MaxAge(tokenID string) time.Duration {
+	if m := pc.maxAges.Load(); m != nil {
+		return (*m)[strings.ToLower(tokenID)]
+	}
+	return 0
+}
+
+// ttlFor returns ttl, or the token's max age if that is shorter
+func (pc *PriceCache) ttlFor(tokenID string, ttl time.Duration) time.Duration {
+	if age := pc.MaxAge(tokenID); age > 0 && age < ttl {
+		return age
+	}
+	return ttl
+}
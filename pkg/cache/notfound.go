@@ -0,0 +1,94 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultNotFoundTTL is how long a token the provider doesn't know is
+	// answered as not found without asking it again
+	DefaultNotFoundTTL = 5 * time.Minute
+
+	// MaxNotFound bounds how many unknown tokens are remembered, so
+	// scanners trying random ids cannot grow the cache without limit
+	MaxNotFound = 10000
+)
+
+// notFound remembers unknown tokens by cache key until they expire
+type notFound struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+// SetNotFoundTTL sets how long unknown tokens are remembered; 0 disables
+// negative caching. It is safe to call while the cache is in use.
+func (pc *PriceCache) SetNotFoundTTL(ttl time.Duration) {
+	if ttl >= 0 {
+		pc.notFoundTTL.Store(int64(ttl))
+	}
+}
+
+// NotFoundTTL returns how long unknown tokens are remembered
+func (pc *PriceCache) NotFoundTTL() time.Duration {
+	return time.Duration(pc.notFoundTTL.Load())
+}
+
+// unknown reports whether the token under key was recently not found
+func (pc *PriceCache) unknown(key string) bool {
+	pc.notFound.mu.Lock()
+	defer pc.notFound.mu.Unlock()
+	expires, ok := pc.notFound.entries[key]
+	if !ok {
+		return false
+	}
+	if !time.Now().Before(expires) {
+		delete(pc.notFound.entries, key)
+		return false
+	}
+	return true
+}
+
+// markUnknown remembers that the token under key was not found. When the
+// table is full, expired entries are dropped; if none have expired the
+// token is not remembered.
+func (pc *PriceCache) markUnknown(key string) {
+	ttl := pc.NotFoundTTL()
+	if ttl <= 0 {
+		return
+	}
+	now := time.Now()
+
+	pc.notFound.mu.Lock()
+	defer pc.notFound.mu.Unlock()
+	if pc.notFound.entries == nil {
+		pc.notFound.entries = make(map[string]time.Time)
+	}
+	if _, ok := pc.notFound.entries[key]; !ok && len(pc.notFound.entries) >= MaxNotFound {
+		for k, expires := range pc.notFound.entries {
+			if !now.Before(expires) {
+				delete(pc.notFound.entries, k)
+			}
+		}
+		if len(pc.notFound.entries) >= MaxNotFound {
+			return
+		}
+	}
+	pc.notFound.entries[key] = now.Add(ttl)
+}
+
+// release forgets unknown tokens whose key has the given prefix, or all
+// of them if prefix is empty
+func (n *notFound) release(prefix string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for key := range n.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(n.entries, key)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxChange is the largest move accepted from a single refresh,
+	// as a fraction of the cached price
+	DefaultMaxChange = 0.5
+
+	// RecheckInterval is how long a quarantined price is held before it is
+	// fetched again for confirmation
+	RecheckInterval = time.Minute
+)
+
+// Quarantined is a fetched price held back because it moved further from
+// the cached price than the sanity bound. The cached price is served until
+// a later fetch confirms the move or the price comes back within bounds.
+type Quarantined struct {
+	Token     string    `json:"token"`
+	Currency  string    `json:"currency"`
+	Price     float64   `json:"price"`    // quarantined price
+	Previous  float64   `json:"previous"` // price still being served
+	Change    float64   `json:"change"`   // fraction, -0.9 is a 90% drop
+	Fetches   int       `json:"fetches"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// quarantine holds suspicious prices by cache key
+type quarantine struct {
+	mu      sync.Mutex
+	entries map[string]*Quarantined
+}
+
+// SetMaxChange sets the sanity bound on how far one refresh may move a
+// cached price (0.5 rejects moves beyond ±50%); 0 disables the check. It is
+// safe to call while the cache is in use.
+func (pc *PriceCache) SetMaxChange(max float64) {
+	if max >= 0 {
+		pc.maxChange.Store(math.Float64bits(max))
+	}
+}
+
+// MaxChange returns the sanity bound, or 0 if disabled
+func (pc *PriceCache) MaxChange() float64 {
+	return math.Float64frombits(pc.maxChange.Load())
+}
+
+// Quarantined returns the prices currently held back, oldest first
+func (pc *PriceCache) Quarantined() []Quarantined {
+	pc.quarantine.mu.Lock()
+	defer pc.quarantine.mu.Unlock()
+	out := make([]Quarantined, 0, len(pc.quarantine.entries))
+	for _, q := range pc.quarantine.entries {
+		out = append(out, *q)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].FirstSeen.Equal(out[j].FirstSeen) {
+			return out[i].FirstSeen.Before(out[j].FirstSeen)
+		}
+		return out[i].Token+":"+out[i].Currency < out[j].Token+":"+out[j].Currency
+	})
+	return out
+}
+
+// held reports whether the expired price under key is quarantined and was
+// checked too recently to fetch again
+func (pc *PriceCache) held(key string) bool {
+	pc.quarantine.mu.Lock()
+	defer pc.quarantine.mu.Unlock()
+	q, ok := pc.quarantine.entries[key]
+	return ok && time.Since(q.LastSeen) < RecheckInterval
+}
+
+// screen reports whether a fetched price may replace the cached one. A
+// price that moved beyond the sanity bound is quarantined instead, and
+// accepted once a fetch at least RecheckInterval later agrees with it.
+func (pc *PriceCache) screen(key, tokenID, currency string, cached *CachedPrice, price float64, now time.Time) bool {
+	max := pc.MaxChange()
+
+	pc.quarantine.mu.Lock()
+	defer pc.quarantine.mu.Unlock()
+	q, pending := pc.quarantine.entries[key]
+
+	if max <= 0 || cached == nil || cached.Price <= 0 {
+		delete(pc.quarantine.entries, key)
+		return true
+	}
+	change := price/cached.Price - 1
+	if math.Abs(change) <= max {
+		delete(pc.quarantine.entries, key)
+		return true
+	}
+	if pending && now.Sub(q.FirstSeen) >= RecheckInterval && q.Price > 0 && math.Abs(price/q.Price-1) <= max {
+		delete(pc.quarantine.entries, key)
+		log.Printf("Accepted %s %s move of %+.1f%% after %d fetches", tokenID, currency, change*100, q.Fetches+1)
+		return true
+	}
+
+	if pending && q.Price > 0 && math.Abs(price/q.Price-1) <= max {
+		q.Fetches++
+		q.LastSeen = now
+		return false
+	}
+	if pc.quarantine.entries == nil {
+		pc.quarantine.entries = make(map[string]*Quarantined)
+	}
+	pc.quarantine.entries[key] = &Quarantined{
+		Token:     tokenID,
+		Currency:  currency,
+		Price:     price,
+		Previous:  cached.Price,
+		Change:    change,
+		Fetches:   1,
+		FirstSeen: now,
+		LastSeen:  now,
+	}
+	log.Printf("Quarantined %s %s price %g: %+.1f%% from cached %g", tokenID, currency, price, change*100, cached.Price)
+	return false
+}
+
+// release drops quarantined prices whose key has the given prefix, or all
+// of them if prefix is empty
+func (q *quarantine) release(prefix string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for key := range q.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(q.entries, key)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import "fmt"
+
+// Apply stores prices refreshed by another replica, last write wins: a
+// price replaces the cached one only if it was fetched later, so replicas
+// applying the same refreshes in any order end up with the same prices.
+// Ties are broken on the source and price, never on arrival. Refresh hooks
+// are not run; Apply returns the prices it stored for the caller to pass
+// on.
+func (pc *PriceCache) Apply(currency string, prices []*PriceResponse) []*PriceResponse {
+	applied := make([]*PriceResponse, 0, len(prices))
+	for _, p := range prices {
+		if p == nil || p.ID == "" || p.UpdatedAt.IsZero() {
+			continue
+		}
+		cached := &CachedPrice{
+			Symbol:    p.Symbol,
+			Name:      p.Name,
+			Price:     p.Price,
+			PriceStr:  p.PriceStr,
+			Source:    p.Source,
+			Currency:  currency,
+			UpdatedAt: p.UpdatedAt,
+			Change24h: p.Change24h,
+			MarketCap: p.MarketCap,
+			Volume24h: p.Volume24h,
+		}
+		if pc.prices.setIf(fmt.Sprintf("%s:%s", p.ID, currency), cached, newer) {
+			applied = append(applied, p)
+		}
+	}
+	pc.applied.Add(int64(len(applied)))
+	return applied
+}
+
+// newer reports whether p should replace old under last-write-wins
+func newer(old, p *CachedPrice) bool {
+	if !p.UpdatedAt.Equal(old.UpdatedAt) {
+		return p.UpdatedAt.After(old.UpdatedAt)
+	}
+	if p.Source != old.Source {
+		return p.Source > old.Source
+	}
+	return p.PriceStr > old.PriceStr
+}
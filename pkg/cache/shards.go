@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"hash/fnv"
+	"strings"
+	"sync"
+)
+
+// priceShardCount must be a power of two
+const priceShardCount = 32
+
+// priceStore is a map of cached prices split into independently locked
+// shards, so concurrent lookups for different keys rarely contend
+type priceStore struct {
+	shards [priceShardCount]priceShard
+}
+
+type priceShard struct {
+	mu     sync.RWMutex
+	prices map[string]*CachedPrice
+}
+
+func newPriceStore() *priceStore {
+	s := &priceStore{}
+	for i := range s.shards {
+		s.shards[i].prices = make(map[string]*CachedPrice)
+	}
+	return s
+}
+
+func (s *priceStore) shard(key string) *priceShard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return &s.shards[h.Sum32()&(priceShardCount-1)]
+}
+
+// get returns the cached price for key
+func (s *priceStore) get(key string) (*CachedPrice, bool) {
+	sh := s.shard(key)
+	sh.mu.RLock()
+	p, ok := sh.prices[key]
+	sh.mu.RUnlock()
+	return p, ok
+}
+
+// set stores the cached price for key
+func (s *priceStore) set(key string, p *CachedPrice) {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	sh.prices[key] = p
+	sh.mu.Unlock()
+}
+
+// deletePrefix removes all keys with the given prefix, or every key if
+// prefix is empty, returning the number removed
+func (s *priceStore) deletePrefix(prefix string) int {
+	n := 0
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		if prefix == "" {
+			n += len(sh.prices)
+			sh.prices = make(map[string]*CachedPrice)
+		} else {
+			for key := range sh.prices {
+				if strings.HasPrefix(key, prefix) {
+					delete(sh.prices, key)
+					n++
+				}
+			}
+		}
+		sh.mu.Unlock()
+	}
+	return n
+}
+
+// each calls fn for every cached price; fn must not modify the store
+func (s *priceStore) each(fn func(key string, p *CachedPrice)) {
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.RLock()
+		for key, p := range sh.prices {
+			fn(key, p)
+		}
+		sh.mu.RUnlock()
+	}
+}
+
+// setIf stores the cached price for key if keep reports it should replace
+// the current one, or there is none, and reports whether it did
+func (s *priceStore) setIf(key string, p *CachedPrice, keep func(old, new *CachedPrice) bool) bool {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if old, ok := sh.prices[key]; ok && !keep(old, p) {
+		return false
+	}
+	sh.prices[key] = p
+	return true
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestPriceStore(t *testing.T) {
+	s := newPriceStore()
+	for _, key := range []string{"bitcoin:usd", "bitcoin:eur", "bitcoin-cash:usd", "ethereum:usd"} {
+		s.set(key, &CachedPrice{Price: 1, Currency: key})
+	}
+
+	if p, ok := s.get("bitcoin:eur"); !ok || p.Currency != "bitcoin:eur" {
+		t.Fatalf("get(bitcoin:eur) = %+v, %v", p, ok)
+	}
+	if _, ok := s.get("solana:usd"); ok {
+		t.Fatal("get(solana:usd) found a price never set")
+	}
+
+	// A token's prefix doesn't reach tokens whose id it starts
+	if n := s.deletePrefix("bitcoin:"); n != 2 {
+		t.Errorf("deletePrefix(bitcoin:) = %d, want 2", n)
+	}
+	for key, want := range map[string]bool{"bitcoin:usd": false, "bitcoin:eur": false, "bitcoin-cash:usd": true, "ethereum:usd": true} {
+		if _, ok := s.get(key); ok != want {
+			t.Errorf("after deleting bitcoin, get(%s) found = %v, want %v", key, ok, want)
+		}
+	}
+	if n := s.deletePrefix(""); n != 2 {
+		t.Errorf("deletePrefix(\"\") = %d, want 2", n)
+	}
+}
+
+// lockedStore is the single-mutex map priceStore replaced, kept as the
+// baseline BenchmarkPriceStore compares against
+type lockedStore struct {
+	mu     sync.RWMutex
+	prices map[string]*CachedPrice
+}
+
+func (s *lockedStore) get(key string) (*CachedPrice, bool) {
+	s.mu.RLock()
+	p, ok := s.prices[key]
+	s.mu.RUnlock()
+	return p, ok
+}
+
+func (s *lockedStore) set(key string, p *CachedPrice) {
+	s.mu.Lock()
+	s.prices[key] = p
+	s.mu.Unlock()
+}
+
+type benchStore interface {
+	get(key string) (*CachedPrice, bool)
+	set(key string, p *CachedPrice)
+}
+
+// BenchmarkPriceStore runs parallel gets and sets on the sharded store and
+// the single-mutex map: run with -cpu 1,8,32 to see contention grow
+func BenchmarkPriceStore(b *testing.B) {
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("token-%d:usd", i)
+	}
+	price := &CachedPrice{Price: 1, Currency: "usd"}
+
+	stores := []struct {
+		name string
+		new  func() benchStore
+	}{
+		{"sharded", func() benchStore { return newPriceStore() }},
+		{"single-mutex", func() benchStore { return &lockedStore{prices: make(map[string]*CachedPrice)} }},
+	}
+	workloads := []struct {
+		name     string
+		setEvery int // one operation in setEvery is a set, the rest gets
+	}{
+		{"get", 0},
+		{"set", 1},
+		{"mixed", 10},
+	}
+	for _, st := range stores {
+		for _, wl := range workloads {
+			b.Run(st.name+"/"+wl.name, func(b *testing.B) {
+				s := st.new()
+				for _, key := range keys {
+					s.set(key, price)
+				}
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					i := 0
+					for pb.Next() {
+						key := keys[i&(len(keys)-1)]
+						if wl.setEvery > 0 && i%wl.setEvery == 0 {
+							s.set(key, price)
+						} else {
+							s.get(key)
+						}
+						i++
+					}
+				})
+			})
+		}
+	}
+}
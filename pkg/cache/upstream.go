@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// DefaultStaleAfter is how long upstream refreshes may fail before the
+// prices served are reported stale, if no bound is configured
+const DefaultStaleAfter = 5 * time.Minute
+
+// UpstreamStatus is when prices were last refreshed from upstream and
+// whether refreshes have been failing since
+type UpstreamStatus = wire.UpstreamStatus
+
+// SetStaleAfter sets how long upstream refreshes may fail before the
+// prices served are reported stale. It is safe to call while the cache is
+// in use.
+func (pc *PriceCache) SetStaleAfter(d time.Duration) {
+	if d > 0 {
+		pc.staleAfter.Store(int64(d))
+	}
+}
+
+// Upstream returns when prices were last refreshed from upstream and
+// whether they are stale
+func (pc *PriceCache) Upstream() UpstreamStatus {
+	var status UpstreamStatus
+	last, failing := pc.lastRefresh.Load(), pc.failingSince.Load()
+	if last != 0 {
+		t := time.Unix(0, last).UTC()
+		status.LastRefresh = &t
+	}
+	if failing == 0 {
+		return status
+	}
+	t := time.Unix(0, failing).UTC()
+	status.FailingSince = &t
+
+	// Prices are as old as the last refresh, or the failures if none
+	// ever succeeded
+	if last == 0 {
+		last = failing
+	}
+	status.Stale = time.Since(time.Unix(0, last)) > time.Duration(pc.staleAfter.Load())
+	return status
+}
+
+// fetched records an upstream fetch: one that returned prices clears the
+// failures, and one that failed outright starts them. Tokens not found
+// and fetches abandoned by the caller are neither.
+func (pc *PriceCache) fetched(ctx context.Context, n int, err error) {
+	now := time.Now().UnixNano()
+	switch {
+	case n > 0:
+		pc.lastRefresh.Store(now)
+		pc.failingSince.Store(0)
+	case err == nil, ctx.Err() != nil, errors.Is(err, providers.ErrTokenNotFound):
+	default:
+		pc.failingSince.CompareAndSwap(0, now)
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package categories tags tokens with the categories a provider files them
+// under, such as DeFi, layer 1, meme or AI, adjusted by local overrides and
+// arranged in a hierarchy, for curated category pages.
+package categories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// Fetcher fetches the ids of the tokens in a provider category, e.g.
+// CoinGecko.FetchCategory
+type Fetcher func(ctx context.Context, slug string) ([]string, error)
+
+// Category is a category, its parent and how many tokens it holds
+type Category = wire.Category
+
+// List is every category, sorted by name
+type List = wire.CategoryList
+
+// Options configures a Service
+type Options struct {
+	// Sources maps category names to the provider categories they are
+	// fetched from, e.g. defi -> decentralized-finance-defi
+	Sources map[string]string
+
+	// Parents maps categories to their parent category. A token in a
+	// category is in all of its ancestors too.
+	Parents map[string]string
+
+	// Overrides maps token ids to categories added to the provider's,
+	// or removed from them if prefixed with "-"
+	Overrides map[string][]string
+}
+
+// Service knows the categories of tokens
+type Service struct {
+	fetch Fetcher
+	opts  Options
+
+	mu        sync.RWMutex
+	members   map[string][]string // category -> token ids, as last fetched
+	byToken   map[string][]string // token id -> categories it was fetched in
+	updatedAt time.Time
+}
+
+// NewService creates a service fetching categories with fetch. Until the
+// first Refresh tokens are in their override categories only.
+func NewService(opts Options, fetch Fetcher) *Service {
+	return &Service{fetch: fetch, opts: opts, members: make(map[string][]string)}
+}
+
+// Refresh fetches the tokens of every source category. A category that
+// fails to fetch keeps its previous tokens.
+func (s *Service) Refresh(ctx context.Context) error {
+	var errs []error
+	fetched := make(map[string][]string, len(s.opts.Sources))
+	for name, slug := range s.opts.Sources {
+		ids, err := s.fetch(ctx, slug)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		fetched[name] = ids
+	}
+
+	s.mu.Lock()
+	if len(fetched) > 0 {
+		for name, ids := range fetched {
+			s.members[name] = ids
+		}
+		s.byToken = make(map[string][]string)
+		for name, ids := range s.members {
+			for _, id := range ids {
+				s.byToken[id] = append(s.byToken[id], name)
+			}
+		}
+		s.updatedAt = time.Now().UTC()
+	}
+	s.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// Run refreshes the categories every interval until ctx is done
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Refresh(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("Refreshing token categories: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Of returns the categories of a token, sorted
+func (s *Service) Of(tokenID string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.of(tokenID, s.byToken)
+}
+
+// Known reports whether name is a category
+func (s *Service) Known(name string) bool {
+	if _, ok := s.opts.Sources[name]; ok {
+		return true
+	}
+	if _, ok := s.opts.Parents[name]; ok {
+		return true
+	}
+	for _, parent := range s.opts.Parents {
+		if parent == name {
+			return true
+		}
+	}
+	for _, tags := range s.opts.Overrides {
+		for _, tag := range tags {
+			if tag == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// List returns every category with its token count
+func (s *Service) List() List {
+	s.mu.RLock()
+	byToken, updatedAt := s.byToken, s.updatedAt
+	s.mu.RUnlock()
+
+	// Every configured category is listed, even if it holds no tokens
+	counts := make(map[string]int)
+	for name := range s.opts.Sources {
+		counts[name] = 0
+	}
+	for child, parent := range s.opts.Parents {
+		counts[child], counts[parent] = 0, 0
+	}
+	tokens := make(map[string]bool, len(byToken)+len(s.opts.Overrides))
+	for id := range byToken {
+		tokens[id] = true
+	}
+	for id, tags := range s.opts.Overrides {
+		tokens[id] = true
+		for _, tag := range tags {
+			if !strings.HasPrefix(tag, "-") {
+				counts[tag] = 0
+			}
+		}
+	}
+	for id := range tokens {
+		for _, name := range s.of(id, byToken) {
+			counts[name]++
+		}
+	}
+
+	list := List{Categories: make([]Category, 0, len(counts)), UpdatedAt: updatedAt}
+	for name, n := range counts {
+		list.Categories = append(list.Categories, Category{
+			Name:   name,
+			Parent: s.opts.Parents[name],
+			Source: s.opts.Sources[name],
+			Tokens: n,
+		})
+	}
+	sort.Slice(list.Categories, func(i, j int) bool { return list.Categories[i].Name < list.Categories[j].Name })
+	return list
+}
+
+// of returns a token's categories: its fetched ones plus those its
+// overrides add, with their ancestors, less those its overrides remove
+func (s *Service) of(tokenID string, byToken map[string][]string) []string {
+	set := make(map[string]bool)
+	removed := make(map[string]bool)
+	add := func(name string) {
+		// Parents are validated acyclic; the set also stops a cycle
+		for name != "" && !set[name] {
+			set[name] = true
+			name = s.opts.Parents[name]
+		}
+	}
+	for _, name := range byToken[tokenID] {
+		add(name)
+	}
+	for _, tag := range s.opts.Overrides[tokenID] {
+		if name, ok := strings.CutPrefix(tag, "-"); ok {
+			removed[name] = true
+		} else {
+			add(tag)
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(set))
+	for name := range set {
+		if !removed[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package changes journals changes of cached prices so polling clients
+// can fetch only the tokens whose price changed since their last poll.
+package changes
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+const (
+	// DefaultMaxPrices is the most token and currency pairs journaled if
+	// none is configured; the least recently changed are dropped first
+	DefaultMaxPrices = 50000
+
+	// DefaultLimit is the most changes returned at once if no limit is
+	// requested
+	DefaultLimit = 1000
+
+	// MaxLimit is the most changes returned at once
+	MaxLimit = 5000
+)
+
+// ErrInvalidCursor is returned for cursors this service never issued
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Changes are the prices of a currency changed since a cursor, oldest
+// change first
+type Changes = wire.PriceChanges
+
+// entry is a journaled price and the sequence number of its last change
+type entry struct {
+	key   string
+	seq   uint64
+	price cache.PriceResponse
+}
+
+// Journal records the latest price of each token and currency and the
+// order they changed in
+type Journal struct {
+	max   int
+	epoch string // tells cursors from before a restart apart
+
+	mu    sync.Mutex
+	seq   uint64                   // last sequence number issued
+	floor uint64                   // highest sequence number dropped
+	order *list.List               // of *entry, least recently changed first
+	byKey map[string]*list.Element // token:currency -> element of order
+}
+
+// NewJournal creates a journal of at most max prices, DefaultMaxPrices if
+// max is not positive
+func NewJournal(max int) *Journal {
+	if max <= 0 {
+		max = DefaultMaxPrices
+	}
+	return &Journal{
+		max:   max,
+		epoch: strconv.FormatInt(time.Now().UnixNano(), 36),
+		order: list.New(),
+		byKey: make(map[string]*list.Element),
+	}
+}
+
+// Record journals the prices that differ from the last ones seen. It is a
+// cache.RefreshFunc.
+func (j *Journal) Record(currency string, prices []*cache.PriceResponse) {
+	currency = strings.ToLower(currency)
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, p := range prices {
+		if p == nil {
+			continue
+		}
+		key := p.ID + ":" + currency
+		if el, ok := j.byKey[key]; ok {
+			e := el.Value.(*entry)
+			if e.price.Price == p.Price && e.price.PriceStr == p.PriceStr {
+				continue
+			}
+			j.seq++
+			e.seq, e.price = j.seq, journaled(p, currency)
+			j.order.MoveToBack(el)
+			continue
+		}
+
+		j.seq++
+		j.byKey[key] = j.order.PushBack(&entry{key: key, seq: j.seq, price: journaled(p, currency)})
+		for len(j.byKey) > j.max {
+			oldest := j.order.Front()
+			e := oldest.Value.(*entry)
+			j.order.Remove(oldest)
+			delete(j.byKey, e.key)
+			j.floor = e.seq
+		}
+	}
+}
+
+// journaled is the copy of p kept in the journal, without the parts that
+// depend on the request it was fetched for
+func journaled(p *cache.PriceResponse, currency string) cache.PriceResponse {
+	c := *p
+	c.Currency = currency
+	c.Cached = false
+	c.Signature = nil
+	c.Formatted = nil
+	c.RiskFlags = nil
+	return c
+}
+
+// Since returns the prices of currency changed after cursor, at most limit
+// of them (DefaultLimit if not positive, at most MaxLimit). Prices of
+// tokens allow rejects are skipped; allow may be nil. An empty cursor, or
+// one the journal can no longer answer, returns every journaled price with
+// Reset set.
+func (j *Journal) Since(cursor, currency string, limit int, allow func(tokenID string) bool) (*Changes, error) {
+	switch {
+	case limit <= 0:
+		limit = DefaultLimit
+	case limit > MaxLimit:
+		limit = MaxLimit
+	}
+	currency = strings.ToLower(currency)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var since uint64
+	reset := true
+	if cursor != "" {
+		epoch, seq, err := parseCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		if epoch == j.epoch && seq >= j.floor && seq <= j.seq {
+			since, reset = seq, false
+		}
+	}
+
+	// Walk back from the latest change to the first one after since, then
+	// return them oldest first
+	var pending []*entry
+	for el := j.order.Back(); el != nil; el = el.Prev() {
+		e := el.Value.(*entry)
+		if e.seq <= since {
+			break
+		}
+		pending = append(pending, e)
+	}
+
+	out := &Changes{Currency: currency, Reset: reset, Prices: []cache.PriceResponse{}}
+	last := j.seq
+	for i := len(pending) - 1; i >= 0; i-- {
+		e := pending[i]
+		if e.price.Currency != currency || allow != nil && !allow(e.price.ID) {
+			continue
+		}
+		if len(out.Prices) == limit {
+			// Resume after the last change returned
+			out.More = true
+			last = pending[i+1].seq
+			break
+		}
+		out.Prices = append(out.Prices, e.price)
+	}
+	out.Cursor = j.cursor(last)
+	return out, nil
+}
+
+// cursor encodes a sequence number as a cursor of this journal
+func (j *Journal) cursor(seq uint64) string {
+	return j.epoch + "-" + strconv.FormatUint(seq, 36)
+}
+
+// parseCursor splits a cursor into its epoch and sequence number
+func parseCursor(cursor string) (string, uint64, error) {
+	epoch, s, ok := strings.Cut(cursor, "-")
+	if !ok || epoch == "" {
+		return "", 0, ErrInvalidCursor
+	}
+	seq, err := strconv.ParseUint(s, 36, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %s", ErrInvalidCursor, cursor)
+	}
+	return epoch, seq, nil
+}
+
+// CheckCursor reports whether cursor is well-formed, without checking it
+// was issued by this journal
+func CheckCursor(cursor string) error {
+	_, _, err := parseCursor(cursor)
+	return err
+}
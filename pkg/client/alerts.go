@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// Alert types. Alerts are private to a tenant, so the client needs an API
+// key to use them.
+type (
+	Alert            = wire.Alert
+	AlertSpec        = wire.AlertSpec
+	AlertChannel     = wire.AlertChannel
+	AlertTestRequest = wire.AlertTestRequest
+	AlertBacktest    = wire.AlertBacktest
+	Delivery         = wire.Delivery
+)
+
+// Alerts returns the tenant's price alerts
+func (c *Client) Alerts(ctx context.Context) ([]Alert, error) {
+	var out struct {
+		Alerts []Alert `json:"alerts"`
+	}
+	return out.Alerts, c.get(ctx, "/v1/alerts", nil, &out)
+}
+
+// Alert returns a price alert
+func (c *Client) Alert(ctx context.Context, id string) (*Alert, error) {
+	var out Alert
+	return &out, c.get(ctx, "/v1/alerts/"+url.PathEscape(id), nil, &out)
+}
+
+// CreateAlert registers a price alert. It is never retried.
+func (c *Client) CreateAlert(ctx context.Context, spec AlertSpec) (*Alert, error) {
+	var out Alert
+	return &out, c.do(ctx, http.MethodPost, "/v1/alerts", nil, spec, &out, true)
+}
+
+// UpdateAlert replaces a price alert's condition and channel
+func (c *Client) UpdateAlert(ctx context.Context, id string, spec AlertSpec) (*Alert, error) {
+	var out Alert
+	return &out, c.do(ctx, http.MethodPut, "/v1/alerts/"+url.PathEscape(id), nil, spec, &out, false)
+}
+
+// DeleteAlert deletes a price alert
+func (c *Client) DeleteAlert(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/v1/alerts/"+url.PathEscape(id), nil, nil, nil, false)
+}
+
+// TestAlert backtests an alert condition against price history
+func (c *Client) TestAlert(ctx context.Context, req AlertTestRequest) (*AlertBacktest, error) {
+	var out AlertBacktest
+	return &out, c.do(ctx, http.MethodPost, "/v1/alerts/test", nil, req, &out, false)
+}
+
+// Deliveries returns an alert's recent deliveries and their attempts,
+// newest first
+func (c *Client) Deliveries(ctx context.Context, alertID string) ([]Delivery, error) {
+	var out struct {
+		Deliveries []Delivery `json:"deliveries"`
+	}
+	return out.Deliveries, c.get(ctx, "/v1/webhooks/"+url.PathEscape(alertID)+"/deliveries", nil, &out)
+}
@@ -0,0 +1,442 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package client is a Go client for the Lux pricing API. It has typed
+// methods for the JSON endpoints: prices, quotes, market data, history,
+// portfolio analytics, alerts, FX rates, signing keys, tenant usage and
+// the admin cache, audit and tenant endpoints. The CoinGecko-compatible
+// /coins routes, the Chainlink adapter, the TradingView UDF feed,
+// widgets, logos, streams and the other admin endpoints have none; Get
+// decodes any endpoint's JSON into a value of the caller's choosing.
+//
+//	c := client.New("https://fx.lux.network", apiKey)
+//	btc, err := c.Price(ctx, "bitcoin", "usd")
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// Response types are shared with the server through wire so they never
+// drift, without linking the server into clients
+type (
+	PriceResponse       = wire.PriceResponse
+	MultiPriceResponse  = wire.MultiPriceResponse
+	TenantUsageSnapshot = wire.TenantUsageSnapshot
+	StatusResponse      = wire.StatusResponse
+	HealthResponse      = wire.HealthResponse
+	SigningKey          = wire.SigningKey // Ed25519 response key or EIP-712 quote signer
+	AuditEntry          = wire.AuditEntry
+	AuditFilter         = wire.AuditFilter
+	FXRates             = wire.FXRates
+	QuoteResponse       = wire.QuoteResponse // EIP-712 price quote
+	BridgeRates         = wire.BridgeRates
+	OnrampComparison    = wire.OnrampComparison
+	PriceChanges        = wire.PriceChanges
+	ContractPrice       = wire.ContractPrice
+)
+
+// APIError is returned for non-2xx responses
+type APIError struct {
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration
+
+	// Code is "token_not_found" for unknown tokens, which come with
+	// Suggestions of similar ids
+	Code        string
+	Suggestions []string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("pricing API error: %d - %s", e.StatusCode, e.Message)
+}
+
+// Client calls the pricing API
+type Client struct {
+	baseURL    string
+	apiKey     string
+	adminKey   string
+	httpClient *http.Client
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the underlying HTTP client
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithAdminKey sets the bearer key used for admin endpoints
+func WithAdminKey(key string) Option {
+	return func(c *Client) { c.adminKey = key }
+}
+
+// WithRetries sets how many times idempotent requests are retried on
+// network errors, 429 and 500/502/503/504, and the backoff bounds between
+// attempts
+func WithRetries(maxRetries int, minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// New creates a client for the API at baseURL. apiKey selects the tenant
+// and may be empty for the default tenant.
+func New(baseURL, apiKey string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxRetries: 3,
+		minBackoff: 200 * time.Millisecond,
+		maxBackoff: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Health checks the service
+func (c *Client) Health(ctx context.Context) (*HealthResponse, error) {
+	var out HealthResponse
+	return &out, c.get(ctx, "/health", nil, &out)
+}
+
+// Status reports what is degraded, if anything: a non-empty Banner is
+// meant to be shown to users as is
+func (c *Client) Status(ctx context.Context) (*StatusResponse, error) {
+	var out StatusResponse
+	return &out, c.get(ctx, "/v1/status", nil, &out)
+}
+
+// Price returns the price of a token in currency (usd if empty)
+func (c *Client) Price(ctx context.Context, tokenID, currency string) (*PriceResponse, error) {
+	var out PriceResponse
+	return &out, c.get(ctx, "/v1/price/"+url.PathEscape(tokenID), currencyQuery(currency), &out)
+}
+
+// SignedPrice returns the price of a token with a signature attached
+func (c *Client) SignedPrice(ctx context.Context, tokenID, currency string) (*PriceResponse, error) {
+	q := currencyQuery(currency)
+	q.Set("signed", "true")
+	var out PriceResponse
+	return &out, c.get(ctx, "/v1/price/"+url.PathEscape(tokenID), q, &out)
+}
+
+// Prices returns prices for several tokens in currency (usd if empty)
+func (c *Client) Prices(ctx context.Context, tokenIDs []string, currency string) (*MultiPriceResponse, error) {
+	q := currencyQuery(currency)
+	q.Set("ids", strings.Join(tokenIDs, ","))
+	var out MultiPriceResponse
+	return &out, c.get(ctx, "/v1/prices", q, &out)
+}
+
+// SimplePrice returns the CoinGecko-compatible id -> currency -> price map
+func (c *Client) SimplePrice(ctx context.Context, tokenIDs, currencies []string) (map[string]map[string]float64, error) {
+	q := url.Values{}
+	q.Set("ids", strings.Join(tokenIDs, ","))
+	if len(currencies) > 0 {
+		q.Set("vs_currencies", strings.Join(currencies, ","))
+	}
+	out := make(map[string]map[string]float64)
+	return out, c.get(ctx, "/v1/simple/price", q, &out)
+}
+
+// FX returns exchange rates from base to symbols, or to every known
+// currency if symbols is empty
+func (c *Client) FX(ctx context.Context, base string, symbols []string) (*FXRates, error) {
+	q := url.Values{}
+	if base != "" {
+		q.Set("base", base)
+	}
+	if len(symbols) > 0 {
+		q.Set("symbols", strings.Join(symbols, ","))
+	}
+	var out FXRates
+	return &out, c.get(ctx, "/v1/fx", q, &out)
+}
+
+// Quote returns a price quote with an expiry as EIP-712 typed data,
+// signed for on-chain verification if signed is set
+func (c *Client) Quote(ctx context.Context, tokenID, currency string, signed bool) (*QuoteResponse, error) {
+	q := currencyQuery(currency)
+	if signed {
+		q.Set("signed", "eip712")
+	}
+	var out QuoteResponse
+	return &out, c.get(ctx, "/v1/quote/"+url.PathEscape(tokenID), q, &out)
+}
+
+// BridgeRates returns exchange rates of bridge pairs such as "wbtc/btc",
+// or of every configured pair if pairs is empty, from prices no older
+// than maxAge (the server's maximum if zero)
+func (c *Client) BridgeRates(ctx context.Context, pairs []string, maxAge time.Duration) (*BridgeRates, error) {
+	q := url.Values{}
+	if len(pairs) > 0 {
+		q.Set("pairs", strings.Join(pairs, ","))
+	}
+	if maxAge > 0 {
+		q.Set("max_age", strconv.FormatInt(int64(maxAge/time.Second), 10))
+	}
+	var out BridgeRates
+	return &out, c.get(ctx, "/v1/bridge/rates", q, &out)
+}
+
+// OnrampQuote compares fiat on-ramp offers for buying a token with amount
+// of fiat (usd if empty), fees included
+func (c *Client) OnrampQuote(ctx context.Context, tokenID, fiat string, amount float64) (*OnrampComparison, error) {
+	q := url.Values{}
+	q.Set("token", tokenID)
+	if fiat != "" {
+		q.Set("fiat", fiat)
+	}
+	q.Set("amount", strconv.FormatFloat(amount, 'f', -1, 64))
+	var out OnrampComparison
+	return &out, c.get(ctx, "/v1/onramp/quote", q, &out)
+}
+
+// Changes returns prices changed since cursor, the Cursor of the previous
+// response; every price is returned with Reset set if cursor is empty or
+// expired. limit is the server's default if zero.
+func (c *Client) Changes(ctx context.Context, cursor, currency string, limit int) (*PriceChanges, error) {
+	q := currencyQuery(currency)
+	if cursor != "" {
+		q.Set("since", cursor)
+	}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var out PriceChanges
+	return &out, c.get(ctx, "/v1/changes", q, &out)
+}
+
+// TokenPrice returns the price of a token by its contract address on a
+// chain, such as ethereum or solana
+func (c *Client) TokenPrice(ctx context.Context, chain, contract, currency string) (*ContractPrice, error) {
+	var out ContractPrice
+	return &out, c.get(ctx, "/v1/token-price/"+url.PathEscape(chain)+"/"+url.PathEscape(contract), currencyQuery(currency), &out)
+}
+
+// SigningKeys returns the keys used for signed responses and the address
+// EIP-712 quotes are signed by, with its domain
+func (c *Client) SigningKeys(ctx context.Context) ([]SigningKey, error) {
+	var out struct {
+		Keys []SigningKey `json:"keys"`
+	}
+	return out.Keys, c.get(ctx, "/v1/signing/keys", nil, &out)
+}
+
+// Usage returns request counts for the client's tenant
+func (c *Client) Usage(ctx context.Context) (*TenantUsageSnapshot, error) {
+	var out TenantUsageSnapshot
+	return &out, c.get(ctx, "/v1/usage", nil, &out)
+}
+
+// FlushCache drops cached prices for a token, or all tokens if empty
+func (c *Client) FlushCache(ctx context.Context, tokenID string) (int, error) {
+	q := url.Values{}
+	if tokenID != "" {
+		q.Set("token", tokenID)
+	}
+	var out struct {
+		Flushed int `json:"flushed"`
+	}
+	return out.Flushed, c.do(ctx, http.MethodPost, "/v1/admin/cache/flush", q, nil, &out, false)
+}
+
+// AuditLog queries the admin audit log
+func (c *Client) AuditLog(ctx context.Context, f AuditFilter) ([]AuditEntry, error) {
+	q := url.Values{}
+	if f.Actor != "" {
+		q.Set("actor", f.Actor)
+	}
+	if f.Action != "" {
+		q.Set("action", f.Action)
+	}
+	if !f.Since.IsZero() {
+		q.Set("since", f.Since.UTC().Format(time.RFC3339))
+	}
+	if f.Limit > 0 {
+		q.Set("limit", strconv.Itoa(f.Limit))
+	}
+	var out struct {
+		Entries []AuditEntry `json:"entries"`
+	}
+	return out.Entries, c.get(ctx, "/v1/admin/audit", q, &out)
+}
+
+// TenantUsage returns usage for all tenants
+func (c *Client) TenantUsage(ctx context.Context) ([]TenantUsageSnapshot, error) {
+	var out struct {
+		Tenants []TenantUsageSnapshot `json:"tenants"`
+	}
+	return out.Tenants, c.get(ctx, "/v1/admin/tenants", nil, &out)
+}
+
+// CreateTenantKey creates an API key for a tenant. It is never retried.
+func (c *Client) CreateTenantKey(ctx context.Context, tenant string) (string, error) {
+	q := url.Values{}
+	q.Set("tenant", tenant)
+	var out struct {
+		APIKey string `json:"api_key"`
+	}
+	return out.APIKey, c.do(ctx, http.MethodPost, "/v1/admin/tenants/keys", q, nil, &out, true)
+}
+
+// Get sends a GET request for path, such as "/v1/markets", with query q
+// and decodes the JSON response into out. It is retried like the typed
+// methods and serves endpoints they don't cover.
+func (c *Client) Get(ctx context.Context, path string, q url.Values, out interface{}) error {
+	return c.get(ctx, path, q, out)
+}
+
+func currencyQuery(currency string) url.Values {
+	q := url.Values{}
+	if currency != "" {
+		q.Set("currency", currency)
+	}
+	return q
+}
+
+func (c *Client) get(ctx context.Context, path string, q url.Values, out interface{}) error {
+	return c.do(ctx, http.MethodGet, path, q, nil, out, false)
+}
+
+// do sends a request with body, if not nil, as JSON, retrying transient
+// failures unless noRetry is set. out may be nil for responses without a
+// body.
+func (c *Client) do(ctx context.Context, method, path string, q url.Values, body, out interface{}, noRetry bool) error {
+	u := c.baseURL + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("encoding %s request: %w", path, err)
+		}
+	}
+
+	attempts := c.maxRetries + 1
+	if noRetry {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			wait := c.backoff(attempt)
+			var apiErr *APIError
+			if errors.As(lastErr, &apiErr) && apiErr.RetryAfter > wait {
+				wait = apiErr.RetryAfter
+			}
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		retry, err := c.attempt(ctx, method, u, payload, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry || ctx.Err() != nil {
+			break
+		}
+	}
+	return lastErr
+}
+
+// attempt performs one request, reporting whether a failure is retryable
+func (c *Client) attempt(ctx context.Context, method, u string, payload []byte, out interface{}) (bool, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	if c.adminKey != "" && strings.Contains(u, "/admin/") {
+		req.Header.Set("Authorization", "Bearer "+c.adminKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		var netErr net.Error
+		return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF), err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+		var parsed struct {
+			Error       string   `json:"error"`
+			Code        string   `json:"code"`
+			Suggestions []string `json:"suggestions"`
+		}
+		if json.Unmarshal(body, &parsed) == nil && parsed.Error != "" {
+			apiErr.Message = parsed.Error
+			apiErr.Code, apiErr.Suggestions = parsed.Code, parsed.Suggestions
+		}
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(secs) * time.Second
+		}
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true, apiErr
+		}
+		return false, apiErr
+	}
+
+	if out == nil {
+		return false, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("decoding %s: %w", u, err)
+	}
+	return false, nil
+}
+
+// backoff returns exponential backoff with full jitter for an attempt
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.minBackoff << (attempt - 1)
+	if d <= 0 || d > c.maxBackoff {
+		d = c.maxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(d)) + 1)
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// Market data types
+type (
+	MarketList         = wire.MarketList
+	CategoryList       = wire.CategoryList
+	SupplyCheck        = wire.SupplyCheck
+	SupplyChange       = wire.SupplyChange
+	RiskFeed           = wire.RiskFeed
+	HistorySeries      = wire.HistorySeries
+	PriceAverage       = wire.PriceAverage // TWAP and VWAP
+	DailyClose         = wire.DailyClose
+	Extremes           = wire.Extremes
+	ListingFeed        = wire.ListingFeed
+	MarketReport       = wire.MarketReport
+	GlobalOverview     = wire.GlobalOverview
+	TrendingList       = wire.TrendingList
+	Movers             = wire.Movers
+	IndexValue         = wire.IndexValue
+	DerivativesSummary = wire.DerivativesSummary
+	NFTCollection      = wire.NFTCollection
+	TVLProtocol        = wire.TVLProtocol
+	GasEstimate        = wire.GasEstimate
+	StablecoinPegs     = wire.StablecoinPegs
+)
+
+// Markets returns the largest tokens by market cap in currency, only those
+// of category if not empty. limit is the server's default if zero.
+func (c *Client) Markets(ctx context.Context, currency, category string, limit int) (*MarketList, error) {
+	q := currencyQuery(currency)
+	if category != "" {
+		q.Set("category", category)
+	}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var out MarketList
+	return &out, c.get(ctx, "/v1/markets", q, &out)
+}
+
+// Categories returns token categories, their hierarchy and sizes
+func (c *Client) Categories(ctx context.Context) (*CategoryList, error) {
+	var out CategoryList
+	return &out, c.get(ctx, "/v1/categories", nil, &out)
+}
+
+// Supply returns a verified token's reported supply checked against its
+// contract
+func (c *Client) Supply(ctx context.Context, tokenID string) (*SupplyCheck, error) {
+	var out SupplyCheck
+	return &out, c.get(ctx, "/v1/supply/"+url.PathEscape(tokenID), nil, &out)
+}
+
+// SupplyChanges returns day-over-day changes of a token's circulating
+// supply over the last days (the server's default if zero), oldest first
+func (c *Client) SupplyChanges(ctx context.Context, tokenID string, days int) ([]SupplyChange, error) {
+	var out struct {
+		Changes []SupplyChange `json:"changes"`
+	}
+	return out.Changes, c.get(ctx, "/v1/supply/"+url.PathEscape(tokenID)+"/changes", daysQuery(nil, days), &out)
+}
+
+// FlaggedSupplyChanges returns circulating supply changes beyond the
+// server's threshold since a time (a week ago if zero), newest first
+func (c *Client) FlaggedSupplyChanges(ctx context.Context, since time.Time) ([]SupplyChange, error) {
+	var out struct {
+		Changes []SupplyChange `json:"changes"`
+	}
+	return out.Changes, c.get(ctx, "/v1/supply/changes", sinceQuery(since), &out)
+}
+
+// Risk returns the tokens flagged as risky
+func (c *Client) Risk(ctx context.Context) (*RiskFeed, error) {
+	var out RiskFeed
+	return &out, c.get(ctx, "/v1/risk", nil, &out)
+}
+
+// History returns a token's price, market cap and volume over the last
+// days (the server's default if zero)
+func (c *Client) History(ctx context.Context, tokenID, currency string, days int) (*HistorySeries, error) {
+	var out HistorySeries
+	return &out, c.get(ctx, "/v1/history/"+url.PathEscape(tokenID), daysQuery(currencyQuery(currency), days), &out)
+}
+
+// TWAP returns a token's time- and volume-weighted average price over the
+// window ending now (the server's default if zero)
+func (c *Client) TWAP(ctx context.Context, tokenID, currency string, window time.Duration) (*PriceAverage, error) {
+	q := currencyQuery(currency)
+	if window > 0 {
+		q.Set("window", window.String())
+	}
+	var out PriceAverage
+	return &out, c.get(ctx, "/v1/twap/"+url.PathEscape(tokenID), q, &out)
+}
+
+// DailyClose returns a token's close on date, as YYYY-MM-DD, in the IANA
+// time zone tz. Empty values select the last full day and UTC.
+func (c *Client) DailyClose(ctx context.Context, tokenID, currency, tz, date string) (*DailyClose, error) {
+	q := currencyQuery(currency)
+	if tz != "" {
+		q.Set("tz", tz)
+	}
+	if date != "" {
+		q.Set("date", date)
+	}
+	var out DailyClose
+	return &out, c.get(ctx, "/v1/close/"+url.PathEscape(tokenID), q, &out)
+}
+
+// Extremes returns a token's all-time and 52-week highs and lows
+func (c *Client) Extremes(ctx context.Context, tokenID, currency string) (*Extremes, error) {
+	var out Extremes
+	return &out, c.get(ctx, "/v1/extremes/"+url.PathEscape(tokenID), currencyQuery(currency), &out)
+}
+
+// ListingChanges returns the coins CoinGecko listed and delisted after a
+// time (a day ago if zero)
+func (c *Client) ListingChanges(ctx context.Context, since time.Time) (*ListingFeed, error) {
+	var out ListingFeed
+	return &out, c.get(ctx, "/v1/listings/changes", sinceQuery(since), &out)
+}
+
+// Report returns the latest daily or weekly market report
+func (c *Client) Report(ctx context.Context) (*MarketReport, error) {
+	var out MarketReport
+	return &out, c.get(ctx, "/v1/reports/latest", nil, &out)
+}
+
+// Global returns the total crypto market cap, volume and dominance
+func (c *Client) Global(ctx context.Context, currency string) (*GlobalOverview, error) {
+	var out GlobalOverview
+	return &out, c.get(ctx, "/v1/global", currencyQuery(currency), &out)
+}
+
+// Trending returns the trending tokens
+func (c *Client) Trending(ctx context.Context) (*TrendingList, error) {
+	var out TrendingList
+	return &out, c.get(ctx, "/v1/trending", nil, &out)
+}
+
+// Movers returns the top gainers and losers over 24 hours. limit is the
+// server's default if zero.
+func (c *Client) Movers(ctx context.Context, currency string, limit int) (*Movers, error) {
+	q := currencyQuery(currency)
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var out Movers
+	return &out, c.get(ctx, "/v1/movers", q, &out)
+}
+
+// Index returns the value of a configured token index
+func (c *Client) Index(ctx context.Context, name, currency string) (*IndexValue, error) {
+	var out IndexValue
+	return &out, c.get(ctx, "/v1/index/"+url.PathEscape(name), currencyQuery(currency), &out)
+}
+
+// Derivatives returns perpetual funding rates and open interest of a
+// token, by id or ticker symbol
+func (c *Client) Derivatives(ctx context.Context, token string) (*DerivativesSummary, error) {
+	var out DerivativesSummary
+	return &out, c.get(ctx, "/v1/derivatives/"+url.PathEscape(token), nil, &out)
+}
+
+// NFT returns an NFT collection's floor price, volume and owners
+func (c *Client) NFT(ctx context.Context, collection string) (*NFTCollection, error) {
+	var out NFTCollection
+	return &out, c.get(ctx, "/v1/nft/"+url.PathEscape(collection), nil, &out)
+}
+
+// TVL returns a DeFi protocol's total value locked
+func (c *Client) TVL(ctx context.Context, protocol string) (*TVLProtocol, error) {
+	var out TVLProtocol
+	return &out, c.get(ctx, "/v1/tvl/"+url.PathEscape(protocol), nil, &out)
+}
+
+// Gas returns gas fee estimates for a chain
+func (c *Client) Gas(ctx context.Context, chain string) (*GasEstimate, error) {
+	var out GasEstimate
+	return &out, c.get(ctx, "/v1/gas/"+url.PathEscape(chain), nil, &out)
+}
+
+// Stablecoins returns each monitored stablecoin's deviation from its peg,
+// with deviation samples if history is set
+func (c *Client) Stablecoins(ctx context.Context, history bool) (*StablecoinPegs, error) {
+	q := url.Values{}
+	if history {
+		q.Set("history", "true")
+	}
+	var out StablecoinPegs
+	return &out, c.get(ctx, "/v1/stablecoins", q, &out)
+}
+
+// daysQuery adds days to q, if set
+func daysQuery(q url.Values, days int) url.Values {
+	if q == nil {
+		q = url.Values{}
+	}
+	if days > 0 {
+		q.Set("days", strconv.Itoa(days))
+	}
+	return q
+}
+
+// sinceQuery sets since to t, if set
+func sinceQuery(t time.Time) url.Values {
+	q := url.Values{}
+	if !t.IsZero() {
+		q.Set("since", t.UTC().Format(time.RFC3339))
+	}
+	return q
+}
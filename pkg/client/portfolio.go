@@ -0,0 +1,102 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// Portfolio and analytics types
+type (
+	PortfolioRequest     = wire.PortfolioRequest
+	PortfolioHolding     = wire.PortfolioHolding
+	PortfolioTrade       = wire.PortfolioTrade
+	PortfolioPerformance = wire.PortfolioPerformance
+	AllocationRequest    = wire.AllocationRequest
+	AllocationHolding    = wire.AllocationHolding
+	Allocation           = wire.Allocation
+	TaxLotsRequest       = wire.TaxLotsRequest
+	Lot                  = wire.Lot
+	TaxLots              = wire.TaxLots
+	Correlation          = wire.Correlation
+	RiskMetrics          = wire.RiskMetrics
+	RelativePerformance  = wire.RelativePerformance
+	Indicators           = wire.Indicators
+	Returns              = wire.Returns
+)
+
+// PortfolioPerformance returns the value over time, PnL and returns of
+// token holdings
+func (c *Client) PortfolioPerformance(ctx context.Context, req PortfolioRequest) (*PortfolioPerformance, error) {
+	var out PortfolioPerformance
+	return &out, c.do(ctx, http.MethodPost, "/v1/portfolio/performance", nil, req, &out, false)
+}
+
+// PortfolioAllocation breaks token holdings down by token, category,
+// chain, stability and staking
+func (c *Client) PortfolioAllocation(ctx context.Context, req AllocationRequest) (*Allocation, error) {
+	var out Allocation
+	return &out, c.do(ctx, http.MethodPost, "/v1/portfolio/allocation", nil, req, &out, false)
+}
+
+// TaxLots prices acquisitions at their time and now for tax reports
+func (c *Client) TaxLots(ctx context.Context, req TaxLotsRequest) (*TaxLots, error) {
+	var out TaxLots
+	return &out, c.do(ctx, http.MethodPost, "/v1/tax/lots", nil, req, &out, false)
+}
+
+// Correlation returns the pairwise correlation of tokens' daily returns
+// over the last days (the server's default if zero)
+func (c *Client) Correlation(ctx context.Context, tokenIDs []string, currency string, days int) (*Correlation, error) {
+	q := daysQuery(currencyQuery(currency), days)
+	q.Set("ids", strings.Join(tokenIDs, ","))
+	var out Correlation
+	return &out, c.get(ctx, "/v1/analytics/correlation", q, &out)
+}
+
+// RiskMetrics returns a token's metrics such as volatility_30d,
+// max_drawdown_90d or sharpe_180d; the server's defaults if none are given
+func (c *Client) RiskMetrics(ctx context.Context, tokenID, currency string, metrics ...string) (*RiskMetrics, error) {
+	var out RiskMetrics
+	return &out, c.get(ctx, "/v1/analytics/"+url.PathEscape(tokenID), listQuery(currencyQuery(currency), "metrics", metrics), &out)
+}
+
+// Relative returns a token's beta, alpha and tracking series against a
+// benchmark token, or index:<name> for a configured index (bitcoin if
+// empty), over the last days (the server's default if zero)
+func (c *Client) Relative(ctx context.Context, tokenID, benchmark, currency string, days int) (*RelativePerformance, error) {
+	q := daysQuery(currencyQuery(currency), days)
+	if benchmark != "" {
+		q.Set("benchmark", benchmark)
+	}
+	var out RelativePerformance
+	return &out, c.get(ctx, "/v1/analytics/"+url.PathEscape(tokenID)+"/vs", q, &out)
+}
+
+// Indicators returns a token's indicators such as sma_50, ema_20 or
+// rsi_14; the server's defaults if none are given
+func (c *Client) Indicators(ctx context.Context, tokenID, currency string, set ...string) (*Indicators, error) {
+	var out Indicators
+	return &out, c.get(ctx, "/v1/indicators/"+url.PathEscape(tokenID), listQuery(currencyQuery(currency), "set", set), &out)
+}
+
+// Returns returns a token's percent returns over periods such as 1d, 2w
+// or 1y ending now; the server's defaults if none are given
+func (c *Client) Returns(ctx context.Context, tokenID, currency string, periods ...string) (*Returns, error) {
+	var out Returns
+	return &out, c.get(ctx, "/v1/returns/"+url.PathEscape(tokenID), listQuery(currencyQuery(currency), "periods", periods), &out)
+}
+
+// listQuery sets key in q to the comma-separated values, if any
+func listQuery(q url.Values, key string, values []string) url.Values {
+	if len(values) > 0 {
+		q.Set(key, strings.Join(values, ","))
+	}
+	return q
+}
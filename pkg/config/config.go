@@ -0,0 +1,2591 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package config loads service configuration from a JSON file, the
+// environment and command-line flags. Later sources win: flags override
+// environment variables, which override the file, which overrides defaults.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is a time.Duration that marshals to and from "5m" style strings
+type Duration struct {
+	time.Duration
+}
+
+// MarshalJSON encodes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON decodes "5m" style strings or integer seconds
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		var secs int64
+		if err := json.Unmarshal(b, &secs); err != nil {
+			return fmt.Errorf("invalid duration %s", string(b))
+		}
+		d.Duration = time.Duration(secs) * time.Second
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+// Config is the complete service configuration
+type Config struct {
+	Port string `json:"port"`
+
+	// Provider is the main price provider: "coingecko", "mock" for
+	// deterministic fixture data without an API key or network, or "demo"
+	// for fixture data whose prices move as seeded random walks
+	Provider string     `json:"provider"`
+	Demo     DemoConfig `json:"demo"`
+
+	CoinGecko CoinGeckoConfig `json:"coingecko"`
+	Cache     CacheConfig     `json:"cache"`
+	Upstream  UpstreamConfig  `json:"upstream"`
+	SLO       SLOConfig       `json:"slo"`
+	CORS      CORSConfig      `json:"cors"`
+	RateLimit RateLimitConfig `json:"rate_limit"`
+	Admin     AdminConfig     `json:"admin"`
+	Plugins   []PluginConfig  `json:"plugins"`
+	Sources   []SourceConfig  `json:"sources"`
+	Onramps   []OnrampConfig  `json:"onramps"`
+	FX        FXConfig        `json:"fx"`
+	Metals    MetalsConfig    `json:"metals"`
+
+	Stablecoins StablecoinsConfig `json:"stablecoins"`
+	Deviation   DeviationConfig   `json:"deviation"`
+	Oracle      OracleConfig      `json:"oracle"`
+	Quotes      QuotesConfig      `json:"quotes"`
+	Quorum      QuorumConfig      `json:"quorum"`
+	Bridge      BridgeConfig      `json:"bridge"`
+	Gas         GasConfig         `json:"gas"`
+	TokenPrice  TokenPriceConfig  `json:"token_price"`
+	Supply      SupplyConfig      `json:"supply"`
+	Risk        RiskConfig        `json:"risk"`
+	Derivatives DerivativesConfig `json:"derivatives"`
+	NFT         NFTConfig         `json:"nft"`
+	TVL         TVLConfig         `json:"tvl"`
+	Logos       LogosConfig       `json:"logos"`
+	Global      GlobalConfig      `json:"global"`
+	Trending    TrendingConfig    `json:"trending"`
+	Markets     MarketsConfig     `json:"markets"`
+	History     HistoryConfig     `json:"history"`
+	Ticks       TicksConfig       `json:"ticks"`
+	Stream      StreamConfig      `json:"stream"`
+	Replica     ReplicaConfig     `json:"replica"`
+	Analytics   AnalyticsConfig   `json:"analytics"`
+	Alerts      AlertsConfig      `json:"alerts"`
+	Snapshot    SnapshotConfig    `json:"snapshot"`
+	Reports     ReportsConfig     `json:"reports"`
+	Treasury    TreasuryConfig    `json:"treasury"`
+	Extremes    ExtremesConfig    `json:"extremes"`
+	Listings    ListingsConfig    `json:"listings"`
+	Categories  CategoriesConfig  `json:"categories"`
+	Email       EmailConfig       `json:"email"`
+	Aliases     AliasesConfig     `json:"aliases"`
+	Overrides   OverridesConfig   `json:"overrides"`
+	Rounding    RoundingConfig    `json:"rounding"`
+	Features    FeaturesConfig    `json:"features"`
+
+	// Indices maps index names to their constituents' token ids and
+	// relative weights. Indices are configured in the config file only.
+	Indices map[string]map[string]float64 `json:"indices"`
+
+	AccessLog   bool   `json:"access_log"`
+	TenantsFile string `json:"tenants_file"`
+	SigningKey  string `json:"signing_key"`
+
+	// LegacySunset is the date (YYYY-MM-DD) unversioned routes stop working,
+	// advertised in the Sunset header
+	LegacySunset string `json:"legacy_sunset"`
+}
+
+// CoinGeckoConfig configures the CoinGecko provider
+type CoinGeckoConfig struct {
+	APIKey string `json:"api_key"`
+
+	// BaseURL overrides the API root; the provider picks one if empty
+	BaseURL string `json:"base_url"`
+}
+
+// DemoConfig configures the demo provider
+type DemoConfig struct {
+	// Seed picks the random walks; instances with the same seed serve the
+	// same prices
+	Seed int `json:"seed"`
+
+	// Tokens is the universe listed by /markets; the fixture tokens if
+	// empty. Other ids are still priced.
+	Tokens []string `json:"tokens,omitempty"`
+}
+
+// CacheConfig configures price caching and Cache-Control headers
+type CacheConfig struct {
+	TTL Duration `json:"ttl"`
+
+	// MaxChange quarantines fetched prices that move further than this
+	// fraction from the cached price (0 disables)
+	MaxChange float64 `json:"max_change"`
+
+	// NotFoundTTL is how long tokens the provider doesn't know are
+	// answered as not found without asking again (0 disables)
+	NotFoundTTL Duration `json:"not_found_ttl"`
+
+	// MaxAges maps token ids to the oldest price served of them, a
+	// freshness SLA: older prices are refetched even within the TTL, and
+	// answered with a 503 carrying the stale price if that fails
+	MaxAges map[string]Duration `json:"max_ages,omitempty"`
+
+	// StaleAfter is how long upstream refreshes may fail before /status
+	// reports the prices served as stale
+	StaleAfter Duration `json:"stale_after"`
+
+	// CacheControl maps endpoint names (price, prices, simple_price) to
+	// Cache-Control directives, e.g. "max-age=300, stale-while-revalidate=60"
+	CacheControl map[string]string `json:"cache_control"`
+}
+
+// UpstreamConfig tunes outbound provider requests
+type UpstreamConfig struct {
+	Concurrency         int      `json:"concurrency"`
+	MaxIdleConns        int      `json:"max_idle_conns"`
+	MaxIdleConnsPerHost int      `json:"max_idle_conns_per_host"`
+	MaxConnsPerHost     int      `json:"max_conns_per_host"`
+	IdleConnTimeout     Duration `json:"idle_conn_timeout"`
+	HTTP2               bool     `json:"http2"`
+
+	// RetryAttempts is the most tries per idempotent request (1 disables
+	// retries); retries wait up to RetryBackoff, doubling up to
+	// RetryMaxBackoff
+	RetryAttempts   int      `json:"retry_attempts"`
+	RetryBackoff    Duration `json:"retry_backoff"`
+	RetryMaxBackoff Duration `json:"retry_max_backoff"`
+
+	// BreakerFailures consecutive failed calls to a price provider open its
+	// circuit for BreakerCooldown (0 disables the breaker)
+	BreakerFailures int      `json:"breaker_failures"`
+	BreakerCooldown Duration `json:"breaker_cooldown"`
+
+	// RateLimitPause is how long requests to a provider are paused after a
+	// 429 that has no Retry-After header
+	RateLimitPause Duration `json:"rate_limit_pause"`
+
+	// MaxInFlight caps requests in flight to all upstream hosts together
+	// (0 disables the cap); up to MaxQueued more wait for a slot
+	MaxInFlight int `json:"max_in_flight"`
+	MaxQueued   int `json:"max_queued"`
+
+	// RequestsPerMinute paces requests to each host listed, e.g.
+	// "api.coingecko.com": 30, to its quota; requests over it wait,
+	// interactive ones ahead of background refreshes and backfills
+	RequestsPerMinute map[string]int `json:"requests_per_minute"`
+
+	// Timeout bounds every upstream call, and alone bounds those made by
+	// background jobs. Calls made for an API request must also finish
+	// within the request's budget: RouteTimeouts for its route pattern
+	// (e.g. "/history/"), or RequestTimeout.
+	Timeout        Duration            `json:"timeout"`
+	RequestTimeout Duration            `json:"request_timeout"`
+	RouteTimeouts  map[string]Duration `json:"route_timeouts"`
+
+	// HedgeRoutes are the route patterns whose provider calls are hedged
+	// when alternate plugins are configured: a call the fastest provider
+	// hasn't answered within HedgeAfter, or its p95 latency if 0, is also
+	// sent to the next fastest
+	HedgeRoutes []string `json:"hedge_routes"`
+	HedgeAfter  Duration `json:"hedge_after"`
+
+	// Record writes every upstream response into this directory; Replay
+	// serves upstream requests from such a directory instead of the
+	// network
+	Record string `json:"record,omitempty"`
+	Replay string `json:"replay,omitempty"`
+}
+
+// SLOConfig sets the service level objectives each route is measured
+// against by /slo
+type SLOConfig struct {
+	// Availability is the fraction of a route's requests that must not
+	// fail with a 5xx
+	Availability float64 `json:"availability"`
+
+	// Latency is the response time LatencyTarget of a route's requests
+	// must beat
+	Latency       Duration `json:"latency"`
+	LatencyTarget float64  `json:"latency_target"`
+
+	// Window is the rolling window objectives are measured over; 0
+	// disables tracking
+	Window Duration `json:"window"`
+
+	// Routes overrides the objectives per route pattern. Routes are
+	// configured in the config file only.
+	Routes map[string]SLOObjective `json:"routes"`
+
+	// Shed lists the feature groups and route patterns answered 503 while
+	// another route burns its error budget ShedBurnRate times faster than
+	// the window allows; none are shed if empty
+	Shed         []string `json:"shed"`
+	ShedBurnRate float64  `json:"shed_burn_rate"`
+}
+
+// SLOObjective is a route's objectives. Fields left out take the defaults;
+// a latency of 0s leaves the route's latency untracked.
+type SLOObjective struct {
+	Availability  float64   `json:"availability,omitempty"`
+	Latency       *Duration `json:"latency,omitempty"`
+	LatencyTarget float64   `json:"latency_target,omitempty"`
+}
+
+// CORSConfig lists origins allowed to call the API from browsers
+type CORSConfig struct {
+	AllowedOrigins []string `json:"allowed_origins"`
+}
+
+// RateLimitConfig limits requests made without an API key
+type RateLimitConfig struct {
+	RequestsPerMinute int `json:"requests_per_minute"`
+	Burst             int `json:"burst"`
+}
+
+// AdminConfig configures the admin API
+type AdminConfig struct {
+	// APIKeys maps actor names to admin bearer keys
+	APIKeys      map[string]string `json:"api_keys"`
+	AuditLogPath string            `json:"audit_log_path"`
+}
+
+// PluginConfig registers a sidecar price source that serves the tokens
+// listed in Tokens. Plugins are configured in the config file only.
+type PluginConfig struct {
+	Name    string   `json:"name"`
+	URL     string   `json:"url"`
+	Tokens  []string `json:"tokens"`
+	Timeout Duration `json:"timeout"`
+
+	// Mode is "claim", serving Tokens ahead of CoinGecko, or "alternate",
+	// serving every token interchangeably with CoinGecko, whichever is
+	// currently faster; claim if empty
+	Mode string `json:"mode"`
+}
+
+// SourceConfig is a manually configured price source, such as a DEX pool
+// or an exchange API, for tokens providers don't list or list unreliably.
+// Sources are configured in the config file only.
+type SourceConfig struct {
+	Name string `json:"name"`
+
+	// Type is "dex_pool" or "exchange"
+	Type string `json:"type"`
+
+	// Mode is "override", serving the tokens ahead of CoinGecko, or
+	// "supplement", serving them only when CoinGecko has no price
+	Mode string `json:"mode"`
+
+	// Quote is the currency or token id prices are quoted in, e.g.
+	// "usd-coin" for a pool against USDC
+	Quote string `json:"quote"`
+
+	// RPCURL and Pools configure a dex_pool source: an EVM JSON-RPC
+	// endpoint and each token's pool
+	RPCURL string                `json:"rpc_url"`
+	Pools  map[string]PoolConfig `json:"pools"`
+
+	// URL, Field and Symbols configure an exchange source: a ticker URL
+	// containing {symbol}, the dotted path of the price in its response,
+	// and each token's market symbol
+	URL     string            `json:"url"`
+	Field   string            `json:"field"`
+	Symbols map[string]string `json:"symbols"`
+
+	Timeout Duration `json:"timeout"`
+}
+
+// OnrampConfig is a fiat on-ramp whose buy quotes /onramp/quote compares.
+// On-ramps are configured in the config file only.
+type OnrampConfig struct {
+	Name string `json:"name"`
+
+	// Type is "moonpay" or "transak"
+	Type string `json:"type"`
+
+	// URL is the API base URL, the provider's production API if empty
+	URL    string `json:"url"`
+	APIKey string `json:"api_key"`
+
+	// Currencies maps token ids to the provider's currency codes
+	Currencies map[string]string `json:"currencies"`
+
+	// Network is the chain Transak delivers on; PaymentMethod narrows
+	// quotes to one way of paying, e.g. "credit_debit_card"
+	Network       string `json:"network"`
+	PaymentMethod string `json:"payment_method"`
+
+	Timeout Duration `json:"timeout"`
+}
+
+// PoolConfig is a Uniswap V2 style pair holding a token and the quote
+// asset
+type PoolConfig struct {
+	Address       string `json:"address"`
+	BaseIndex     int    `json:"base_index"` // 0 if the priced token is token0, 1 if token1
+	BaseDecimals  int    `json:"base_decimals"`
+	QuoteDecimals int    `json:"quote_decimals"`
+}
+
+// Tokens returns the token ids the source prices
+func (s SourceConfig) Tokens() []string {
+	var ids []string
+	for id := range s.Pools {
+		ids = append(ids, id)
+	}
+	for id := range s.Symbols {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// FXConfig configures the fiat exchange rate source used for /fx and to
+// derive prices in currencies the price provider does not quote
+type FXConfig struct {
+	// Source is "ecb", "exchangerate.host" or "none"
+	Source string `json:"source"`
+	APIKey string `json:"api_key"`
+
+	// BaseURL overrides the source's URL
+	BaseURL string   `json:"base_url"`
+	TTL     Duration `json:"ttl"`
+
+	// Normalize derives every currency the source knows from USD prices,
+	// not only those the price provider doesn't quote
+	Normalize bool `json:"normalize"`
+}
+
+// MetalsConfig configures metals-api.com quotes for gold, silver and oil;
+// they are disabled without an API key
+type MetalsConfig struct {
+	APIKey  string `json:"api_key"`
+	BaseURL string `json:"base_url"`
+}
+
+// StablecoinsConfig configures stablecoin peg monitoring
+type StablecoinsConfig struct {
+	// Coins to monitor; USDT, USDC and DAI if empty
+	Coins []StablecoinConfig `json:"coins"`
+
+	// Threshold is the absolute deviation from the peg that counts as a
+	// depeg, e.g. 0.005 for half a percent
+	Threshold float64 `json:"threshold"`
+
+	// Interval between polls; 0 disables monitoring
+	Interval Duration `json:"interval"`
+
+	// History is how long deviation samples are kept
+	History Duration `json:"history"`
+
+	// Webhooks receive a POST when a coin depegs or recovers
+	Webhooks []string `json:"webhooks"`
+}
+
+// StablecoinConfig is one monitored stablecoin
+type StablecoinConfig struct {
+	ID     string  `json:"id"`
+	Symbol string  `json:"symbol"`
+	Peg    float64 `json:"peg"`
+}
+
+// DeviationConfig configures alarms for providers that disagree on a price
+type DeviationConfig struct {
+	// Tokens to compare; every token a plugin serves if empty
+	Tokens []string `json:"tokens"`
+
+	// Threshold is the absolute deviation from the median quote that
+	// raises an alarm, e.g. 0.02 for two percent
+	Threshold float64 `json:"threshold"`
+
+	// Interval between comparisons; 0 disables them
+	Interval Duration `json:"interval"`
+
+	// Webhooks receive a POST when an alarm is raised or clears
+	Webhooks []string `json:"webhooks"`
+}
+
+// OracleConfig configures pushing prices to an on-chain oracle contract.
+// Pushing is enabled when Contract is set.
+type OracleConfig struct {
+	RPCURL   string `json:"rpc_url"`
+	Contract string `json:"contract"`
+
+	// From is the feeder account; the node or signer behind RPCURL holds
+	// its key and signs for it
+	From string `json:"from"`
+
+	// Method is the contract function taking (bytes32 feedId, int256
+	// answer, uint256 updatedAt)
+	Method string `json:"method"`
+
+	// Feeds are "token/currency" pairs, e.g. "lux/usd"
+	Feeds []string `json:"feeds"`
+
+	// Decimals scales answers: a price p is pushed as p × 10^Decimals
+	Decimals int `json:"decimals"`
+
+	// Deviation is the relative price move that triggers an update, e.g.
+	// 0.005 for half a percent; Heartbeat is the longest time between
+	// updates of an unmoving price
+	Deviation float64  `json:"deviation"`
+	Heartbeat Duration `json:"heartbeat"`
+
+	// Interval between price checks
+	Interval Duration `json:"interval"`
+
+	// MaxGasPriceGwei defers updates while the network asks more; 0 is
+	// no cap. GasLimit is the gas per update; 0 estimates each one.
+	MaxGasPriceGwei float64 `json:"max_gas_price_gwei"`
+	GasLimit        int     `json:"gas_limit"`
+
+	// ReplaceAfter is how long an update may stay unmined before it is
+	// resent with a higher gas price
+	ReplaceAfter Duration `json:"replace_after"`
+}
+
+// BridgeConfig configures the exchange rates served to the Lux bridge by
+// /bridge/rates. Rates are served when Pairs is set.
+type BridgeConfig struct {
+	// Pairs are "base/quote" pairs, e.g. "wrapped-bitcoin/bitcoin". A
+	// quote equal to Currency is priced directly; any other quote is a
+	// token id and both sides are priced in Currency.
+	Pairs    []string `json:"pairs"`
+	Currency string   `json:"currency"`
+
+	// MaxAge is the oldest price a rate may be derived from; older prices
+	// fail the request instead of being served
+	MaxAge Duration `json:"max_age"`
+
+	// Decimals rates are rounded down to
+	Decimals int `json:"decimals"`
+}
+
+// QuotesConfig configures EIP-712 signed quotes served by /quote/{token}.
+// Signing is enabled when Signer is set.
+type QuotesConfig struct {
+	// Signer is the address quotes are signed by; the signer behind
+	// SignerURL holds its key and signs with SignMethod
+	Signer     string `json:"signer"`
+	SignerURL  string `json:"signer_url"`
+	SignMethod string `json:"sign_method"`
+
+	// The EIP-712 domain; VerifyingContract is optional
+	DomainName        string `json:"domain_name"`
+	DomainVersion     string `json:"domain_version"`
+	ChainID           int    `json:"chain_id"`
+	VerifyingContract string `json:"verifying_contract"`
+
+	// TTL is how long a quote is valid
+	TTL Duration `json:"ttl"`
+
+	// Decimals scales prices: a price p is quoted as p × 10^Decimals
+	Decimals int `json:"decimals"`
+}
+
+// QuorumConfig requires the prices of quotes and oracle updates to be
+// agreed by several providers instead of taken from the cache. It applies
+// when Min is set.
+type QuorumConfig struct {
+	// Min is how many providers must agree on a price
+	Min int `json:"min"`
+
+	// Providers names the providers counted: coingecko, metals, or a
+	// plugin or source; every configured one if empty
+	Providers []string `json:"providers"`
+
+	// Tolerance is the relative deviation from the median of all quotes
+	// within which a provider agrees
+	Tolerance float64 `json:"tolerance"`
+
+	// TTL is how long an agreed price is reused before providers are
+	// asked again
+	TTL Duration `json:"ttl"`
+}
+
+// GasConfig configures fee estimates served by /gas/{chain}
+type GasConfig struct {
+	// RPCs maps chain names to JSON-RPC URLs. Entries from the config file
+	// are merged with the defaults; an empty URL removes a chain.
+	RPCs map[string]string `json:"rpcs"`
+	TTL  Duration          `json:"ttl"`
+}
+
+// TokenPriceConfig configures prices by contract address served by
+// /token-price/{chain}/{contract}
+type TokenPriceConfig struct {
+	// Platforms maps chain names to CoinGecko asset platforms. Entries from
+	// the config file are merged with the defaults; an empty platform
+	// removes a chain.
+	Platforms map[string]string `json:"platforms"`
+
+	// DEXes maps chain names to the DEX contracts are priced on when
+	// CoinGecko doesn't list them. DEXes are configured in the config file
+	// only.
+	DEXes map[string]DEXConfig `json:"dexes"`
+
+	// MinLiquidity is the least USD value a DEX pool must hold
+	MinLiquidity float64  `json:"min_liquidity"`
+	TTL          Duration `json:"ttl"`
+}
+
+// DEXConfig is a Uniswap V2 style factory and the tokens pairs are looked
+// up against
+type DEXConfig struct {
+	// RPCURL is the chain's JSON-RPC endpoint; its gas.rpcs entry if empty
+	RPCURL  string           `json:"rpc_url"`
+	Factory string           `json:"factory"`
+	Quotes  []DEXQuoteConfig `json:"quotes"`
+}
+
+// DEXQuoteConfig is a quote token of a DEX, priced by its token id
+type DEXQuoteConfig struct {
+	Address  string `json:"address"`
+	Token    string `json:"token"` // e.g. usd-coin
+	Decimals int    `json:"decimals"`
+}
+
+// SupplyConfig configures verification of the supply CoinGecko reports
+// against token contracts on chain
+type SupplyConfig struct {
+	// Tokens maps token ids to their contracts. Tokens are configured in
+	// the config file only; none are verified if empty.
+	Tokens map[string]SupplyTokenConfig `json:"tokens"`
+
+	// Threshold is the relative difference flagged as a discrepancy
+	Threshold float64 `json:"threshold"`
+
+	// Interval between verifications
+	Interval Duration `json:"interval"`
+
+	// Changes tracks day-over-day changes of circulating supply
+	Changes SupplyChangesConfig `json:"changes"`
+}
+
+// SupplyChangesConfig configures the tracking of circulating supply
+// changes, such as unlocks and mints, from provider data
+type SupplyChangesConfig struct {
+	// Interval between checks of the supply providers report; 0 disables
+	// tracking
+	Interval Duration `json:"interval"`
+
+	// Threshold is the relative day-over-day change alerted on
+	Threshold float64 `json:"threshold"`
+
+	// Tokens are tracked besides the largest tokens by market cap
+	Tokens []string `json:"tokens"`
+
+	// File persists daily supply across restarts; memory only if empty
+	File string `json:"file"`
+
+	// Retention is how long daily supply is kept
+	Retention Duration `json:"retention"`
+
+	// Channels receive every change beyond the threshold
+	Channels []ChannelConfig `json:"channels"`
+}
+
+// SupplyTokenConfig is the ERC-20 contract a token's supply is read from
+type SupplyTokenConfig struct {
+	Chain    string `json:"chain"`
+	RPCURL   string `json:"rpc_url"` // the chain's gas.rpcs entry if empty
+	Contract string `json:"contract"`
+	Decimals int    `json:"decimals"`
+
+	// Locked are addresses whose balance doesn't circulate, such as
+	// staking contracts, treasuries and vesting wallets
+	Locked []string `json:"locked"`
+}
+
+// RiskConfig configures the risk flags of price and market responses
+type RiskConfig struct {
+	// Contracts maps token ids to the contract checked for honeypot and
+	// other contract risks. Contracts are configured in the config file
+	// only.
+	Contracts map[string]RiskContractConfig `json:"contracts"`
+
+	// SecurityURL is the root of a GoPlus-compatible token security API
+	SecurityURL string `json:"security_url"`
+
+	// Volatility is the annualized 30-day volatility flagged, e.g. 1.5
+	Volatility float64 `json:"volatility"`
+
+	// MinVolume is the 24h USD volume below which liquidity is flagged
+	MinVolume float64 `json:"min_volume"`
+
+	// MaxTax is the buy or sell tax of a contract flagged, e.g. 0.1
+	MaxTax float64 `json:"max_tax"`
+
+	// Tokens is how many of the largest tokens by market cap are scanned
+	Tokens int `json:"tokens"`
+
+	// Interval between scans; 0 disables risk flags
+	Interval Duration `json:"interval"`
+}
+
+// RiskContractConfig is the contract of a token checked for risks
+type RiskContractConfig struct {
+	Chain    string `json:"chain"` // e.g. ethereum, or a chain id
+	Contract string `json:"contract"`
+}
+
+// DerivativesConfig configures perpetual funding and open interest data
+type DerivativesConfig struct {
+	TTL Duration `json:"ttl"`
+}
+
+// NFTConfig configures NFT collection floor prices
+type NFTConfig struct {
+	TTL Duration `json:"ttl"`
+}
+
+// TVLConfig configures DefiLlama protocol TVL
+type TVLConfig struct {
+	// BaseURL overrides the DefiLlama API root
+	BaseURL string   `json:"base_url"`
+	TTL     Duration `json:"ttl"`
+}
+
+// LogosConfig configures the token logo proxy served by /token/{id}/logo
+type LogosConfig struct {
+	// TTL is how long a logo is served before it is refetched
+	TTL Duration `json:"ttl"`
+
+	// MaxBytes bounds the logos cached in memory
+	MaxBytes int `json:"max_bytes"`
+
+	// Hosts are the image hosts logos may be fetched from, and their
+	// subdomains
+	Hosts []string `json:"hosts"`
+}
+
+// GlobalConfig configures the market overview served by /global
+type GlobalConfig struct {
+	TTL Duration `json:"ttl"`
+}
+
+// TrendingConfig configures the trending list served by /trending
+type TrendingConfig struct {
+	TTL Duration `json:"ttl"`
+}
+
+// MarketsConfig configures the market list served by /markets
+type MarketsConfig struct {
+	TTL Duration `json:"ttl"`
+}
+
+// HistoryConfig configures price history served by /history/{id}
+type HistoryConfig struct {
+	TTL Duration `json:"ttl"`
+
+	// Dir stores daily closes on disk, filled as history is fetched and
+	// by "pricing backfill"; history is only cached in memory if empty
+	Dir string `json:"dir,omitempty"`
+}
+
+// TicksConfig configures the recorded prices TWAP and VWAP are computed
+// from
+type TicksConfig struct {
+	// Retention is how long ticks are kept, and the longest window
+	// unless they are downsampled
+	Retention Duration `json:"retention"`
+
+	// HourlyRetention keeps older ticks as hourly averages for this long;
+	// they are dropped if zero. DailyRetention then keeps older hourly
+	// averages as daily ones for this long, or forever if zero. Both are
+	// compacted every CompactInterval.
+	HourlyRetention Duration `json:"hourly_retention,omitempty"`
+	DailyRetention  Duration `json:"daily_retention,omitempty"`
+	CompactInterval Duration `json:"compact_interval"`
+
+	// Tokens are refreshed every Interval in each of Currencies so they
+	// have ticks even when no client requests them
+	Tokens     []string `json:"tokens"`
+	Currencies []string `json:"currencies"`
+	Interval   Duration `json:"interval"`
+}
+
+// StreamConfig configures price streams served by /stream
+type StreamConfig struct {
+	// MaxClients is the most streams open at once
+	MaxClients int `json:"max_clients"`
+
+	// MaxLag is how long a client may leave updates unread before its
+	// stream is closed
+	MaxLag Duration `json:"max_lag"`
+
+	// Interval between refreshes of the streamed tokens; 0 leaves streams
+	// to update only on refreshes caused by other requests
+	Interval Duration `json:"interval"`
+
+	// RedisURL relays refreshes between replicas through Redis pub/sub on
+	// RedisChannel, so streams of every replica see prices fetched by any
+	RedisURL     string `json:"redis_url,omitempty"`
+	RedisChannel string `json:"redis_channel"`
+}
+
+// ReplicaConfig runs instances in several regions off one upstream quota:
+// a primary publishes its refreshes through the stream relay's Redis
+// channel and secondaries store them in their caches, asking the primary
+// for anything they don't have
+type ReplicaConfig struct {
+	// Role is primary or secondary; empty runs standalone
+	Role string `json:"role"`
+
+	// PrimaryURL is the primary's API, which a secondary asks for prices
+	// it has not been sent
+	PrimaryURL string `json:"primary_url"`
+
+	// APIKey is the tenant key a secondary uses with the primary, if the
+	// primary requires one
+	APIKey string `json:"api_key"`
+}
+
+// AnalyticsConfig configures statistics served by /analytics
+type AnalyticsConfig struct {
+	// RiskFreeRate is the annual return Sharpe ratios are measured
+	// against, as a fraction
+	RiskFreeRate float64 `json:"risk_free_rate"`
+}
+
+// AlertsConfig configures client price alerts
+type AlertsConfig struct {
+	// File persists alerts across restarts; memory only if empty
+	File string `json:"file"`
+
+	// Interval between refreshes of the prices alerts watch; 0 leaves
+	// alerts to fire only on refreshes caused by client requests
+	Interval Duration `json:"interval"`
+
+	// TelegramBotToken enables Telegram channels, sent from this bot
+	TelegramBotToken string `json:"telegram_bot_token"`
+
+	// Cooldown is the least time between firings of an alert that
+	// doesn't set its own; 0 lets alerts fire on every crossing
+	Cooldown Duration `json:"cooldown"`
+
+	// Mute lists UTC windows in which alerts are held back, e.g.
+	// "22:00-07:00" or "sat-sun 00:00-24:00"
+	Mute []string `json:"mute"`
+
+	// RetryAttempts and RetryBackoff retry failed deliveries to channels
+	// that set no policy of their own: RetryAttempts tries in all,
+	// waiting RetryBackoff before the first retry and doubling it after
+	RetryAttempts int      `json:"retry_attempts"`
+	RetryBackoff  Duration `json:"retry_backoff"`
+
+	// DeadLetterFile persists deliveries that failed every attempt until
+	// they are replayed; memory only if empty
+	DeadLetterFile string `json:"dead_letter_file"`
+}
+
+// ReportsConfig configures the market report served by /reports/latest
+// and delivered on a schedule
+type ReportsConfig struct {
+	// Period is daily or weekly (generated Mondays)
+	Period string `json:"period"`
+
+	// Hour is the UTC hour reports are generated at
+	Hour int `json:"hour"`
+
+	Currency string `json:"currency"`
+
+	// Movers is how many gainers and losers are listed
+	Movers int `json:"movers"`
+
+	// Channels receive every scheduled report
+	Channels []ChannelConfig `json:"channels"`
+}
+
+// TreasuryConfig configures scheduled valuations of the foundation's
+// holdings
+type TreasuryConfig struct {
+	// Holdings maps token ids to the amounts held; valuations are
+	// disabled if empty
+	Holdings map[string]float64 `json:"holdings"`
+
+	Currency string `json:"currency"`
+
+	// Period is daily, weekly (recorded Mondays) or monthly (recorded on
+	// the first)
+	Period string `json:"period"`
+
+	// Hour is the UTC hour valuations are recorded at
+	Hour int `json:"hour"`
+
+	// File keeps recorded valuations across restarts; memory only if
+	// empty
+	File string `json:"file"`
+
+	// ExportURL receives every recorded valuation as JSON and CSV:
+	// s3://bucket/prefix or gs://bucket/prefix with the snapshot
+	// credentials, or file:///dir; not exported if empty
+	ExportURL string `json:"export_url"`
+
+	// Channels receive every scheduled valuation
+	Channels []ChannelConfig `json:"channels"`
+}
+
+// ExtremesConfig configures the highs and lows tracked for
+// /extremes/{token}
+type ExtremesConfig struct {
+	// File persists tracked extremes across restarts; memory only if
+	// empty
+	File string `json:"file"`
+
+	// Tokens are seeded from a year of history and refreshed every
+	// Interval in each of Currencies
+	Tokens     []string `json:"tokens"`
+	Currencies []string `json:"currencies"`
+	Interval   Duration `json:"interval"`
+
+	// Channels receive new highs and lows of Tokens
+	Channels []ChannelConfig `json:"channels"`
+}
+
+// ListingsConfig configures tracking of the coins CoinGecko lists for
+// /listings/changes
+type ListingsConfig struct {
+	// Interval between checks of the coin list; 0 disables tracking
+	Interval Duration `json:"interval"`
+
+	// File persists the coin list and changes across restarts; memory
+	// only if empty
+	File string `json:"file"`
+
+	// Retention is how long changes are kept
+	Retention Duration `json:"retention"`
+
+	// Channels receive every listing and delisting
+	Channels []ChannelConfig `json:"channels"`
+}
+
+// CategoriesConfig configures the categories tokens are tagged with on
+// /markets and listed on /categories
+type CategoriesConfig struct {
+	// Sources maps category names to the CoinGecko categories they are
+	// fetched from, e.g. defi -> decentralized-finance-defi
+	Sources map[string]string `json:"sources"`
+
+	// Parents maps categories to their parent category; a token in a
+	// category is in its ancestors too
+	Parents map[string]string `json:"parents"`
+
+	// Overrides maps token ids to categories added to CoinGecko's, or
+	// removed from them if prefixed with "-"
+	Overrides map[string][]string `json:"overrides"`
+
+	// Interval between refreshes of Sources; 0 disables fetching, leaving
+	// the categories of Overrides
+	Interval Duration `json:"interval"`
+}
+
+// AliasesConfig maps alternative token ids to the ids providers know
+type AliasesConfig struct {
+	// Tokens maps aliases to token ids, e.g. matic -> polygon-ecosystem-token
+	Tokens map[string]string `json:"tokens"`
+
+	// File persists aliases added at runtime, which override Tokens;
+	// memory only if empty
+	File string `json:"file"`
+}
+
+// OverridesConfig is where the token metadata overrides managed through
+// /admin/overrides are kept
+type OverridesConfig struct {
+	// File holds the overrides and persists changes to them; memory only
+	// if empty
+	File string `json:"file"`
+}
+
+// RoundingConfig is the display precision prices are rounded to when a
+// request asks for rounded prices
+type RoundingConfig struct {
+	// Policy lists magnitude bands as min=Nd for N decimals or min=Ns for
+	// N significant digits, e.g. 1000=2d,1=4d,0=4s; a band from 0 is
+	// required
+	Policy string `json:"policy"`
+}
+
+// FeaturesConfig selects the endpoint groups a deployment serves, such as
+// "alerts", "analytics", "admin" or "oracle". Health checks are always
+// served.
+type FeaturesConfig struct {
+	// Enabled lists the groups served; all are if empty
+	Enabled []string `json:"enabled,omitempty"`
+
+	// Disabled lists groups not served, even if Enabled lists them
+	Disabled []string `json:"disabled,omitempty"`
+}
+
+// On reports whether the feature group name is served
+func (f FeaturesConfig) On(name string) bool {
+	for _, d := range f.Disabled {
+		if d == name {
+			return false
+		}
+	}
+	if len(f.Enabled) == 0 {
+		return true
+	}
+	for _, e := range f.Enabled {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ChannelConfig is a notification channel: a webhook, Slack or Discord
+// URL, a Telegram chat or an email address
+type ChannelConfig struct {
+	Type   string `json:"type"` // webhook, slack, discord, telegram or email
+	URL    string `json:"url,omitempty"`
+	ChatID string `json:"chat_id,omitempty"`
+	Email  string `json:"email,omitempty"`
+}
+
+// EmailConfig configures SMTP delivery for email alert and report
+// channels
+type EmailConfig struct {
+	// SMTPAddr is the server's host:port; email channels are disabled if
+	// empty
+	SMTPAddr     string `json:"smtp_addr"`
+	SMTPUsername string `json:"smtp_username"`
+	SMTPPassword string `json:"smtp_password"`
+	From         string `json:"from"`
+
+	// Template is a text/template file rendering each email; a plain text
+	// digest if empty
+	Template string `json:"template"`
+
+	// Batch collects notifications to one address into a single email
+	// sent this long after the first; 0 sends each at once
+	Batch Duration `json:"batch"`
+}
+
+// SnapshotConfig configures scheduled dataset exports to object storage
+type SnapshotConfig struct {
+	// URL is the destination: s3://bucket/prefix, gs://bucket/prefix or
+	// file:///dir; exports are disabled if empty
+	URL string `json:"url"`
+
+	// Interval between snapshots, aligned to multiples of it
+	Interval Duration `json:"interval"`
+
+	// Retention deletes snapshots older than this; 0 keeps them all
+	Retention Duration `json:"retention"`
+
+	// Currency prices and markets are exported in
+	Currency string `json:"currency"`
+
+	// Markets is how many of the largest tokens by market cap to include
+	Markets int `json:"markets"`
+
+	// Endpoint overrides the S3 API root for S3-compatible services
+	Endpoint        string `json:"endpoint"`
+	Region          string `json:"region"`
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+}
+
+// Default returns the configuration used when nothing is set
+func Default() *Config {
+	return &Config{
+		Port:     "8080",
+		Provider: "coingecko",
+		Demo:     DemoConfig{Seed: 1},
+		Cache: CacheConfig{
+			TTL:          Duration{time.Hour},
+			MaxChange:    0.5,
+			NotFoundTTL:  Duration{5 * time.Minute},
+			StaleAfter:   Duration{5 * time.Minute},
+			CacheControl: map[string]string{},
+		},
+		Upstream: UpstreamConfig{
+			Concurrency:         4,
+			MaxIdleConns:        256,
+			MaxIdleConnsPerHost: 64,
+			IdleConnTimeout:     Duration{90 * time.Second},
+			HTTP2:               true,
+			RetryAttempts:       3,
+			RetryBackoff:        Duration{200 * time.Millisecond},
+			RetryMaxBackoff:     Duration{5 * time.Second},
+			BreakerFailures:     5,
+			BreakerCooldown:     Duration{30 * time.Second},
+			RateLimitPause:      Duration{time.Minute},
+			MaxInFlight:         64,
+			MaxQueued:           512,
+			Timeout:             Duration{time.Minute},
+			RequestTimeout:      Duration{10 * time.Second},
+			RouteTimeouts:       map[string]Duration{},
+			HedgeRoutes:         []string{"/price/", "/prices", "/simple/price"},
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"*"},
+		},
+		Admin: AdminConfig{
+			APIKeys: map[string]string{},
+		},
+		FX: FXConfig{
+			Source: "ecb",
+			TTL:    Duration{time.Hour},
+		},
+		Gas: GasConfig{
+			RPCs: map[string]string{
+				"ethereum": "https://ethereum-rpc.publicnode.com",
+				"lux":      "https://api.lux.network/ext/bc/C/rpc",
+			},
+			TTL: Duration{10 * time.Second},
+		},
+		TokenPrice: TokenPriceConfig{
+			Platforms: map[string]string{
+				"ethereum":  "ethereum",
+				"bsc":       "binance-smart-chain",
+				"polygon":   "polygon-pos",
+				"arbitrum":  "arbitrum-one",
+				"optimism":  "optimistic-ethereum",
+				"base":      "base",
+				"avalanche": "avalanche",
+				"solana":    "solana",
+			},
+			DEXes: map[string]DEXConfig{
+				// Uniswap V2
+				"ethereum": {
+					Factory: "0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f",
+					Quotes: []DEXQuoteConfig{
+						{Address: "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", Token: "ethereum", Decimals: 18},
+						{Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Token: "usd-coin", Decimals: 6},
+						{Address: "0xdAC17F958D2ee523a2206206994597C13D831ec7", Token: "tether", Decimals: 6},
+					},
+				},
+			},
+			MinLiquidity: 10000,
+			TTL:          Duration{time.Minute},
+		},
+		Supply: SupplyConfig{
+			Threshold: 0.02,
+			Interval:  Duration{time.Hour},
+			Changes: SupplyChangesConfig{
+				Threshold: 0.05,
+				Retention: Duration{90 * 24 * time.Hour},
+			},
+		},
+		Risk: RiskConfig{
+			SecurityURL: "https://api.gopluslabs.io/api/v1",
+			Volatility:  1.5,
+			MinVolume:   100000,
+			MaxTax:      0.1,
+			Tokens:      100,
+			Interval:    Duration{time.Hour},
+		},
+		Derivatives: DerivativesConfig{
+			TTL: Duration{5 * time.Minute},
+		},
+		NFT: NFTConfig{
+			TTL: Duration{10 * time.Minute},
+		},
+		TVL: TVLConfig{
+			TTL: Duration{10 * time.Minute},
+		},
+		Logos: LogosConfig{
+			TTL:      Duration{24 * time.Hour},
+			MaxBytes: 64 << 20,
+			Hosts:    []string{"coin-images.coingecko.com", "assets.coingecko.com"},
+		},
+		Global: GlobalConfig{
+			TTL: Duration{5 * time.Minute},
+		},
+		Trending: TrendingConfig{
+			TTL: Duration{10 * time.Minute},
+		},
+		Markets: MarketsConfig{
+			TTL: Duration{5 * time.Minute},
+		},
+		History: HistoryConfig{
+			TTL: Duration{5 * time.Minute},
+		},
+		Ticks: TicksConfig{
+			Retention:       Duration{24 * time.Hour},
+			CompactInterval: Duration{time.Hour},
+			Currencies:      []string{"usd"},
+			Interval:        Duration{time.Minute},
+		},
+		SLO: SLOConfig{
+			Availability:  0.999,
+			Latency:       Duration{time.Second},
+			LatencyTarget: 0.99,
+			Window:        Duration{24 * time.Hour},
+			// Streams stay open for as long as their clients do
+			Routes:       map[string]SLOObjective{"/stream": {Latency: &Duration{}}},
+			ShedBurnRate: 10,
+		},
+		Stream: StreamConfig{
+			MaxClients:   10000,
+			MaxLag:       Duration{30 * time.Second},
+			Interval:     Duration{10 * time.Second},
+			RedisChannel: "pricing:prices",
+		},
+		Alerts: AlertsConfig{
+			Interval:      Duration{time.Minute},
+			Cooldown:      Duration{15 * time.Minute},
+			RetryAttempts: 3,
+			RetryBackoff:  Duration{5 * time.Second},
+		},
+		Reports: ReportsConfig{
+			Period:   "daily",
+			Currency: "usd",
+			Movers:   5,
+		},
+		Treasury: TreasuryConfig{
+			Currency: "usd",
+			Period:   "monthly",
+		},
+		Extremes: ExtremesConfig{
+			Currencies: []string{"usd"},
+			Interval:   Duration{time.Minute},
+		},
+		Listings: ListingsConfig{
+			Retention: Duration{90 * 24 * time.Hour},
+		},
+		Categories: CategoriesConfig{
+			Sources: map[string]string{
+				"layer-1": "layer-1",
+				"defi":    "decentralized-finance-defi",
+				"meme":    "meme-token",
+				"ai":      "artificial-intelligence",
+			},
+			Interval: Duration{12 * time.Hour},
+		},
+		Email: EmailConfig{
+			Batch: Duration{time.Minute},
+		},
+		Rounding: RoundingConfig{
+			Policy: "1000=2d,1=4d,0.01=6d,0=4s",
+		},
+		Snapshot: SnapshotConfig{
+			Interval:  Duration{time.Hour},
+			Retention: Duration{30 * 24 * time.Hour},
+			Currency:  "usd",
+			Markets:   250,
+		},
+		Stablecoins: StablecoinsConfig{
+			Threshold: 0.005,
+			Interval:  Duration{time.Minute},
+			History:   Duration{24 * time.Hour},
+		},
+		Deviation: DeviationConfig{
+			Threshold: 0.02,
+			Interval:  Duration{time.Minute},
+		},
+		Oracle: OracleConfig{
+			Method:          "updatePrice",
+			Decimals:        8,
+			Deviation:       0.005,
+			Heartbeat:       Duration{time.Hour},
+			Interval:        Duration{30 * time.Second},
+			MaxGasPriceGwei: 100,
+			ReplaceAfter:    Duration{2 * time.Minute},
+		},
+		Bridge: BridgeConfig{
+			Currency: "usd",
+			MaxAge:   Duration{time.Minute},
+			Decimals: 8,
+		},
+		Quotes: QuotesConfig{
+			SignMethod:    "eth_signTypedData_v4",
+			DomainName:    "Lux Pricing",
+			DomainVersion: "1",
+			ChainID:       96369,
+			TTL:           Duration{time.Minute},
+			Decimals:      18,
+		},
+		Quorum: QuorumConfig{
+			Tolerance: 0.01,
+			TTL:       Duration{5 * time.Second},
+		},
+	}
+}
+
+// binding ties a setting to its environment variable and flag
+type binding struct {
+	env   string
+	flag  string
+	usage string
+	set   func(c *Config, v string) error
+}
+
+func stringSetter(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		*field(c) = v
+		return nil
+	}
+}
+
+func intSetter(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", v)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func durationSetter(field func(*Config) *Duration) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		field(c).Duration = d
+		return nil
+	}
+}
+
+func floatSetter(field func(*Config) *float64) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", v)
+		}
+		*field(c) = f
+		return nil
+	}
+}
+
+func boolSetter(field func(*Config) *bool) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", v)
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func listSetter(field func(*Config) *[]string) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		var list []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		*field(c) = list
+		return nil
+	}
+}
+
+// adminKeysSetter parses "actor:key,actor:key"; a bare key gets actor "admin"
+func adminKeysSetter(c *Config, v string) error {
+	keys := make(map[string]string)
+	for _, pair := range strings.Split(v, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		actor, key, ok := strings.Cut(pair, ":")
+		if !ok {
+			actor, key = "admin", pair
+		}
+		keys[actor] = key
+	}
+	c.Admin.APIKeys = keys
+	return nil
+}
+
+// gasRPCsSetter parses "chain=url,chain=url", replacing the configured chains
+func gasRPCsSetter(c *Config, v string) error {
+	rpcs := make(map[string]string)
+	for _, pair := range strings.Split(v, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		chain, url, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid chain=url pair %q", pair)
+		}
+		rpcs[chain] = url
+	}
+	c.Gas.RPCs = rpcs
+	return nil
+}
+
+// channelsSetter parses "type=url,telegram=chat_id,email=address",
+// replacing the channels field points to
+func channelsSetter(field func(*Config) *[]ChannelConfig) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		var channels []ChannelConfig
+		for _, pair := range strings.Split(v, ",") {
+			pair = strings.TrimSpace(pair)
+			if pair == "" {
+				continue
+			}
+			typ, target, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("invalid type=url pair %q", pair)
+			}
+			switch typ {
+			case "telegram":
+				channels = append(channels, ChannelConfig{Type: typ, ChatID: target})
+			case "email":
+				channels = append(channels, ChannelConfig{Type: typ, Email: target})
+			default:
+				channels = append(channels, ChannelConfig{Type: typ, URL: target})
+			}
+		}
+		*field(c) = channels
+		return nil
+	}
+}
+
+// routeTimeoutsSetter parses route=duration pairs, e.g.
+// /history/=20s,/portfolio/performance=30s
+func routeTimeoutsSetter(c *Config, v string) error {
+	timeouts := make(map[string]Duration)
+	for _, pair := range strings.Split(v, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		route, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid route timeout %q: want route=duration", pair)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return err
+		}
+		timeouts[strings.TrimSpace(route)] = Duration{d}
+	}
+	c.Upstream.RouteTimeouts = timeouts
+	return nil
+}
+
+// maxAgesSetter parses token=duration pairs, e.g. bitcoin=30s,ethereum=1m
+func maxAgesSetter(c *Config, v string) error {
+	pairs, err := parsePairs(v, "token=duration")
+	if err != nil {
+		return err
+	}
+	ages := make(map[string]Duration, len(pairs))
+	for token, value := range pairs {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		ages[token] = Duration{d}
+	}
+	c.Cache.MaxAges = ages
+	return nil
+}
+
+// upstreamRPMSetter parses host=n pairs, e.g. api.coingecko.com=30
+func upstreamRPMSetter(c *Config, v string) error {
+	pairs, err := parsePairs(v, "host=requests per minute")
+	if err != nil {
+		return err
+	}
+	quotas := make(map[string]int, len(pairs))
+	for host, value := range pairs {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", host, err)
+		}
+		quotas[host] = n
+	}
+	c.Upstream.RequestsPerMinute = quotas
+	return nil
+}
+
+// treasuryHoldingsSetter parses token=amount pairs, e.g.
+// lux-network=250000000,bitcoin=12.5
+func treasuryHoldingsSetter(c *Config, v string) error {
+	pairs, err := parsePairs(v, "token=amount")
+	if err != nil {
+		return err
+	}
+	holdings := make(map[string]float64, len(pairs))
+	for token, value := range pairs {
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid amount of %s: %w", token, err)
+		}
+		holdings[token] = amount
+	}
+	c.Treasury.Holdings = holdings
+	return nil
+}
+
+// aliasesSetter parses alias=id pairs, e.g.
+// avalanche=avalanche-2,matic=polygon-ecosystem-token
+func aliasesSetter(c *Config, v string) error {
+	tokens := make(map[string]string)
+	for _, pair := range strings.Split(v, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		alias, id, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid alias %q: want alias=id", pair)
+		}
+		tokens[strings.TrimSpace(alias)] = strings.TrimSpace(id)
+	}
+	c.Aliases.Tokens = tokens
+	return nil
+}
+
+// tokenPricePlatformsSetter parses chain=platform pairs, replacing the
+// configured chains
+func tokenPricePlatformsSetter(c *Config, v string) error {
+	platforms, err := parsePairs(v, "chain=platform")
+	if err != nil {
+		return err
+	}
+	c.TokenPrice.Platforms = platforms
+	return nil
+}
+
+// categoriesSetter parses name=slug pairs of categories and the CoinGecko
+// categories they are fetched from, e.g. defi=decentralized-finance-defi
+func categoriesSetter(c *Config, v string) error {
+	sources, err := parsePairs(v, "name=slug")
+	if err != nil {
+		return err
+	}
+	c.Categories.Sources = sources
+	return nil
+}
+
+// categoryParentsSetter parses child=parent pairs of categories, e.g.
+// dex=defi,lending=defi
+func categoryParentsSetter(c *Config, v string) error {
+	parents, err := parsePairs(v, "child=parent")
+	if err != nil {
+		return err
+	}
+	c.Categories.Parents = parents
+	return nil
+}
+
+// categoryOverridesSetter parses token=category pairs, a token repeated
+// for several, e.g. lux-network=layer-1,lux-network=-meme
+func categoryOverridesSetter(c *Config, v string) error {
+	overrides := make(map[string][]string)
+	for _, pair := range strings.Split(v, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		token, category, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid category override %q: want token=category", pair)
+		}
+		token = strings.TrimSpace(token)
+		overrides[token] = append(overrides[token], strings.TrimSpace(category))
+	}
+	c.Categories.Overrides = overrides
+	return nil
+}
+
+// parsePairs parses comma-separated key=value pairs; want names their
+// form in errors
+func parsePairs(v, want string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for _, pair := range strings.Split(v, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid pair %q: want %s", pair, want)
+		}
+		pairs[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return pairs, nil
+}
+
+func cacheControlSetter(endpoint string) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		if c.Cache.CacheControl == nil {
+			c.Cache.CacheControl = map[string]string{}
+		}
+		c.Cache.CacheControl[endpoint] = v
+		return nil
+	}
+}
+
+var bindings = []binding{
+	{"PORT", "port", "HTTP listen port", stringSetter(func(c *Config) *string { return &c.Port })},
+	{"PROVIDER", "provider", "price provider: coingecko, mock for fixture data or demo for moving fixture data", stringSetter(func(c *Config) *string { return &c.Provider })},
+	{"DEMO_SEED", "demo-seed", "seed of the demo provider's random walks", intSetter(func(c *Config) *int { return &c.Demo.Seed })},
+	{"DEMO_TOKENS", "demo-tokens", "comma-separated token ids the demo provider lists (fixture tokens if unset)", listSetter(func(c *Config) *[]string { return &c.Demo.Tokens })},
+	{"COINGECKO_API_KEY", "coingecko-api-key", "CoinGecko API key", stringSetter(func(c *Config) *string { return &c.CoinGecko.APIKey })},
+	{"COINGECKO_BASE_URL", "coingecko-base-url", "CoinGecko API root", stringSetter(func(c *Config) *string { return &c.CoinGecko.BaseURL })},
+	{"CACHE_TTL", "cache-ttl", "price cache TTL", durationSetter(func(c *Config) *Duration { return &c.Cache.TTL })},
+	{"CACHE_NOT_FOUND_TTL", "cache-not-found-ttl", "how long unknown tokens are answered as not found without asking upstream (0 disables)", durationSetter(func(c *Config) *Duration { return &c.Cache.NotFoundTTL })},
+	{"CACHE_MAX_CHANGE", "cache-max-change", "largest price move accepted in one refresh, as a fraction (0 disables)", floatSetter(func(c *Config) *float64 { return &c.Cache.MaxChange })},
+	{"CACHE_STALE_AFTER", "cache-stale-after", "how long upstream refreshes may fail before /status reports prices as stale", durationSetter(func(c *Config) *Duration { return &c.Cache.StaleAfter })},
+	{"CACHE_MAX_AGES", "cache-max-ages", "oldest price served per token, as token=duration pairs, e.g. bitcoin=30s", maxAgesSetter},
+	{"CACHE_CONTROL_PRICE", "cache-control-price", "Cache-Control policy for /price", cacheControlSetter("price")},
+	{"CACHE_CONTROL_PRICES", "cache-control-prices", "Cache-Control policy for /prices", cacheControlSetter("prices")},
+	{"CACHE_CONTROL_SIMPLE_PRICE", "cache-control-simple-price", "Cache-Control policy for /simple/price", cacheControlSetter("simple_price")},
+	{"UPSTREAM_CONCURRENCY", "upstream-concurrency", "concurrent requests per provider", intSetter(func(c *Config) *int { return &c.Upstream.Concurrency })},
+	{"UPSTREAM_MAX_IDLE_CONNS", "upstream-max-idle-conns", "idle upstream connections across hosts", intSetter(func(c *Config) *int { return &c.Upstream.MaxIdleConns })},
+	{"UPSTREAM_MAX_IDLE_CONNS_PER_HOST", "upstream-max-idle-conns-per-host", "idle upstream connections per host", intSetter(func(c *Config) *int { return &c.Upstream.MaxIdleConnsPerHost })},
+	{"UPSTREAM_MAX_CONNS_PER_HOST", "upstream-max-conns-per-host", "upstream connections per host (0 = unlimited)", intSetter(func(c *Config) *int { return &c.Upstream.MaxConnsPerHost })},
+	{"UPSTREAM_IDLE_CONN_TIMEOUT", "upstream-idle-conn-timeout", "how long idle upstream connections are kept", durationSetter(func(c *Config) *Duration { return &c.Upstream.IdleConnTimeout })},
+	{"UPSTREAM_HTTP2", "upstream-http2", "negotiate HTTP/2 with providers", boolSetter(func(c *Config) *bool { return &c.Upstream.HTTP2 })},
+	{"UPSTREAM_RETRY_ATTEMPTS", "upstream-retry-attempts", "most tries per upstream GET on 5xx or timeout (1 disables retries)", intSetter(func(c *Config) *int { return &c.Upstream.RetryAttempts })},
+	{"UPSTREAM_RETRY_BACKOFF", "upstream-retry-backoff", "longest wait before the first upstream retry, doubling per retry", durationSetter(func(c *Config) *Duration { return &c.Upstream.RetryBackoff })},
+	{"UPSTREAM_RETRY_MAX_BACKOFF", "upstream-retry-max-backoff", "longest wait between upstream retries", durationSetter(func(c *Config) *Duration { return &c.Upstream.RetryMaxBackoff })},
+	{"UPSTREAM_BREAKER_FAILURES", "upstream-breaker-failures", "consecutive provider failures that open its circuit (0 disables)", intSetter(func(c *Config) *int { return &c.Upstream.BreakerFailures })},
+	{"UPSTREAM_BREAKER_COOLDOWN", "upstream-breaker-cooldown", "how long an open circuit fails fast before probing", durationSetter(func(c *Config) *Duration { return &c.Upstream.BreakerCooldown })},
+	{"UPSTREAM_RATE_LIMIT_PAUSE", "upstream-rate-limit-pause", "how long to pause a provider after a 429 without Retry-After", durationSetter(func(c *Config) *Duration { return &c.Upstream.RateLimitPause })},
+	{"UPSTREAM_MAX_IN_FLIGHT", "upstream-max-in-flight", "requests in flight to all providers together (0 disables the cap)", intSetter(func(c *Config) *int { return &c.Upstream.MaxInFlight })},
+	{"UPSTREAM_MAX_QUEUED", "upstream-max-queued", "upstream requests that may wait for a free slot before new ones are refused", intSetter(func(c *Config) *int { return &c.Upstream.MaxQueued })},
+	{"UPSTREAM_RPM", "upstream-rpm", "requests per minute sent to each provider host, as host=n pairs, e.g. api.coingecko.com=30", upstreamRPMSetter},
+	{"UPSTREAM_TIMEOUT", "upstream-timeout", "longest any upstream call may take, including background jobs", durationSetter(func(c *Config) *Duration { return &c.Upstream.Timeout })},
+	{"UPSTREAM_REQUEST_TIMEOUT", "upstream-request-timeout", "upstream time budget of an API request", durationSetter(func(c *Config) *Duration { return &c.Upstream.RequestTimeout })},
+	{"UPSTREAM_ROUTE_TIMEOUTS", "upstream-route-timeouts", "per-route upstream budgets as route=duration pairs, e.g. /history/=20s", routeTimeoutsSetter},
+	{"UPSTREAM_HEDGE_ROUTES", "upstream-hedge-routes", "route patterns whose provider calls are hedged across alternate providers, comma separated", listSetter(func(c *Config) *[]string { return &c.Upstream.HedgeRoutes })},
+	{"UPSTREAM_HEDGE_AFTER", "upstream-hedge-after", "how long a hedged call waits for the fastest provider (0 = its p95 latency)", durationSetter(func(c *Config) *Duration { return &c.Upstream.HedgeAfter })},
+	{"UPSTREAM_RECORD", "upstream-record", "directory every upstream response is recorded into", stringSetter(func(c *Config) *string { return &c.Upstream.Record })},
+	{"UPSTREAM_REPLAY", "upstream-replay", "directory of recorded upstream responses to serve instead of the network", stringSetter(func(c *Config) *string { return &c.Upstream.Replay })},
+	{"SLO_AVAILABILITY", "slo-availability", "fraction of each route's requests that must not fail with a 5xx", floatSetter(func(c *Config) *float64 { return &c.SLO.Availability })},
+	{"SLO_LATENCY", "slo-latency", "response time SLO_LATENCY_TARGET of each route's requests must beat", durationSetter(func(c *Config) *Duration { return &c.SLO.Latency })},
+	{"SLO_LATENCY_TARGET", "slo-latency-target", "fraction of each route's requests that must beat SLO_LATENCY", floatSetter(func(c *Config) *float64 { return &c.SLO.LatencyTarget })},
+	{"SLO_WINDOW", "slo-window", "rolling window objectives are measured over (0 disables tracking)", durationSetter(func(c *Config) *Duration { return &c.SLO.Window })},
+	{"SLO_SHED", "slo-shed", "comma-separated feature groups and route patterns shed while another route burns its error budget, e.g. analytics,/reports/latest", listSetter(func(c *Config) *[]string { return &c.SLO.Shed })},
+	{"SLO_SHED_BURN_RATE", "slo-shed-burn-rate", "error budget burn rate over the last 5 minutes that sheds SLO_SHED", floatSetter(func(c *Config) *float64 { return &c.SLO.ShedBurnRate })},
+	{"CORS_ALLOWED_ORIGINS", "cors-allowed-origins", "comma-separated allowed CORS origins (* for any)", listSetter(func(c *Config) *[]string { return &c.CORS.AllowedOrigins })},
+	{"RATE_LIMIT_RPM", "rate-limit-rpm", "requests per minute without an API key (0 = unlimited)", intSetter(func(c *Config) *int { return &c.RateLimit.RequestsPerMinute })},
+	{"RATE_LIMIT_BURST", "rate-limit-burst", "burst size for requests without an API key", intSetter(func(c *Config) *int { return &c.RateLimit.Burst })},
+	{"ADMIN_API_KEYS", "admin-api-keys", "admin keys as actor:key pairs", adminKeysSetter},
+	{"AUDIT_LOG_PATH", "audit-log-path", "append-only audit log file", stringSetter(func(c *Config) *string { return &c.Admin.AuditLogPath })},
+	{"ACCESS_LOG", "access-log", "log one line per request", boolSetter(func(c *Config) *bool { return &c.AccessLog })},
+	{"TENANTS_FILE", "tenants-file", "JSON file of tenant policies", stringSetter(func(c *Config) *string { return &c.TenantsFile })},
+	{"SIGNING_KEY", "signing-key", "hex Ed25519 seed for signed responses", stringSetter(func(c *Config) *string { return &c.SigningKey })},
+	{"FX_SOURCE", "fx-source", "exchange rate source: ecb, exchangerate.host or none", stringSetter(func(c *Config) *string { return &c.FX.Source })},
+	{"FX_API_KEY", "fx-api-key", "exchange rate source API key", stringSetter(func(c *Config) *string { return &c.FX.APIKey })},
+	{"FX_BASE_URL", "fx-base-url", "exchange rate source URL", stringSetter(func(c *Config) *string { return &c.FX.BaseURL })},
+	{"FX_TTL", "fx-ttl", "how long exchange rates are reused", durationSetter(func(c *Config) *Duration { return &c.FX.TTL })},
+	{"FX_NORMALIZE", "fx-normalize", "derive every fiat currency from USD prices, not only those the provider doesn't quote", boolSetter(func(c *Config) *bool { return &c.FX.Normalize })},
+	{"METALS_API_KEY", "metals-api-key", "metals-api.com API key (enables xau, xag, oil)", stringSetter(func(c *Config) *string { return &c.Metals.APIKey })},
+	{"METALS_BASE_URL", "metals-base-url", "metals-api.com API root", stringSetter(func(c *Config) *string { return &c.Metals.BaseURL })},
+	{"STABLECOIN_INTERVAL", "stablecoin-interval", "stablecoin peg poll interval (0 disables)", durationSetter(func(c *Config) *Duration { return &c.Stablecoins.Interval })},
+	{"STABLECOIN_THRESHOLD", "stablecoin-threshold", "deviation from peg that counts as a depeg", floatSetter(func(c *Config) *float64 { return &c.Stablecoins.Threshold })},
+	{"STABLECOIN_WEBHOOKS", "stablecoin-webhooks", "comma-separated URLs notified on depeg and repeg", listSetter(func(c *Config) *[]string { return &c.Stablecoins.Webhooks })},
+	{"DEVIATION_TOKENS", "deviation-tokens", "comma-separated tokens compared across providers", listSetter(func(c *Config) *[]string { return &c.Deviation.Tokens })},
+	{"DEVIATION_THRESHOLD", "deviation-threshold", "deviation between providers that raises an alarm", floatSetter(func(c *Config) *float64 { return &c.Deviation.Threshold })},
+	{"DEVIATION_INTERVAL", "deviation-interval", "provider comparison interval (0 disables)", durationSetter(func(c *Config) *Duration { return &c.Deviation.Interval })},
+	{"DEVIATION_WEBHOOKS", "deviation-webhooks", "comma-separated URLs notified of deviation alarms", listSetter(func(c *Config) *[]string { return &c.Deviation.Webhooks })},
+	{"ORACLE_RPC_URL", "oracle-rpc-url", "JSON-RPC endpoint oracle updates are sent through", stringSetter(func(c *Config) *string { return &c.Oracle.RPCURL })},
+	{"ORACLE_CONTRACT", "oracle-contract", "oracle contract address prices are pushed to (empty disables)", stringSetter(func(c *Config) *string { return &c.Oracle.Contract })},
+	{"ORACLE_FROM", "oracle-from", "feeder account the RPC endpoint signs oracle updates for", stringSetter(func(c *Config) *string { return &c.Oracle.From })},
+	{"ORACLE_METHOD", "oracle-method", "oracle contract function taking (bytes32,int256,uint256)", stringSetter(func(c *Config) *string { return &c.Oracle.Method })},
+	{"ORACLE_FEEDS", "oracle-feeds", "comma-separated token/currency feeds pushed on-chain", listSetter(func(c *Config) *[]string { return &c.Oracle.Feeds })},
+	{"ORACLE_DECIMALS", "oracle-decimals", "decimals of pushed answers", intSetter(func(c *Config) *int { return &c.Oracle.Decimals })},
+	{"ORACLE_DEVIATION", "oracle-deviation", "price move that triggers an oracle update", floatSetter(func(c *Config) *float64 { return &c.Oracle.Deviation })},
+	{"ORACLE_HEARTBEAT", "oracle-heartbeat", "longest time between oracle updates of a feed", durationSetter(func(c *Config) *Duration { return &c.Oracle.Heartbeat })},
+	{"ORACLE_INTERVAL", "oracle-interval", "oracle price check interval", durationSetter(func(c *Config) *Duration { return &c.Oracle.Interval })},
+	{"ORACLE_MAX_GAS_PRICE_GWEI", "oracle-max-gas-price-gwei", "gas price above which oracle updates wait (0 is no cap)", floatSetter(func(c *Config) *float64 { return &c.Oracle.MaxGasPriceGwei })},
+	{"ORACLE_GAS_LIMIT", "oracle-gas-limit", "gas per oracle update (0 estimates)", intSetter(func(c *Config) *int { return &c.Oracle.GasLimit })},
+	{"ORACLE_REPLACE_AFTER", "oracle-replace-after", "how long an oracle update may stay unmined before it is resent with more gas", durationSetter(func(c *Config) *Duration { return &c.Oracle.ReplaceAfter })},
+	{"BRIDGE_PAIRS", "bridge-pairs", "comma-separated base/quote pairs served to the bridge", listSetter(func(c *Config) *[]string { return &c.Bridge.Pairs })},
+	{"BRIDGE_CURRENCY", "bridge-currency", "currency bridge pairs are priced through", stringSetter(func(c *Config) *string { return &c.Bridge.Currency })},
+	{"BRIDGE_MAX_AGE", "bridge-max-age", "oldest price a bridge rate may be derived from", durationSetter(func(c *Config) *Duration { return &c.Bridge.MaxAge })},
+	{"BRIDGE_DECIMALS", "bridge-decimals", "decimals bridge rates are rounded down to", intSetter(func(c *Config) *int { return &c.Bridge.Decimals })},
+	{"QUOTE_SIGNER", "quote-signer", "address EIP-712 quotes are signed by (empty disables signing)", stringSetter(func(c *Config) *string { return &c.Quotes.Signer })},
+	{"QUOTE_SIGNER_URL", "quote-signer-url", "JSON-RPC signer holding the quote signer key", stringSetter(func(c *Config) *string { return &c.Quotes.SignerURL })},
+	{"QUOTE_SIGN_METHOD", "quote-sign-method", "JSON-RPC method quotes are signed with", stringSetter(func(c *Config) *string { return &c.Quotes.SignMethod })},
+	{"QUOTE_DOMAIN_NAME", "quote-domain-name", "EIP-712 domain name of quotes", stringSetter(func(c *Config) *string { return &c.Quotes.DomainName })},
+	{"QUOTE_DOMAIN_VERSION", "quote-domain-version", "EIP-712 domain version of quotes", stringSetter(func(c *Config) *string { return &c.Quotes.DomainVersion })},
+	{"QUOTE_CHAIN_ID", "quote-chain-id", "chain id in the EIP-712 domain of quotes", intSetter(func(c *Config) *int { return &c.Quotes.ChainID })},
+	{"QUOTE_VERIFYING_CONTRACT", "quote-verifying-contract", "contract address in the EIP-712 domain of quotes (optional)", stringSetter(func(c *Config) *string { return &c.Quotes.VerifyingContract })},
+	{"QUOTE_TTL", "quote-ttl", "how long a signed quote is valid", durationSetter(func(c *Config) *Duration { return &c.Quotes.TTL })},
+	{"QUOTE_DECIMALS", "quote-decimals", "decimals of quoted prices", intSetter(func(c *Config) *int { return &c.Quotes.Decimals })},
+	{"QUORUM_MIN", "quorum-min", "providers that must agree on quote and oracle prices (0 disables)", intSetter(func(c *Config) *int { return &c.Quorum.Min })},
+	{"QUORUM_PROVIDERS", "quorum-providers", "comma-separated providers counted towards the quorum (empty counts all)", listSetter(func(c *Config) *[]string { return &c.Quorum.Providers })},
+	{"QUORUM_TOLERANCE", "quorum-tolerance", "deviation from the median within which providers agree", floatSetter(func(c *Config) *float64 { return &c.Quorum.Tolerance })},
+	{"QUORUM_TTL", "quorum-ttl", "how long an agreed price is reused", durationSetter(func(c *Config) *Duration { return &c.Quorum.TTL })},
+	{"GAS_RPCS", "gas-rpcs", "chain=url JSON-RPC endpoints for gas estimates", gasRPCsSetter},
+	{"GAS_TTL", "gas-ttl", "how long gas estimates are cached", durationSetter(func(c *Config) *Duration { return &c.Gas.TTL })},
+	{"TOKEN_PRICE_PLATFORMS", "token-price-platforms", "CoinGecko asset platforms of chains as chain=platform pairs, e.g. bsc=binance-smart-chain", tokenPricePlatformsSetter},
+	{"TOKEN_PRICE_MIN_LIQUIDITY", "token-price-min-liquidity", "least USD value of a DEX pool contract prices are read from", floatSetter(func(c *Config) *float64 { return &c.TokenPrice.MinLiquidity })},
+	{"TOKEN_PRICE_TTL", "token-price-ttl", "how long contract prices are cached", durationSetter(func(c *Config) *Duration { return &c.TokenPrice.TTL })},
+	{"SUPPLY_THRESHOLD", "supply-threshold", "relative difference between reported and on-chain supply flagged as a discrepancy", floatSetter(func(c *Config) *float64 { return &c.Supply.Threshold })},
+	{"SUPPLY_INTERVAL", "supply-interval", "how often token supply is verified on chain", durationSetter(func(c *Config) *Duration { return &c.Supply.Interval })},
+	{"SUPPLY_CHANGES_INTERVAL", "supply-changes-interval", "how often circulating supply is checked for day-over-day changes (0 disables)", durationSetter(func(c *Config) *Duration { return &c.Supply.Changes.Interval })},
+	{"SUPPLY_CHANGES_THRESHOLD", "supply-changes-threshold", "relative day-over-day change of circulating supply alerted on", floatSetter(func(c *Config) *float64 { return &c.Supply.Changes.Threshold })},
+	{"SUPPLY_CHANGES_TOKENS", "supply-changes-tokens", "comma-separated tokens whose supply changes are tracked besides the largest by market cap", listSetter(func(c *Config) *[]string { return &c.Supply.Changes.Tokens })},
+	{"SUPPLY_CHANGES_FILE", "supply-changes-file", "JSON file daily circulating supply persists in (memory only if unset)", stringSetter(func(c *Config) *string { return &c.Supply.Changes.File })},
+	{"SUPPLY_CHANGES_RETENTION", "supply-changes-retention", "how long daily circulating supply is kept", durationSetter(func(c *Config) *Duration { return &c.Supply.Changes.Retention })},
+	{"SUPPLY_CHANGES_CHANNELS", "supply-changes-channels", "channels notified of supply changes beyond the threshold as type=url pairs (telegram=chat_id, email=address)", channelsSetter(func(c *Config) *[]ChannelConfig { return &c.Supply.Changes.Channels })},
+	{"RISK_INTERVAL", "risk-interval", "how often tokens are scanned for risk flags (0 disables)", durationSetter(func(c *Config) *Duration { return &c.Risk.Interval })},
+	{"RISK_SECURITY_URL", "risk-security-url", "GoPlus-compatible token security API contracts are checked with", stringSetter(func(c *Config) *string { return &c.Risk.SecurityURL })},
+	{"RISK_VOLATILITY", "risk-volatility", "annualized 30-day volatility flagged as extreme", floatSetter(func(c *Config) *float64 { return &c.Risk.Volatility })},
+	{"RISK_MIN_VOLUME", "risk-min-volume", "24h USD volume below which liquidity is flagged as low", floatSetter(func(c *Config) *float64 { return &c.Risk.MinVolume })},
+	{"RISK_MAX_TAX", "risk-max-tax", "buy or sell tax of a token contract flagged as a risk", floatSetter(func(c *Config) *float64 { return &c.Risk.MaxTax })},
+	{"RISK_TOKENS", "risk-tokens", "how many of the largest tokens by market cap are scanned for risks", intSetter(func(c *Config) *int { return &c.Risk.Tokens })},
+	{"DERIVATIVES_TTL", "derivatives-ttl", "how long funding and open interest data is cached", durationSetter(func(c *Config) *Duration { return &c.Derivatives.TTL })},
+	{"NFT_TTL", "nft-ttl", "how long NFT collection data is cached", durationSetter(func(c *Config) *Duration { return &c.NFT.TTL })},
+	{"DEFILLAMA_BASE_URL", "defillama-base-url", "DefiLlama API root", stringSetter(func(c *Config) *string { return &c.TVL.BaseURL })},
+	{"TVL_TTL", "tvl-ttl", "how long DefiLlama TVL data is cached", durationSetter(func(c *Config) *Duration { return &c.TVL.TTL })},
+	{"LOGOS_TTL", "logos-ttl", "how long token logos are served before they are refetched", durationSetter(func(c *Config) *Duration { return &c.Logos.TTL })},
+	{"LOGOS_MAX_BYTES", "logos-max-bytes", "memory token logos are cached in, in bytes", intSetter(func(c *Config) *int { return &c.Logos.MaxBytes })},
+	{"LOGOS_HOSTS", "logos-hosts", "comma-separated image hosts token logos may be fetched from", listSetter(func(c *Config) *[]string { return &c.Logos.Hosts })},
+	{"GLOBAL_TTL", "global-ttl", "how long the market overview is cached", durationSetter(func(c *Config) *Duration { return &c.Global.TTL })},
+	{"TRENDING_TTL", "trending-ttl", "how long the trending list is cached", durationSetter(func(c *Config) *Duration { return &c.Trending.TTL })},
+	{"MARKETS_TTL", "markets-ttl", "how long the market list is cached", durationSetter(func(c *Config) *Duration { return &c.Markets.TTL })},
+	{"HISTORY_TTL", "history-ttl", "how long price history is cached", durationSetter(func(c *Config) *Duration { return &c.History.TTL })},
+	{"HISTORY_DIR", "history-dir", "directory storing daily price history, filled by pricing backfill", stringSetter(func(c *Config) *string { return &c.History.Dir })},
+	{"TICKS_RETENTION", "ticks-retention", "how long prices are kept for TWAP and VWAP", durationSetter(func(c *Config) *Duration { return &c.Ticks.Retention })},
+	{"TICKS_TOKENS", "ticks-tokens", "comma-separated tokens refreshed every TICKS_INTERVAL for TWAP and VWAP", listSetter(func(c *Config) *[]string { return &c.Ticks.Tokens })},
+	{"TICKS_CURRENCIES", "ticks-currencies", "comma-separated currencies TICKS_TOKENS are refreshed in", listSetter(func(c *Config) *[]string { return &c.Ticks.Currencies })},
+	{"TICKS_INTERVAL", "ticks-interval", "how often TICKS_TOKENS are refreshed", durationSetter(func(c *Config) *Duration { return &c.Ticks.Interval })},
+	{"TICKS_HOURLY_RETENTION", "ticks-hourly-retention", "how long ticks past TICKS_RETENTION are kept as hourly averages (0 drops them)", durationSetter(func(c *Config) *Duration { return &c.Ticks.HourlyRetention })},
+	{"TICKS_DAILY_RETENTION", "ticks-daily-retention", "how long hourly averages past TICKS_HOURLY_RETENTION are kept as daily averages (0 keeps them forever)", durationSetter(func(c *Config) *Duration { return &c.Ticks.DailyRetention })},
+	{"TICKS_COMPACT_INTERVAL", "ticks-compact-interval", "how often ticks are downsampled", durationSetter(func(c *Config) *Duration { return &c.Ticks.CompactInterval })},
+	{"STREAM_MAX_CLIENTS", "stream-max-clients", "most price streams open at once", intSetter(func(c *Config) *int { return &c.Stream.MaxClients })},
+	{"STREAM_MAX_LAG", "stream-max-lag", "how long a stream client may leave updates unread before it is disconnected", durationSetter(func(c *Config) *Duration { return &c.Stream.MaxLag })},
+	{"STREAM_INTERVAL", "stream-interval", "how often streamed tokens are refreshed (0 disables)", durationSetter(func(c *Config) *Duration { return &c.Stream.Interval })},
+	{"STREAM_REDIS_URL", "stream-redis-url", "Redis server refreshes are relayed between replicas through, e.g. redis://:password@redis:6379", stringSetter(func(c *Config) *string { return &c.Stream.RedisURL })},
+	{"STREAM_REDIS_CHANNEL", "stream-redis-channel", "Redis pub/sub channel refreshes are relayed on", stringSetter(func(c *Config) *string { return &c.Stream.RedisChannel })},
+	{"REPLICA_ROLE", "replica-role", "primary or secondary to replicate the cache between regions through STREAM_REDIS_URL (empty runs standalone)", stringSetter(func(c *Config) *string { return &c.Replica.Role })},
+	{"REPLICA_PRIMARY_URL", "replica-primary-url", "API of the primary a secondary asks for prices it has not been sent", stringSetter(func(c *Config) *string { return &c.Replica.PrimaryURL })},
+	{"REPLICA_API_KEY", "replica-api-key", "tenant key a secondary uses with the primary", stringSetter(func(c *Config) *string { return &c.Replica.APIKey })},
+	{"ANALYTICS_RISK_FREE_RATE", "analytics-risk-free-rate", "annual risk-free rate Sharpe ratios are measured against, as a fraction", floatSetter(func(c *Config) *float64 { return &c.Analytics.RiskFreeRate })},
+	{"ALERTS_FILE", "alerts-file", "JSON file price alerts are persisted to", stringSetter(func(c *Config) *string { return &c.Alerts.File })},
+	{"ALERTS_INTERVAL", "alerts-interval", "how often prices watched by alerts are refreshed (0 disables)", durationSetter(func(c *Config) *Duration { return &c.Alerts.Interval })},
+	{"ALERTS_COOLDOWN", "alerts-cooldown", "least time between firings of an alert (0 disables)", durationSetter(func(c *Config) *Duration { return &c.Alerts.Cooldown })},
+	{"ALERTS_MUTE", "alerts-mute", "comma-separated UTC windows alerts are muted in, e.g. 22:00-07:00 or sat-sun 00:00-24:00", listSetter(func(c *Config) *[]string { return &c.Alerts.Mute })},
+	{"ALERTS_RETRY_ATTEMPTS", "alerts-retry-attempts", "tries of a channel delivery in all, unless the channel sets its own", intSetter(func(c *Config) *int { return &c.Alerts.RetryAttempts })},
+	{"ALERTS_RETRY_BACKOFF", "alerts-retry-backoff", "wait before retrying a failed delivery, doubling after each retry", durationSetter(func(c *Config) *Duration { return &c.Alerts.RetryBackoff })},
+	{"ALERTS_DEAD_LETTER_FILE", "alerts-dead-letter-file", "JSON file failed deliveries are kept in until replayed", stringSetter(func(c *Config) *string { return &c.Alerts.DeadLetterFile })},
+	{"REPORT_PERIOD", "report-period", "market report period: daily or weekly", stringSetter(func(c *Config) *string { return &c.Reports.Period })},
+	{"REPORT_HOUR", "report-hour", "UTC hour market reports are generated at", intSetter(func(c *Config) *int { return &c.Reports.Hour })},
+	{"REPORT_CURRENCY", "report-currency", "currency market reports are written in", stringSetter(func(c *Config) *string { return &c.Reports.Currency })},
+	{"REPORT_MOVERS", "report-movers", "gainers and losers listed in market reports", intSetter(func(c *Config) *int { return &c.Reports.Movers })},
+	{"REPORT_CHANNELS", "report-channels", "channels receiving market reports as type=url pairs (telegram=chat_id, email=address)", channelsSetter(func(c *Config) *[]ChannelConfig { return &c.Reports.Channels })},
+	{"TREASURY_HOLDINGS", "treasury-holdings", "treasury holdings valued on a schedule, as token=amount pairs (empty disables)", treasuryHoldingsSetter},
+	{"TREASURY_CURRENCY", "treasury-currency", "currency treasury valuations are made in", stringSetter(func(c *Config) *string { return &c.Treasury.Currency })},
+	{"TREASURY_PERIOD", "treasury-period", "treasury valuation period: daily, weekly or monthly", stringSetter(func(c *Config) *string { return &c.Treasury.Period })},
+	{"TREASURY_HOUR", "treasury-hour", "UTC hour treasury valuations are recorded at", intSetter(func(c *Config) *int { return &c.Treasury.Hour })},
+	{"TREASURY_FILE", "treasury-file", "JSON file recorded treasury valuations persist in (memory only if unset)", stringSetter(func(c *Config) *string { return &c.Treasury.File })},
+	{"TREASURY_EXPORT_URL", "treasury-export-url", "destination of treasury valuations as JSON and CSV: s3://bucket/prefix, gs://bucket/prefix or file:///dir", stringSetter(func(c *Config) *string { return &c.Treasury.ExportURL })},
+	{"TREASURY_CHANNELS", "treasury-channels", "channels receiving treasury valuations as type=url pairs (telegram=chat_id, email=address)", channelsSetter(func(c *Config) *[]ChannelConfig { return &c.Treasury.Channels })},
+	{"EXTREMES_FILE", "extremes-file", "JSON file tracked highs and lows persist in (memory only if unset)", stringSetter(func(c *Config) *string { return &c.Extremes.File })},
+	{"EXTREMES_TOKENS", "extremes-tokens", "comma-separated tokens whose highs and lows are tracked", listSetter(func(c *Config) *[]string { return &c.Extremes.Tokens })},
+	{"EXTREMES_CURRENCIES", "extremes-currencies", "comma-separated currencies EXTREMES_TOKENS are tracked in", listSetter(func(c *Config) *[]string { return &c.Extremes.Currencies })},
+	{"EXTREMES_INTERVAL", "extremes-interval", "how often EXTREMES_TOKENS are refreshed", durationSetter(func(c *Config) *Duration { return &c.Extremes.Interval })},
+	{"EXTREMES_CHANNELS", "extremes-channels", "channels notified of new highs and lows as type=url pairs (telegram=chat_id, email=address)", channelsSetter(func(c *Config) *[]ChannelConfig { return &c.Extremes.Channels })},
+	{"LISTINGS_INTERVAL", "listings-interval", "how often the CoinGecko coin list is checked for listings and delistings (0 disables)", durationSetter(func(c *Config) *Duration { return &c.Listings.Interval })},
+	{"LISTINGS_FILE", "listings-file", "JSON file the coin list and listing changes persist in (memory only if unset)", stringSetter(func(c *Config) *string { return &c.Listings.File })},
+	{"LISTINGS_RETENTION", "listings-retention", "how long listing changes are kept", durationSetter(func(c *Config) *Duration { return &c.Listings.Retention })},
+	{"LISTINGS_CHANNELS", "listings-channels", "channels notified of listings and delistings as type=url pairs (telegram=chat_id, email=address)", channelsSetter(func(c *Config) *[]ChannelConfig { return &c.Listings.Channels })},
+	{"ALIASES", "aliases", "token id aliases as alias=id pairs, e.g. matic=polygon-ecosystem-token", aliasesSetter},
+	{"CATEGORIES", "categories", "categories fetched from CoinGecko as name=slug pairs, e.g. defi=decentralized-finance-defi", categoriesSetter},
+	{"CATEGORY_PARENTS", "category-parents", "category hierarchy as child=parent pairs, e.g. dex=defi", categoryParentsSetter},
+	{"CATEGORY_OVERRIDES", "category-overrides", "token categories as token=category pairs, or token=-category to remove one", categoryOverridesSetter},
+	{"CATEGORIES_INTERVAL", "categories-interval", "how often categories are fetched from CoinGecko (0 disables)", durationSetter(func(c *Config) *Duration { return &c.Categories.Interval })},
+	{"ALIASES_FILE", "aliases-file", "JSON file aliases added at runtime persist in (memory only if unset)", stringSetter(func(c *Config) *string { return &c.Aliases.File })},
+	{"OVERRIDES_FILE", "overrides-file", "JSON file of token metadata overrides, kept up to date with /admin/overrides (memory only if unset)", stringSetter(func(c *Config) *string { return &c.Overrides.File })},
+	{"ROUNDING_POLICY", "rounding-policy", "display precision of ?rounded=true prices by magnitude, e.g. 1000=2d,1=4d,0=4s for decimals (d) or significant digits (s)", stringSetter(func(c *Config) *string { return &c.Rounding.Policy })},
+	{"SMTP_ADDR", "smtp-addr", "SMTP server host:port for email channels", stringSetter(func(c *Config) *string { return &c.Email.SMTPAddr })},
+	{"SMTP_USERNAME", "smtp-username", "SMTP username", stringSetter(func(c *Config) *string { return &c.Email.SMTPUsername })},
+	{"SMTP_PASSWORD", "smtp-password", "SMTP password", stringSetter(func(c *Config) *string { return &c.Email.SMTPPassword })},
+	{"EMAIL_FROM", "email-from", "sender address of alert and report emails", stringSetter(func(c *Config) *string { return &c.Email.From })},
+	{"EMAIL_TEMPLATE", "email-template", "text/template file rendering alert and report emails", stringSetter(func(c *Config) *string { return &c.Email.Template })},
+	{"EMAIL_BATCH", "email-batch", "how long notifications to one address are collected into one email (0 disables)", durationSetter(func(c *Config) *Duration { return &c.Email.Batch })},
+	{"SNAPSHOT_URL", "snapshot-url", "snapshot destination: s3://bucket/prefix, gs://bucket/prefix or file:///dir", stringSetter(func(c *Config) *string { return &c.Snapshot.URL })},
+	{"SNAPSHOT_INTERVAL", "snapshot-interval", "time between snapshots", durationSetter(func(c *Config) *Duration { return &c.Snapshot.Interval })},
+	{"SNAPSHOT_RETENTION", "snapshot-retention", "how long snapshots are kept (0 keeps all)", durationSetter(func(c *Config) *Duration { return &c.Snapshot.Retention })},
+	{"SNAPSHOT_CURRENCY", "snapshot-currency", "currency snapshots are exported in", stringSetter(func(c *Config) *string { return &c.Snapshot.Currency })},
+	{"SNAPSHOT_MARKETS", "snapshot-markets", "largest tokens by market cap included in snapshots", intSetter(func(c *Config) *int { return &c.Snapshot.Markets })},
+	{"SNAPSHOT_ENDPOINT", "snapshot-endpoint", "S3-compatible API root for snapshots", stringSetter(func(c *Config) *string { return &c.Snapshot.Endpoint })},
+	{"SNAPSHOT_REGION", "snapshot-region", "S3 region for snapshots", stringSetter(func(c *Config) *string { return &c.Snapshot.Region })},
+	{"SNAPSHOT_ACCESS_KEY_ID", "snapshot-access-key-id", "S3 access key id, or GCS HMAC key id", stringSetter(func(c *Config) *string { return &c.Snapshot.AccessKeyID })},
+	{"SNAPSHOT_SECRET_ACCESS_KEY", "snapshot-secret-access-key", "S3 secret access key, or GCS HMAC secret", stringSetter(func(c *Config) *string { return &c.Snapshot.SecretAccessKey })},
+	{"TELEGRAM_BOT_TOKEN", "telegram-bot-token", "Telegram bot token for alert channels", stringSetter(func(c *Config) *string { return &c.Alerts.TelegramBotToken })},
+	{"FEATURES_ENABLED", "features-enabled", "comma-separated endpoint groups served (all if unset)", listSetter(func(c *Config) *[]string { return &c.Features.Enabled })},
+	{"FEATURES_DISABLED", "features-disabled", "comma-separated endpoint groups not served, e.g. alerts,admin", listSetter(func(c *Config) *[]string { return &c.Features.Disabled })},
+	{"LEGACY_SUNSET", "legacy-sunset", "date (YYYY-MM-DD) unversioned routes are removed", stringSetter(func(c *Config) *string { return &c.LegacySunset })},
+}
+
+// Loader collects configuration flags registered on a FlagSet
+type Loader struct {
+	file    string
+	pending []func(*Config) error
+}
+
+// Bind registers -config and one flag per setting on fs. Call Load after
+// fs.Parse.
+func Bind(fs *flag.FlagSet) *Loader {
+	l := &Loader{}
+	fs.StringVar(&l.file, "config", os.Getenv("PRICING_CONFIG"), "JSON or TOML (.toml) config file (env PRICING_CONFIG)")
+	for _, b := range bindings {
+		b := b
+		fs.Func(b.flag, fmt.Sprintf("%s (env %s)", b.usage, b.env), func(v string) error {
+			l.pending = append(l.pending, func(c *Config) error {
+				if err := b.set(c, v); err != nil {
+					return fmt.Errorf("-%s: %w", b.flag, err)
+				}
+				return nil
+			})
+			return nil
+		})
+	}
+	return l
+}
+
+// File returns the config file path, or "" if none was given
+func (l *Loader) File() string {
+	return l.file
+}
+
+// Load builds the configuration from defaults, the config file, the
+// environment and parsed flags, then validates it
+func (l *Loader) Load() (*Config, error) {
+	cfg := Default()
+
+	if l.file != "" {
+		data, err := os.ReadFile(l.file)
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(filepath.Ext(l.file), ".toml") {
+			if data, err = tomlToJSON(data); err != nil {
+				return nil, fmt.Errorf("config file %s: %w", l.file, err)
+			}
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("config file %s: %w", l.file, err)
+		}
+	}
+
+	for _, b := range bindings {
+		if v, ok := os.LookupEnv(b.env); ok && v != "" {
+			if err := b.set(cfg, v); err != nil {
+				return nil, fmt.Errorf("%s: %w", b.env, err)
+			}
+		}
+	}
+
+	for _, apply := range l.pending {
+		if err := apply(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks the configuration for errors, reporting all of them
+func (c *Config) Validate() error {
+	var errs []error
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("port: %q is not a valid port", c.Port))
+	}
+	switch c.Provider {
+	case "coingecko", "mock", "demo":
+	default:
+		errs = append(errs, fmt.Errorf("provider: %q is not coingecko, mock or demo", c.Provider))
+	}
+	if c.CoinGecko.APIKey == "" && c.Provider == "coingecko" {
+		errs = append(errs, errors.New("coingecko.api_key: required (COINGECKO_API_KEY)"))
+	}
+	if u := c.CoinGecko.BaseURL; u != "" && !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		errs = append(errs, fmt.Errorf("coingecko.base_url: %q is not an http(s) URL", u))
+	}
+	if c.Cache.TTL.Duration <= 0 {
+		errs = append(errs, errors.New("cache.ttl: must be positive"))
+	}
+	if c.Cache.MaxChange < 0 {
+		errs = append(errs, errors.New("cache.max_change: must not be negative"))
+	}
+	if c.Cache.NotFoundTTL.Duration < 0 {
+		errs = append(errs, errors.New("cache.not_found_ttl: must not be negative"))
+	}
+	if c.Cache.StaleAfter.Duration <= 0 {
+		errs = append(errs, errors.New("cache.stale_after: must be positive"))
+	}
+	for token, d := range c.Cache.MaxAges {
+		if d.Duration < time.Second {
+			errs = append(errs, fmt.Errorf("cache.max_ages: %s: must be at least 1s", token))
+		}
+	}
+	for endpoint := range c.Cache.CacheControl {
+		switch endpoint {
+		case "price", "prices", "simple_price":
+		default:
+			errs = append(errs, fmt.Errorf("cache.cache_control: unknown endpoint %q", endpoint))
+		}
+	}
+	if c.Upstream.Concurrency <= 0 {
+		errs = append(errs, errors.New("upstream.concurrency: must be positive"))
+	}
+	if c.Upstream.MaxIdleConns < 0 || c.Upstream.MaxIdleConnsPerHost < 0 || c.Upstream.MaxConnsPerHost < 0 {
+		errs = append(errs, errors.New("upstream: connection limits must not be negative"))
+	}
+	if c.Upstream.RetryAttempts < 1 {
+		errs = append(errs, errors.New("upstream.retry_attempts: must be at least 1"))
+	}
+	if c.Upstream.RetryBackoff.Duration < 0 || c.Upstream.RetryMaxBackoff.Duration < 0 {
+		errs = append(errs, errors.New("upstream: retry backoff must not be negative"))
+	}
+	if c.Upstream.BreakerFailures < 0 {
+		errs = append(errs, errors.New("upstream.breaker_failures: must not be negative"))
+	}
+	if c.Upstream.BreakerFailures > 0 && c.Upstream.BreakerCooldown.Duration <= 0 {
+		errs = append(errs, errors.New("upstream.breaker_cooldown: must be positive"))
+	}
+	if c.Upstream.RateLimitPause.Duration <= 0 {
+		errs = append(errs, errors.New("upstream.rate_limit_pause: must be positive"))
+	}
+	if c.Upstream.Record != "" && c.Upstream.Replay != "" {
+		errs = append(errs, errors.New("upstream: record and replay are mutually exclusive"))
+	}
+	if c.Upstream.MaxInFlight < 0 || c.Upstream.MaxQueued < 0 {
+		errs = append(errs, errors.New("upstream: max_in_flight and max_queued must not be negative"))
+	}
+	for host, n := range c.Upstream.RequestsPerMinute {
+		if n <= 0 || host == "" || strings.ContainsAny(host, "/:") {
+			errs = append(errs, fmt.Errorf("upstream.requests_per_minute: %q: want a host name and a positive number", host))
+		}
+	}
+	if c.Upstream.Timeout.Duration <= 0 || c.Upstream.RequestTimeout.Duration <= 0 {
+		errs = append(errs, errors.New("upstream: timeouts must be positive"))
+	}
+	for route, d := range c.Upstream.RouteTimeouts {
+		if !strings.HasPrefix(route, "/") || d.Duration <= 0 {
+			errs = append(errs, fmt.Errorf("upstream.route_timeouts: %s must be a route pattern with a positive duration", route))
+		}
+	}
+	for _, route := range c.Upstream.HedgeRoutes {
+		if !strings.HasPrefix(route, "/") {
+			errs = append(errs, fmt.Errorf("upstream.hedge_routes: %s is not a route pattern", route))
+		}
+	}
+	if c.Upstream.HedgeAfter.Duration < 0 {
+		errs = append(errs, errors.New("upstream.hedge_after: must not be negative"))
+	}
+	if c.SLO.Availability <= 0 || c.SLO.Availability >= 1 || c.SLO.LatencyTarget <= 0 || c.SLO.LatencyTarget >= 1 {
+		errs = append(errs, errors.New("slo: availability and latency_target must be between 0 and 1"))
+	}
+	if c.SLO.Latency.Duration <= 0 {
+		errs = append(errs, errors.New("slo.latency: must be positive"))
+	}
+	if w := c.SLO.Window.Duration; w != 0 && (w < time.Hour || w > 90*24*time.Hour) {
+		errs = append(errs, errors.New("slo.window: must be 0 or between 1h and 90 days"))
+	}
+	for route, o := range c.SLO.Routes {
+		if !strings.HasPrefix(route, "/") || o.Availability < 0 || o.Availability >= 1 || o.LatencyTarget < 0 || o.LatencyTarget >= 1 || (o.Latency != nil && o.Latency.Duration < 0) {
+			errs = append(errs, fmt.Errorf("slo.routes: %s must be a route pattern with objectives between 0 and 1", route))
+		}
+	}
+	if c.SLO.ShedBurnRate < 1 {
+		errs = append(errs, errors.New("slo.shed_burn_rate: must be at least 1"))
+	}
+	if len(c.CORS.AllowedOrigins) == 0 {
+		errs = append(errs, errors.New("cors.allowed_origins: at least one origin required (use * for any)"))
+	}
+	if c.RateLimit.RequestsPerMinute < 0 || c.RateLimit.Burst < 0 {
+		errs = append(errs, errors.New("rate_limit: values must not be negative"))
+	}
+	plugins := make(map[string]bool)
+	for i, p := range c.Plugins {
+		switch {
+		case p.Name == "":
+			errs = append(errs, fmt.Errorf("plugins[%d]: name required", i))
+		case plugins[p.Name]:
+			errs = append(errs, fmt.Errorf("plugins[%d]: duplicate name %q", i, p.Name))
+		}
+		plugins[p.Name] = true
+		if !strings.HasPrefix(p.URL, "http://") && !strings.HasPrefix(p.URL, "https://") {
+			errs = append(errs, fmt.Errorf("plugins[%d]: %q is not an http(s) URL", i, p.URL))
+		}
+		switch p.Mode {
+		case "", "claim":
+			if len(p.Tokens) == 0 {
+				errs = append(errs, fmt.Errorf("plugins[%d]: at least one token required", i))
+			}
+		case "alternate":
+			if len(p.Tokens) > 0 {
+				errs = append(errs, fmt.Errorf("plugins[%d]: alternate plugins serve every token; tokens must be empty", i))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("plugins[%d]: mode must be claim or alternate, not %q", i, p.Mode))
+		}
+	}
+	for i, s := range c.Sources {
+		switch {
+		case s.Name == "":
+			errs = append(errs, fmt.Errorf("sources[%d]: name required", i))
+		case plugins[s.Name]:
+			errs = append(errs, fmt.Errorf("sources[%d]: duplicate name %q", i, s.Name))
+		}
+		plugins[s.Name] = true
+		if s.Mode != "override" && s.Mode != "supplement" {
+			errs = append(errs, fmt.Errorf("sources[%d]: mode must be override or supplement", i))
+		}
+		if s.Quote == "" {
+			errs = append(errs, fmt.Errorf("sources[%d]: quote required", i))
+		}
+		switch s.Type {
+		case "dex_pool":
+			if !strings.HasPrefix(s.RPCURL, "http://") && !strings.HasPrefix(s.RPCURL, "https://") {
+				errs = append(errs, fmt.Errorf("sources[%d]: rpc_url %q is not an http(s) URL", i, s.RPCURL))
+			}
+			if len(s.Pools) == 0 {
+				errs = append(errs, fmt.Errorf("sources[%d]: at least one pool required", i))
+			}
+			for id, p := range s.Pools {
+				if p.Address == "" || (p.BaseIndex != 0 && p.BaseIndex != 1) || p.BaseDecimals < 0 || p.QuoteDecimals < 0 {
+					errs = append(errs, fmt.Errorf("sources[%d].pools.%s: address, base_index 0 or 1 and non-negative decimals required", i, id))
+				}
+			}
+		case "exchange":
+			if !strings.HasPrefix(s.URL, "http://") && !strings.HasPrefix(s.URL, "https://") {
+				errs = append(errs, fmt.Errorf("sources[%d]: url %q is not an http(s) URL", i, s.URL))
+			}
+			if !strings.Contains(s.URL, "{symbol}") {
+				errs = append(errs, fmt.Errorf("sources[%d]: url must contain {symbol}", i))
+			}
+			if s.Field == "" {
+				errs = append(errs, fmt.Errorf("sources[%d]: field required", i))
+			}
+			if len(s.Symbols) == 0 {
+				errs = append(errs, fmt.Errorf("sources[%d]: at least one symbol required", i))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("sources[%d]: unknown type %q", i, s.Type))
+		}
+	}
+	onramps := make(map[string]bool, len(c.Onramps))
+	for i, o := range c.Onramps {
+		switch {
+		case o.Name == "":
+			errs = append(errs, fmt.Errorf("onramps[%d]: name required", i))
+		case onramps[o.Name]:
+			errs = append(errs, fmt.Errorf("onramps[%d]: duplicate name %q", i, o.Name))
+		}
+		onramps[o.Name] = true
+		if o.Type != "moonpay" && o.Type != "transak" {
+			errs = append(errs, fmt.Errorf("onramps[%d]: type must be moonpay or transak", i))
+		}
+		if o.URL != "" && !strings.HasPrefix(o.URL, "http://") && !strings.HasPrefix(o.URL, "https://") {
+			errs = append(errs, fmt.Errorf("onramps[%d]: url %q is not an http(s) URL", i, o.URL))
+		}
+		if o.APIKey == "" {
+			errs = append(errs, fmt.Errorf("onramps[%d]: api_key required", i))
+		}
+		if len(o.Currencies) == 0 {
+			errs = append(errs, fmt.Errorf("onramps[%d]: at least one currency required", i))
+		}
+	}
+	switch c.FX.Source {
+	case "ecb", "exchangerate.host", "none":
+	default:
+		errs = append(errs, fmt.Errorf("fx.source: unknown source %q", c.FX.Source))
+	}
+	if u := c.FX.BaseURL; u != "" && !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		errs = append(errs, fmt.Errorf("fx.base_url: %q is not an http(s) URL", u))
+	}
+	if u := c.Metals.BaseURL; u != "" && !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		errs = append(errs, fmt.Errorf("metals.base_url: %q is not an http(s) URL", u))
+	}
+	if c.FX.TTL.Duration <= 0 {
+		errs = append(errs, errors.New("fx.ttl: must be positive"))
+	}
+	for chain, u := range c.Gas.RPCs {
+		if u != "" && !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+			errs = append(errs, fmt.Errorf("gas.rpcs.%s: %q is not an http(s) URL", chain, u))
+		}
+	}
+	if c.Gas.TTL.Duration <= 0 {
+		errs = append(errs, errors.New("gas.ttl: must be positive"))
+	}
+	for chain, d := range c.TokenPrice.DEXes {
+		rpc := d.RPCURL
+		if rpc == "" {
+			rpc = c.Gas.RPCs[chain]
+		}
+		if !strings.HasPrefix(rpc, "http://") && !strings.HasPrefix(rpc, "https://") {
+			errs = append(errs, fmt.Errorf("token_price.dexes.%s: rpc_url, or gas.rpcs.%s, must be an http(s) URL", chain, chain))
+		}
+		if !isAddress(d.Factory) {
+			errs = append(errs, fmt.Errorf("token_price.dexes.%s: factory %q is not an address", chain, d.Factory))
+		}
+		if len(d.Quotes) == 0 {
+			errs = append(errs, fmt.Errorf("token_price.dexes.%s: at least one quote token required", chain))
+		}
+		for i, q := range d.Quotes {
+			if !isAddress(q.Address) || q.Token == "" || q.Decimals < 0 || q.Decimals > 77 {
+				errs = append(errs, fmt.Errorf("token_price.dexes.%s.quotes[%d]: address, token and decimals between 0 and 77 required", chain, i))
+			}
+		}
+	}
+	for id, t := range c.Supply.Tokens {
+		rpc := t.RPCURL
+		if rpc == "" {
+			rpc = c.Gas.RPCs[t.Chain]
+		}
+		if !strings.HasPrefix(rpc, "http://") && !strings.HasPrefix(rpc, "https://") {
+			errs = append(errs, fmt.Errorf("supply.tokens.%s: rpc_url, or the gas.rpcs entry of chain %q, must be an http(s) URL", id, t.Chain))
+		}
+		if !isAddress(t.Contract) {
+			errs = append(errs, fmt.Errorf("supply.tokens.%s: contract %q is not an address", id, t.Contract))
+		}
+		if t.Decimals < 0 || t.Decimals > 77 {
+			errs = append(errs, fmt.Errorf("supply.tokens.%s: decimals must be between 0 and 77", id))
+		}
+		for _, addr := range t.Locked {
+			if !isAddress(addr) {
+				errs = append(errs, fmt.Errorf("supply.tokens.%s: locked address %q is not an address", id, addr))
+			}
+		}
+	}
+	if c.Supply.Threshold <= 0 {
+		errs = append(errs, errors.New("supply.threshold: must be positive"))
+	}
+	if c.Supply.Interval.Duration < time.Minute {
+		errs = append(errs, errors.New("supply.interval: must be at least 1m"))
+	}
+	if c.Supply.Changes.Interval.Duration < 0 {
+		errs = append(errs, errors.New("supply.changes.interval: must not be negative"))
+	}
+	if c.Supply.Changes.Interval.Duration > 0 && c.Supply.Changes.Interval.Duration < time.Minute {
+		errs = append(errs, errors.New("supply.changes.interval: must be at least 1m"))
+	}
+	if c.Supply.Changes.Threshold <= 0 {
+		errs = append(errs, errors.New("supply.changes.threshold: must be positive"))
+	}
+	if c.Supply.Changes.Retention.Duration < 48*time.Hour {
+		errs = append(errs, errors.New("supply.changes.retention: must be at least 48h"))
+	}
+	if c.Risk.Interval.Duration > 0 {
+		for id, t := range c.Risk.Contracts {
+			if t.Chain == "" || !isAddress(t.Contract) {
+				errs = append(errs, fmt.Errorf("risk.contracts.%s: chain and contract address required", id))
+			}
+		}
+		if u := c.Risk.SecurityURL; len(c.Risk.Contracts) > 0 && !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+			errs = append(errs, fmt.Errorf("risk.security_url: %q is not an http(s) URL", u))
+		}
+		if c.Risk.Volatility <= 0 || c.Risk.MinVolume < 0 || c.Risk.MaxTax <= 0 {
+			errs = append(errs, errors.New("risk: volatility and max_tax must be positive, min_volume not negative"))
+		}
+		if c.Risk.Tokens < 0 || c.Risk.Tokens > 250 {
+			errs = append(errs, errors.New("risk.tokens: must be between 0 and 250"))
+		}
+		if c.Risk.Interval.Duration < time.Minute {
+			errs = append(errs, errors.New("risk.interval: must be at least 1m"))
+		}
+	} else if c.Risk.Interval.Duration < 0 {
+		errs = append(errs, errors.New("risk.interval: must not be negative"))
+	}
+	if c.TokenPrice.MinLiquidity < 0 {
+		errs = append(errs, errors.New("token_price.min_liquidity: must not be negative"))
+	}
+	if c.TokenPrice.TTL.Duration <= 0 {
+		errs = append(errs, errors.New("token_price.ttl: must be positive"))
+	}
+	if c.Derivatives.TTL.Duration <= 0 {
+		errs = append(errs, errors.New("derivatives.ttl: must be positive"))
+	}
+	if c.NFT.TTL.Duration <= 0 {
+		errs = append(errs, errors.New("nft.ttl: must be positive"))
+	}
+	if u := c.TVL.BaseURL; u != "" && !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		errs = append(errs, fmt.Errorf("tvl.base_url: %q is not an http(s) URL", u))
+	}
+	if c.TVL.TTL.Duration <= 0 {
+		errs = append(errs, errors.New("tvl.ttl: must be positive"))
+	}
+	if c.Logos.TTL.Duration <= 0 || c.Logos.MaxBytes <= 0 {
+		errs = append(errs, errors.New("logos: ttl and max_bytes must be positive"))
+	}
+	if len(c.Logos.Hosts) == 0 {
+		errs = append(errs, errors.New("logos.hosts: at least one host required"))
+	}
+	if c.Global.TTL.Duration <= 0 {
+		errs = append(errs, errors.New("global.ttl: must be positive"))
+	}
+	if c.Trending.TTL.Duration <= 0 {
+		errs = append(errs, errors.New("trending.ttl: must be positive"))
+	}
+	if c.Markets.TTL.Duration <= 0 {
+		errs = append(errs, errors.New("markets.ttl: must be positive"))
+	}
+	if c.History.TTL.Duration <= 0 {
+		errs = append(errs, errors.New("history.ttl: must be positive"))
+	}
+	if c.Ticks.Retention.Duration <= 0 {
+		errs = append(errs, errors.New("ticks.retention: must be positive"))
+	}
+	if len(c.Ticks.Tokens) > 0 && c.Ticks.Interval.Duration <= 0 {
+		errs = append(errs, errors.New("ticks.interval: must be positive"))
+	}
+	if h := c.Ticks.HourlyRetention.Duration; h < 0 || h > 0 && h <= c.Ticks.Retention.Duration {
+		errs = append(errs, errors.New("ticks.hourly_retention: must be 0 or longer than ticks.retention"))
+	}
+	if d := c.Ticks.DailyRetention.Duration; d < 0 || d > 0 && d <= c.Ticks.HourlyRetention.Duration {
+		errs = append(errs, errors.New("ticks.daily_retention: must be 0 or longer than ticks.hourly_retention"))
+	}
+	if c.Ticks.HourlyRetention.Duration > 0 && c.Ticks.CompactInterval.Duration <= 0 {
+		errs = append(errs, errors.New("ticks.compact_interval: must be positive"))
+	}
+	if c.Analytics.RiskFreeRate <= -1 || c.Analytics.RiskFreeRate >= 1 {
+		errs = append(errs, errors.New("analytics.risk_free_rate: must be between -1 and 1"))
+	}
+	if c.Stream.MaxClients < 1 {
+		errs = append(errs, errors.New("stream.max_clients: must be positive"))
+	}
+	if c.Stream.MaxLag.Duration < time.Second {
+		errs = append(errs, errors.New("stream.max_lag: must be at least 1s"))
+	}
+	if c.Stream.Interval.Duration < 0 {
+		errs = append(errs, errors.New("stream.interval: must not be negative"))
+	}
+	if u := c.Stream.RedisURL; u != "" && !strings.HasPrefix(u, "redis://") && !strings.HasPrefix(u, "rediss://") {
+		errs = append(errs, errors.New("stream.redis_url: must start with redis:// or rediss://"))
+	}
+	if c.Stream.RedisURL != "" && c.Stream.RedisChannel == "" {
+		errs = append(errs, errors.New("stream.redis_channel: required with stream.redis_url"))
+	}
+	switch c.Replica.Role {
+	case "":
+	case "primary", "secondary":
+		if c.Stream.RedisURL == "" {
+			errs = append(errs, fmt.Errorf("replica.role: %s requires stream.redis_url", c.Replica.Role))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("replica.role: must be primary or secondary, not %q", c.Replica.Role))
+	}
+	if u := c.Replica.PrimaryURL; c.Replica.Role == "secondary" && !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		errs = append(errs, errors.New("replica.primary_url: must start with http:// or https:// for a secondary"))
+	}
+	if c.Alerts.Interval.Duration < 0 {
+		errs = append(errs, errors.New("alerts.interval: must not be negative"))
+	}
+	if c.Alerts.Cooldown.Duration < 0 {
+		errs = append(errs, errors.New("alerts.cooldown: must not be negative"))
+	}
+	if c.Alerts.RetryAttempts < 1 || c.Alerts.RetryAttempts > 10 {
+		errs = append(errs, errors.New("alerts.retry_attempts: must be between 1 and 10"))
+	}
+	if b := c.Alerts.RetryBackoff.Duration; b < time.Second || b > time.Hour {
+		errs = append(errs, errors.New("alerts.retry_backoff: must be between 1s and 1h"))
+	}
+	if c.Reports.Period != "daily" && c.Reports.Period != "weekly" {
+		errs = append(errs, fmt.Errorf("reports.period: %q must be daily or weekly", c.Reports.Period))
+	}
+	if c.Reports.Hour < 0 || c.Reports.Hour > 23 {
+		errs = append(errs, errors.New("reports.hour: must be between 0 and 23"))
+	}
+	if c.Reports.Currency == "" {
+		errs = append(errs, errors.New("reports.currency: required"))
+	}
+	if c.Reports.Movers < 1 || c.Reports.Movers > 50 {
+		errs = append(errs, errors.New("reports.movers: must be between 1 and 50"))
+	}
+	for token, amount := range c.Treasury.Holdings {
+		if amount < 0 {
+			errs = append(errs, fmt.Errorf("treasury.holdings.%s: must not be negative", token))
+		}
+	}
+	if p := c.Treasury.Period; p != "daily" && p != "weekly" && p != "monthly" {
+		errs = append(errs, fmt.Errorf("treasury.period: %q must be daily, weekly or monthly", p))
+	}
+	if c.Treasury.Hour < 0 || c.Treasury.Hour > 23 {
+		errs = append(errs, errors.New("treasury.hour: must be between 0 and 23"))
+	}
+	if c.Treasury.Currency == "" {
+		errs = append(errs, errors.New("treasury.currency: required"))
+	}
+	if u := c.Treasury.ExportURL; u != "" {
+		scheme, _, _ := strings.Cut(u, "://")
+		if scheme != "s3" && scheme != "gs" && scheme != "file" {
+			errs = append(errs, fmt.Errorf("treasury.export_url: %q is not an s3://, gs:// or file:// URL", u))
+		}
+		if (scheme == "s3" || scheme == "gs") && (c.Snapshot.AccessKeyID == "" || c.Snapshot.SecretAccessKey == "") {
+			errs = append(errs, errors.New("treasury.export_url: snapshot access_key_id and secret_access_key required for s3:// and gs://"))
+		}
+	}
+	if len(c.Extremes.Tokens) > 0 && c.Extremes.Interval.Duration <= 0 {
+		errs = append(errs, errors.New("extremes.interval: must be positive"))
+	}
+	if c.Listings.Interval.Duration < 0 {
+		errs = append(errs, errors.New("listings.interval: must not be negative"))
+	}
+	if c.Listings.Interval.Duration > 0 && c.Listings.Interval.Duration < time.Minute {
+		errs = append(errs, errors.New("listings.interval: must be at least 1m"))
+	}
+	if c.Listings.Retention.Duration <= 0 {
+		errs = append(errs, errors.New("listings.retention: must be positive"))
+	}
+	for name, slug := range c.Categories.Sources {
+		if !validCategory(name) || !validCategory(slug) {
+			errs = append(errs, fmt.Errorf("categories.sources: %s -> %s must name categories in lowercase letters, digits and dashes", name, slug))
+		}
+	}
+	for child, parent := range c.Categories.Parents {
+		if !validCategory(child) || !validCategory(parent) {
+			errs = append(errs, fmt.Errorf("categories.parents: %s -> %s must name categories in lowercase letters, digits and dashes", child, parent))
+			continue
+		}
+		seen := map[string]bool{child: true}
+		for p := parent; p != ""; p = c.Categories.Parents[p] {
+			if seen[p] {
+				errs = append(errs, fmt.Errorf("categories.parents: the ancestors of %s form a cycle", child))
+				break
+			}
+			seen[p] = true
+		}
+	}
+	for token, tags := range c.Categories.Overrides {
+		for _, tag := range tags {
+			if !validCategory(strings.TrimPrefix(tag, "-")) {
+				errs = append(errs, fmt.Errorf("categories.overrides.%s: invalid category %q", token, tag))
+			}
+		}
+	}
+	if c.Categories.Interval.Duration < 0 {
+		errs = append(errs, errors.New("categories.interval: must not be negative"))
+	}
+	if c.Categories.Interval.Duration > 0 && c.Categories.Interval.Duration < time.Minute {
+		errs = append(errs, errors.New("categories.interval: must be at least 1m"))
+	}
+	for alias, id := range c.Aliases.Tokens {
+		if alias == "" || id == "" || alias == id {
+			errs = append(errs, fmt.Errorf("aliases.tokens: %s -> %s must map an alias to a different token id", alias, id))
+		} else if _, chained := c.Aliases.Tokens[id]; chained {
+			errs = append(errs, fmt.Errorf("aliases.tokens: %s is itself an alias", id))
+		}
+	}
+	if c.Email.SMTPAddr != "" && c.Email.From == "" {
+		errs = append(errs, errors.New("email.from: required with email.smtp_addr"))
+	}
+	if c.Email.Batch.Duration < 0 {
+		errs = append(errs, errors.New("email.batch: must not be negative"))
+	}
+	if u := c.Snapshot.URL; u != "" {
+		scheme, _, _ := strings.Cut(u, "://")
+		if scheme != "s3" && scheme != "gs" && scheme != "file" {
+			errs = append(errs, fmt.Errorf("snapshot.url: %q is not an s3://, gs:// or file:// URL", u))
+		}
+		if (scheme == "s3" || scheme == "gs") && (c.Snapshot.AccessKeyID == "" || c.Snapshot.SecretAccessKey == "") {
+			errs = append(errs, errors.New("snapshot: access_key_id and secret_access_key required for s3:// and gs://"))
+		}
+	}
+	if c.Snapshot.Interval.Duration <= 0 {
+		errs = append(errs, errors.New("snapshot.interval: must be positive"))
+	}
+	if c.Snapshot.Retention.Duration < 0 {
+		errs = append(errs, errors.New("snapshot.retention: must not be negative"))
+	}
+	if c.Snapshot.Markets < 0 {
+		errs = append(errs, errors.New("snapshot.markets: must not be negative"))
+	}
+	if c.Snapshot.Currency == "" {
+		errs = append(errs, errors.New("snapshot.currency: required"))
+	}
+	for name, weights := range c.Indices {
+		if len(weights) == 0 {
+			errs = append(errs, fmt.Errorf("indices.%s: no constituents", name))
+		}
+		for token, w := range weights {
+			if w <= 0 {
+				errs = append(errs, fmt.Errorf("indices.%s.%s: weight must be positive", name, token))
+			}
+		}
+	}
+	if c.Stablecoins.Interval.Duration < 0 {
+		errs = append(errs, errors.New("stablecoins.interval: must not be negative"))
+	}
+	if c.Stablecoins.Threshold <= 0 || c.Stablecoins.Threshold >= 1 {
+		errs = append(errs, errors.New("stablecoins.threshold: must be between 0 and 1"))
+	}
+	if c.Stablecoins.History.Duration <= 0 {
+		errs = append(errs, errors.New("stablecoins.history: must be positive"))
+	}
+	for i, coin := range c.Stablecoins.Coins {
+		if coin.ID == "" {
+			errs = append(errs, fmt.Errorf("stablecoins.coins[%d]: id required", i))
+		}
+		if coin.Peg < 0 {
+			errs = append(errs, fmt.Errorf("stablecoins.coins[%d]: peg must not be negative", i))
+		}
+	}
+	for _, u := range c.Stablecoins.Webhooks {
+		if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+			errs = append(errs, fmt.Errorf("stablecoins.webhooks: %q is not an http(s) URL", u))
+		}
+	}
+	if c.Deviation.Interval.Duration < 0 {
+		errs = append(errs, errors.New("deviation.interval: must not be negative"))
+	}
+	if c.Deviation.Threshold <= 0 || c.Deviation.Threshold >= 1 {
+		errs = append(errs, errors.New("deviation.threshold: must be between 0 and 1"))
+	}
+	for _, u := range c.Deviation.Webhooks {
+		if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+			errs = append(errs, fmt.Errorf("deviation.webhooks: %q is not an http(s) URL", u))
+		}
+	}
+	if o := c.Oracle; o.Contract != "" {
+		if !strings.HasPrefix(o.RPCURL, "http://") && !strings.HasPrefix(o.RPCURL, "https://") {
+			errs = append(errs, fmt.Errorf("oracle.rpc_url: %q is not an http(s) URL", o.RPCURL))
+		}
+		if !isAddress(o.Contract) {
+			errs = append(errs, fmt.Errorf("oracle.contract: %q is not a 0x address", o.Contract))
+		}
+		if !isAddress(o.From) {
+			errs = append(errs, fmt.Errorf("oracle.from: %q is not a 0x address", o.From))
+		}
+		if o.Method == "" {
+			errs = append(errs, errors.New("oracle.method: required"))
+		}
+		if len(o.Feeds) == 0 {
+			errs = append(errs, errors.New("oracle.feeds: at least one feed required"))
+		}
+		for _, f := range o.Feeds {
+			if token, currency, ok := strings.Cut(f, "/"); !ok || token == "" || currency == "" {
+				errs = append(errs, fmt.Errorf("oracle.feeds: %q is not token/currency", f))
+			}
+		}
+		if o.Decimals < 0 || o.Decimals > 36 {
+			errs = append(errs, errors.New("oracle.decimals: must be between 0 and 36"))
+		}
+		if o.Deviation <= 0 || o.Deviation >= 1 {
+			errs = append(errs, errors.New("oracle.deviation: must be between 0 and 1"))
+		}
+		if o.Heartbeat.Duration <= 0 || o.Interval.Duration <= 0 {
+			errs = append(errs, errors.New("oracle: heartbeat and interval must be positive"))
+		}
+		if o.MaxGasPriceGwei < 0 || o.GasLimit < 0 || o.ReplaceAfter.Duration < 0 {
+			errs = append(errs, errors.New("oracle: gas and replacement settings must not be negative"))
+		}
+	}
+	if b := c.Bridge; len(b.Pairs) > 0 {
+		for _, p := range b.Pairs {
+			if base, quote, ok := strings.Cut(p, "/"); !ok || base == "" || quote == "" || base == quote {
+				errs = append(errs, fmt.Errorf("bridge.pairs: %q is not base/quote", p))
+			}
+		}
+		if b.Currency == "" {
+			errs = append(errs, errors.New("bridge.currency: required"))
+		}
+		if b.MaxAge.Duration <= 0 {
+			errs = append(errs, errors.New("bridge.max_age: must be positive"))
+		}
+		if b.Decimals < 0 || b.Decimals > 36 {
+			errs = append(errs, errors.New("bridge.decimals: must be between 0 and 36"))
+		}
+	}
+	if q := c.Quotes; q.Signer != "" {
+		if !isAddress(q.Signer) {
+			errs = append(errs, fmt.Errorf("quotes.signer: %q is not a 0x address", q.Signer))
+		}
+		if !strings.HasPrefix(q.SignerURL, "http://") && !strings.HasPrefix(q.SignerURL, "https://") {
+			errs = append(errs, fmt.Errorf("quotes.signer_url: %q is not an http(s) URL", q.SignerURL))
+		}
+		if q.SignMethod == "" {
+			errs = append(errs, errors.New("quotes.sign_method: required"))
+		}
+	}
+	if q := c.Quotes; q.VerifyingContract != "" && !isAddress(q.VerifyingContract) {
+		errs = append(errs, fmt.Errorf("quotes.verifying_contract: %q is not a 0x address", q.VerifyingContract))
+	}
+	if c.Quotes.ChainID <= 0 {
+		errs = append(errs, errors.New("quotes.chain_id: must be positive"))
+	}
+	if c.Quotes.TTL.Duration <= 0 {
+		errs = append(errs, errors.New("quotes.ttl: must be positive"))
+	}
+	if c.Quotes.Decimals < 0 || c.Quotes.Decimals > 36 {
+		errs = append(errs, errors.New("quotes.decimals: must be between 0 and 36"))
+	}
+	if q := c.Quorum; q.Min != 0 {
+		counted := len(q.Providers)
+		if counted == 0 {
+			counted = 1 + len(plugins)
+			if c.Metals.APIKey != "" {
+				counted++
+			}
+		}
+		switch {
+		case q.Min < 0:
+			errs = append(errs, errors.New("quorum.min: must not be negative"))
+		case q.Min > counted:
+			errs = append(errs, fmt.Errorf("quorum.min: %d providers must agree but %d are counted", q.Min, counted))
+		}
+		for _, name := range q.Providers {
+			if name != "coingecko" && !(name == "metals" && c.Metals.APIKey != "") && !plugins[name] {
+				errs = append(errs, fmt.Errorf("quorum.providers: %q is not coingecko, metals or a configured plugin or source", name))
+			}
+		}
+		if q.Tolerance <= 0 || q.Tolerance >= 1 {
+			errs = append(errs, errors.New("quorum.tolerance: must be between 0 and 1"))
+		}
+		if q.TTL.Duration < 0 {
+			errs = append(errs, errors.New("quorum.ttl: must not be negative"))
+		}
+	}
+	if _, err := c.LegacySunsetTime(); err != nil {
+		errs = append(errs, fmt.Errorf("legacy_sunset: %q is not a YYYY-MM-DD date", c.LegacySunset))
+	}
+	seen := make(map[string]string)
+	for actor, key := range c.Admin.APIKeys {
+		if key == "" {
+			errs = append(errs, fmt.Errorf("admin.api_keys: empty key for %q", actor))
+		}
+		if other, dup := seen[key]; dup {
+			errs = append(errs, fmt.Errorf("admin.api_keys: %q and %q share a key", other, actor))
+		}
+		seen[key] = actor
+	}
+	return errors.Join(errs...)
+}
+
+// LegacySunsetTime parses LegacySunset, returning the zero time if unset
+func (c *Config) LegacySunsetTime() (time.Time, error) {
+	if c.LegacySunset == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", c.LegacySunset)
+}
+
+// RestartError reports settings that changed but only take effect after a
+// restart
+type RestartError struct {
+	Fields []string
+}
+
+func (e *RestartError) Error() string {
+	return "changes require a restart: " + strings.Join(e.Fields, ", ")
+}
+
+// RestartRequired lists settings that differ between old and new and
+// cannot be applied to a running service
+func RestartRequired(old, new *Config) []string {
+	var fields []string
+	check := func(name string, a, b interface{}) {
+		if !reflect.DeepEqual(a, b) {
+			fields = append(fields, name)
+		}
+	}
+	check("port", old.Port, new.Port)
+	check("provider", old.Provider, new.Provider)
+	check("demo", old.Demo, new.Demo)
+	check("coingecko", old.CoinGecko, new.CoinGecko)
+	check("upstream", old.Upstream, new.Upstream)
+	check("plugins", old.Plugins, new.Plugins)
+	check("sources", old.Sources, new.Sources)
+	check("onramps", old.Onramps, new.Onramps)
+	check("fx", old.FX, new.FX)
+	check("metals", old.Metals, new.Metals)
+	check("stablecoins", old.Stablecoins, new.Stablecoins)
+	check("deviation", old.Deviation, new.Deviation)
+	check("oracle", old.Oracle, new.Oracle)
+	check("quotes", old.Quotes, new.Quotes)
+	check("quorum", old.Quorum, new.Quorum)
+	check("bridge", old.Bridge, new.Bridge)
+	check("gas", old.Gas, new.Gas)
+	check("token_price", old.TokenPrice, new.TokenPrice)
+	check("supply", old.Supply, new.Supply)
+	check("risk", old.Risk, new.Risk)
+	check("derivatives", old.Derivatives, new.Derivatives)
+	check("nft", old.NFT, new.NFT)
+	check("tvl", old.TVL, new.TVL)
+	check("logos", old.Logos, new.Logos)
+	check("global", old.Global, new.Global)
+	check("trending", old.Trending, new.Trending)
+	check("markets", old.Markets, new.Markets)
+	check("history", old.History, new.History)
+	check("indices", old.Indices, new.Indices)
+	check("ticks", old.Ticks, new.Ticks)
+	check("stream", old.Stream, new.Stream)
+	check("replica", old.Replica, new.Replica)
+	check("slo", old.SLO, new.SLO)
+	check("analytics", old.Analytics, new.Analytics)
+	// The alert cooldown and mute schedule are reloadable
+	oldAlerts := old.Alerts
+	oldAlerts.Cooldown, oldAlerts.Mute = new.Alerts.Cooldown, new.Alerts.Mute
+	check("alerts", oldAlerts, new.Alerts)
+	check("reports", old.Reports, new.Reports)
+	check("treasury", old.Treasury, new.Treasury)
+	check("extremes", old.Extremes, new.Extremes)
+	check("listings", old.Listings, new.Listings)
+	check("categories", old.Categories, new.Categories)
+	check("aliases", old.Aliases, new.Aliases)
+	check("overrides", old.Overrides, new.Overrides)
+	check("rounding", old.Rounding, new.Rounding)
+	check("features", old.Features, new.Features)
+	check("email", old.Email, new.Email)
+	check("snapshot", old.Snapshot, new.Snapshot)
+	check("admin.audit_log_path", old.Admin.AuditLogPath, new.Admin.AuditLogPath)
+	check("tenants_file", old.TenantsFile, new.TenantsFile)
+	check("signing_key", old.SigningKey, new.SigningKey)
+	return fields
+}
+
+// isAddress reports whether s is a 0x-prefixed 20-byte hex address
+func isAddress(s string) bool {
+	if len(s) != 42 || !strings.HasPrefix(s, "0x") {
+		return false
+	}
+	for _, c := range s[2:] {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// validCategory reports whether s is a category name: lowercase letters,
+// digits and dashes
+func validCategory(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("abcdefghijklmnopqrstuvwxyz0123456789-", c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,527 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// tomlToJSON converts a TOML document to the JSON the config decodes, so
+// a .toml config file is read with the same keys, durations and checks as
+// a JSON one. Tables, arrays of tables, inline tables, arrays, strings,
+// integers, floats and booleans are supported; dates and times are not,
+// as no setting takes one.
+func tomlToJSON(data []byte) ([]byte, error) {
+	p := &tomlParser{src: string(data), line: 1, root: make(map[string]any), defined: make(map[string]bool)}
+	if err := p.parse(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", p.line, err)
+	}
+	return json.Marshal(p.root)
+}
+
+// tomlParser reads a TOML document into nested maps
+type tomlParser struct {
+	src     string
+	pos     int
+	line    int
+	root    map[string]any
+	cur     map[string]any  // table keys are currently assigned to
+	defined map[string]bool // headers of tables defined so far
+}
+
+func (p *tomlParser) parse() error {
+	p.cur = p.root
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return nil
+		}
+		var err error
+		if p.peek() == '[' {
+			err = p.header()
+		} else {
+			err = p.keyValue(p.cur)
+		}
+		if err != nil {
+			return err
+		}
+		if err := p.endOfLine(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *tomlParser) startsWith(s string) bool {
+	return strings.HasPrefix(p.src[p.pos:], s)
+}
+
+// skipBlank skips spaces, tabs and comments, and newlines if multiline
+func (p *tomlParser) skipBlank(multiline bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n' && multiline:
+			p.pos++
+			p.line++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// endOfLine requires the rest of the line to be blank or a comment
+func (p *tomlParser) endOfLine() error {
+	p.skipBlank(false)
+	if p.eof() {
+		return nil
+	}
+	if p.peek() != '\n' {
+		return fmt.Errorf("unexpected %q after value", p.peek())
+	}
+	p.pos++
+	p.line++
+	return nil
+}
+
+// header reads a [table] or [[array of tables]] header and makes it the
+// current table
+func (p *tomlParser) header() error {
+	array := p.startsWith("[[")
+	if array {
+		p.pos += 2
+	} else {
+		p.pos++
+	}
+	p.skipBlank(false)
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	p.skipBlank(false)
+	closing := "]"
+	if array {
+		closing = "]]"
+	}
+	if !p.startsWith(closing) {
+		return fmt.Errorf("expected %s after table name", closing)
+	}
+	p.pos += len(closing)
+
+	parent, err := p.table(p.root, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if array {
+		existing, ok := parent[last]
+		list, isList := existing.([]any)
+		if ok && !isList {
+			return fmt.Errorf("%s is not an array of tables", strings.Join(keys, "."))
+		}
+		t := make(map[string]any)
+		parent[last] = append(list, t)
+		p.cur = t
+		return nil
+	}
+
+	path := strings.Join(keys, "\x00")
+	if p.defined[path] {
+		return fmt.Errorf("table %s defined twice", strings.Join(keys, "."))
+	}
+	p.defined[path] = true
+	t, err := p.table(parent, []string{last})
+	if err != nil {
+		return err
+	}
+	p.cur = t
+	return nil
+}
+
+// table returns the table at keys under t, creating missing ones. An
+// array of tables stands for its last table.
+func (p *tomlParser) table(t map[string]any, keys []string) (map[string]any, error) {
+	for _, k := range keys {
+		switch v := t[k].(type) {
+		case nil:
+			next := make(map[string]any)
+			t[k] = next
+			t = next
+		case map[string]any:
+			t = v
+		case []any:
+			if len(v) == 0 {
+				return nil, fmt.Errorf("%s is not a table", k)
+			}
+			last, ok := v[len(v)-1].(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s is not a table", k)
+			}
+			t = last
+		default:
+			return nil, fmt.Errorf("%s is not a table", k)
+		}
+	}
+	return t, nil
+}
+
+// keyValue reads key = value into t
+func (p *tomlParser) keyValue(t map[string]any) error {
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	p.skipBlank(false)
+	if p.peek() != '=' {
+		return fmt.Errorf("expected = after %s", strings.Join(keys, "."))
+	}
+	p.pos++
+	p.skipBlank(false)
+	v, err := p.value()
+	if err != nil {
+		return fmt.Errorf("%s: %w", strings.Join(keys, "."), err)
+	}
+	parent, err := p.table(t, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if _, dup := parent[last]; dup {
+		return fmt.Errorf("%s set twice", strings.Join(keys, "."))
+	}
+	parent[last] = v
+	return nil
+}
+
+// key reads a possibly dotted key of bare or quoted parts
+func (p *tomlParser) key() ([]string, error) {
+	var keys []string
+	for {
+		var k string
+		switch c := p.peek(); {
+		case c == '"':
+			s, err := p.basicString()
+			if err != nil {
+				return nil, err
+			}
+			k = s
+		case c == '\'':
+			s, err := p.literalString()
+			if err != nil {
+				return nil, err
+			}
+			k = s
+		default:
+			start := p.pos
+			for !p.eof() && isBareKeyChar(p.peek()) {
+				p.pos++
+			}
+			if p.pos == start {
+				return nil, fmt.Errorf("expected a key, found %q", p.peek())
+			}
+			k = p.src[start:p.pos]
+		}
+		keys = append(keys, k)
+		p.skipBlank(false)
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+		p.skipBlank(false)
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// value reads a string, number, boolean, array or inline table
+func (p *tomlParser) value() (any, error) {
+	switch {
+	case p.startsWith(`"""`):
+		return p.multilineBasicString()
+	case p.startsWith("'''"):
+		return p.multilineLiteralString()
+	case p.peek() == '"':
+		return p.basicString()
+	case p.peek() == '\'':
+		return p.literalString()
+	case p.peek() == '[':
+		return p.array()
+	case p.peek() == '{':
+		return p.inlineTable()
+	case p.startsWith("true"):
+		p.pos += 4
+		return true, nil
+	case p.startsWith("false"):
+		p.pos += 5
+		return false, nil
+	}
+	return p.number()
+}
+
+// array reads [a, b, ...], which may span lines
+func (p *tomlParser) array() (any, error) {
+	p.pos++
+	list := []any{}
+	for {
+		p.skipBlank(true)
+		if p.peek() == ']' {
+			p.pos++
+			return list, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+		p.skipBlank(true)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return list, nil
+		default:
+			return nil, fmt.Errorf("expected , or ] in array, found %q", p.peek())
+		}
+	}
+}
+
+// inlineTable reads {key = value, ...}
+func (p *tomlParser) inlineTable() (any, error) {
+	p.pos++
+	t := make(map[string]any)
+	p.skipBlank(false)
+	if p.peek() == '}' {
+		p.pos++
+		return t, nil
+	}
+	for {
+		p.skipBlank(false)
+		if err := p.keyValue(t); err != nil {
+			return nil, err
+		}
+		p.skipBlank(false)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return t, nil
+		default:
+			return nil, fmt.Errorf("expected , or } in inline table, found %q", p.peek())
+		}
+	}
+}
+
+// number reads an integer or float
+func (p *tomlParser) number() (any, error) {
+	start := p.pos
+	for !p.eof() && strings.IndexByte("+-_.:0123456789abcdefoxABCDEFOXTZ", p.peek()) >= 0 {
+		p.pos++
+	}
+	tok := p.src[start:p.pos]
+	if tok == "" {
+		if p.eof() {
+			return nil, fmt.Errorf("missing value")
+		}
+		return nil, fmt.Errorf("unexpected %q", p.peek())
+	}
+	if strings.Contains(tok, ":") || len(tok) >= 10 && tok[4] == '-' && tok[7] == '-' {
+		return nil, fmt.Errorf("dates and times are not supported; quote %s", tok)
+	}
+	clean := strings.ReplaceAll(tok, "_", "")
+	for _, prefix := range []struct {
+		s    string
+		base int
+	}{{"0x", 16}, {"0o", 8}, {"0b", 2}} {
+		if digits, ok := strings.CutPrefix(clean, prefix.s); ok {
+			n, err := strconv.ParseInt(digits, prefix.base, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid integer %s", tok)
+			}
+			return n, nil
+		}
+	}
+	if strings.ContainsAny(clean, ".eE") {
+		f, err := strconv.ParseFloat(clean, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float %s", tok)
+		}
+		return f, nil
+	}
+	n, err := strconv.ParseInt(clean, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %s", tok)
+	}
+	return n, nil
+}
+
+// literalString reads '...', taken as is
+func (p *tomlParser) literalString() (string, error) {
+	p.pos++
+	end := strings.IndexAny(p.src[p.pos:], "'\n")
+	if end < 0 || p.src[p.pos+end] != '\'' {
+		return "", fmt.Errorf("unterminated string")
+	}
+	s := p.src[p.pos : p.pos+end]
+	p.pos += end + 1
+	return s, nil
+}
+
+// multilineLiteralString reads a triple-quoted literal string, taken as is
+// but for a newline right after the opening quotes
+func (p *tomlParser) multilineLiteralString() (string, error) {
+	p.pos += 3
+	end := strings.Index(p.src[p.pos:], "'''")
+	if end < 0 {
+		return "", fmt.Errorf("unterminated string")
+	}
+	s := p.src[p.pos : p.pos+end]
+	p.pos += end + 3
+	p.line += strings.Count(s, "\n")
+	return trimFirstNewline(s), nil
+}
+
+// basicString reads "...", unescaping it
+func (p *tomlParser) basicString() (string, error) {
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", fmt.Errorf("unterminated string")
+		}
+		c := p.peek()
+		switch c {
+		case '"':
+			p.pos++
+			return b.String(), nil
+		case '\\':
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// multilineBasicString reads """...""", unescaping it. A backslash at the
+// end of a line joins it to the next, dropping the whitespace between.
+func (p *tomlParser) multilineBasicString() (string, error) {
+	p.pos += 3
+	if p.startsWith("\r\n") {
+		p.pos += 2
+		p.line++
+	} else if p.startsWith("\n") {
+		p.pos++
+		p.line++
+	}
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", fmt.Errorf("unterminated string")
+		}
+		switch c := p.peek(); {
+		case p.startsWith(`"""`):
+			p.pos += 3
+			return b.String(), nil
+		case c == '\\' && lineEndsAfter(p.src[p.pos+1:]):
+			p.pos++
+			for !p.eof() && strings.IndexByte(" \t\r\n", p.peek()) >= 0 {
+				if p.peek() == '\n' {
+					p.line++
+				}
+				p.pos++
+			}
+		case c == '\\':
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// lineEndsAfter reports whether s is only spaces up to a newline
+func lineEndsAfter(s string) bool {
+	rest := strings.TrimLeft(s, " \t\r")
+	return strings.HasPrefix(rest, "\n")
+}
+
+// escape reads the escape sequence at p.pos into b
+func (p *tomlParser) escape(b *strings.Builder) error {
+	if p.pos+1 >= len(p.src) {
+		return fmt.Errorf("unterminated escape")
+	}
+	c := p.src[p.pos+1]
+	p.pos += 2
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case '"':
+		b.WriteByte('"')
+	case '\\':
+		b.WriteByte('\\')
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.src) {
+			return fmt.Errorf("short \\%c escape", c)
+		}
+		r, err := strconv.ParseUint(p.src[p.pos:p.pos+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(r)) {
+			return fmt.Errorf("invalid \\%c escape", c)
+		}
+		b.WriteRune(rune(r))
+		p.pos += n
+	default:
+		return fmt.Errorf("invalid escape \\%c", c)
+	}
+	return nil
+}
+
+// trimFirstNewline drops a newline right after a multi-line string opens
+func trimFirstNewline(s string) string {
+	if t, ok := strings.CutPrefix(s, "\r\n"); ok {
+		return t
+	}
+	return strings.TrimPrefix(s, "\n")
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTOMLToJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		toml string
+		want string // JSON
+	}{
+		{
+			name: "empty",
+			toml: "# nothing here\n\n",
+			want: `{}`,
+		},
+		{
+			name: "scalars",
+			toml: "port = \"8080\"\ncount = 42\nneg = -7\nbig = 1_000_000\nhex = 0xff\noct = 0o17\nbin = 0b101\n" +
+				"ratio = 0.25\nexp = 1e3\nnegexp = -2.5E-2\non = true\noff = false\n",
+			want: `{"port":"8080","count":42,"neg":-7,"big":1000000,"hex":255,"oct":15,"bin":5,
+				"ratio":0.25,"exp":1000,"negexp":-0.025,"on":true,"off":false}`,
+		},
+		{
+			name: "comments and blank lines",
+			toml: "# leading\n\nport = \"80\" # trailing\n\t\n  debug = true   \r\n",
+			want: `{"port":"80","debug":true}`,
+		},
+		{
+			name: "tables",
+			toml: "top = 1\n[cache]\nttl = \"30s\"\n[coingecko]\napi_key = \"k\"\n[alerts.email]\nfrom = \"a@b.c\"\n",
+			want: `{"top":1,"cache":{"ttl":"30s"},"coingecko":{"api_key":"k"},"alerts":{"email":{"from":"a@b.c"}}}`,
+		},
+		{
+			name: "dotted and quoted keys",
+			toml: "cache.ttl = \"1m\"\n\"quoted key\" = 1\n'literal.key' = 2\nsite.\"a b\".c = 3\n",
+			want: `{"cache":{"ttl":"1m"},"quoted key":1,"literal.key":2,"site":{"a b":{"c":3}}}`,
+		},
+		{
+			name: "arrays of tables",
+			toml: "[[tenants]]\nname = \"wallet\"\n[[tenants]]\nname = \"exchange\"\n[tenants.limits]\nrpm = 60\n",
+			want: `{"tenants":[{"name":"wallet"},{"name":"exchange","limits":{"rpm":60}}]}`,
+		},
+		{
+			name: "inline tables",
+			toml: "limit = { rpm = 60, burst = 10 }\nempty = {}\nnested = { a = { b = \"c\" } }\n",
+			want: `{"limit":{"rpm":60,"burst":10},"empty":{},"nested":{"a":{"b":"c"}}}`,
+		},
+		{
+			name: "arrays",
+			toml: "origins = [\"https://a\", \"https://b\"]\nempty = []\nmixed = [1, 2.5, \"x\", [true]]\n" +
+				"multi = [\n  \"one\", # first\n  \"two\",\n]\n",
+			want: `{"origins":["https://a","https://b"],"empty":[],"mixed":[1,2.5,"x",[true]],"multi":["one","two"]}`,
+		},
+		{
+			name: "basic string escapes",
+			toml: `s = "tab\tnl\nquote\"back\\ u\u00e9 U\U0001F600"` + "\n",
+			want: `{"s":"tab\tnl\nquote\"back\\ u\u00e9 U\ud83d\ude00"}`,
+		},
+		{
+			name: "literal strings are not unescaped",
+			toml: `path = 'C:\Users\lux\n'` + "\n",
+			want: `{"path":"C:\\Users\\lux\\n"}`,
+		},
+		{
+			name: "multi-line basic string",
+			toml: "s = \"\"\"\nline one\nline \\\n    two\\t\"\"\"\n",
+			want: `{"s":"line one\nline two\t"}`,
+		},
+		{
+			name: "multi-line literal string",
+			toml: "s = '''\nraw \\n\nsecond'''\n",
+			want: `{"s":"raw \\n\nsecond"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tomlToJSON([]byte(tt.toml))
+			if err != nil {
+				t.Fatalf("tomlToJSON: %v", err)
+			}
+			var got, want any
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("output %s is not JSON: %v", data, err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatalf("bad want: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestTOMLToJSONErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		toml string
+		want string
+	}{
+		{"duplicate key", "a = 1\na = 2\n", "line 2: a set twice"},
+		{"duplicate dotted key", "[x]\ny.z = 1\ny.z = 2\n", "line 3: y.z set twice"},
+		{"duplicate inline key", "t = { a = 1, a = 2 }\n", "line 1: t: a set twice"},
+		{"table defined twice", "[cache]\nttl = 1\n\n[cache]\n", "line 4: table cache defined twice"},
+		{"table over a value", "cache = 1\n[cache]\n", "line 2: cache is not a table"},
+		{"array of tables over a table", "[t]\n[[t]]\n", "line 2: t is not an array of tables"},
+		{"missing equals", "\n\nport 8080\n", "line 3: expected = after port"},
+		{"missing value", "port =", "line 1: port: missing value"},
+		{"trailing garbage", "a = 1 2\n", `line 1: unexpected '2' after value`},
+		{"unterminated basic string", "a = \"open\nb = 1\n", "line 1: a: unterminated string"},
+		{"unterminated literal string", "a = 'open\n", "line 1: a: unterminated string"},
+		{"unterminated multi-line string", "a = \"\"\"\none\ntwo\n", "line 4: a: unterminated string"},
+		{"invalid escape", `a = "\q"` + "\n", `line 1: a: invalid escape \q`},
+		{"invalid unicode escape", `a = "\uZZZZ"` + "\n", `line 1: a: invalid \u escape`},
+		{"short unicode escape", `a = "\u12`, `line 1: a: short \u escape`},
+		{"invalid integer", "a = 0xZZ\n", "line 1: a: invalid integer 0xZZ"},
+		{"invalid float", "a = 1.2.3\n", "line 1: a: invalid float 1.2.3"},
+		{"bare word", "a = yes\n", `line 1: a: unexpected 'y'`},
+		{"date", "a = 2024-01-01\n", "line 1: a: dates and times are not supported; quote 2024-01-01"},
+		{"unclosed array", "a = [1, 2\nb = 3\n", "line 2: a: expected , or ] in array, found 'b'"},
+		{"unclosed header", "[cache\n", "line 1: expected ] after table name"},
+		{"unclosed inline table", "a = { b = 1\n", `line 1: a: expected , or } in inline table, found '\n'`},
+		{"line after multi-line string", "a = '''\nx\ny'''\nb = \n", "line 4: b: unexpected '\\n'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tomlToJSON([]byte(tt.toml))
+			if err == nil {
+				t.Fatalf("tomlToJSON(%q) succeeded, want error %q", tt.toml, tt.want)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("error = %q, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadTOMLFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pricing.toml")
+	const file = `
+port = "9090"
+provider = "mock"
+
+[cors]
+allowed_origins = ["https://wallet.lux.network"]
+`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	l := Bind(fs)
+	if err := fs.Parse([]string{"-config", path}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := l.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Port != "9090" || cfg.Provider != "mock" {
+		t.Errorf("port, provider = %q, %q, want 9090, mock", cfg.Port, cfg.Provider)
+	}
+	if got := cfg.CORS.AllowedOrigins; len(got) != 1 || got[0] != "https://wallet.lux.network" {
+		t.Errorf("cors.allowed_origins = %v", got)
+	}
+
+	if err := os.WriteFile(path, []byte("port = \"9090\"\nnot_a_setting = 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Load(); err == nil || !strings.Contains(err.Error(), "not_a_setting") {
+		t.Errorf("Load with an unknown key: %v, want an error naming it", err)
+	}
+}
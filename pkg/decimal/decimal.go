@@ -0,0 +1,215 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package decimal is exact decimal arithmetic for prices and amounts, so
+// micro-cap prices keep digits that float64 arithmetic and formatting
+// round away
+package decimal
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MaxScale is the most fractional digits String writes; results are
+// rounded to it, halves away from zero
+const MaxScale = 18
+
+// Decimal is an exact rational number written in decimal. The zero value
+// is 0.
+type Decimal struct {
+	r *big.Rat
+}
+
+// Parse reads a decimal such as "0.000000001234" or "1.234e-9".
+// Fractions, hexadecimal and digit separators, which big.Rat also reads,
+// are rejected.
+func Parse(s string) (Decimal, error) {
+	t := strings.TrimSpace(s)
+	if !plain(t) {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	r, ok := new(big.Rat).SetString(t)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	return Decimal{r: r}, nil
+}
+
+// plain reports whether s is a signed decimal number with an optional
+// exponent and nothing else
+func plain(s string) bool {
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	digits, point := 0, false
+	for len(s) > 0 {
+		c := s[0]
+		if c == '.' && !point {
+			point = true
+		} else if c >= '0' && c <= '9' {
+			digits++
+		} else {
+			break
+		}
+		s = s[1:]
+	}
+	if digits == 0 {
+		return false
+	}
+	if s == "" {
+		return true
+	}
+	if s[0] != 'e' && s[0] != 'E' {
+		return false
+	}
+	s = s[1:]
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// FromFloat converts f through its shortest decimal representation, so
+// 0.1 becomes exactly 0.1 rather than the nearest binary fraction
+func FromFloat(f float64) Decimal {
+	d, err := Parse(strconv.FormatFloat(f, 'g', -1, 64))
+	if err != nil {
+		// NaN and infinities have no decimal form
+		return Decimal{}
+	}
+	return d
+}
+
+func (d Decimal) rat() *big.Rat {
+	if d.r == nil {
+		return new(big.Rat)
+	}
+	return d.r
+}
+
+// Add returns d + e
+func (d Decimal) Add(e Decimal) Decimal {
+	return Decimal{r: new(big.Rat).Add(d.rat(), e.rat())}
+}
+
+// Sub returns d - e
+func (d Decimal) Sub(e Decimal) Decimal {
+	return Decimal{r: new(big.Rat).Sub(d.rat(), e.rat())}
+}
+
+// Mul returns d × e
+func (d Decimal) Mul(e Decimal) Decimal {
+	return Decimal{r: new(big.Rat).Mul(d.rat(), e.rat())}
+}
+
+// Quo returns d ÷ e, or 0 if e is 0
+func (d Decimal) Quo(e Decimal) Decimal {
+	if e.IsZero() {
+		return Decimal{}
+	}
+	return Decimal{r: new(big.Rat).Quo(d.rat(), e.rat())}
+}
+
+// IsZero reports whether d is 0
+func (d Decimal) IsZero() bool {
+	return d.rat().Sign() == 0
+}
+
+// Sign returns -1, 0 or 1 as d is negative, zero or positive
+func (d Decimal) Sign() int {
+	return d.rat().Sign()
+}
+
+// Int returns d rounded to the nearest integer, halves away from zero
+func (d Decimal) Int() *big.Int {
+	r := d.rat()
+	q, m := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if m.Abs(m).Lsh(m, 1).Cmp(r.Denom()) >= 0 {
+		q.Add(q, big.NewInt(int64(r.Sign())))
+	}
+	return q
+}
+
+// Floor returns d rounded down to places fractional digits
+func (d Decimal) Floor(places int) Decimal {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	r := d.rat()
+	// Euclidean division by the positive denominator rounds down
+	n := new(big.Int).Div(new(big.Int).Mul(r.Num(), scale), r.Denom())
+	return Decimal{r: new(big.Rat).SetFrac(n, scale)}
+}
+
+// Round returns d rounded to places fractional digits, halves away from
+// zero. Negative places round to tens, hundreds and so on.
+func (d Decimal) Round(places int) Decimal {
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(places))), nil))
+	if places < 0 {
+		scale.Inv(scale)
+	}
+	n := Decimal{r: new(big.Rat).Mul(d.rat(), scale)}.Int()
+	return Decimal{r: new(big.Rat).Quo(new(big.Rat).SetInt(n), scale)}
+}
+
+// RoundSignificant returns d rounded to digits significant digits, halves
+// away from zero
+func (d Decimal) RoundSignificant(digits int) Decimal {
+	if d.IsZero() {
+		return d
+	}
+	exp := int(math.Floor(math.Log10(math.Abs(d.Float64()))))
+	return d.Round(digits - 1 - exp)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Float64 returns the float64 nearest to d
+func (d Decimal) Float64() float64 {
+	f, _ := d.rat().Float64()
+	return f
+}
+
+// String writes d in plain notation with at most MaxScale fractional
+// digits and no trailing zeros, e.g. "0.000000001234"
+func (d Decimal) String() string {
+	s := d.rat().FloatString(MaxScale)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
+
+// MarshalJSON writes d as a JSON string, since JSON numbers are commonly
+// decoded into float64
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON reads a JSON string or number
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	v, err := Parse(strings.Trim(string(b), `"`))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
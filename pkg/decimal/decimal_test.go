@@ -0,0 +1,167 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package decimal_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+)
+
+func mustParse(t *testing.T, s string) decimal.Decimal {
+	t.Helper()
+	d, err := decimal.Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", s, err)
+	}
+	return d
+}
+
+func TestParseString(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"0", "0"},
+		{"65000", "65000"},
+		{"65000.00", "65000"},
+		{"0.000000001234", "0.000000001234"},
+		{"1.234e-9", "0.000000001234"},
+		{"1.5E3", "1500"},
+		{"2e+2", "200"},
+		{"-42.5", "-42.5"},
+		{"-1.2e-3", "-0.0012"},
+		{"+7", "7"},
+		{"-0.0", "0"},
+		{".5", "0.5"},
+		{"5.", "5"},
+		{" 12.5 ", "12.5"},
+		{"123456789012345678901234567890.123456789012345678", "123456789012345678901234567890.123456789012345678"},
+		// Beyond MaxScale digits String rounds, halves away from zero
+		{"0.0000000000000000015", "0.000000000000000002"},
+		{"-0.0000000000000000015", "-0.000000000000000002"},
+		{"1e-19", "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			d := mustParse(t, tt.in)
+			if got := d.String(); got != tt.want {
+				t.Fatalf("Parse(%q).String() = %q, want %q", tt.in, got, tt.want)
+			}
+			// What String writes parses back to itself
+			if again := mustParse(t, tt.want).String(); again != tt.want {
+				t.Errorf("round trip of %q = %q", tt.want, again)
+			}
+		})
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	for _, in := range []string{
+		"", " ", "abc", "1.2.3", "1e", "1e+", "e5", ".", "-", "--1", "+-1", "1,5", "1 000",
+		"NaN", "Inf", "-Inf", "1/3", "1/0", "0x10", "0b1", "1_000", "1e5.5", "$5",
+	} {
+		if d, err := decimal.Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %s, want an error", in, d)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		got  func() decimal.Decimal
+		want string
+	}{
+		{"add", func() decimal.Decimal { return mustParse(t, "0.1").Add(mustParse(t, "0.2")) }, "0.3"},
+		{"sub to negative", func() decimal.Decimal { return mustParse(t, "0.1").Sub(mustParse(t, "0.3")) }, "-0.2"},
+		{"mul", func() decimal.Decimal { return mustParse(t, "1.5").Mul(mustParse(t, "-2.25")) }, "-3.375"},
+		{"mul keeps small digits", func() decimal.Decimal { return mustParse(t, "0.000000001234").Mul(mustParse(t, "1e6")) }, "0.001234"},
+		{"mul rounds at MaxScale", func() decimal.Decimal { return mustParse(t, "0.000000001").Mul(mustParse(t, "0.0000000015")) }, "0.000000000000000002"},
+		{"quo exact", func() decimal.Decimal { return mustParse(t, "1").Quo(mustParse(t, "8")) }, "0.125"},
+		{"quo rounds down", func() decimal.Decimal { return mustParse(t, "1").Quo(mustParse(t, "3")) }, "0.333333333333333333"},
+		{"quo rounds up", func() decimal.Decimal { return mustParse(t, "2").Quo(mustParse(t, "3")) }, "0.666666666666666667"},
+		{"quo negative", func() decimal.Decimal { return mustParse(t, "-2").Quo(mustParse(t, "3")) }, "-0.666666666666666667"},
+		{"quo by zero", func() decimal.Decimal { return mustParse(t, "5").Quo(decimal.Decimal{}) }, "0"},
+		// Quotients stay exact until written: 1/3 × 3 is 1
+		{"quo then mul", func() decimal.Decimal { return mustParse(t, "1").Quo(mustParse(t, "3")).Mul(mustParse(t, "3")) }, "1"},
+		{"zero value", func() decimal.Decimal { return decimal.Decimal{}.Add(mustParse(t, "2")) }, "2"},
+		{"from float", func() decimal.Decimal { return decimal.FromFloat(0.1).Add(decimal.FromFloat(0.2)) }, "0.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got().String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRounding(t *testing.T) {
+	tests := []struct {
+		in                  string
+		places              int
+		round, floor, whole string
+	}{
+		{"2.345", 2, "2.35", "2.34", "2"},
+		{"2.5", 0, "3", "2", "3"},
+		{"-2.5", 0, "-3", "-3", "-3"},
+		{"-2.345", 2, "-2.35", "-2.35", "-2"},
+		{"-2.341", 2, "-2.34", "-2.35", "-2"},
+		{"1234.5", -2, "1200", "1234.5", "1235"},
+		{"0.000000001234", 10, "0.0000000012", "0.0000000012", "0"},
+	}
+	for _, tt := range tests {
+		d := mustParse(t, tt.in)
+		if got := d.Round(tt.places).String(); got != tt.round {
+			t.Errorf("Round(%s, %d) = %s, want %s", tt.in, tt.places, got, tt.round)
+		}
+		if tt.places >= 0 {
+			if got := d.Floor(tt.places).String(); got != tt.floor {
+				t.Errorf("Floor(%s, %d) = %s, want %s", tt.in, tt.places, got, tt.floor)
+			}
+		}
+		if got := d.Int().String(); got != tt.whole {
+			t.Errorf("Int(%s) = %s, want %s", tt.in, got, tt.whole)
+		}
+	}
+}
+
+func TestRoundSignificant(t *testing.T) {
+	tests := []struct {
+		in     string
+		digits int
+		want   string
+	}{
+		{"65432.1", 3, "65400"},
+		{"0.000012345", 3, "0.0000123"},
+		{"-0.000012355", 4, "-0.00001236"},
+		{"0", 3, "0"},
+	}
+	for _, tt := range tests {
+		if got := mustParse(t, tt.in).RoundSignificant(tt.digits).String(); got != tt.want {
+			t.Errorf("RoundSignificant(%s, %d) = %s, want %s", tt.in, tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		A decimal.Decimal `json:"a"`
+		B decimal.Decimal `json:"b"`
+	}
+	if err := json.Unmarshal([]byte(`{"a":"0.000000001234","b":1.5e3}`), &v); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(b) != `{"a":"0.000000001234","b":"1500"}` {
+		t.Errorf("Marshal = %s", b)
+	}
+	if err := json.Unmarshal([]byte(`{"a":"1/3"}`), &v); err == nil {
+		t.Errorf("Unmarshal of a fraction succeeded, want an error")
+	}
+}
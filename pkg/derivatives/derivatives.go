@@ -0,0 +1,162 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package derivatives aggregates perpetual futures funding rates and open
+// interest across venues.
+package derivatives
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// DefaultTTL is how long the venue tickers are reused
+const DefaultTTL = 5 * time.Minute
+
+// ErrNotFound is returned for tokens with no perpetual markets
+var ErrNotFound = errors.New("no perpetual markets")
+
+// Venue is one perpetual market for a token
+type Venue = wire.DerivativesVenue
+
+// Summary aggregates a token's perpetual markets. FundingRate is weighted by
+// open interest; venues are sorted by open interest, largest first.
+type Summary = wire.DerivativesSummary
+
+// Fetcher returns every derivatives ticker, e.g. CoinGecko.FetchDerivatives
+type Fetcher func(ctx context.Context) ([]providers.Derivative, error)
+
+// Resolver returns the ticker symbol of a token id, e.g. "btc" for bitcoin
+type Resolver func(ctx context.Context, tokenID string) (string, error)
+
+// Aggregator caches venue tickers and summarizes them per token
+type Aggregator struct {
+	fetch   Fetcher
+	resolve Resolver
+	ttl     time.Duration
+
+	mu        sync.Mutex
+	tickers   []providers.Derivative
+	fetchedAt time.Time
+	symbols   map[string]string // token id -> symbol
+}
+
+// NewAggregator creates an aggregator that refetches tickers after ttl
+func NewAggregator(fetch Fetcher, resolve Resolver, ttl time.Duration) *Aggregator {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Aggregator{fetch: fetch, resolve: resolve, ttl: ttl, symbols: make(map[string]string)}
+}
+
+// Summary returns funding and open interest for a token, given as a token
+// id (bitcoin) or ticker symbol (btc)
+func (a *Aggregator) Summary(ctx context.Context, token string) (*Summary, error) {
+	token = strings.ToLower(token)
+	tickers, fetchedAt, cached, err := a.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	symbol, err := a.symbolFor(ctx, token, tickers)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := &Summary{Token: token, Symbol: symbol, UpdatedAt: fetchedAt, Cached: cached}
+	var weighted float64
+	for _, t := range tickers {
+		if t.ContractType != "perpetual" || !strings.EqualFold(t.IndexID, symbol) {
+			continue
+		}
+		price, _ := t.Price.Float64()
+		v := Venue{
+			Market:       t.Market,
+			Symbol:       t.Symbol,
+			Price:        price,
+			IndexPrice:   t.Index,
+			Basis:        t.Basis,
+			FundingRate:  t.FundingRate,
+			OpenInterest: t.OpenInterest,
+			Volume24h:    t.Volume24h,
+		}
+		if t.LastTradedAt > 0 {
+			v.LastTradedAt = time.Unix(t.LastTradedAt, 0).UTC()
+		}
+		sum.Venues = append(sum.Venues, v)
+		sum.OpenInterest += v.OpenInterest
+		sum.Volume24h += v.Volume24h
+		weighted += v.FundingRate * v.OpenInterest
+	}
+	if len(sum.Venues) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, token)
+	}
+	if sum.OpenInterest > 0 {
+		sum.FundingRate = weighted / sum.OpenInterest
+	} else {
+		for _, v := range sum.Venues {
+			sum.FundingRate += v.FundingRate
+		}
+		sum.FundingRate /= float64(len(sum.Venues))
+	}
+	sort.Slice(sum.Venues, func(i, j int) bool {
+		return sum.Venues[i].OpenInterest > sum.Venues[j].OpenInterest
+	})
+	return sum, nil
+}
+
+// load returns the tickers, refetching them if they have expired. Stale
+// tickers are returned if the refetch fails.
+func (a *Aggregator) load(ctx context.Context) ([]providers.Derivative, time.Time, bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.tickers != nil && time.Since(a.fetchedAt) < a.ttl {
+		return a.tickers, a.fetchedAt, true, nil
+	}
+	tickers, err := a.fetch(ctx)
+	if err != nil {
+		if a.tickers != nil {
+			return a.tickers, a.fetchedAt, true, nil
+		}
+		return nil, time.Time{}, false, err
+	}
+	a.tickers = tickers
+	a.fetchedAt = time.Now().UTC()
+	return tickers, a.fetchedAt, false, nil
+}
+
+// symbolFor returns the index symbol for token. Tokens that already are
+// an index symbol are used as is; ids are resolved once and remembered.
+func (a *Aggregator) symbolFor(ctx context.Context, token string, tickers []providers.Derivative) (string, error) {
+	for _, t := range tickers {
+		if strings.EqualFold(t.IndexID, token) {
+			return token, nil
+		}
+	}
+
+	a.mu.Lock()
+	symbol, ok := a.symbols[token]
+	a.mu.Unlock()
+	if ok {
+		return symbol, nil
+	}
+
+	symbol, err := a.resolve(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	symbol = strings.ToLower(symbol)
+	a.mu.Lock()
+	a.symbols[token] = symbol
+	a.mu.Unlock()
+	return symbol, nil
+}
@@ -0,0 +1,268 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package deviation compares quotes for the same token across providers
+// and raises alarms when they disagree.
+package deviation
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// Status is the latest comparison for a token
+type Status struct {
+	Token        string             `json:"token"`
+	Median       float64            `json:"median"` // USD
+	Sources      map[string]float64 `json:"sources"`
+	Deviations   map[string]float64 `json:"deviations"`        // (quote - median) / median per source
+	MaxDeviation float64            `json:"max_deviation"`     // largest absolute deviation
+	Outlier      string             `json:"outlier,omitempty"` // source furthest from the median
+	Alarming     bool               `json:"alarming"`
+	AlarmingAt   *time.Time         `json:"alarming_at,omitempty"`
+	Alarms       int64              `json:"alarms"` // times the threshold was crossed since start
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+// Alarm is sent when a token's deviation crosses the threshold in either
+// direction
+type Alarm struct {
+	Event     string             `json:"event"` // "deviation" or "recovered"
+	Token     string             `json:"token"`
+	Source    string             `json:"source"` // the outlier
+	Price     float64            `json:"price"`  // the outlier's quote
+	Median    float64            `json:"median"`
+	Deviation float64            `json:"deviation"`
+	Threshold float64            `json:"threshold"`
+	Sources   map[string]float64 `json:"sources"`
+	Time      time.Time          `json:"time"`
+}
+
+// Options configures a Monitor
+type Options struct {
+	Tokens    []string
+	Threshold float64     // absolute deviation from the median that raises an alarm
+	Notify    func(Alarm) // called on deviation and recovery if set
+}
+
+// source is a provider and the token ids it is asked for
+type source struct {
+	provider providers.Provider
+	ids      map[string]bool // nil means all tokens
+}
+
+// Monitor polls providers for the same tokens and tracks how far each
+// quote is from their median
+type Monitor struct {
+	tokens    []string
+	threshold float64
+	notify    func(Alarm)
+	sources   []source
+
+	mu     sync.RWMutex
+	status map[string]*Status
+}
+
+// NewMonitor creates a monitor. Add sources with AddSource before Run.
+func NewMonitor(opts Options) *Monitor {
+	m := &Monitor{
+		threshold: opts.Threshold,
+		notify:    opts.Notify,
+		status:    make(map[string]*Status),
+	}
+	for _, t := range opts.Tokens {
+		m.tokens = append(m.tokens, strings.ToLower(t))
+	}
+	return m
+}
+
+// AddSource queries p for the given token ids, or for every token if none
+// are given
+func (m *Monitor) AddSource(p providers.Provider, ids ...string) {
+	s := source{provider: p}
+	if len(ids) > 0 {
+		s.ids = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			s.ids[strings.ToLower(id)] = true
+		}
+	}
+	m.sources = append(m.sources, s)
+}
+
+// Threshold returns the alarm threshold
+func (m *Monitor) Threshold() float64 {
+	return m.threshold
+}
+
+// Run polls every interval until ctx is done
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		m.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches quotes from every source once and compares them. Tokens
+// quoted by fewer than two sources are skipped.
+func (m *Monitor) Poll(ctx context.Context) {
+	quotes := make(map[string]map[string]float64) // token id -> source -> price
+	for _, s := range m.sources {
+		var ids []string
+		for _, t := range m.tokens {
+			if s.ids == nil || s.ids[t] {
+				ids = append(ids, t)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		prices, err := s.provider.FetchPrices(ctx, ids, "usd")
+		if err != nil {
+			log.Printf("Deviation monitor: %s: %v", s.provider.Name(), err)
+		}
+		for _, p := range prices {
+			if p.CurrentPrice <= 0 {
+				continue
+			}
+			if quotes[p.ID] == nil {
+				quotes[p.ID] = make(map[string]float64)
+			}
+			quotes[p.ID][s.provider.Name()] = p.CurrentPrice
+		}
+	}
+
+	now := time.Now().UTC()
+	var alarms []Alarm
+	m.mu.Lock()
+	for _, t := range m.tokens {
+		if len(quotes[t]) < 2 {
+			continue
+		}
+		if alarm, ok := m.record(t, quotes[t], now); ok {
+			alarms = append(alarms, alarm)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, a := range alarms {
+		if a.Event == "deviation" {
+			log.Printf("Price deviation: %s from %s is %g, %.2f%% from the median %g",
+				a.Token, a.Source, a.Price, a.Deviation*100, a.Median)
+		} else {
+			log.Printf("Price deviation recovered: %s sources within %.2f%%", a.Token, a.Threshold*100)
+		}
+		if m.notify != nil {
+			m.notify(a)
+		}
+	}
+}
+
+// record updates a token with new quotes and returns an alarm if it
+// crossed the threshold. Callers hold m.mu.
+func (m *Monitor) record(token string, quotes map[string]float64, now time.Time) (Alarm, bool) {
+	st, ok := m.status[token]
+	if !ok {
+		st = &Status{Token: token}
+		m.status[token] = st
+	}
+
+	mid := median(quotes)
+	st.Median = mid
+	st.Sources = quotes
+	st.Deviations = make(map[string]float64, len(quotes))
+	st.MaxDeviation = 0
+	st.Outlier = ""
+	names := make([]string, 0, len(quotes))
+	for name := range quotes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d := (quotes[name] - mid) / mid
+		st.Deviations[name] = d
+		if math.Abs(d) > st.MaxDeviation {
+			st.MaxDeviation = math.Abs(d)
+			st.Outlier = name
+		}
+	}
+	st.UpdatedAt = now
+
+	alarming := st.MaxDeviation >= m.threshold
+	if alarming == st.Alarming {
+		return Alarm{}, false
+	}
+	st.Alarming = alarming
+	event := "recovered"
+	if alarming {
+		event = "deviation"
+		st.Alarms++
+		at := now
+		st.AlarmingAt = &at
+	} else {
+		st.AlarmingAt = nil
+	}
+	return Alarm{
+		Event:     event,
+		Token:     token,
+		Source:    st.Outlier,
+		Price:     quotes[st.Outlier],
+		Median:    mid,
+		Deviation: st.Deviations[st.Outlier],
+		Threshold: m.threshold,
+		Sources:   quotes,
+		Time:      now,
+	}, true
+}
+
+// Snapshot returns the status of every token compared so far, in
+// configured order
+func (m *Monitor) Snapshot() []Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]Status, 0, len(m.tokens))
+	for _, t := range m.tokens {
+		st, ok := m.status[t]
+		if !ok {
+			continue
+		}
+		cp := *st
+		cp.Sources = make(map[string]float64, len(st.Sources))
+		for k, v := range st.Sources {
+			cp.Sources[k] = v
+		}
+		cp.Deviations = make(map[string]float64, len(st.Deviations))
+		for k, v := range st.Deviations {
+			cp.Deviations[k] = v
+		}
+		out = append(out, cp)
+	}
+	return out
+}
+
+// median returns the median of the quoted prices
+func median(quotes map[string]float64) float64 {
+	prices := make([]float64, 0, len(quotes))
+	for _, p := range quotes {
+		prices = append(prices, p)
+	}
+	sort.Float64s(prices)
+	n := len(prices)
+	if n%2 == 1 {
+		return prices[n/2]
+	}
+	return (prices[n/2-1] + prices[n/2]) / 2
+}
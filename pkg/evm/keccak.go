@@ -0,0 +1,90 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package evm
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// Keccak256 returns the Keccak-256 hash of data as used by the EVM. It
+// differs from SHA3-256 only in padding.
+func Keccak256(data ...[]byte) []byte {
+	const rate = 136
+	var (
+		st  [25]uint64
+		buf []byte
+	)
+	for _, d := range data {
+		buf = append(buf, d...)
+	}
+
+	// Pad with 0x01 ... 0x80 to a multiple of the rate
+	n := len(buf)
+	buf = append(buf, make([]byte, rate-n%rate)...)
+	buf[n] ^= 0x01
+	buf[len(buf)-1] ^= 0x80
+
+	for len(buf) > 0 {
+		for i := 0; i < rate/8; i++ {
+			st[i] ^= binary.LittleEndian.Uint64(buf[i*8:])
+		}
+		keccakF(&st)
+		buf = buf[rate:]
+	}
+
+	out := make([]byte, 32)
+	for i := 0; i < 4; i++ {
+		binary.LittleEndian.PutUint64(out[i*8:], st[i])
+	}
+	return out
+}
+
+var roundConstants = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808a, 0x8000000080008000,
+	0x000000000000808b, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008a, 0x0000000000000088, 0x0000000080008009, 0x000000008000000a,
+	0x000000008000808b, 0x800000000000008b, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800a, 0x800000008000000a,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+var (
+	rotations = [24]int{1, 3, 6, 10, 15, 21, 28, 36, 45, 55, 2, 14, 27, 41, 56, 8, 25, 43, 62, 18, 39, 61, 20, 44}
+	lanes     = [24]int{10, 7, 11, 17, 18, 3, 5, 16, 8, 21, 24, 4, 15, 23, 19, 13, 12, 2, 20, 14, 22, 9, 6, 1}
+)
+
+// keccakF is the Keccak-f[1600] permutation
+func keccakF(st *[25]uint64) {
+	var bc [5]uint64
+	for r := 0; r < 24; r++ {
+		// θ
+		for i := 0; i < 5; i++ {
+			bc[i] = st[i] ^ st[i+5] ^ st[i+10] ^ st[i+15] ^ st[i+20]
+		}
+		for i := 0; i < 5; i++ {
+			t := bc[(i+4)%5] ^ bits.RotateLeft64(bc[(i+1)%5], 1)
+			for j := 0; j < 25; j += 5 {
+				st[j+i] ^= t
+			}
+		}
+
+		// ρ and π
+		t := st[1]
+		for i, j := range lanes {
+			t, st[j] = st[j], bits.RotateLeft64(t, rotations[i])
+		}
+
+		// χ
+		for j := 0; j < 25; j += 5 {
+			copy(bc[:], st[j:j+5])
+			for i := 0; i < 5; i++ {
+				st[j+i] ^= ^bc[(i+1)%5] & bc[(i+2)%5]
+			}
+		}
+
+		// ι
+		st[0] ^= roundConstants[r]
+	}
+}
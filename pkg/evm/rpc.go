@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package evm is the small part of the EVM toolchain this service needs:
+// Keccak-256 and a JSON-RPC client.
+package evm
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// RPCError is an error returned by the JSON-RPC endpoint
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("RPC error %d: %s", e.Code, e.Message)
+}
+
+// Client is a minimal EVM JSON-RPC client
+type Client struct {
+	url    string
+	client *http.Client
+	id     atomic.Int64
+}
+
+// NewClient creates a client for the endpoint at url. Requests use
+// transport, or http.DefaultTransport if nil.
+func NewClient(url string, timeout time.Duration, transport http.RoundTripper) *Client {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &Client{url: url, client: &http.Client{Timeout: timeout, Transport: transport}}
+}
+
+// Call invokes method and decodes its result into result, if non-nil
+func (c *Client) Call(ctx context.Context, result any, method string, params ...any) error {
+	if params == nil {
+		params = []any{}
+	}
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      c.id.Add(1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("%s: RPC error: %d - %s", method, resp.StatusCode, string(msg))
+	}
+
+	var doc struct {
+		Result json.RawMessage `json:"result"`
+		Error  *RPCError       `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	if doc.Error != nil {
+		return fmt.Errorf("%s: %w", method, doc.Error)
+	}
+	if result != nil {
+		if err := json.Unmarshal(doc.Result, result); err != nil {
+			return fmt.Errorf("%s: %w", method, err)
+		}
+	}
+	return nil
+}
+
+// Quantity calls a method returning a hex quantity
+func (c *Client) Quantity(ctx context.Context, method string, params ...any) (*big.Int, error) {
+	var s string
+	if err := c.Call(ctx, &s, method, params...); err != nil {
+		return nil, err
+	}
+	n, ok := new(big.Int).SetString(strings.TrimPrefix(s, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("%s: invalid quantity %q", method, s)
+	}
+	return n, nil
+}
+
+// CallContract makes an eth_call of data to the contract at to, against
+// the latest block, and returns the raw result
+func (c *Client) CallContract(ctx context.Context, to string, data []byte) ([]byte, error) {
+	var s string
+	tx := map[string]string{"to": to, "data": "0x" + hex.EncodeToString(data)}
+	if err := c.Call(ctx, &s, "eth_call", tx, "latest"); err != nil {
+		return nil, err
+	}
+	out, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("eth_call: invalid result %q", s)
+	}
+	return out, nil
+}
+
+// Selector returns the 4-byte selector of a function signature, e.g.
+// "balanceOf(address)"
+func Selector(signature string) []byte {
+	return Keccak256([]byte(signature))[:4]
+}
+
+// AddressWord left-pads a 0x address to a 32-byte ABI word
+func AddressWord(address string) []byte {
+	raw, _ := hex.DecodeString(strings.TrimPrefix(address, "0x"))
+	w := make([]byte, 32)
+	if len(raw) <= 32 {
+		copy(w[32-len(raw):], raw)
+	}
+	return w
+}
+
+// HexQuantity encodes n as a JSON-RPC quantity
+func HexQuantity(n *big.Int) string {
+	return "0x" + n.Text(16)
+}
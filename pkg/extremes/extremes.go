@@ -0,0 +1,387 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package extremes tracks all-time and 52-week highs and lows of tokens
+// from the prices the service fetches, and reports new ones.
+package extremes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// year is the span of the 52-week high and low, and of the daily ranges
+// kept per token
+const year = 365 * 24 * time.Hour
+
+// Kinds of new extremes
+const (
+	ATH     = "ath"      // all-time high
+	ATL     = "atl"      // all-time low
+	High52w = "high_52w" // 52-week high that is not an all-time high
+	Low52w  = "low_52w"  // 52-week low that is not an all-time low
+)
+
+// Mark is a price and when it was seen
+type Mark = wire.PriceMark
+
+// Day is a token's price range on one UTC day
+type Day struct {
+	Date time.Time `json:"date"`
+	High Mark      `json:"high"`
+	Low  Mark      `json:"low"`
+}
+
+// Extremes are a token's highs and lows
+type Extremes = wire.Extremes
+
+// Event is emitted when a tracked token sets a new high or low
+type Event struct {
+	Token    string    `json:"token"`
+	Currency string    `json:"currency"`
+	Kind     string    `json:"kind"`
+	Price    float64   `json:"price"`
+	Previous Mark      `json:"previous"`
+	Time     time.Time `json:"time"`
+}
+
+// String describes the event in one line
+func (e Event) String() string {
+	name := map[string]string{
+		ATH: "all-time high", ATL: "all-time low", High52w: "52-week high", Low52w: "52-week low",
+	}[e.Kind]
+	return fmt.Sprintf("%s set a new %s of %g %s (previous %g on %s)",
+		e.Token, name, e.Price, strings.ToUpper(e.Currency), e.Previous.Price, e.Previous.Time.Format("2006-01-02"))
+}
+
+// HistoryFunc returns a token's price history, e.g. history.Service.History
+type HistoryFunc func(ctx context.Context, tokenID, currency string, days int) (*history.Series, error)
+
+// Options configures a Tracker
+type Options struct {
+	// File persists tracked extremes across restarts; memory only if
+	// empty
+	File string
+
+	// Tokens are tracked in each of Currencies (usd if empty). Run
+	// seeds them from a year of history and refreshes them.
+	Tokens     []string
+	Currencies []string
+
+	// Notify receives new highs and lows of tracked tokens, if set
+	Notify func(Event)
+}
+
+// record is a tracked token's extremes
+type record struct {
+	Token    string    `json:"token"`
+	Currency string    `json:"currency"`
+	Since    time.Time `json:"since"`
+	Price    Mark      `json:"price"`
+	ATH      Mark      `json:"ath"`
+	ATL      Mark      `json:"atl"`
+	Days     []Day     `json:"days"` // the last year, oldest first
+}
+
+// Tracker keeps the extremes of tracked tokens
+type Tracker struct {
+	opts    Options
+	history HistoryFunc
+	tracked map[string]bool
+
+	mu      sync.Mutex
+	records map[string]*record
+	dirty   bool
+}
+
+// NewTracker loads tracked extremes from opts.File, or starts empty if it
+// does not exist. history seeds tracked tokens and serves untracked ones.
+func NewTracker(opts Options, history HistoryFunc) (*Tracker, error) {
+	if len(opts.Currencies) == 0 {
+		opts.Currencies = []string{"usd"}
+	}
+	t := &Tracker{opts: opts, history: history, tracked: make(map[string]bool), records: make(map[string]*record)}
+	for _, token := range opts.Tokens {
+		for _, currency := range opts.Currencies {
+			t.tracked[key(token, currency)] = true
+		}
+	}
+	if opts.File == "" {
+		return t, nil
+	}
+
+	data, err := os.ReadFile(opts.File)
+	if os.IsNotExist(err) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []*record
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("extremes file %s: %w", opts.File, err)
+	}
+	for _, r := range list {
+		if k := key(r.Token, r.Currency); t.tracked[k] {
+			t.records[k] = r
+		}
+	}
+	return t, nil
+}
+
+// key identifies a token in a currency
+func key(token, currency string) string {
+	return strings.ToLower(token) + ":" + strings.ToLower(currency)
+}
+
+// Record updates tracked tokens with prices just fetched and emits their
+// new highs and lows; it is a cache.RefreshFunc. Tokens not yet seeded
+// are skipped, so a first price is never reported as a new extreme.
+func (t *Tracker) Record(currency string, prices []*cache.PriceResponse) {
+	var events []Event
+	t.mu.Lock()
+	for _, p := range prices {
+		r, ok := t.records[key(p.ID, currency)]
+		if !ok || p.Price <= 0 || !p.UpdatedAt.After(r.Price.Time) {
+			continue
+		}
+		if e, ok := r.add(Mark{Price: p.Price, Time: p.UpdatedAt.UTC()}); ok {
+			events = append(events, e)
+		}
+		t.dirty = true
+	}
+	t.mu.Unlock()
+
+	if t.opts.Notify != nil {
+		for _, e := range events {
+			t.opts.Notify(e)
+		}
+	}
+}
+
+// add records a price, returning the extreme it sets if any
+func (r *record) add(m Mark) (Event, bool) {
+	high, low := r.year(m.Time)
+	r.Price = m
+	day := m.Time.Truncate(24 * time.Hour)
+	if n := len(r.Days); n > 0 && r.Days[n-1].Date.Equal(day) {
+		d := &r.Days[n-1]
+		if m.Price > d.High.Price {
+			d.High = m
+		}
+		if m.Price < d.Low.Price {
+			d.Low = m
+		}
+	} else {
+		r.Days = append(r.Days, Day{Date: day, High: m, Low: m})
+	}
+	cutoff := m.Time.Add(-year)
+	drop := sort.Search(len(r.Days), func(i int) bool { return r.Days[i].Date.After(cutoff) })
+	if drop > 0 {
+		r.Days = append(r.Days[:0:0], r.Days[drop:]...)
+	}
+
+	e := Event{Token: r.Token, Currency: r.Currency, Price: m.Price, Time: m.Time}
+	switch {
+	case r.ATH.Time.IsZero():
+		r.ATH, r.ATL = m, m
+		return Event{}, false
+	case m.Price > r.ATH.Price:
+		e.Kind, e.Previous, r.ATH = ATH, r.ATH, m
+	case m.Price < r.ATL.Price:
+		e.Kind, e.Previous, r.ATL = ATL, r.ATL, m
+	case high.Time.IsZero():
+		return Event{}, false
+	case m.Price > high.Price:
+		e.Kind, e.Previous = High52w, high
+	case m.Price < low.Price:
+		e.Kind, e.Previous = Low52w, low
+	default:
+		return Event{}, false
+	}
+	return e, true
+}
+
+// year returns the highest and lowest prices in the year before now
+func (r *record) year(now time.Time) (high, low Mark) {
+	cutoff := now.Add(-year).Truncate(24 * time.Hour)
+	for _, d := range r.Days {
+		if d.Date.Before(cutoff) {
+			continue
+		}
+		if high.Time.IsZero() || d.High.Price > high.Price {
+			high = d.High
+		}
+		if low.Time.IsZero() || d.Low.Price < low.Price {
+			low = d.Low
+		}
+	}
+	return high, low
+}
+
+// extremes returns the record's highs and lows
+func (r *record) extremes(tracked bool) *Extremes {
+	high, low := r.year(time.Now())
+	e := &Extremes{
+		Token:    r.Token,
+		Currency: r.Currency,
+		Tracked:  tracked,
+		Since:    r.Since,
+		Price:    r.Price.Price,
+		ATH:      r.ATH,
+		ATL:      r.ATL,
+		High52w:  high,
+		Low52w:   low,
+		Updated:  r.Price.Time,
+	}
+	if r.ATH.Price > 0 {
+		e.FromATH = (r.ATH.Price - r.Price.Price) / r.ATH.Price * 100
+	}
+	return e
+}
+
+// seed builds a record from a year of history
+func (t *Tracker) seed(ctx context.Context, token, currency string) (*record, error) {
+	series, err := t.history(ctx, token, currency, history.MaxDays)
+	if err != nil {
+		return nil, err
+	}
+	if len(series.Points) == 0 {
+		return nil, &providers.NotFoundError{Token: token}
+	}
+	r := &record{Token: token, Currency: currency, Since: series.Points[0].Time.UTC()}
+	for _, p := range series.Points {
+		if p.Price > 0 {
+			r.add(Mark{Price: p.Price, Time: p.Time.UTC()})
+		}
+	}
+	return r, nil
+}
+
+// Get returns a token's extremes: tracked since it was seeded, or from
+// the last year of history otherwise
+func (t *Tracker) Get(ctx context.Context, tokenID, currency string) (*Extremes, error) {
+	tokenID, currency = strings.ToLower(tokenID), strings.ToLower(currency)
+	k := key(tokenID, currency)
+	t.mu.Lock()
+	if r, ok := t.records[k]; ok {
+		defer t.mu.Unlock()
+		return r.extremes(true), nil
+	}
+	t.mu.Unlock()
+
+	r, err := t.seed(ctx, tokenID, currency)
+	if err != nil {
+		return nil, err
+	}
+	return r.extremes(t.tracked[k]), nil
+}
+
+// Run seeds the tracked tokens not yet seeded, then refreshes them every
+// interval until ctx is done, saving changes after each refresh. refresh
+// is typically PriceCache.GetMultiplePrices with a TTL of interval, whose
+// refresh hook calls Record.
+func (t *Tracker) Run(ctx context.Context, interval time.Duration, refresh func(ctx context.Context, tokenIDs []string, currency string) error) {
+	if len(t.opts.Tokens) == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		t.seedAll(ctx)
+		for _, currency := range t.opts.Currencies {
+			if err := refresh(ctx, t.opts.Tokens, currency); err != nil {
+				log.Printf("Refreshing extreme prices in %s: %v", currency, err)
+			}
+		}
+		t.mu.Lock()
+		if t.dirty {
+			if err := t.save(); err != nil {
+				log.Printf("Saving extremes: %v", err)
+			}
+			t.dirty = false
+		}
+		t.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// seedAll seeds the tracked tokens without a record, retrying failures
+// on the next run
+func (t *Tracker) seedAll(ctx context.Context) {
+	for _, token := range t.opts.Tokens {
+		for _, currency := range t.opts.Currencies {
+			k := key(token, currency)
+			t.mu.Lock()
+			_, ok := t.records[k]
+			t.mu.Unlock()
+			if ok {
+				continue
+			}
+			r, err := t.seed(ctx, strings.ToLower(token), strings.ToLower(currency))
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			if err != nil {
+				log.Printf("Seeding %s extremes in %s: %v", token, currency, err)
+				continue
+			}
+			t.mu.Lock()
+			t.records[k] = r
+			t.dirty = true
+			t.mu.Unlock()
+		}
+	}
+}
+
+// save writes the tracked records to the file, replacing it atomically.
+// The caller holds t.mu.
+func (t *Tracker) save() error {
+	if t.opts.File == "" {
+		return nil
+	}
+	list := make([]*record, 0, len(t.records))
+	for _, r := range t.records {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return key(list[i].Token, list[i].Currency) < key(list[j].Token, list[j].Currency)
+	})
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(t.opts.File), filepath.Base(t.opts.File)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), t.opts.File)
+}
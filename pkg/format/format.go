@@ -0,0 +1,223 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package format renders prices as display-ready strings for a locale:
+// currency symbols, digit grouping and enough significant digits for
+// sub-cent tokens.
+package format
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// DefaultLocale is used when none is requested
+const DefaultLocale = "en-US"
+
+// priceDigits is the significant digits shown for prices below one unit
+const priceDigits = 4
+
+// maxDecimals bounds the fractional digits of tiny prices
+const maxDecimals = 18
+
+// Locale is a locale's number conventions
+type Locale struct {
+	Name    string
+	Decimal string // decimal separator
+	Group   string // thousands separator
+
+	// SymbolAfter places the currency symbol after the number, separated
+	// by a space, e.g. "1.234,56 €"
+	SymbolAfter bool
+
+	// PercentSpace separates the percent sign from the number, e.g.
+	// "2,5 %"
+	PercentSpace bool
+}
+
+// Space characters used by locale conventions
+const (
+	nbsp       = " " // no-break space
+	narrowNBSP = " " // narrow no-break space
+)
+
+// locales are the supported locales by name
+var locales = map[string]Locale{
+	"en-US": {Decimal: ".", Group: ","},
+	"en-GB": {Decimal: ".", Group: ","},
+	"en-AU": {Decimal: ".", Group: ","},
+	"en-CA": {Decimal: ".", Group: ","},
+	"en-SG": {Decimal: ".", Group: ","},
+	"de-DE": {Decimal: ",", Group: ".", SymbolAfter: true, PercentSpace: true},
+	"de-AT": {Decimal: ",", Group: nbsp, SymbolAfter: true, PercentSpace: true},
+	"de-CH": {Decimal: ".", Group: "’"},
+	"fr-FR": {Decimal: ",", Group: narrowNBSP, SymbolAfter: true, PercentSpace: true},
+	"fr-CH": {Decimal: ",", Group: narrowNBSP, SymbolAfter: true, PercentSpace: true},
+	"es-ES": {Decimal: ",", Group: ".", SymbolAfter: true, PercentSpace: true},
+	"es-MX": {Decimal: ".", Group: ","},
+	"it-IT": {Decimal: ",", Group: ".", SymbolAfter: true},
+	"nl-NL": {Decimal: ",", Group: "."},
+	"pt-BR": {Decimal: ",", Group: "."},
+	"pt-PT": {Decimal: ",", Group: nbsp, SymbolAfter: true},
+	"pl-PL": {Decimal: ",", Group: nbsp, SymbolAfter: true},
+	"sv-SE": {Decimal: ",", Group: nbsp, SymbolAfter: true, PercentSpace: true},
+	"tr-TR": {Decimal: ",", Group: "."},
+	"ru-RU": {Decimal: ",", Group: nbsp, SymbolAfter: true, PercentSpace: true},
+	"uk-UA": {Decimal: ",", Group: nbsp, SymbolAfter: true},
+	"ja-JP": {Decimal: ".", Group: ","},
+	"ko-KR": {Decimal: ".", Group: ","},
+	"zh-CN": {Decimal: ".", Group: ","},
+	"zh-TW": {Decimal: ".", Group: ","},
+	"vi-VN": {Decimal: ",", Group: ".", SymbolAfter: true},
+	"id-ID": {Decimal: ",", Group: "."},
+	"th-TH": {Decimal: ".", Group: ","},
+}
+
+// languages maps a bare language to its default locale
+var languages = map[string]string{
+	"en": "en-US", "de": "de-DE", "fr": "fr-FR", "es": "es-ES", "it": "it-IT", "nl": "nl-NL",
+	"pt": "pt-BR", "pl": "pl-PL", "sv": "sv-SE", "tr": "tr-TR", "ru": "ru-RU", "uk": "uk-UA",
+	"ja": "ja-JP", "ko": "ko-KR", "zh": "zh-CN", "vi": "vi-VN", "id": "id-ID", "th": "th-TH",
+}
+
+// symbols are currency symbols; other currencies show their upper-case
+// code
+var symbols = map[string]string{
+	"usd": "$", "eur": "€", "gbp": "£", "jpy": "¥", "cny": "CN¥", "krw": "₩", "inr": "₹",
+	"brl": "R$", "rub": "₽", "try": "₺", "uah": "₴", "ils": "₪", "ngn": "₦", "php": "₱",
+	"vnd": "₫", "thb": "฿", "pln": "zł", "cad": "CA$", "aud": "A$", "nzd": "NZ$", "hkd": "HK$",
+	"sgd": "S$", "mxn": "MX$", "twd": "NT$", "btc": "₿", "eth": "Ξ",
+}
+
+// minorUnits are the fractional digits of prices of one unit or more;
+// currencies not listed use 2
+var minorUnits = map[string]int{
+	"jpy": 0, "krw": 0, "vnd": 0, "idr": 0, "clp": 0, "huf": 0,
+	"btc": 8, "eth": 6, "sats": 0, "bits": 2,
+}
+
+// ParseLocale returns the conventions of a locale such as "de-DE",
+// "de_de" or "de"
+func ParseLocale(name string) (*Locale, error) {
+	lang, region, _ := strings.Cut(strings.ReplaceAll(name, "_", "-"), "-")
+	lang = strings.ToLower(lang)
+	key := languages[lang]
+	if region != "" {
+		key = lang + "-" + strings.ToUpper(region)
+	}
+	l, ok := locales[key]
+	if !ok {
+		return nil, fmt.Errorf("unsupported locale %q; locales are %s", name, strings.Join(Locales(), ", "))
+	}
+	l.Name = key
+	return &l, nil
+}
+
+// Locales returns the supported locale names, sorted
+func Locales() []string {
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Values are display-ready renderings of a token's figures
+type Values = wire.FormattedValues
+
+// Values formats a token's price, 24h change in percent, market cap and
+// volume in currency. Zero market cap and volume are left empty.
+func (l *Locale) Values(currency string, price, change24h, marketCap, volume24h float64) *Values {
+	v := &Values{Price: l.Price(price, currency), Change24h: l.Percent(change24h)}
+	if marketCap != 0 {
+		v.MarketCap = l.Money(marketCap, currency, 0)
+	}
+	if volume24h != 0 {
+		v.Volume24h = l.Money(volume24h, currency, 0)
+	}
+	return v
+}
+
+// Price formats a price in currency: with the currency's minor units from
+// one unit up, and with four significant digits below, so sub-cent
+// tokens keep their digits, e.g. "$0.00001234" but "$0.50"
+func (l *Locale) Price(v float64, currency string) string {
+	currency = strings.ToLower(currency)
+	decimals, ok := minorUnits[currency]
+	if !ok {
+		decimals = 2
+	}
+	if abs := math.Abs(v); abs > 0 && abs < 1 {
+		minor := decimals
+		sig := priceDigits - 1 - int(math.Floor(math.Log10(abs)))
+		decimals = min(max(decimals, sig), maxDecimals)
+		for decimals > minor && strings.HasSuffix(strconv.FormatFloat(abs, 'f', decimals, 64), "0") {
+			decimals--
+		}
+	}
+	return l.Money(v, currency, decimals)
+}
+
+// Money formats an amount of currency with a fixed number of decimals
+func (l *Locale) Money(v float64, currency string, decimals int) string {
+	symbol, ok := symbols[strings.ToLower(currency)]
+	if !ok {
+		symbol = strings.ToUpper(currency)
+	}
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	n := l.Number(v, decimals)
+	if l.SymbolAfter {
+		return sign + n + nbsp + symbol
+	}
+	if len(symbol) > 1 && !strings.ContainsAny(symbol, "$¥£€₿Ξ") {
+		symbol += nbsp // codes such as "CHF" are set apart
+	}
+	return sign + symbol + n
+}
+
+// Percent formats a percentage with a sign and two decimals, e.g. "+2.34%"
+func (l *Locale) Percent(v float64) string {
+	sign := "+"
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	space := ""
+	if l.PercentSpace {
+		space = nbsp
+	}
+	return sign + l.Number(v, 2) + space + "%"
+}
+
+// Number formats v with decimals fractional digits and the locale's
+// separators
+func (l *Locale) Number(v float64, decimals int) string {
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	whole, frac, _ := strings.Cut(s, ".")
+	sign := ""
+	if strings.HasPrefix(whole, "-") {
+		sign, whole = "-", whole[1:]
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(l.Group)
+		}
+		b.WriteRune(d)
+	}
+	if frac != "" {
+		b.WriteString(l.Decimal)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
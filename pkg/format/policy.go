@@ -0,0 +1,128 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package format
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+)
+
+// DefaultPolicy is the display precision used if none is configured:
+// cents from 1000 up, four decimals from 1, six from 0.01 and four
+// significant digits below
+var DefaultPolicy = Policy{
+	{Min: 1000, Decimals: 2},
+	{Min: 1, Decimals: 4},
+	{Min: 0.01, Decimals: 6},
+	{Min: 0, Significant: 4},
+}
+
+// Band rounds prices whose magnitude is at least Min: to Significant
+// significant digits if set, otherwise to Decimals fractional digits
+type Band struct {
+	Min         float64 `json:"min"`
+	Decimals    int     `json:"decimals"`
+	Significant int     `json:"significant,omitempty"`
+}
+
+// Policy is the display precision of prices by magnitude band, largest
+// band first, so every surface rounds the same price the same way
+type Policy []Band
+
+// ParsePolicy reads bands written min=Nd for N decimals or min=Ns for N
+// significant digits, comma separated, e.g. 1000=2d,1=4d,0=4s. A band
+// from 0 is required so every price is covered.
+func ParsePolicy(s string) (Policy, error) {
+	var p Policy
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		from, precision, ok := strings.Cut(part, "=")
+		precision = strings.TrimSpace(precision)
+		if !ok || len(precision) < 2 {
+			return nil, fmt.Errorf("invalid band %q: want min=Nd or min=Ns", part)
+		}
+		var b Band
+		var err error
+		if b.Min, err = strconv.ParseFloat(strings.TrimSpace(from), 64); err != nil || b.Min < 0 || math.IsInf(b.Min, 0) {
+			return nil, fmt.Errorf("invalid band %q: min must be a number from 0", part)
+		}
+		n, err := strconv.Atoi(precision[:len(precision)-1])
+		if err != nil || n < 0 || n > decimal.MaxScale {
+			return nil, fmt.Errorf("invalid band %q: digits must be 0 to %d", part, decimal.MaxScale)
+		}
+		switch precision[len(precision)-1] {
+		case 'd':
+			b.Decimals = n
+		case 's':
+			if n == 0 {
+				return nil, fmt.Errorf("invalid band %q: at least 1 significant digit", part)
+			}
+			b.Significant = n
+		default:
+			return nil, fmt.Errorf("invalid band %q: want min=Nd or min=Ns", part)
+		}
+		p = append(p, b)
+	}
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// check sorts the bands, largest first, and checks they cover every price
+// once
+func (p Policy) check() error {
+	sort.Slice(p, func(i, j int) bool { return p[i].Min > p[j].Min })
+	for i := 1; i < len(p); i++ {
+		if p[i].Min == p[i-1].Min {
+			return fmt.Errorf("two bands from %g", p[i].Min)
+		}
+	}
+	if len(p) == 0 || p[len(p)-1].Min != 0 {
+		return errors.New("a band from 0 is required")
+	}
+	return nil
+}
+
+// String writes the policy as ParsePolicy reads it
+func (p Policy) String() string {
+	parts := make([]string, len(p))
+	for i, b := range p {
+		if b.Significant > 0 {
+			parts[i] = fmt.Sprintf("%g=%ds", b.Min, b.Significant)
+		} else {
+			parts[i] = fmt.Sprintf("%g=%dd", b.Min, b.Decimals)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// band returns the band of a price's magnitude
+func (p Policy) band(d decimal.Decimal) Band {
+	abs := math.Abs(d.Float64())
+	for _, b := range p {
+		if abs >= b.Min {
+			return b
+		}
+	}
+	return Band{Significant: priceDigits}
+}
+
+// Round rounds a price by the band of its magnitude, halves away from
+// zero
+func (p Policy) Round(d decimal.Decimal) decimal.Decimal {
+	b := p.band(d)
+	if b.Significant > 0 {
+		return d.RoundSignificant(b.Significant)
+	}
+	return d.Round(b.Decimals)
+}
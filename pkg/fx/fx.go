@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package fx provides fiat foreign exchange rates and derives token prices
+// in currencies the crypto provider does not quote directly.
+package fx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTTL is how long fetched rates are reused
+const DefaultTTL = time.Hour
+
+// Rates are exchange rates relative to Base: 1 Base = Rates[c] units of c.
+// Currency codes are lowercase and Rates includes Base itself.
+type Rates struct {
+	Base      string
+	Rates     map[string]float64
+	UpdatedAt time.Time
+}
+
+// Source fetches the latest exchange rates
+type Source interface {
+	Name() string
+	Fetch(ctx context.Context) (*Rates, error)
+}
+
+// Converter caches rates from a source and converts between currencies
+type Converter struct {
+	source Source
+	ttl    time.Duration
+
+	mu        sync.Mutex
+	rates     *Rates
+	fetchedAt time.Time
+}
+
+// NewConverter creates a converter that refetches rates after ttl
+func NewConverter(source Source, ttl time.Duration) *Converter {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Converter{source: source, ttl: ttl}
+}
+
+// Source returns the name of the rate source
+func (c *Converter) Source() string {
+	return c.source.Name()
+}
+
+// Rates returns the current rates, fetching them if they have expired.
+// Stale rates are returned if the refetch fails.
+func (c *Converter) Rates(ctx context.Context) (*Rates, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.rates != nil && time.Since(c.fetchedAt) < c.ttl {
+		return c.rates, nil
+	}
+
+	rates, err := c.source.Fetch(ctx)
+	if err != nil {
+		if c.rates != nil {
+			return c.rates, nil
+		}
+		return nil, fmt.Errorf("%s: %w", c.source.Name(), err)
+	}
+	c.rates = rates
+	c.fetchedAt = time.Now()
+	return rates, nil
+}
+
+// Rate returns how many units of to one unit of from is worth
+func (c *Converter) Rate(ctx context.Context, from, to string) (float64, error) {
+	rates, err := c.Rates(ctx)
+	if err != nil {
+		return 0, err
+	}
+	from, to = strings.ToLower(from), strings.ToLower(to)
+	fromRate, ok := rates.Rates[from]
+	if !ok || fromRate == 0 {
+		return 0, fmt.Errorf("unsupported currency: %s", from)
+	}
+	toRate, ok := rates.Rates[to]
+	if !ok {
+		return 0, fmt.Errorf("unsupported currency: %s", to)
+	}
+	return toRate / fromRate, nil
+}
+
+// Supports reports whether currency has a known rate
+func (c *Converter) Supports(ctx context.Context, currency string) bool {
+	rates, err := c.Rates(ctx)
+	if err != nil {
+		return false
+	}
+	_, ok := rates.Rates[strings.ToLower(currency)]
+	return ok
+}
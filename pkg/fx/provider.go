@@ -0,0 +1,137 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package fx
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// PriceDigits is the significant digits derived prices are rounded to,
+// halves away from zero. Market caps and volumes are rounded to whole
+// units.
+const PriceDigits = 8
+
+// Provider wraps a price provider so that currencies it does not quote
+// directly are derived from its USD prices with FX rates
+type Provider struct {
+	inner     providers.Provider
+	converter *Converter
+	native    map[string]bool
+	normalize bool
+}
+
+// NewProvider derives prices for currencies outside native from inner's
+// USD prices
+func NewProvider(inner providers.Provider, converter *Converter, native []string) *Provider {
+	p := &Provider{inner: inner, converter: converter, native: make(map[string]bool, len(native))}
+	for _, c := range native {
+		p.native[strings.ToLower(c)] = true
+	}
+	return p
+}
+
+// SetNormalize derives every currency the FX source knows from USD prices,
+// even those the provider quotes, so a token priced only in USD upstream
+// is served consistently in other currencies. Native currencies the FX
+// source doesn't know, such as btc, are still fetched. It must be called
+// before the provider is used.
+func (p *Provider) SetNormalize(normalize bool) {
+	p.normalize = normalize
+}
+
+// derives reports whether prices in currency are derived from USD prices
+func (p *Provider) derives(ctx context.Context, currency string) bool {
+	currency = strings.ToLower(currency)
+	switch {
+	case currency == "usd":
+		return false
+	case !p.native[currency]:
+		return true
+	default:
+		return p.normalize && p.converter.Supports(ctx, currency)
+	}
+}
+
+// Name identifies the wrapped provider
+func (p *Provider) Name() string {
+	return p.inner.Name()
+}
+
+// FetchPrice fetches a price, converting from USD if needed
+func (p *Provider) FetchPrice(ctx context.Context, tokenID, currency string) (*providers.Price, error) {
+	if !p.derives(ctx, currency) {
+		return p.inner.FetchPrice(ctx, tokenID, currency)
+	}
+	rate, err := p.usdRate(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	price, err := p.inner.FetchPrice(ctx, tokenID, "usd")
+	if err != nil {
+		return nil, err
+	}
+	convert(price, rate)
+	return price, nil
+}
+
+// FetchPrices fetches prices, converting from USD if needed
+func (p *Provider) FetchPrices(ctx context.Context, tokenIDs []string, currency string) ([]providers.Price, error) {
+	if !p.derives(ctx, currency) {
+		return p.inner.FetchPrices(ctx, tokenIDs, currency)
+	}
+	rate, err := p.usdRate(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := p.inner.FetchPrices(ctx, tokenIDs, "usd")
+	for i := range prices {
+		convert(&prices[i], rate)
+	}
+	return prices, err
+}
+
+// Markets wraps a fetcher of the largest tokens, such as
+// CoinGecko.FetchTopMarkets, so it derives currencies as the provider
+// does
+func (p *Provider) Markets(fetch func(ctx context.Context, currency string, limit int) ([]providers.Price, error)) func(ctx context.Context, currency string, limit int) ([]providers.Price, error) {
+	return func(ctx context.Context, currency string, limit int) ([]providers.Price, error) {
+		if !p.derives(ctx, currency) {
+			return fetch(ctx, currency, limit)
+		}
+		rate, err := p.usdRate(ctx, currency)
+		if err != nil {
+			return nil, err
+		}
+		prices, err := fetch(ctx, "usd", limit)
+		for i := range prices {
+			convert(&prices[i], rate)
+		}
+		return prices, err
+	}
+}
+
+// usdRate returns the USD to currency rate
+func (p *Provider) usdRate(ctx context.Context, currency string) (float64, error) {
+	rate, err := p.converter.Rate(ctx, "usd", currency)
+	if err != nil {
+		return 0, fmt.Errorf("currency %s: %w", currency, err)
+	}
+	return rate, nil
+}
+
+// convert scales the monetary fields of a USD price by rate, rounding them
+// as PriceDigits describes. The 24h change is left as is, so it ignores FX
+// movement over the day.
+func convert(price *providers.Price, rate float64) {
+	exact := price.Exact().Mul(decimal.FromFloat(rate)).RoundSignificant(PriceDigits)
+	price.CurrentPrice, price.CurrentPriceText = exact.Float64(), exact.String()
+	price.MarketCap = math.Round(price.MarketCap * rate)
+	price.TotalVolume = math.Round(price.TotalVolume * rate)
+}
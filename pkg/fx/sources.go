@@ -0,0 +1,157 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// ECBURL publishes daily euro reference rates for about 30 currencies
+	ECBURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+
+	// ExchangeRateHostURL is the exchangerate.host API root
+	ExchangeRateHostURL = "https://api.exchangerate.host"
+)
+
+// ECB fetches European Central Bank reference rates. It needs no key but
+// covers only major currencies.
+type ECB struct {
+	URL    string
+	client *http.Client
+}
+
+// NewECB creates an ECB source using client, or a default client if nil
+func NewECB(client *http.Client) *ECB {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &ECB{URL: ECBURL, client: client}
+}
+
+// Name identifies the source
+func (e *ECB) Name() string {
+	return "ecb"
+}
+
+// Fetch fetches the latest daily rates
+func (e *ECB) Fetch(ctx context.Context) (*Rates, error) {
+	body, err := get(ctx, e.client, e.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var doc struct {
+		Cube struct {
+			Cube struct {
+				Time  string `xml:"time,attr"`
+				Rates []struct {
+					Currency string  `xml:"currency,attr"`
+					Rate     float64 `xml:"rate,attr"`
+				} `xml:"Cube"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	}
+	if err := xml.NewDecoder(body).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Cube.Cube.Rates) == 0 {
+		return nil, fmt.Errorf("no rates in ECB response")
+	}
+
+	rates := &Rates{Base: "eur", Rates: map[string]float64{"eur": 1}}
+	for _, r := range doc.Cube.Cube.Rates {
+		rates.Rates[strings.ToLower(r.Currency)] = r.Rate
+	}
+	rates.UpdatedAt, _ = time.Parse("2006-01-02", doc.Cube.Cube.Time)
+	return rates, nil
+}
+
+// ExchangeRateHost fetches rates from exchangerate.host, which covers most
+// world currencies
+type ExchangeRateHost struct {
+	BaseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewExchangeRateHost creates an exchangerate.host source using client, or
+// a default client if nil
+func NewExchangeRateHost(apiKey string, client *http.Client) *ExchangeRateHost {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &ExchangeRateHost{BaseURL: ExchangeRateHostURL, apiKey: apiKey, client: client}
+}
+
+// Name identifies the source
+func (x *ExchangeRateHost) Name() string {
+	return "exchangerate.host"
+}
+
+// Fetch fetches the latest rates against USD
+func (x *ExchangeRateHost) Fetch(ctx context.Context) (*Rates, error) {
+	q := url.Values{}
+	q.Set("base", "USD")
+	if x.apiKey != "" {
+		q.Set("access_key", x.apiKey)
+	}
+	body, err := get(ctx, x.client, strings.TrimRight(x.BaseURL, "/")+"/latest?"+q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var doc struct {
+		Base  string             `json:"base"`
+		Date  string             `json:"date"`
+		Rates map[string]float64 `json:"rates"`
+		Error json.RawMessage    `json:"error"`
+	}
+	if err := json.NewDecoder(body).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Rates) == 0 {
+		return nil, fmt.Errorf("no rates in exchangerate.host response: %s", string(doc.Error))
+	}
+
+	base := strings.ToLower(doc.Base)
+	if base == "" {
+		base = "usd"
+	}
+	rates := &Rates{Base: base, Rates: make(map[string]float64, len(doc.Rates)+1)}
+	for c, r := range doc.Rates {
+		rates.Rates[strings.ToLower(c)] = r
+	}
+	rates.Rates[base] = 1
+	rates.UpdatedAt, _ = time.Parse("2006-01-02", doc.Date)
+	return rates, nil
+}
+
+// get performs a GET and returns the body of a 200 response
+func get(ctx context.Context, client *http.Client, u string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		resp.Body.Close()
+		return nil, fmt.Errorf("FX API error: %d - %s", resp.StatusCode, string(body))
+	}
+	return resp.Body, nil
+}
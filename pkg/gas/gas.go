@@ -0,0 +1,228 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package gas estimates transaction fees on EVM chains from their RPC nodes.
+package gas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// DefaultTTL is how long an estimate is reused
+const DefaultTTL = 10 * time.Second
+
+// ErrUnknownChain is returned for chains without a configured RPC node
+var ErrUnknownChain = errors.New("unknown chain")
+
+// Fees are priority fees, or max fees, at three confirmation speeds in gwei
+type Fees = wire.GasFees
+
+// Estimate is a chain's current fee estimate. EIP-1559 fields are zero on
+// chains without a base fee; GasPrice is set on every chain.
+type Estimate = wire.GasEstimate
+
+type entry struct {
+	estimate  Estimate
+	fetchedAt time.Time
+}
+
+// Oracle fetches and caches fee estimates per chain
+type Oracle struct {
+	rpcs   map[string]string
+	ttl    time.Duration
+	client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]entry
+}
+
+// NewOracle creates an oracle for the chains in rpcs (chain name -> RPC
+// URL). Requests use client, or a default client if nil.
+func NewOracle(rpcs map[string]string, ttl time.Duration, client *http.Client) *Oracle {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	o := &Oracle{rpcs: make(map[string]string, len(rpcs)), ttl: ttl, client: client, cache: make(map[string]entry)}
+	for chain, url := range rpcs {
+		o.rpcs[strings.ToLower(chain)] = url
+	}
+	return o
+}
+
+// Chains returns the configured chain names, sorted
+func (o *Oracle) Chains() []string {
+	chains := make([]string, 0, len(o.rpcs))
+	for chain := range o.rpcs {
+		chains = append(chains, chain)
+	}
+	sort.Strings(chains)
+	return chains
+}
+
+// Estimate returns the fee estimate for chain, from cache if it is fresh
+func (o *Oracle) Estimate(ctx context.Context, chain string) (*Estimate, error) {
+	chain = strings.ToLower(chain)
+	url, ok := o.rpcs[chain]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownChain, chain)
+	}
+
+	o.mu.Lock()
+	e, ok := o.cache[chain]
+	o.mu.Unlock()
+	if ok && time.Since(e.fetchedAt) < o.ttl {
+		est := e.estimate
+		est.Cached = true
+		return &est, nil
+	}
+
+	est, err := o.fetch(ctx, chain, url)
+	if err != nil {
+		return nil, err
+	}
+
+	o.mu.Lock()
+	o.cache[chain] = entry{estimate: *est, fetchedAt: time.Now()}
+	o.mu.Unlock()
+	return est, nil
+}
+
+// feeHistory is the eth_feeHistory result
+type feeHistory struct {
+	BaseFeePerGas []string   `json:"baseFeePerGas"`
+	Reward        [][]string `json:"reward"`
+}
+
+// fetch queries the chain's RPC node for a new estimate
+func (o *Oracle) fetch(ctx context.Context, chain, url string) (*Estimate, error) {
+	est := &Estimate{Chain: chain, UpdatedAt: time.Now().UTC()}
+
+	var chainID, block, gasPrice string
+	for _, q := range []struct {
+		method string
+		out    *string
+	}{
+		{"eth_chainId", &chainID},
+		{"eth_blockNumber", &block},
+		{"eth_gasPrice", &gasPrice},
+	} {
+		if err := o.call(ctx, url, q.method, []interface{}{}, q.out); err != nil {
+			return nil, err
+		}
+	}
+	est.ChainID = hexUint(chainID)
+	est.Block = hexUint(block)
+	est.GasPrice = gwei(hexUint(gasPrice))
+
+	// Priority fees at the 10th, 50th and 90th percentile of the last 20
+	// blocks; chains without EIP-1559 fail this call or report no base fee
+	var hist feeHistory
+	if err := o.call(ctx, url, "eth_feeHistory", []interface{}{"0x14", "latest", []int{10, 50, 90}}, &hist); err != nil || len(hist.BaseFeePerGas) == 0 {
+		return est, nil
+	}
+
+	// The last entry is the base fee of the next block
+	est.BaseFee = gwei(hexUint(hist.BaseFeePerGas[len(hist.BaseFeePerGas)-1]))
+	est.PriorityFee = Fees{
+		Slow:     gwei(medianReward(hist.Reward, 0)),
+		Standard: gwei(medianReward(hist.Reward, 1)),
+		Fast:     gwei(medianReward(hist.Reward, 2)),
+	}
+	// Allow the base fee to double before the transaction is priced out
+	est.MaxFee = Fees{
+		Slow:     2*est.BaseFee + est.PriorityFee.Slow,
+		Standard: 2*est.BaseFee + est.PriorityFee.Standard,
+		Fast:     2*est.BaseFee + est.PriorityFee.Fast,
+	}
+	return est, nil
+}
+
+// call makes a JSON-RPC request and decodes its result into out
+func (o *Oracle) call(ctx context.Context, url, method string, params []interface{}, out interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("RPC error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var rpc struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rpc); err != nil {
+		return err
+	}
+	if rpc.Error != nil {
+		return fmt.Errorf("%s: %s (%d)", method, rpc.Error.Message, rpc.Error.Code)
+	}
+	return json.Unmarshal(rpc.Result, out)
+}
+
+// medianReward returns the median across blocks of the reward at
+// percentile index i, ignoring empty blocks
+func medianReward(rewards [][]string, i int) uint64 {
+	var vals []uint64
+	for _, r := range rewards {
+		if i < len(r) {
+			if v := hexUint(r[i]); v > 0 {
+				vals = append(vals, v)
+			}
+		}
+	}
+	if len(vals) == 0 {
+		return 0
+	}
+	sort.Slice(vals, func(a, b int) bool { return vals[a] < vals[b] })
+	return vals[len(vals)/2]
+}
+
+// hexUint parses a 0x-prefixed quantity, returning 0 if it is invalid
+func hexUint(s string) uint64 {
+	v, _ := strconv.ParseUint(strings.TrimPrefix(s, "0x"), 16, 64)
+	return v
+}
+
+// gwei converts wei to gwei
+func gwei(wei uint64) float64 {
+	return float64(wei) / 1e9
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package global serves a cached overview of the whole crypto market.
+package global
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// DefaultTTL is how long the overview is reused
+const DefaultTTL = 5 * time.Minute
+
+// ErrUnsupportedCurrency is returned for currencies the upstream has no
+// totals in
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// Overview is the total market in one quote currency
+type Overview = wire.GlobalOverview
+
+// Fetcher fetches the market overview, e.g. CoinGecko.FetchGlobal
+type Fetcher func(ctx context.Context) (*providers.Global, error)
+
+// Service caches the market overview fetched from an upstream
+type Service struct {
+	fetch Fetcher
+	ttl   time.Duration
+
+	mu        sync.Mutex
+	data      *providers.Global
+	fetchedAt time.Time
+}
+
+// NewService creates a service that refetches the overview after ttl
+func NewService(fetch Fetcher, ttl time.Duration) *Service {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Service{fetch: fetch, ttl: ttl}
+}
+
+// Overview returns the market overview in currency, from cache if it is
+// fresh. Stale data is returned if the refetch fails.
+func (s *Service) Overview(ctx context.Context, currency string) (*Overview, error) {
+	currency = strings.ToLower(currency)
+	data, fetchedAt, cached, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mcap, ok := data.TotalMarketCap[currency]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+	o := &Overview{
+		Currency:               currency,
+		TotalMarketCap:         mcap,
+		TotalVolume24h:         data.TotalVolume[currency],
+		MarketCapChange24h:     data.MarketCapChange24h,
+		BTCDominance:           data.MarketCapPercentage["btc"],
+		ETHDominance:           data.MarketCapPercentage["eth"],
+		Dominance:              data.MarketCapPercentage,
+		ActiveCryptocurrencies: data.ActiveCryptocurrencies,
+		Markets:                data.Markets,
+		UpdatedAt:              fetchedAt,
+		Cached:                 cached,
+	}
+	if data.UpdatedAt > 0 {
+		o.UpdatedAt = time.Unix(data.UpdatedAt, 0).UTC()
+	}
+	return o, nil
+}
+
+// load returns the overview, refetching it if it has expired
+func (s *Service) load(ctx context.Context) (*providers.Global, time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data != nil && time.Since(s.fetchedAt) < s.ttl {
+		return s.data, s.fetchedAt, true, nil
+	}
+	data, err := s.fetch(ctx)
+	if err != nil {
+		if s.data != nil {
+			return s.data, s.fetchedAt, true, nil
+		}
+		return nil, time.Time{}, false, err
+	}
+	s.data = data
+	s.fetchedAt = time.Now().UTC()
+	return data, s.fetchedAt, false, nil
+}
@@ -0,0 +1,299 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package history serves cached token price history.
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+const (
+	// DefaultTTL is how long a series is reused
+	DefaultTTL = 5 * time.Minute
+
+	// DefaultNotFoundTTL is how long a token the upstream doesn't know is
+	// answered as not found without asking again
+	DefaultNotFoundTTL = 5 * time.Minute
+
+	// MaxDays is the longest history served
+	MaxDays = 365
+
+	// dailyAfter is the number of days beyond which histories are daily,
+	// as CoinGecko's are, and can be served from a Store
+	dailyAfter = 90
+
+	// maxSeries bounds the number of cached series before expired ones
+	// are dropped
+	maxSeries = 1024
+)
+
+// Point is a token's price, market cap and volume at a point in time
+type Point = wire.HistoryPoint
+
+// Series is a token's history over the last Days, oldest point first
+type Series = wire.HistorySeries
+
+// Fetcher fetches a token's history, e.g. CoinGecko.FetchMarketChart
+type Fetcher func(ctx context.Context, tokenID, currency string, days int) (*providers.MarketChart, error)
+
+// Service caches history fetched from an upstream
+type Service struct {
+	fetch Fetcher
+	ttl   time.Duration
+
+	mu          sync.Mutex
+	cache       map[string]Series
+	notFound    map[string]time.Time // token id -> expiry
+	notFoundTTL time.Duration
+
+	store *Store
+}
+
+// NewService creates a service that refetches a series after ttl
+func NewService(fetch Fetcher, ttl time.Duration) *Service {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Service{
+		fetch:       fetch,
+		ttl:         ttl,
+		cache:       make(map[string]Series),
+		notFound:    make(map[string]time.Time),
+		notFoundTTL: DefaultNotFoundTTL,
+	}
+}
+
+// SetNotFoundTTL sets how long unknown tokens are remembered; 0 disables
+// negative caching. It is safe to call while the service is in use.
+func (s *Service) SetNotFoundTTL(ttl time.Duration) {
+	if ttl < 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notFoundTTL = ttl
+	if ttl == 0 {
+		s.notFound = make(map[string]time.Time)
+	}
+}
+
+// SetStore keeps daily closes of fetched histories in st, serves daily
+// histories from it when it is current and falls back to it when the
+// upstream fails. It must be called before the service is used.
+func (s *Service) SetStore(st *Store) {
+	s.store = st
+}
+
+// Store returns the history store, or nil if none is set
+func (s *Service) Store() *Store {
+	return s.store
+}
+
+// Backfill fetches a token's history over the last days and stores its
+// daily closes, returning the number of days now stored
+func (s *Service) Backfill(ctx context.Context, tokenID, currency string, days int) (int, error) {
+	if s.store == nil {
+		return 0, errors.New("no history store configured")
+	}
+	if days < 1 || days > MaxDays {
+		return 0, fmt.Errorf("days must be between 1 and %d", MaxDays)
+	}
+	tokenID, currency = strings.ToLower(tokenID), strings.ToLower(currency)
+	chart, err := s.fetch(ctx, tokenID, currency, days)
+	if err != nil {
+		return 0, err
+	}
+	return s.store.Merge(tokenID, currency, points(chart))
+}
+
+// Forget drops a token's not-found result, e.g. once it becomes an alias
+func (s *Service) Forget(tokenID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.notFound, strings.ToLower(tokenID))
+}
+
+// History returns a token's history over the last days (1 to MaxDays),
+// from cache if it is fresh, or from the store for daily histories it
+// covers. Stale or stored data is returned if the refetch fails.
+func (s *Service) History(ctx context.Context, tokenID, currency string, days int) (*Series, error) {
+	if days < 1 || days > MaxDays {
+		return nil, fmt.Errorf("days must be between 1 and %d", MaxDays)
+	}
+	tokenID, currency = strings.ToLower(tokenID), strings.ToLower(currency)
+	key := fmt.Sprintf("%s:%s:%d", tokenID, currency, days)
+
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	unknown := time.Now().Before(s.notFound[tokenID])
+	s.mu.Unlock()
+	if ok && time.Since(cached.UpdatedAt) < s.ttl {
+		cached.Cached = true
+		return &cached, nil
+	}
+	if days > dailyAfter {
+		if stored := s.stored(tokenID, currency, days, true); stored != nil {
+			s.put(key, *stored)
+			return stored, nil
+		}
+	}
+	if !ok && unknown {
+		return nil, &providers.NotFoundError{Token: tokenID}
+	}
+
+	chart, err := s.fetch(ctx, tokenID, currency, days)
+	if err != nil {
+		if ok {
+			cached.Cached = true
+			return &cached, nil
+		}
+		if stored := s.stored(tokenID, currency, days, false); stored != nil {
+			return stored, nil
+		}
+		if errors.Is(err, providers.ErrTokenNotFound) {
+			s.markUnknown(tokenID)
+		}
+		return nil, err
+	}
+
+	series := Series{
+		ID:        tokenID,
+		Currency:  currency,
+		Days:      days,
+		Points:    points(chart),
+		UpdatedAt: time.Now().UTC(),
+	}
+	if s.store != nil {
+		if _, err := s.store.Merge(tokenID, currency, series.Points); err != nil {
+			log.Printf("Storing %s %s history: %v", tokenID, currency, err)
+		}
+	}
+	s.put(key, series)
+	return &series, nil
+}
+
+// stored returns a token's history over the last days from the store:
+// only if the store covers them all when complete, or any of them
+// otherwise. It returns nil if there is no such history.
+func (s *Service) stored(tokenID, currency string, days int, complete bool) *Series {
+	if s.store == nil {
+		return nil
+	}
+	all, err := s.store.Load(tokenID, currency)
+	if err != nil {
+		log.Printf("Loading stored %s %s history: %v", tokenID, currency, err)
+		return nil
+	}
+	now := time.Now().UTC()
+	if complete && !covers(all, days, now) {
+		return nil
+	}
+	points := since(all, now.Truncate(day).Add(-time.Duration(days)*day))
+	if len(points) == 0 {
+		return nil
+	}
+	return &Series{ID: tokenID, Currency: currency, Days: days, Points: points, UpdatedAt: now, Cached: true}
+}
+
+// put caches a series, dropping expired ones once there are maxSeries
+func (s *Service) put(key string, series Series) {
+	s.mu.Lock()
+	if len(s.cache) >= maxSeries {
+		for k, v := range s.cache {
+			if time.Since(v.UpdatedAt) >= s.ttl {
+				delete(s.cache, k)
+			}
+		}
+	}
+	s.cache[key] = series
+	s.mu.Unlock()
+}
+
+// markUnknown remembers that the upstream doesn't know tokenID. Expired
+// entries are dropped once there are as many as maxSeries.
+func (s *Service) markUnknown(tokenID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.notFoundTTL <= 0 {
+		return
+	}
+	now := time.Now()
+	if len(s.notFound) >= maxSeries {
+		for id, expires := range s.notFound {
+			if !now.Before(expires) {
+				delete(s.notFound, id)
+			}
+		}
+		if len(s.notFound) >= maxSeries {
+			return
+		}
+	}
+	s.notFound[tokenID] = now.Add(s.notFoundTTL)
+}
+
+// Bar is the open, high, low and close price over an interval starting at
+// Time
+type Bar struct {
+	Time  time.Time `json:"time"`
+	Open  float64   `json:"open"`
+	High  float64   `json:"high"`
+	Low   float64   `json:"low"`
+	Close float64   `json:"close"`
+}
+
+// barEpoch is the Monday bars are aligned to, so weekly bars start on
+// Mondays and shorter bars on whole days, hours and minutes
+var barEpoch = time.Date(1970, 1, 5, 0, 0, 0, 0, time.UTC)
+
+// Bars groups points, oldest first, into bars of the given width. Bars
+// without points are left out, since a series is no finer than its
+// points.
+func Bars(points []Point, width time.Duration) []Bar {
+	var bars []Bar
+	for _, p := range points {
+		start := p.Time.Add(-((p.Time.Sub(barEpoch)%width + width) % width))
+		if n := len(bars); n > 0 && bars[n-1].Time.Equal(start) {
+			b := &bars[n-1]
+			b.High = max(b.High, p.Price)
+			b.Low = min(b.Low, p.Price)
+			b.Close = p.Price
+			continue
+		}
+		bars = append(bars, Bar{Time: start, Open: p.Price, High: p.Price, Low: p.Price, Close: p.Price})
+	}
+	return bars
+}
+
+// points joins a chart's market caps and volumes to its prices by
+// timestamp
+func points(chart *providers.MarketChart) []Point {
+	caps := make(map[float64]float64, len(chart.MarketCaps))
+	for _, v := range chart.MarketCaps {
+		caps[v[0]] = v[1]
+	}
+	volumes := make(map[float64]float64, len(chart.TotalVolumes))
+	for _, v := range chart.TotalVolumes {
+		volumes[v[0]] = v[1]
+	}
+
+	out := make([]Point, len(chart.Prices))
+	for i, p := range chart.Prices {
+		out[i] = Point{
+			Time:      time.UnixMilli(int64(p[0])).UTC(),
+			Price:     p[1],
+			MarketCap: caps[p[0]],
+			Volume:    volumes[p[0]],
+		}
+	}
+	return out
+}
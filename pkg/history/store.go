@@ -0,0 +1,156 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// day is the resolution of stored history
+const day = 24 * time.Hour
+
+// Store keeps daily closes on disk, one file per token and currency, so
+// long histories survive restarts and can be backfilled ahead of traffic
+type Store struct {
+	dir string
+
+	mu sync.Mutex
+}
+
+// NewStore opens a store in dir, creating it if needed
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Load returns the stored daily closes of a token, oldest first, or none
+// if nothing is stored
+func (st *Store) Load(tokenID, currency string) ([]Point, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.load(tokenID, currency)
+}
+
+// Merge adds the daily closes of points to the stored ones, replacing
+// stored days they cover, and returns the number of days stored. The
+// current UTC day is left out until it has closed.
+func (st *Store) Merge(tokenID, currency string, points []Point) (int, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	stored, err := st.load(tokenID, currency)
+	if err != nil {
+		return 0, err
+	}
+
+	byDay := make(map[time.Time]Point, len(stored)+len(points))
+	for _, p := range stored {
+		byDay[p.Time.Truncate(day)] = p
+	}
+	today := time.Now().UTC().Truncate(day)
+	for _, p := range dailyCloses(points) {
+		if d := p.Time.Truncate(day); d.Before(today) {
+			byDay[d] = p
+		}
+	}
+	merged := make([]Point, 0, len(byDay))
+	for _, p := range byDay {
+		merged = append(merged, p)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Time.Before(merged[j].Time) })
+	return len(merged), st.save(tokenID, currency, merged)
+}
+
+// Covers reports whether the stored closes of a token span the last days
+// up to yesterday
+func (st *Store) Covers(tokenID, currency string, days int) bool {
+	points, err := st.Load(tokenID, currency)
+	return err == nil && covers(points, days, time.Now())
+}
+
+// covers reports whether daily closes, oldest first, span the days
+// before now up to the previous UTC day
+func covers(points []Point, days int, now time.Time) bool {
+	if len(points) == 0 {
+		return false
+	}
+	today := now.UTC().Truncate(day)
+	first, last := points[0].Time.Truncate(day), points[len(points)-1].Time.Truncate(day)
+	return !first.After(today.Add(-time.Duration(days)*day)) && !last.Before(today.Add(-day))
+}
+
+// since returns the points at or after start
+func since(points []Point, start time.Time) []Point {
+	i := sort.Search(len(points), func(i int) bool { return !points[i].Time.Before(start) })
+	return points[i:]
+}
+
+// dailyCloses keeps the last point of each UTC day of points, oldest
+// first
+func dailyCloses(points []Point) []Point {
+	var out []Point
+	for _, p := range points {
+		if n := len(out); n > 0 && out[n-1].Time.Truncate(day).Equal(p.Time.Truncate(day)) {
+			out[n-1] = p
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// path is the file of a token's history. Ids and currencies are checked
+// by the API, but the name is still kept to one path element.
+func (st *Store) path(tokenID, currency string) string {
+	name := strings.ToLower(tokenID) + "." + strings.ToLower(currency) + ".json"
+	return filepath.Join(st.dir, filepath.Base(filepath.Clean("/"+name)))
+}
+
+// load reads a token's stored closes. The caller holds st.mu.
+func (st *Store) load(tokenID, currency string) ([]Point, error) {
+	data, err := os.ReadFile(st.path(tokenID, currency))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var points []Point
+	if err := json.Unmarshal(data, &points); err != nil {
+		return nil, fmt.Errorf("history file %s: %w", st.path(tokenID, currency), err)
+	}
+	return points, nil
+}
+
+// save writes a token's closes, replacing the file atomically. The caller
+// holds st.mu.
+func (st *Store) save(tokenID, currency string, points []Point) error {
+	data, err := json.Marshal(points)
+	if err != nil {
+		return err
+	}
+	path := st.path(tokenID, currency)
+	tmp, err := os.CreateTemp(st.dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package index prices weighted baskets of tokens.
+package index
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// ErrNotFound is returned for undefined indices
+var ErrNotFound = errors.New("index not found")
+
+// Constituent is one token of an index and its share of the value
+type Constituent = wire.IndexConstituent
+
+// Value is an index's current value. The value is the weighted sum of its
+// constituents' prices; Change24h compares it with the same sum over
+// their prices 24 hours ago.
+type Value = wire.IndexValue
+
+// Pricer returns prices for several tokens, e.g. PriceCache.GetMultiplePrices
+type Pricer func(ctx context.Context, tokenIDs []string, currency string) (*cache.MultiPriceResponse, error)
+
+// basket is a defined index with normalized weights
+type basket struct {
+	name    string
+	tokens  []string // sorted
+	weights map[string]float64
+}
+
+// Service prices the configured indices from current token prices
+type Service struct {
+	prices  Pricer
+	baskets map[string]basket // lower-case name -> basket
+}
+
+// NewService creates a service for indices, given as index name -> token
+// id -> weight. Weights are relative: 40/30/30 and 0.4/0.3/0.3 are the same.
+func NewService(indices map[string]map[string]float64, prices Pricer) *Service {
+	s := &Service{prices: prices, baskets: make(map[string]basket, len(indices))}
+	for name, weights := range indices {
+		var total float64
+		for _, w := range weights {
+			total += w
+		}
+		b := basket{name: name, weights: make(map[string]float64, len(weights))}
+		for token, w := range weights {
+			token = strings.ToLower(token)
+			b.tokens = append(b.tokens, token)
+			b.weights[token] = w / total
+		}
+		sort.Strings(b.tokens)
+		s.baskets[strings.ToLower(name)] = b
+	}
+	return s
+}
+
+// Names returns the defined index names, sorted
+func (s *Service) Names() []string {
+	names := make([]string, 0, len(s.baskets))
+	for _, b := range s.baskets {
+		names = append(names, b.name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Tokens returns the constituent token ids of an index
+func (s *Service) Tokens(name string) ([]string, error) {
+	b, ok := s.baskets[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return append([]string(nil), b.tokens...), nil
+}
+
+// Weights returns the constituents of an index and their normalized
+// weights
+func (s *Service) Weights(name string) (map[string]float64, error) {
+	b, ok := s.baskets[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	weights := make(map[string]float64, len(b.weights))
+	for token, w := range b.weights {
+		weights[token] = w
+	}
+	return weights, nil
+}
+
+// Value computes an index in currency from the current constituent
+// prices, so it changes whenever they are refreshed
+func (s *Service) Value(ctx context.Context, name, currency string) (*Value, error) {
+	b, ok := s.baskets[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	resp, err := s.prices(ctx, b.tokens, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Value{Name: b.name, Currency: currency, Cached: true}
+	var previous float64
+	for _, token := range b.tokens {
+		p, ok := resp.Prices[token]
+		if !ok {
+			return nil, fmt.Errorf("no %s price for %s", currency, token)
+		}
+		c := Constituent{
+			Token:        token,
+			Weight:       b.weights[token],
+			Price:        p.Price,
+			Change24h:    p.Change24h,
+			Contribution: b.weights[token] * p.Price,
+		}
+		v.Constituents = append(v.Constituents, c)
+		v.Value += c.Contribution
+		if c.Change24h > -100 {
+			previous += c.Contribution / (1 + c.Change24h/100)
+		}
+		if v.UpdatedAt.IsZero() || p.UpdatedAt.Before(v.UpdatedAt) {
+			v.UpdatedAt = p.UpdatedAt
+		}
+		v.Cached = v.Cached && p.Cached
+	}
+	if previous > 0 {
+		v.Change24h = (v.Value/previous - 1) * 100
+	}
+	return v, nil
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package listings tracks the coins a provider lists and reports the ones
+// newly listed and delisted, so token registries can follow along.
+package listings
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// Change events
+const (
+	Listed   = "listed"
+	Delisted = "delisted"
+)
+
+// DefaultRetention is how long changes are kept when unset
+const DefaultRetention = 90 * 24 * time.Hour
+
+// maxDelistedShare is the largest share of known coins one check may
+// delist. A list missing more is taken for a truncated upstream response
+// rather than a mass delisting.
+const maxDelistedShare = 0.05
+
+// Coin is a listed coin
+type Coin = wire.ListedCoin
+
+// Change is a coin listed or delisted between two checks
+type Change = wire.ListingChange
+
+// Feed is the changes since a time
+type Feed = wire.ListingFeed
+
+// Fetcher fetches the full coin list, e.g. CoinGecko.FetchCoinList
+type Fetcher func(ctx context.Context) ([]providers.ListedCoin, error)
+
+// Options configures a Tracker
+type Options struct {
+	// File persists the coin list and changes across restarts, so coins
+	// listed or delisted while the service was down are still reported;
+	// memory only if empty
+	File string
+
+	// Retention is how long changes are kept; DefaultRetention if 0
+	Retention time.Duration
+
+	// Notify receives each change, if set
+	Notify func(Change)
+}
+
+// state is what the tracker persists
+type state struct {
+	Coins     map[string]Coin `json:"coins"`
+	Changes   []Change        `json:"changes"`
+	CheckedAt time.Time       `json:"checked_at"`
+}
+
+// Tracker compares the provider's coin list with the last one seen
+type Tracker struct {
+	opts  Options
+	fetch Fetcher
+
+	mu    sync.RWMutex
+	state state
+}
+
+// NewTracker loads the last coin list and changes from opts.File, or
+// starts empty if it does not exist. The first check of an empty tracker
+// only records the list.
+func NewTracker(opts Options, fetch Fetcher) (*Tracker, error) {
+	if opts.Retention <= 0 {
+		opts.Retention = DefaultRetention
+	}
+	t := &Tracker{opts: opts, fetch: fetch}
+	if opts.File == "" {
+		return t, nil
+	}
+
+	data, err := os.ReadFile(opts.File)
+	if os.IsNotExist(err) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &t.state); err != nil {
+		return nil, fmt.Errorf("listings file %s: %w", opts.File, err)
+	}
+	return t, nil
+}
+
+// Changes returns the changes found after since, oldest first
+func (t *Tracker) Changes(since time.Time) *Feed {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	feed := &Feed{Since: since.UTC(), CheckedAt: t.state.CheckedAt, Coins: len(t.state.Coins), Changes: []Change{}}
+	i := sort.Search(len(t.state.Changes), func(i int) bool { return t.state.Changes[i].Time.After(since) })
+	feed.Changes = append(feed.Changes, t.state.Changes[i:]...)
+	return feed
+}
+
+// Check fetches the coin list and records the coins listed and delisted
+// since the last check, notifying each change
+func (t *Tracker) Check(ctx context.Context) error {
+	list, err := t.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		return errors.New("empty coin list")
+	}
+	coins := make(map[string]Coin, len(list))
+	for _, c := range list {
+		if c.ID != "" {
+			coins[c.ID] = Coin{ID: c.ID, Symbol: c.Symbol, Name: c.Name}
+		}
+	}
+
+	now := time.Now().UTC()
+	t.mu.Lock()
+	var changes []Change
+	if t.state.Coins != nil {
+		var delisted []Change
+		for id, c := range t.state.Coins {
+			if _, ok := coins[id]; !ok {
+				delisted = append(delisted, Change{Event: Delisted, ListedCoin: c, Time: now})
+			}
+		}
+		if limit := max(int(float64(len(t.state.Coins))*maxDelistedShare), 1); len(delisted) > limit {
+			t.mu.Unlock()
+			return fmt.Errorf("coin list drops %d of %d coins; ignoring it as incomplete", len(delisted), len(t.state.Coins))
+		}
+		for id, c := range coins {
+			if _, ok := t.state.Coins[id]; !ok {
+				changes = append(changes, Change{Event: Listed, ListedCoin: c, Time: now})
+			}
+		}
+		changes = append(changes, delisted...)
+		sort.Slice(changes, func(i, j int) bool {
+			if changes[i].Event != changes[j].Event {
+				return changes[i].Event == Listed
+			}
+			return changes[i].ID < changes[j].ID
+		})
+	}
+	t.state.Coins = coins
+	t.state.CheckedAt = now
+	t.state.Changes = append(t.state.Changes, changes...)
+	cutoff := now.Add(-t.opts.Retention)
+	if drop := sort.Search(len(t.state.Changes), func(i int) bool { return t.state.Changes[i].Time.After(cutoff) }); drop > 0 {
+		t.state.Changes = append(t.state.Changes[:0:0], t.state.Changes[drop:]...)
+	}
+	err = t.save()
+	t.mu.Unlock()
+
+	if t.opts.Notify != nil {
+		for _, c := range changes {
+			t.opts.Notify(c)
+		}
+	}
+	return err
+}
+
+// Run checks the coin list every interval until ctx is done
+func (t *Tracker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := t.Check(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("Checking coin listings: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// save writes the state to the file, replacing it atomically. The caller
+// holds t.mu.
+func (t *Tracker) save() error {
+	if t.opts.File == "" {
+		return nil
+	}
+	data, err := json.Marshal(t.state)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(t.opts.File), filepath.Base(t.opts.File)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), t.opts.File)
+}
@@ -0,0 +1,402 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package logos proxies and caches token logos, so frontends need not
+// hotlink provider CDNs whose image URLs change and rate limit.
+package logos
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // decoders for resizing
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTTL is how long a logo is served before it is refetched, if
+	// none is configured
+	DefaultTTL = 24 * time.Hour
+
+	// DefaultMaxBytes bounds the cached images if no bound is configured
+	DefaultMaxBytes = 64 << 20
+
+	// MinSize and MaxSize bound the sizes logos are resized to, in pixels
+	MinSize = 16
+	MaxSize = 512
+
+	// maxImageBytes bounds a fetched image
+	maxImageBytes = 2 << 20
+
+	// maxDimension bounds the width and height of a fetched image
+	maxDimension = 4096
+
+	// missingTTL is the longest a token without a logo is remembered
+	missingTTL = time.Hour
+)
+
+// DefaultHosts are the hosts logos are fetched from if none are configured
+var DefaultHosts = []string{"coin-images.coingecko.com", "assets.coingecko.com"}
+
+var (
+	// ErrNotFound is returned for tokens without a logo
+	ErrNotFound = errors.New("logo not found")
+
+	// ErrHost is returned when a logo URL is on a host not allowed
+	ErrHost = errors.New("logo host not allowed")
+)
+
+// Resolver returns the URL of a token's logo, or an error wrapping
+// ErrNotFound if it has none
+type Resolver func(ctx context.Context, tokenID string) (string, error)
+
+// Options configures a Service
+type Options struct {
+	TTL      time.Duration // DefaultTTL if zero
+	MaxBytes int64         // DefaultMaxBytes if zero
+
+	// Hosts are the hosts logos may be fetched from, and their
+	// subdomains; DefaultHosts if empty
+	Hosts []string
+}
+
+// Logo is a token's logo image
+type Logo struct {
+	Data        []byte
+	ContentType string
+	ETag        string
+	FetchedAt   time.Time
+}
+
+// entry is a cached logo, or the absence of one
+type entry struct {
+	key     string
+	logo    *Logo // nil if the token has no logo
+	expires time.Time
+}
+
+// call is a fetch in progress that other requests for the key wait on
+type call struct {
+	done chan struct{}
+	logo *Logo
+	err  error
+}
+
+// Service fetches token logos through a Resolver and caches them, resized
+// on request, in memory up to MaxBytes, least recently used first out
+type Service struct {
+	opts    Options
+	resolve Resolver
+	client  *http.Client
+
+	mu       sync.Mutex
+	entries  map[string]*list.Element // token@size -> entry
+	lru      *list.List
+	bytes    int64
+	inflight map[string]*call
+}
+
+// NewService creates a logo proxy resolving logo URLs with resolve.
+// Images are fetched with client, or a default client if nil; redirects
+// must stay on the allowed hosts.
+func NewService(opts Options, resolve Resolver, client *http.Client) *Service {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultMaxBytes
+	}
+	if len(opts.Hosts) == 0 {
+		opts.Hosts = DefaultHosts
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	s := &Service{
+		opts:     opts,
+		resolve:  resolve,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		inflight: make(map[string]*call),
+	}
+	c := *client
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return errors.New("too many redirects")
+		}
+		return s.checkHost(req.URL)
+	}
+	s.client = &c
+	return s
+}
+
+// TTL returns how long logos are served before they are refetched
+func (s *Service) TTL() time.Duration {
+	return s.opts.TTL
+}
+
+// checkHost returns ErrHost unless u is https or http on an allowed host
+func (s *Service) checkHost(u *url.URL) error {
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return fmt.Errorf("%w: %s", ErrHost, u.Redacted())
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, h := range s.opts.Hosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrHost, host)
+}
+
+// Logo returns a token's logo, scaled down to fit size by size pixels if
+// size is not zero. Images that cannot be decoded, such as SVG and WebP,
+// are returned as they are. A logo that fails to refetch is served stale.
+func (s *Service) Logo(ctx context.Context, tokenID string, size int) (*Logo, error) {
+	tokenID = strings.ToLower(tokenID)
+	key := tokenID + "@" + strconv.Itoa(size)
+
+	s.mu.Lock()
+	var stale *entry
+	if el, ok := s.entries[key]; ok {
+		e := el.Value.(*entry)
+		s.lru.MoveToFront(el)
+		if time.Now().Before(e.expires) {
+			s.mu.Unlock()
+			if e.logo == nil {
+				return nil, fmt.Errorf("%w: %s", ErrNotFound, tokenID)
+			}
+			return e.logo, nil
+		}
+		stale = e
+	}
+	c, ok := s.inflight[key]
+	if !ok {
+		c = &call{done: make(chan struct{})}
+		s.inflight[key] = c
+		go s.fetch(key, tokenID, size, c)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.done:
+	}
+	if c.err != nil && !errors.Is(c.err, ErrNotFound) && stale != nil && stale.logo != nil {
+		return stale.logo, nil
+	}
+	return c.logo, c.err
+}
+
+// fetch loads a logo for the callers waiting on c and caches the result.
+// It is detached from any one caller, so a client giving up does not
+// waste the fetch for the others.
+func (s *Service) fetch(key, tokenID string, size int, c *call) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if size == 0 {
+		c.logo, c.err = s.download(ctx, tokenID)
+	} else {
+		var orig *Logo
+		if orig, c.err = s.Logo(ctx, tokenID, 0); c.err == nil {
+			c.logo = resize(orig, size)
+		}
+	}
+
+	s.mu.Lock()
+	delete(s.inflight, key)
+	switch {
+	case c.err == nil:
+		s.store(key, c.logo, s.opts.TTL)
+	case errors.Is(c.err, ErrNotFound):
+		ttl := s.opts.TTL
+		if ttl > missingTTL {
+			ttl = missingTTL
+		}
+		s.store(key, nil, ttl)
+	}
+	s.mu.Unlock()
+	close(c.done)
+}
+
+// store caches a logo, evicting the least recently used ones over
+// MaxBytes. The caller holds s.mu.
+func (s *Service) store(key string, logo *Logo, ttl time.Duration) {
+	if el, ok := s.entries[key]; ok {
+		s.remove(el)
+	}
+	e := &entry{key: key, logo: logo, expires: time.Now().Add(ttl)}
+	s.entries[key] = s.lru.PushFront(e)
+	s.bytes += e.size()
+	for s.bytes > s.opts.MaxBytes && s.lru.Len() > 1 {
+		s.remove(s.lru.Back())
+	}
+}
+
+// remove drops a cached entry. The caller holds s.mu.
+func (s *Service) remove(el *list.Element) {
+	e := el.Value.(*entry)
+	s.lru.Remove(el)
+	delete(s.entries, e.key)
+	s.bytes -= e.size()
+}
+
+// Forget drops a token's cached logos at every size, so the next request
+// resolves its URL again
+func (s *Service) Forget(tokenID string) {
+	prefix := strings.ToLower(tokenID) + "@"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, el := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			s.remove(el)
+		}
+	}
+}
+
+// size is what an entry counts against MaxBytes
+func (e *entry) size() int64 {
+	if e.logo == nil {
+		return 0
+	}
+	return int64(len(e.logo.Data))
+}
+
+// download resolves and fetches a token's logo as published
+func (s *Service) download(ctx context.Context, tokenID string) (*Logo, error) {
+	raw, err := s.resolve(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	if raw == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, tokenID)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("logo url of %s: %w", tokenID, err)
+	}
+	if err := s.checkHost(u); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "image/*")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, tokenID)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("logo of %s: %s answered %d", tokenID, u.Hostname(), resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxImageBytes {
+		return nil, fmt.Errorf("logo of %s: larger than %d bytes", tokenID, maxImageBytes)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = http.DetectContentType(data)
+	}
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("logo of %s: not an image (%s)", tokenID, contentType)
+	}
+	// A small file can still decode to an enormous image
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil && (cfg.Width > maxDimension || cfg.Height > maxDimension) {
+		return nil, fmt.Errorf("logo of %s: %dx%d pixels, more than %d a side", tokenID, cfg.Width, cfg.Height, maxDimension)
+	}
+	return newLogo(data, contentType), nil
+}
+
+// newLogo wraps image data with its ETag
+func newLogo(data []byte, contentType string) *Logo {
+	sum := sha256.Sum256(data)
+	return &Logo{
+		Data:        data,
+		ContentType: contentType,
+		ETag:        `"` + hex.EncodeToString(sum[:8]) + `"`,
+		FetchedAt:   time.Now().UTC(),
+	}
+}
+
+// resize scales a logo down to fit size by size pixels as a PNG. Logos
+// already small enough, or that cannot be decoded, are returned as is.
+func resize(logo *Logo, size int) *Logo {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(logo.Data))
+	if err != nil || cfg.Width > maxDimension || cfg.Height > maxDimension {
+		return logo
+	}
+	if cfg.Width <= size && cfg.Height <= size {
+		return logo
+	}
+	src, _, err := image.Decode(bytes.NewReader(logo.Data))
+	if err != nil {
+		return logo
+	}
+
+	w, h := size, size
+	if cfg.Width > cfg.Height {
+		h = max(1, cfg.Height*size/cfg.Width)
+	} else {
+		w = max(1, cfg.Width*size/cfg.Height)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, scale(src, w, h)); err != nil {
+		return logo
+	}
+	resized := newLogo(buf.Bytes(), "image/png")
+	resized.FetchedAt = logo.FetchedAt
+	return resized
+}
+
+// scale downsamples src to w by h pixels, averaging the source pixels
+// each destination pixel covers
+func scale(src image.Image, w, h int) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(y0+1, b.Min.Y+(y+1)*b.Dy()/h)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(x0+1, b.Min.X+(x+1)*b.Dx()/w)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(pr), g+uint64(pg), bl+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8(r / n >> 8)
+			dst.Pix[i+1] = uint8(g / n >> 8)
+			dst.Pix[i+2] = uint8(bl / n >> 8)
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package logos_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/luxfi/pricing/pkg/logos"
+)
+
+// pngOf encodes a blank w by h image
+func pngOf(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// cdn serves body as image/png at every path and counts the requests
+func cdn(t *testing.T, body []byte) (*httptest.Server, *atomic.Int32) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+// newService fetches every token's logo from url, allowing only the
+// loopback address as a host
+func newService(url string) *logos.Service {
+	return logos.NewService(logos.Options{Hosts: []string{"127.0.0.1"}}, func(ctx context.Context, tokenID string) (string, error) {
+		return url, nil
+	}, nil)
+}
+
+func TestLogoCachedAndResized(t *testing.T) {
+	srv, hits := cdn(t, pngOf(t, 64, 32))
+	s := newService(srv.URL + "/bitcoin.png")
+	ctx := context.Background()
+
+	logo, err := s.Logo(ctx, "Bitcoin", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logo.ContentType != "image/png" || logo.ETag == "" {
+		t.Errorf("logo %s with ETag %q, want image/png with an ETag", logo.ContentType, logo.ETag)
+	}
+
+	small, err := s.Logo(ctx, "bitcoin", 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := png.DecodeConfig(bytes.NewReader(small.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Width != 32 || cfg.Height != 16 {
+		t.Errorf("resized to %dx%d, want 32x16", cfg.Width, cfg.Height)
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("fetched %d times, want once for both sizes", n)
+	}
+}
+
+func TestLogoRedirectOffAllowedHosts(t *testing.T) {
+	other, otherHits := cdn(t, pngOf(t, 8, 8))
+	// localhost is the same machine but not an allowed host
+	target := strings.Replace(other.URL, "127.0.0.1", "localhost", 1)
+	srv := httptest.NewServer(http.RedirectHandler(target+"/logo.png", http.StatusFound))
+	t.Cleanup(srv.Close)
+
+	_, err := newService(srv.URL+"/bitcoin.png").Logo(context.Background(), "bitcoin", 0)
+	if !errors.Is(err, logos.ErrHost) {
+		t.Errorf("got %v, want ErrHost", err)
+	}
+	if n := otherHits.Load(); n != 0 {
+		t.Errorf("the redirect target was fetched %d times", n)
+	}
+}
+
+func TestLogoURLOffAllowedHosts(t *testing.T) {
+	for _, url := range []string{"https://evil.example/logo.png", "file:///etc/passwd", "http://127.0.0.1.evil.example/logo.png"} {
+		_, err := newService(url).Logo(context.Background(), "bitcoin", 0)
+		if !errors.Is(err, logos.ErrHost) {
+			t.Errorf("%s: got %v, want ErrHost", url, err)
+		}
+	}
+}
+
+func TestLogoTooManyBytes(t *testing.T) {
+	// A valid PNG padded past the 2 MiB bound
+	body := append(pngOf(t, 8, 8), make([]byte, 2<<20)...)
+	srv, _ := cdn(t, body)
+
+	_, err := newService(srv.URL).Logo(context.Background(), "bitcoin", 0)
+	if err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("got %v, want the body refused as too large", err)
+	}
+}
+
+func TestLogoTooManyPixels(t *testing.T) {
+	for _, dims := range [][2]int{{4097, 1}, {1, 4097}} {
+		srv, _ := cdn(t, pngOf(t, dims[0], dims[1]))
+		for _, size := range []int{0, 64} {
+			if _, err := newService(srv.URL).Logo(context.Background(), "bitcoin", size); err == nil {
+				t.Errorf("%dx%d image at size %d was accepted", dims[0], dims[1], size)
+			}
+		}
+	}
+	// The bound itself is allowed
+	srv, _ := cdn(t, pngOf(t, 4096, 1))
+	if _, err := newService(srv.URL).Logo(context.Background(), "bitcoin", 0); err != nil {
+		t.Errorf("4096x1 image: %v", err)
+	}
+}
+
+func TestLogoNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+
+	if _, err := newService(srv.URL).Logo(context.Background(), "bitcoin", 0); !errors.Is(err, logos.ErrNotFound) {
+		t.Errorf("upstream 404: got %v, want ErrNotFound", err)
+	}
+	if _, err := newService("").Logo(context.Background(), "bitcoin", 0); !errors.Is(err, logos.ErrNotFound) {
+		t.Errorf("no logo URL: got %v, want ErrNotFound", err)
+	}
+}
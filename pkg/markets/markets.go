@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package markets serves the largest tokens by market cap.
+package markets
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+const (
+	// DefaultTTL is how long a market list is reused
+	DefaultTTL = 5 * time.Minute
+
+	// MaxLimit is the most tokens a list holds
+	MaxLimit = providers.MaxIDsPerRequest
+)
+
+// MarketAsset is a token's rank, price and market data
+type MarketAsset = wire.MarketAsset
+
+// List is the largest tokens by market cap, largest first
+type List = wire.MarketList
+
+// Fetcher fetches the largest tokens, e.g. CoinGecko.FetchTopMarkets
+type Fetcher func(ctx context.Context, currency string, limit int) ([]providers.Price, error)
+
+// Service caches the market list per currency
+type Service struct {
+	fetch Fetcher
+	ttl   time.Duration
+
+	mu    sync.Mutex
+	cache map[string]List
+}
+
+// NewService creates a service that refetches a currency's list after ttl
+func NewService(fetch Fetcher, ttl time.Duration) *Service {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Service{fetch: fetch, ttl: ttl, cache: make(map[string]List)}
+}
+
+// Top returns the limit largest tokens in currency. The full list of
+// MaxLimit tokens is fetched and cached, so any limit is served from one
+// upstream call. Stale data is returned if the refetch fails.
+func (s *Service) Top(ctx context.Context, currency string, limit int) (*List, error) {
+	currency = strings.ToLower(currency)
+	if limit <= 0 || limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	s.mu.Lock()
+	cached, ok := s.cache[currency]
+	s.mu.Unlock()
+	if ok && time.Since(cached.UpdatedAt) < s.ttl {
+		cached.Cached = true
+		return truncate(cached, limit), nil
+	}
+
+	prices, err := s.fetch(ctx, currency, MaxLimit)
+	if err != nil {
+		if ok {
+			cached.Cached = true
+			return truncate(cached, limit), nil
+		}
+		return nil, err
+	}
+
+	// Rank by market cap rather than trusting the upstream order
+	sort.SliceStable(prices, func(i, j int) bool { return prices[i].MarketCap > prices[j].MarketCap })
+
+	now := time.Now().UTC()
+	list := List{Currency: currency, Assets: make([]MarketAsset, len(prices)), UpdatedAt: now}
+	for i, p := range prices {
+		list.Assets[i] = MarketAsset{
+			Rank:      i + 1,
+			ID:        p.ID,
+			Symbol:    p.Symbol,
+			Name:      p.Name,
+			Price:     p.CurrentPrice,
+			MarketCap: p.MarketCap,
+			Volume24h: p.TotalVolume,
+			Change24h: p.PriceChangePercentage24h,
+			Change7d:  p.PriceChangePercentage7d,
+			UpdatedAt: now,
+
+			CirculatingSupply: p.CirculatingSupply,
+			TotalSupply:       p.TotalSupply,
+		}
+	}
+
+	s.mu.Lock()
+	s.cache[currency] = list
+	s.mu.Unlock()
+	return truncate(list, limit), nil
+}
+
+// truncate returns a copy of l holding at most limit assets
+func truncate(l List, limit int) *List {
+	if len(l.Assets) > limit {
+		l.Assets = l.Assets[:limit]
+	}
+	return &l
+}
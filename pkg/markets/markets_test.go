@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package markets_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/markets"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// fetcher answers with prices in the order given and counts its calls
+type fetcher struct {
+	prices []providers.Price
+	err    error
+	calls  int
+}
+
+func (f *fetcher) fetch(ctx context.Context, currency string, limit int) ([]providers.Price, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return append([]providers.Price(nil), f.prices...), nil
+}
+
+func TestTopRanksByMarketCap(t *testing.T) {
+	f := &fetcher{prices: []providers.Price{
+		{ID: "solana", MarketCap: 7e10},
+		{ID: "bitcoin", MarketCap: 1.2e12},
+		{ID: "tether", MarketCap: 1.1e11},
+		{ID: "ethereum", MarketCap: 3.8e11},
+		{ID: "usd-coin", MarketCap: 1.1e11},
+	}}
+	s := markets.NewService(f.fetch, time.Minute)
+
+	list, err := s.Top(context.Background(), "USD", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Equal caps keep the upstream order
+	want := []string{"bitcoin", "ethereum", "tether", "usd-coin", "solana"}
+	if len(list.Assets) != len(want) {
+		t.Fatalf("got %d assets, want %d", len(list.Assets), len(want))
+	}
+	for i, a := range list.Assets {
+		if a.ID != want[i] || a.Rank != i+1 {
+			t.Errorf("asset %d = %s ranked %d, want %s ranked %d", i, a.ID, a.Rank, want[i], i+1)
+		}
+	}
+	if list.Currency != "usd" || list.Cached {
+		t.Errorf("currency %q cached %v, want usd fresh", list.Currency, list.Cached)
+	}
+}
+
+func TestTopCaches(t *testing.T) {
+	f := &fetcher{prices: []providers.Price{
+		{ID: "ethereum", MarketCap: 3.8e11},
+		{ID: "bitcoin", MarketCap: 1.2e12},
+	}}
+	s := markets.NewService(f.fetch, time.Minute)
+	ctx := context.Background()
+
+	if _, err := s.Top(ctx, "usd", 0); err != nil {
+		t.Fatal(err)
+	}
+	list, err := s.Top(ctx, "usd", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.calls != 1 {
+		t.Errorf("fetched %d times, want the list reused", f.calls)
+	}
+	if !list.Cached || len(list.Assets) != 1 || list.Assets[0].ID != "bitcoin" {
+		t.Errorf("got %+v, want the cached top asset bitcoin", list)
+	}
+
+	// Another currency is a list of its own
+	if _, err := s.Top(ctx, "eur", 0); err != nil {
+		t.Fatal(err)
+	}
+	if f.calls != 2 {
+		t.Errorf("fetched %d times, want eur fetched separately", f.calls)
+	}
+}
+
+func TestTopServesStaleOnError(t *testing.T) {
+	f := &fetcher{prices: []providers.Price{{ID: "bitcoin", MarketCap: 1.2e12}}}
+	// The shortest ttl, so the second call refetches
+	s := markets.NewService(f.fetch, time.Nanosecond)
+	ctx := context.Background()
+
+	if _, err := s.Top(ctx, "usd", 0); err != nil {
+		t.Fatal(err)
+	}
+	f.err = errors.New("upstream down")
+	time.Sleep(time.Millisecond)
+	list, err := s.Top(ctx, "usd", 0)
+	if err != nil {
+		t.Fatalf("got %v, want the stale list", err)
+	}
+	if !list.Cached || len(list.Assets) != 1 {
+		t.Errorf("got %+v, want the stale list", list)
+	}
+
+	if _, err := s.Top(ctx, "eur", 0); err == nil {
+		t.Error("got a list for eur with nothing cached and the upstream down")
+	}
+}
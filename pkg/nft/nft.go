@@ -0,0 +1,90 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package nft serves cached NFT collection floor prices.
+package nft
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// DefaultTTL is how long collection data is reused
+const DefaultTTL = 10 * time.Minute
+
+// Amount is a value in the collection's native currency and in USD
+type Amount = wire.NFTAmount
+
+// Collection is the floor price and activity of an NFT collection
+type Collection = wire.NFTCollection
+
+// Fetcher fetches a collection, e.g. CoinGecko.FetchNFT
+type Fetcher func(ctx context.Context, collectionID string) (*providers.NFTCollection, error)
+
+// Service caches collections fetched from an upstream
+type Service struct {
+	fetch Fetcher
+	ttl   time.Duration
+
+	mu    sync.Mutex
+	cache map[string]Collection
+}
+
+// NewService creates a service that refetches collections after ttl
+func NewService(fetch Fetcher, ttl time.Duration) *Service {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Service{fetch: fetch, ttl: ttl, cache: make(map[string]Collection)}
+}
+
+// Collection returns a collection by id, from cache if it is fresh. Stale
+// data is returned if the refetch fails.
+func (s *Service) Collection(ctx context.Context, id string) (*Collection, error) {
+	id = strings.ToLower(id)
+
+	s.mu.Lock()
+	cached, ok := s.cache[id]
+	s.mu.Unlock()
+	if ok && time.Since(cached.UpdatedAt) < s.ttl {
+		cached.Cached = true
+		return &cached, nil
+	}
+
+	raw, err := s.fetch(ctx, id)
+	if err != nil {
+		if ok {
+			cached.Cached = true
+			return &cached, nil
+		}
+		return nil, err
+	}
+
+	c := Collection{
+		ID:                  raw.ID,
+		Name:                raw.Name,
+		Symbol:              raw.Symbol,
+		Platform:            raw.AssetPlatformID,
+		ContractAddress:     raw.ContractAddress,
+		NativeCurrency:      strings.ToLower(raw.NativeCurrencySymbol),
+		FloorPrice:          Amount{Native: raw.FloorPrice["native_currency"], USD: raw.FloorPrice["usd"]},
+		FloorPriceChange24h: raw.FloorPriceChange24h,
+		Volume24h:           Amount{Native: raw.Volume24h["native_currency"], USD: raw.Volume24h["usd"]},
+		Owners:              raw.Owners,
+		TotalSupply:         raw.TotalSupply,
+		UpdatedAt:           time.Now().UTC(),
+	}
+	if c.ID == "" {
+		c.ID = id
+	}
+
+	s.mu.Lock()
+	s.cache[id] = c
+	s.mu.Unlock()
+	return &c, nil
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package onramp compares buy quotes from fiat on-ramp providers, so the
+// wallet can route a purchase to the one selling tokens cheapest.
+package onramp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// ErrUnsupported is returned when no provider sells a token
+var ErrUnsupported = errors.New("no on-ramp sells this token")
+
+// Provider quotes buying a token with fiat
+type Provider interface {
+	Name() string
+
+	// Supports reports whether the provider sells a token
+	Supports(tokenID string) bool
+
+	// Quote returns what buying tokenID for amount of fiat, fees
+	// included, delivers
+	Quote(ctx context.Context, tokenID, fiat string, amount decimal.Decimal) (*Quote, error)
+}
+
+// PriceFunc returns a token's market price in a currency, e.g. from the
+// price cache
+type PriceFunc func(ctx context.Context, tokenID, currency string) (decimal.Decimal, error)
+
+// Quote is one provider's offer. EffectivePrice is what each token costs
+// once fees are paid, so offers compare on it directly.
+type Quote = wire.OnrampQuote
+
+// Comparison is every provider's offer for a purchase, best first
+type Comparison = wire.OnrampComparison
+
+// QuoteError is a provider declining a quote, such as for an amount below
+// its minimum; its message is meant for the buyer
+type QuoteError struct {
+	Provider string
+	Message  string
+}
+
+func (e *QuoteError) Error() string {
+	return e.Provider + ": " + e.Message
+}
+
+// Aggregator asks every provider selling a token for a quote
+type Aggregator struct {
+	providers []Provider
+	price     PriceFunc
+	timeout   time.Duration
+}
+
+// NewAggregator creates an aggregator over providers, each given timeout
+// to answer. Offers are compared with market prices from price, if set.
+func NewAggregator(providers []Provider, price PriceFunc, timeout time.Duration) *Aggregator {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &Aggregator{providers: providers, price: price, timeout: timeout}
+}
+
+// Providers returns the provider names
+func (a *Aggregator) Providers() []string {
+	names := make([]string, len(a.providers))
+	for i, p := range a.providers {
+		names[i] = p.Name()
+	}
+	return names
+}
+
+// Compare asks the providers selling tokenID for quotes on buying it for
+// amount of fiat. Quotes are sorted by effective price, lowest first. A
+// provider that fails is listed in Errors; if all fail the error is
+// returned too.
+func (a *Aggregator) Compare(ctx context.Context, tokenID, fiat string, amount decimal.Decimal) (*Comparison, error) {
+	tokenID, fiat = strings.ToLower(tokenID), strings.ToLower(fiat)
+	var selling []Provider
+	for _, p := range a.providers {
+		if p.Supports(tokenID) {
+			selling = append(selling, p)
+		}
+	}
+	if len(selling) == 0 {
+		return nil, ErrUnsupported
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	var (
+		wg     sync.WaitGroup
+		market *decimal.Decimal
+		quotes = make([]*Quote, len(selling))
+		errs   = make([]error, len(selling))
+	)
+	if a.price != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if p, err := a.price(ctx, tokenID, fiat); err == nil && p.Sign() > 0 {
+				market = &p
+			}
+		}()
+	}
+	for i, p := range selling {
+		wg.Add(1)
+		go func(i int, p Provider) {
+			defer wg.Done()
+			quotes[i], errs[i] = p.Quote(ctx, tokenID, fiat, amount)
+			if errs[i] == nil && (quotes[i] == nil || quotes[i].CryptoAmount.Sign() <= 0) {
+				errs[i] = fmt.Errorf("%s: empty quote", p.Name())
+			}
+		}(i, p)
+	}
+	wg.Wait()
+
+	c := &Comparison{Token: tokenID, Fiat: fiat, Amount: amount, MarketPrice: market, Quotes: []Quote{}}
+	for i, q := range quotes {
+		if err := errs[i]; err != nil {
+			if c.Errors == nil {
+				c.Errors = make(map[string]string)
+			}
+			var qe *QuoteError
+			if errors.As(err, &qe) {
+				c.Errors[selling[i].Name()] = qe.Message
+			} else {
+				log.Printf("On-ramp %s quote for %s failed: %v", selling[i].Name(), tokenID, err)
+				c.Errors[selling[i].Name()] = "quote unavailable"
+			}
+			continue
+		}
+		q.Provider = selling[i].Name()
+		q.EffectivePrice = q.FiatAmount.Quo(q.CryptoAmount)
+		if market != nil {
+			markup := (q.EffectivePrice.Quo(*market).Float64() - 1) * 100
+			q.Markup = &markup
+		}
+		c.Quotes = append(c.Quotes, *q)
+	}
+	sort.SliceStable(c.Quotes, func(i, j int) bool {
+		return c.Quotes[i].EffectivePrice.Sub(c.Quotes[j].EffectivePrice).Sign() < 0
+	})
+	if len(c.Quotes) == 0 {
+		return c, errors.Join(errs...)
+	}
+	c.Best = c.Quotes[0].Provider
+	return c, nil
+}
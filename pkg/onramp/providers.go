@@ -0,0 +1,233 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package onramp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+)
+
+// Default API base URLs
+const (
+	MoonPayURL = "https://api.moonpay.com"
+	TransakURL = "https://api.transak.com"
+)
+
+// Options configures a provider
+type Options struct {
+	Name    string
+	BaseURL string // the provider's production API if empty
+	APIKey  string // publishable API key
+
+	// Currencies maps token ids to the provider's currency codes, e.g.
+	// "ethereum" to "eth" for MoonPay or "ETH" for Transak
+	Currencies map[string]string
+
+	// PaymentMethod narrows quotes to one way of paying, e.g.
+	// "credit_debit_card"; Network picks the chain Transak delivers on,
+	// e.g. "ethereum"
+	Network       string
+	PaymentMethod string
+
+	Client *http.Client // http.DefaultClient if nil
+}
+
+// base holds what every provider shares
+type base struct {
+	opts Options
+}
+
+func newBase(opts Options, defaultURL string) base {
+	if opts.BaseURL == "" {
+		opts.BaseURL = defaultURL
+	}
+	opts.BaseURL = strings.TrimSuffix(opts.BaseURL, "/")
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	currencies := make(map[string]string, len(opts.Currencies))
+	for id, code := range opts.Currencies {
+		currencies[strings.ToLower(id)] = code
+	}
+	opts.Currencies = currencies
+	return base{opts: opts}
+}
+
+// Name identifies the provider
+func (b base) Name() string {
+	return b.opts.Name
+}
+
+// Supports reports whether the provider has a currency code for tokenID
+func (b base) Supports(tokenID string) bool {
+	_, ok := b.opts.Currencies[tokenID]
+	return ok
+}
+
+// get fetches a quote URL and decodes its JSON into v. A 4xx answer is a
+// QuoteError carrying the provider's message.
+func (b base) get(ctx context.Context, u string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := b.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusUnauthorized &&
+			resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+			var doc struct {
+				Message string `json:"message"`
+				Error   struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			json.Unmarshal(body, &doc)
+			if msg := firstNonEmpty(doc.Message, doc.Error.Message); msg != "" {
+				return &QuoteError{Provider: b.opts.Name, Message: msg}
+			}
+		}
+		return fmt.Errorf("%s API error: %d - %s", b.opts.Name, resp.StatusCode, string(body))
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// MoonPay quotes purchases through MoonPay's buy quote API
+type MoonPay struct {
+	base
+}
+
+// NewMoonPay creates a MoonPay provider
+func NewMoonPay(opts Options) *MoonPay {
+	if opts.Name == "" {
+		opts.Name = "moonpay"
+	}
+	return &MoonPay{base: newBase(opts, MoonPayURL)}
+}
+
+// Quote asks MoonPay for a quote with fees included in amount
+func (m *MoonPay) Quote(ctx context.Context, tokenID, fiat string, amount decimal.Decimal) (*Quote, error) {
+	code := m.opts.Currencies[tokenID]
+	q := url.Values{
+		"apiKey":             {m.opts.APIKey},
+		"baseCurrencyCode":   {fiat},
+		"baseCurrencyAmount": {amount.String()},
+		"areFeesIncluded":    {"true"},
+	}
+	if m.opts.PaymentMethod != "" {
+		q.Set("paymentMethod", m.opts.PaymentMethod)
+	}
+	u := fmt.Sprintf("%s/v3/currencies/%s/buy_quote?%s", m.opts.BaseURL, url.PathEscape(code), q.Encode())
+
+	var doc struct {
+		TotalAmount         json.Number `json:"totalAmount"`
+		QuoteCurrencyAmount json.Number `json:"quoteCurrencyAmount"`
+		FeeAmount           json.Number `json:"feeAmount"`
+		ExtraFeeAmount      json.Number `json:"extraFeeAmount"`
+		NetworkFeeAmount    json.Number `json:"networkFeeAmount"`
+	}
+	if err := m.get(ctx, u, &doc); err != nil {
+		return nil, err
+	}
+	return newQuote(amount, doc.TotalAmount, doc.QuoteCurrencyAmount, doc.FeeAmount, doc.ExtraFeeAmount, doc.NetworkFeeAmount)
+}
+
+// Transak quotes purchases through Transak's public pricing API
+type Transak struct {
+	base
+}
+
+// NewTransak creates a Transak provider
+func NewTransak(opts Options) *Transak {
+	if opts.Name == "" {
+		opts.Name = "transak"
+	}
+	return &Transak{base: newBase(opts, TransakURL)}
+}
+
+// Quote asks Transak for the price of a purchase paying amount
+func (t *Transak) Quote(ctx context.Context, tokenID, fiat string, amount decimal.Decimal) (*Quote, error) {
+	q := url.Values{
+		"partnerApiKey":  {t.opts.APIKey},
+		"fiatCurrency":   {strings.ToUpper(fiat)},
+		"cryptoCurrency": {t.opts.Currencies[tokenID]},
+		"isBuyOrSell":    {"BUY"},
+		"fiatAmount":     {amount.String()},
+	}
+	if t.opts.Network != "" {
+		q.Set("network", t.opts.Network)
+	}
+	if t.opts.PaymentMethod != "" {
+		q.Set("paymentMethod", t.opts.PaymentMethod)
+	}
+	u := t.opts.BaseURL + "/api/v1/pricing/public/quotes?" + q.Encode()
+
+	var doc struct {
+		Response struct {
+			FiatAmount   json.Number `json:"fiatAmount"`
+			CryptoAmount json.Number `json:"cryptoAmount"`
+			TotalFee     json.Number `json:"totalFee"`
+		} `json:"response"`
+	}
+	if err := t.get(ctx, u, &doc); err != nil {
+		return nil, err
+	}
+	r := doc.Response
+	return newQuote(amount, r.FiatAmount, r.CryptoAmount, r.TotalFee)
+}
+
+// newQuote builds a quote from a provider's numbers: the fiat paid (amount
+// if missing), the tokens received and fee components, missing ones zero
+func newQuote(amount decimal.Decimal, paid, received json.Number, fees ...json.Number) (*Quote, error) {
+	q := &Quote{FiatAmount: amount}
+	if paid != "" {
+		v, err := decimal.Parse(paid.String())
+		if err != nil {
+			return nil, err
+		}
+		q.FiatAmount = v
+	}
+	v, err := decimal.Parse(received.String())
+	if err != nil {
+		return nil, fmt.Errorf("crypto amount: %w", err)
+	}
+	q.CryptoAmount = v
+	for _, f := range fees {
+		if f == "" {
+			continue
+		}
+		v, err := decimal.Parse(f.String())
+		if err != nil {
+			return nil, err
+		}
+		q.Fees = q.Fees.Add(v)
+	}
+	return q, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
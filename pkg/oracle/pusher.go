@@ -0,0 +1,473 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package oracle pushes prices to an oracle contract on an EVM chain such
+// as the Lux C-Chain, making this service the feeder for on-chain price
+// consumers.
+//
+// Transactions are sent with eth_sendTransaction, so they are signed by
+// the node or signer (Clef, Web3Signer) holding the feeder account's key;
+// the key never reaches this service. A feed is updated when its price
+// moves by the deviation threshold or when the heartbeat has passed since
+// the last update.
+package oracle
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+	"github.com/luxfi/pricing/pkg/evm"
+)
+
+// DefaultMethod is the contract function prices are pushed with. It takes
+// (bytes32 feedId, int256 answer, uint256 updatedAt).
+const DefaultMethod = "updatePrice"
+
+// Feed is a token's price in a currency, pushed to the contract
+type Feed struct {
+	Token    string
+	Currency string
+}
+
+// Name returns the feed's name, e.g. "LUX/USD"
+func (f Feed) Name() string {
+	return strings.ToUpper(f.Token + "/" + f.Currency)
+}
+
+// ID returns the feed id passed to the contract, keccak256 of the name
+func (f Feed) ID() [32]byte {
+	var id [32]byte
+	copy(id[:], evm.Keccak256([]byte(f.Name())))
+	return id
+}
+
+// PriceFunc returns a token's price in currency and when it was fetched
+type PriceFunc func(ctx context.Context, token, currency string) (decimal.Decimal, time.Time, error)
+
+// Options configures a Pusher
+type Options struct {
+	RPCURL   string
+	Contract string // oracle contract address
+	From     string // feeder account, unlocked in the node or signer
+	Method   string // DefaultMethod if empty
+	Feeds    []Feed
+
+	Decimals  int           // answers are prices × 10^Decimals
+	Deviation float64       // relative price move that triggers an update
+	Heartbeat time.Duration // longest time between updates of a feed
+
+	MaxGasPrice  *big.Int      // wei; updates wait while the network asks more. nil is no cap
+	GasLimit     uint64        // per update; 0 estimates each one
+	ReplaceAfter time.Duration // an unmined update is resent with a higher gas price after this
+
+	Timeout   time.Duration
+	Transport http.RoundTripper
+}
+
+// PendingTx is an update sent but not yet mined
+type PendingTx struct {
+	Hash         string    `json:"hash"`
+	Nonce        uint64    `json:"nonce"`
+	GasPrice     string    `json:"gas_price"` // wei
+	Price        string    `json:"price"`
+	SentAt       time.Time `json:"sent_at"`
+	Replacements int       `json:"replacements"`
+}
+
+// FeedStatus is the state of one feed
+type FeedStatus struct {
+	Feed        string     `json:"feed"`
+	ID          string     `json:"id"`
+	Price       string     `json:"price,omitempty"`        // latest price read
+	PushedPrice string     `json:"pushed_price,omitempty"` // price of the last mined update
+	PushedAt    *time.Time `json:"pushed_at,omitempty"`
+	Pending     *PendingTx `json:"pending,omitempty"`
+	Updates     int64      `json:"updates"` // mined since start
+	Reverted    int64      `json:"reverted"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// Status is a snapshot of the pusher
+type Status struct {
+	Contract  string       `json:"contract"`
+	From      string       `json:"from"`
+	Method    string       `json:"method"`
+	Decimals  int          `json:"decimals"`
+	Deviation float64      `json:"deviation"`
+	Heartbeat string       `json:"heartbeat"`
+	NextNonce *uint64      `json:"next_nonce,omitempty"`
+	GasPrice  string       `json:"gas_price,omitempty"` // wei, as last read from the network
+	Feeds     []FeedStatus `json:"feeds"`
+}
+
+// pending is a sent update and every hash sent for its nonce
+type pending struct {
+	hashes       []string
+	nonce        uint64
+	gasPrice     *big.Int
+	price        decimal.Decimal
+	sentAt       time.Time
+	replacements int
+}
+
+// feedState tracks one feed. Only Poll writes it, under Pusher.mu.
+type feedState struct {
+	feed     Feed
+	id       [32]byte
+	price    decimal.Decimal
+	priceAt  time.Time
+	hasPrice bool
+	pushed   decimal.Decimal
+	pushedAt time.Time
+	pending  *pending
+	updates  int64
+	reverted int64
+	lastErr  string
+}
+
+// Pusher keeps feeds on an oracle contract up to date
+type Pusher struct {
+	opts     Options
+	price    PriceFunc
+	rpc      *evm.Client
+	selector []byte
+
+	mu       sync.RWMutex
+	feeds    []*feedState
+	nonce    *uint64 // next nonce to use; nil until read from the node
+	gasPrice *big.Int
+}
+
+// NewPusher creates a pusher reading prices with price
+func NewPusher(opts Options, price PriceFunc) *Pusher {
+	if opts.Method == "" {
+		opts.Method = DefaultMethod
+	}
+	p := &Pusher{
+		opts:     opts,
+		price:    price,
+		rpc:      evm.NewClient(opts.RPCURL, opts.Timeout, opts.Transport),
+		selector: evm.Keccak256([]byte(opts.Method + "(bytes32,int256,uint256)"))[:4],
+	}
+	for _, f := range opts.Feeds {
+		f.Token, f.Currency = strings.ToLower(f.Token), strings.ToLower(f.Currency)
+		p.feeds = append(p.feeds, &feedState{feed: f, id: f.ID()})
+	}
+	return p
+}
+
+// Run polls every interval until ctx is done
+func (p *Pusher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		p.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll reads prices and settles sent updates, replacing those stuck
+// unmined, then sends an update for every feed that has moved past the
+// deviation threshold or whose heartbeat is due. A feed has at most one
+// update in flight.
+func (p *Pusher) Poll(ctx context.Context) {
+	var priced []*feedState
+	for _, f := range p.feeds {
+		price, at, err := p.price(ctx, f.feed.Token, f.feed.Currency)
+		if err == nil && price.Sign() <= 0 {
+			err = fmt.Errorf("price %s is not positive", price)
+		}
+		if err != nil {
+			p.fail(f, err)
+			continue
+		}
+		p.mu.Lock()
+		f.price, f.priceAt, f.hasPrice = price, at, true
+		p.mu.Unlock()
+		priced = append(priced, f)
+	}
+
+	for _, f := range p.feeds {
+		if f.pending != nil && f.hasPrice {
+			p.settle(ctx, f)
+		}
+	}
+
+	now := time.Now()
+	var due []*feedState
+	for _, f := range priced {
+		if f.pending == nil && p.due(f, now) {
+			due = append(due, f)
+		}
+	}
+	if len(due) == 0 {
+		return
+	}
+
+	gasPrice, err := p.networkGasPrice(ctx)
+	if err == nil && p.overCap(gasPrice) {
+		err = fmt.Errorf("gas price %s wei is above the cap of %s; update deferred", gasPrice, p.opts.MaxGasPrice)
+	}
+	if err != nil {
+		for _, f := range due {
+			p.fail(f, err)
+		}
+		return
+	}
+	for _, f := range due {
+		if err := p.send(ctx, f, gasPrice); err != nil {
+			p.fail(f, err)
+		}
+	}
+}
+
+// due reports whether a feed needs an update
+func (p *Pusher) due(f *feedState, now time.Time) bool {
+	if f.pushedAt.IsZero() || now.Sub(f.pushedAt) >= p.opts.Heartbeat {
+		return true
+	}
+	move := f.price.Sub(f.pushed).Quo(f.pushed).Float64()
+	return move >= p.opts.Deviation || -move >= p.opts.Deviation
+}
+
+// settle checks whether a feed's update was mined, and replaces it with a
+// higher gas price if it has waited longer than ReplaceAfter
+func (p *Pusher) settle(ctx context.Context, f *feedState) {
+	tx := f.pending
+	for _, hash := range tx.hashes {
+		var receipt *struct {
+			Status string `json:"status"`
+		}
+		if err := p.rpc.Call(ctx, &receipt, "eth_getTransactionReceipt", hash); err != nil {
+			p.fail(f, err)
+			return
+		}
+		if receipt == nil {
+			continue
+		}
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		f.pending = nil
+		if receipt.Status == "0x1" {
+			f.pushed, f.pushedAt = tx.price, time.Now()
+			f.updates++
+			f.lastErr = ""
+			log.Printf("Oracle: %s updated to %s in %s", f.feed.Name(), tx.price, hash)
+		} else {
+			f.reverted++
+			f.lastErr = "update " + hash + " reverted"
+			log.Printf("Oracle: %s update %s reverted", f.feed.Name(), hash)
+		}
+		return
+	}
+
+	if p.opts.ReplaceAfter <= 0 || time.Since(tx.sentAt) < p.opts.ReplaceAfter {
+		return
+	}
+
+	// Nodes only accept a replacement paying at least 10% more
+	gasPrice, err := p.networkGasPrice(ctx)
+	if err != nil {
+		p.fail(f, err)
+		return
+	}
+	bumped := new(big.Int).Mul(tx.gasPrice, big.NewInt(9))
+	bumped.Div(bumped, big.NewInt(8)).Add(bumped, big.NewInt(1))
+	if gasPrice.Cmp(bumped) < 0 {
+		gasPrice = bumped
+	}
+	if p.overCap(gasPrice) {
+		p.fail(f, fmt.Errorf("replacing stuck update %s needs gas price %s wei, above the cap of %s", tx.hashes[len(tx.hashes)-1], gasPrice, p.opts.MaxGasPrice))
+		return
+	}
+
+	// A replacement carries the latest price. If the original was mined in
+	// the meantime the node refuses it and the next poll settles.
+	hash, err := p.submit(ctx, f, tx.nonce, gasPrice)
+	if err != nil {
+		p.fail(f, fmt.Errorf("replacing stuck update: %w", err))
+		return
+	}
+	p.mu.Lock()
+	tx.hashes = append(tx.hashes, hash)
+	tx.gasPrice, tx.price, tx.sentAt = gasPrice, f.price, time.Now()
+	tx.replacements++
+	p.mu.Unlock()
+	log.Printf("Oracle: %s update replaced by %s at %s wei", f.feed.Name(), hash, gasPrice)
+}
+
+// send sends an update for a feed with the next nonce
+func (p *Pusher) send(ctx context.Context, f *feedState, gasPrice *big.Int) error {
+	if p.nonce == nil {
+		n, err := p.rpc.Quantity(ctx, "eth_getTransactionCount", p.opts.From, "pending")
+		if err != nil {
+			return err
+		}
+		next := n.Uint64()
+		p.mu.Lock()
+		p.nonce = &next
+		p.mu.Unlock()
+	}
+	nonce := *p.nonce
+
+	hash, err := p.submit(ctx, f, nonce, gasPrice)
+	if err != nil {
+		// The account was used elsewhere or a send was lost; read the
+		// nonce again next time
+		if strings.Contains(strings.ToLower(err.Error()), "nonce") {
+			p.mu.Lock()
+			p.nonce = nil
+			p.mu.Unlock()
+		}
+		return err
+	}
+
+	p.mu.Lock()
+	next := nonce + 1
+	p.nonce = &next
+	f.pending = &pending{
+		hashes:   []string{hash},
+		nonce:    nonce,
+		gasPrice: gasPrice,
+		price:    f.price,
+		sentAt:   time.Now(),
+	}
+	p.mu.Unlock()
+	log.Printf("Oracle: %s update to %s sent in %s (nonce %d)", f.feed.Name(), f.price, hash, nonce)
+	return nil
+}
+
+// submit sends a transaction updating a feed to its latest price and
+// returns its hash
+func (p *Pusher) submit(ctx context.Context, f *feedState, nonce uint64, gasPrice *big.Int) (string, error) {
+	tx := map[string]string{
+		"from":     p.opts.From,
+		"to":       p.opts.Contract,
+		"data":     "0x" + hex.EncodeToString(p.calldata(f)),
+		"gasPrice": evm.HexQuantity(gasPrice),
+		"nonce":    evm.HexQuantity(new(big.Int).SetUint64(nonce)),
+	}
+
+	gas := new(big.Int).SetUint64(p.opts.GasLimit)
+	if p.opts.GasLimit == 0 {
+		estimate, err := p.rpc.Quantity(ctx, "eth_estimateGas", tx)
+		if err != nil {
+			return "", err
+		}
+		// Headroom for state changing between estimate and inclusion
+		gas = estimate.Add(estimate, new(big.Int).Div(estimate, big.NewInt(5)))
+	}
+	tx["gas"] = evm.HexQuantity(gas)
+
+	var hash string
+	if err := p.rpc.Call(ctx, &hash, "eth_sendTransaction", tx); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// calldata encodes method(feedId, answer, updatedAt) for a feed's latest
+// price
+func (p *Pusher) calldata(f *feedState) []byte {
+	scale, _ := decimal.Parse(fmt.Sprintf("1e%d", p.opts.Decimals))
+	answer := f.price.Mul(scale).Int()
+
+	data := make([]byte, 4+3*32)
+	copy(data, p.selector)
+	copy(data[4:36], f.id[:])
+	answer.FillBytes(data[36:68])
+	big.NewInt(f.priceAt.Unix()).FillBytes(data[68:100])
+	return data
+}
+
+// networkGasPrice reads the gas price the network currently asks
+func (p *Pusher) networkGasPrice(ctx context.Context) (*big.Int, error) {
+	gasPrice, err := p.rpc.Quantity(ctx, "eth_gasPrice")
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	p.gasPrice = gasPrice
+	p.mu.Unlock()
+	return gasPrice, nil
+}
+
+func (p *Pusher) overCap(gasPrice *big.Int) bool {
+	return p.opts.MaxGasPrice != nil && gasPrice.Cmp(p.opts.MaxGasPrice) > 0
+}
+
+// fail records a feed's latest error
+func (p *Pusher) fail(f *feedState, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	log.Printf("Oracle: %s: %v", f.feed.Name(), err)
+	p.mu.Lock()
+	f.lastErr = err.Error()
+	p.mu.Unlock()
+}
+
+// Status returns the state of every feed, in configured order
+func (p *Pusher) Status() Status {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	st := Status{
+		Contract:  p.opts.Contract,
+		From:      p.opts.From,
+		Method:    p.opts.Method + "(bytes32,int256,uint256)",
+		Decimals:  p.opts.Decimals,
+		Deviation: p.opts.Deviation,
+		Heartbeat: p.opts.Heartbeat.String(),
+		Feeds:     make([]FeedStatus, 0, len(p.feeds)),
+	}
+	if p.nonce != nil {
+		n := *p.nonce
+		st.NextNonce = &n
+	}
+	if p.gasPrice != nil {
+		st.GasPrice = p.gasPrice.String()
+	}
+	for _, f := range p.feeds {
+		fs := FeedStatus{
+			Feed:      f.feed.Name(),
+			ID:        "0x" + hex.EncodeToString(f.id[:]),
+			Updates:   f.updates,
+			Reverted:  f.reverted,
+			LastError: f.lastErr,
+		}
+		if f.hasPrice {
+			fs.Price = f.price.String()
+		}
+		if !f.pushedAt.IsZero() {
+			at := f.pushedAt.UTC()
+			fs.PushedPrice, fs.PushedAt = f.pushed.String(), &at
+		}
+		if tx := f.pending; tx != nil {
+			fs.Pending = &PendingTx{
+				Hash:         tx.hashes[len(tx.hashes)-1],
+				Nonce:        tx.nonce,
+				GasPrice:     tx.gasPrice.String(),
+				Price:        tx.price.String(),
+				SentAt:       tx.sentAt.UTC(),
+				Replacements: tx.replacements,
+			}
+		}
+		st.Feeds = append(st.Feeds, fs)
+	}
+	return st
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package oracle_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+	"github.com/luxfi/pricing/pkg/oracle"
+)
+
+// sentTx is a transaction the node accepted
+type sentTx struct {
+	hash     string
+	nonce    uint64
+	gasPrice uint64
+}
+
+// node is a JSON-RPC endpoint answering the calls a Pusher makes
+type node struct {
+	mu       sync.Mutex
+	count    uint64            // eth_getTransactionCount result
+	gasPrice uint64            // eth_gasPrice result
+	sendErrs []string          // messages failing the next sends, in order
+	mined    map[string]string // receipt status by hash
+	sent     []sentTx
+	reads    int // eth_getTransactionCount calls
+}
+
+func newNode(t *testing.T, count, gasPrice uint64) (*node, string) {
+	n := &node{count: count, gasPrice: gasPrice, mined: make(map[string]string)}
+	srv := httptest.NewServer(http.HandlerFunc(n.serve))
+	t.Cleanup(srv.Close)
+	return n, srv.URL
+}
+
+func (n *node) serve(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     int64             `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var result interface{}
+	var rpcErr string
+	switch req.Method {
+	case "eth_getTransactionCount":
+		n.reads++
+		result = fmt.Sprintf("0x%x", n.count)
+	case "eth_gasPrice":
+		result = fmt.Sprintf("0x%x", n.gasPrice)
+	case "eth_estimateGas":
+		result = "0x5208"
+	case "eth_sendTransaction":
+		if len(n.sendErrs) > 0 {
+			rpcErr, n.sendErrs = n.sendErrs[0], n.sendErrs[1:]
+			break
+		}
+		var tx struct {
+			Nonce    string `json:"nonce"`
+			GasPrice string `json:"gasPrice"`
+		}
+		json.Unmarshal(req.Params[0], &tx)
+		st := sentTx{hash: fmt.Sprintf("0x%064x", len(n.sent)+1), nonce: quantity(tx.Nonce), gasPrice: quantity(tx.GasPrice)}
+		n.sent = append(n.sent, st)
+		result = st.hash
+	case "eth_getTransactionReceipt":
+		var hash string
+		json.Unmarshal(req.Params[0], &hash)
+		if status, ok := n.mined[hash]; ok {
+			result = map[string]string{"status": status}
+		}
+	default:
+		rpcErr = "method not found"
+	}
+
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result}
+	if rpcErr != "" {
+		resp = map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": map[string]interface{}{"code": -32000, "message": rpcErr}}
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func quantity(s string) uint64 {
+	n, _ := new(big.Int).SetString(s[2:], 16)
+	return n.Uint64()
+}
+
+// mine marks every transaction sent so far as mined
+func (n *node) mine() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, tx := range n.sent {
+		n.mined[tx.hash] = "0x1"
+	}
+}
+
+// nonces returns the nonce of each transaction sent, in order
+func (n *node) nonces() []uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	nonces := make([]uint64, len(n.sent))
+	for i, tx := range n.sent {
+		nonces[i] = tx.nonce
+	}
+	return nonces
+}
+
+// newPusher pushes its feeds, LUX/USD if opts has none, at 1.5 on every
+// poll, the heartbeat being 0
+func newPusher(url string, opts oracle.Options) *oracle.Pusher {
+	opts.RPCURL = url
+	opts.Contract = "0x00000000000000000000000000000000000000aa"
+	opts.From = "0x00000000000000000000000000000000000000bb"
+	if opts.Feeds == nil {
+		opts.Feeds = []oracle.Feed{{Token: "lux", Currency: "usd"}}
+	}
+	opts.Decimals = 8
+	return oracle.NewPusher(opts, func(ctx context.Context, token, currency string) (decimal.Decimal, time.Time, error) {
+		d, err := decimal.Parse("1.5")
+		return d, time.Now(), err
+	})
+}
+
+func nextNonce(p *oracle.Pusher) string {
+	if n := p.Status().NextNonce; n != nil {
+		return fmt.Sprint(*n)
+	}
+	return "unread"
+}
+
+func TestNonceReusedAfterFailedSend(t *testing.T) {
+	n, url := newNode(t, 7, 100)
+	n.sendErrs = []string{"insufficient funds for gas * price + value"}
+	p := newPusher(url, oracle.Options{})
+	ctx := context.Background()
+
+	p.Poll(ctx)
+	if got := nextNonce(p); got != "7" {
+		t.Errorf("after a failed send the next nonce is %s, want 7 kept", got)
+	}
+	if st := p.Status().Feeds[0]; st.Pending != nil || st.LastError == "" {
+		t.Errorf("feed %+v, want the error recorded and nothing pending", st)
+	}
+
+	p.Poll(ctx)
+	if got := n.nonces(); len(got) != 1 || got[0] != 7 {
+		t.Errorf("sent nonces %v, want [7]", got)
+	}
+	if got := nextNonce(p); got != "8" {
+		t.Errorf("after a send the next nonce is %s, want 8", got)
+	}
+	if n.reads != 1 {
+		t.Errorf("read the nonce %d times, want once", n.reads)
+	}
+}
+
+func TestNonceRereadAfterNonceError(t *testing.T) {
+	n, url := newNode(t, 7, 100)
+	p := newPusher(url, oracle.Options{})
+	ctx := context.Background()
+
+	p.Poll(ctx)
+	n.mine()
+	// The account sends from elsewhere, so nonce 8 is taken
+	n.mu.Lock()
+	n.count = 9
+	n.sendErrs = []string{"nonce too low"}
+	n.mu.Unlock()
+
+	p.Poll(ctx)
+	if got := nextNonce(p); got != "unread" {
+		t.Errorf("after a nonce error the next nonce is %s, want it read again", got)
+	}
+	p.Poll(ctx)
+
+	if got := n.nonces(); len(got) != 2 || got[0] != 7 || got[1] != 9 {
+		t.Errorf("sent nonces %v, want [7 9]", got)
+	}
+	if n.reads != 2 {
+		t.Errorf("read the nonce %d times, want twice", n.reads)
+	}
+	if st := p.Status().Feeds[0]; st.Updates != 1 || st.Pending == nil || st.Pending.Nonce != 9 {
+		t.Errorf("feed %+v, want one update mined and nonce 9 pending", st)
+	}
+}
+
+func TestNonceBumpsPerFeed(t *testing.T) {
+	n, url := newNode(t, 3, 100)
+	p := newPusher(url, oracle.Options{
+		Feeds: []oracle.Feed{{Token: "lux", Currency: "usd"}, {Token: "btc", Currency: "usd"}},
+	})
+
+	p.Poll(context.Background())
+	if got := n.nonces(); len(got) != 2 || got[0] != 3 || got[1] != 4 {
+		t.Errorf("sent nonces %v, want [3 4]", got)
+	}
+	if got := nextNonce(p); got != "5" {
+		t.Errorf("next nonce %s, want 5", got)
+	}
+}
+
+func TestReplacementKeepsNonce(t *testing.T) {
+	n, url := newNode(t, 7, 100)
+	p := newPusher(url, oracle.Options{ReplaceAfter: time.Nanosecond})
+	ctx := context.Background()
+
+	p.Poll(ctx)
+	time.Sleep(time.Millisecond)
+	// Unmined past ReplaceAfter: resent with the same nonce, paying the
+	// 12.5% and 1 wei nodes require even though the network asks no more
+	p.Poll(ctx)
+
+	n.mu.Lock()
+	sent := append([]sentTx(nil), n.sent...)
+	n.mu.Unlock()
+	if len(sent) != 2 || sent[1].nonce != 7 || sent[1].gasPrice != 113 {
+		t.Fatalf("sent %+v, want nonce 7 resent at 113 wei", sent)
+	}
+	st := p.Status()
+	if got := nextNonce(p); got != "8" {
+		t.Errorf("next nonce %s, want 8", got)
+	}
+	if tx := st.Feeds[0].Pending; tx == nil || tx.Replacements != 1 || tx.Hash != sent[1].hash {
+		t.Errorf("pending %+v, want the replacement", tx)
+	}
+}
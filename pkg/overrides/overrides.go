@@ -0,0 +1,210 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package overrides patches token metadata from providers with our own,
+// so wrong upstream names, symbols or logos of tokens we know better can
+// be corrected at once
+package overrides
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// MaxDecimals bounds the decimals an override may set
+	MaxDecimals = 36
+
+	// maxText bounds an overridden name or symbol
+	maxText = 100
+
+	// maxTags bounds the tags of an override
+	maxTags = 20
+)
+
+// Override holds the fields patched over a token's provider data. Fields
+// left empty keep the provider's value.
+type Override struct {
+	Name     string   `json:"name,omitempty"`
+	Symbol   string   `json:"symbol,omitempty"`
+	Logo     string   `json:"logo,omitempty"`     // https URL of the logo image
+	Decimals *int     `json:"decimals,omitempty"` // on-chain decimals
+	Tags     []string `json:"tags,omitempty"`     // free-form labels served with prices
+}
+
+// Check validates an override and normalizes its tags
+func (o *Override) Check() error {
+	o.Name, o.Symbol, o.Logo = strings.TrimSpace(o.Name), strings.TrimSpace(o.Symbol), strings.TrimSpace(o.Logo)
+	if len(o.Name) > maxText || len(o.Symbol) > maxText {
+		return fmt.Errorf("name and symbol must be at most %d characters", maxText)
+	}
+	if o.Logo != "" {
+		u, err := url.Parse(o.Logo)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return errors.New("logo must be an https URL")
+		}
+	}
+	if o.Decimals != nil && (*o.Decimals < 0 || *o.Decimals > MaxDecimals) {
+		return fmt.Errorf("decimals must be between 0 and %d", MaxDecimals)
+	}
+	if len(o.Tags) > maxTags {
+		return fmt.Errorf("at most %d tags", maxTags)
+	}
+	var tags []string
+	for _, tag := range o.Tags {
+		tag = normalize(tag)
+		if tag == "" {
+			return errors.New("tags must not be empty")
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	o.Tags = tags
+	if o.Name == "" && o.Symbol == "" && o.Logo == "" && o.Decimals == nil && o.Tags == nil {
+		return errors.New("override sets no field")
+	}
+	return nil
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// Table holds the overrides by token id. Changes are saved to a file if
+// one is set.
+type Table struct {
+	mu        sync.RWMutex
+	file      string
+	overrides map[string]Override
+}
+
+// NewTable creates a table of the overrides in file, if it exists, or an
+// empty one
+func NewTable(file string) (*Table, error) {
+	t := &Table{file: file, overrides: make(map[string]Override)}
+	if file == "" {
+		return t, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var overrides map[string]Override
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("overrides file %s: %w", file, err)
+	}
+	for id, o := range overrides {
+		if err := o.Check(); err != nil {
+			return nil, fmt.Errorf("overrides file %s: %s: %w", file, id, err)
+		}
+		t.overrides[normalize(id)] = o
+	}
+	return t, nil
+}
+
+// Get returns a token's override, if it has one
+func (t *Table) Get(tokenID string) (Override, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	o, ok := t.overrides[normalize(tokenID)]
+	return o, ok
+}
+
+// Len returns the number of tokens overridden
+func (t *Table) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.overrides)
+}
+
+// All returns a copy of every override by token id
+func (t *Table) All() map[string]Override {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	all := make(map[string]Override, len(t.overrides))
+	for id, o := range t.overrides {
+		all[id] = o
+	}
+	return all
+}
+
+// Set replaces a token's override
+func (t *Table) Set(tokenID string, o Override) error {
+	tokenID = normalize(tokenID)
+	if tokenID == "" {
+		return errors.New("token id required")
+	}
+	if err := o.Check(); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	prev, had := t.overrides[tokenID]
+	t.overrides[tokenID] = o
+	if err := t.save(); err != nil {
+		if had {
+			t.overrides[tokenID] = prev
+		} else {
+			delete(t.overrides, tokenID)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete removes a token's override, reporting whether it had one
+func (t *Table) Delete(tokenID string) (bool, error) {
+	tokenID = normalize(tokenID)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	prev, ok := t.overrides[tokenID]
+	if !ok {
+		return false, nil
+	}
+	delete(t.overrides, tokenID)
+	if err := t.save(); err != nil {
+		t.overrides[tokenID] = prev
+		return false, err
+	}
+	return true, nil
+}
+
+// save writes the overrides to the file, replacing it atomically. The
+// caller holds t.mu.
+func (t *Table) save() error {
+	if t.file == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(t.overrides, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(t.file), filepath.Base(t.file)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), t.file)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package overrides
+
+import (
+	"context"
+
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// Provider wraps a price provider, patching the names, symbols and logos
+// of the prices it returns with the overrides in a table
+type Provider struct {
+	inner providers.Provider
+	table *Table
+}
+
+// NewProvider patches the prices inner returns with table
+func NewProvider(inner providers.Provider, table *Table) *Provider {
+	return &Provider{inner: inner, table: table}
+}
+
+// Name identifies the wrapped provider
+func (p *Provider) Name() string {
+	return p.inner.Name()
+}
+
+// FetchPrice fetches a price and patches it
+func (p *Provider) FetchPrice(ctx context.Context, tokenID, currency string) (*providers.Price, error) {
+	price, err := p.inner.FetchPrice(ctx, tokenID, currency)
+	if err != nil {
+		return nil, err
+	}
+	p.patch(price)
+	return price, nil
+}
+
+// FetchPrices fetches prices and patches them
+func (p *Provider) FetchPrices(ctx context.Context, tokenIDs []string, currency string) ([]providers.Price, error) {
+	prices, err := p.inner.FetchPrices(ctx, tokenIDs, currency)
+	if p.table.Len() > 0 {
+		for i := range prices {
+			p.patch(&prices[i])
+		}
+	}
+	return prices, err
+}
+
+// patch applies a price's token override, if any
+func (p *Provider) patch(price *providers.Price) {
+	o, ok := p.table.Get(price.ID)
+	if !ok {
+		return
+	}
+	if o.Name != "" {
+		price.Name = o.Name
+	}
+	if o.Symbol != "" {
+		price.Symbol = o.Symbol
+	}
+	if o.Logo != "" {
+		price.Image = o.Logo
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// MaxAllocationHoldings is the most holdings one allocation request may
+// list; a token may be listed once per chain and staking state
+const MaxAllocationHoldings = 100
+
+// Names of allocation shares that aren't categories or chains
+const (
+	Uncategorized = "uncategorized" // tokens in no category
+	Unspecified   = "unspecified"   // holdings without a chain
+	Stablecoin    = "stablecoin"
+	Volatile      = "volatile"
+	Staked        = "staked"
+	Liquid        = "liquid"
+)
+
+// AllocationHolding is an amount of a token held on a chain, staked or not
+type AllocationHolding = wire.AllocationHolding
+
+// AllocationRequest is a portfolio to break down
+type AllocationRequest = wire.AllocationRequest
+
+// ValidateAllocationRequest normalizes r and reports the first invalid
+// field
+func ValidateAllocationRequest(r *AllocationRequest) error {
+	r.Currency = strings.ToLower(strings.TrimSpace(r.Currency))
+	if r.Currency == "" {
+		r.Currency = "usd"
+	}
+	switch {
+	case len(r.Holdings) == 0:
+		return errors.New("holdings required")
+	case len(r.Holdings) > MaxAllocationHoldings:
+		return fmt.Errorf("at most %d holdings", MaxAllocationHoldings)
+	}
+	for i := range r.Holdings {
+		h := &r.Holdings[i]
+		h.Token = strings.ToLower(strings.TrimSpace(h.Token))
+		h.Chain = strings.ToLower(strings.TrimSpace(h.Chain))
+		switch {
+		case h.Token == "":
+			return fmt.Errorf("holdings[%d]: token required", i)
+		case h.Amount < 0:
+			return fmt.Errorf("holdings[%d]: amount must not be negative", i)
+		}
+	}
+	return nil
+}
+
+// Share is the part of a portfolio's value in one group
+type Share = wire.AllocationShare
+
+// Allocation breaks a portfolio's current value down by token, category,
+// chain, stability and staking. Shares are sorted by value, largest
+// first.
+type Allocation = wire.Allocation
+
+// Allocation values req's holdings at current prices and breaks them
+// down. req must have been validated.
+func (s *Service) Allocation(ctx context.Context, req AllocationRequest) (*Allocation, error) {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, h := range req.Holdings {
+		if !seen[h.Token] {
+			seen[h.Token] = true
+			ids = append(ids, h.Token)
+		}
+	}
+	prices, err := s.prices(ctx, ids, req.Currency)
+	if err != nil {
+		log.Printf("Portfolio: current prices: %v", err)
+	}
+	if prices == nil {
+		prices = make(map[string]decimal.Decimal)
+	}
+	// Tokens without a current price are valued at their latest history
+	// point
+	for _, id := range ids {
+		if _, ok := prices[id]; ok {
+			continue
+		}
+		hist, err := s.history(ctx, id, req.Currency, 1)
+		if errors.Is(err, providers.ErrTokenNotFound) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s price: %w", id, err)
+		}
+		prices[id] = decimal.FromFloat(priceAt(hist.Points, time.Now()))
+	}
+
+	type group map[string]decimal.Decimal
+	tokens, categories, chains := group{}, group{}, group{}
+	stability := group{Stablecoin: {}, Volatile: {}}
+	staking := group{Staked: {}, Liquid: {}}
+	var total decimal.Decimal
+	for _, h := range req.Holdings {
+		value := decimal.FromFloat(h.Amount).Mul(prices[h.Token])
+		total = total.Add(value)
+		tokens[h.Token] = tokens[h.Token].Add(value)
+
+		var cats []string
+		if s.Categories != nil {
+			cats = s.Categories(h.Token)
+		}
+		if len(cats) == 0 {
+			cats = []string{Uncategorized}
+		}
+		for _, c := range cats {
+			categories[c] = categories[c].Add(value)
+		}
+
+		chain := h.Chain
+		if chain == "" {
+			chain = Unspecified
+		}
+		chains[chain] = chains[chain].Add(value)
+
+		kind := Volatile
+		if s.Stablecoin != nil && s.Stablecoin(h.Token) {
+			kind = Stablecoin
+		}
+		stability[kind] = stability[kind].Add(value)
+
+		state := Liquid
+		if h.Staked {
+			state = Staked
+		}
+		staking[state] = staking[state].Add(value)
+	}
+
+	shares := func(g group) []Share {
+		out := make([]Share, 0, len(g))
+		for name, value := range g {
+			sh := Share{Name: name, Value: value.Float64()}
+			if total.Sign() > 0 {
+				sh.Percent = value.Quo(total).Float64() * 100
+			}
+			out = append(out, sh)
+		}
+		sort.Slice(out, func(i, j int) bool {
+			if out[i].Value != out[j].Value {
+				return out[i].Value > out[j].Value
+			}
+			return out[i].Name < out[j].Name
+		})
+		return out
+	}
+	return &Allocation{
+		Currency:   req.Currency,
+		Value:      total.Float64(),
+		ValueStr:   total.String(),
+		Tokens:     shares(tokens),
+		Categories: shares(categories),
+		Chains:     shares(chains),
+		Stability:  shares(stability),
+		Staking:    shares(staking),
+		UpdatedAt:  time.Now().UTC(),
+	}, nil
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// MaxLots is the most tax lots one request may hold
+const MaxLots = 1000
+
+// Holding terms of a lot
+const (
+	ShortTerm = "short" // held a year or less
+	LongTerm  = "long"  // held more than a year
+)
+
+// Lot is an acquisition of a token
+type Lot = wire.Lot
+
+// LotsRequest is a list of acquisitions to enrich
+type LotsRequest = wire.TaxLotsRequest
+
+// ValidateLotsRequest normalizes r and reports the first invalid field
+func ValidateLotsRequest(r *LotsRequest) error {
+	r.Currency = strings.ToLower(strings.TrimSpace(r.Currency))
+	if r.Currency == "" {
+		r.Currency = "usd"
+	}
+	switch {
+	case len(r.Lots) == 0:
+		return errors.New("lots required")
+	case len(r.Lots) > MaxLots:
+		return fmt.Errorf("at most %d lots", MaxLots)
+	}
+
+	tokens := make(map[string]bool)
+	now := time.Now()
+	for i := range r.Lots {
+		l := &r.Lots[i]
+		l.Token = strings.ToLower(strings.TrimSpace(l.Token))
+		switch {
+		case l.Token == "":
+			return fmt.Errorf("lots[%d]: token required", i)
+		case l.Amount <= 0:
+			return fmt.Errorf("lots[%d]: amount must be positive", i)
+		case l.Time.IsZero() || l.Time.After(now):
+			return fmt.Errorf("lots[%d]: time must be in the past", i)
+		}
+		tokens[l.Token] = true
+	}
+	if len(tokens) > MaxHoldings {
+		return fmt.Errorf("at most %d tokens", MaxHoldings)
+	}
+	return nil
+}
+
+// TaxLot is a lot valued at acquisition and now. The acquisition price,
+// cost basis and gain are null for lots older than the price history.
+type TaxLot = wire.TaxLot
+
+// Lots are enriched tax lots and their totals
+type Lots = wire.TaxLots
+
+// Lots prices each acquisition from history at its time and values it at
+// current prices. History is hourly for lots up to 90 days old and daily
+// up to a year. req must have been validated.
+func (s *Service) Lots(ctx context.Context, req LotsRequest) (*Lots, error) {
+	// Each token's history reaches back to its oldest lot
+	now := time.Now().UTC()
+	days := make(map[string]int)
+	for _, l := range req.Lots {
+		d := int(now.Sub(l.Time)/(24*time.Hour)) + 1
+		days[l.Token] = min(max(days[l.Token], d), history.MaxDays)
+	}
+
+	ids := make([]string, 0, len(days))
+	for id := range days {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	series := make(map[string][]history.Point, len(ids))
+	errs := make([]error, len(ids))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			hist, err := s.history(ctx, id, req.Currency, days[id])
+			if errors.Is(err, providers.ErrTokenNotFound) {
+				errs[i] = err
+				return
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("%s history: %w", id, err)
+				return
+			}
+			mu.Lock()
+			series[id] = hist.Points
+			mu.Unlock()
+		}(i, id)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	current, err := s.prices(ctx, ids, req.Currency)
+	if err != nil {
+		log.Printf("Tax lots: current prices: %v", err)
+	}
+
+	out := &Lots{Currency: req.Currency, Lots: make([]TaxLot, len(req.Lots)), UpdatedAt: now}
+	var total, totalCost, totalGain decimal.Decimal
+	for i, l := range req.Lots {
+		points := series[l.Token]
+		price, ok := current[l.Token]
+		if !ok {
+			price = decimal.FromFloat(priceAt(points, now))
+		}
+		amount := decimal.FromFloat(l.Amount)
+		value := amount.Mul(price)
+		held := now.Sub(l.Time)
+		lot := TaxLot{
+			Lot:         l,
+			Price:       price.Float64(),
+			PriceStr:    price.String(),
+			Value:       value.Float64(),
+			ValueStr:    value.String(),
+			HoldingDays: int(held / (24 * time.Hour)),
+			Term:        ShortTerm,
+		}
+		if l.Time.AddDate(1, 0, 0).Before(now) {
+			lot.Term = LongTerm
+		}
+		total = total.Add(value)
+
+		// Lots just before the first point are priced at it
+		at := priceAt(points, l.Time)
+		if at == 0 && len(points) > 0 && points[0].Time.Sub(l.Time) <= 24*time.Hour {
+			at = points[0].Price
+		}
+		if at > 0 {
+			cost := amount.Mul(decimal.FromFloat(at))
+			gain := value.Sub(cost)
+			c, g := cost.Float64(), gain.Float64()
+			pct := gain.Quo(cost).Float64() * 100
+			lot.AcquisitionPrice, lot.CostBasis, lot.Gain, lot.GainPercent = &at, &c, &g, &pct
+			totalCost = totalCost.Add(cost)
+			totalGain = totalGain.Add(gain)
+		}
+		out.Lots[i] = lot
+	}
+	out.Value, out.ValueStr = total.Float64(), total.String()
+	out.CostBasis, out.Gain = totalCost.Float64(), totalGain.Float64()
+	return out, nil
+}
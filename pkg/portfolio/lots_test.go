@@ -0,0 +1,122 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package portfolio_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/portfolio"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+func TestLots(t *testing.T) {
+	now := time.Now().UTC()
+	day := func(d int) time.Time { return now.AddDate(0, 0, -d) }
+	svc := portfolio.NewService(
+		fixedHistory(map[string][]history.Point{
+			"bitcoin":  {{Time: day(500), Price: 50}, {Time: day(30), Price: 80}, {Time: day(10), Price: 100}},
+			"ethereum": {{Time: day(30), Price: 8}, {Time: day(1), Price: 10}},
+		}),
+		fixedPrices(map[string]string{"bitcoin": "120"}),
+	)
+
+	req := portfolio.LotsRequest{Lots: []portfolio.Lot{
+		{Token: "bitcoin", Amount: 2, Time: day(400)},                 // priced 50, long term
+		{Token: "bitcoin", Amount: 0.5, Time: day(20)},                // priced 80, short term
+		{Token: "bitcoin", Amount: 1, Time: day(600)},                 // before the history
+		{Token: "bitcoin", Amount: 1, Time: day(500).Add(-time.Hour)}, // just before it: its first point
+		{Token: "ethereum", Amount: 3, Time: day(5)},                  // no current price: the last point's 10
+	}}
+	if err := portfolio.ValidateLotsRequest(&req); err != nil {
+		t.Fatalf("ValidateLotsRequest: %v", err)
+	}
+	lots, err := svc.Lots(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Lots: %v", err)
+	}
+
+	tests := []struct {
+		acquired, cost, gain, pct float64 // 0: unknown
+		value                     string
+		term                      string
+		days                      int
+	}{
+		{acquired: 50, cost: 100, gain: 140, pct: 140, value: "240", term: portfolio.LongTerm, days: 400},
+		{acquired: 80, cost: 40, gain: 20, pct: 50, value: "60", term: portfolio.ShortTerm, days: 20},
+		{value: "120", term: portfolio.LongTerm, days: 600},
+		{acquired: 50, cost: 50, gain: 70, pct: 140, value: "120", term: portfolio.LongTerm, days: 500},
+		{acquired: 8, cost: 24, gain: 6, pct: 25, value: "30", term: portfolio.ShortTerm, days: 5},
+	}
+	for i, tt := range tests {
+		lot := lots.Lots[i]
+		if lot.ValueStr != tt.value || lot.Term != tt.term || lot.HoldingDays != tt.days {
+			t.Errorf("lot %d: value %s, %s term, %d days; want %s, %s, %d", i, lot.ValueStr, lot.Term, lot.HoldingDays, tt.value, tt.term, tt.days)
+		}
+		if tt.acquired == 0 {
+			if lot.AcquisitionPrice != nil || lot.CostBasis != nil || lot.Gain != nil || lot.GainPercent != nil {
+				t.Errorf("lot %d: %+v, want an unknown cost basis", i, lot)
+			}
+			continue
+		}
+		if lot.AcquisitionPrice == nil || *lot.AcquisitionPrice != tt.acquired ||
+			lot.CostBasis == nil || *lot.CostBasis != tt.cost ||
+			lot.Gain == nil || *lot.Gain != tt.gain ||
+			lot.GainPercent == nil || *lot.GainPercent != tt.pct {
+			t.Errorf("lot %d: acquired %v, cost %v, gain %v (%v%%); want %v, %v, %v (%v%%)", i,
+				lot.AcquisitionPrice, lot.CostBasis, lot.Gain, lot.GainPercent, tt.acquired, tt.cost, tt.gain, tt.pct)
+		}
+	}
+
+	// Totals of cost and gain leave out the lot with no cost basis
+	if lots.ValueStr != "570" || lots.CostBasis != 214 || lots.Gain != 236 {
+		t.Errorf("totals = %s, cost basis %v, gain %v; want 570, 214, 236", lots.ValueStr, lots.CostBasis, lots.Gain)
+	}
+}
+
+func TestLotsUnknownToken(t *testing.T) {
+	svc := portfolio.NewService(fixedHistory(nil), fixedPrices(nil))
+	req := portfolio.LotsRequest{Lots: []portfolio.Lot{{Token: "nosuchcoin", Amount: 1, Time: time.Now().AddDate(0, 0, -1)}}}
+	if err := portfolio.ValidateLotsRequest(&req); err != nil {
+		t.Fatalf("ValidateLotsRequest: %v", err)
+	}
+	if _, err := svc.Lots(context.Background(), req); !errors.Is(err, providers.ErrTokenNotFound) {
+		t.Errorf("Lots: %v, want ErrTokenNotFound", err)
+	}
+}
+
+func TestValidateLotsRequest(t *testing.T) {
+	past := time.Now().AddDate(0, 0, -1)
+	tests := []struct {
+		name    string
+		lots    []portfolio.Lot
+		wantErr string
+	}{
+		{name: "valid", lots: []portfolio.Lot{{Token: " Bitcoin ", Amount: 1, Time: past}}},
+		{name: "none", wantErr: "lots required"},
+		{name: "no token", lots: []portfolio.Lot{{Amount: 1, Time: past}}, wantErr: "token required"},
+		{name: "zero amount", lots: []portfolio.Lot{{Token: "bitcoin", Time: past}}, wantErr: "amount must be positive"},
+		{name: "sale", lots: []portfolio.Lot{{Token: "bitcoin", Amount: -1, Time: past}}, wantErr: "amount must be positive"},
+		{name: "no time", lots: []portfolio.Lot{{Token: "bitcoin", Amount: 1}}, wantErr: "in the past"},
+		{name: "future", lots: []portfolio.Lot{{Token: "bitcoin", Amount: 1, Time: time.Now().Add(time.Hour)}}, wantErr: "in the past"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := portfolio.LotsRequest{Currency: " USD ", Lots: tt.lots}
+			err := portfolio.ValidateLotsRequest(&req)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("ValidateLotsRequest: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("ValidateLotsRequest: %v, want %q", err, tt.wantErr)
+			case err == nil && (req.Currency != "usd" || req.Lots[0].Token != "bitcoin"):
+				t.Errorf("normalized to %q, %q; want usd, bitcoin", req.Currency, req.Lots[0].Token)
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package portfolio values token holdings over time and computes their
+// profit and loss.
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+const (
+	// MaxHoldings is the most tokens one request may hold
+	MaxHoldings = 25
+
+	// DefaultDays is the length of the value series if none is requested
+	DefaultDays = 30
+)
+
+// ReturnWindows are the periods, in days, returns are reported over
+var ReturnWindows = []int{7, 30, 90}
+
+// Trade is a purchase or sale of a holding's token
+type Trade = wire.PortfolioTrade
+
+// Holding is an amount of a token held now. Trades, if given, are used
+// for the cost basis and realized PnL and to value the holding as it was
+// before them; any amount not accounted for by trades was held from the
+// start at CostBasis, and PnL is unknown if that is not set.
+type Holding = wire.PortfolioHolding
+
+// Request is a portfolio to evaluate
+type Request = wire.PortfolioRequest
+
+// ValidateRequest normalizes r and reports the first invalid field
+func ValidateRequest(r *Request) error {
+	r.Currency = strings.ToLower(strings.TrimSpace(r.Currency))
+	if r.Currency == "" {
+		r.Currency = "usd"
+	}
+	if r.Days == 0 {
+		r.Days = DefaultDays
+	}
+	switch {
+	case r.Days < 1 || r.Days > history.MaxDays:
+		return fmt.Errorf("days must be between 1 and %d", history.MaxDays)
+	case len(r.Holdings) == 0:
+		return errors.New("holdings required")
+	case len(r.Holdings) > MaxHoldings:
+		return fmt.Errorf("at most %d holdings", MaxHoldings)
+	}
+
+	seen := make(map[string]bool)
+	for i := range r.Holdings {
+		h := &r.Holdings[i]
+		h.Token = strings.ToLower(strings.TrimSpace(h.Token))
+		switch {
+		case h.Token == "":
+			return fmt.Errorf("holdings[%d]: token required", i)
+		case seen[h.Token]:
+			return fmt.Errorf("holdings[%d]: %s listed twice", i, h.Token)
+		case h.Amount < 0:
+			return fmt.Errorf("holdings[%d]: amount must not be negative", i)
+		case h.CostBasis != nil && *h.CostBasis < 0:
+			return fmt.Errorf("holdings[%d]: cost_basis must not be negative", i)
+		}
+		seen[h.Token] = true
+
+		sort.SliceStable(h.Trades, func(a, b int) bool { return h.Trades[a].Time.Before(h.Trades[b].Time) })
+		for j, t := range h.Trades {
+			if t.Amount == 0 || t.Price < 0 || t.Time.IsZero() {
+				return fmt.Errorf("holdings[%d].trades[%d]: time, a non-zero amount and a price are required", i, j)
+			}
+		}
+		// The amount held must never go negative, before or between trades
+		held := opening(h)
+		for _, t := range h.Trades {
+			if held.Sign() < 0 {
+				break
+			}
+			held = held.Add(decimal.FromFloat(t.Amount))
+		}
+		if held.Sign() < 0 {
+			return fmt.Errorf("holdings[%d]: trades sell more %s than held", i, h.Token)
+		}
+	}
+	return nil
+}
+
+// opening is the amount held before the first trade
+func opening(h *Holding) decimal.Decimal {
+	amount := decimal.FromFloat(h.Amount)
+	for _, t := range h.Trades {
+		amount = amount.Sub(decimal.FromFloat(t.Amount))
+	}
+	return amount
+}
+
+// amountAt is the amount h holds at t
+func amountAt(h *Holding, t time.Time) float64 {
+	amount := h.Amount
+	for _, trade := range h.Trades {
+		if trade.Time.After(t) {
+			amount -= trade.Amount
+		}
+	}
+	return amount
+}
+
+// Point is the portfolio's value at a point in time
+type Point = wire.PortfolioPoint
+
+// Position is a holding's current value and PnL. Cost and PnL are null
+// when part of the holding has no known cost.
+type Position = wire.PortfolioPosition
+
+// Performance is a portfolio's value over time and its PnL. Totals of
+// cost and PnL cover the positions where they are known.
+type Performance = wire.PortfolioPerformance
+
+// HistoryFunc returns a token's price history, e.g. history.Service.History
+type HistoryFunc func(ctx context.Context, tokenID, currency string, days int) (*history.Series, error)
+
+// PricesFunc returns exact current prices by token id; missing tokens are
+// valued at their latest history point
+type PricesFunc func(ctx context.Context, tokenIDs []string, currency string) (map[string]decimal.Decimal, error)
+
+// Service evaluates portfolios against price history
+type Service struct {
+	history HistoryFunc
+	prices  PricesFunc
+
+	// Categories returns a token's categories, e.g.
+	// categories.Service.Of, for allocations by category; tokens are
+	// uncategorized if nil
+	Categories func(tokenID string) []string
+
+	// Stablecoin reports whether a token is a stablecoin, for allocations
+	// by stability; every token is volatile if nil
+	Stablecoin func(tokenID string) bool
+}
+
+// NewService creates a portfolio service
+func NewService(history HistoryFunc, prices PricesFunc) *Service {
+	return &Service{history: history, prices: prices}
+}
+
+// Performance values req's holdings daily over the last req.Days and
+// computes their PnL and returns. req must have been validated.
+func (s *Service) Performance(ctx context.Context, req Request) (*Performance, error) {
+	days := req.Days
+	for _, w := range ReturnWindows {
+		if w > days {
+			days = w
+		}
+	}
+
+	series := make([][]history.Point, len(req.Holdings))
+	errs := make([]error, len(req.Holdings))
+	var wg sync.WaitGroup
+	for i, h := range req.Holdings {
+		wg.Add(1)
+		go func(i int, token string) {
+			defer wg.Done()
+			hist, err := s.history(ctx, token, req.Currency, days)
+			if errors.Is(err, providers.ErrTokenNotFound) {
+				errs[i] = err
+				return
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("%s history: %w", token, err)
+				return
+			}
+			series[i] = hist.Points
+		}(i, h.Token)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(req.Holdings))
+	for i, h := range req.Holdings {
+		ids[i] = h.Token
+	}
+	current, err := s.prices(ctx, ids, req.Currency)
+	if err != nil {
+		log.Printf("Portfolio: current prices: %v", err)
+	}
+
+	now := time.Now().UTC()
+	perf := &Performance{
+		Currency:  req.Currency,
+		Days:      req.Days,
+		Returns:   make(map[string]*float64, len(ReturnWindows)),
+		Positions: make([]Position, len(req.Holdings)),
+		UpdatedAt: now,
+	}
+
+	// Positions at current prices, totalled exactly
+	var total, cost, unrealized, realized decimal.Decimal
+	for i, h := range req.Holdings {
+		price, ok := current[h.Token]
+		if !ok {
+			price = decimal.FromFloat(priceAt(series[i], now))
+		}
+		pos, exact := position(h, price)
+		perf.Positions[i] = pos
+		total = total.Add(exact.value)
+		if pos.Cost != nil {
+			cost = cost.Add(exact.cost)
+			unrealized = unrealized.Add(exact.unrealized)
+			realized = realized.Add(exact.realized)
+		}
+	}
+	perf.Value, perf.ValueStr = total.Float64(), total.String()
+	perf.Cost, perf.UnrealizedPnL, perf.RealizedPnL = cost.Float64(), unrealized.Float64(), realized.Float64()
+
+	// value is the portfolio's worth at t, now at current prices
+	value := func(t time.Time) float64 {
+		if !t.Before(now) {
+			return perf.Value
+		}
+		var v float64
+		for i := range req.Holdings {
+			v += amountAt(&req.Holdings[i], t) * priceAt(series[i], t)
+		}
+		return v
+	}
+
+	today := now.Truncate(24 * time.Hour)
+	for d := req.Days - 1; d >= 0; d-- {
+		t := today.AddDate(0, 0, -d)
+		perf.Points = append(perf.Points, Point{Time: t, Value: value(t)})
+	}
+	perf.Points = append(perf.Points, Point{Time: now, Value: perf.Value})
+
+	for _, w := range ReturnWindows {
+		start := now.AddDate(0, 0, -w)
+		base := value(start)
+		key := strconv.Itoa(w) + "d"
+		if base <= 0 {
+			perf.Returns[key] = nil
+			continue
+		}
+		var flows float64
+		for _, h := range req.Holdings {
+			for _, t := range h.Trades {
+				if t.Time.After(start) && !t.Time.After(now) {
+					flows += t.Amount * t.Price
+				}
+			}
+		}
+		r := (perf.Value - base - flows) / base * 100
+		perf.Returns[key] = &r
+	}
+	return perf, nil
+}
+
+// positionTotals are a position's amounts in exact decimals, for the
+// portfolio totals
+type positionTotals struct {
+	value, cost, unrealized, realized decimal.Decimal
+}
+
+// position values a holding at price, with its cost and PnL by the
+// average cost method, in exact decimals
+func position(h Holding, price decimal.Decimal) (Position, positionTotals) {
+	held := decimal.FromFloat(h.Amount)
+	value := held.Mul(price)
+	pos := Position{
+		Token:    h.Token,
+		Amount:   h.Amount,
+		Price:    price.Float64(),
+		PriceStr: price.String(),
+		Value:    value.Float64(),
+		ValueStr: value.String(),
+	}
+	exact := positionTotals{value: value}
+	amount := opening(&h)
+	if h.CostBasis == nil && amount.Sign() > 0 {
+		return pos, exact
+	}
+
+	var basis decimal.Decimal
+	if h.CostBasis != nil {
+		basis = decimal.FromFloat(*h.CostBasis)
+	}
+	cost := amount.Mul(basis)
+	var realized decimal.Decimal
+	for _, t := range h.Trades {
+		traded, at := decimal.FromFloat(t.Amount), decimal.FromFloat(t.Price)
+		if t.Amount > 0 {
+			cost = cost.Add(traded.Mul(at))
+			amount = amount.Add(traded)
+			continue
+		}
+		var avg decimal.Decimal
+		if amount.Sign() > 0 {
+			avg = cost.Quo(amount)
+		}
+		realized = realized.Sub(traded.Mul(at.Sub(avg)))
+		cost = cost.Add(traded.Mul(avg))
+		amount = amount.Add(traded)
+	}
+
+	unrealized := value.Sub(cost)
+	exact.cost, exact.unrealized, exact.realized = cost, unrealized, realized
+	c, u, r := cost.Float64(), unrealized.Float64(), realized.Float64()
+	pos.Cost, pos.UnrealizedPnL, pos.RealizedPnL = &c, &u, &r
+	if h.Amount > 0 {
+		avg := cost.Quo(held).Float64()
+		pos.CostBasis = &avg
+	}
+	return pos, exact
+}
+
+// priceAt is the last price in points at or before t, or 0 before the
+// first point
+func priceAt(points []history.Point, t time.Time) float64 {
+	i := sort.Search(len(points), func(i int) bool { return points[i].Time.After(t) })
+	if i == 0 {
+		return 0
+	}
+	return points[i-1].Price
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package portfolio_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/portfolio"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// fixedHistory answers every token in points with its points and any other
+// token as not found
+func fixedHistory(points map[string][]history.Point) portfolio.HistoryFunc {
+	return func(ctx context.Context, tokenID, currency string, days int) (*history.Series, error) {
+		p, ok := points[tokenID]
+		if !ok {
+			return nil, providers.ErrTokenNotFound
+		}
+		return &history.Series{ID: tokenID, Currency: currency, Days: days, Points: p}, nil
+	}
+}
+
+// fixedPrices answers current prices from prices, leaving out tokens it
+// doesn't hold
+func fixedPrices(prices map[string]string) portfolio.PricesFunc {
+	return func(ctx context.Context, tokenIDs []string, currency string) (map[string]decimal.Decimal, error) {
+		out := make(map[string]decimal.Decimal)
+		for _, id := range tokenIDs {
+			if p, ok := prices[id]; ok {
+				out[id], _ = decimal.Parse(p)
+			}
+		}
+		return out, nil
+	}
+}
+
+func ptr(f float64) *float64 { return &f }
+
+func TestPerformanceAverageCost(t *testing.T) {
+	now := time.Now().UTC()
+	day := func(d int) time.Time { return now.AddDate(0, 0, -d) }
+	svc := portfolio.NewService(
+		fixedHistory(map[string][]history.Point{
+			"bitcoin":  {{Time: day(60), Price: 100}},
+			"ethereum": {{Time: day(60), Price: 10}, {Time: day(1), Price: 12}},
+		}),
+		fixedPrices(map[string]string{"bitcoin": "200"}),
+	)
+
+	// The ledger, by the average cost method:
+	//   held from the start  1   @ 100  pool 1   cost 100
+	//   buy                  1   @ 130  pool 2   cost 230, average 115
+	//   sell part            1.5 @ 160  pool 0.5 cost 57.5, realized 1.5 × (160 − 115) = 67.5
+	//   buy                  1   @ 190  pool 1.5 cost 247.5, average 165
+	// valued at 200: 300, unrealized 300 − 247.5 = 52.5
+	req := portfolio.Request{
+		Days: 7,
+		Holdings: []portfolio.Holding{
+			{
+				Token:     "bitcoin",
+				Amount:    1.5,
+				CostBasis: ptr(100),
+				Trades: []portfolio.Trade{
+					{Time: day(20), Amount: -1.5, Price: 160},
+					{Time: day(30), Amount: 1, Price: 130},
+					{Time: day(10), Amount: 1, Price: 190},
+				},
+			},
+			// No cost basis for what was held before the trades: value only
+			{Token: "ethereum", Amount: 3, Trades: []portfolio.Trade{{Time: day(5), Amount: 1, Price: 11}}},
+		},
+	}
+	if err := portfolio.ValidateRequest(&req); err != nil {
+		t.Fatalf("ValidateRequest: %v", err)
+	}
+	perf, err := svc.Performance(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Performance: %v", err)
+	}
+
+	btc := perf.Positions[0]
+	if btc.ValueStr != "300" || btc.PriceStr != "200" {
+		t.Errorf("bitcoin value = %s at %s, want 300 at 200", btc.ValueStr, btc.PriceStr)
+	}
+	for name, got := range map[string]struct {
+		got  *float64
+		want float64
+	}{
+		"cost":           {btc.Cost, 247.5},
+		"cost_basis":     {btc.CostBasis, 165},
+		"realized_pnl":   {btc.RealizedPnL, 67.5},
+		"unrealized_pnl": {btc.UnrealizedPnL, 52.5},
+	} {
+		if got.got == nil || *got.got != got.want {
+			t.Errorf("bitcoin %s = %v, want %v", name, got.got, got.want)
+		}
+	}
+
+	// Ethereum has no current price, so is valued at its last point
+	eth := perf.Positions[1]
+	if eth.ValueStr != "36" || eth.Cost != nil || eth.CostBasis != nil || eth.RealizedPnL != nil {
+		t.Errorf("ethereum = %+v, want 36 with unknown cost", eth)
+	}
+
+	// Totals cover the positions with a known cost
+	if perf.ValueStr != "336" || perf.Cost != 247.5 || perf.UnrealizedPnL != 52.5 || perf.RealizedPnL != 67.5 {
+		t.Errorf("totals = %s, cost %v, unrealized %v, realized %v; want 336, 247.5, 52.5, 67.5",
+			perf.ValueStr, perf.Cost, perf.UnrealizedPnL, perf.RealizedPnL)
+	}
+}
+
+func TestPerformanceSellEverything(t *testing.T) {
+	now := time.Now().UTC()
+	svc := portfolio.NewService(
+		fixedHistory(map[string][]history.Point{"bitcoin": {{Time: now.AddDate(0, 0, -30), Price: 100}}}),
+		fixedPrices(map[string]string{"bitcoin": "300"}),
+	)
+	// Bought 2 for 200, sold them for 500: all of it realized
+	req := portfolio.Request{Holdings: []portfolio.Holding{{
+		Token: "bitcoin",
+		Trades: []portfolio.Trade{
+			{Time: now.AddDate(0, 0, -20), Amount: 2, Price: 100},
+			{Time: now.AddDate(0, 0, -10), Amount: -2, Price: 250},
+		},
+	}}}
+	if err := portfolio.ValidateRequest(&req); err != nil {
+		t.Fatalf("ValidateRequest: %v", err)
+	}
+	perf, err := svc.Performance(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Performance: %v", err)
+	}
+	pos := perf.Positions[0]
+	if pos.ValueStr != "0" || *pos.Cost != 0 || *pos.RealizedPnL != 300 || *pos.UnrealizedPnL != 0 || pos.CostBasis != nil {
+		t.Errorf("position = %+v, want nothing held and 300 realized", pos)
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	now := time.Now().UTC()
+	day := func(d int) time.Time { return now.AddDate(0, 0, -d) }
+	tests := []struct {
+		name    string
+		holding portfolio.Holding
+		wantErr string
+	}{
+		{name: "valid", holding: portfolio.Holding{Token: "bitcoin", Amount: 1}},
+		{name: "negative amount", holding: portfolio.Holding{Token: "bitcoin", Amount: -1}, wantErr: "must not be negative"},
+		{name: "negative cost basis", holding: portfolio.Holding{Token: "bitcoin", Amount: 1, CostBasis: ptr(-1)}, wantErr: "cost_basis"},
+		{
+			name:    "zero trade",
+			holding: portfolio.Holding{Token: "bitcoin", Amount: 1, Trades: []portfolio.Trade{{Time: day(1), Price: 1}}},
+			wantErr: "non-zero amount",
+		},
+		{
+			// Holding 1 now after buying 2 means holding -1 before
+			name:    "sold more than held before the trades",
+			holding: portfolio.Holding{Token: "bitcoin", Amount: 1, Trades: []portfolio.Trade{{Time: day(1), Amount: 2, Price: 1}}},
+			wantErr: "sell more bitcoin than held",
+		},
+		{
+			// Nets out to nothing, but holds -1 between the trades
+			name: "sold more than held between the trades",
+			holding: portfolio.Holding{Token: "bitcoin", Trades: []portfolio.Trade{
+				{Time: day(1), Amount: 1, Price: 1},
+				{Time: day(2), Amount: -1, Price: 1},
+			}},
+			wantErr: "sell more bitcoin than held",
+		},
+		{
+			name: "sold down to nothing and bought back",
+			holding: portfolio.Holding{Token: "bitcoin", Amount: 1, Trades: []portfolio.Trade{
+				{Time: day(2), Amount: -1, Price: 1},
+				{Time: day(1), Amount: 1, Price: 1},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := portfolio.Request{Holdings: []portfolio.Holding{tt.holding}}
+			err := portfolio.ValidateRequest(&req)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("ValidateRequest: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("ValidateRequest: %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
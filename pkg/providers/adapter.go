@@ -0,0 +1,106 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTPAdapter is a price source served by a sidecar process. Adapters let
+// teams add bespoke feeds (an OTC desk, an internal index) without
+// changing this service. The contract is one endpoint:
+//
+//	GET {base}/prices?ids=a,b&currency=usd
+//
+// returning 200 with a JSON array of Price objects. Unknown ids are
+// omitted from the array; any other status is an error. Prices without a
+// source are attributed to the adapter.
+type HTTPAdapter struct {
+	name    string
+	baseURL string
+	client  *http.Client
+	schemas *SchemaMonitor
+}
+
+// NewHTTPAdapter creates an adapter for the sidecar at baseURL. Requests
+// use transport, or a default pooled transport if nil.
+func NewHTTPAdapter(name, baseURL string, timeout time.Duration, transport http.RoundTripper) *HTTPAdapter {
+	if transport == nil {
+		transport = NewTransport(DefaultTransportConfig())
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &HTTPAdapter{
+		name:    name,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout, Transport: transport},
+	}
+}
+
+// SetSchemaMonitor counts responses that do not match the expected schema
+// in m
+func (a *HTTPAdapter) SetSchemaMonitor(m *SchemaMonitor) {
+	a.schemas = m
+}
+
+// Name identifies the provider
+func (a *HTTPAdapter) Name() string {
+	return a.name
+}
+
+// FetchPrice fetches a single price from the sidecar
+func (a *HTTPAdapter) FetchPrice(ctx context.Context, tokenID, currency string) (*Price, error) {
+	prices, err := a.FetchPrices(ctx, []string{tokenID}, currency)
+	if err != nil {
+		return nil, err
+	}
+	for i := range prices {
+		if prices[i].ID == tokenID {
+			return &prices[i], nil
+		}
+	}
+	return nil, &NotFoundError{Token: tokenID}
+}
+
+// FetchPrices fetches prices from the sidecar in one request
+func (a *HTTPAdapter) FetchPrices(ctx context.Context, tokenIDs []string, currency string) ([]Price, error) {
+	q := url.Values{}
+	q.Set("ids", strings.Join(tokenIDs, ","))
+	q.Set("currency", currency)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", a.baseURL+"/prices?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return nil, fmt.Errorf("%s adapter error: %d - %s", a.name, resp.StatusCode, string(body))
+	}
+
+	prices, err := a.schemas.decodeMarkets(resp.Body, a.name, adapterSchema)
+	if err != nil {
+		return nil, fmt.Errorf("%s adapter: %w", a.name, err)
+	}
+	for i := range prices {
+		if prices[i].Source == "" {
+			prices[i].Source = a.name
+		}
+	}
+	return prices, nil
+}
@@ -0,0 +1,403 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHedgeAfter is how long a hedged call waits for a provider
+	// whose latency isn't known yet
+	DefaultHedgeAfter = 250 * time.Millisecond
+
+	// minHedgeAfter keeps hedges from doubling the calls to a provider
+	// that is merely fast
+	minHedgeAfter = 10 * time.Millisecond
+
+	// balancerDecay is the weight of each call in the moving averages
+	balancerDecay = 0.2
+
+	// balancerUnhealthy is the error rate above which a provider is only
+	// tried after the healthy ones
+	balancerUnhealthy = 0.5
+
+	// balancerProbe is how long a provider may go unused before a call is
+	// routed to it first, to keep its latency and health current
+	balancerProbe = time.Minute
+
+	// balancerSamples is the number of recent latencies p95 is taken over
+	balancerSamples = 64
+)
+
+type hedgeKey struct{}
+
+// errHedgeLost cancels the slower call of a hedged pair
+var errHedgeLost = errors.New("hedged call lost")
+
+// WithHedge marks calls made with ctx as latency sensitive: a Balancer
+// also sends them to its next fastest provider if the fastest hasn't
+// answered within its p95 latency
+func WithHedge(ctx context.Context) context.Context {
+	return context.WithValue(ctx, hedgeKey{}, true)
+}
+
+func hedged(ctx context.Context) bool {
+	h, _ := ctx.Value(hedgeKey{}).(bool)
+	return h
+}
+
+// RouteStatus is a snapshot of a provider's routing statistics
+type RouteStatus struct {
+	Provider  string     `json:"provider"`
+	Rank      int        `json:"rank"` // order calls try the provider in, from 1
+	Healthy   bool       `json:"healthy"`
+	LatencyMs float64    `json:"latency_ms"` // moving average of successful calls
+	P95Ms     float64    `json:"p95_ms"`
+	ErrorRate float64    `json:"error_rate"` // moving average
+	Calls     int64      `json:"calls"`
+	Errors    int64      `json:"errors"`
+	Routed    int64      `json:"routed"`     // calls the provider was tried first for
+	Failovers int64      `json:"failovers"`  // calls it took over after others failed
+	Hedges    int64      `json:"hedges"`     // hedged calls sent to it
+	HedgeWins int64      `json:"hedge_wins"` // hedged calls it answered first
+	LastUsed  *time.Time `json:"last_used,omitempty"`
+}
+
+// Balancer sends each call to the fastest healthy of several providers
+// serving the same tokens, and on failure to the next fastest. Latency
+// and error rate are tracked per provider as moving averages over its
+// calls; a provider failing more than half of them is tried only after
+// the healthy ones. A provider unused for a minute is tried first once,
+// so a recovered or sped up provider is noticed.
+type Balancer struct {
+	members    []*member
+	hedgeAfter time.Duration
+}
+
+// member is a provider and its routing statistics
+type member struct {
+	provider Provider
+
+	mu        sync.Mutex
+	latency   time.Duration // moving average of successful calls
+	errorRate float64
+	samples   []time.Duration // ring of recent successful latencies
+	next      int
+	lastUsed  time.Time
+	calls     int64
+	errors    int64
+	routed    int64
+	failovers int64
+	hedges    int64
+	hedgeWins int64
+}
+
+// NewBalancer balances calls between providers. Hedged calls go to the
+// runner-up after hedgeAfter, or the fastest provider's p95 latency if 0.
+func NewBalancer(hedgeAfter time.Duration, ps ...Provider) *Balancer {
+	b := &Balancer{hedgeAfter: hedgeAfter}
+	for _, p := range ps {
+		b.members = append(b.members, &member{provider: p})
+	}
+	return b
+}
+
+// Name identifies the provider
+func (b *Balancer) Name() string {
+	return "balancer"
+}
+
+// FetchPrice fetches a price from the fastest provider that has it
+func (b *Balancer) FetchPrice(ctx context.Context, tokenID, currency string) (*Price, error) {
+	return balance(ctx, b, func(ctx context.Context, p Provider) (*Price, bool, error) {
+		price, err := p.FetchPrice(ctx, tokenID, currency)
+		return price, err == nil, err
+	})
+}
+
+// FetchPrices fetches prices from the fastest provider that returns any
+func (b *Balancer) FetchPrices(ctx context.Context, tokenIDs []string, currency string) ([]Price, error) {
+	return balance(ctx, b, func(ctx context.Context, p Provider) ([]Price, bool, error) {
+		prices, err := p.FetchPrices(ctx, tokenIDs, currency)
+		return prices, err == nil || len(prices) > 0, err
+	})
+}
+
+// Status returns each provider's statistics in the order calls try them
+func (b *Balancer) Status() []RouteStatus {
+	order := b.order(false)
+	statuses := make([]RouteStatus, len(order))
+	for i, m := range order {
+		m.mu.Lock()
+		statuses[i] = RouteStatus{
+			Provider:  m.provider.Name(),
+			Rank:      i + 1,
+			Healthy:   m.errorRate <= balancerUnhealthy,
+			LatencyMs: float64(m.latency) / float64(time.Millisecond),
+			P95Ms:     float64(m.p95()) / float64(time.Millisecond),
+			ErrorRate: m.errorRate,
+			Calls:     m.calls,
+			Errors:    m.errors,
+			Routed:    m.routed,
+			Failovers: m.failovers,
+			Hedges:    m.hedges,
+			HedgeWins: m.hedgeWins,
+		}
+		if !m.lastUsed.IsZero() {
+			used := m.lastUsed
+			statuses[i].LastUsed = &used
+		}
+		m.mu.Unlock()
+	}
+	return statuses
+}
+
+// balance makes a call to the providers in order until one succeeds.
+// Hedged calls race the first two.
+func balance[T any](ctx context.Context, b *Balancer, call func(context.Context, Provider) (T, bool, error)) (T, error) {
+	order := b.order(true)
+	var errs []error
+	i := 0
+	if hedged(ctx) && len(order) > 1 {
+		v, ok, hedgeErrs, tried := hedge(ctx, b, order[0], order[1], call)
+		if ok {
+			// The winner's error, for prices returned with one
+			return v, errors.Join(hedgeErrs...)
+		}
+		if ctx.Err() != nil {
+			return v, balancerError(hedgeErrs)
+		}
+		errs, i = hedgeErrs, tried
+	}
+	for ; i < len(order); i++ {
+		m := order[i]
+		m.count(func() {
+			if i == 0 {
+				m.routed++
+			} else {
+				m.failovers++
+			}
+		})
+		v, ok, err := try(ctx, m, call)
+		if ok || ctx.Err() != nil {
+			return v, err
+		}
+		errs = append(errs, err)
+	}
+	var zero T
+	return zero, balancerError(errs)
+}
+
+// hedge calls primary, and backup too if primary hasn't answered in time,
+// returning the first success with its error, or the errors of those
+// tried. If primary fails before backup is called, only primary was
+// tried.
+func hedge[T any](ctx context.Context, b *Balancer, primary, backup *member, call func(context.Context, Provider) (T, bool, error)) (T, bool, []error, int) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(errHedgeLost)
+
+	type result struct {
+		m   *member
+		v   T
+		ok  bool
+		err error
+	}
+	results := make(chan result, 2)
+	run := func(m *member) {
+		go func() {
+			v, ok, err := try(ctx, m, call)
+			results <- result{m, v, ok, err}
+		}()
+	}
+
+	primary.count(func() { primary.routed++ })
+	run(primary)
+	timer := time.NewTimer(b.hedgeDelay(primary))
+	defer timer.Stop()
+
+	var errs []error
+	tried, pending := 1, 1
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if tried == 1 {
+				backup.count(func() { backup.hedges++ })
+				run(backup)
+				tried, pending = 2, pending+1
+			}
+		case r := <-results:
+			pending--
+			if r.ok {
+				if r.m == backup {
+					backup.count(func() { backup.hedgeWins++ })
+				}
+				return r.v, true, []error{r.err}, tried
+			}
+			errs = append(errs, r.err)
+			if tried == 1 {
+				// Failed before the hedge; the caller fails over
+				var zero T
+				return zero, false, errs, tried
+			}
+		}
+	}
+	var zero T
+	return zero, false, errs, tried
+}
+
+// try makes a call to m's provider and records how it went
+func try[T any](ctx context.Context, m *member, call func(context.Context, Provider) (T, bool, error)) (T, bool, error) {
+	m.count(func() { m.lastUsed = time.Now() })
+	start := time.Now()
+	v, ok, err := call(ctx, m.provider)
+	m.record(ctx, time.Since(start), ok, err)
+	return v, ok, err
+}
+
+// balancerError keeps the failures that aren't unknown tokens, so a token
+// one provider doesn't list isn't reported unknown while another is down
+func balancerError(errs []error) error {
+	var failures []error
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, ErrTokenNotFound) {
+			failures = append(failures, err)
+		}
+	}
+	if len(failures) > 0 {
+		return errors.Join(failures...)
+	}
+	return errors.Join(errs...)
+}
+
+// order returns the members in the order calls try them: a stale one
+// first if probe is set, then healthy ones from the fastest, those not
+// yet measured last, then unhealthy ones from the least failing
+func (b *Balancer) order(probe bool) []*member {
+	type ranked struct {
+		m        *member
+		healthy  bool
+		measured bool
+		latency  time.Duration
+		errRate  float64
+		stale    bool
+	}
+	now := time.Now()
+	rs := make([]ranked, len(b.members))
+	for i, m := range b.members {
+		m.mu.Lock()
+		rs[i] = ranked{m, m.errorRate <= balancerUnhealthy, len(m.samples) > 0, m.latency, m.errorRate, now.Sub(m.lastUsed) > balancerProbe}
+		m.mu.Unlock()
+	}
+	sort.SliceStable(rs, func(i, j int) bool {
+		a, b := rs[i], rs[j]
+		switch {
+		case a.healthy != b.healthy:
+			return a.healthy
+		case !a.healthy:
+			return a.errRate < b.errRate
+		case a.measured != b.measured:
+			// Unmeasured providers are reached by probes
+			return a.measured
+		default:
+			return a.latency < b.latency
+		}
+	})
+
+	order := make([]*member, len(rs))
+	for i, r := range rs {
+		order[i] = r.m
+	}
+	if !probe {
+		return order
+	}
+	for i, r := range rs {
+		if !r.stale {
+			continue
+		}
+		// Claim the probe so concurrent calls don't all take it
+		r.m.mu.Lock()
+		claimed := now.Sub(r.m.lastUsed) > balancerProbe
+		if claimed {
+			r.m.lastUsed = now
+		}
+		r.m.mu.Unlock()
+		if claimed {
+			copy(order[1:i+1], order[:i])
+			order[0] = r.m
+		}
+		break
+	}
+	return order
+}
+
+// hedgeDelay is how long a hedged call waits for m before calling the
+// runner-up
+func (b *Balancer) hedgeDelay(m *member) time.Duration {
+	if b.hedgeAfter > 0 {
+		return b.hedgeAfter
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.samples) == 0 {
+		return DefaultHedgeAfter
+	}
+	return max(m.p95(), minHedgeAfter)
+}
+
+// count updates m's counters under its lock
+func (m *member) count(update func()) {
+	m.mu.Lock()
+	update()
+	m.mu.Unlock()
+}
+
+// record folds a call into m's statistics. Unknown tokens and calls
+// abandoned by the caller say nothing about the provider; a call that
+// lost a hedge took at least as long as the winner, which is kept as its
+// latency.
+func (m *member) record(ctx context.Context, elapsed time.Duration, ok bool, err error) {
+	lost := !ok && context.Cause(ctx) == errHedgeLost
+	if !ok && !lost && (errors.Is(err, ErrTokenNotFound) || ctx.Err() != nil) {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+	switch {
+	case lost:
+	case !ok:
+		m.errors++
+		m.errorRate += balancerDecay * (1 - m.errorRate)
+		return
+	default:
+		m.errorRate -= balancerDecay * m.errorRate
+	}
+	if m.latency == 0 {
+		m.latency = elapsed
+	} else {
+		m.latency += time.Duration(balancerDecay * float64(elapsed-m.latency))
+	}
+	if len(m.samples) < balancerSamples {
+		m.samples = append(m.samples, elapsed)
+	} else {
+		m.samples[m.next] = elapsed
+		m.next = (m.next + 1) % balancerSamples
+	}
+}
+
+// p95 returns the 95th percentile of m's recent latencies; m.mu must be
+// held
+func (m *member) p95() time.Duration {
+	if len(m.samples) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), m.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[(len(sorted)*95-1)/100]
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the provider while its
+// circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit open")
+
+// Breaker states
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// BreakerPolicy configures when a breaker opens and how long it stays open
+type BreakerPolicy struct {
+	// Failures is the number of consecutive failed calls that opens the
+	// circuit; 0 disables the breaker
+	Failures int
+
+	// Cooldown is how long the circuit stays open before a single probe
+	// call is let through
+	Cooldown time.Duration
+}
+
+// DefaultBreakerPolicy opens after 5 consecutive failures for 30 seconds
+func DefaultBreakerPolicy() BreakerPolicy {
+	return BreakerPolicy{Failures: 5, Cooldown: 30 * time.Second}
+}
+
+// BreakerStatus is a snapshot of a breaker
+type BreakerStatus struct {
+	Provider string     `json:"provider"`
+	State    string     `json:"state"`
+	Failures int        `json:"failures"`
+	OpenedAt *time.Time `json:"opened_at,omitempty"`
+	Trips    int64      `json:"trips"`
+}
+
+// Breaker wraps a provider with a circuit breaker. After Failures
+// consecutive failed calls it fails fast with ErrCircuitOpen for Cooldown,
+// then lets one probe through: success closes the circuit and failure
+// reopens it.
+type Breaker struct {
+	provider Provider
+	policy   BreakerPolicy
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+	trips    int64
+}
+
+// NewBreaker wraps p with a circuit breaker
+func NewBreaker(p Provider, policy BreakerPolicy) *Breaker {
+	return &Breaker{provider: p, policy: policy}
+}
+
+// Name identifies the wrapped provider
+func (b *Breaker) Name() string {
+	return b.provider.Name()
+}
+
+// FetchPrice fetches a price unless the circuit is open
+func (b *Breaker) FetchPrice(ctx context.Context, tokenID, currency string) (*Price, error) {
+	probe, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+	price, err := b.provider.FetchPrice(ctx, tokenID, currency)
+	b.record(ctx, probe, err, err == nil)
+	return price, err
+}
+
+// FetchPrices fetches prices unless the circuit is open. A call that
+// returns some prices counts as a success.
+func (b *Breaker) FetchPrices(ctx context.Context, tokenIDs []string, currency string) ([]Price, error) {
+	probe, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+	prices, err := b.provider.FetchPrices(ctx, tokenIDs, currency)
+	b.record(ctx, probe, err, err == nil || len(prices) > 0)
+	return prices, err
+}
+
+// Status returns the breaker's current state
+func (b *Breaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := BreakerStatus{
+		Provider: b.provider.Name(),
+		State:    b.state(time.Now()),
+		Failures: b.failures,
+		Trips:    b.trips,
+	}
+	if !b.openedAt.IsZero() {
+		opened := b.openedAt
+		status.OpenedAt = &opened
+	}
+	return status
+}
+
+// state reports the state at now; b.mu must be held
+func (b *Breaker) state(now time.Time) string {
+	switch {
+	case b.openedAt.IsZero():
+		return BreakerClosed
+	case b.probing || now.Sub(b.openedAt) >= b.policy.Cooldown:
+		return BreakerHalfOpen
+	default:
+		return BreakerOpen
+	}
+}
+
+// allow reports whether a call may go through, and whether it is the
+// probe claimed once the cooldown has passed
+func (b *Breaker) allow() (bool, error) {
+	if b.policy.Failures <= 0 {
+		return false, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openedAt.IsZero() {
+		return false, nil
+	}
+	if b.probing || time.Since(b.openedAt) < b.policy.Cooldown {
+		return false, fmt.Errorf("%s: %w", b.provider.Name(), ErrCircuitOpen)
+	}
+	b.probing = true
+	return true, nil
+}
+
+// record counts the outcome of a call. Unknown tokens, rate limiting, a
+// full upstream queue and calls abandoned by the caller say nothing about
+// the provider's health.
+func (b *Breaker) record(ctx context.Context, probe bool, err error, ok bool) {
+	if b.policy.Failures <= 0 {
+		return
+	}
+	neutral := !ok && (errors.Is(err, ErrTokenNotFound) || errors.Is(err, ErrRateLimited) || errors.Is(err, ErrOverloaded) || ctx.Err() != nil)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe {
+		b.probing = false
+	}
+	switch {
+	case ok:
+		if !b.openedAt.IsZero() {
+			log.Printf("Circuit for %s closed", b.provider.Name())
+		}
+		b.failures = 0
+		b.openedAt = time.Time{}
+	case neutral:
+		// A probe that proved nothing lets the next call probe again
+	case probe:
+		b.openedAt = time.Now()
+		log.Printf("Circuit for %s reopened: %v", b.provider.Name(), err)
+	default:
+		b.failures++
+		if b.failures >= b.policy.Failures && b.openedAt.IsZero() {
+			b.openedAt = time.Now()
+			b.trips++
+			log.Printf("Circuit for %s opened after %d failures: %v", b.provider.Name(), b.failures, err)
+		}
+	}
+}
@@ -0,0 +1,651 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package providers implements clients for upstream price sources.
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// CoinGecko API URLs
+	CoinGeckoProURL  = "https://pro-api.coingecko.com/api/v3"
+	CoinGeckoDemoURL = "https://api.coingecko.com/api/v3"
+
+	// MaxIDsPerRequest is the most ids CoinGecko returns from one /coins/markets page
+	MaxIDsPerRequest = 250
+
+	// DefaultConcurrency is the default number of concurrent requests per provider
+	DefaultConcurrency = 4
+)
+
+// CoinGeckoCurrencies are the quote currencies CoinGecko supports directly
+// (its /simple/supported_vs_currencies list)
+var CoinGeckoCurrencies = []string{
+	"btc", "eth", "ltc", "bch", "bnb", "eos", "xrp", "xlm", "link", "dot", "yfi", "sol",
+	"usd", "aed", "ars", "aud", "bdt", "bhd", "bmd", "brl", "cad", "chf", "clp", "cny",
+	"czk", "dkk", "eur", "gbp", "gel", "hkd", "huf", "idr", "ils", "inr", "jpy", "krw",
+	"kwd", "lkr", "mmk", "mxn", "myr", "ngn", "nok", "nzd", "php", "pkr", "pln", "rub",
+	"sar", "sek", "sgd", "thb", "try", "twd", "uah", "vef", "vnd", "zar", "xdr", "xag",
+	"xau", "bits", "sats",
+}
+
+// CoinGecko is a client for the CoinGecko API
+type CoinGecko struct {
+	// BaseURL is the API root, without a trailing slash
+	BaseURL string
+
+	apiKey  string
+	client  *http.Client
+	sem     chan struct{} // bounds concurrent upstream requests
+	schemas *SchemaMonitor
+}
+
+// NewCoinGecko creates a CoinGecko client. Requests use transport, or a
+// default pooled transport if nil.
+func NewCoinGecko(apiKey string, transport http.RoundTripper) *CoinGecko {
+	// Detect API type from key prefix
+	// Pro keys start with "CG-" followed by alphanumeric
+	// Demo keys also start with "CG-" but use demo API
+	// If no key, use demo API
+	baseURL := CoinGeckoDemoURL
+	if apiKey != "" && strings.HasPrefix(apiKey, "CG-") && len(apiKey) > 10 {
+		// Check if it's a pro key by trying pro first
+		// For now, assume demo unless explicitly marked
+		baseURL = CoinGeckoDemoURL
+	}
+
+	if transport == nil {
+		transport = NewTransport(DefaultTransportConfig())
+	}
+
+	return &CoinGecko{
+		BaseURL: baseURL,
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: 30 * time.Second, Transport: transport},
+		sem:     make(chan struct{}, DefaultConcurrency),
+	}
+}
+
+// SetSchemaMonitor counts responses that do not match the expected schema
+// in m
+func (cg *CoinGecko) SetSchemaMonitor(m *SchemaMonitor) {
+	cg.schemas = m
+}
+
+// Name identifies the provider
+func (cg *CoinGecko) Name() string {
+	return "coingecko"
+}
+
+// SetConcurrency sets how many requests may be in flight to the provider
+// at once. It must be called before the client is used.
+func (cg *CoinGecko) SetConcurrency(n int) {
+	if n <= 0 {
+		n = DefaultConcurrency
+	}
+	cg.sem = make(chan struct{}, n)
+}
+
+// SetTimeout bounds each upstream call, on top of any deadline on the
+// caller's context
+func (cg *CoinGecko) SetTimeout(d time.Duration) {
+	cg.client.Timeout = d
+}
+
+// acquire takes an upstream request slot, giving up if ctx is done
+func (cg *CoinGecko) acquire(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case cg.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (cg *CoinGecko) release() {
+	<-cg.sem
+}
+
+// FetchPrice fetches a single price from CoinGecko
+func (cg *CoinGecko) FetchPrice(ctx context.Context, tokenID, currency string) (*Price, error) {
+	prices, err := cg.fetchMarketsPage(ctx, []string{tokenID}, "", currency, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(prices) == 0 {
+		return nil, &NotFoundError{Token: tokenID}
+	}
+
+	return &prices[0], nil
+}
+
+// FetchPrices fetches any number of prices, splitting them into pages
+// fetched by a bounded pool of workers. Prices from pages that succeeded
+// are returned even if other pages failed. Once ctx is done no more pages
+// are started.
+func (cg *CoinGecko) FetchPrices(ctx context.Context, tokenIDs []string, currency string) ([]Price, error) {
+	chunks := chunkIDs(tokenIDs, MaxIDsPerRequest)
+	if len(chunks) == 1 {
+		return cg.fetchMarketsPage(ctx, chunks[0], "", currency, MaxIDsPerRequest)
+	}
+
+	workers := cap(cg.sem)
+	if workers > len(chunks) {
+		workers = len(chunks)
+	}
+
+	jobs := make(chan []string)
+	var (
+		mu     sync.Mutex
+		prices []Price
+		errs   []error
+		wg     sync.WaitGroup
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range jobs {
+				page, err := cg.fetchMarketsPage(ctx, chunk, "", currency, MaxIDsPerRequest)
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, err)
+				} else {
+					prices = append(prices, page...)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for _, chunk := range chunks {
+		select {
+		case jobs <- chunk:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return prices, err
+	}
+	return prices, errors.Join(errs...)
+}
+
+// FetchTopMarkets fetches the largest tokens by market cap, up to
+// MaxIDsPerRequest
+func (cg *CoinGecko) FetchTopMarkets(ctx context.Context, currency string, limit int) ([]Price, error) {
+	if limit <= 0 || limit > MaxIDsPerRequest {
+		limit = MaxIDsPerRequest
+	}
+	return cg.fetchMarketsPage(ctx, nil, "", currency, limit)
+}
+
+// FetchCategory fetches the ids of the largest tokens, up to
+// MaxIDsPerRequest, in a CoinGecko category such as
+// decentralized-finance-defi
+func (cg *CoinGecko) FetchCategory(ctx context.Context, category string) ([]string, error) {
+	prices, err := cg.fetchMarketsPage(ctx, nil, category, "usd", MaxIDsPerRequest)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(prices))
+	for i, p := range prices {
+		ids[i] = p.ID
+	}
+	return ids, nil
+}
+
+// fetchMarketsPage fetches one page of /coins/markets for the given ids,
+// or the top tokens by market cap if ids is empty, limited to a category
+// if set
+func (cg *CoinGecko) fetchMarketsPage(ctx context.Context, tokenIDs []string, category, currency string, perPage int) ([]Price, error) {
+	if err := cg.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer cg.release()
+
+	url := fmt.Sprintf("%s/coins/markets?vs_currency=%s&order=market_cap_desc&per_page=%d&page=1&sparkline=false&price_change_percentage=7d",
+		cg.BaseURL, currency, perPage)
+	if len(tokenIDs) > 0 {
+		url += "&ids=" + strings.Join(tokenIDs, ",")
+	}
+	if category != "" {
+		url += "&category=" + category
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("x-cg-demo-api-key", cg.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := cg.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("CoinGecko API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	prices, err := cg.schemas.decodeMarkets(resp.Body, cg.Name(), coinMarketSchema)
+	if err != nil {
+		return nil, err
+	}
+	for i := range prices {
+		prices[i].Source = cg.Name()
+	}
+
+	return prices, nil
+}
+
+// TokenPrice is a token contract's price from CoinGecko's
+// /simple/token_price
+type TokenPrice struct {
+	Price     float64
+	MarketCap float64
+	Volume24h float64
+	Change24h float64 // percent
+	UpdatedAt time.Time
+}
+
+// FetchTokenPrice fetches the price of a token contract on a CoinGecko
+// asset platform, such as ethereum or binance-smart-chain. Contracts
+// CoinGecko doesn't list return ErrTokenNotFound.
+func (cg *CoinGecko) FetchTokenPrice(ctx context.Context, platform, contract, currency string) (*TokenPrice, error) {
+	if err := cg.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer cg.release()
+
+	u := fmt.Sprintf("%s/simple/token_price/%s?contract_addresses=%s&vs_currencies=%s&include_market_cap=true&include_24hr_vol=true&include_24hr_change=true&include_last_updated_at=true",
+		cg.BaseURL, url.PathEscape(platform), url.QueryEscape(contract), url.QueryEscape(currency))
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("x-cg-demo-api-key", cg.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := cg.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("CoinGecko API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	// Keyed by contract, lowercased for EVM chains
+	var doc map[string]map[string]float64
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, err
+	}
+	for addr, fields := range doc {
+		price, ok := fields[currency]
+		if !strings.EqualFold(addr, contract) || !ok {
+			continue
+		}
+		return &TokenPrice{
+			Price:     price,
+			MarketCap: fields[currency+"_market_cap"],
+			Volume24h: fields[currency+"_24h_vol"],
+			Change24h: fields[currency+"_24h_change"],
+			UpdatedAt: time.Unix(int64(fields["last_updated_at"]), 0).UTC(),
+		}, nil
+	}
+	return nil, &NotFoundError{Token: platform + ":" + contract}
+}
+
+// Derivative is a derivatives ticker from CoinGecko's /derivatives,
+// covering perpetuals and futures on the venues it tracks
+type Derivative struct {
+	Market       string      `json:"market"`
+	Symbol       string      `json:"symbol"`
+	IndexID      string      `json:"index_id"`
+	Price        json.Number `json:"price"`
+	ContractType string      `json:"contract_type"`
+	Index        float64     `json:"index"`
+	Basis        float64     `json:"basis"`
+	FundingRate  float64     `json:"funding_rate"`  // percent per funding interval
+	OpenInterest float64     `json:"open_interest"` // USD
+	Volume24h    float64     `json:"volume_24h"`    // USD
+	LastTradedAt int64       `json:"last_traded_at"`
+}
+
+// FetchDerivatives fetches every derivatives ticker CoinGecko tracks
+func (cg *CoinGecko) FetchDerivatives(ctx context.Context) ([]Derivative, error) {
+	if err := cg.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer cg.release()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", cg.BaseURL+"/derivatives", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("x-cg-demo-api-key", cg.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := cg.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("CoinGecko API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var tickers []Derivative
+	if err := json.NewDecoder(resp.Body).Decode(&tickers); err != nil {
+		return nil, err
+	}
+	return tickers, nil
+}
+
+// NFTCollection is a collection from CoinGecko's /nfts/{id}. Amounts are
+// keyed by "native_currency" and "usd".
+type NFTCollection struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	Symbol               string             `json:"symbol"`
+	AssetPlatformID      string             `json:"asset_platform_id"`
+	ContractAddress      string             `json:"contract_address"`
+	NativeCurrencySymbol string             `json:"native_currency_symbol"`
+	FloorPrice           map[string]float64 `json:"floor_price"`
+	Volume24h            map[string]float64 `json:"volume_24h"`
+	FloorPriceChange24h  float64            `json:"floor_price_in_usd_24h_percentage_change"`
+	Owners               int64              `json:"number_of_unique_addresses"`
+	TotalSupply          float64            `json:"total_supply"`
+}
+
+// ErrNFTNotFound is returned for unknown NFT collections
+var ErrNFTNotFound = errors.New("collection not found")
+
+// FetchNFT fetches an NFT collection's floor price and activity
+func (cg *CoinGecko) FetchNFT(ctx context.Context, collectionID string) (*NFTCollection, error) {
+	if err := cg.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer cg.release()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", cg.BaseURL+"/nfts/"+url.PathEscape(collectionID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("x-cg-demo-api-key", cg.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := cg.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNFTNotFound, collectionID)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("CoinGecko API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var nft NFTCollection
+	if err := json.NewDecoder(resp.Body).Decode(&nft); err != nil {
+		return nil, err
+	}
+	return &nft, nil
+}
+
+// Global is CoinGecko's /global market overview. Totals are keyed by
+// quote currency and dominance by ticker symbol.
+type Global struct {
+	ActiveCryptocurrencies int                `json:"active_cryptocurrencies"`
+	Markets                int                `json:"markets"`
+	TotalMarketCap         map[string]float64 `json:"total_market_cap"`
+	TotalVolume            map[string]float64 `json:"total_volume"`
+	MarketCapPercentage    map[string]float64 `json:"market_cap_percentage"`
+	MarketCapChange24h     float64            `json:"market_cap_change_percentage_24h_usd"`
+	UpdatedAt              int64              `json:"updated_at"`
+}
+
+// FetchGlobal fetches total market cap, volume and dominance across all
+// tokens CoinGecko tracks
+func (cg *CoinGecko) FetchGlobal(ctx context.Context) (*Global, error) {
+	if err := cg.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer cg.release()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", cg.BaseURL+"/global", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("x-cg-demo-api-key", cg.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := cg.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("CoinGecko API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var global struct {
+		Data Global `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&global); err != nil {
+		return nil, err
+	}
+	return &global.Data, nil
+}
+
+// TrendingCoin is an entry of CoinGecko's /search/trending, ranked by
+// search activity over the last 24 hours
+type TrendingCoin struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Symbol        string `json:"symbol"`
+	MarketCapRank int    `json:"market_cap_rank"`
+	Thumb         string `json:"thumb"`
+	Score         int    `json:"score"` // position in the list, 0 first
+	Data          struct {
+		Price                    float64            `json:"price"` // USD
+		PriceChangePercentage24h map[string]float64 `json:"price_change_percentage_24h"`
+	} `json:"data"`
+}
+
+// FetchTrending fetches the coins trending on CoinGecko
+func (cg *CoinGecko) FetchTrending(ctx context.Context) ([]TrendingCoin, error) {
+	if err := cg.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer cg.release()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", cg.BaseURL+"/search/trending", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("x-cg-demo-api-key", cg.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := cg.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("CoinGecko API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var trending struct {
+		Coins []struct {
+			Item TrendingCoin `json:"item"`
+		} `json:"coins"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&trending); err != nil {
+		return nil, err
+	}
+	coins := make([]TrendingCoin, len(trending.Coins))
+	for i, c := range trending.Coins {
+		coins[i] = c.Item
+	}
+	return coins, nil
+}
+
+// ListedCoin is an entry of CoinGecko's /coins/list
+type ListedCoin struct {
+	ID     string `json:"id"`
+	Symbol string `json:"symbol"`
+	Name   string `json:"name"`
+}
+
+// FetchCoinList fetches every coin CoinGecko lists
+func (cg *CoinGecko) FetchCoinList(ctx context.Context) ([]ListedCoin, error) {
+	if err := cg.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer cg.release()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", cg.BaseURL+"/coins/list", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("x-cg-demo-api-key", cg.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := cg.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("CoinGecko API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var coins []ListedCoin
+	if err := json.NewDecoder(resp.Body).Decode(&coins); err != nil {
+		return nil, err
+	}
+	return coins, nil
+}
+
+// MarketChart is a token's history from CoinGecko's
+// /coins/{id}/market_chart as [unix milliseconds, value] pairs. Points are
+// 5-minutely for 1 day, hourly up to 90 days and daily beyond.
+type MarketChart struct {
+	Prices       [][2]float64 `json:"prices"`
+	MarketCaps   [][2]float64 `json:"market_caps"`
+	TotalVolumes [][2]float64 `json:"total_volumes"`
+}
+
+// ErrTokenNotFound is returned for tokens the provider doesn't know
+var ErrTokenNotFound = errors.New("token not found")
+
+// NotFoundError reports a token the provider doesn't know
+type NotFoundError struct {
+	Token string
+}
+
+func (e *NotFoundError) Error() string {
+	return "token not found: " + e.Token
+}
+
+// Is matches ErrTokenNotFound
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrTokenNotFound
+}
+
+// FetchMarketChart fetches a token's price, market cap and volume over the
+// last days
+func (cg *CoinGecko) FetchMarketChart(ctx context.Context, tokenID, currency string, days int) (*MarketChart, error) {
+	if err := cg.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer cg.release()
+
+	u := fmt.Sprintf("%s/coins/%s/market_chart?vs_currency=%s&days=%d",
+		cg.BaseURL, url.PathEscape(tokenID), url.QueryEscape(currency), days)
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("x-cg-demo-api-key", cg.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := cg.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &NotFoundError{Token: tokenID}
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("CoinGecko API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var chart MarketChart
+	if err := json.NewDecoder(resp.Body).Decode(&chart); err != nil {
+		return nil, err
+	}
+	return &chart, nil
+}
+
+// chunkIDs splits ids into slices of at most size elements
+func chunkIDs(ids []string, size int) [][]string {
+	var chunks [][]string
+	for len(ids) > size {
+		chunks = append(chunks, ids[:size])
+		ids = ids[size:]
+	}
+	if len(ids) > 0 {
+		chunks = append(chunks, ids)
+	}
+	return chunks
+}
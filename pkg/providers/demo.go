@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package providers
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// demoStep is the finest scale demo prices move at
+	demoStep = time.Minute
+
+	// demoOctaves is the number of scales demo prices move at, doubling
+	// from demoStep up to about a year
+	demoOctaves = 20
+
+	// demoPegOctaves bounds the scales of pegged tokens to a few hours, so
+	// they wobble around their peg instead of drifting off it
+	demoPegOctaves = 8
+)
+
+// NewDemoHandler serves the mock CoinGecko with prices that move as
+// seeded random walks, for frontends to develop against moving data
+// offline. The walks are a function of seed, token and time alone, so
+// every instance with the same seed serves the same prices and
+// histories. tokens is the universe listed by /coins/markets and
+// /coins/list; the mock's fixture tokens if empty.
+func NewDemoHandler(seed int64, tokens []string) http.Handler {
+	m := &mockMarket{tokens: mockTokens, walk: &demoWalk{seed: seed}}
+	if len(tokens) > 0 {
+		m.tokens = make([]mockToken, 0, len(tokens))
+		for _, id := range tokens {
+			m.tokens = append(m.tokens, lookupMockToken(strings.ToLower(id)))
+		}
+	}
+	return m.handler()
+}
+
+// demoWalk moves prices as a sum of value noise at scales doubling from
+// demoStep, each weighted by the square root of its scale. Like a random
+// walk, moves grow with the square root of time, but the price at any
+// time is computed directly rather than by stepping from a start.
+type demoWalk struct {
+	seed int64
+}
+
+// price is a token's USD price at t. Its log moves with the token's
+// daily volatility around the fixture price.
+func (w *demoWalk) price(tok mockToken, t time.Time) float64 {
+	octaves := demoOctaves
+	if tok.vol < 0.001 {
+		octaves = demoPegOctaves
+	}
+	perStep := tok.vol / math.Sqrt(float64(24*time.Hour/demoStep))
+	x := float64(t.UnixNano()) / float64(demoStep)
+	var sum float64
+	scale := 1.0
+	for k := 0; k < octaves; k++ {
+		sum += math.Sqrt(scale) * w.noise(tok.id, k, x/scale)
+		scale *= 2
+	}
+	return tok.price * math.Exp(perStep*sum)
+}
+
+// noise is smooth value noise in [-1, 1] at x for a token and octave
+func (w *demoWalk) noise(id string, octave int, x float64) float64 {
+	i := math.Floor(x)
+	f := x - i
+	f = f * f * (3 - 2*f)
+	a, b := w.lattice(id, octave, int64(i)), w.lattice(id, octave, int64(i)+1)
+	return a + (b-a)*f
+}
+
+// lattice is the seeded random value in [-1, 1] at point i of a token's
+// octave
+func (w *demoWalk) lattice(id string, octave int, i int64) float64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(w.seed))
+	h.Write(buf[:])
+	h.Write([]byte(id))
+	binary.LittleEndian.PutUint64(buf[:], uint64(octave))
+	h.Write(buf[:])
+	binary.LittleEndian.PutUint64(buf[:], uint64(i))
+	h.Write(buf[:])
+	return float64(h.Sum64()>>11)/float64(1<<52) - 1
+}
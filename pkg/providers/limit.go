@@ -0,0 +1,130 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package providers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrOverloaded is returned without calling a provider when every upstream
+// request slot is taken and the queue for them is full
+var ErrOverloaded = errors.New("too many upstream requests queued")
+
+// LimitStatus is a snapshot of upstream request concurrency
+type LimitStatus struct {
+	MaxInFlight int   `json:"max_in_flight"`
+	MaxQueued   int   `json:"max_queued"`
+	InFlight    int   `json:"in_flight"`
+	Queued      int   `json:"queued"`
+	Rejected    int64 `json:"rejected"`  // refused with a full queue
+	Abandoned   int64 `json:"abandoned"` // left the queue because the caller gave up
+}
+
+// LimitTransport caps the requests in flight to all upstream hosts
+// together. Requests over the cap wait in a queue of at most maxQueued;
+// one whose context is done leaves the queue at once, so requests
+// abandoned by clients never reach the provider. A slot is held until the
+// response body is closed.
+type LimitTransport struct {
+	next      http.RoundTripper
+	slots     chan struct{}
+	maxQueued int64
+
+	queued    atomic.Int64
+	rejected  atomic.Int64
+	abandoned atomic.Int64
+}
+
+// NewLimitTransport wraps next, allowing maxInFlight requests at once and
+// queueing up to maxQueued more
+func NewLimitTransport(next http.RoundTripper, maxInFlight, maxQueued int) *LimitTransport {
+	return &LimitTransport{
+		next:      next,
+		slots:     make(chan struct{}, max(1, maxInFlight)),
+		maxQueued: int64(maxQueued),
+	}
+}
+
+// RoundTrip sends req once a slot is free
+func (t *LimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.acquire(req); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.release()
+		return nil, err
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: t.release}
+	return resp, nil
+}
+
+// acquire takes a slot, queueing for one if none is free
+func (t *LimitTransport) acquire(req *http.Request) error {
+	ctx := req.Context()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case t.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if t.queued.Add(1) > t.maxQueued {
+		t.queued.Add(-1)
+		t.rejected.Add(1)
+		return ErrOverloaded
+	}
+	defer t.queued.Add(-1)
+	select {
+	case t.slots <- struct{}{}:
+		// The caller may have given up just as the slot came free
+		if err := ctx.Err(); err != nil {
+			t.release()
+			t.abandoned.Add(1)
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		t.abandoned.Add(1)
+		return ctx.Err()
+	}
+}
+
+func (t *LimitTransport) release() {
+	<-t.slots
+}
+
+// Status returns the current concurrency and queue counters
+func (t *LimitTransport) Status() LimitStatus {
+	return LimitStatus{
+		MaxInFlight: cap(t.slots),
+		MaxQueued:   int(t.maxQueued),
+		InFlight:    len(t.slots),
+		Queued:      int(t.queued.Load()),
+		Rejected:    t.rejected.Load(),
+		Abandoned:   t.abandoned.Load(),
+	}
+}
+
+// releaseBody frees a request slot when the response body is closed
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
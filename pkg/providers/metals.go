@@ -0,0 +1,172 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+)
+
+// MetalsAPIURL is the metals-api.com API root
+const MetalsAPIURL = "https://metals-api.com/api"
+
+// Commodity is a metal or commodity quoted by the metals provider
+type Commodity struct {
+	Symbol string // metals-api symbol
+	Name   string
+	Unit   string
+}
+
+// Commodities maps token ids to the commodities they quote. Metals are
+// priced per troy ounce and oil per barrel.
+var Commodities = map[string]Commodity{
+	"xau":   {Symbol: "XAU", Name: "Gold", Unit: "troy ounce"},
+	"xag":   {Symbol: "XAG", Name: "Silver", Unit: "troy ounce"},
+	"xpt":   {Symbol: "XPT", Name: "Platinum", Unit: "troy ounce"},
+	"xpd":   {Symbol: "XPD", Name: "Palladium", Unit: "troy ounce"},
+	"wti":   {Symbol: "WTIOIL", Name: "WTI Crude Oil", Unit: "barrel"},
+	"brent": {Symbol: "BRENTOIL", Name: "Brent Crude Oil", Unit: "barrel"},
+}
+
+// CommodityIDs returns the token ids of all supported commodities
+func CommodityIDs() []string {
+	ids := make([]string, 0, len(Commodities))
+	for id := range Commodities {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Metals is a client for metals-api.com, quoting precious metals and oil
+// in the same shape as crypto prices
+type Metals struct {
+	// BaseURL is the API root, without a trailing slash
+	BaseURL string
+
+	apiKey string
+	client *http.Client
+}
+
+// NewMetals creates a metals-api.com client. Requests use transport, or a
+// default pooled transport if nil.
+func NewMetals(apiKey string, transport http.RoundTripper) *Metals {
+	if transport == nil {
+		transport = NewTransport(DefaultTransportConfig())
+	}
+	return &Metals{
+		BaseURL: MetalsAPIURL,
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: 30 * time.Second, Transport: transport},
+	}
+}
+
+// SetTimeout bounds each upstream call, on top of any deadline on the
+// caller's context
+func (m *Metals) SetTimeout(d time.Duration) {
+	m.client.Timeout = d
+}
+
+// Name identifies the provider
+func (m *Metals) Name() string {
+	return "metals"
+}
+
+// FetchPrice fetches a single commodity price
+func (m *Metals) FetchPrice(ctx context.Context, tokenID, currency string) (*Price, error) {
+	prices, err := m.FetchPrices(ctx, []string{tokenID}, currency)
+	if err != nil {
+		return nil, err
+	}
+	if len(prices) == 0 {
+		return nil, &NotFoundError{Token: tokenID}
+	}
+	return &prices[0], nil
+}
+
+// FetchPrices fetches commodity prices in one request. Unknown ids are
+// omitted.
+func (m *Metals) FetchPrices(ctx context.Context, tokenIDs []string, currency string) ([]Price, error) {
+	var symbols []string
+	for _, id := range tokenIDs {
+		if c, ok := Commodities[id]; ok {
+			symbols = append(symbols, c.Symbol)
+		}
+	}
+	if len(symbols) == 0 {
+		return nil, nil
+	}
+
+	q := url.Values{}
+	q.Set("access_key", m.apiKey)
+	q.Set("base", strings.ToUpper(currency))
+	q.Set("symbols", strings.Join(symbols, ","))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", m.BaseURL+"/latest?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return nil, fmt.Errorf("metals API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	// Rates are units of metal per unit of base currency
+	var doc struct {
+		Success   bool               `json:"success"`
+		Timestamp int64              `json:"timestamp"`
+		Rates     map[string]float64 `json:"rates"`
+		Error     json.RawMessage    `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if !doc.Success {
+		return nil, fmt.Errorf("metals API error: %s", string(doc.Error))
+	}
+
+	updated := time.Now().UTC()
+	if doc.Timestamp > 0 {
+		updated = time.Unix(doc.Timestamp, 0).UTC()
+	}
+
+	var prices []Price
+	for _, id := range tokenIDs {
+		c, ok := Commodities[id]
+		if !ok {
+			continue
+		}
+		rate := doc.Rates[c.Symbol]
+		if rate <= 0 {
+			continue
+		}
+		// Rates are units per currency unit; the price is their inverse
+		price := decimal.FromFloat(1).Quo(decimal.FromFloat(rate))
+		prices = append(prices, Price{
+			ID:               id,
+			Symbol:           id,
+			Name:             c.Name,
+			CurrentPrice:     price.Float64(),
+			CurrentPriceText: price.String(),
+			LastUpdated:      updated.Format(time.RFC3339),
+			Source:           m.Name(),
+		})
+	}
+	return prices, nil
+}
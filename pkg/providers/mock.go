@@ -0,0 +1,464 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package providers
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MockHost is the host the mock CoinGecko is served on by
+	// NewMockTransport
+	MockHost = "coingecko.mock"
+
+	// MockURL is the API root of the mock CoinGecko
+	MockURL = "http://" + MockHost + "/api/v3"
+)
+
+// mockToken is a fixture token of the mock CoinGecko, priced in USD
+type mockToken struct {
+	id, symbol, name string
+	price, marketCap float64
+	vol              float64 // daily volatility of demo prices
+}
+
+// mockTokens are the fixture tokens, largest market cap first. Other ids
+// are priced from a hash of the id, so every id is known.
+var mockTokens = []mockToken{
+	{"bitcoin", "btc", "Bitcoin", 65000, 1.28e12, 0.025},
+	{"ethereum", "eth", "Ethereum", 3200, 3.85e11, 0.03},
+	{"tether", "usdt", "Tether", 1, 1.1e11, 0.0005},
+	{"binancecoin", "bnb", "BNB", 580, 8.5e10, 0.03},
+	{"solana", "sol", "Solana", 150, 6.9e10, 0.045},
+	{"usd-coin", "usdc", "USDC", 1, 3.4e10, 0.0005},
+	{"ripple", "xrp", "XRP", 0.52, 2.9e10, 0.04},
+	{"dogecoin", "doge", "Dogecoin", 0.12, 1.7e10, 0.06},
+	{"cardano", "ada", "Cardano", 0.45, 1.6e10, 0.04},
+	{"dai", "dai", "Dai", 1, 5.3e9, 0.0005},
+	{"lux-network", "lux", "Lux Network", 2.5, 2.5e9, 0.05},
+}
+
+// mockRates converts USD fixture prices to other quote currencies;
+// currencies not listed quote at par
+var mockRates = map[string]float64{
+	"usd": 1, "eur": 0.92, "gbp": 0.79, "jpy": 150, "chf": 0.88, "cad": 1.36,
+	"aud": 1.52, "cny": 7.2, "krw": 1350, "inr": 83,
+	"btc": 1.0 / 65000, "eth": 1.0 / 3200, "sats": 1e8 / 65000,
+}
+
+// mockNFTs are the fixture NFT collections, floor prices in ETH
+var mockNFTs = map[string]NFTCollection{
+	"bored-ape-yacht-club": {ID: "bored-ape-yacht-club", Name: "Bored Ape Yacht Club", Symbol: "BAYC", AssetPlatformID: "ethereum", ContractAddress: "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d", NativeCurrencySymbol: "eth", Owners: 5500, TotalSupply: 10000},
+	"pudgy-penguins":       {ID: "pudgy-penguins", Name: "Pudgy Penguins", Symbol: "PPG", AssetPlatformID: "ethereum", ContractAddress: "0xbd3531da5cf5857e7cfaa92426877b022e612cf8", NativeCurrencySymbol: "eth", Owners: 4700, TotalSupply: 8888},
+}
+
+// mockCategories are the tokens of the fixture categories; tokens outside
+// the fixtures are in none
+var mockCategories = map[string][]string{
+	"layer-1":                    {"bitcoin", "ethereum", "binancecoin", "solana", "ripple", "cardano", "lux-network"},
+	"smart-contract-platform":    {"ethereum", "binancecoin", "solana", "cardano", "lux-network"},
+	"decentralized-finance-defi": {"dai"},
+	"stablecoins":                {"tether", "usd-coin", "dai"},
+	"meme-token":                 {"dogecoin"},
+	"artificial-intelligence":    {},
+}
+
+// mockContracts maps asset platforms to the fixture tokens' contracts,
+// lowercased
+var mockContracts = map[string]map[string]string{
+	"ethereum": {
+		"0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2": "ethereum",
+		"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48": "usd-coin",
+		"0xdac17f958d2ee523a2206206994597c13d831ec7": "tether",
+		"0x6b175474e89094c44da98b954eedeac495271d0f": "dai",
+	},
+}
+
+// mockEpoch is the time chart prices oscillate from
+var mockEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// mockMarket is the data behind the mock CoinGecko: a universe of tokens
+// and how their prices move
+type mockMarket struct {
+	tokens []mockToken
+	walk   *demoWalk // moves prices if set; the mock's are fixed
+}
+
+// NewMockHandler serves deterministic fixture data in the shape of the
+// CoinGecko endpoints the providers call, so the service runs without an
+// API key or network. Prices are fixed; histories oscillate around them.
+func NewMockHandler() http.Handler {
+	return (&mockMarket{tokens: mockTokens}).handler()
+}
+
+// handler serves the market on the CoinGecko paths under /api/v3
+func (m *mockMarket) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/coins/markets", m.serveMarkets)
+	mux.HandleFunc("/api/v3/coins/list", m.serveCoinList)
+	mux.HandleFunc("/api/v3/coins/", m.serveMarketChart)
+	mux.HandleFunc("/api/v3/global", m.serveGlobal)
+	mux.HandleFunc("/api/v3/search/trending", m.serveTrending)
+	mux.HandleFunc("/api/v3/derivatives", m.serveDerivatives)
+	mux.HandleFunc("/api/v3/nfts/", m.serveNFT)
+	mux.HandleFunc("/api/v3/simple/token_price/", m.serveTokenPrice)
+	return mux
+}
+
+// mockTransport serves requests to MockHost from a handler in process
+type mockTransport struct {
+	handler http.Handler
+	next    http.RoundTripper
+}
+
+// NewMockTransport wraps next, answering requests to MockHost with
+// handler, such as NewMockHandler, and passing any others on
+func NewMockTransport(next http.RoundTripper, handler http.Handler) http.RoundTripper {
+	return &mockTransport{handler: handler, next: next}
+}
+
+// RoundTrip serves req from the mock if it is for MockHost
+func (t *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != MockHost {
+		return t.next.RoundTrip(req)
+	}
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	rec := httptest.NewRecorder()
+	t.handler.ServeHTTP(rec, req)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// lookupMockToken returns the fixture token with id, or one priced from
+// its hash
+func lookupMockToken(id string) mockToken {
+	for _, t := range mockTokens {
+		if t.id == id {
+			return t
+		}
+	}
+	h := mockHash(id)
+	price := float64(h%1000000) / 100
+	symbol := id
+	if len(symbol) > 4 {
+		symbol = symbol[:4]
+	}
+	return mockToken{id: id, symbol: symbol, name: id, price: price, marketCap: price * 1e7, vol: 0.06}
+}
+
+// mockHash is a stable hash of s
+func mockHash(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
+
+// mockRate returns the rate of a quote currency to USD
+func mockRate(currency string) float64 {
+	if r, ok := mockRates[strings.ToLower(currency)]; ok {
+		return r
+	}
+	return 1
+}
+
+// spot is a token's USD price now
+func (m *mockMarket) spot(t mockToken, now time.Time) float64 {
+	if m.walk == nil {
+		return t.price
+	}
+	return m.walk.price(t, now)
+}
+
+// priceAt is a token's USD price at a past time. Without a walk it is
+// within 5% of the fixture price, cycling every 30 days.
+func (m *mockMarket) priceAt(t mockToken, at time.Time) float64 {
+	if m.walk != nil {
+		return m.walk.price(t, at)
+	}
+	phase := float64(mockHash(t.id)%360) * math.Pi / 180
+	cycle := at.Sub(mockEpoch).Hours() / (30 * 24)
+	return t.price * (1 + 0.05*math.Sin(2*math.Pi*cycle+phase))
+}
+
+// change is a token's percent change over the period before now
+func (m *mockMarket) change(t mockToken, now time.Time, period time.Duration) float64 {
+	if m.walk == nil {
+		h := mockHash(t.id)
+		if period > 24*time.Hour {
+			return float64(h/2000%4000)/100 - 20
+		}
+		return float64(h%2000)/100 - 10
+	}
+	return (m.spot(t, now)/m.priceAt(t, now.Add(-period)) - 1) * 100
+}
+
+// supply is a token's circulating supply, fixed by its fixture price and
+// market cap
+func (t mockToken) supply() float64 {
+	return t.marketCap / t.price
+}
+
+// quote returns the token as a /coins/markets entry in currency
+func (m *mockMarket) quote(t mockToken, currency string, now time.Time) Price {
+	rate := mockRate(currency)
+	price := m.spot(t, now)
+	return Price{
+		ID:                       t.id,
+		Symbol:                   t.symbol,
+		Name:                     t.name,
+		CurrentPrice:             price * rate,
+		MarketCap:                price * t.supply() * rate,
+		TotalVolume:              price * t.supply() * rate / 50,
+		PriceChangePercentage24h: m.change(t, now, 24*time.Hour),
+		PriceChangePercentage7d:  m.change(t, now, 7*24*time.Hour),
+		CirculatingSupply:        t.supply(),
+		TotalSupply:              t.supply(),
+		LastUpdated:              now.Format(time.RFC3339),
+	}
+}
+
+// token returns the token of the universe with id, or lookupMockToken's
+func (m *mockMarket) token(id string) mockToken {
+	for _, t := range m.tokens {
+		if t.id == id {
+			return t
+		}
+	}
+	return lookupMockToken(id)
+}
+
+// top returns the universe by market cap now, largest first
+func (m *mockMarket) top(now time.Time) []mockToken {
+	tokens := append([]mockToken(nil), m.tokens...)
+	m.sortByMarketCap(tokens, now)
+	return tokens
+}
+
+// sortByMarketCap sorts tokens by market cap now, largest first
+func (m *mockMarket) sortByMarketCap(tokens []mockToken, now time.Time) {
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return m.spot(tokens[i], now)*tokens[i].supply() > m.spot(tokens[j], now)*tokens[j].supply()
+	})
+}
+
+// mockJSON writes v as a JSON response
+func mockJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func (m *mockMarket) serveMarkets(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	currency := q.Get("vs_currency")
+	if currency == "" {
+		http.Error(w, `{"error":"Missing parameter vs_currency"}`, http.StatusBadRequest)
+		return
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	var tokens []mockToken
+	if ids := q.Get("ids"); ids != "" {
+		for _, id := range strings.Split(ids, ",") {
+			if id != "" {
+				tokens = append(tokens, m.token(id))
+			}
+		}
+		m.sortByMarketCap(tokens, now)
+	} else {
+		tokens = m.top(now)
+	}
+	if category := q.Get("category"); category != "" {
+		members, ok := mockCategories[category]
+		if !ok {
+			http.Error(w, `{"error":"category not found"}`, http.StatusNotFound)
+			return
+		}
+		in := tokens[:0]
+		for _, t := range tokens {
+			if slices.Contains(members, t.id) {
+				in = append(in, t)
+			}
+		}
+		tokens = in
+	}
+	if n, err := strconv.Atoi(q.Get("per_page")); err == nil && n > 0 && n < len(tokens) {
+		tokens = tokens[:n]
+	}
+
+	prices := make([]Price, len(tokens))
+	for i, t := range tokens {
+		prices[i] = m.quote(t, currency, now)
+	}
+	mockJSON(w, prices)
+}
+
+func (m *mockMarket) serveCoinList(w http.ResponseWriter, r *http.Request) {
+	coins := make([]ListedCoin, len(m.tokens))
+	for i, t := range m.tokens {
+		coins[i] = ListedCoin{ID: t.id, Symbol: t.symbol, Name: t.name}
+	}
+	mockJSON(w, coins)
+}
+
+// serveMarketChart serves /coins/{id}/market_chart with CoinGecko's
+// granularity: 5-minutely for a day, hourly up to 90 days, daily beyond
+func (m *mockMarket) serveMarketChart(w http.ResponseWriter, r *http.Request) {
+	id, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/v3/coins/"), "/market_chart")
+	if !ok || id == "" || strings.Contains(id, "/") {
+		http.Error(w, `{"error":"Not found"}`, http.StatusNotFound)
+		return
+	}
+	q := r.URL.Query()
+	days, err := strconv.Atoi(q.Get("days"))
+	if err != nil || days < 1 {
+		http.Error(w, `{"error":"Invalid days"}`, http.StatusBadRequest)
+		return
+	}
+	step := 24 * time.Hour
+	switch {
+	case days == 1:
+		step = 5 * time.Minute
+	case days <= 90:
+		step = time.Hour
+	}
+
+	t := m.token(id)
+	rate := mockRate(q.Get("vs_currency"))
+	now := time.Now().UTC()
+	var chart MarketChart
+	for at := now.Add(-time.Duration(days) * 24 * time.Hour).Truncate(step).Add(step); !at.After(now); at = at.Add(step) {
+		ms := float64(at.UnixMilli())
+		price := m.priceAt(t, at) * rate
+		chart.Prices = append(chart.Prices, [2]float64{ms, price})
+		chart.MarketCaps = append(chart.MarketCaps, [2]float64{ms, price * t.supply()})
+		chart.TotalVolumes = append(chart.TotalVolumes, [2]float64{ms, price * t.supply() / 50})
+	}
+	mockJSON(w, chart)
+}
+
+func (m *mockMarket) serveGlobal(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	var total, before float64
+	for _, t := range m.tokens {
+		total += m.spot(t, now) * t.supply()
+		before += m.priceAt(t, now.Add(-24*time.Hour)) * t.supply()
+	}
+	g := Global{
+		ActiveCryptocurrencies: len(m.tokens),
+		Markets:                len(m.tokens) * 10,
+		TotalMarketCap:         make(map[string]float64),
+		TotalVolume:            make(map[string]float64),
+		MarketCapPercentage:    make(map[string]float64),
+		MarketCapChange24h:     1.5,
+		UpdatedAt:              now.Unix(),
+	}
+	if m.walk != nil && before > 0 {
+		g.MarketCapChange24h = (total/before - 1) * 100
+	}
+	for currency, rate := range mockRates {
+		g.TotalMarketCap[currency] = total * rate
+		g.TotalVolume[currency] = total * rate / 50
+	}
+	for _, t := range m.tokens {
+		g.MarketCapPercentage[t.symbol] = m.spot(t, now) * t.supply() / total * 100
+	}
+	mockJSON(w, struct {
+		Data Global `json:"data"`
+	}{g})
+}
+
+// serveTrending serves the last seven tokens of the universe by market
+// cap as trending
+func (m *mockMarket) serveTrending(w http.ResponseWriter, r *http.Request) {
+	type item struct {
+		Item TrendingCoin `json:"item"`
+	}
+	now := time.Now().UTC()
+	top := m.top(now)
+	first := max(0, len(top)-7)
+	var coins []item
+	for i, t := range top[first:] {
+		c := TrendingCoin{ID: t.id, Name: t.name, Symbol: strings.ToUpper(t.symbol), MarketCapRank: first + i + 1, Score: i}
+		c.Data.Price = m.spot(t, now)
+		c.Data.PriceChangePercentage24h = map[string]float64{"usd": m.change(t, now, 24*time.Hour)}
+		coins = append(coins, item{c})
+	}
+	mockJSON(w, struct {
+		Coins []item `json:"coins"`
+	}{coins})
+}
+
+func (m *mockMarket) serveDerivatives(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	var tickers []Derivative
+	for _, t := range m.top(now)[:min(2, len(m.tokens))] {
+		price := m.spot(t, now)
+		for i, market := range []string{"Binance (Futures)", "Bybit (Futures)"} {
+			tickers = append(tickers, Derivative{
+				Market:       market,
+				Symbol:       strings.ToUpper(t.symbol) + "USDT",
+				IndexID:      strings.ToUpper(t.symbol),
+				Price:        json.Number(strconv.FormatFloat(price*(1+0.0005*float64(i+1)), 'f', -1, 64)),
+				ContractType: "perpetual",
+				Index:        price,
+				Basis:        0.05 * float64(i+1),
+				FundingRate:  0.01 * float64(i+1),
+				OpenInterest: price * t.supply() / 100,
+				Volume24h:    price * t.supply() / 50,
+				LastTradedAt: now.Unix(),
+			})
+		}
+	}
+	mockJSON(w, tickers)
+}
+
+// serveTokenPrice serves /simple/token_price/{platform} for the fixture
+// contracts; others are left out of the response, as CoinGecko does
+func (m *mockMarket) serveTokenPrice(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	contracts := mockContracts[strings.TrimPrefix(r.URL.Path, "/api/v3/simple/token_price/")]
+	now := time.Now().UTC().Truncate(time.Second)
+	out := make(map[string]map[string]float64)
+	for _, addr := range strings.Split(strings.ToLower(q.Get("contract_addresses")), ",") {
+		id, ok := contracts[addr]
+		if !ok {
+			continue
+		}
+		fields := map[string]float64{"last_updated_at": float64(now.Unix())}
+		for _, currency := range strings.Split(q.Get("vs_currencies"), ",") {
+			p := m.quote(m.token(id), currency, now)
+			fields[currency] = p.CurrentPrice
+			fields[currency+"_market_cap"] = p.MarketCap
+			fields[currency+"_24h_vol"] = p.TotalVolume
+			fields[currency+"_24h_change"] = p.PriceChangePercentage24h
+		}
+		out[addr] = fields
+	}
+	mockJSON(w, out)
+}
+
+func (m *mockMarket) serveNFT(w http.ResponseWriter, r *http.Request) {
+	nft, ok := mockNFTs[strings.TrimPrefix(r.URL.Path, "/api/v3/nfts/")]
+	if !ok {
+		http.Error(w, `{"error":"NFT collection not found"}`, http.StatusNotFound)
+		return
+	}
+	floor := float64(mockHash(nft.ID)%2000)/100 + 5
+	eth := m.spot(lookupMockToken("ethereum"), time.Now().UTC())
+	nft.FloorPrice = map[string]float64{"native_currency": floor, "usd": floor * eth}
+	nft.Volume24h = map[string]float64{"native_currency": floor * 12, "usd": floor * 12 * eth}
+	nft.FloorPriceChange24h = float64(mockHash(nft.ID)%1000)/100 - 5
+	mockJSON(w, nft)
+}
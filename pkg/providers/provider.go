@@ -0,0 +1,233 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+)
+
+// Price is a token quote in the shape of a CoinGecko /coins/markets entry,
+// which every provider returns
+type Price struct {
+	ID                       string  `json:"id"`
+	Symbol                   string  `json:"symbol"`
+	Name                     string  `json:"name"`
+	CurrentPrice             float64 `json:"current_price"`
+	MarketCap                float64 `json:"market_cap"`
+	TotalVolume              float64 `json:"total_volume"`
+	PriceChangePercentage24h float64 `json:"price_change_percentage_24h"`
+	PriceChangePercentage7d  float64 `json:"price_change_percentage_7d_in_currency"`
+	CirculatingSupply        float64 `json:"circulating_supply,omitempty"`
+	TotalSupply              float64 `json:"total_supply,omitempty"`
+	LastUpdated              string  `json:"last_updated"`
+	Image                    string  `json:"image,omitempty"` // logo URL
+
+	// Source names the provider or configured source that quoted the price
+	Source string `json:"source,omitempty"`
+
+	// CurrentPriceText is current_price exactly as the provider wrote it,
+	// if it did
+	CurrentPriceText string `json:"-"`
+}
+
+// UnmarshalJSON decodes a price, keeping current_price's exact text
+func (p *Price) UnmarshalJSON(b []byte) error {
+	type price Price
+	aux := struct {
+		*price
+		CurrentPrice json.Number `json:"current_price"`
+	}{price: (*price)(p)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	p.CurrentPrice, p.CurrentPriceText = 0, ""
+	if aux.CurrentPrice != "" {
+		f, err := aux.CurrentPrice.Float64()
+		if err != nil {
+			return fmt.Errorf("current_price: %w", err)
+		}
+		p.CurrentPrice, p.CurrentPriceText = f, aux.CurrentPrice.String()
+	}
+	return nil
+}
+
+// Exact returns the current price as a decimal: exactly as the provider
+// wrote it if known, otherwise from CurrentPrice
+func (p *Price) Exact() decimal.Decimal {
+	if p.CurrentPriceText != "" {
+		if d, err := decimal.Parse(p.CurrentPriceText); err == nil {
+			return d
+		}
+	}
+	return decimal.FromFloat(p.CurrentPrice)
+}
+
+// Provider is an upstream price source
+type Provider interface {
+	// Name identifies the provider in logs and errors
+	Name() string
+
+	// FetchPrice fetches one token's price
+	FetchPrice(ctx context.Context, tokenID, currency string) (*Price, error)
+
+	// FetchPrices fetches several prices, returning those that succeeded
+	// along with any error
+	FetchPrices(ctx context.Context, tokenIDs []string, currency string) ([]Price, error)
+}
+
+// Router sends each token to the provider that owns it, and all other
+// tokens to a fallback provider. A token may instead be supplemented by a
+// provider that is only asked when the fallback has no price for it.
+type Router struct {
+	fallback    Provider
+	byToken     map[string]Provider
+	supplements map[string]Provider
+}
+
+// NewRouter creates a router that sends unclaimed tokens to fallback
+func NewRouter(fallback Provider) *Router {
+	return &Router{
+		fallback:    fallback,
+		byToken:     make(map[string]Provider),
+		supplements: make(map[string]Provider),
+	}
+}
+
+// Route claims tokens for p. A token may only be claimed or supplemented
+// once.
+func (rt *Router) Route(p Provider, tokenIDs ...string) error {
+	return rt.assign(rt.byToken, p, tokenIDs)
+}
+
+// Supplement has p price tokens the fallback doesn't return, or all of
+// them while the fallback is failing
+func (rt *Router) Supplement(p Provider, tokenIDs ...string) error {
+	return rt.assign(rt.supplements, p, tokenIDs)
+}
+
+func (rt *Router) assign(to map[string]Provider, p Provider, tokenIDs []string) error {
+	for _, id := range tokenIDs {
+		id = strings.ToLower(id)
+		other, dup := rt.byToken[id]
+		if !dup {
+			other, dup = rt.supplements[id]
+		}
+		if dup {
+			return fmt.Errorf("token %s claimed by both %s and %s", id, other.Name(), p.Name())
+		}
+		to[id] = p
+	}
+	return nil
+}
+
+// Name identifies the provider
+func (rt *Router) Name() string {
+	return "router"
+}
+
+func (rt *Router) providerFor(tokenID string) Provider {
+	if p, ok := rt.byToken[tokenID]; ok {
+		return p
+	}
+	return rt.fallback
+}
+
+// FetchPrice fetches a price from the provider owning the token, or from
+// the fallback while the owner is unavailable
+func (rt *Router) FetchPrice(ctx context.Context, tokenID, currency string) (*Price, error) {
+	p := rt.providerFor(tokenID)
+	price, err := p.FetchPrice(ctx, tokenID, currency)
+	if unavailable(err) && p != rt.fallback {
+		return rt.fallback.FetchPrice(ctx, tokenID, currency)
+	}
+	if s, ok := rt.supplements[tokenID]; ok && err != nil && ctx.Err() == nil {
+		return s.FetchPrice(ctx, tokenID, currency)
+	}
+	return price, err
+}
+
+// FetchPrices groups tokens by provider and fetches the groups concurrently
+func (rt *Router) FetchPrices(ctx context.Context, tokenIDs []string, currency string) ([]Price, error) {
+	groups := make(map[Provider][]string)
+	for _, id := range tokenIDs {
+		p := rt.providerFor(id)
+		groups[p] = append(groups[p], id)
+	}
+	prices, err := rt.fetchGroups(ctx, groups, currency)
+	if len(rt.supplements) == 0 || ctx.Err() != nil {
+		return prices, err
+	}
+
+	// Supplemented tokens the fallback didn't price go to their supplement
+	found := make(map[string]bool, len(prices))
+	for _, p := range prices {
+		found[p.ID] = true
+	}
+	missing := make(map[Provider][]string)
+	for _, id := range tokenIDs {
+		if s, ok := rt.supplements[id]; ok && !found[id] {
+			missing[s] = append(missing[s], id)
+		}
+	}
+	if len(missing) == 0 {
+		return prices, err
+	}
+	more, moreErr := rt.fetchGroups(ctx, missing, currency)
+	return append(prices, more...), errors.Join(err, moreErr)
+}
+
+// fetchGroups fetches each provider's ids concurrently
+func (rt *Router) fetchGroups(ctx context.Context, groups map[Provider][]string, currency string) ([]Price, error) {
+	if len(groups) == 1 {
+		for p, ids := range groups {
+			return rt.fetchGroup(ctx, p, ids, currency)
+		}
+	}
+
+	var (
+		mu     sync.Mutex
+		prices []Price
+		errs   []error
+		wg     sync.WaitGroup
+	)
+	for p, ids := range groups {
+		wg.Add(1)
+		go func(p Provider, ids []string) {
+			defer wg.Done()
+			page, err := rt.fetchGroup(ctx, p, ids, currency)
+			mu.Lock()
+			defer mu.Unlock()
+			prices = append(prices, page...)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+			}
+		}(p, ids)
+	}
+	wg.Wait()
+
+	return prices, errors.Join(errs...)
+}
+
+// fetchGroup fetches ids from p, or from the fallback while p is
+// unavailable
+func (rt *Router) fetchGroup(ctx context.Context, p Provider, ids []string, currency string) ([]Price, error) {
+	prices, err := p.FetchPrices(ctx, ids, currency)
+	if unavailable(err) && len(prices) == 0 && p != rt.fallback {
+		return rt.fallback.FetchPrices(ctx, ids, currency)
+	}
+	return prices, err
+}
+
+// unavailable reports whether a provider refused a call without trying it,
+// because its circuit is open or it is rate limiting
+func unavailable(err error) bool {
+	return errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrRateLimited)
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned without calling a provider that answered 429
+// until its Retry-After window has passed
+var ErrRateLimited = errors.New("rate limited")
+
+// RateLimitError reports a call refused because the host is rate limiting
+type RateLimitError struct {
+	Host  string
+	Until time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s rate limited until %s", e.Host, e.Until.UTC().Format(time.RFC3339))
+}
+
+// Is matches ErrRateLimited
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// RateLimitStatus is a snapshot of one host's rate limiting
+type RateLimitStatus struct {
+	Host        string     `json:"host"`
+	Limited     int64      `json:"limited"`
+	Rejected    int64      `json:"rejected"`
+	PausedUntil *time.Time `json:"paused_until,omitempty"`
+}
+
+type hostLimit struct {
+	until    time.Time
+	limited  int64
+	rejected int64
+}
+
+// RateLimitTransport pauses all requests to a host that answers 429 for
+// the window its Retry-After header asks for, or Pause if it gives none,
+// failing them with a RateLimitError instead. Hammering a rate-limited API
+// only lengthens the block.
+type RateLimitTransport struct {
+	next  http.RoundTripper
+	pause time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimit
+}
+
+// NewRateLimitTransport wraps next, pausing for pause after a 429 without
+// a usable Retry-After
+func NewRateLimitTransport(next http.RoundTripper, pause time.Duration) *RateLimitTransport {
+	return &RateLimitTransport{next: next, pause: pause, hosts: make(map[string]*hostLimit)}
+}
+
+// RoundTrip sends req unless its host is paused
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if until, ok := t.paused(host); ok {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, &RateLimitError{Host: host, Until: until}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+		return resp, err
+	}
+	wait := retryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if wait <= 0 {
+		wait = t.pause
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	until := time.Now().Add(wait)
+	t.limit(host, until)
+	log.Printf("Upstream %s rate limited; pausing requests for %s", host, wait.Round(time.Second))
+	return nil, &RateLimitError{Host: host, Until: until}
+}
+
+// paused reports whether host is paused, counting the refused call
+func (t *RateLimitTransport) paused(host string) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.hosts[host]
+	if h == nil || !time.Now().Before(h.until) {
+		return time.Time{}, false
+	}
+	h.rejected++
+	return h.until, true
+}
+
+// limit pauses host until the given time, unless it is already paused longer
+func (t *RateLimitTransport) limit(host string, until time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.hosts[host]
+	if h == nil {
+		h = &hostLimit{}
+		t.hosts[host] = h
+	}
+	h.limited++
+	if until.After(h.until) {
+		h.until = until
+	}
+}
+
+// Status lists every host that has rate limited requests, by host
+func (t *RateLimitTransport) Status() []RateLimitStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	statuses := make([]RateLimitStatus, 0, len(t.hosts))
+	for host, h := range t.hosts {
+		status := RateLimitStatus{Host: host, Limited: h.limited, Rejected: h.rejected}
+		if now.Before(h.until) {
+			until := h.until
+			status.PausedUntil = &until
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Host < statuses[j].Host })
+	return statuses
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP
+// date, returning 0 if it is missing or invalid
+func retryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package providers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Recording is an upstream response captured by a RecordTransport and
+// served by a ReplayTransport, one file per request
+type Recording struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"` // without credentials in the query
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+
+	// JSON is the body if it is JSON, kept readable; Body otherwise
+	JSON json.RawMessage `json:"json,omitempty"`
+	Body string          `json:"body,omitempty"`
+}
+
+// RecordTransport writes every response from next into a directory, so
+// it can be replayed later by a ReplayTransport. Rate limited and server
+// error responses are not recorded, since a rerun could succeed.
+type RecordTransport struct {
+	next http.RoundTripper
+	dir  string
+}
+
+// NewRecordTransport wraps next, recording responses into dir
+func NewRecordTransport(next http.RoundTripper, dir string) (*RecordTransport, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &RecordTransport{next: next, dir: dir}, nil
+}
+
+// RoundTrip sends req and records the response
+func (t *RecordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	name, err := recordingName(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	rec := Recording{
+		Method:      req.Method,
+		URL:         redactURL(req.URL),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if json.Valid(body) {
+		rec.JSON = body
+	} else {
+		rec.Body = string(body)
+	}
+	if err := t.save(name, rec); err != nil {
+		return nil, fmt.Errorf("recording %s: %w", rec.URL, err)
+	}
+	return resp, nil
+}
+
+// save writes a recording, replacing the file atomically
+func (t *RecordTransport) save(name string, rec Recording) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(rec); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(t.dir, name+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(t.dir, name))
+}
+
+// ReplayTransport serves responses recorded by a RecordTransport without
+// touching the network. A request that was never recorded fails.
+type ReplayTransport struct {
+	dir string
+}
+
+// NewReplayTransport serves the recordings in dir
+func NewReplayTransport(dir string) (*ReplayTransport, error) {
+	if info, err := os.Stat(dir); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return &ReplayTransport{dir: dir}, nil
+}
+
+// RoundTrip answers req from its recording
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	name, err := recordingName(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	data, err := os.ReadFile(filepath.Join(t.dir, name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no recording of %s %s", req.Method, redactURL(req.URL))
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rec Recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("recording %s: %w", name, err)
+	}
+
+	body := []byte(rec.Body)
+	if len(rec.JSON) > 0 {
+		body = rec.JSON
+	}
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+	if rec.ContentType != "" {
+		resp.Header.Set("Content-Type", rec.ContentType)
+	}
+	return resp, nil
+}
+
+// recordingName names the file of a request's recording after a hash of
+// its method, URL without credentials and body. The body is read and
+// restored.
+func recordingName(req *http.Request) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", req.Method, redactURL(req.URL))
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return "", err
+		}
+		h.Write(body)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return strings.ReplaceAll(req.URL.Host, ":", "_") + "-" + hex.EncodeToString(h.Sum(nil))[:16] + ".json", nil
+}
+
+// redactURL returns u without query parameters that look like
+// credentials, with the rest sorted
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	for name := range q {
+		lower := strings.ToLower(name)
+		if strings.Contains(lower, "key") || strings.HasSuffix(lower, "_token") || strings.Contains(lower, "secret") {
+			q.Del(name)
+		}
+	}
+	redacted := *u
+	redacted.User = nil
+	redacted.RawQuery = q.Encode()
+	return redacted.String()
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package providers
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures how transient upstream failures are retried
+type RetryPolicy struct {
+	// Attempts is the most tries per request, including the first; 1 or
+	// less disables retries
+	Attempts int
+
+	// Backoff is the delay cap before the first retry, doubling per retry
+	// up to MaxBackoff. Each delay is drawn uniformly below its cap.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy retries twice, after up to 200ms and 400ms
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{Attempts: 3, Backoff: 200 * time.Millisecond, MaxBackoff: 5 * time.Second}
+}
+
+// retryTransport retries idempotent requests that fail transiently
+type retryTransport struct {
+	next   http.RoundTripper
+	policy RetryPolicy
+}
+
+// NewRetryTransport wraps next so GET and HEAD requests answered with 429
+// or a 5xx status, or that time out, are retried with exponential backoff
+// and jitter. Retries stop when the request's context is done, so a
+// client timeout bounds every attempt together.
+func NewRetryTransport(next http.RoundTripper, policy RetryPolicy) http.RoundTripper {
+	if policy.Attempts <= 1 {
+		return next
+	}
+	if policy.MaxBackoff < policy.Backoff {
+		policy.MaxBackoff = policy.Backoff
+	}
+	return &retryTransport{next: next, policy: policy}
+}
+
+// RoundTrip sends req, retrying transient failures
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return t.next.RoundTrip(req)
+	}
+	delay := t.policy.Backoff
+	for attempt := 1; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt >= t.policy.Attempts || !retryable(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(time.Duration(rand.Int63n(int64(delay) + 1)))
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		delay = min(2*delay, t.policy.MaxBackoff)
+	}
+}
+
+// retryable reports whether a response or error is worth another try:
+// rate limiting, server errors and timeouts
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		var netErr net.Error
+		return errors.As(err, &netErr) && netErr.Timeout()
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
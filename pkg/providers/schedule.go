@@ -0,0 +1,273 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package providers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Priority orders requests waiting for a host's quota: lower values go
+// first
+type Priority int
+
+// Priorities of upstream requests
+const (
+	PriorityInteractive Priority = iota // made for an API request; the default
+	PriorityBackground                  // made by a periodic refresh job
+	PriorityBackfill                    // made by a history backfill
+	numPriorities
+)
+
+// String names the priority in status reports
+func (p Priority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityBackground:
+		return "background"
+	case PriorityBackfill:
+		return "backfill"
+	}
+	return "unknown"
+}
+
+type priorityKey struct{}
+
+// WithPriority marks upstream requests made with ctx as of priority p
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext returns the priority set on ctx, or
+// PriorityInteractive if none
+func PriorityFromContext(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok && p >= 0 && p < numPriorities {
+		return p
+	}
+	return PriorityInteractive
+}
+
+// ScheduleStatus is a snapshot of one host's request pacing
+type ScheduleStatus struct {
+	Host              string           `json:"host"`
+	RequestsPerMinute int              `json:"requests_per_minute"`
+	Available         float64          `json:"available"` // requests that may be sent at once
+	Queued            map[string]int   `json:"queued"`    // by priority
+	Sent              map[string]int64 `json:"sent"`      // by priority
+	Waited            map[string]int64 `json:"waited"`    // sent after queueing, by priority
+	Abandoned         map[string]int64 `json:"abandoned"` // left the queue because the caller gave up
+}
+
+// waiter is a request queued for a host's quota
+type waiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// hostSchedule paces requests to one host with a token bucket refilled at
+// its rate, handing tokens to waiters by priority, then arrival
+type hostSchedule struct {
+	host   string
+	rpm    int
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+	timer  *time.Timer
+
+	queues    [numPriorities][]*waiter
+	sent      [numPriorities]int64
+	waited    [numPriorities]int64
+	abandoned [numPriorities]int64
+}
+
+// SchedulerTransport paces requests to each host with a configured quota
+// of requests per minute, so the jobs sharing a provider cannot spend its
+// quota between them and get it rate limited. Requests over the quota
+// wait for it, interactive ones first, then background refreshes, then
+// backfills; one whose context is done leaves the queue at once. Up to a
+// tenth of a minute's quota may be sent at once. Hosts without a quota
+// are not paced.
+type SchedulerTransport struct {
+	next http.RoundTripper
+
+	mu    sync.Mutex
+	hosts map[string]*hostSchedule
+}
+
+// NewSchedulerTransport wraps next, pacing each host in quotas to its
+// requests per minute
+func NewSchedulerTransport(next http.RoundTripper, quotas map[string]int) *SchedulerTransport {
+	t := &SchedulerTransport{next: next, hosts: make(map[string]*hostSchedule, len(quotas))}
+	now := time.Now()
+	for host, rpm := range quotas {
+		if rpm <= 0 {
+			continue
+		}
+		burst := max(1, float64(rpm)/10)
+		host = strings.ToLower(host)
+		t.hosts[host] = &hostSchedule{host: host, rpm: rpm, rate: float64(rpm) / 60, burst: burst, tokens: burst, last: now}
+	}
+	return t
+}
+
+// RoundTrip sends req once its host's quota allows
+func (t *SchedulerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if h := t.hosts[strings.ToLower(req.URL.Hostname())]; h != nil {
+		if err := t.wait(req.Context(), h, PriorityFromContext(req.Context())); err != nil {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
+	}
+	return t.next.RoundTrip(req)
+}
+
+// wait takes a token from h, queueing for one behind the requests of the
+// same or higher priority if none is free
+func (t *SchedulerTransport) wait(ctx context.Context, h *hostSchedule, p Priority) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	h.refill(time.Now())
+	if h.tokens >= 1 && !h.queuedAhead(p) {
+		h.tokens--
+		h.sent[p]++
+		t.mu.Unlock()
+		return nil
+	}
+	w := &waiter{ready: make(chan struct{})}
+	h.queues[p] = append(h.queues[p], w)
+	t.dispatch(h)
+	t.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if w.granted {
+		// The token came just as the caller gave up; hand it on
+		h.tokens++
+		h.sent[p]--
+		h.waited[p]--
+	} else {
+		h.remove(p, w)
+	}
+	h.abandoned[p]++
+	t.dispatch(h)
+	return ctx.Err()
+}
+
+// dispatch hands the tokens available to the waiters in order and, if
+// any are left waiting, schedules itself for the next token. The caller
+// holds t.mu.
+func (t *SchedulerTransport) dispatch(h *hostSchedule) {
+	h.refill(time.Now())
+	for h.tokens >= 1 {
+		w, p := h.pop()
+		if w == nil {
+			return
+		}
+		h.tokens--
+		h.sent[p]++
+		h.waited[p]++
+		w.granted = true
+		close(w.ready)
+	}
+	if h.timer == nil && h.queued() > 0 {
+		wait := time.Duration((1 - h.tokens) / h.rate * float64(time.Second))
+		h.timer = time.AfterFunc(wait, func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			h.timer = nil
+			t.dispatch(h)
+		})
+	}
+}
+
+// refill adds the tokens earned since the last refill, up to the burst
+func (h *hostSchedule) refill(now time.Time) {
+	h.tokens = min(h.burst, h.tokens+now.Sub(h.last).Seconds()*h.rate)
+	h.last = now
+}
+
+// queuedAhead reports whether requests of priority p or higher are queued
+func (h *hostSchedule) queuedAhead(p Priority) bool {
+	for q := PriorityInteractive; q <= p; q++ {
+		if len(h.queues[q]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// queued counts the waiters
+func (h *hostSchedule) queued() int {
+	n := 0
+	for _, q := range h.queues {
+		n += len(q)
+	}
+	return n
+}
+
+// pop removes the first waiter of the highest priority queued
+func (h *hostSchedule) pop() (*waiter, Priority) {
+	for p := PriorityInteractive; p < numPriorities; p++ {
+		if q := h.queues[p]; len(q) > 0 {
+			h.queues[p] = q[1:]
+			return q[0], p
+		}
+	}
+	return nil, 0
+}
+
+// remove drops a waiter that gave up from its queue
+func (h *hostSchedule) remove(p Priority, w *waiter) {
+	q := h.queues[p]
+	for i := range q {
+		if q[i] == w {
+			h.queues[p] = append(q[:i:i], q[i+1:]...)
+			return
+		}
+	}
+}
+
+// Status lists the pacing of every host with a quota, by host
+func (t *SchedulerTransport) Status() []ScheduleStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	statuses := make([]ScheduleStatus, 0, len(t.hosts))
+	for _, h := range t.hosts {
+		h.refill(now)
+		status := ScheduleStatus{
+			Host:              h.host,
+			RequestsPerMinute: h.rpm,
+			Available:         float64(int(h.tokens*100)) / 100,
+			Queued:            make(map[string]int, numPriorities),
+			Sent:              make(map[string]int64, numPriorities),
+			Waited:            make(map[string]int64, numPriorities),
+			Abandoned:         make(map[string]int64, numPriorities),
+		}
+		for p := PriorityInteractive; p < numPriorities; p++ {
+			status.Queued[p.String()] = len(h.queues[p])
+			status.Sent[p.String()] = h.sent[p]
+			status.Waited[p.String()] = h.waited[p]
+			status.Abandoned[p.String()] = h.abandoned[p]
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Host < statuses[j].Host })
+	return statuses
+}
@@ -0,0 +1,373 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package providers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SchemaVersion is the revision of the upstream schemas responses are
+// checked against; it is bumped whenever one is updated for an upstream
+// change
+const SchemaVersion = 1
+
+// Kinds of schema drift
+const (
+	DriftUnexpected = "unexpected" // a field the schema does not know
+	DriftMissing    = "missing"    // an expected field absent
+	DriftType       = "type"       // a field of the wrong JSON type
+)
+
+// jsonKind is the JSON type a schema expects of a field
+type jsonKind int
+
+const (
+	anyKind jsonKind = iota
+	stringKind
+	numberKind
+)
+
+// schema lists the fields of an entry of an upstream response. Entries
+// lacking a required field are dropped. Expected fields may be null but
+// their absence is counted; known fields are ones we do not map.
+type schema struct {
+	endpoint string
+	required map[string]jsonKind
+	expected map[string]jsonKind
+	known    map[string]jsonKind
+}
+
+// coinMarketSchema is a CoinGecko /coins/markets entry
+var coinMarketSchema = &schema{
+	endpoint: "/coins/markets",
+	required: map[string]jsonKind{
+		"id":            stringKind,
+		"current_price": numberKind,
+	},
+	expected: map[string]jsonKind{
+		"symbol":                                 stringKind,
+		"name":                                   stringKind,
+		"market_cap":                             numberKind,
+		"total_volume":                           numberKind,
+		"price_change_percentage_24h":            numberKind,
+		"price_change_percentage_7d_in_currency": numberKind,
+		"circulating_supply":                     numberKind,
+		"total_supply":                           numberKind,
+		"last_updated":                           stringKind,
+	},
+	known: map[string]jsonKind{
+		"image":                            stringKind,
+		"market_cap_rank":                  numberKind,
+		"fully_diluted_valuation":          numberKind,
+		"high_24h":                         numberKind,
+		"low_24h":                          numberKind,
+		"price_change_24h":                 numberKind,
+		"market_cap_change_24h":            numberKind,
+		"market_cap_change_percentage_24h": numberKind,
+		"max_supply":                       numberKind,
+		"ath":                              numberKind,
+		"ath_change_percentage":            numberKind,
+		"ath_date":                         stringKind,
+		"atl":                              numberKind,
+		"atl_change_percentage":            numberKind,
+		"atl_date":                         stringKind,
+		"roi":                              anyKind,
+	},
+}
+
+// adapterSchema is an entry of an HTTPAdapter sidecar's /prices. Sidecars
+// may leave out any field but the required ones.
+var adapterSchema = &schema{
+	endpoint: "/prices",
+	required: coinMarketSchema.required,
+	known: map[string]jsonKind{
+		"symbol":                                 stringKind,
+		"name":                                   stringKind,
+		"market_cap":                             numberKind,
+		"total_volume":                           numberKind,
+		"price_change_percentage_24h":            numberKind,
+		"price_change_percentage_7d_in_currency": numberKind,
+		"circulating_supply":                     numberKind,
+		"total_supply":                           numberKind,
+		"last_updated":                           stringKind,
+		"image":                                  stringKind,
+		"source":                                 stringKind,
+	},
+}
+
+// SchemaDrift counts one kind of mismatch of one field of an upstream
+// response against its schema
+type SchemaDrift struct {
+	Provider  string    `json:"provider"`
+	Endpoint  string    `json:"endpoint"`
+	Field     string    `json:"field"`
+	Kind      string    `json:"kind"`
+	Required  bool      `json:"required"` // entries were dropped
+	Count     uint64    `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// SchemaStatus is the schema version checked and the drift seen since
+// startup
+type SchemaStatus struct {
+	Version int           `json:"version"`
+	Drift   []SchemaDrift `json:"drift"`
+}
+
+// SchemaMonitor counts upstream responses that do not match their schema,
+// logging each kind of mismatch the first time it is seen. A nil monitor
+// checks responses without counting.
+type SchemaMonitor struct {
+	mu    sync.Mutex
+	drift map[string]*SchemaDrift // provider endpoint field kind -> drift
+}
+
+// NewSchemaMonitor creates a monitor with no drift recorded
+func NewSchemaMonitor() *SchemaMonitor {
+	return &SchemaMonitor{drift: make(map[string]*SchemaDrift)}
+}
+
+// record counts one mismatch
+func (m *SchemaMonitor) record(provider string, s *schema, field, kind string, required bool) {
+	if m == nil {
+		return
+	}
+	now := time.Now().UTC()
+	key := provider + " " + s.endpoint + " " + field + " " + kind
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d, ok := m.drift[key]
+	if !ok {
+		d = &SchemaDrift{Provider: provider, Endpoint: s.endpoint, Field: field, Kind: kind, Required: required, FirstSeen: now}
+		m.drift[key] = d
+		var what string
+		switch {
+		case field == "":
+			what = "response is not an array"
+		case kind == DriftUnexpected:
+			what = fmt.Sprintf("unexpected field %q", field)
+		case kind == DriftMissing:
+			what = fmt.Sprintf("field %q missing", field)
+		default:
+			what = fmt.Sprintf("field %q has the wrong type", field)
+		}
+		if required {
+			what += "; entries dropped"
+		}
+		log.Printf("Schema drift in %s %s: %s", provider, s.endpoint, what)
+	}
+	d.Count++
+	d.LastSeen = now
+}
+
+// Status returns the drift seen, by provider, endpoint and field
+func (m *SchemaMonitor) Status() SchemaStatus {
+	status := SchemaStatus{Version: SchemaVersion, Drift: []SchemaDrift{}}
+	if m == nil {
+		return status
+	}
+	m.mu.Lock()
+	for _, d := range m.drift {
+		status.Drift = append(status.Drift, *d)
+	}
+	m.mu.Unlock()
+	sort.Slice(status.Drift, func(i, j int) bool {
+		a, b := status.Drift[i], status.Drift[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		return a.Kind < b.Kind
+	})
+	return status
+}
+
+// decodeEntries decodes a JSON array of entries and checks each against s,
+// dropping those a required field is absent, null or mistyped in
+func (m *SchemaMonitor) decodeEntries(r io.Reader, provider string, s *schema) ([]map[string]json.RawMessage, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	var entries []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		m.record(provider, s, "", DriftType, true)
+		return nil, fmt.Errorf("%s %s: %w", provider, s.endpoint, err)
+	}
+
+	valid := entries[:0]
+	for _, e := range entries {
+		if e != nil && m.check(provider, s, e) {
+			valid = append(valid, e)
+		}
+	}
+	return valid, nil
+}
+
+// check counts an entry's mismatches against s, removing mistyped fields,
+// and reports whether every required field is present
+func (m *SchemaMonitor) check(provider string, s *schema, e map[string]json.RawMessage) bool {
+	ok := true
+	for field, kind := range s.required {
+		v, present := e[field]
+		switch {
+		case !present:
+			m.record(provider, s, field, DriftMissing, true)
+			ok = false
+		case isNull(v):
+			// The provider has no value for this token, e.g. a price
+			// for a delisted coin
+			ok = false
+		case !kindOf(v, kind):
+			m.record(provider, s, field, DriftType, true)
+			ok = false
+		}
+	}
+	for field := range s.expected {
+		if _, present := e[field]; !present {
+			m.record(provider, s, field, DriftMissing, false)
+		}
+	}
+	for field, v := range e {
+		kind, expected := s.expected[field]
+		if !expected {
+			if _, required := s.required[field]; required {
+				continue
+			}
+			var known bool
+			if kind, known = s.known[field]; !known {
+				m.record(provider, s, field, DriftUnexpected, false)
+				continue
+			}
+		}
+		if !isNull(v) && !kindOf(v, kind) {
+			m.record(provider, s, field, DriftType, false)
+			delete(e, field)
+		}
+	}
+	return ok
+}
+
+// isNull reports whether v is JSON null
+func isNull(v json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(v), []byte("null"))
+}
+
+// kindOf reports whether v is of the JSON type kind
+func kindOf(v json.RawMessage, kind jsonKind) bool {
+	v = bytes.TrimSpace(v)
+	if len(v) == 0 {
+		return false
+	}
+	switch kind {
+	case stringKind:
+		return v[0] == '"'
+	case numberKind:
+		return v[0] == '-' || (v[0] >= '0' && v[0] <= '9')
+	}
+	return true
+}
+
+// coinMarket is a /coins/markets entry as the provider wrote it. It is
+// kept apart from Price, which the API serves, so an upstream change stops
+// at the mapper.
+type coinMarket struct {
+	ID                string
+	Symbol            string
+	Name              string
+	CurrentPrice      json.Number
+	MarketCap         float64
+	TotalVolume       float64
+	Change24h         float64
+	Change7d          float64
+	CirculatingSupply float64
+	TotalSupply       float64
+	LastUpdated       string
+	Image             string
+	Source            string
+}
+
+// decodeCoinMarket fills a coinMarket from a checked entry. Fields absent,
+// null or removed as mistyped are left zero.
+func decodeCoinMarket(e map[string]json.RawMessage) coinMarket {
+	var c coinMarket
+	fields := map[string]interface{}{
+		"id":                                     &c.ID,
+		"symbol":                                 &c.Symbol,
+		"name":                                   &c.Name,
+		"current_price":                          &c.CurrentPrice,
+		"market_cap":                             &c.MarketCap,
+		"total_volume":                           &c.TotalVolume,
+		"price_change_percentage_24h":            &c.Change24h,
+		"price_change_percentage_7d_in_currency": &c.Change7d,
+		"circulating_supply":                     &c.CirculatingSupply,
+		"total_supply":                           &c.TotalSupply,
+		"last_updated":                           &c.LastUpdated,
+		"image":                                  &c.Image,
+		"source":                                 &c.Source,
+	}
+	for field, dst := range fields {
+		if v, ok := e[field]; ok {
+			json.Unmarshal(v, dst)
+		}
+	}
+	return c
+}
+
+// price maps the entry to a Price, keeping current_price's exact text
+func (c coinMarket) price() (Price, error) {
+	f, err := c.CurrentPrice.Float64()
+	if err != nil {
+		return Price{}, fmt.Errorf("%s: current_price: %w", c.ID, err)
+	}
+	return Price{
+		ID:                       c.ID,
+		Symbol:                   c.Symbol,
+		Name:                     c.Name,
+		CurrentPrice:             f,
+		CurrentPriceText:         c.CurrentPrice.String(),
+		MarketCap:                c.MarketCap,
+		TotalVolume:              c.TotalVolume,
+		PriceChangePercentage24h: c.Change24h,
+		PriceChangePercentage7d:  c.Change7d,
+		CirculatingSupply:        c.CirculatingSupply,
+		TotalSupply:              c.TotalSupply,
+		LastUpdated:              c.LastUpdated,
+		Image:                    c.Image,
+		Source:                   c.Source,
+	}, nil
+}
+
+// decodeMarkets decodes a /coins/markets shaped response into prices,
+// checking it against s. Entries that fail the schema are left out rather
+// than served with zeroed fields.
+func (m *SchemaMonitor) decodeMarkets(r io.Reader, provider string, s *schema) ([]Price, error) {
+	entries, err := m.decodeEntries(r, provider, s)
+	if err != nil {
+		return nil, err
+	}
+	prices := make([]Price, 0, len(entries))
+	for _, e := range entries {
+		p, err := decodeCoinMarket(e).price()
+		if err != nil {
+			m.record(provider, s, "current_price", DriftType, true)
+			continue
+		}
+		prices = append(prices, p)
+	}
+	return prices, nil
+}
@@ -0,0 +1,287 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+)
+
+// quoter prices one token in a source's quote asset
+type quoter func(ctx context.Context, tokenID string) (decimal.Decimal, error)
+
+// Source is a manually configured price source for tokens that providers
+// don't list yet, or list unreliably, such as a DEX pool or an exchange's
+// own API. A source quotes its tokens in one asset, the quote: either a
+// currency, used as is when requested in it, or a token id priced by
+// pricer in the requested currency, so a pool against USDC serves every
+// currency USDC is quoted in.
+type Source struct {
+	name   string
+	quote  string
+	pricer Provider
+	tokens map[string]quoter
+}
+
+// Name identifies the source
+func (s *Source) Name() string {
+	return s.name
+}
+
+// Tokens returns the token ids the source prices
+func (s *Source) Tokens() []string {
+	ids := make([]string, 0, len(s.tokens))
+	for id := range s.tokens {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// FetchPrice fetches one token's price
+func (s *Source) FetchPrice(ctx context.Context, tokenID, currency string) (*Price, error) {
+	prices, err := s.FetchPrices(ctx, []string{tokenID}, currency)
+	if len(prices) == 0 {
+		if err == nil {
+			err = &NotFoundError{Token: tokenID}
+		}
+		return nil, err
+	}
+	return &prices[0], nil
+}
+
+// FetchPrices fetches the prices of the tokens the source knows; others
+// are omitted. Prices that succeeded are returned along with any error.
+func (s *Source) FetchPrices(ctx context.Context, tokenIDs []string, currency string) ([]Price, error) {
+	var known []string
+	for _, id := range tokenIDs {
+		if _, ok := s.tokens[id]; ok {
+			known = append(known, id)
+		}
+	}
+	if len(known) == 0 {
+		return nil, nil
+	}
+
+	rate, err := s.rate(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		prices []Price
+		errs   []error
+	)
+	updated := time.Now().UTC().Format(time.RFC3339)
+	for _, id := range known {
+		q, err := s.tokens[id](ctx, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", id, err))
+			continue
+		}
+		price := q.Mul(rate)
+		prices = append(prices, Price{
+			ID:               id,
+			Symbol:           id,
+			CurrentPrice:     price.Float64(),
+			CurrentPriceText: price.String(),
+			LastUpdated:      updated,
+			Source:           s.name,
+		})
+	}
+	return prices, errors.Join(errs...)
+}
+
+// rate returns the price of one unit of the quote asset in currency
+func (s *Source) rate(ctx context.Context, currency string) (decimal.Decimal, error) {
+	if strings.EqualFold(s.quote, currency) {
+		return decimal.FromFloat(1), nil
+	}
+	if s.pricer == nil {
+		return decimal.Decimal{}, fmt.Errorf("%s quotes in %s, not %s", s.name, s.quote, currency)
+	}
+	p, err := s.pricer.FetchPrice(ctx, s.quote, currency)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("%s quote %s: %w", s.name, s.quote, err)
+	}
+	return p.Exact(), nil
+}
+
+// Pool is a Uniswap V2 style pair holding a token and the quote asset
+type Pool struct {
+	Address       string `json:"address"`
+	BaseIndex     int    `json:"base_index"` // 0 if the priced token is token0, 1 if token1
+	BaseDecimals  int    `json:"base_decimals"`
+	QuoteDecimals int    `json:"quote_decimals"`
+}
+
+// getReservesCall is the calldata of getReserves()
+const getReservesCall = "0x0902f1ac"
+
+// NewDEXSource creates a source pricing each token from the reserves of
+// its pool, read over the EVM JSON-RPC endpoint at rpcURL. Requests use
+// transport, or a default pooled transport if nil.
+func NewDEXSource(name, quote string, pricer Provider, rpcURL string, pools map[string]Pool, timeout time.Duration, transport http.RoundTripper) *Source {
+	client := sourceClient(timeout, transport)
+	s := &Source{name: name, quote: quote, pricer: pricer, tokens: make(map[string]quoter, len(pools))}
+	for id, pool := range pools {
+		pool := pool
+		s.tokens[strings.ToLower(id)] = func(ctx context.Context, tokenID string) (decimal.Decimal, error) {
+			return poolPrice(ctx, client, rpcURL, pool)
+		}
+	}
+	return s
+}
+
+// poolPrice reads a pool's reserves and returns the base token's price in
+// the quote asset
+func poolPrice(ctx context.Context, client *http.Client, rpcURL string, pool Pool) (decimal.Decimal, error) {
+	body, _ := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "eth_call",
+		"params":  []any{map[string]string{"to": pool.Address, "data": getReservesCall}, "latest"},
+	})
+	req, err := http.NewRequestWithContext(ctx, "POST", rpcURL, bytes.NewReader(body))
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return decimal.Decimal{}, fmt.Errorf("RPC error: %d - %s", resp.StatusCode, string(msg))
+	}
+
+	var doc struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return decimal.Decimal{}, err
+	}
+	if doc.Error != nil {
+		return decimal.Decimal{}, fmt.Errorf("RPC error: %s", doc.Error.Message)
+	}
+
+	// The result is reserve0, reserve1 and a timestamp, one 32-byte word each
+	raw, err := hex.DecodeString(strings.TrimPrefix(doc.Result, "0x"))
+	if err != nil || len(raw) < 64 {
+		return decimal.Decimal{}, fmt.Errorf("pool %s: malformed getReserves result", pool.Address)
+	}
+	reserves := [2]*big.Int{new(big.Int).SetBytes(raw[:32]), new(big.Int).SetBytes(raw[32:64])}
+	base, quote := reserves[pool.BaseIndex], reserves[1-pool.BaseIndex]
+	if base.Sign() == 0 {
+		return decimal.Decimal{}, fmt.Errorf("pool %s is empty", pool.Address)
+	}
+
+	b, err := decimal.Parse(base.String() + "e-" + strconv.Itoa(pool.BaseDecimals))
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	q, err := decimal.Parse(quote.String() + "e-" + strconv.Itoa(pool.QuoteDecimals))
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	return q.Quo(b), nil
+}
+
+// NewExchangeSource creates a source pricing each token from an exchange
+// ticker API. tickerURL contains {symbol}, replaced by the token's market
+// symbol from symbols; field is the dotted path of the price in the JSON
+// response, e.g. "data.last". Requests use transport, or a default pooled
+// transport if nil.
+func NewExchangeSource(name, quote string, pricer Provider, tickerURL, field string, symbols map[string]string, timeout time.Duration, transport http.RoundTripper) *Source {
+	client := sourceClient(timeout, transport)
+	s := &Source{name: name, quote: quote, pricer: pricer, tokens: make(map[string]quoter, len(symbols))}
+	for id, symbol := range symbols {
+		u := strings.ReplaceAll(tickerURL, "{symbol}", url.PathEscape(symbol))
+		s.tokens[strings.ToLower(id)] = func(ctx context.Context, tokenID string) (decimal.Decimal, error) {
+			return tickerPrice(ctx, client, u, field)
+		}
+	}
+	return s
+}
+
+// tickerPrice fetches a ticker and reads the price at field
+func tickerPrice(ctx context.Context, client *http.Client, tickerURL, field string) (decimal.Decimal, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", tickerURL, nil)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return decimal.Decimal{}, fmt.Errorf("exchange API error: %d - %s", resp.StatusCode, string(msg))
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return decimal.Decimal{}, err
+	}
+	for _, key := range strings.Split(field, ".") {
+		obj, ok := doc.(map[string]any)
+		if !ok {
+			return decimal.Decimal{}, fmt.Errorf("exchange API response has no %s", field)
+		}
+		doc = obj[key]
+	}
+
+	var text string
+	switch v := doc.(type) {
+	case json.Number:
+		text = v.String()
+	case string:
+		text = v
+	default:
+		return decimal.Decimal{}, fmt.Errorf("exchange API response has no %s", field)
+	}
+	price, err := decimal.Parse(text)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("%s: %w", field, err)
+	}
+	if price.Sign() <= 0 {
+		return decimal.Decimal{}, fmt.Errorf("%s is not positive: %s", field, text)
+	}
+	return price, nil
+}
+
+func sourceClient(timeout time.Duration, transport http.RoundTripper) *http.Client {
+	if transport == nil {
+		transport = NewTransport(DefaultTransportConfig())
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package providers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/internal/testutil"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// fastRetries retries three times in all without waiting noticeably
+var fastRetries = providers.RetryPolicy{Attempts: 3, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+func TestCoinGeckoRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  []testutil.Failure
+		wantErr   bool
+		wantCalls int
+	}{
+		{name: "no failure", wantCalls: 1},
+		{name: "server errors retried", failures: []testutil.Failure{{Status: 503}, {Status: 502}}, wantCalls: 3},
+		{name: "429 retried", failures: []testutil.Failure{{Status: 429}}, wantCalls: 2},
+		{name: "attempts exhausted", failures: []testutil.Failure{{Status: 500}, {Status: 500}, {Status: 500}}, wantErr: true, wantCalls: 3},
+		{name: "client error not retried", failures: []testutil.Failure{{Status: 401}}, wantErr: true, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := testutil.NewUpstream(t)
+			u.FailNext(tt.failures...)
+			cg := u.CoinGecko(providers.NewRetryTransport(http.DefaultTransport, fastRetries))
+
+			price, err := cg.FetchPrice(context.Background(), "bitcoin", "usd")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("FetchPrice = %+v, want an error", price)
+				}
+			} else if err != nil {
+				t.Fatalf("FetchPrice: %v", err)
+			} else if price.ID != "bitcoin" || price.CurrentPrice <= 0 {
+				t.Errorf("price = %+v, want bitcoin", price)
+			}
+			if n := len(u.Requests()); n != tt.wantCalls {
+				t.Errorf("upstream requests = %d, want %d", n, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCoinGeckoRetriesTimeouts(t *testing.T) {
+	u := testutil.NewUpstream(t)
+	u.FailNext(testutil.Failure{Delay: time.Second})
+	cg := u.CoinGecko(providers.NewRetryTransport(&http.Transport{ResponseHeaderTimeout: 50 * time.Millisecond}, fastRetries))
+
+	if _, err := cg.FetchPrice(context.Background(), "bitcoin", "usd"); err != nil {
+		t.Fatalf("FetchPrice after a slow answer: %v", err)
+	}
+	if n := len(u.Requests()); n != 2 {
+		t.Errorf("upstream requests = %d, want 2", n)
+	}
+}
+
+func TestCoinGeckoRateLimited(t *testing.T) {
+	u := testutil.NewUpstream(t)
+	rl := providers.NewRateLimitTransport(http.DefaultTransport, time.Minute)
+	cg := u.CoinGecko(rl)
+
+	u.FailNext(testutil.Failure{Status: http.StatusTooManyRequests, RetryAfter: 30 * time.Second})
+	start := time.Now()
+	_, err := cg.FetchPrice(context.Background(), "bitcoin", "usd")
+	if !errors.Is(err, providers.ErrRateLimited) {
+		t.Fatalf("FetchPrice answered 429: %v, want ErrRateLimited", err)
+	}
+	var rle *providers.RateLimitError
+	if !errors.As(err, &rle) || rle.Until.Sub(start) < 29*time.Second || rle.Until.Sub(start) > 31*time.Second {
+		t.Errorf("error %v, want a pause of the Retry-After 30s", err)
+	}
+
+	// Paused: the next call fails without reaching the upstream
+	if _, err := cg.FetchPrice(context.Background(), "bitcoin", "usd"); !errors.Is(err, providers.ErrRateLimited) {
+		t.Errorf("FetchPrice while paused: %v, want ErrRateLimited", err)
+	}
+	if n := len(u.Requests()); n != 1 {
+		t.Errorf("upstream requests = %d, want 1", n)
+	}
+	status := rl.Status()
+	if len(status) != 1 || status[0].Limited != 1 || status[0].Rejected != 1 || status[0].PausedUntil == nil {
+		t.Errorf("status = %+v, want one paused host limited and rejected once", status)
+	}
+}
+
+func TestCoinGeckoBreaker(t *testing.T) {
+	u := testutil.NewUpstream(t)
+	b := providers.NewBreaker(u.CoinGecko(nil), providers.BreakerPolicy{Failures: 3, Cooldown: 100 * time.Millisecond})
+	ctx := context.Background()
+
+	u.FailAll(&testutil.Failure{Status: http.StatusInternalServerError})
+	for i := 0; i < 3; i++ {
+		if _, err := b.FetchPrice(ctx, "bitcoin", "usd"); err == nil || errors.Is(err, providers.ErrCircuitOpen) {
+			t.Fatalf("call %d: %v, want the upstream error", i+1, err)
+		}
+	}
+	if s := b.Status(); s.State != providers.BreakerOpen || s.Trips != 1 {
+		t.Fatalf("status after 3 failures = %+v, want open after one trip", s)
+	}
+
+	// Open: calls fail fast without reaching the upstream
+	if _, err := b.FetchPrice(ctx, "bitcoin", "usd"); !errors.Is(err, providers.ErrCircuitOpen) {
+		t.Errorf("call while open: %v, want ErrCircuitOpen", err)
+	}
+	if n := len(u.Requests()); n != 3 {
+		t.Errorf("upstream requests = %d, want 3", n)
+	}
+
+	// After the cooldown a successful probe closes the circuit
+	u.FailAll(nil)
+	time.Sleep(150 * time.Millisecond)
+	if s := b.Status(); s.State != providers.BreakerHalfOpen {
+		t.Errorf("state after cooldown = %s, want half-open", s.State)
+	}
+	if _, err := b.FetchPrice(ctx, "bitcoin", "usd"); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if s := b.Status(); s.State != providers.BreakerClosed || s.Failures != 0 {
+		t.Errorf("status after probe = %+v, want closed", s)
+	}
+}
+
+func TestBreakerIgnoresRateLimits(t *testing.T) {
+	u := testutil.NewUpstream(t)
+	rl := providers.NewRateLimitTransport(http.DefaultTransport, time.Minute)
+	b := providers.NewBreaker(u.CoinGecko(rl), providers.BreakerPolicy{Failures: 2, Cooldown: time.Minute})
+
+	u.FailNext(testutil.Failure{Status: http.StatusTooManyRequests})
+	for i := 0; i < 3; i++ {
+		if _, err := b.FetchPrice(context.Background(), "bitcoin", "usd"); !errors.Is(err, providers.ErrRateLimited) {
+			t.Fatalf("call %d: %v, want ErrRateLimited", i+1, err)
+		}
+	}
+	if s := b.Status(); s.State != providers.BreakerClosed || s.Failures != 0 {
+		t.Errorf("status = %+v, want closed: rate limiting says nothing about health", s)
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package quorum prices tokens for settlement only when several providers
+// agree, so a single upstream can't set a signed quote or an on-chain
+// price on its own
+package quorum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// DefaultTolerance is the deviation from the median within which quotes
+// agree when unset
+const DefaultTolerance = 0.01
+
+// ErrNoQuorum is matched by the errors of checks too few providers agree on
+var ErrNoQuorum = errors.New("no quorum")
+
+// Options configures a Checker
+type Options struct {
+	// Min is how many providers must agree on a price
+	Min int
+
+	// Tolerance is the relative deviation from the median of all quotes
+	// within which a quote agrees; DefaultTolerance if 0
+	Tolerance float64
+
+	// TTL is how long an agreed price is reused before providers are asked
+	// again; every check asks them if 0
+	TTL time.Duration
+
+	// Resolve maps a requested token id to the one providers know, such
+	// as an alias to its token, if set
+	Resolve func(string) string
+}
+
+// Result is the outcome of a check
+type Result = wire.QuorumResult
+
+// Error is returned with the result of a check too few providers agree
+// on. It matches ErrNoQuorum.
+type Error struct {
+	Result *Result
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("no quorum for %s/%s: %d of %d providers agree within %g%%, %d required",
+		e.Result.Token, e.Result.Currency, len(e.Result.Agreeing), e.Result.Providers, e.Result.Tolerance*100, e.Result.Required)
+}
+
+// Is reports whether target is ErrNoQuorum
+func (e *Error) Is(target error) bool {
+	return target == ErrNoQuorum
+}
+
+// member is a provider and the token ids it serves
+type member struct {
+	provider providers.Provider
+	ids      map[string]bool // nil means all tokens
+}
+
+// Checker asks every provider serving a token for its price and agrees a
+// price only if enough of them are close
+type Checker struct {
+	opts    Options
+	members []member
+
+	mu     sync.Mutex
+	agreed map[string]*Result // by token/currency
+}
+
+// NewChecker creates a checker. Add providers with AddProvider before the
+// first check.
+func NewChecker(opts Options) *Checker {
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = DefaultTolerance
+	}
+	return &Checker{opts: opts, agreed: make(map[string]*Result)}
+}
+
+// AddProvider counts p for the given token ids, or for every token if
+// none are given. Quotes are told apart by provider name, so a second
+// provider with the same name is refused rather than counted twice.
+func (c *Checker) AddProvider(p providers.Provider, ids ...string) error {
+	for _, m := range c.members {
+		if m.provider.Name() == p.Name() {
+			return fmt.Errorf("provider %s added twice", p.Name())
+		}
+	}
+	m := member{provider: p}
+	if len(ids) > 0 {
+		m.ids = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			m.ids[strings.ToLower(id)] = true
+		}
+	}
+	c.members = append(c.members, m)
+	return nil
+}
+
+// Len returns the number of providers added
+func (c *Checker) Len() int {
+	return len(c.members)
+}
+
+// Min returns how many providers must agree
+func (c *Checker) Min() int {
+	return c.opts.Min
+}
+
+// Check returns a token's price agreed by at least Min providers. If
+// fewer agree, the error is an *Error carrying what each one quoted; if
+// none knows the token, it is providers.ErrTokenNotFound.
+func (c *Checker) Check(ctx context.Context, tokenID, currency string) (*Result, error) {
+	id, currency := strings.ToLower(tokenID), strings.ToLower(currency)
+	if c.opts.Resolve != nil {
+		id = c.opts.Resolve(id)
+	}
+	key := id + "/" + currency
+	if c.opts.TTL > 0 {
+		c.mu.Lock()
+		res, ok := c.agreed[key]
+		c.mu.Unlock()
+		if ok && time.Since(res.CheckedAt) < c.opts.TTL {
+			return res, nil
+		}
+	}
+
+	var serving []providers.Provider
+	for _, m := range c.members {
+		if m.ids == nil || m.ids[id] {
+			serving = append(serving, m.provider)
+		}
+	}
+	res := &Result{
+		Token:     id,
+		Currency:  currency,
+		Quotes:    make(map[string]float64, len(serving)),
+		Agreeing:  []string{},
+		Providers: len(serving),
+		Required:  c.opts.Min,
+		Tolerance: c.opts.Tolerance,
+		CheckedAt: time.Now().UTC(),
+	}
+
+	type quote struct {
+		name  string
+		price *providers.Price
+		err   error
+	}
+	quotes := make([]quote, len(serving))
+	var wg sync.WaitGroup
+	for i, p := range serving {
+		wg.Add(1)
+		go func(i int, p providers.Provider) {
+			defer wg.Done()
+			price, err := p.FetchPrice(ctx, id, currency)
+			quotes[i] = quote{name: p.Name(), price: price, err: err}
+		}(i, p)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	exact := make(map[string]decimal.Decimal, len(quotes))
+	notFound := 0
+	for _, q := range quotes {
+		switch {
+		case q.err != nil:
+			if errors.Is(q.err, providers.ErrTokenNotFound) {
+				notFound++
+			}
+			if res.Errors == nil {
+				res.Errors = make(map[string]string)
+			}
+			res.Errors[q.name] = q.err.Error()
+		case q.price.CurrentPrice <= 0 || math.IsInf(q.price.CurrentPrice, 0) || math.IsNaN(q.price.CurrentPrice):
+			if res.Errors == nil {
+				res.Errors = make(map[string]string)
+			}
+			res.Errors[q.name] = "no price"
+		default:
+			res.Quotes[q.name] = q.price.CurrentPrice
+			exact[q.name] = q.price.Exact()
+		}
+	}
+	if len(serving) > 0 && notFound == len(serving) {
+		return nil, fmt.Errorf("%s: %w", id, providers.ErrTokenNotFound)
+	}
+
+	if len(res.Quotes) > 0 {
+		mid := median(res.Quotes)
+		for name, p := range res.Quotes {
+			if math.Abs(p/mid-1) <= c.opts.Tolerance {
+				res.Agreeing = append(res.Agreeing, name)
+			}
+		}
+		sort.Strings(res.Agreeing)
+	}
+	if len(res.Agreeing) < c.opts.Min || len(res.Agreeing) == 0 {
+		return res, &Error{Result: res}
+	}
+	res.Price = medianExact(res.Agreeing, exact, res.Quotes)
+
+	if c.opts.TTL > 0 {
+		c.mu.Lock()
+		c.agreed[key] = res
+		c.mu.Unlock()
+	}
+	return res, nil
+}
+
+// median returns the median of the quoted prices
+func median(quotes map[string]float64) float64 {
+	prices := make([]float64, 0, len(quotes))
+	for _, p := range quotes {
+		prices = append(prices, p)
+	}
+	sort.Float64s(prices)
+	n := len(prices)
+	if n%2 == 1 {
+		return prices[n/2]
+	}
+	return (prices[n/2-1] + prices[n/2]) / 2
+}
+
+// medianExact returns the exact median of the named providers' quotes,
+// the mean of the middle two if there is an even number
+func medianExact(names []string, exact map[string]decimal.Decimal, quotes map[string]float64) decimal.Decimal {
+	sorted := append([]string(nil), names...)
+	sort.Slice(sorted, func(i, j int) bool { return quotes[sorted[i]] < quotes[sorted[j]] })
+	n := len(sorted)
+	if n%2 == 1 {
+		return exact[sorted[n/2]]
+	}
+	return exact[sorted[n/2-1]].Add(exact[sorted[n/2]]).Quo(decimal.FromFloat(2))
+}
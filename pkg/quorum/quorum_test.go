@@ -0,0 +1,210 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package quorum_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/internal/testutil"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/quorum"
+)
+
+// checker adds a provider per price, named a, b and so on, each quoting
+// bitcoin at its price
+func checker(t *testing.T, opts quorum.Options, prices ...float64) (*quorum.Checker, []*testutil.Provider) {
+	t.Helper()
+	c := quorum.NewChecker(opts)
+	ps := make([]*testutil.Provider, len(prices))
+	for i, price := range prices {
+		ps[i] = testutil.NewProvider(string(rune('a' + i)))
+		ps[i].Set("bitcoin", price)
+		if err := c.AddProvider(ps[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return c, ps
+}
+
+func TestCheckMedian(t *testing.T) {
+	tests := []struct {
+		name     string
+		prices   []float64
+		want     string
+		agreeing []string
+	}{
+		{"odd", []float64{101, 100, 102}, "101", []string{"a", "b", "c"}},
+		{"even", []float64{100, 103, 101, 102}, "101.5", []string{"a", "b", "c", "d"}},
+		// The outlier moves the median of all quotes but is left out of
+		// the agreed price
+		{"outlier", []float64{100, 101, 102, 150}, "101", []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := checker(t, quorum.Options{Min: 2, Tolerance: 0.05}, tt.prices...)
+			res, err := c.Check(context.Background(), "Bitcoin", "USD")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Price.String() != tt.want {
+				t.Errorf("price %s, want %s", res.Price, tt.want)
+			}
+			if !reflect.DeepEqual(res.Agreeing, tt.agreeing) {
+				t.Errorf("agreeing %v, want %v", res.Agreeing, tt.agreeing)
+			}
+			if res.Token != "bitcoin" || res.Currency != "usd" || res.Providers != len(tt.prices) || res.Required != 2 {
+				t.Errorf("result %+v", res)
+			}
+		})
+	}
+}
+
+func TestCheckToleranceEdge(t *testing.T) {
+	// Quotes 25% either side of the median of 100 agree at a tolerance of
+	// 0.25; those just beyond do not. Quarters are exact in binary.
+	c, _ := checker(t, quorum.Options{Min: 1, Tolerance: 0.25}, 75, 100, 125, 74.99, 125.01)
+	res, err := c.Check(context.Background(), "bitcoin", "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(res.Agreeing, want) {
+		t.Errorf("agreeing %v, want %v", res.Agreeing, want)
+	}
+}
+
+func TestCheckNoQuorum(t *testing.T) {
+	c, _ := checker(t, quorum.Options{Min: 3}, 100, 100.5, 120)
+	res, err := c.Check(context.Background(), "bitcoin", "usd")
+	if !errors.Is(err, quorum.ErrNoQuorum) {
+		t.Fatalf("got %v, want ErrNoQuorum", err)
+	}
+	var qe *quorum.Error
+	if !errors.As(err, &qe) {
+		t.Fatalf("error %T is not a *quorum.Error", err)
+	}
+	if qe.Result != res || len(res.Agreeing) != 2 || len(res.Quotes) != 3 {
+		t.Errorf("result %+v, want 2 of 3 quotes agreeing", qe.Result)
+	}
+}
+
+func TestCheckNotFound(t *testing.T) {
+	c, _ := checker(t, quorum.Options{Min: 1}, 100, 100)
+	_, err := c.Check(context.Background(), "dogecoin", "usd")
+	if !errors.Is(err, providers.ErrTokenNotFound) {
+		t.Errorf("got %v, want ErrTokenNotFound", err)
+	}
+	if errors.Is(err, quorum.ErrNoQuorum) {
+		t.Error("a token no provider knows is reported as no quorum")
+	}
+}
+
+func TestCheckPartlyNotFound(t *testing.T) {
+	c, ps := checker(t, quorum.Options{Min: 2}, 100, 100, 100)
+	ps[2].Remove("bitcoin")
+	res, err := c.Check(context.Background(), "bitcoin", "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Agreeing) != 2 || res.Errors["c"] == "" {
+		t.Errorf("result %+v, want a and b agreeing and c's error recorded", res)
+	}
+}
+
+func TestCheckUnusableQuotes(t *testing.T) {
+	c, _ := checker(t, quorum.Options{Min: 2}, 100, math.NaN(), math.Inf(1), 0, -5, 100)
+	res, err := c.Check(context.Background(), "bitcoin", "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "f"}; !reflect.DeepEqual(res.Agreeing, want) {
+		t.Errorf("agreeing %v, want %v", res.Agreeing, want)
+	}
+	for _, name := range []string{"b", "c", "d", "e"} {
+		if _, ok := res.Quotes[name]; ok || res.Errors[name] != "no price" {
+			t.Errorf("%s: quote %v, error %q; want it left out as no price", name, res.Quotes[name], res.Errors[name])
+		}
+	}
+
+	// Unusable quotes alone are no quorum rather than a price
+	c, _ = checker(t, quorum.Options{Min: 1}, math.NaN(), 0)
+	if _, err := c.Check(context.Background(), "bitcoin", "usd"); !errors.Is(err, quorum.ErrNoQuorum) {
+		t.Errorf("only unusable quotes: got %v, want ErrNoQuorum", err)
+	}
+}
+
+func TestCheckTTL(t *testing.T) {
+	c, ps := checker(t, quorum.Options{Min: 2, TTL: time.Minute}, 100, 100)
+	ctx := context.Background()
+	first, err := c.Check(ctx, "bitcoin", "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps[0].Set("bitcoin", 200)
+	ps[1].Set("bitcoin", 200)
+
+	again, err := c.Check(ctx, "BITCOIN", "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != first || ps[0].Calls() != 1 {
+		t.Errorf("second check asked the providers again (%d calls), want the agreed price reused", ps[0].Calls())
+	}
+	if res, err := c.Check(ctx, "bitcoin", "eur"); err != nil || res.Price.String() != "200" {
+		t.Errorf("eur: %v, %v; want a fresh check at 200", res, err)
+	}
+}
+
+func TestCheckNoQuorumNotReused(t *testing.T) {
+	c, ps := checker(t, quorum.Options{Min: 2, TTL: time.Minute}, 100, 200)
+	ctx := context.Background()
+	if _, err := c.Check(ctx, "bitcoin", "usd"); !errors.Is(err, quorum.ErrNoQuorum) {
+		t.Fatalf("got %v, want ErrNoQuorum", err)
+	}
+	ps[1].Set("bitcoin", 100)
+	if _, err := c.Check(ctx, "bitcoin", "usd"); err != nil {
+		t.Errorf("after the providers agree: %v", err)
+	}
+}
+
+func TestCheckProvidersByToken(t *testing.T) {
+	c := quorum.NewChecker(quorum.Options{Min: 2})
+	all, lux := testutil.NewProvider("all"), testutil.NewProvider("lux")
+	all.Set("bitcoin", 100)
+	all.Set("lux", 1)
+	lux.Set("lux", 1)
+	lux.Set("bitcoin", 500)
+	for _, err := range []error{c.AddProvider(all), c.AddProvider(lux, "LUX")} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := c.Check(context.Background(), "lux", "usd")
+	if err != nil || res.Providers != 2 {
+		t.Errorf("lux: %+v, %v; want both providers agreeing", res, err)
+	}
+	// Only "all" serves bitcoin, so its quote alone is short of Min
+	res, err = c.Check(context.Background(), "bitcoin", "usd")
+	if !errors.Is(err, quorum.ErrNoQuorum) || res.Providers != 1 || lux.Calls() != 1 {
+		t.Errorf("bitcoin: %+v, %v; want no quorum with lux not asked", res, err)
+	}
+}
+
+func TestAddProviderRejectsDuplicateNames(t *testing.T) {
+	c := quorum.NewChecker(quorum.Options{Min: 2})
+	if err := c.AddProvider(testutil.NewProvider("coingecko")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddProvider(testutil.NewProvider("coingecko"), "bitcoin"); err == nil {
+		t.Error("a second provider named coingecko was added")
+	}
+	if c.Len() != 1 {
+		t.Errorf("%d providers, want 1", c.Len())
+	}
+}
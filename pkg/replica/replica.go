@@ -0,0 +1,132 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package replica lets secondary instances serve prices refreshed by a
+// primary, so instances in several regions share one upstream quota
+package replica
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/client"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// Roles an instance may take
+const (
+	Primary   = "primary"   // fetches from upstream and publishes its refreshes
+	Secondary = "secondary" // applies the primary's refreshes and asks it for misses
+)
+
+// ErrClosed is returned by an Upstream's fetches once it is closed
+var ErrClosed = errors.New("upstream closed")
+
+// Upstream is the provider of a secondary: instead of asking upstream
+// providers, it asks the primary's API, whose cache answers if it can and
+// whose refreshes reach every secondary
+type Upstream struct {
+	client *client.Client
+	http   *http.Client
+	closed atomic.Bool
+}
+
+// NewUpstream creates a provider asking the primary at baseURL, as the
+// tenant of apiKey if set. Requests use transport, or a default pooled
+// transport if nil.
+func NewUpstream(baseURL, apiKey string, timeout time.Duration, transport http.RoundTripper) *Upstream {
+	if transport == nil {
+		transport = providers.NewTransport(providers.DefaultTransportConfig())
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	hc := &http.Client{Timeout: timeout, Transport: transport}
+	// The cache falls back on stale prices; retrying only holds up reads
+	return &Upstream{client: client.New(baseURL, apiKey, client.WithHTTPClient(hc), client.WithRetries(0, 0, 0)), http: hc}
+}
+
+// Close stops the upstream asking the primary and closes its idle
+// connections. Fetches after Close fail with ErrClosed.
+func (u *Upstream) Close() {
+	u.closed.Store(true)
+	u.http.CloseIdleConnections()
+}
+
+// Name identifies the provider
+func (u *Upstream) Name() string {
+	return Primary
+}
+
+// FetchPrice asks the primary for one token's price
+func (u *Upstream) FetchPrice(ctx context.Context, tokenID, currency string) (*providers.Price, error) {
+	if u.closed.Load() {
+		return nil, fmt.Errorf("primary: %w", ErrClosed)
+	}
+	resp, err := u.client.Price(ctx, tokenID, currency)
+	if err != nil {
+		var apiErr *client.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return nil, &providers.NotFoundError{Token: tokenID}
+		}
+		return nil, fmt.Errorf("primary: %w", err)
+	}
+	price := toPrice(resp)
+	return &price, nil
+}
+
+// FetchPrices asks the primary for several prices. Tokens the primary
+// doesn't know are omitted; tokens it has no fresh price for are omitted
+// too and reported in the error, so the cache keeps its own stale price.
+func (u *Upstream) FetchPrices(ctx context.Context, tokenIDs []string, currency string) ([]providers.Price, error) {
+	if u.closed.Load() {
+		return nil, fmt.Errorf("primary: %w", ErrClosed)
+	}
+	resp, err := u.client.Prices(ctx, tokenIDs, currency)
+	if err != nil {
+		return nil, fmt.Errorf("primary: %w", err)
+	}
+	prices := make([]providers.Price, 0, len(resp.Prices))
+	var failed []string
+	for id, p := range resp.Prices {
+		if p == nil {
+			continue
+		}
+		if resp.Errors[id] != nil {
+			failed = append(failed, id)
+			continue
+		}
+		p.ID = id
+		prices = append(prices, toPrice(p))
+	}
+	for id, e := range resp.Errors {
+		if e.Code != cache.CodeNotFound && resp.Prices[id] == nil {
+			failed = append(failed, id)
+		}
+	}
+	if len(failed) > 0 {
+		return prices, fmt.Errorf("primary: no fresh price for %d of %d tokens", len(failed), len(tokenIDs))
+	}
+	return prices, nil
+}
+
+// toPrice converts a price served by the primary to a provider price
+func toPrice(p *cache.PriceResponse) providers.Price {
+	return providers.Price{
+		ID:                       p.ID,
+		Symbol:                   p.Symbol,
+		Name:                     p.Name,
+		CurrentPrice:             p.Price,
+		CurrentPriceText:         p.PriceStr,
+		MarketCap:                p.MarketCap,
+		TotalVolume:              p.Volume24h,
+		PriceChangePercentage24h: p.Change24h,
+		LastUpdated:              p.UpdatedAt.UTC().Format(time.RFC3339),
+		Source:                   p.Source,
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package replica_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/internal/testutil"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/replica"
+)
+
+func TestUpstreamFetchPrice(t *testing.T) {
+	primary := testutil.NewServer(t)
+	primary.Provider.Set("bitcoin", 65000)
+	u := replica.NewUpstream(primary.URL, "", time.Second, nil)
+	defer u.Close()
+
+	p, err := u.FetchPrice(context.Background(), "bitcoin", "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != "bitcoin" || p.CurrentPrice != 65000 {
+		t.Errorf("got %s at %v, want bitcoin at 65000", p.ID, p.CurrentPrice)
+	}
+
+	_, err = u.FetchPrice(context.Background(), "dogecoin", "usd")
+	var notFound *providers.NotFoundError
+	if !errors.As(err, &notFound) || notFound.Token != "dogecoin" {
+		t.Errorf("unknown token: got %v, want a not found error", err)
+	}
+}
+
+func TestUpstreamFetchPricesOmitsUnknown(t *testing.T) {
+	primary := testutil.NewServer(t)
+	primary.Provider.Set("bitcoin", 65000)
+	primary.Provider.Set("ethereum", 3500)
+	u := replica.NewUpstream(primary.URL, "", time.Second, nil)
+	defer u.Close()
+
+	prices, err := u.FetchPrices(context.Background(), []string{"bitcoin", "ethereum", "dogecoin"}, "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]float64)
+	for _, p := range prices {
+		got[p.ID] = p.CurrentPrice
+	}
+	if len(got) != 2 || got["bitcoin"] != 65000 || got["ethereum"] != 3500 {
+		t.Errorf("got %v, want bitcoin and ethereum only", got)
+	}
+}
+
+func TestUpstreamFetchPricesReportsFailures(t *testing.T) {
+	primary := testutil.NewServer(t)
+	primary.Provider.FailAll(errors.New("upstream down"))
+	u := replica.NewUpstream(primary.URL, "", time.Second, nil)
+	defer u.Close()
+
+	if _, err := u.FetchPrices(context.Background(), []string{"bitcoin"}, "usd"); err == nil {
+		t.Error("got no error while the primary has no price")
+	}
+}
+
+func TestUpstreamClose(t *testing.T) {
+	primary := testutil.NewServer(t)
+	primary.Provider.Set("bitcoin", 65000)
+	u := replica.NewUpstream(primary.URL, "", time.Second, nil)
+	if _, err := u.FetchPrice(context.Background(), "bitcoin", "usd"); err != nil {
+		t.Fatal(err)
+	}
+	calls := primary.Provider.Calls()
+
+	u.Close()
+	if _, err := u.FetchPrice(context.Background(), "ethereum", "usd"); !errors.Is(err, replica.ErrClosed) {
+		t.Errorf("FetchPrice after Close: got %v, want ErrClosed", err)
+	}
+	if _, err := u.FetchPrices(context.Background(), []string{"ethereum"}, "usd"); !errors.Is(err, replica.ErrClosed) {
+		t.Errorf("FetchPrices after Close: got %v, want ErrClosed", err)
+	}
+	if n := primary.Provider.Calls(); n != calls {
+		t.Errorf("primary asked %d more times after Close", n-calls)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package report
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/luxfi/pricing/pkg/markets"
+)
+
+// funcs are the helpers shared by the Markdown and HTML templates
+var funcs = map[string]interface{}{
+	"upper":   strings.ToUpper,
+	"title":   func(s string) string { return strings.ToUpper(s[:1]) + s[1:] },
+	"date":    func(r *Report) string { return r.To.Format("2006-01-02") },
+	"amount":  amount,
+	"price":   price,
+	"percent": func(p float64) string { return strconv.FormatFloat(p, 'f', 2, 64) + "%" },
+	"signed":  signed,
+	"mul100":  func(f float64) float64 { return f * 100 },
+	"change": func(r *Report, a markets.MarketAsset) string {
+		if r.Period == Weekly {
+			return signed(a.Change7d)
+		}
+		return signed(a.Change24h)
+	},
+	"window": func(r *Report) string {
+		if r.Period == Weekly {
+			return "7d"
+		}
+		return "24h"
+	},
+	"inc": func(i int) int { return i + 1 },
+}
+
+const markdownTemplate = `# {{title .Period}} market report, {{date .}}
+{{with .Market}}
+Total market cap {{amount .TotalMarketCap}} {{upper .Currency}} ({{signed .MarketCapChange24h}} in 24h), 24h volume {{amount .TotalVolume24h}} {{upper .Currency}}, BTC dominance {{percent .BTCDominance}}, ETH dominance {{percent .ETHDominance}}.
+{{end}}
+## Top gainers ({{window .}})
+{{if .Gainers}}
+| # | Token | Price | Change |
+|---|-------|-------|--------|
+{{range .Gainers}}| {{.Rank}} | {{.Name}} ({{upper .Symbol}}) | {{price .Price}} {{upper $.Currency}} | {{change $ .}} |
+{{end}}{{else}}
+No gainers.
+{{end}}
+## Top losers ({{window .}})
+{{if .Losers}}
+| # | Token | Price | Change |
+|---|-------|-------|--------|
+{{range .Losers}}| {{.Rank}} | {{.Name}} ({{upper .Symbol}}) | {{price .Price}} {{upper $.Currency}} | {{change $ .}} |
+{{end}}{{else}}
+No losers.
+{{end}}{{if .Trending}}
+## Trending
+{{range $i, $c := .Trending}}
+{{inc $i}}. {{$c.Name}} ({{upper $c.Symbol}}){{end}}
+{{end}}{{if .Stablecoins}}
+## Stablecoins
+{{range .Stablecoins}}
+- {{upper .Symbol}} {{price .Price}} USD ({{signed (mul100 .Deviation)}} from peg){{if .Depegged}} **depegged**{{end}}{{end}}
+{{end}}`
+
+const htmlTemplate = `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{title .Period}} market report, {{date .}}</title></head>
+<body>
+<h1>{{title .Period}} market report, {{date .}}</h1>
+{{with .Market}}<p>Total market cap {{amount .TotalMarketCap}} {{upper .Currency}} ({{signed .MarketCapChange24h}} in 24h), 24h volume {{amount .TotalVolume24h}} {{upper .Currency}}, BTC dominance {{percent .BTCDominance}}, ETH dominance {{percent .ETHDominance}}.</p>
+{{end}}<h2>Top gainers ({{window .}})</h2>
+{{if .Gainers}}<table>
+<tr><th>#</th><th>Token</th><th>Price</th><th>Change</th></tr>
+{{range .Gainers}}<tr><td>{{.Rank}}</td><td>{{.Name}} ({{upper .Symbol}})</td><td>{{price .Price}} {{upper $.Currency}}</td><td>{{change $ .}}</td></tr>
+{{end}}</table>
+{{else}}<p>No gainers.</p>
+{{end}}<h2>Top losers ({{window .}})</h2>
+{{if .Losers}}<table>
+<tr><th>#</th><th>Token</th><th>Price</th><th>Change</th></tr>
+{{range .Losers}}<tr><td>{{.Rank}}</td><td>{{.Name}} ({{upper .Symbol}})</td><td>{{price .Price}} {{upper $.Currency}}</td><td>{{change $ .}}</td></tr>
+{{end}}</table>
+{{else}}<p>No losers.</p>
+{{end}}{{if .Trending}}<h2>Trending</h2>
+<ol>
+{{range .Trending}}<li>{{.Name}} ({{upper .Symbol}})</li>
+{{end}}</ol>
+{{end}}{{if .Stablecoins}}<h2>Stablecoins</h2>
+<ul>
+{{range .Stablecoins}}<li>{{upper .Symbol}} {{price .Price}} USD ({{signed (mul100 .Deviation)}} from peg){{if .Depegged}} <strong>depegged</strong>{{end}}</li>
+{{end}}</ul>
+{{end}}</body></html>
+`
+
+var (
+	markdown = template.Must(template.New("markdown").Funcs(funcs).Parse(markdownTemplate))
+	html     = htmltemplate.Must(htmltemplate.New("html").Funcs(funcs).Parse(htmlTemplate))
+)
+
+// Markdown renders r as Markdown, also used for chat channels
+func Markdown(r *Report) string {
+	var buf bytes.Buffer
+	if err := markdown.Execute(&buf, r); err != nil {
+		return err.Error()
+	}
+	return buf.String()
+}
+
+// HTML renders r as a standalone HTML page
+func HTML(r *Report) string {
+	var buf bytes.Buffer
+	if err := html.Execute(&buf, r); err != nil {
+		return err.Error()
+	}
+	return buf.String()
+}
+
+// amount abbreviates large values: 2.50T, 90.00B, 1.23M
+func amount(v float64) string {
+	for _, u := range []struct {
+		div    float64
+		suffix string
+	}{{1e12, "T"}, {1e9, "B"}, {1e6, "M"}, {1e3, "K"}} {
+		if v >= u.div || v <= -u.div {
+			return strconv.FormatFloat(v/u.div, 'f', 2, 64) + u.suffix
+		}
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// price prints a price with enough precision for sub-cent tokens
+func price(p float64) string {
+	if p >= 1 {
+		return strconv.FormatFloat(p, 'f', 2, 64)
+	}
+	return strconv.FormatFloat(p, 'g', 4, 64)
+}
+
+// signed prints a percentage with its sign, e.g. +3.10%
+func signed(p float64) string {
+	s := strconv.FormatFloat(p, 'f', 2, 64) + "%"
+	if p >= 0 {
+		s = "+" + s
+	}
+	return s
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package report generates daily or weekly market summaries.
+package report
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/global"
+	"github.com/luxfi/pricing/pkg/markets"
+	"github.com/luxfi/pricing/pkg/stablecoins"
+	"github.com/luxfi/pricing/pkg/trending"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// Report periods
+const (
+	Daily  = "daily"
+	Weekly = "weekly"
+)
+
+// DefaultMovers is how many gainers and losers a report lists
+const DefaultMovers = 5
+
+// Report summarizes the market over a period
+type Report = wire.MarketReport
+
+// Options configures a Generator. Markets is required; the other sources
+// are left out of reports if nil or failing.
+type Options struct {
+	Period   string // Daily (default) or Weekly
+	Hour     int    // UTC hour scheduled reports are generated at
+	Currency string // usd if empty
+	Movers   int    // gainers and losers listed, DefaultMovers if zero
+
+	Markets     func(ctx context.Context, currency string, limit int) (*markets.List, error)
+	Global      func(ctx context.Context, currency string) (*global.Overview, error)
+	Trending    func(ctx context.Context) (*trending.List, error)
+	Stablecoins func() []stablecoins.Status
+
+	// Deliver receives every scheduled report
+	Deliver func(r *Report)
+}
+
+// Generator builds reports and keeps the latest one
+type Generator struct {
+	opts Options
+
+	mu     sync.Mutex
+	latest *Report
+}
+
+// NewGenerator creates a report generator
+func NewGenerator(opts Options) *Generator {
+	if opts.Period == "" {
+		opts.Period = Daily
+	}
+	if opts.Currency == "" {
+		opts.Currency = "usd"
+	}
+	if opts.Movers <= 0 {
+		opts.Movers = DefaultMovers
+	}
+	return &Generator{opts: opts}
+}
+
+// Period returns the report period
+func (g *Generator) Period() string {
+	return g.opts.Period
+}
+
+// Latest returns the most recent report, generating one if there is none
+// or the last is older than a period
+func (g *Generator) Latest(ctx context.Context) (*Report, error) {
+	g.mu.Lock()
+	latest := g.latest
+	g.mu.Unlock()
+	if latest != nil && time.Since(latest.To) < periodLength(g.opts.Period) {
+		return latest, nil
+	}
+	return g.Generate(ctx)
+}
+
+// Generate builds a report covering the period up to now and makes it the
+// latest
+func (g *Generator) Generate(ctx context.Context) (*Report, error) {
+	now := time.Now().UTC()
+	list, err := g.opts.Markets(ctx, g.opts.Currency, markets.MaxLimit)
+	if err != nil {
+		return nil, fmt.Errorf("markets: %w", err)
+	}
+
+	r := &Report{
+		Period:   g.opts.Period,
+		Currency: g.opts.Currency,
+		From:     now.Add(-periodLength(g.opts.Period)),
+		To:       now,
+		Universe: len(list.Assets),
+	}
+	r.Gainers, r.Losers = movers(list.Assets, g.opts.Period, g.opts.Movers)
+
+	if g.opts.Global != nil {
+		if overview, err := g.opts.Global(ctx, g.opts.Currency); err == nil {
+			r.Market = overview
+		} else {
+			log.Printf("Report: market overview: %v", err)
+		}
+	}
+	if g.opts.Trending != nil {
+		if t, err := g.opts.Trending(ctx); err == nil {
+			r.Trending = t.Coins
+		} else {
+			log.Printf("Report: trending: %v", err)
+		}
+	}
+	if g.opts.Stablecoins != nil {
+		r.Stablecoins = g.opts.Stablecoins()
+	}
+
+	g.mu.Lock()
+	g.latest = r
+	g.mu.Unlock()
+	return r, nil
+}
+
+// Run generates and delivers a report at every scheduled time until ctx
+// is done
+func (g *Generator) Run(ctx context.Context) {
+	for {
+		now := time.Now()
+		timer := time.NewTimer(Next(g.opts.Period, g.opts.Hour, now).Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		r, err := g.Generate(ctx)
+		if err != nil {
+			log.Printf("Report generation failed: %v", err)
+			continue
+		}
+		log.Printf("Generated %s report", r.Period)
+		if g.opts.Deliver != nil {
+			g.opts.Deliver(r)
+		}
+	}
+}
+
+// Next returns the first scheduled report time after t: the next hour:00
+// UTC for daily reports, and the next Monday at hour:00 UTC for weekly
+func Next(period string, hour int, t time.Time) time.Time {
+	t = t.UTC()
+	next := time.Date(t.Year(), t.Month(), t.Day(), hour, 0, 0, 0, time.UTC)
+	if period == Weekly {
+		next = next.AddDate(0, 0, (int(time.Monday)-int(next.Weekday())+7)%7)
+		if !next.After(t) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	}
+	if !next.After(t) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// periodLength is the span a report covers
+func periodLength(period string) time.Duration {
+	if period == Weekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// movers returns the n largest gainers and losers by change over the
+// period
+func movers(assets []markets.MarketAsset, period string, n int) (gainers, losers []markets.MarketAsset) {
+	change := func(a markets.MarketAsset) float64 {
+		if period == Weekly {
+			return a.Change7d
+		}
+		return a.Change24h
+	}
+
+	ranked := append([]markets.MarketAsset(nil), assets...)
+	sort.SliceStable(ranked, func(i, j int) bool { return change(ranked[i]) > change(ranked[j]) })
+	for i := 0; i < len(ranked) && len(gainers) < n && change(ranked[i]) > 0; i++ {
+		gainers = append(gainers, ranked[i])
+	}
+	for i := len(ranked) - 1; i >= 0 && len(losers) < n && change(ranked[i]) < 0; i-- {
+		losers = append(losers, ranked[i])
+	}
+	return gainers, losers
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package risk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GoPlusURL is the GoPlus security API root
+const GoPlusURL = "https://api.gopluslabs.io/api/v1"
+
+// ChainIDs maps chain names to the EVM chain ids GoPlus knows them by
+var ChainIDs = map[string]string{
+	"ethereum":  "1",
+	"bsc":       "56",
+	"polygon":   "137",
+	"arbitrum":  "42161",
+	"optimism":  "10",
+	"base":      "8453",
+	"avalanche": "43114",
+}
+
+// Contract is the contract of a token, on a chain named in ChainIDs or
+// given by its chain id
+type Contract struct {
+	Chain   string
+	Address string
+}
+
+// ChainID returns the chain id of the contract's chain, if it is known
+func (c Contract) ChainID() (string, bool) {
+	if id, ok := ChainIDs[strings.ToLower(c.Chain)]; ok {
+		return id, true
+	}
+	if _, err := strconv.ParseUint(c.Chain, 10, 64); err == nil {
+		return c.Chain, true
+	}
+	return "", false
+}
+
+// tokenSecurity is a contract's entry of GoPlus's token_security. Flags
+// are "1" if set, "0" if not and empty if unknown.
+type tokenSecurity struct {
+	IsHoneypot           string `json:"is_honeypot"`
+	CannotSellAll        string `json:"cannot_sell_all"`
+	IsOpenSource         string `json:"is_open_source"`
+	HiddenOwner          string `json:"hidden_owner"`
+	Selfdestruct         string `json:"selfdestruct"`
+	OwnerChangeBalance   string `json:"owner_change_balance"`
+	CanTakeBackOwnership string `json:"can_take_back_ownership"`
+	SlippageModifiable   string `json:"slippage_modifiable"`
+	BuyTax               string `json:"buy_tax"`
+	SellTax              string `json:"sell_tax"`
+}
+
+// flags turns a contract's security report into flags. Blacklists and
+// pausing aren't flagged: the largest stablecoins have both.
+func (t tokenSecurity) flags(maxTax float64) []Flag {
+	var flags []Flag
+	if t.IsHoneypot == "1" || t.CannotSellAll == "1" {
+		flags = append(flags, Flag{Type: Honeypot, Severity: Danger, Detail: "the token can't be sold, or not in full"})
+	}
+
+	var reasons []string
+	if t.IsOpenSource == "0" {
+		reasons = append(reasons, "source not verified")
+	}
+	if t.HiddenOwner == "1" {
+		reasons = append(reasons, "hidden owner")
+	}
+	if t.Selfdestruct == "1" {
+		reasons = append(reasons, "can self-destruct")
+	}
+	if t.OwnerChangeBalance == "1" {
+		reasons = append(reasons, "owner can change balances")
+	}
+	if t.CanTakeBackOwnership == "1" {
+		reasons = append(reasons, "ownership can be taken back")
+	}
+	if t.SlippageModifiable == "1" {
+		reasons = append(reasons, "taxes can be changed")
+	}
+	for _, tax := range []struct{ side, value string }{{"buy", t.BuyTax}, {"sell", t.SellTax}} {
+		if v, err := strconv.ParseFloat(tax.value, 64); err == nil && v > maxTax {
+			reasons = append(reasons, fmt.Sprintf("%s tax %.0f%%", tax.side, v*100))
+		}
+	}
+	if len(reasons) > 0 {
+		flags = append(flags, Flag{Type: ContractRisk, Severity: Warning, Detail: strings.Join(reasons, ", ")})
+	}
+	return flags
+}
+
+// checkContracts asks the security API about every configured contract,
+// one request per chain, returning the flags of each token checked
+func (c *Checker) checkContracts(ctx context.Context) (map[string][]Flag, error) {
+	byChain := make(map[string]map[string]string) // chain id -> address -> token id
+	for id, contract := range c.opts.Contracts {
+		chain, ok := contract.ChainID()
+		if !ok {
+			continue
+		}
+		if byChain[chain] == nil {
+			byChain[chain] = make(map[string]string)
+		}
+		byChain[chain][strings.ToLower(contract.Address)] = id
+	}
+
+	out := make(map[string][]Flag)
+	var errs []error
+	for chain, tokens := range byChain {
+		addrs := make([]string, 0, len(tokens))
+		for addr := range tokens {
+			addrs = append(addrs, addr)
+		}
+		sort.Strings(addrs)
+		reports, err := c.tokenSecurity(ctx, chain, addrs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("token security on chain %s: %w", chain, err))
+			continue
+		}
+		for addr, id := range tokens {
+			if report, ok := reports[addr]; ok {
+				out[id] = report.flags(c.opts.MaxTax)
+			}
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+// tokenSecurity fetches the security reports of contracts on a chain,
+// keyed by lowercase address
+func (c *Checker) tokenSecurity(ctx context.Context, chain string, addrs []string) (map[string]tokenSecurity, error) {
+	u := fmt.Sprintf("%s/token_security/%s?contract_addresses=%s", c.opts.SecurityURL, url.PathEscape(chain), url.QueryEscape(strings.Join(addrs, ",")))
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var body struct {
+		Code    int                      `json:"code"`
+		Message string                   `json:"message"`
+		Result  map[string]tokenSecurity `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	if body.Code != 1 {
+		return nil, fmt.Errorf("code %d: %s", body.Code, body.Message)
+	}
+	reports := make(map[string]tokenSecurity, len(body.Result))
+	for addr, report := range body.Result {
+		reports[strings.ToLower(addr)] = report
+	}
+	return reports, nil
+}
@@ -0,0 +1,316 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package risk flags tokens wallets should warn about before a swap:
+// contracts reported as honeypots or otherwise risky, extreme volatility,
+// thin trading and recent stablecoin depegs.
+package risk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/stablecoins"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// Defaults of Options
+const (
+	DefaultVolatility = 1.5    // annualized 30-day volatility, 150%
+	DefaultMinVolume  = 100000 // 24h USD volume
+	DefaultMaxTax     = 0.1    // buy or sell tax of a token contract
+)
+
+// Flag types
+const (
+	Honeypot          = "honeypot"           // the token can be bought but not sold
+	ContractRisk      = "contract_risk"      // the contract lets its owner harm holders
+	ExtremeVolatility = "extreme_volatility" // 30-day volatility above the threshold
+	LowLiquidity      = "low_liquidity"      // 24h volume below the minimum
+	RecentDepeg       = "recent_depeg"       // a stablecoin off its peg recently
+)
+
+// Severities of flags
+const (
+	Warning = "warning"
+	Danger  = "danger"
+)
+
+// Flag is a reason to warn about a token
+type Flag = wire.RiskFlag
+
+// TokenFlags are the flags of a token
+type TokenFlags = wire.RiskTokenFlags
+
+// Feed lists every flagged token
+type Feed = wire.RiskFeed
+
+// Listing is a scanned token and its 24h trading volume in USD
+type Listing struct {
+	ID        string
+	Volume24h float64
+}
+
+// Lister lists the tokens scanned, e.g. the largest by market cap
+type Lister func(ctx context.Context) ([]Listing, error)
+
+// VolatilityFunc returns a token's annualized 30-day volatility, or false
+// if its history is too short
+type VolatilityFunc func(ctx context.Context, tokenID string) (float64, bool, error)
+
+// Options configures a Checker
+type Options struct {
+	// Contracts maps token ids to the contract checked for honeypot and
+	// other contract risks
+	Contracts map[string]Contract
+
+	// SecurityURL is the root of a GoPlus-compatible token security API;
+	// GoPlusURL if empty
+	SecurityURL string
+
+	// Volatility is the annualized 30-day volatility flagged;
+	// DefaultVolatility if 0
+	Volatility float64
+
+	// MinVolume is the 24h USD volume below which trading is thin;
+	// DefaultMinVolume if 0
+	MinVolume float64
+
+	// MaxTax is the buy or sell tax flagged; DefaultMaxTax if 0
+	MaxTax float64
+
+	// Pegs flags recent depegs of the stablecoins it monitors, if set
+	Pegs *stablecoins.Monitor
+}
+
+// scanned is what a scan learned about a token
+type scanned struct {
+	volume     float64  // 24h USD volume, 0 if unknown
+	volatility *float64 // nil if unknown
+	contract   []Flag
+}
+
+// Checker periodically scans tokens for risks and flags them
+type Checker struct {
+	opts       Options
+	list       Lister
+	volatility VolatilityFunc
+	client     *http.Client
+
+	mu        sync.RWMutex
+	tokens    map[string]*scanned
+	checkedAt time.Time
+}
+
+// NewChecker creates a checker scanning the tokens list returns, and those
+// with contracts, measuring volatility with volatility. Contracts are
+// checked with client, or a default client if nil.
+func NewChecker(opts Options, list Lister, volatility VolatilityFunc, client *http.Client) *Checker {
+	if opts.SecurityURL == "" {
+		opts.SecurityURL = GoPlusURL
+	}
+	opts.SecurityURL = strings.TrimRight(opts.SecurityURL, "/")
+	if opts.Volatility <= 0 {
+		opts.Volatility = DefaultVolatility
+	}
+	if opts.MinVolume <= 0 {
+		opts.MinVolume = DefaultMinVolume
+	}
+	if opts.MaxTax <= 0 {
+		opts.MaxTax = DefaultMaxTax
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	contracts := make(map[string]Contract, len(opts.Contracts))
+	for id, contract := range opts.Contracts {
+		contracts[strings.ToLower(id)] = contract
+	}
+	opts.Contracts = contracts
+	return &Checker{
+		opts:       opts,
+		list:       list,
+		volatility: volatility,
+		client:     client,
+		tokens:     make(map[string]*scanned),
+	}
+}
+
+// Flags returns a token's flags, worst first. volumeUSD is its current
+// 24h volume in USD, or 0 to use the volume of the last scan.
+func (c *Checker) Flags(tokenID string, volumeUSD float64) []Flag {
+	tokenID = strings.ToLower(tokenID)
+	c.mu.RLock()
+	s := c.tokens[tokenID]
+	c.mu.RUnlock()
+
+	var flags []Flag
+	if s != nil {
+		flags = append(flags, s.contract...)
+		if v := s.volatility; v != nil && *v > c.opts.Volatility {
+			flags = append(flags, Flag{
+				Type:     ExtremeVolatility,
+				Severity: Warning,
+				Detail:   fmt.Sprintf("30-day volatility is %.0f%% annualized", *v*100),
+			})
+		}
+		if volumeUSD <= 0 {
+			volumeUSD = s.volume
+		}
+	}
+	if volumeUSD > 0 && volumeUSD < c.opts.MinVolume {
+		flags = append(flags, Flag{
+			Type:     LowLiquidity,
+			Severity: Warning,
+			Detail:   fmt.Sprintf("24h volume is $%.0f", volumeUSD),
+		})
+	}
+	if c.opts.Pegs != nil {
+		if sample, depegged, ok := c.opts.Pegs.LastDepeg(tokenID); ok {
+			f := Flag{Type: RecentDepeg, Severity: Warning}
+			if depegged {
+				f.Severity = Danger
+				f.Detail = fmt.Sprintf("off its peg by %.2f%%", sample.Deviation*100)
+			} else {
+				f.Detail = fmt.Sprintf("off its peg by %.2f%% at %s", sample.Deviation*100, sample.Time.Format(time.RFC3339))
+			}
+			flags = append(flags, f)
+		}
+	}
+	sort.SliceStable(flags, func(i, j int) bool {
+		return flags[i].Severity == Danger && flags[j].Severity != Danger
+	})
+	return flags
+}
+
+// Feed returns every flagged token scanned or monitored, the most
+// dangerous first
+func (c *Checker) Feed() Feed {
+	c.mu.RLock()
+	ids := make([]string, 0, len(c.tokens))
+	for id := range c.tokens {
+		ids = append(ids, id)
+	}
+	feed := Feed{Tokens: []TokenFlags{}, CheckedAt: c.checkedAt}
+	c.mu.RUnlock()
+	if c.opts.Pegs != nil {
+		for _, st := range c.opts.Pegs.Snapshot(false) {
+			ids = append(ids, st.ID)
+		}
+	}
+
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if flags := c.Flags(id, 0); len(flags) > 0 {
+			feed.Tokens = append(feed.Tokens, TokenFlags{Token: id, Flags: flags})
+		}
+	}
+	sort.Slice(feed.Tokens, func(i, j int) bool {
+		a, b := feed.Tokens[i], feed.Tokens[j]
+		if da, db := a.Flags[0].Severity == Danger, b.Flags[0].Severity == Danger; da != db {
+			return da
+		}
+		if len(a.Flags) != len(b.Flags) {
+			return len(a.Flags) > len(b.Flags)
+		}
+		return a.Token < b.Token
+	})
+	return feed
+}
+
+// Scan lists the tokens, measures their volatility and checks the
+// configured contracts. Tokens that fail keep what the previous scan
+// learned about them, and all are kept if listing fails.
+func (c *Checker) Scan(ctx context.Context) error {
+	c.mu.RLock()
+	prev := c.tokens
+	c.mu.RUnlock()
+
+	var errs []error
+	next := make(map[string]*scanned)
+	var ids []string
+	add := func(id string) *scanned {
+		id = strings.ToLower(id)
+		if s, ok := next[id]; ok {
+			return s
+		}
+		s := &scanned{}
+		if p, ok := prev[id]; ok {
+			cp := *p
+			s = &cp
+		}
+		next[id] = s
+		ids = append(ids, id)
+		return s
+	}
+
+	listings, err := c.list(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("listing tokens: %w", err))
+		for id := range prev {
+			add(id)
+		}
+	}
+	for _, l := range listings {
+		add(l.ID).volume = l.Volume24h
+	}
+	for id := range c.opts.Contracts {
+		add(id)
+	}
+
+	for _, id := range ids {
+		v, ok, err := c.volatility(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			errs = append(errs, fmt.Errorf("volatility of %s: %w", id, err))
+			continue
+		}
+		next[id].volatility = nil
+		if ok && !math.IsNaN(v) {
+			next[id].volatility = &v
+		}
+	}
+
+	contracts, err := c.checkContracts(ctx)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for id, flags := range contracts {
+		add(id).contract = flags
+	}
+
+	c.mu.Lock()
+	c.tokens, c.checkedAt = next, time.Now().UTC()
+	c.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// Run scans every interval until ctx is done
+func (c *Checker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := c.Scan(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("Scanning token risks: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
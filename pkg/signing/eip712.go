@@ -0,0 +1,217 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package signing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+	"github.com/luxfi/pricing/pkg/evm"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// ErrNoQuoteSigner is returned when signing a quote without a signer
+// configured
+var ErrNoQuoteSigner = errors.New("quote signing not configured")
+
+// quoteType is the EIP-712 type of a quote
+const quoteType = "Quote(string token,string currency,uint256 price,uint8 decimals,uint256 timestamp,uint256 expiry)"
+
+// EIP712Domain separates quote signatures by application, version, chain
+// and verifying contract
+type EIP712Domain struct {
+	Name              string `json:"name"`
+	Version           string `json:"version"`
+	ChainID           int64  `json:"chainId"`
+	VerifyingContract string `json:"verifyingContract,omitempty"`
+}
+
+// typeString is the domain's EIP-712 type; verifyingContract is left out
+// when not set
+func (d EIP712Domain) typeString() string {
+	if d.VerifyingContract == "" {
+		return "EIP712Domain(string name,string version,uint256 chainId)"
+	}
+	return "EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"
+}
+
+// Separator returns the domain separator, hashStruct(domain)
+func (d EIP712Domain) Separator() []byte {
+	enc := [][]byte{
+		evm.Keccak256([]byte(d.typeString())),
+		evm.Keccak256([]byte(d.Name)),
+		evm.Keccak256([]byte(d.Version)),
+		word(big.NewInt(d.ChainID)),
+	}
+	if d.VerifyingContract != "" {
+		addr, _ := hex.DecodeString(strings.TrimPrefix(d.VerifyingContract, "0x"))
+		enc = append(enc, word(new(big.Int).SetBytes(addr)))
+	}
+	return evm.Keccak256(enc...)
+}
+
+// Quote is a price quote as EIP-712 typed data, for contracts to accept
+// until Expiry after checking its signer with ecrecover. Price is the
+// price × 10^Decimals, as a decimal string since it can exceed what JSON
+// numbers hold exactly.
+type Quote = wire.PriceQuote
+
+// hashQuote returns hashStruct(q)
+func hashQuote(q Quote) []byte {
+	price, _ := new(big.Int).SetString(q.Price, 10)
+	return evm.Keccak256(
+		evm.Keccak256([]byte(quoteType)),
+		evm.Keccak256([]byte(q.Token)),
+		evm.Keccak256([]byte(q.Currency)),
+		word(price),
+		word(big.NewInt(int64(q.Decimals))),
+		word(big.NewInt(q.Timestamp)),
+		word(big.NewInt(q.Expiry)),
+	)
+}
+
+// TypedData is a quote in the eth_signTypedData_v4 format
+type TypedData struct {
+	Types       map[string][]TypedField `json:"types"`
+	PrimaryType string                  `json:"primaryType"`
+	Domain      EIP712Domain            `json:"domain"`
+	Message     Quote                   `json:"message"`
+}
+
+// TypedField is a member of an EIP-712 type
+type TypedField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// QuoterOptions configures a Quoter
+type QuoterOptions struct {
+	Domain   EIP712Domain
+	TTL      time.Duration // how long a quote is valid
+	Decimals uint8
+
+	// Signer is the address quotes are signed by. The JSON-RPC endpoint
+	// at SignerURL, a signer such as Clef or Web3Signer, holds its key and
+	// signs with SignMethod (eth_signTypedData_v4 if empty).
+	Signer     string
+	SignerURL  string
+	SignMethod string
+	Timeout    time.Duration
+	Transport  http.RoundTripper
+}
+
+// Quoter builds EIP-712 price quotes and has them signed. Quotes can be
+// built without a signer, but not signed.
+type Quoter struct {
+	opts   QuoterOptions
+	client *evm.Client
+}
+
+// NewQuoter creates a quoter
+func NewQuoter(opts QuoterOptions) *Quoter {
+	if opts.SignMethod == "" {
+		opts.SignMethod = "eth_signTypedData_v4"
+	}
+	q := &Quoter{opts: opts}
+	if opts.Signer != "" && opts.SignerURL != "" {
+		q.client = evm.NewClient(opts.SignerURL, opts.Timeout, opts.Transport)
+	}
+	return q
+}
+
+// Domain returns the EIP-712 domain quotes are signed in
+func (q *Quoter) Domain() EIP712Domain {
+	return q.opts.Domain
+}
+
+// Signer returns the address quotes are signed by, or "" if signing is
+// not configured
+func (q *Quoter) Signer() string {
+	if q.client == nil {
+		return ""
+	}
+	return q.opts.Signer
+}
+
+// Quote builds a quote for a price fetched at, valid for the TTL from now
+func (q *Quoter) Quote(token, currency string, price decimal.Decimal, at, now time.Time) Quote {
+	scale, _ := decimal.Parse(fmt.Sprintf("1e%d", q.opts.Decimals))
+	return Quote{
+		Token:     token,
+		Currency:  currency,
+		Price:     price.Mul(scale).Int().String(),
+		Decimals:  q.opts.Decimals,
+		Timestamp: at.Unix(),
+		Expiry:    now.Add(q.opts.TTL).Unix(),
+	}
+}
+
+// Digest returns the hash a quote's signature is over:
+// keccak256("\x19\x01" ‖ domainSeparator ‖ hashStruct(quote))
+func (q *Quoter) Digest(quote Quote) []byte {
+	return evm.Keccak256([]byte{0x19, 0x01}, q.opts.Domain.Separator(), hashQuote(quote))
+}
+
+// TypedData returns a quote as typed data for eth_signTypedData_v4
+func (q *Quoter) TypedData(quote Quote) TypedData {
+	domain := []TypedField{
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+	}
+	if q.opts.Domain.VerifyingContract != "" {
+		domain = append(domain, TypedField{Name: "verifyingContract", Type: "address"})
+	}
+	return TypedData{
+		Types: map[string][]TypedField{
+			"EIP712Domain": domain,
+			"Quote": {
+				{Name: "token", Type: "string"},
+				{Name: "currency", Type: "string"},
+				{Name: "price", Type: "uint256"},
+				{Name: "decimals", Type: "uint8"},
+				{Name: "timestamp", Type: "uint256"},
+				{Name: "expiry", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Quote",
+		Domain:      q.opts.Domain,
+		Message:     quote,
+	}
+}
+
+// Sign has the signer sign a quote and returns the 65-byte r ‖ s ‖ v
+// signature in hex, with v as 27 or 28 for ecrecover
+func (q *Quoter) Sign(ctx context.Context, quote Quote) (string, error) {
+	if q.client == nil {
+		return "", ErrNoQuoteSigner
+	}
+	var sig string
+	if err := q.client.Call(ctx, &sig, q.opts.SignMethod, q.opts.Signer, q.TypedData(quote)); err != nil {
+		return "", fmt.Errorf("quote signer: %w", err)
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(sig, "0x"))
+	if err != nil || len(b) != 65 {
+		return "", fmt.Errorf("quote signer: malformed signature %q", sig)
+	}
+	if b[64] < 27 {
+		b[64] += 27
+	}
+	return "0x" + hex.EncodeToString(b), nil
+}
+
+// word encodes n as a 32-byte ABI word
+func word(n *big.Int) []byte {
+	if n == nil {
+		n = new(big.Int)
+	}
+	return n.FillBytes(make([]byte, 32))
+}
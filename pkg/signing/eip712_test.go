@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package signing_test
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/luxfi/pricing/internal/testutil"
+	"github.com/luxfi/pricing/pkg/evm"
+	"github.com/luxfi/pricing/pkg/signing"
+)
+
+// quote is signed in the known-answer tests below. The digests were
+// computed with an independent EIP-712 encoder.
+var quote = signing.Quote{
+	Token:     "bitcoin",
+	Currency:  "usd",
+	Price:     "6500000000000",
+	Decimals:  8,
+	Timestamp: 1735689600,
+	Expiry:    1735689660,
+}
+
+func TestDomainSeparator(t *testing.T) {
+	tests := []struct {
+		name   string
+		domain signing.EIP712Domain
+		want   string
+	}{
+		{
+			// The example in EIP-712 itself
+			name:   "eip-712 mail",
+			domain: signing.EIP712Domain{Name: "Ether Mail", Version: "1", ChainID: 1, VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"},
+			want:   "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f",
+		},
+		{
+			name:   "no verifying contract",
+			domain: signing.EIP712Domain{Name: "Lux Pricing", Version: "1", ChainID: 96369},
+			want:   "82385c3f73b4c441403bbd50a09383f6848de7e0a865951ed6ef0fb2e66ec632",
+		},
+		{
+			name:   "verifying contract",
+			domain: signing.EIP712Domain{Name: "Lux Pricing", Version: "1", ChainID: 96369, VerifyingContract: "0x5FbDB2315678afecb367f032d93F642f64180aa3"},
+			want:   "95b88b78910fabb6ba0e9b41a2880d2d4140ae5294ab599179722fa227202b9e",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hex.EncodeToString(tt.domain.Separator()); got != tt.want {
+				t.Errorf("Separator() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteDigest(t *testing.T) {
+	tests := []struct {
+		name     string
+		contract string
+		want     string
+	}{
+		{name: "no verifying contract", want: "555d87c3e5235db7b26d80e9fdd30eb6d4747bc6cdb78aec630cc79dc6559a60"},
+		{name: "verifying contract", contract: "0x5FbDB2315678afecb367f032d93F642f64180aa3", want: "a7b1a75290dca31a29ca75744dd056f7a728c9f731be6c67f4cc84ab7d847acf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := signing.NewQuoter(signing.QuoterOptions{Domain: signing.EIP712Domain{Name: "Lux Pricing", Version: "1", ChainID: 96369, VerifyingContract: tt.contract}})
+			if got := hex.EncodeToString(q.Digest(quote)); got != tt.want {
+				t.Errorf("Digest() = %s, want %s", got, tt.want)
+			}
+			domain := q.TypedData(quote).Types["EIP712Domain"]
+			if hasContract := domain[len(domain)-1].Name == "verifyingContract"; hasContract != (tt.contract != "") {
+				t.Errorf("typed data domain fields = %+v, verifyingContract should be listed only when set", domain)
+			}
+		})
+	}
+}
+
+// TestEcrecover checks the test signer's recovery against the signature
+// published in EIP-712, so the tests below recover what Ethereum would
+func TestEcrecover(t *testing.T) {
+	digest, _ := hex.DecodeString("be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2")
+	sig := "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d" +
+		"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562" + "1c"
+	const cow = "0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826"
+
+	if got, err := testutil.Ecrecover(digest, sig); err != nil || got != cow {
+		t.Errorf("Ecrecover = %s, %v, want %s", got, err, cow)
+	}
+	if got := testutil.PrivateKeyAddress(new(big.Int).SetBytes(evm.Keccak256([]byte("cow")))); got != cow {
+		t.Errorf("address of keccak256(cow) = %s, want %s", got, cow)
+	}
+}
+
+func TestSignRecoversSigner(t *testing.T) {
+	for _, contract := range []string{"", "0x5FbDB2315678afecb367f032d93F642f64180aa3"} {
+		for _, rawV := range []bool{false, true} {
+			signer := testutil.NewQuoteSigner(t, "quote signer")
+			signer.RawV(rawV)
+			q := signing.NewQuoter(signer.Options(signing.EIP712Domain{Name: "Lux Pricing", Version: "1", ChainID: 96369, VerifyingContract: contract}))
+
+			sig, err := q.Sign(context.Background(), quote)
+			if err != nil {
+				t.Fatalf("contract %q, raw v %v: Sign: %v", contract, rawV, err)
+			}
+			got, err := testutil.Ecrecover(q.Digest(quote), sig)
+			if err != nil || got != q.Signer() {
+				t.Errorf("contract %q, raw v %v: signature recovers to %s, %v, want %s", contract, rawV, got, err, q.Signer())
+			}
+		}
+	}
+}
+
+func TestSignWithoutSigner(t *testing.T) {
+	q := signing.NewQuoter(signing.QuoterOptions{Domain: signing.EIP712Domain{Name: "Lux Pricing", Version: "1", ChainID: 96369}})
+	if _, err := q.Sign(context.Background(), quote); !errors.Is(err, signing.ErrNoQuoteSigner) {
+		t.Errorf("Sign without a signer: %v, want ErrNoQuoteSigner", err)
+	}
+}
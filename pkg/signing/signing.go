@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package signing produces verifiable attestations over price data.
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// PriceSignature is attached to signed price responses. Payload is the
+// exact canonical message that was signed.
+type PriceSignature = wire.PriceSignature
+
+// Signer signs price attestations with an Ed25519 key
+type Signer struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// NewSigner creates a signer from a hex-encoded 32-byte Ed25519 seed
+func NewSigner(seedHex string) (*Signer, error) {
+	seed, err := hex.DecodeString(strings.TrimPrefix(seedHex, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("signing key: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key: expected %d byte seed, got %d", ed25519.SeedSize, len(seed))
+	}
+
+	key := ed25519.NewKeyFromSeed(seed)
+	pub := key.Public().(ed25519.PublicKey)
+	sum := sha256.Sum256(pub)
+
+	return &Signer{
+		key:   key,
+		keyID: hex.EncodeToString(sum[:8]),
+	}, nil
+}
+
+// KeyID identifies the signing key (first 8 bytes of sha256(pubkey), hex)
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// PublicKey returns the hex-encoded public key
+func (s *Signer) PublicKey() string {
+	return hex.EncodeToString(s.key.Public().(ed25519.PublicKey))
+}
+
+// CanonicalPricePayload encodes {token, price, currency, timestamp} as JSON
+// with sorted keys, no whitespace, the price as the exact decimal string
+// the response carries in price_str and the timestamp as unix seconds.
+func CanonicalPricePayload(token, currency, price string, timestamp time.Time) string {
+	return fmt.Sprintf(`{"currency":%s,"price":%s,"timestamp":%d,"token":%s}`,
+		strconv.Quote(currency),
+		strconv.Quote(price),
+		timestamp.Unix(),
+		strconv.Quote(token))
+}
+
+// SignPrice signs a price observation; price is its exact decimal string
+func (s *Signer) SignPrice(token, currency, price string, timestamp time.Time) *PriceSignature {
+	payload := CanonicalPricePayload(token, currency, price, timestamp)
+	sig := ed25519.Sign(s.key, []byte(payload))
+	return &PriceSignature{
+		Algorithm: "ed25519",
+		KeyID:     s.keyID,
+		Payload:   payload,
+		Signature: hex.EncodeToString(sig),
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package signing_test
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/signing"
+)
+
+// seed is an Ed25519 seed for tests only
+const seed = "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"
+
+func TestSignPrice(t *testing.T) {
+	s, err := signing.NewSigner("0x" + seed)
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	pub, _ := hex.DecodeString(s.PublicKey())
+	sum := sha256.Sum256(pub)
+	if s.KeyID() != hex.EncodeToString(sum[:8]) {
+		t.Errorf("KeyID = %s, want the first 8 bytes of sha256(public key)", s.KeyID())
+	}
+
+	at := time.Unix(1737720000, 0)
+	tests := []struct {
+		price, payload string
+	}{
+		{"97234.56", `{"currency":"usd","price":"97234.56","timestamp":1737720000,"token":"bitcoin"}`},
+		// More digits than a float64 holds are signed as given
+		{"97234.123456789012345678", `{"currency":"usd","price":"97234.123456789012345678","timestamp":1737720000,"token":"bitcoin"}`},
+		{"0.000000001234", `{"currency":"usd","price":"0.000000001234","timestamp":1737720000,"token":"bitcoin"}`},
+	}
+	for _, tt := range tests {
+		sig := s.SignPrice("bitcoin", "usd", tt.price, at)
+		if sig.Payload != tt.payload || sig.Algorithm != "ed25519" || sig.KeyID != s.KeyID() {
+			t.Errorf("SignPrice(%s) = %+v, want payload %s", tt.price, sig, tt.payload)
+		}
+		b, err := hex.DecodeString(sig.Signature)
+		if err != nil || !ed25519.Verify(pub, []byte(sig.Payload), b) {
+			t.Errorf("signature over %s does not verify", sig.Payload)
+		}
+	}
+}
+
+func TestNewSignerInvalid(t *testing.T) {
+	for _, key := range []string{"", "zz", seed[:62]} {
+		if _, err := signing.NewSigner(key); err == nil {
+			t.Errorf("NewSigner(%q) succeeded, want an error", key)
+		}
+	}
+}
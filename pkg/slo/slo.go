@@ -0,0 +1,321 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package slo tracks availability and latency objectives per route over a
+// rolling window, and how fast each route is burning its error budget.
+package slo
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAvailability is the fraction of requests that must not fail
+	// with a 5xx if none is configured
+	DefaultAvailability = 0.999
+
+	// DefaultLatency is the latency requests are measured against if none
+	// is configured
+	DefaultLatency = time.Second
+
+	// DefaultLatencyTarget is the fraction of requests that must be faster
+	// than the latency objective if none is configured
+	DefaultLatencyTarget = 0.99
+
+	// DefaultWindow is the rolling window objectives are measured over if
+	// none is configured
+	DefaultWindow = 24 * time.Hour
+
+	// DefaultShedBurnRate is the burn rate at which low-priority routes
+	// are shed if none is configured
+	DefaultShedBurnRate = 10
+
+	// buckets is how many buckets a window is divided into; a bucket is at
+	// least a minute
+	buckets = 1440
+
+	// burnBuckets is how many of the latest buckets burn rates are
+	// measured over
+	burnBuckets = 5
+
+	// minBurnRequests is the fewest requests in the burn window for a
+	// route's burn rate to count towards shedding, so a few errors on a
+	// quiet route do not shed load
+	minBurnRequests = 20
+)
+
+// Objective is the service level a route is held to
+type Objective struct {
+	// Availability is the fraction of requests that must not fail with a
+	// 5xx, e.g. 0.999
+	Availability float64
+
+	// Latency is the response time LatencyTarget of requests must beat;
+	// zero leaves latency untracked, as for long-lived streams
+	Latency       time.Duration
+	LatencyTarget float64
+}
+
+// Options configures a Tracker
+type Options struct {
+	// Objective applies to every route without one in Routes
+	Objective Objective
+
+	// Routes holds objectives per route pattern
+	Routes map[string]Objective
+
+	// Window is the rolling window measured; DefaultWindow if zero
+	Window time.Duration
+
+	// ShedBurnRate is the burn rate at which Burning reports true;
+	// DefaultShedBurnRate if zero
+	ShedBurnRate float64
+}
+
+// bucket counts the requests of one slice of the window
+type bucket struct {
+	slot   int64 // start of the slice, in bucket widths since the epoch
+	total  uint64
+	failed uint64 // 5xx
+	slow   uint64 // slower than the latency objective
+}
+
+// series is one route's buckets, as a ring
+type series struct {
+	mu      sync.Mutex
+	buckets []bucket
+}
+
+// Tracker counts requests per route and measures them against their
+// objectives
+type Tracker struct {
+	opts  Options
+	width time.Duration // of a bucket
+	now   func() time.Time
+
+	mu     sync.RWMutex
+	routes map[string]*series
+}
+
+// NewTracker creates a tracker with no requests counted
+func NewTracker(opts Options) *Tracker {
+	if opts.Objective.Availability <= 0 {
+		opts.Objective.Availability = DefaultAvailability
+	}
+	if opts.Objective.LatencyTarget <= 0 {
+		opts.Objective.LatencyTarget = DefaultLatencyTarget
+	}
+	if opts.Window <= 0 {
+		opts.Window = DefaultWindow
+	}
+	if opts.ShedBurnRate <= 0 {
+		opts.ShedBurnRate = DefaultShedBurnRate
+	}
+	width := (opts.Window / buckets).Round(time.Minute)
+	if width < time.Minute {
+		width = time.Minute
+	}
+	return &Tracker{opts: opts, width: width, now: time.Now, routes: make(map[string]*series)}
+}
+
+// objective returns the objective of a route
+func (t *Tracker) objective(route string) Objective {
+	if o, ok := t.opts.Routes[route]; ok {
+		return o
+	}
+	return t.opts.Objective
+}
+
+// Observe counts a request to route. It is an api.RequestObserver.
+func (t *Tracker) Observe(route string, status int, elapsed time.Duration) {
+	t.mu.RLock()
+	s, ok := t.routes[route]
+	t.mu.RUnlock()
+	if !ok {
+		t.mu.Lock()
+		if s, ok = t.routes[route]; !ok {
+			s = &series{buckets: make([]bucket, int(t.opts.Window/t.width)+1)}
+			t.routes[route] = s
+		}
+		t.mu.Unlock()
+	}
+
+	o := t.objective(route)
+	slot := t.now().UnixNano() / int64(t.width)
+	s.mu.Lock()
+	b := &s.buckets[slot%int64(len(s.buckets))]
+	if b.slot != slot {
+		*b = bucket{slot: slot}
+	}
+	b.total++
+	if status >= 500 {
+		b.failed++
+	}
+	if o.Latency > 0 && elapsed > o.Latency {
+		b.slow++
+	}
+	s.mu.Unlock()
+}
+
+// sum adds up the route's buckets of the last n slots
+func (s *series) sum(slot int64, n int) bucket {
+	var total bucket
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, b := range s.buckets {
+		if b.slot > slot-int64(n) && b.slot <= slot {
+			total.total += b.total
+			total.failed += b.failed
+			total.slow += b.slow
+		}
+	}
+	return total
+}
+
+// burnRate is how many times faster than sustainable the counts spend o's
+// error budget: 1 spends it exactly over the window
+func burnRate(c bucket, o Objective) float64 {
+	if c.total == 0 {
+		return 0
+	}
+	rate := float64(c.failed) / float64(c.total) / (1 - o.Availability)
+	if o.Latency > 0 {
+		if slow := float64(c.slow) / float64(c.total) / (1 - o.LatencyTarget); slow > rate {
+			rate = slow
+		}
+	}
+	return rate
+}
+
+// RouteReport is a route's service level over the window
+type RouteReport struct {
+	Route    string `json:"route"`
+	Requests uint64 `json:"requests"`
+	Failed   uint64 `json:"failed"` // 5xx responses
+
+	Availability       float64 `json:"availability"`
+	AvailabilityTarget float64 `json:"availability_target"`
+
+	// Latency fields are omitted for routes without a latency objective
+	Latency       string  `json:"latency,omitempty"`        // objective, e.g. 1s
+	WithinLatency float64 `json:"within_latency,omitempty"` // fraction of requests faster
+	LatencyTarget float64 `json:"latency_target,omitempty"`
+
+	// BudgetRemaining is the fraction of the window's error budget left;
+	// negative once the objective is missed
+	BudgetRemaining float64 `json:"budget_remaining"`
+
+	// BurnRate is how fast the budget is spent over the burn window: 1
+	// spends it exactly over the window
+	BurnRate float64 `json:"burn_rate"`
+
+	Met bool `json:"met"`
+}
+
+// Report is every observed route's service level, sorted by route
+type Report struct {
+	Window     string        `json:"window"`
+	BurnWindow string        `json:"burn_window"`
+	Routes     []RouteReport `json:"routes"`
+}
+
+// Report measures every observed route against its objective
+func (t *Tracker) Report() Report {
+	slot := t.now().UnixNano() / int64(t.width)
+	rep := Report{
+		Window:     t.opts.Window.String(),
+		BurnWindow: (t.width * burnBuckets).String(),
+		Routes:     []RouteReport{},
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for route, s := range t.routes {
+		o := t.objective(route)
+		c := s.sum(slot, len(s.buckets))
+		if c.total == 0 {
+			continue
+		}
+		rr := RouteReport{
+			Route:              route,
+			Requests:           c.total,
+			Failed:             c.failed,
+			Availability:       1 - float64(c.failed)/float64(c.total),
+			AvailabilityTarget: o.Availability,
+			BurnRate:           burnRate(s.sum(slot, burnBuckets), o),
+		}
+		// The budget left is that of whichever objective is closer to
+		// being missed
+		rr.BudgetRemaining = 1 - float64(c.failed)/(float64(c.total)*(1-o.Availability))
+		if o.Latency > 0 {
+			rr.Latency = o.Latency.String()
+			rr.WithinLatency = 1 - float64(c.slow)/float64(c.total)
+			rr.LatencyTarget = o.LatencyTarget
+			if left := 1 - float64(c.slow)/(float64(c.total)*(1-o.LatencyTarget)); left < rr.BudgetRemaining {
+				rr.BudgetRemaining = left
+			}
+		}
+		rr.Met = rr.BudgetRemaining >= 0
+		rr.Availability, rr.WithinLatency = round(rr.Availability), round(rr.WithinLatency)
+		rr.BudgetRemaining, rr.BurnRate = round(rr.BudgetRemaining), round(rr.BurnRate)
+		rep.Routes = append(rep.Routes, rr)
+	}
+	sort.Slice(rep.Routes, func(i, j int) bool { return rep.Routes[i].Route < rep.Routes[j].Route })
+	return rep
+}
+
+// round rounds a ratio to six decimals, enough for five nines
+func round(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
+
+// Burning reports whether any route but those skip matches is spending
+// its error budget at ShedBurnRate or faster over the burn window
+func (t *Tracker) Burning(skip func(route string) bool) bool {
+	slot := t.now().UnixNano() / int64(t.width)
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for route, s := range t.routes {
+		if skip != nil && skip(route) {
+			continue
+		}
+		c := s.sum(slot, burnBuckets)
+		if c.total >= minBurnRequests && burnRate(c, t.objective(route)) >= t.opts.ShedBurnRate {
+			return true
+		}
+	}
+	return false
+}
+
+// WritePrometheus writes a report in the Prometheus text exposition
+// format, so the objectives can be scraped alongside other metrics
+func WritePrometheus(w io.Writer, rep Report, shedding bool) {
+	metrics := []struct {
+		name, help string
+		value      func(RouteReport) (float64, bool)
+	}{
+		{"pricing_slo_requests", "Requests over the SLO window", func(r RouteReport) (float64, bool) { return float64(r.Requests), true }},
+		{"pricing_slo_availability", "Fraction of requests over the SLO window that did not fail", func(r RouteReport) (float64, bool) { return r.Availability, true }},
+		{"pricing_slo_availability_target", "Availability objective", func(r RouteReport) (float64, bool) { return r.AvailabilityTarget, true }},
+		{"pricing_slo_within_latency", "Fraction of requests over the SLO window faster than the latency objective", func(r RouteReport) (float64, bool) { return r.WithinLatency, r.Latency != "" }},
+		{"pricing_slo_error_budget_remaining", "Fraction of the error budget left over the SLO window", func(r RouteReport) (float64, bool) { return r.BudgetRemaining, true }},
+		{"pricing_slo_burn_rate", "Error budget burn rate over the burn window", func(r RouteReport) (float64, bool) { return r.BurnRate, true }},
+	}
+	for _, m := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", m.name, m.help, m.name)
+		for _, r := range rep.Routes {
+			if v, ok := m.value(r); ok {
+				fmt.Fprintf(w, "%s{route=%q} %g\n", m.name, r.Route, v)
+			}
+		}
+	}
+	shed := 0
+	if shedding {
+		shed = 1
+	}
+	fmt.Fprintf(w, "# HELP pricing_slo_shedding Whether low-priority routes are being shed\n# TYPE pricing_slo_shedding gauge\npricing_slo_shedding %d\n", shed)
+}
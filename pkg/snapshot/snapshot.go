@@ -0,0 +1,134 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package snapshot periodically exports the price and market dataset as
+// gzip-compressed JSON to object storage.
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/stablecoins"
+)
+
+const (
+	// DefaultInterval is how often snapshots are written
+	DefaultInterval = time.Hour
+
+	// keyLayout names snapshots so they sort by time
+	keyLayout = "20060102T150405Z"
+)
+
+// Dataset is one snapshot
+type Dataset struct {
+	GeneratedAt time.Time              `json:"generated_at"`
+	Currency    string                 `json:"currency"`
+	Markets     []providers.Price      `json:"markets"`               // largest tokens by market cap
+	Prices      []*cache.PriceResponse `json:"prices"`                // every unexpired cached price
+	Stablecoins []stablecoins.Status   `json:"stablecoins,omitempty"` // peg status, if monitored
+}
+
+// CollectFunc gathers the dataset for a snapshot
+type CollectFunc func(ctx context.Context) (*Dataset, error)
+
+// Exporter writes a snapshot to a Store on a schedule and deletes
+// snapshots older than the retention period
+type Exporter struct {
+	store     Store
+	prefix    string
+	collect   CollectFunc
+	retention time.Duration
+}
+
+// NewExporter creates an exporter writing to store under prefix. A zero
+// retention keeps every snapshot.
+func NewExporter(store Store, prefix string, collect CollectFunc, retention time.Duration) *Exporter {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &Exporter{store: store, prefix: prefix, collect: collect, retention: retention}
+}
+
+// Run exports a snapshot at every multiple of interval (on the hour for
+// hourly snapshots) until ctx is done
+func (e *Exporter) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	for {
+		now := time.Now()
+		timer := time.NewTimer(now.Truncate(interval).Add(interval).Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		key, err := e.Export(ctx)
+		if err != nil {
+			log.Printf("Snapshot export failed: %v", err)
+			continue
+		}
+		log.Printf("Snapshot exported to %s", key)
+	}
+}
+
+// Export writes one snapshot, prunes expired ones and returns the key
+// written
+func (e *Exporter) Export(ctx context.Context) (string, error) {
+	data, err := e.collect(ctx)
+	if err != nil {
+		return "", fmt.Errorf("collecting dataset: %w", err)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(data); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+
+	key := e.prefix + "prices-" + data.GeneratedAt.UTC().Format(keyLayout) + ".json.gz"
+	if err := e.store.Put(ctx, key, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("writing %s: %w", key, err)
+	}
+
+	if e.retention > 0 {
+		if err := e.prune(ctx, data.GeneratedAt.Add(-e.retention)); err != nil {
+			log.Printf("Snapshot retention: %v", err)
+		}
+	}
+	return key, nil
+}
+
+// prune deletes snapshots written before cutoff. Only keys named by the
+// exporter are considered, so other objects under the prefix are kept.
+func (e *Exporter) prune(ctx context.Context, cutoff time.Time) error {
+	keys, err := e.store.List(ctx, e.prefix+"prices-")
+	if err != nil {
+		return fmt.Errorf("listing snapshots: %w", err)
+	}
+	for _, key := range keys {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(key, e.prefix+"prices-"), ".json.gz")
+		t, err := time.Parse(keyLayout, stamp)
+		if err != nil || !t.Before(cutoff) {
+			continue
+		}
+		if err := e.store.Delete(ctx, key); err != nil {
+			return fmt.Errorf("deleting %s: %w", key, err)
+		}
+	}
+	return nil
+}
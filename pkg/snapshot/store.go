@@ -0,0 +1,127 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Store is where snapshots are written
+type Store interface {
+	// Put writes an object, replacing any existing one
+	Put(ctx context.Context, key string, body []byte) error
+
+	// List returns the keys starting with prefix
+	List(ctx context.Context, prefix string) ([]string, error)
+
+	// Delete removes an object
+	Delete(ctx context.Context, key string) error
+}
+
+// S3Options are the credentials and endpoint for an S3 or GCS bucket
+type S3Options struct {
+	Endpoint        string // API root; the provider's if empty
+	Region          string // us-east-1 if empty
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// Open returns the store for a destination URL and the key prefix within
+// it. s3://bucket/prefix writes to S3 (or an S3-compatible endpoint),
+// gs://bucket/prefix to Google Cloud Storage with HMAC keys, and
+// file:///dir to a local directory.
+func Open(rawURL string, opts S3Options, client *http.Client) (Store, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+	prefix := strings.TrimPrefix(u.Path, "/")
+
+	switch u.Scheme {
+	case "file":
+		return Dir(u.Path), "", nil
+	case "s3", "gs":
+		if u.Host == "" {
+			return nil, "", fmt.Errorf("%s: missing bucket", rawURL)
+		}
+		if opts.AccessKeyID == "" || opts.SecretAccessKey == "" {
+			return nil, "", fmt.Errorf("%s: access key and secret required", rawURL)
+		}
+		s := NewS3(u.Host, opts, client)
+		if u.Scheme == "gs" && opts.Endpoint == "" {
+			// GCS serves the S3 XML API with HMAC keys
+			s.Endpoint, s.Region, s.PathStyle = "https://storage.googleapis.com", "auto", true
+		}
+		return s, prefix, nil
+	default:
+		return nil, "", fmt.Errorf("%s: unsupported scheme (use s3, gs or file)", rawURL)
+	}
+}
+
+// Dir is a Store writing to a local directory
+type Dir string
+
+// Put writes key below the directory, replacing it atomically
+func (d Dir) Put(ctx context.Context, key string, body []byte) error {
+	path := filepath.Join(string(d), filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// List returns the keys below the directory starting with prefix
+func (d Dir) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(string(d), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(string(d), path)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	sort.Strings(keys)
+	return keys, err
+}
+
+// Delete removes key from the directory
+func (d Dir) Delete(ctx context.Context, key string) error {
+	err := os.Remove(filepath.Join(string(d), filepath.FromSlash(key)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,281 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package stablecoins monitors stablecoin prices for deviation from their
+// peg across providers.
+package stablecoins
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// Coin is a monitored stablecoin
+type Coin = wire.Stablecoin
+
+// DefaultCoins are monitored when none are configured
+var DefaultCoins = []Coin{
+	{ID: "tether", Symbol: "usdt", Peg: 1},
+	{ID: "usd-coin", Symbol: "usdc", Peg: 1},
+	{ID: "dai", Symbol: "dai", Peg: 1},
+}
+
+// Sample is one observation of a coin's price
+type Sample = wire.PegSample
+
+// Status is the current state of a coin
+type Status = wire.StablecoinStatus
+
+// Alert is sent when a coin crosses the depeg threshold in either direction
+type Alert struct {
+	Event     string    `json:"event"` // "depeg" or "repeg"
+	ID        string    `json:"id"`
+	Symbol    string    `json:"symbol"`
+	Price     float64   `json:"price"`
+	Deviation float64   `json:"deviation"`
+	Threshold float64   `json:"threshold"`
+	Time      time.Time `json:"time"`
+}
+
+// Options configures a Monitor
+type Options struct {
+	Coins     []Coin
+	Threshold float64       // absolute deviation that counts as a depeg
+	History   time.Duration // how long samples are kept
+	Notify    func(Alert)   // called on depeg and repeg if set
+}
+
+// source is a provider and the coin ids it is asked for
+type source struct {
+	provider providers.Provider
+	ids      map[string]bool // nil means all coins
+}
+
+// Monitor polls providers for stablecoin prices and tracks deviation
+type Monitor struct {
+	coins     []Coin
+	threshold float64
+	history   time.Duration
+	notify    func(Alert)
+	sources   []source
+
+	mu     sync.RWMutex
+	status map[string]*Status
+}
+
+// NewMonitor creates a monitor. Add sources with AddSource before Run.
+func NewMonitor(opts Options) *Monitor {
+	m := &Monitor{
+		coins:     opts.Coins,
+		threshold: opts.Threshold,
+		history:   opts.History,
+		notify:    opts.Notify,
+		status:    make(map[string]*Status),
+	}
+	if len(m.coins) == 0 {
+		m.coins = DefaultCoins
+	}
+	for i := range m.coins {
+		c := &m.coins[i]
+		c.ID = strings.ToLower(c.ID)
+		if c.Peg == 0 {
+			c.Peg = 1
+		}
+	}
+	return m
+}
+
+// AddSource queries p for the given coin ids, or for every coin if none
+// are given
+func (m *Monitor) AddSource(p providers.Provider, ids ...string) {
+	s := source{provider: p}
+	if len(ids) > 0 {
+		s.ids = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			s.ids[strings.ToLower(id)] = true
+		}
+	}
+	m.sources = append(m.sources, s)
+}
+
+// Threshold returns the depeg threshold
+func (m *Monitor) Threshold() float64 {
+	return m.threshold
+}
+
+// LastDepeg returns a coin's latest sample off its peg by at least the
+// threshold, if one is in the history window, and whether the coin is off
+// its peg now
+func (m *Monitor) LastDepeg(id string) (sample Sample, depegged, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	st, found := m.status[strings.ToLower(id)]
+	if !found {
+		return Sample{}, false, false
+	}
+	for i := len(st.History) - 1; i >= 0; i-- {
+		if math.Abs(st.History[i].Deviation) >= m.threshold {
+			return st.History[i], st.Depegged, true
+		}
+	}
+	return Sample{}, false, false
+}
+
+// Run polls every interval until ctx is done
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		m.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches prices from every source once and updates each coin
+func (m *Monitor) Poll(ctx context.Context) {
+	quotes := make(map[string]map[string]float64) // coin id -> source -> price
+	for _, s := range m.sources {
+		var ids []string
+		for _, c := range m.coins {
+			if s.ids == nil || s.ids[c.ID] {
+				ids = append(ids, c.ID)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		prices, err := s.provider.FetchPrices(ctx, ids, "usd")
+		if err != nil {
+			log.Printf("Stablecoin monitor: %s: %v", s.provider.Name(), err)
+		}
+		for _, p := range prices {
+			if quotes[p.ID] == nil {
+				quotes[p.ID] = make(map[string]float64)
+			}
+			quotes[p.ID][s.provider.Name()] = p.CurrentPrice
+		}
+	}
+
+	now := time.Now().UTC()
+	var alerts []Alert
+	m.mu.Lock()
+	for _, c := range m.coins {
+		if len(quotes[c.ID]) == 0 {
+			continue
+		}
+		if alert, ok := m.record(c, quotes[c.ID], now); ok {
+			alerts = append(alerts, alert)
+		}
+	}
+	m.mu.Unlock()
+
+	if m.notify != nil {
+		for _, a := range alerts {
+			m.notify(a)
+		}
+	}
+}
+
+// record updates a coin with new quotes and returns an alert if it crossed
+// the threshold. Callers hold m.mu.
+func (m *Monitor) record(c Coin, quotes map[string]float64, now time.Time) (Alert, bool) {
+	st, ok := m.status[c.ID]
+	if !ok {
+		st = &Status{Stablecoin: c}
+		m.status[c.ID] = st
+	}
+
+	price := median(quotes)
+	deviation := (price - c.Peg) / c.Peg
+	st.Price = price
+	st.Deviation = deviation
+	st.Sources = quotes
+	st.UpdatedAt = now
+
+	st.History = append(st.History, Sample{Time: now, Price: price, Deviation: deviation})
+	cutoff := now.Add(-m.history)
+	drop := 0
+	for drop < len(st.History) && st.History[drop].Time.Before(cutoff) {
+		drop++
+	}
+	st.History = st.History[drop:]
+	st.MaxDeviation = 0
+	for _, s := range st.History {
+		st.MaxDeviation = math.Max(st.MaxDeviation, math.Abs(s.Deviation))
+	}
+
+	depegged := math.Abs(deviation) >= m.threshold
+	if depegged == st.Depegged {
+		return Alert{}, false
+	}
+	st.Depegged = depegged
+	event := "repeg"
+	if depegged {
+		event = "depeg"
+		at := now
+		st.DepeggedAt = &at
+	} else {
+		st.DepeggedAt = nil
+	}
+	return Alert{
+		Event:     event,
+		ID:        c.ID,
+		Symbol:    c.Symbol,
+		Price:     price,
+		Deviation: deviation,
+		Threshold: m.threshold,
+		Time:      now,
+	}, true
+}
+
+// Snapshot returns the status of every coin seen so far, in configured
+// order. History is included if withHistory is set.
+func (m *Monitor) Snapshot(withHistory bool) []Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]Status, 0, len(m.coins))
+	for _, c := range m.coins {
+		st, ok := m.status[c.ID]
+		if !ok {
+			continue
+		}
+		cp := *st
+		cp.Sources = make(map[string]float64, len(st.Sources))
+		for k, v := range st.Sources {
+			cp.Sources[k] = v
+		}
+		cp.History = nil
+		if withHistory {
+			cp.History = append([]Sample(nil), st.History...)
+		}
+		out = append(out, cp)
+	}
+	return out
+}
+
+// median returns the median of the quoted prices
+func median(quotes map[string]float64) float64 {
+	prices := make([]float64, 0, len(quotes))
+	for _, p := range quotes {
+		prices = append(prices, p)
+	}
+	sort.Float64s(prices)
+	n := len(prices)
+	if n%2 == 1 {
+		return prices[n/2]
+	}
+	return (prices[n/2-1] + prices[n/2]) / 2
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package stablecoins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Webhooks returns a notifier that POSTs each alert as JSON to every url.
+// Deliveries run in the background; failures are logged.
+func Webhooks(urls []string, client *http.Client) func(Alert) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return func(a Alert) {
+		body, err := json.Marshal(a)
+		if err != nil {
+			return
+		}
+		for _, u := range urls {
+			go func(u string) {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				req, err := http.NewRequestWithContext(ctx, "POST", u, bytes.NewReader(body))
+				if err != nil {
+					log.Printf("Stablecoin webhook %s: %v", u, err)
+					return
+				}
+				req.Header.Set("Content-Type", "application/json")
+				resp, err := client.Do(req)
+				if err != nil {
+					log.Printf("Stablecoin webhook %s: %v", u, err)
+					return
+				}
+				resp.Body.Close()
+				if resp.StatusCode >= 300 {
+					log.Printf("Stablecoin webhook %s: status %d", u, resp.StatusCode)
+				}
+			}(u)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package stream
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+)
+
+const (
+	// DefaultChannel is the Redis channel refreshes are relayed on if none
+	// is configured
+	DefaultChannel = "pricing:prices"
+
+	// relayQueue bounds refreshes waiting to be published; more are
+	// dropped rather than holding up the cache
+	relayQueue = 256
+
+	// relayBackoff bounds the wait before reconnecting to Redis
+	relayBackoff = 30 * time.Second
+)
+
+// RelayOptions configures a Relay
+type RelayOptions struct {
+	// URL of the Redis server: redis://[user:password@]host:port, or
+	// rediss:// for TLS
+	URL string
+
+	Channel string // DefaultChannel if empty
+}
+
+// relayed is a refresh published to the other replicas
+type relayed struct {
+	Origin   string                `json:"origin"`
+	Currency string                `json:"currency"`
+	Prices   []cache.PriceResponse `json:"prices"`
+}
+
+// Relay shares cache refreshes between replicas through Redis pub/sub, so
+// stream clients of every replica see prices fetched by any of them.
+// Refreshes are published as JSON; those from other replicas are handed
+// to deliver, typically Hub.Publish.
+type Relay struct {
+	opts    RelayOptions
+	addr    string
+	useTLS  bool
+	user    string
+	pass    string
+	origin  string
+	deliver cache.RefreshFunc
+	queue   chan relayed
+
+	mu      sync.Mutex
+	dropped int // refreshes dropped since the last log line
+}
+
+// NewRelay creates a relay for the Redis server at opts.URL
+func NewRelay(opts RelayOptions, deliver cache.RefreshFunc) (*Relay, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, fmt.Errorf("redis url: %w", err)
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return nil, fmt.Errorf("redis url: scheme must be redis or rediss, not %q", u.Scheme)
+	}
+	if opts.Channel == "" {
+		opts.Channel = DefaultChannel
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	r := &Relay{
+		opts:    opts,
+		addr:    host,
+		useTLS:  u.Scheme == "rediss",
+		origin:  hex.EncodeToString(b),
+		deliver: deliver,
+		queue:   make(chan relayed, relayQueue),
+	}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			r.user, r.pass = u.User.Username(), pass
+		} else {
+			// redis://secret@host is a password without a user
+			r.pass = u.User.Username()
+		}
+	}
+	return r, nil
+}
+
+// Publish queues a refresh for the other replicas, dropping it if Redis
+// is not keeping up. It is a cache.RefreshFunc.
+func (r *Relay) Publish(currency string, prices []*cache.PriceResponse) {
+	msg := relayed{Origin: r.origin, Currency: currency, Prices: make([]cache.PriceResponse, 0, len(prices))}
+	for _, p := range prices {
+		if p != nil {
+			price := *p
+			price.Signature, price.Formatted, price.RiskFlags = nil, nil, nil
+			msg.Prices = append(msg.Prices, price)
+		}
+	}
+	select {
+	case r.queue <- msg:
+	default:
+		r.mu.Lock()
+		r.dropped++
+		r.mu.Unlock()
+	}
+}
+
+// Run publishes queued refreshes and delivers those of other replicas
+// until ctx is done, reconnecting to Redis as needed. It returns once
+// its connections are closed.
+func (r *Relay) Run(ctx context.Context) {
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		r.publishLoop(ctx)
+	}()
+	defer func() { <-published }()
+	backoff := time.Second
+	for {
+		start := time.Now()
+		err := r.subscribe(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(start) > relayBackoff {
+			backoff = time.Second
+		}
+		log.Printf("Stream relay: subscription to %s: %v; reconnecting in %v", r.opts.Channel, err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > relayBackoff {
+			backoff = relayBackoff
+		}
+	}
+}
+
+// publishLoop publishes queued refreshes on one connection, redialing
+// after a failure; refreshes are dropped while Redis is unreachable
+func (r *Relay) publishLoop(ctx context.Context) {
+	var conn *redisConn
+	var retryAt time.Time
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+	for {
+		var msg relayed
+		select {
+		case <-ctx.Done():
+			return
+		case msg = <-r.queue:
+		}
+		r.mu.Lock()
+		if r.dropped > 0 {
+			log.Printf("Stream relay: dropped %d refreshes while Redis was behind", r.dropped)
+			r.dropped = 0
+		}
+		r.mu.Unlock()
+
+		data, err := json.Marshal(msg)
+		if err != nil {
+			continue
+		}
+		if conn == nil {
+			if time.Now().Before(retryAt) {
+				continue
+			}
+			if conn, err = r.dial(ctx); err != nil {
+				log.Printf("Stream relay: %v", err)
+				conn, retryAt = nil, time.Now().Add(5*time.Second)
+				continue
+			}
+		}
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if _, err := conn.do("PUBLISH", r.opts.Channel, string(data)); err != nil {
+			log.Printf("Stream relay: publishing: %v", err)
+			conn.Close()
+			conn = nil
+		}
+	}
+}
+
+// subscribe listens on the channel until the connection fails or ctx is
+// done, delivering refreshes from other replicas
+func (r *Relay) subscribe(ctx context.Context) error {
+	conn, err := r.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := conn.send("SUBSCRIBE", r.opts.Channel); err != nil {
+		return err
+	}
+	for {
+		reply, err := conn.read()
+		if err != nil {
+			return err
+		}
+		parts, ok := reply.([]interface{})
+		if !ok || len(parts) != 3 {
+			continue
+		}
+		if kind, _ := parts[0].(string); kind != "message" {
+			continue
+		}
+		payload, _ := parts[2].(string)
+		var msg relayed
+		if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+			log.Printf("Stream relay: undecodable message on %s: %v", r.opts.Channel, err)
+			continue
+		}
+		if msg.Origin == r.origin {
+			continue
+		}
+		prices := make([]*cache.PriceResponse, len(msg.Prices))
+		for i := range msg.Prices {
+			prices[i] = &msg.Prices[i]
+		}
+		r.deliver(msg.Currency, prices)
+	}
+}
+
+// dial connects and authenticates to Redis
+func (r *Relay) dial(ctx context.Context) (*redisConn, error) {
+	d := net.Dialer{Timeout: 5 * time.Second}
+	var nc net.Conn
+	var err error
+	if r.useTLS {
+		host, _, _ := net.SplitHostPort(r.addr)
+		td := tls.Dialer{NetDialer: &d, Config: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}}
+		nc, err = td.DialContext(ctx, "tcp", r.addr)
+	} else {
+		nc, err = d.DialContext(ctx, "tcp", r.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connecting to redis: %w", err)
+	}
+	conn := &redisConn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+	if r.pass != "" {
+		args := []string{"AUTH", r.pass}
+		if r.user != "" {
+			args = []string{"AUTH", r.user, r.pass}
+		}
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if _, err := conn.do(args...); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("redis auth: %w", err)
+		}
+		conn.SetDeadline(time.Time{})
+	}
+	return conn, nil
+}
+
+// redisConn speaks the Redis protocol (RESP) over a connection
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+// send writes a command
+func (c *redisConn) send(args ...string) error {
+	fmt.Fprintf(c.w, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(c.w, "$%d\r\n%s\r\n", len(a), a)
+	}
+	return c.w.Flush()
+}
+
+// do sends a command and reads its reply
+func (c *redisConn) do(args ...string) (interface{}, error) {
+	if err := c.send(args...); err != nil {
+		return nil, err
+	}
+	return c.read()
+}
+
+// read reads one reply: a string, an integer, nil or an array of them.
+// Error replies are returned as errors.
+func (c *redisConn) read() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, errors.New(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// bufConn returns a redisConn reading in and writing to the buffer returned
+func bufConn(in string) (*redisConn, *bytes.Buffer) {
+	var out bytes.Buffer
+	return &redisConn{r: bufio.NewReader(strings.NewReader(in)), w: bufio.NewWriter(&out)}, &out
+}
+
+func TestRESPSend(t *testing.T) {
+	c, out := bufConn("")
+	if err := c.send("PUBLISH", "pricing:prices", "héllo\r\n", ""); err != nil {
+		t.Fatal(err)
+	}
+	// Bulk string lengths count bytes, and payloads may hold CRLF
+	want := "*4\r\n$7\r\nPUBLISH\r\n$14\r\npricing:prices\r\n$8\r\nhéllo\r\n\r\n$0\r\n\r\n"
+	if got := out.String(); got != want {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+}
+
+func TestRESPRead(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want interface{}
+	}{
+		{"simple string", "+OK\r\n", "OK"},
+		{"integer", ":-42\r\n", int64(-42)},
+		{"bulk string", "$8\r\nhé\r\nllo\r\n", "hé\r\nllo"},
+		{"empty bulk string", "$0\r\n\r\n", ""},
+		{"null bulk string", "$-1\r\n", nil},
+		{"message", "*3\r\n$7\r\nmessage\r\n$2\r\nch\r\n$2\r\n{}\r\n", []interface{}{"message", "ch", "{}"}},
+		{"nested array", "*2\r\n:1\r\n*1\r\n+x\r\n", []interface{}{int64(1), []interface{}{"x"}}},
+		{"empty array", "*0\r\n", []interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := bufConn(tt.in)
+			got, err := c.read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("read %q = %#v, want %#v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRESPReadErrors(t *testing.T) {
+	for _, in := range []string{
+		"-ERR unknown command\r\n",
+		"-WRONGPASS invalid username-password pair\r\n",
+		"?what\r\n",
+		"\r\n",
+		"$5\r\nab\r\n", // shorter than declared
+		"*2\r\n+a\r\n", // missing item
+		":x\r\n",
+	} {
+		c, _ := bufConn(in)
+		if got, err := c.read(); err == nil {
+			t.Errorf("read %q = %#v, want an error", in, got)
+		}
+	}
+	c, _ := bufConn("-ERR unknown command\r\n")
+	if _, err := c.read(); err == nil || err.Error() != "ERR unknown command" {
+		t.Errorf("error reply read as %v", err)
+	}
+}
+
+// redisServer is a Redis server implementing AUTH, SUBSCRIBE and PUBLISH
+type redisServer struct {
+	ln       net.Listener
+	password string
+
+	mu          sync.Mutex
+	commands    [][]interface{}
+	subscribers map[*redisConn]*sync.Mutex
+}
+
+func newRedisServer(t *testing.T, password string) *redisServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &redisServer{ln: ln, password: password, subscribers: make(map[*redisConn]*sync.Mutex)}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(&redisConn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)})
+		}
+	}()
+	return s
+}
+
+func (s *redisServer) serve(c *redisConn) {
+	defer c.Close()
+	var wmu sync.Mutex
+	reply := func(line string) {
+		wmu.Lock()
+		c.w.WriteString(line)
+		c.w.Flush()
+		wmu.Unlock()
+	}
+	authed := s.password == ""
+	for {
+		v, err := c.read()
+		if err != nil {
+			s.mu.Lock()
+			delete(s.subscribers, c)
+			s.mu.Unlock()
+			return
+		}
+		cmd, _ := v.([]interface{})
+		s.mu.Lock()
+		s.commands = append(s.commands, cmd)
+		s.mu.Unlock()
+		if len(cmd) == 0 {
+			reply("-ERR empty command\r\n")
+			continue
+		}
+		switch name, _ := cmd[0].(string); {
+		case name == "AUTH":
+			if cmd[len(cmd)-1] != s.password {
+				reply("-WRONGPASS invalid username-password pair\r\n")
+				continue
+			}
+			authed = true
+			reply("+OK\r\n")
+		case !authed:
+			reply("-NOAUTH Authentication required.\r\n")
+		case name == "SUBSCRIBE":
+			s.mu.Lock()
+			s.subscribers[c] = &wmu
+			s.mu.Unlock()
+			ch := cmd[1].(string)
+			reply("*3\r\n$9\r\nsubscribe\r\n$" + strconv.Itoa(len(ch)) + "\r\n" + ch + "\r\n:1\r\n")
+		case name == "PUBLISH":
+			ch, msg := cmd[1].(string), cmd[2].(string)
+			s.mu.Lock()
+			for sub, mu := range s.subscribers {
+				mu.Lock()
+				sub.send("message", ch, msg)
+				mu.Unlock()
+			}
+			n := len(s.subscribers)
+			s.mu.Unlock()
+			reply(":" + strconv.Itoa(n) + "\r\n")
+		default:
+			reply("-ERR unknown command\r\n")
+		}
+	}
+}
+
+// subscribed returns how many connections are subscribed
+func (s *redisServer) subscribed() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers)
+}
+
+// deliveries collects what a relay delivers
+type deliveries struct {
+	ch chan []cache.PriceResponse
+}
+
+func (d *deliveries) deliver(currency string, prices []*cache.PriceResponse) {
+	var got []cache.PriceResponse
+	for _, p := range prices {
+		got = append(got, *p)
+	}
+	d.ch <- got
+}
+
+func TestRelayBetweenReplicas(t *testing.T) {
+	srv := newRedisServer(t, "secret")
+	url := "redis://secret@" + srv.ln.Addr().String()
+	a, b := &deliveries{ch: make(chan []cache.PriceResponse, 4)}, &deliveries{ch: make(chan []cache.PriceResponse, 4)}
+	ra, err := NewRelay(RelayOptions{URL: url}, a.deliver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rb, err := NewRelay(RelayOptions{URL: url}, b.deliver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for _, r := range []*Relay{ra, rb} {
+		wg.Add(1)
+		go func(r *Relay) {
+			defer wg.Done()
+			r.Run(ctx)
+		}(r)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.subscribed() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("relays did not subscribe")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ra.Publish("usd", []*cache.PriceResponse{{ID: "bitcoin", Price: 65000, Signature: &wire.PriceSignature{}}, nil})
+	select {
+	case got := <-b.ch:
+		if len(got) != 1 || got[0].ID != "bitcoin" || got[0].Price != 65000 || got[0].Signature != nil {
+			t.Errorf("b got %+v, want bitcoin at 65000 without its signature", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("b received nothing")
+	}
+	select {
+	case got := <-a.ch:
+		t.Errorf("a received its own refresh %+v", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	done := make(chan struct{})
+	go func() { wg.Wait(); close(done) }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if first := srv.commands[0]; !reflect.DeepEqual(first, []interface{}{"AUTH", "secret"}) {
+		t.Errorf("first command %q, want AUTH secret", first)
+	}
+}
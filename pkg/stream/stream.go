@@ -0,0 +1,297 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package stream fans refreshed prices out to streaming subscribers
+// through a registry of subscriptions per token.
+package stream
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+)
+
+const (
+	// DefaultMaxClients is the most concurrent subscriptions if none is
+	// configured
+	DefaultMaxClients = 10000
+
+	// DefaultMaxLag is how long a subscriber may leave updates undelivered
+	// before it is evicted, if none is configured
+	DefaultMaxLag = 30 * time.Second
+
+	// MaxTokens is the most tokens one subscription may follow
+	MaxTokens = 100
+)
+
+var (
+	// ErrFull is returned when MaxClients subscriptions are open
+	ErrFull = errors.New("too many stream clients")
+
+	// ErrSlow is a subscription's error once it is evicted for leaving
+	// updates undelivered longer than MaxLag
+	ErrSlow = errors.New("client too slow to keep up")
+)
+
+// Options configures a Hub
+type Options struct {
+	MaxClients int           // DefaultMaxClients if zero
+	MaxLag     time.Duration // DefaultMaxLag if zero
+}
+
+// Stats counts a hub's subscribers
+type Stats struct {
+	Clients int    `json:"clients"`
+	Tokens  int    `json:"tokens"`  // token and currency pairs followed
+	Evicted uint64 `json:"evicted"` // subscribers evicted as too slow
+}
+
+// Hub registers subscriptions per token and currency and delivers each
+// refreshed price to the subscriptions following it. Publishing never
+// blocks on a subscriber: updates are coalesced per token until the
+// subscriber takes them, so a slow subscriber costs at most one pending
+// price per token it follows, and is evicted once it falls MaxLag behind.
+type Hub struct {
+	opts    Options
+	evicted atomic.Uint64
+
+	mu      sync.RWMutex
+	clients int
+	subs    map[string]map[*Subscription]struct{} // token:currency -> subscribers
+}
+
+// NewHub creates a hub without subscribers
+func NewHub(opts Options) *Hub {
+	if opts.MaxClients <= 0 {
+		opts.MaxClients = DefaultMaxClients
+	}
+	if opts.MaxLag <= 0 {
+		opts.MaxLag = DefaultMaxLag
+	}
+	return &Hub{opts: opts, subs: make(map[string]map[*Subscription]struct{})}
+}
+
+// MaxLag returns how long a subscriber may fall behind before eviction
+func (h *Hub) MaxLag() time.Duration {
+	return h.opts.MaxLag
+}
+
+// Subscription is one subscriber's interest in a set of tokens in a
+// currency
+type Subscription struct {
+	hub      *Hub
+	currency string
+	keys     []string
+	ready    chan struct{} // signaled when updates are pending
+	done     chan struct{} // closed when the subscription ends
+	once     sync.Once
+
+	mu      sync.Mutex
+	pending map[string]cache.PriceResponse // latest undelivered price per key
+	since   time.Time                      // when the oldest pending update arrived
+	err     error
+}
+
+// Subscribe registers a subscription to the prices of tokenIDs in
+// currency. The caller must Close it when done.
+func (h *Hub) Subscribe(tokenIDs []string, currency string) (*Subscription, error) {
+	currency = strings.ToLower(currency)
+	if len(tokenIDs) > MaxTokens {
+		tokenIDs = tokenIDs[:MaxTokens]
+	}
+	sub := &Subscription{
+		hub:      h,
+		currency: currency,
+		ready:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		pending:  make(map[string]cache.PriceResponse),
+	}
+	seen := make(map[string]bool, len(tokenIDs))
+	for _, id := range tokenIDs {
+		key := strings.ToLower(id) + ":" + currency
+		if !seen[key] {
+			seen[key] = true
+			sub.keys = append(sub.keys, key)
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.clients >= h.opts.MaxClients {
+		return nil, ErrFull
+	}
+	h.clients++
+	for _, key := range sub.keys {
+		if h.subs[key] == nil {
+			h.subs[key] = make(map[*Subscription]struct{})
+		}
+		h.subs[key][sub] = struct{}{}
+	}
+	return sub, nil
+}
+
+// Publish delivers refreshed prices to their subscribers and evicts those
+// that have fallen too far behind. It is a cache.RefreshFunc.
+func (h *Hub) Publish(currency string, prices []*cache.PriceResponse) {
+	currency = strings.ToLower(currency)
+	now := time.Now()
+	var slow []*Subscription
+
+	h.mu.RLock()
+	for _, p := range prices {
+		if p == nil {
+			continue
+		}
+		key := p.ID + ":" + currency
+		for sub := range h.subs[key] {
+			if !sub.offer(key, p, now) {
+				slow = append(slow, sub)
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, sub := range slow {
+		sub.end(ErrSlow)
+	}
+}
+
+// offer queues a price for the subscriber, replacing any undelivered one
+// for the same token. It returns false if the subscriber has left updates
+// undelivered for longer than MaxLag.
+func (s *Subscription) offer(key string, p *cache.PriceResponse, now time.Time) bool {
+	s.mu.Lock()
+	if s.err != nil {
+		s.mu.Unlock()
+		return true
+	}
+	if len(s.pending) > 0 && now.Sub(s.since) > s.hub.opts.MaxLag {
+		s.mu.Unlock()
+		return false
+	}
+	if len(s.pending) == 0 {
+		s.since = now
+	}
+	price := *p
+	price.Currency = s.currency
+	price.Signature, price.Formatted, price.RiskFlags = nil, nil, nil
+	s.pending[key] = price
+	s.mu.Unlock()
+
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// Ready is signaled when updates are pending; take them with Next
+func (s *Subscription) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// Done is closed when the subscription ends, by Close or by eviction
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why the subscription ended, or nil if it is open or was
+// closed by its subscriber
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Next takes the pending updates, at most one per token, sorted by token
+func (s *Subscription) Next() []cache.PriceResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]cache.PriceResponse, 0, len(s.pending))
+	for key, p := range s.pending {
+		out = append(out, p)
+		delete(s.pending, key)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Close ends the subscription and unregisters it
+func (s *Subscription) Close() {
+	s.end(nil)
+}
+
+// end unregisters the subscription once, recording err
+func (s *Subscription) end(err error) {
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.err = err
+		s.pending = nil
+		s.mu.Unlock()
+		if errors.Is(err, ErrSlow) {
+			s.hub.evicted.Add(1)
+		}
+
+		h := s.hub
+		h.mu.Lock()
+		h.clients--
+		for _, key := range s.keys {
+			delete(h.subs[key], s)
+			if len(h.subs[key]) == 0 {
+				delete(h.subs, key)
+			}
+		}
+		h.mu.Unlock()
+		close(s.done)
+	})
+}
+
+// Stats returns the number of subscribers and tokens followed
+func (h *Hub) Stats() Stats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return Stats{Clients: h.clients, Tokens: len(h.subs), Evicted: h.evicted.Load()}
+}
+
+// Watched returns the tokens followed by any subscriber, by currency
+func (h *Hub) Watched() map[string][]string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	watched := make(map[string][]string)
+	for key := range h.subs {
+		token, currency, _ := strings.Cut(key, ":")
+		watched[currency] = append(watched[currency], token)
+	}
+	for _, tokens := range watched {
+		sort.Strings(tokens)
+	}
+	return watched
+}
+
+// Run refreshes the tokens subscribers follow every interval until ctx is
+// done, so they are streamed even when no client requests them. refresh
+// is typically PriceCache.GetMultiplePrices, whose refresh hook calls
+// Publish.
+func (h *Hub) Run(ctx context.Context, interval time.Duration, refresh func(ctx context.Context, tokenIDs []string, currency string) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for currency, tokens := range h.Watched() {
+			if err := refresh(ctx, tokens, currency); err != nil && !errors.Is(err, context.Canceled) {
+				log.Printf("Refreshing streamed prices in %s: %v", currency, err)
+			}
+		}
+	}
+}
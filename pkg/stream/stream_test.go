@@ -0,0 +1,269 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package stream
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/providers"
+)
+
+// quoter is a provider quoting every token at the same price
+type quoter struct {
+	price float64
+}
+
+func (q *quoter) Name() string { return "quoter" }
+
+func (q *quoter) FetchPrice(ctx context.Context, tokenID, currency string) (*providers.Price, error) {
+	return &providers.Price{ID: tokenID, Symbol: tokenID, CurrentPrice: q.price}, nil
+}
+
+func (q *quoter) FetchPrices(ctx context.Context, tokenIDs []string, currency string) ([]providers.Price, error) {
+	prices := make([]providers.Price, len(tokenIDs))
+	for i, id := range tokenIDs {
+		prices[i] = providers.Price{ID: id, Symbol: id, CurrentPrice: q.price}
+	}
+	return prices, nil
+}
+
+func price(id string, p float64) *cache.PriceResponse {
+	return &cache.PriceResponse{ID: id, Price: p, UpdatedAt: time.Now()}
+}
+
+// prices returns the id and price of each update
+func prices(updates []cache.PriceResponse) map[string]float64 {
+	m := make(map[string]float64, len(updates))
+	for _, p := range updates {
+		m[p.ID] = p.Price
+	}
+	return m
+}
+
+// wait fails the test unless ch is signaled or closed within a second
+func wait(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
+
+func TestSubscribeAndClose(t *testing.T) {
+	h := NewHub(Options{MaxClients: 2})
+
+	a, err := h.Subscribe([]string{"Bitcoin", "bitcoin", "ethereum"}, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a.keys, []string{"bitcoin:usd", "ethereum:usd"}) {
+		t.Errorf("keys %v, want bitcoin and ethereum in usd once each", a.keys)
+	}
+	b, err := h.Subscribe([]string{"bitcoin"}, "eur")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Subscribe([]string{"solana"}, "usd"); !errors.Is(err, ErrFull) {
+		t.Errorf("third subscription: %v, want ErrFull", err)
+	}
+
+	if got := h.Stats(); got.Clients != 2 || got.Tokens != 3 {
+		t.Errorf("stats %+v, want 2 clients following 3 tokens", got)
+	}
+	want := map[string][]string{"usd": {"bitcoin", "ethereum"}, "eur": {"bitcoin"}}
+	if got := h.Watched(); !reflect.DeepEqual(got, want) {
+		t.Errorf("watched %v, want %v", got, want)
+	}
+
+	a.Close()
+	a.Close()
+	wait(t, a.Done(), "Done after Close")
+	if a.Err() != nil {
+		t.Errorf("Err after Close = %v, want nil", a.Err())
+	}
+	if got := h.Stats(); got.Clients != 1 || got.Tokens != 1 {
+		t.Errorf("stats after Close %+v, want 1 client following 1 token", got)
+	}
+	h.Publish("usd", []*cache.PriceResponse{price("bitcoin", 1)})
+	if len(a.pending) != 0 {
+		t.Error("a closed subscription was offered an update")
+	}
+
+	// The slot is free again
+	if _, err := h.Subscribe([]string{"solana"}, "usd"); err != nil {
+		t.Errorf("subscribing after Close: %v", err)
+	}
+	b.Close()
+}
+
+func TestSubscribeTruncates(t *testing.T) {
+	h := NewHub(Options{})
+	ids := make([]string, MaxTokens+10)
+	for i := range ids {
+		ids[i] = string(rune('a'+i%26)) + string(rune('a'+i/26))
+	}
+	sub, err := h.Subscribe(ids, "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	if len(sub.keys) != MaxTokens {
+		t.Errorf("following %d tokens, want MaxTokens", len(sub.keys))
+	}
+}
+
+func TestFanOutOnRefresh(t *testing.T) {
+	h := NewHub(Options{})
+	pc := cache.NewPriceCache(&quoter{price: 42})
+	pc.OnRefresh(h.Publish)
+
+	a, err := h.Subscribe([]string{"bitcoin", "ethereum"}, "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, err := h.Subscribe([]string{"bitcoin"}, "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	c, err := h.Subscribe([]string{"bitcoin"}, "eur")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := pc.GetMultiplePrices(context.Background(), []string{"bitcoin", "ethereum", "solana"}, "usd"); err != nil {
+		t.Fatal(err)
+	}
+
+	wait(t, a.Ready(), "a's updates")
+	if got, want := prices(a.Next()), map[string]float64{"bitcoin": 42, "ethereum": 42}; !reflect.DeepEqual(got, want) {
+		t.Errorf("a got %v, want %v", got, want)
+	}
+	wait(t, b.Ready(), "b's updates")
+	updates := b.Next()
+	if got, want := prices(updates), map[string]float64{"bitcoin": 42}; !reflect.DeepEqual(got, want) {
+		t.Errorf("b got %v, want %v", got, want)
+	}
+	if updates[0].Currency != "usd" {
+		t.Errorf("update currency %q, want usd", updates[0].Currency)
+	}
+	select {
+	case <-c.Ready():
+		t.Errorf("the eur subscriber got usd updates %v", c.Next())
+	default:
+	}
+}
+
+func TestCoalescesPendingUpdates(t *testing.T) {
+	h := NewHub(Options{})
+	sub, err := h.Subscribe([]string{"bitcoin", "ethereum"}, "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	h.Publish("usd", []*cache.PriceResponse{price("bitcoin", 1), price("ethereum", 10)})
+	h.Publish("usd", []*cache.PriceResponse{price("bitcoin", 2), nil})
+	h.Publish("usd", []*cache.PriceResponse{price("bitcoin", 3)})
+
+	wait(t, sub.Ready(), "updates")
+	updates := sub.Next()
+	if len(updates) != 2 || updates[0].ID != "bitcoin" || updates[0].Price != 3 || updates[1].Price != 10 {
+		t.Errorf("updates %+v, want the latest bitcoin then ethereum", updates)
+	}
+	if got := sub.Next(); len(got) != 0 {
+		t.Errorf("second Next %+v, want nothing pending", got)
+	}
+}
+
+func TestEvictsSlowConsumer(t *testing.T) {
+	const maxLag = 20 * time.Millisecond
+	h := NewHub(Options{MaxLag: maxLag})
+	slow, err := h.Subscribe([]string{"bitcoin"}, "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast, err := h.Subscribe([]string{"bitcoin"}, "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fast.Close()
+
+	h.Publish("usd", []*cache.PriceResponse{price("bitcoin", 1)})
+	fast.Next()
+	time.Sleep(2 * maxLag)
+	// slow has left its update pending past MaxLag; fast has not
+	h.Publish("usd", []*cache.PriceResponse{price("bitcoin", 2)})
+
+	wait(t, slow.Done(), "the slow subscriber's eviction")
+	if !errors.Is(slow.Err(), ErrSlow) {
+		t.Errorf("Err = %v, want ErrSlow", slow.Err())
+	}
+	if got := slow.Next(); len(got) != 0 {
+		t.Errorf("evicted subscriber still holds %+v", got)
+	}
+	if got := h.Stats(); got.Clients != 1 || got.Evicted != 1 {
+		t.Errorf("stats %+v, want 1 client left and 1 evicted", got)
+	}
+
+	if got := prices(fast.Next()); got["bitcoin"] != 2 {
+		t.Errorf("fast subscriber got %v, want bitcoin at 2", got)
+	}
+	select {
+	case <-fast.Done():
+		t.Errorf("fast subscriber ended: %v", fast.Err())
+	default:
+	}
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	h := NewHub(Options{})
+	sub, err := h.Subscribe([]string{"bitcoin"}, "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	var mu sync.Mutex
+	var refreshed []string
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.Run(ctx, time.Millisecond, func(ctx context.Context, tokenIDs []string, currency string) error {
+			mu.Lock()
+			refreshed = append(refreshed, currency+":"+tokenIDs[0])
+			mu.Unlock()
+			return nil
+		})
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n := len(refreshed)
+		mu.Unlock()
+		if n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Run never refreshed the watched tokens")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	wait(t, done, "Run to return after cancel")
+	if refreshed[0] != "usd:bitcoin" {
+		t.Errorf("refreshed %v, want usd:bitcoin", refreshed)
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package supply
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+const (
+	// DefaultChangeThreshold is the day-over-day change of circulating
+	// supply flagged when unset
+	DefaultChangeThreshold = 0.05
+
+	// DefaultChangeRetention is how long daily supply is kept when unset
+	DefaultChangeRetention = 90 * 24 * time.Hour
+
+	// dateLayout is the layout of the UTC days supply is kept by
+	dateLayout = "2006-01-02"
+)
+
+// Change is a token's circulating supply on a day against the last day
+// it was seen before
+type Change = wire.SupplyChange
+
+// CirculatingFetcher fetches the circulating supply providers report,
+// by token id
+type CirculatingFetcher func(ctx context.Context) (map[string]float64, error)
+
+// ChangeOptions configures a ChangeTracker
+type ChangeOptions struct {
+	// File persists daily supply across restarts, so the first check
+	// after one still has the day before to compare with; memory only if
+	// empty
+	File string
+
+	// Threshold is the relative day-over-day change flagged;
+	// DefaultChangeThreshold if 0
+	Threshold float64
+
+	// Retention is how long daily supply is kept; DefaultChangeRetention
+	// if 0
+	Retention time.Duration
+
+	// Notify receives each flagged change, once per token and day, if set
+	Notify func(Change)
+}
+
+// sample is a token's circulating supply last seen on a day
+type sample struct {
+	Date   string    `json:"date"`
+	Supply float64   `json:"supply"`
+	Time   time.Time `json:"time"`
+}
+
+// changeState is what the tracker persists
+type changeState struct {
+	Samples   map[string][]sample `json:"samples"` // by token, oldest day first
+	Alerted   map[string]string   `json:"alerted"` // day each token was last flagged
+	CheckedAt time.Time           `json:"checked_at"`
+}
+
+// ChangeTracker keeps the daily circulating supply of tokens and flags
+// the days it jumps, such as token unlocks and mints
+type ChangeTracker struct {
+	opts  ChangeOptions
+	fetch CirculatingFetcher
+
+	mu     sync.RWMutex
+	state  changeState
+	alerts int64 // flagged changes notified since start
+}
+
+// NewChangeTracker loads daily supply from opts.File, or starts empty if
+// it does not exist
+func NewChangeTracker(opts ChangeOptions, fetch CirculatingFetcher) (*ChangeTracker, error) {
+	if opts.Threshold <= 0 {
+		opts.Threshold = DefaultChangeThreshold
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = DefaultChangeRetention
+	}
+	t := &ChangeTracker{
+		opts:  opts,
+		fetch: fetch,
+		state: changeState{Samples: make(map[string][]sample), Alerted: make(map[string]string)},
+	}
+	if opts.File == "" {
+		return t, nil
+	}
+
+	data, err := os.ReadFile(opts.File)
+	if os.IsNotExist(err) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &t.state); err != nil {
+		return nil, fmt.Errorf("supply changes file %s: %w", opts.File, err)
+	}
+	if t.state.Samples == nil {
+		t.state.Samples = make(map[string][]sample)
+	}
+	if t.state.Alerted == nil {
+		t.state.Alerted = make(map[string]string)
+	}
+	return t, nil
+}
+
+// Check fetches the circulating supply of every token, records it as its
+// supply for the day and notifies the tokens whose change from the day
+// before is flagged for the first time today
+func (t *ChangeTracker) Check(ctx context.Context) error {
+	supplies, err := t.fetch(ctx)
+	if err != nil && len(supplies) == 0 {
+		return err
+	}
+
+	now := time.Now().UTC()
+	today := now.Format(dateLayout)
+	cutoff := now.Add(-t.opts.Retention).Format(dateLayout)
+	var flagged []Change
+
+	t.mu.Lock()
+	for id, supply := range supplies {
+		if supply <= 0 || math.IsInf(supply, 0) || math.IsNaN(supply) {
+			continue
+		}
+		samples := t.state.Samples[id]
+		if n := len(samples); n > 0 && samples[n-1].Date == today {
+			samples[n-1] = sample{Date: today, Supply: supply, Time: now}
+		} else {
+			samples = append(samples, sample{Date: today, Supply: supply, Time: now})
+		}
+		t.state.Samples[id] = samples
+
+		if n := len(samples); n > 1 && t.state.Alerted[id] != today {
+			if c := t.change(id, samples[n-2], samples[n-1]); c.Flagged {
+				t.state.Alerted[id] = today
+				flagged = append(flagged, c)
+			}
+		}
+	}
+	for id, samples := range t.state.Samples {
+		keep := sort.Search(len(samples), func(i int) bool { return samples[i].Date >= cutoff })
+		switch {
+		case keep == len(samples):
+			delete(t.state.Samples, id)
+			delete(t.state.Alerted, id)
+		case keep > 0:
+			t.state.Samples[id] = append(samples[:0:0], samples[keep:]...)
+		}
+	}
+	t.state.CheckedAt = now
+	t.alerts += int64(len(flagged))
+	saveErr := t.save()
+	t.mu.Unlock()
+
+	if t.opts.Notify != nil {
+		sort.Slice(flagged, func(i, j int) bool { return flagged[i].Token < flagged[j].Token })
+		for _, c := range flagged {
+			t.opts.Notify(c)
+		}
+	}
+	return errors.Join(err, saveErr)
+}
+
+// change compares a token's supply on one day with the one before
+func (t *ChangeTracker) change(id string, prev, cur sample) Change {
+	c := Change{
+		Token:    id,
+		Date:     cur.Date,
+		Since:    prev.Date,
+		Previous: prev.Supply,
+		Current:  cur.Supply,
+		Change:   cur.Supply/prev.Supply - 1,
+		Time:     cur.Time,
+	}
+	c.Flagged = math.Abs(c.Change) > t.opts.Threshold
+	return c
+}
+
+// Changes returns a token's day-over-day changes over the last days,
+// oldest first, and whether the token is tracked
+func (t *ChangeTracker) Changes(tokenID string, days int) ([]Change, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	samples, ok := t.state.Samples[tokenID]
+	if !ok {
+		return nil, false
+	}
+	cutoff := time.Now().UTC().AddDate(0, 0, -days).Format(dateLayout)
+	changes := []Change{}
+	for i := 1; i < len(samples); i++ {
+		if samples[i].Date > cutoff {
+			changes = append(changes, t.change(tokenID, samples[i-1], samples[i]))
+		}
+	}
+	return changes, true
+}
+
+// Flagged returns the flagged changes of every token from the day of
+// since on, newest first
+func (t *ChangeTracker) Flagged(since time.Time) []Change {
+	after := since.UTC().Format(dateLayout)
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	flagged := []Change{}
+	for id, samples := range t.state.Samples {
+		for i := len(samples) - 1; i > 0 && samples[i].Date >= after; i-- {
+			if c := t.change(id, samples[i-1], samples[i]); c.Flagged {
+				flagged = append(flagged, c)
+			}
+		}
+	}
+	sort.Slice(flagged, func(i, j int) bool {
+		if flagged[i].Date != flagged[j].Date {
+			return flagged[i].Date > flagged[j].Date
+		}
+		return flagged[i].Token < flagged[j].Token
+	})
+	return flagged
+}
+
+// Run checks supply every interval until ctx is done
+func (t *ChangeTracker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := t.Check(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("Checking circulating supply changes: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// WritePrometheus writes each token's latest day-over-day change and the
+// alerts raised in the Prometheus text exposition format
+func (t *ChangeTracker) WritePrometheus(w io.Writer) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	ids := make([]string, 0, len(t.state.Samples))
+	for id, samples := range t.state.Samples {
+		if len(samples) > 1 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	fmt.Fprintf(w, "# HELP pricing_supply_change Latest day-over-day change of circulating supply\n# TYPE pricing_supply_change gauge\n")
+	for _, id := range ids {
+		samples := t.state.Samples[id]
+		c := t.change(id, samples[len(samples)-2], samples[len(samples)-1])
+		fmt.Fprintf(w, "pricing_supply_change{token=%q} %g\n", id, c.Change)
+	}
+	fmt.Fprintf(w, "# HELP pricing_supply_change_flagged Whether the latest change of circulating supply is beyond the threshold\n# TYPE pricing_supply_change_flagged gauge\n")
+	for _, id := range ids {
+		samples := t.state.Samples[id]
+		flagged := 0
+		if t.change(id, samples[len(samples)-2], samples[len(samples)-1]).Flagged {
+			flagged = 1
+		}
+		fmt.Fprintf(w, "pricing_supply_change_flagged{token=%q} %d\n", id, flagged)
+	}
+	fmt.Fprintf(w, "# HELP pricing_supply_change_alerts_total Flagged supply changes raised since start\n# TYPE pricing_supply_change_alerts_total counter\npricing_supply_change_alerts_total %d\n", t.alerts)
+}
+
+// save writes the state to the file, replacing it atomically. The caller
+// holds t.mu.
+func (t *ChangeTracker) save() error {
+	if t.opts.File == "" {
+		return nil
+	}
+	data, err := json.Marshal(t.state)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(t.opts.File), filepath.Base(t.opts.File)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), t.opts.File)
+}
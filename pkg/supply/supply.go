@@ -0,0 +1,204 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package supply cross-checks the circulating and total supply providers
+// report against the token's contract on chain, so market caps built on
+// a wrong supply are flagged rather than trusted.
+package supply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/evm"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// DefaultThreshold is the relative difference between the provider's and
+// the chain's figure that is flagged
+const DefaultThreshold = 0.02
+
+var (
+	totalSupplySelector = evm.Selector("totalSupply()")
+	balanceOfSelector   = evm.Selector("balanceOf(address)")
+)
+
+// Token is a token whose supply is read from its ERC-20 contract
+type Token struct {
+	Chain    string
+	RPC      *evm.Client
+	Contract string
+	Decimals int
+
+	// Locked are addresses whose balance doesn't circulate, such as
+	// staking contracts, treasuries and vesting wallets
+	Locked []string
+}
+
+// Figure compares one supply figure between the provider and the chain
+type Figure = wire.SupplyFigure
+
+// Check is a token's supply as reported and as read on chain
+type Check = wire.SupplyCheck
+
+// Fetcher fetches provider data of tokens including their supply, e.g.
+// CoinGecko.FetchPrices
+type Fetcher func(ctx context.Context, tokenIDs []string, currency string) ([]providers.Price, error)
+
+// Options configures a Verifier
+type Options struct {
+	// Tokens maps token ids to their contracts
+	Tokens map[string]Token
+
+	// Threshold is the deviation flagged; DefaultThreshold if 0
+	Threshold float64
+}
+
+// Verifier periodically checks the supply of configured tokens
+type Verifier struct {
+	opts  Options
+	fetch Fetcher
+
+	mu     sync.RWMutex
+	checks map[string]Check
+}
+
+// NewVerifier creates a verifier reading provider supply with fetch
+func NewVerifier(opts Options, fetch Fetcher) *Verifier {
+	if opts.Threshold <= 0 {
+		opts.Threshold = DefaultThreshold
+	}
+	return &Verifier{opts: opts, fetch: fetch, checks: make(map[string]Check)}
+}
+
+// Tokens returns the verified token ids, sorted
+func (v *Verifier) Tokens() []string {
+	ids := make([]string, 0, len(v.opts.Tokens))
+	for id := range v.opts.Tokens {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Check returns the latest check of a token, if it was checked
+func (v *Verifier) Check(tokenID string) (Check, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	c, ok := v.checks[tokenID]
+	return c, ok
+}
+
+// Verify checks every token. A token that fails keeps its previous check.
+func (v *Verifier) Verify(ctx context.Context) error {
+	ids := v.Tokens()
+	prices, err := v.fetch(ctx, ids, "usd")
+	if err != nil && len(prices) == 0 {
+		return err
+	}
+	reported := make(map[string]providers.Price, len(prices))
+	for _, p := range prices {
+		reported[p.ID] = p
+	}
+
+	var errs []error
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, id := range ids {
+		p, ok := reported[id]
+		if !ok {
+			continue
+		}
+		c, err := v.check(ctx, id, v.opts.Tokens[id], p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", id, err))
+			continue
+		}
+		v.mu.Lock()
+		v.checks[id] = c
+		v.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// Run verifies every interval until ctx is done
+func (v *Verifier) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := v.Verify(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("Verifying token supply: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check reads a token's supply on chain and compares it to p's
+func (v *Verifier) check(ctx context.Context, id string, t Token, p providers.Price) (Check, error) {
+	total, err := v.amount(ctx, t, totalSupplySelector)
+	if err != nil {
+		return Check{}, fmt.Errorf("totalSupply: %w", err)
+	}
+	var locked float64
+	for _, addr := range t.Locked {
+		data := append(append([]byte(nil), balanceOfSelector...), evm.AddressWord(addr)...)
+		balance, err := v.amount(ctx, t, data)
+		if err != nil {
+			return Check{}, fmt.Errorf("balanceOf %s: %w", addr, err)
+		}
+		locked += balance
+	}
+
+	c := Check{
+		Token:       id,
+		Chain:       t.Chain,
+		Contract:    t.Contract,
+		Total:       v.compare(p.TotalSupply, total),
+		Circulating: v.compare(p.CirculatingSupply, total-locked),
+		Locked:      locked,
+		CheckedAt:   time.Now().UTC(),
+	}
+	c.Discrepancy = c.Total.Flagged || c.Circulating.Flagged
+	return c, nil
+}
+
+// compare compares a provider's figure to the chain's. Figures the
+// provider doesn't report are not flagged.
+func (v *Verifier) compare(provider, onChain float64) Figure {
+	f := Figure{Provider: provider, OnChain: onChain}
+	if provider == 0 || onChain <= 0 {
+		return f
+	}
+	f.Deviation = provider/onChain - 1
+	f.Flagged = math.Abs(f.Deviation) > v.opts.Threshold
+	return f
+}
+
+// amount calls a contract function returning a token amount, scaled by
+// the token's decimals
+func (v *Verifier) amount(ctx context.Context, t Token, data []byte) (float64, error) {
+	out, err := t.RPC.CallContract(ctx, t.Contract, data)
+	if err != nil {
+		return 0, err
+	}
+	if len(out) < 32 {
+		return 0, fmt.Errorf("%s returned no amount; is it a token contract?", t.Contract)
+	}
+	n := new(big.Float).SetInt(new(big.Int).SetBytes(out[:32]))
+	div := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(t.Decimals)), nil))
+	f, _ := n.Quo(n, div).Float64()
+	return f, nil
+}
@@ -0,0 +1,369 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package ticks records fetched prices and computes time- and
+// volume-weighted averages over them.
+package ticks
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+const (
+	// DefaultRetention is how long ticks are kept
+	DefaultRetention = 24 * time.Hour
+
+	// maxTicks bounds one token's ticks, dropping the oldest
+	maxTicks = 20000
+
+	// maxSeries bounds the number of tokens recorded
+	maxSeries = 4096
+
+	// day is the width of daily averages
+	day = 24 * time.Hour
+)
+
+// ErrNoTicks is returned when no price was recorded in a window
+var ErrNoTicks = errors.New("no ticks in window")
+
+// Tick is a price accepted by the cache
+type Tick struct {
+	Time      time.Time `json:"time"`
+	Price     float64   `json:"price"`
+	Volume24h float64   `json:"volume_24h"`
+}
+
+// Average is a token's time- and volume-weighted average price over a
+// window ending now
+type Average = wire.PriceAverage
+
+// Options configures a Store
+type Options struct {
+	Retention time.Duration // DefaultRetention if zero
+
+	// HourlyRetention keeps ticks older than Retention as hourly averages
+	// until they are this old; they are dropped if zero
+	HourlyRetention time.Duration
+
+	// DailyRetention keeps hourly averages older than HourlyRetention as
+	// daily averages until they are this old, or forever if zero
+	DailyRetention time.Duration
+
+	// Tokens are refreshed by Run in each of Currencies (usd if empty),
+	// so they have ticks even when no client requests them
+	Tokens     []string
+	Currencies []string
+}
+
+// Store holds recent ticks per token and currency
+type Store struct {
+	opts Options
+
+	mu     sync.Mutex
+	series map[string][]Tick
+}
+
+// NewStore creates an empty tick store
+func NewStore(opts Options) *Store {
+	if opts.Retention <= 0 {
+		opts.Retention = DefaultRetention
+	}
+	if len(opts.Currencies) == 0 {
+		opts.Currencies = []string{"usd"}
+	}
+	return &Store{opts: opts, series: make(map[string][]Tick)}
+}
+
+// Retention returns how long ticks are kept
+func (s *Store) Retention() time.Duration {
+	return s.opts.Retention
+}
+
+// MaxWindow returns the longest window averages cover: Retention, or the
+// retention of the longest downsampled tier, or 0 if daily averages are
+// kept forever
+func (s *Store) MaxWindow() time.Duration {
+	switch {
+	case s.opts.HourlyRetention <= 0:
+		return s.opts.Retention
+	case s.opts.DailyRetention <= 0:
+		return 0
+	}
+	return s.opts.DailyRetention
+}
+
+// Record stores prices just fetched; it is a cache.RefreshFunc
+func (s *Store) Record(currency string, prices []*cache.PriceResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range prices {
+		key := p.ID + ":" + strings.ToLower(currency)
+		ticks, ok := s.series[key]
+		if !ok && len(s.series) >= maxSeries {
+			s.expire(time.Now())
+			if len(s.series) >= maxSeries {
+				continue
+			}
+		}
+		if n := len(ticks); n > 0 && !p.UpdatedAt.After(ticks[n-1].Time) {
+			continue
+		}
+		ticks = append(ticks, Tick{Time: p.UpdatedAt, Price: p.Price, Volume24h: p.Volume24h})
+		drop := 0
+		if s.opts.HourlyRetention <= 0 {
+			cutoff := p.UpdatedAt.Add(-s.opts.Retention)
+			drop = sort.Search(len(ticks), func(i int) bool { return ticks[i].Time.After(cutoff) })
+		}
+		if over := len(ticks) - maxTicks; over > drop {
+			drop = over
+		}
+		if drop > 0 {
+			ticks = append(ticks[:0:0], ticks[drop:]...)
+		}
+		s.series[key] = ticks
+	}
+}
+
+// expire drops tokens whose latest tick is older than the longest window,
+// if it is bounded. The caller holds s.mu.
+func (s *Store) expire(now time.Time) {
+	window := s.MaxWindow()
+	for key, ticks := range s.series {
+		if len(ticks) == 0 || window > 0 && now.Sub(ticks[len(ticks)-1].Time) > window {
+			delete(s.series, key)
+		}
+	}
+}
+
+// Average returns the TWAP and VWAP of a token over the window ending
+// now. The tick before the window, if any, sets the price at its start;
+// each tick's price holds until the next. VWAP weights each interval by
+// the 24h volume reported with its price, as per-trade volume is not
+// available. Beyond Retention, hourly and daily averages stand in for the
+// ticks they replaced.
+func (s *Store) Average(tokenID, currency string, window time.Duration) (*Average, error) {
+	tokenID, currency = strings.ToLower(tokenID), strings.ToLower(currency)
+	now := time.Now()
+	start := now.Add(-window)
+
+	s.mu.Lock()
+	all := s.series[tokenID+":"+currency]
+	first := sort.Search(len(all), func(i int) bool { return all[i].Time.After(start) })
+	if first == len(all) {
+		s.mu.Unlock()
+		return nil, ErrNoTicks
+	}
+	if first > 0 {
+		first--
+	}
+	ticks := append([]Tick(nil), all[first:]...)
+	s.mu.Unlock()
+
+	from := ticks[0].Time
+	if from.Before(start) {
+		from = start
+	}
+	var twap, vwap, volume float64
+	for i, t := range ticks {
+		begin, end := t.Time, now
+		if begin.Before(start) {
+			begin = start
+		}
+		if i+1 < len(ticks) {
+			end = ticks[i+1].Time
+		}
+		dt := end.Sub(begin).Seconds()
+		twap += t.Price * dt
+		vwap += t.Price * t.Volume24h * dt
+		volume += t.Volume24h * dt
+	}
+
+	avg := &Average{
+		Token:    tokenID,
+		Currency: currency,
+		Window:   window.String(),
+		From:     from.UTC(),
+		To:       now.UTC(),
+		Spot:     ticks[len(ticks)-1].Price,
+		Ticks:    len(ticks),
+	}
+	if span := now.Sub(from).Seconds(); span > 0 {
+		avg.TWAP = twap / span
+	} else {
+		avg.TWAP = avg.Spot
+	}
+	if volume > 0 {
+		v := vwap / volume
+		avg.VWAP = &v
+	}
+	return avg, nil
+}
+
+// Close is a token's prices over one calendar day in a time zone
+type Close = wire.DailyClose
+
+// Close returns a token's close and range over the day starting at date,
+// a midnight in the time zone of the day; the day ends at the next
+// midnight there, so days are 23 or 25 hours long across DST changes.
+// Beyond Retention, hourly and daily averages stand in for the ticks they
+// replaced, so the close is the average of the day's last hour or day.
+func (s *Store) Close(tokenID, currency string, date time.Time) (*Close, error) {
+	tokenID, currency = strings.ToLower(tokenID), strings.ToLower(currency)
+	start, end := date, date.AddDate(0, 0, 1)
+
+	s.mu.Lock()
+	all := s.series[tokenID+":"+currency]
+	first := sort.Search(len(all), func(i int) bool { return !all[i].Time.Before(start) })
+	last := sort.Search(len(all), func(i int) bool { return !all[i].Time.Before(end) })
+	if first == last {
+		s.mu.Unlock()
+		return nil, ErrNoTicks
+	}
+	open := all[first].Price
+	if first > 0 {
+		open = all[first-1].Price
+	}
+	day := append([]Tick(nil), all[first:last]...)
+	s.mu.Unlock()
+
+	c := &Close{
+		Token:    tokenID,
+		Currency: currency,
+		Date:     date.Format("2006-01-02"),
+		TimeZone: date.Location().String(),
+		From:     start,
+		To:       end,
+		Open:     open,
+		High:     open,
+		Low:      open,
+		Close:    day[len(day)-1].Price,
+		CloseAt:  day[len(day)-1].Time.In(date.Location()),
+		Ticks:    len(day),
+		Final:    !time.Now().Before(end),
+	}
+	for _, t := range day {
+		if t.Price > c.High {
+			c.High = t.Price
+		}
+		if t.Price < c.Low {
+			c.Low = t.Price
+		}
+	}
+	return c, nil
+}
+
+// Compact rolls ticks older than Retention up into hourly averages and
+// those older than HourlyRetention into daily ones, dropping daily
+// averages older than DailyRetention. It does nothing without
+// HourlyRetention, as old ticks are then dropped as they are recorded.
+func (s *Store) Compact(now time.Time) {
+	if s.opts.HourlyRetention <= 0 {
+		return
+	}
+	hourly := now.Add(-s.opts.Retention).Truncate(time.Hour)
+	daily := now.Add(-s.opts.HourlyRetention).Truncate(day)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, ticks := range s.series {
+		ticks = rollUp(ticks, daily, day)
+		ticks = rollUp(ticks, hourly, time.Hour)
+		if s.opts.DailyRetention > 0 {
+			cutoff := now.Add(-s.opts.DailyRetention)
+			if drop := sort.Search(len(ticks), func(i int) bool { return ticks[i].Time.After(cutoff) }); drop > 0 {
+				ticks = append(ticks[:0:0], ticks[drop:]...)
+			}
+		}
+		if len(ticks) == 0 {
+			delete(s.series, key)
+			continue
+		}
+		s.series[key] = ticks
+	}
+}
+
+// RunCompaction compacts the store every interval until ctx is done
+func (s *Store) RunCompaction(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.Compact(time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rollUp replaces the ticks before a width-aligned time with one tick per
+// width-long bucket, stamped at the bucket start, carrying the
+// time-weighted average price and volume over the bucket. A bucket
+// already rolled up holds a single tick at its start and is unchanged.
+func rollUp(ticks []Tick, before time.Time, width time.Duration) []Tick {
+	end := sort.Search(len(ticks), func(i int) bool { return !ticks[i].Time.Before(before) })
+	if end == 0 {
+		return ticks
+	}
+
+	out := make([]Tick, 0, len(ticks))
+	for i := 0; i < end; {
+		start := ticks[i].Time.Truncate(width)
+		stop := start.Add(width)
+		var price, volume, span float64
+
+		// The previous tick's price holds until the bucket's first
+		if i > 0 {
+			dt := ticks[i].Time.Sub(start).Seconds()
+			price += ticks[i-1].Price * dt
+			volume += ticks[i-1].Volume24h * dt
+			span += dt
+		}
+		j := i
+		for ; j < end && ticks[j].Time.Before(stop); j++ {
+			next := stop
+			if j+1 < len(ticks) && ticks[j+1].Time.Before(stop) {
+				next = ticks[j+1].Time
+			}
+			dt := next.Sub(ticks[j].Time).Seconds()
+			price += ticks[j].Price * dt
+			volume += ticks[j].Volume24h * dt
+			span += dt
+		}
+		out = append(out, Tick{Time: start, Price: price / span, Volume24h: volume / span})
+		i = j
+	}
+	return append(out, ticks[end:]...)
+}
+
+// Run refreshes the configured tokens every interval until ctx is done.
+// refresh is typically PriceCache.GetMultiplePrices with a TTL of
+// interval, whose refresh hook calls Record.
+func (s *Store) Run(ctx context.Context, interval time.Duration, refresh func(ctx context.Context, tokenIDs []string, currency string) error) {
+	if len(s.opts.Tokens) == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, currency := range s.opts.Currencies {
+			if err := refresh(ctx, s.opts.Tokens, currency); err != nil {
+				log.Printf("Refreshing tick prices in %s: %v", currency, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package ticks_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/ticks"
+)
+
+// record stores one bitcoin tick in usd
+func record(s *ticks.Store, at time.Time, price, volume float64) {
+	s.Record("usd", []*cache.PriceResponse{{ID: "bitcoin", Price: price, Volume24h: volume, UpdatedAt: at}})
+}
+
+// near reports whether got is within 0.001 of want. Average measures its
+// window from its own clock, a moment after the test's.
+func near(got, want float64) bool {
+	return math.Abs(got-want) < 1e-3
+}
+
+func TestAverage(t *testing.T) {
+	now := time.Now()
+	s := ticks.NewStore(ticks.Options{})
+	// Unevenly spaced; the first tick is before an hour's window and sets
+	// the price at its start
+	record(s, now.Add(-90*time.Minute), 100, 10)
+	record(s, now.Add(-40*time.Minute), 110, 20)
+	record(s, now.Add(-30*time.Minute), 120, 10)
+	record(s, now.Add(-5*time.Minute), 90, 40)
+
+	tests := []struct {
+		name       string
+		window     time.Duration
+		twap, vwap float64
+		ticks      int
+		from       time.Time
+	}{
+		{
+			// 100 for 20m, 110 for 10m, 120 for 25m, 90 for 5m:
+			// TWAP 6550/60; weights volume × minutes 200, 200, 250, 200:
+			// VWAP 90000/850
+			name:   "hour",
+			window: time.Hour,
+			twap:   6550.0 / 60,
+			vwap:   90000.0 / 850,
+			ticks:  4,
+			from:   now.Add(-time.Hour),
+		},
+		{
+			// Longer than the ticks: averaged from the first, 100 for 50m
+			name:   "beyond the first tick",
+			window: 3 * time.Hour,
+			twap:   9550.0 / 90,
+			vwap:   (100*500 + 110*200 + 120*250 + 90*200) / 1150.0,
+			ticks:  4,
+			from:   now.Add(-90 * time.Minute),
+		},
+		{
+			// 120 for 5m and 90 for 5m, with volume 10 and 40
+			name:   "last ticks",
+			window: 10 * time.Minute,
+			twap:   105,
+			vwap:   (120*50 + 90*200) / 250.0,
+			ticks:  2,
+			from:   now.Add(-10 * time.Minute),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			avg, err := s.Average("Bitcoin", "USD", tt.window)
+			if err != nil {
+				t.Fatalf("Average: %v", err)
+			}
+			if !near(avg.TWAP, tt.twap) {
+				t.Errorf("TWAP = %v, want %v", avg.TWAP, tt.twap)
+			}
+			if avg.VWAP == nil || !near(*avg.VWAP, tt.vwap) {
+				t.Errorf("VWAP = %v, want %v", avg.VWAP, tt.vwap)
+			}
+			if avg.Ticks != tt.ticks || avg.Spot != 90 || avg.Token != "bitcoin" || avg.Currency != "usd" {
+				t.Errorf("average = %+v, want %d ticks of bitcoin in usd, spot 90", avg, tt.ticks)
+			}
+			if d := avg.From.Sub(tt.from); d < 0 || d > time.Second {
+				t.Errorf("from = %v, want %v", avg.From, tt.from.UTC())
+			}
+		})
+	}
+}
+
+func TestAverageEmptyWindow(t *testing.T) {
+	now := time.Now()
+	s := ticks.NewStore(ticks.Options{})
+	record(s, now.Add(-5*time.Minute), 100, 10)
+
+	// No tick within the last minute, though the one before still holds
+	if avg, err := s.Average("bitcoin", "usd", time.Minute); !errors.Is(err, ticks.ErrNoTicks) {
+		t.Errorf("Average of an empty window = %+v, %v, want ErrNoTicks", avg, err)
+	}
+	if _, err := s.Average("ethereum", "usd", time.Hour); !errors.Is(err, ticks.ErrNoTicks) {
+		t.Errorf("Average of an unknown token: %v, want ErrNoTicks", err)
+	}
+	if _, err := s.Average("bitcoin", "eur", time.Hour); !errors.Is(err, ticks.ErrNoTicks) {
+		t.Errorf("Average in another currency: %v, want ErrNoTicks", err)
+	}
+}
+
+func TestAverageWithoutVolume(t *testing.T) {
+	now := time.Now()
+	s := ticks.NewStore(ticks.Options{})
+	record(s, now.Add(-30*time.Minute), 100, 0)
+	record(s, now.Add(-15*time.Minute), 200, 0)
+
+	avg, err := s.Average("bitcoin", "usd", time.Hour)
+	if err != nil {
+		t.Fatalf("Average: %v", err)
+	}
+	if !near(avg.TWAP, 150) || avg.VWAP != nil {
+		t.Errorf("TWAP, VWAP = %v, %v, want 150 and no VWAP", avg.TWAP, avg.VWAP)
+	}
+}
+
+func TestRecordIgnoresStaleTicks(t *testing.T) {
+	now := time.Now()
+	s := ticks.NewStore(ticks.Options{})
+	record(s, now.Add(-10*time.Minute), 100, 10)
+	record(s, now.Add(-20*time.Minute), 500, 10) // older than the last: dropped
+	record(s, now.Add(-10*time.Minute), 500, 10) // the same price again: dropped
+
+	avg, err := s.Average("bitcoin", "usd", time.Hour)
+	if err != nil {
+		t.Fatalf("Average: %v", err)
+	}
+	if avg.Ticks != 1 || !near(avg.TWAP, 100) {
+		t.Errorf("average = %+v, want the one tick at 100", avg)
+	}
+}
+
+func TestClose(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	at := func(day, hour, min int) time.Time { return time.Date(2025, 3, day, hour, min, 0, 0, ny) }
+	s := ticks.NewStore(ticks.Options{Retention: 72 * time.Hour})
+	record(s, at(8, 23, 0), 10, 1)  // the day before: the open
+	record(s, at(9, 1, 0), 12, 1)   // before the clocks go forward
+	record(s, at(9, 12, 0), 8, 1)   // after
+	record(s, at(9, 23, 30), 11, 1) // the close
+	record(s, at(10, 0, 30), 99, 1) // the day after
+
+	c, err := s.Close("bitcoin", "usd", at(9, 0, 0))
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if c.Open != 10 || c.High != 12 || c.Low != 8 || c.Close != 11 || c.Ticks != 3 || !c.Final {
+		t.Errorf("close = %+v, want open 10, high 12, low 8, close 11 over 3 ticks, final", c)
+	}
+	if c.To.Sub(c.From) != 23*time.Hour || c.Date != "2025-03-09" || c.TimeZone != "America/New_York" {
+		t.Errorf("day %s in %s from %v to %v, want 23 hours of 2025-03-09 in New York", c.Date, c.TimeZone, c.From, c.To)
+	}
+
+	if _, err := s.Close("bitcoin", "usd", at(11, 0, 0)); !errors.Is(err, ticks.ErrNoTicks) {
+		t.Errorf("Close of a day without ticks: %v, want ErrNoTicks", err)
+	}
+}
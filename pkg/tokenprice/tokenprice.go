@@ -0,0 +1,330 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package tokenprice prices tokens by chain and contract address, so
+// dApps holding only an address can price arbitrary tokens: from
+// CoinGecko where it lists the contract, and from Uniswap V2 style DEX
+// pools where it doesn't.
+package tokenprice
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/evm"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+const (
+	// DefaultTTL is how long a contract's price is reused
+	DefaultTTL = time.Minute
+
+	// DefaultMinLiquidity is the least USD value a DEX pool must hold for
+	// its price to be served
+	DefaultMinLiquidity = 10000
+)
+
+// Price sources
+const (
+	SourceCoinGecko = "coingecko"
+	SourceDEX       = "dex"
+)
+
+// ErrUnknownChain is returned for chains with neither a CoinGecko
+// platform nor a DEX
+var ErrUnknownChain = errors.New("unknown chain")
+
+// Price is a token contract's price
+type Price = wire.ContractPrice
+
+// Fetcher fetches a contract's price from CoinGecko, e.g.
+// CoinGecko.FetchTokenPrice
+type Fetcher func(ctx context.Context, platform, contract, currency string) (*providers.TokenPrice, error)
+
+// Pricer prices a token by id, used for the quote tokens of DEX pools
+type Pricer func(ctx context.Context, tokenID, currency string) (float64, error)
+
+// Quote is a token DEX pairs are looked up against
+type Quote struct {
+	Address  string
+	TokenID  string // id the quote token is priced by, e.g. usd-coin
+	Decimals int
+}
+
+// DEX is a Uniswap V2 style factory on a chain
+type DEX struct {
+	RPC     *evm.Client
+	Factory string
+	Quotes  []Quote
+}
+
+// Options configures a Service
+type Options struct {
+	// Platforms maps chain names to CoinGecko asset platforms
+	Platforms map[string]string
+
+	// DEXes maps chain names to the DEX tokens are priced on when
+	// CoinGecko doesn't list them
+	DEXes map[string]DEX
+
+	// MinLiquidity is the least USD value a pool must hold;
+	// DefaultMinLiquidity if 0
+	MinLiquidity float64
+
+	// TTL is how long prices are reused; DefaultTTL if 0
+	TTL time.Duration
+}
+
+// Service caches contract prices
+type Service struct {
+	opts  Options
+	fetch Fetcher
+	price Pricer
+
+	mu    sync.Mutex
+	cache map[string]Price
+}
+
+// NewService creates a service fetching from CoinGecko with fetch and
+// pricing DEX quote tokens with price
+func NewService(opts Options, fetch Fetcher, price Pricer) *Service {
+	if opts.MinLiquidity <= 0 {
+		opts.MinLiquidity = DefaultMinLiquidity
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+	return &Service{opts: opts, fetch: fetch, price: price, cache: make(map[string]Price)}
+}
+
+// Chains returns the chains contracts can be priced on, sorted
+func (s *Service) Chains() []string {
+	seen := make(map[string]bool)
+	for chain := range s.opts.Platforms {
+		seen[chain] = true
+	}
+	for chain := range s.opts.DEXes {
+		seen[chain] = true
+	}
+	chains := make([]string, 0, len(seen))
+	for chain := range seen {
+		chains = append(chains, chain)
+	}
+	sort.Strings(chains)
+	return chains
+}
+
+// Price returns a contract's price in currency, from cache if it is
+// fresh. CoinGecko is asked first; the chain's DEX if CoinGecko fails or
+// doesn't list the contract. Stale data is returned if both fail.
+func (s *Service) Price(ctx context.Context, chain, contract, currency string) (*Price, error) {
+	chain, currency = strings.ToLower(chain), strings.ToLower(currency)
+	platform, listed := s.opts.Platforms[chain]
+	dex, onChain := s.opts.DEXes[chain]
+	if !listed && !onChain {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownChain, chain)
+	}
+	// EVM addresses are case-insensitive; others, such as Solana's, aren't
+	if strings.HasPrefix(contract, "0x") {
+		contract = strings.ToLower(contract)
+	}
+	key := chain + "/" + contract + "/" + currency
+
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && time.Since(cached.UpdatedAt) < s.opts.TTL {
+		cached.Cached = true
+		return &cached, nil
+	}
+
+	var p *Price
+	var err error = &providers.NotFoundError{Token: chain + ":" + contract}
+	if listed {
+		p, err = s.fromCoinGecko(ctx, platform, contract, currency)
+	}
+	if err != nil && onChain {
+		onDEX, dexErr := s.fromDEX(ctx, dex, contract, currency)
+		switch {
+		case dexErr == nil:
+			p, err = onDEX, nil
+		case errors.Is(err, providers.ErrTokenNotFound):
+			// The DEX's reason is the better one when CoinGecko has none
+			err = dexErr
+		}
+	}
+	if err != nil {
+		if ok {
+			cached.Cached = true
+			return &cached, nil
+		}
+		return nil, err
+	}
+
+	p.Chain, p.Contract, p.Currency = chain, contract, currency
+	s.mu.Lock()
+	s.cache[key] = *p
+	s.mu.Unlock()
+	return p, nil
+}
+
+// fromCoinGecko prices a contract listed on a CoinGecko platform
+func (s *Service) fromCoinGecko(ctx context.Context, platform, contract, currency string) (*Price, error) {
+	tp, err := s.fetch(ctx, platform, contract, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &Price{
+		Price:     tp.Price,
+		MarketCap: tp.MarketCap,
+		Volume24h: tp.Volume24h,
+		Change24h: tp.Change24h,
+		Source:    SourceCoinGecko,
+		UpdatedAt: time.Now().UTC(),
+	}, nil
+}
+
+// ABI function selectors of the Uniswap V2 factory, pair and ERC-20 calls
+var (
+	getPairSelector     = evm.Selector("getPair(address,address)")
+	token0Selector      = evm.Selector("token0()")
+	getReservesSelector = evm.Selector("getReserves()")
+	decimalsSelector    = evm.Selector("decimals()")
+)
+
+// pool is a pair of the priced token and a quote token
+type pool struct {
+	address   string
+	quote     Quote
+	base      *big.Float // reserve of the priced token, in whole tokens
+	reserve   *big.Float // reserve of the quote token, in whole tokens
+	liquidity float64    // USD value of both reserves
+}
+
+// fromDEX prices a contract from its deepest pair against the DEX's
+// quote tokens, if it holds at least MinLiquidity
+func (s *Service) fromDEX(ctx context.Context, dex DEX, contract, currency string) (*Price, error) {
+	if !isAddress(contract) {
+		return nil, fmt.Errorf("%s is not an EVM address", contract)
+	}
+	out, err := dex.RPC.CallContract(ctx, contract, decimalsSelector)
+	if err != nil {
+		return nil, fmt.Errorf("decimals of %s: %w", contract, err)
+	}
+	if len(out) < 32 {
+		// Accounts without code return nothing
+		return nil, fmt.Errorf("%w: %s is not a token contract", providers.ErrTokenNotFound, contract)
+	}
+	decimals := new(big.Int).SetBytes(out[:32])
+	if decimals.Cmp(big.NewInt(77)) > 0 {
+		return nil, fmt.Errorf("%s has %s decimals", contract, decimals)
+	}
+
+	var best *pool
+	var errs []error
+	for _, q := range dex.Quotes {
+		if strings.EqualFold(q.Address, contract) {
+			continue
+		}
+		p, err := s.pool(ctx, dex, contract, int(decimals.Int64()), q)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if p != nil && (best == nil || p.liquidity > best.liquidity) {
+			best = p
+		}
+	}
+	if best == nil {
+		if len(errs) > 0 {
+			return nil, errors.Join(errs...)
+		}
+		return nil, fmt.Errorf("%w: no DEX pool of %s", providers.ErrTokenNotFound, contract)
+	}
+	if best.liquidity < s.opts.MinLiquidity {
+		return nil, fmt.Errorf("%w: DEX pool %s holds only $%.0f", providers.ErrTokenNotFound, best.address, best.liquidity)
+	}
+
+	quotePrice, err := s.price(ctx, best.quote.TokenID, currency)
+	if err != nil {
+		return nil, fmt.Errorf("pricing %s: %w", best.quote.TokenID, err)
+	}
+	ratio, _ := new(big.Float).Quo(best.reserve, best.base).Float64()
+	return &Price{
+		Price:     ratio * quotePrice,
+		Source:    SourceDEX,
+		Pool:      best.address,
+		Liquidity: best.liquidity,
+		UpdatedAt: time.Now().UTC(),
+	}, nil
+}
+
+// pool reads the pair of contract and a quote token, or nil if the
+// factory has none or it is empty
+func (s *Service) pool(ctx context.Context, dex DEX, contract string, decimals int, q Quote) (*pool, error) {
+	data := append([]byte(nil), getPairSelector...)
+	data = append(data, evm.AddressWord(contract)...)
+	data = append(data, evm.AddressWord(q.Address)...)
+	out, err := dex.RPC.CallContract(ctx, dex.Factory, data)
+	if err != nil {
+		return nil, fmt.Errorf("getPair of %s: %w", q.TokenID, err)
+	}
+	if len(out) < 32 {
+		return nil, fmt.Errorf("getPair of %s: malformed result", q.TokenID)
+	}
+	if new(big.Int).SetBytes(out[:32]).Sign() == 0 {
+		return nil, nil
+	}
+	pair := "0x" + hex.EncodeToString(out[12:32])
+
+	token0, err := dex.RPC.CallContract(ctx, pair, token0Selector)
+	if err != nil {
+		return nil, fmt.Errorf("token0 of %s: %w", pair, err)
+	}
+	reserves, err := dex.RPC.CallContract(ctx, pair, getReservesSelector)
+	if err != nil {
+		return nil, fmt.Errorf("reserves of %s: %w", pair, err)
+	}
+	if len(token0) < 32 || len(reserves) < 64 {
+		return nil, fmt.Errorf("pair %s: malformed result", pair)
+	}
+	r0, r1 := new(big.Int).SetBytes(reserves[:32]), new(big.Int).SetBytes(reserves[32:64])
+	if !strings.EqualFold("0x"+hex.EncodeToString(token0[12:32]), contract) {
+		r0, r1 = r1, r0
+	}
+	if r0.Sign() == 0 || r1.Sign() == 0 {
+		return nil, nil
+	}
+
+	quoteUSD, err := s.price(ctx, q.TokenID, "usd")
+	if err != nil {
+		return nil, fmt.Errorf("pricing %s: %w", q.TokenID, err)
+	}
+	p := &pool{address: pair, quote: q, base: scale(r0, decimals), reserve: scale(r1, q.Decimals)}
+	value, _ := p.reserve.Float64()
+	p.liquidity = 2 * value * quoteUSD
+	return p, nil
+}
+
+// scale divides an integer amount by 10^decimals
+func scale(n *big.Int, decimals int) *big.Float {
+	div := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	return new(big.Float).Quo(new(big.Float).SetInt(n), div)
+}
+
+// isAddress reports whether s is a 0x-prefixed 20-byte hex address
+func isAddress(s string) bool {
+	if len(s) != 42 || !strings.HasPrefix(s, "0x") {
+		return false
+	}
+	_, err := hex.DecodeString(s[2:])
+	return err == nil
+}
@@ -0,0 +1,522 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package treasury values the foundation's holdings on a schedule, keeping
+// every valuation with how it changed since the one before.
+package treasury
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/decimal"
+	"github.com/luxfi/pricing/pkg/snapshot"
+)
+
+// Valuation periods
+const (
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+)
+
+const (
+	// MaxValuations bounds the valuations kept; the oldest are dropped
+	MaxValuations = 1000
+
+	// keyLayout names exported valuations so they sort by time
+	keyLayout = "20060102T150405Z"
+)
+
+// ErrNoValuation is returned when no valuation has been recorded yet
+var ErrNoValuation = errors.New("no treasury valuation recorded yet")
+
+// PriceFunc prices tokens in a currency, typically
+// PriceCache.GetMultiplePrices
+type PriceFunc func(ctx context.Context, tokenIDs []string, currency string) (*cache.MultiPriceResponse, error)
+
+// Options configures a Service
+type Options struct {
+	Holdings map[string]float64 // token id -> amount held
+	Currency string             // usd if empty
+	Period   string             // Daily, Weekly or Monthly (default)
+	Hour     int                // UTC hour scheduled valuations are recorded at
+
+	// File persists recorded valuations; memory only if empty
+	File string
+
+	// Store and Prefix receive every recorded valuation as JSON and CSV,
+	// if Store is set
+	Store  snapshot.Store
+	Prefix string
+
+	// Deliver receives every scheduled valuation
+	Deliver func(v *Valuation)
+}
+
+// Change is how a figure moved since the previous valuation. Value is
+// split into what price moves and what changes in the amount held
+// contributed: PriceEffect is the previous amount times the price change,
+// AmountEffect the amount change at the current price.
+type Change struct {
+	Amount       float64  `json:"amount,omitempty"` // per position only
+	Value        float64  `json:"value"`
+	Percent      *float64 `json:"percent,omitempty"` // of the previous value, unless it was zero
+	PriceEffect  float64  `json:"price_effect"`
+	AmountEffect float64  `json:"amount_effect"`
+}
+
+// Position is the value of one token held
+type Position struct {
+	Token  string  `json:"token"`
+	Symbol string  `json:"symbol,omitempty"`
+	Name   string  `json:"name,omitempty"`
+	Amount float64 `json:"amount"`
+	Price  float64 `json:"price"`
+	Value  float64 `json:"value"`
+	Weight float64 `json:"weight"` // fraction of the total
+
+	PricedAt time.Time `json:"priced_at"`
+	Change   *Change   `json:"change,omitempty"` // since the previous valuation
+}
+
+// Valuation is the value of the holdings at a point in time
+type Valuation struct {
+	Period    string     `json:"period"`
+	Currency  string     `json:"currency"`
+	At        time.Time  `json:"at"`
+	Total     float64    `json:"total"`
+	Positions []Position `json:"positions"` // largest value first
+
+	// Missing lists tokens held without a price, left out of the total
+	Missing []string `json:"missing,omitempty"`
+
+	// Previous is when the valuation Change is measured against was
+	// recorded; both are absent for the first
+	Previous *time.Time `json:"previous,omitempty"`
+	Change   *Change    `json:"change,omitempty"`
+}
+
+// Summary is a recorded valuation without its positions
+type Summary struct {
+	At       time.Time `json:"at"`
+	Currency string    `json:"currency"`
+	Total    float64   `json:"total"`
+	Change   *Change   `json:"change,omitempty"`
+}
+
+// Service values the holdings and keeps the recorded valuations
+type Service struct {
+	opts   Options
+	prices PriceFunc
+
+	mu         sync.Mutex
+	valuations []*Valuation // oldest first
+}
+
+// NewService creates a treasury valuing opts.Holdings with prices, loading
+// the valuations recorded in opts.File if it exists
+func NewService(opts Options, prices PriceFunc) (*Service, error) {
+	if opts.Currency == "" {
+		opts.Currency = "usd"
+	}
+	opts.Currency = strings.ToLower(opts.Currency)
+	if opts.Period == "" {
+		opts.Period = Monthly
+	}
+	if opts.Prefix != "" && !strings.HasSuffix(opts.Prefix, "/") {
+		opts.Prefix += "/"
+	}
+	s := &Service{opts: opts, prices: prices}
+	if opts.File == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(opts.File)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.valuations); err != nil {
+		return nil, fmt.Errorf("treasury file %s: %w", opts.File, err)
+	}
+	sort.Slice(s.valuations, func(i, j int) bool { return s.valuations[i].At.Before(s.valuations[j].At) })
+	return s, nil
+}
+
+// Period returns the valuation period
+func (s *Service) Period() string {
+	return s.opts.Period
+}
+
+// Latest returns the most recently recorded valuation
+func (s *Service) Latest() (*Valuation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.valuations) == 0 {
+		return nil, ErrNoValuation
+	}
+	return s.valuations[len(s.valuations)-1], nil
+}
+
+// History summarizes the recorded valuations, newest first, at most limit
+// of them if limit is positive
+func (s *Service) History(limit int) []Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Summary, 0, len(s.valuations))
+	for i := len(s.valuations) - 1; i >= 0 && (limit <= 0 || len(out) < limit); i-- {
+		v := s.valuations[i]
+		out = append(out, Summary{At: v.At, Currency: v.Currency, Total: v.Total, Change: v.Change})
+	}
+	return out
+}
+
+// Value values the holdings now against the latest recorded valuation,
+// without recording it
+func (s *Service) Value(ctx context.Context) (*Valuation, error) {
+	s.mu.Lock()
+	var prev *Valuation
+	if n := len(s.valuations); n > 0 {
+		prev = s.valuations[n-1]
+	}
+	s.mu.Unlock()
+	if prev != nil && prev.Currency != s.opts.Currency {
+		// A change of currency starts the comparison afresh
+		prev = nil
+	}
+
+	// Tokens no longer held are priced too, so their sale shows up in the
+	// change
+	amounts := make(map[string]float64, len(s.opts.Holdings))
+	for token, amount := range s.opts.Holdings {
+		amounts[token] = amount
+	}
+	before := make(map[string]Position)
+	if prev != nil {
+		for _, p := range prev.Positions {
+			before[p.Token] = p
+			if _, ok := amounts[p.Token]; !ok {
+				amounts[p.Token] = 0
+			}
+		}
+	}
+	tokens := make([]string, 0, len(amounts))
+	for token := range amounts {
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+
+	quoted, err := s.prices(ctx, tokens, s.opts.Currency)
+	if err != nil && (quoted == nil || len(quoted.Prices) == 0) {
+		return nil, fmt.Errorf("pricing holdings: %w", err)
+	}
+
+	v := &Valuation{Period: s.opts.Period, Currency: s.opts.Currency, At: time.Now().UTC(), Positions: []Position{}}
+	var total decimal.Decimal
+	for _, token := range tokens {
+		p, ok := quoted.Prices[token]
+		if !ok {
+			if amounts[token] != 0 {
+				v.Missing = append(v.Missing, token)
+			}
+			continue
+		}
+		price, err := decimal.Parse(p.PriceStr)
+		if err != nil {
+			price = decimal.FromFloat(p.Price)
+		}
+		value := decimal.FromFloat(amounts[token]).Mul(price)
+		pos := Position{
+			Token:    token,
+			Symbol:   p.Symbol,
+			Name:     p.Name,
+			Amount:   amounts[token],
+			Price:    price.Float64(),
+			Value:    value.Float64(),
+			PricedAt: p.UpdatedAt,
+		}
+		if prev != nil {
+			old := before[token]
+			pos.Change = change(old.Amount, old.Price, pos.Amount, price)
+		}
+		if pos.Amount == 0 && (pos.Change == nil || pos.Change.Value == 0) {
+			continue
+		}
+		total = total.Add(value)
+		v.Positions = append(v.Positions, pos)
+	}
+	v.Total = total.Float64()
+
+	// The total change is the sum of the positions', so it always equals
+	// its price and amount effects; tokens now missing a price are left
+	// out of it as they are of the total
+	var diff, priceEffect, amountEffect decimal.Decimal
+	for i := range v.Positions {
+		p := &v.Positions[i]
+		if !total.IsZero() {
+			p.Weight = decimal.FromFloat(p.Value).Quo(total).Round(6).Float64()
+		}
+		if p.Change != nil {
+			diff = diff.Add(decimal.FromFloat(p.Change.Value))
+			priceEffect = priceEffect.Add(decimal.FromFloat(p.Change.PriceEffect))
+			amountEffect = amountEffect.Add(decimal.FromFloat(p.Change.AmountEffect))
+		}
+	}
+	sort.SliceStable(v.Positions, func(i, j int) bool { return v.Positions[i].Value > v.Positions[j].Value })
+
+	if prev != nil {
+		at := prev.At
+		v.Previous = &at
+		v.Change = &Change{Value: diff.Float64(), PriceEffect: priceEffect.Float64(), AmountEffect: amountEffect.Float64()}
+		v.Change.Percent = percent(diff, decimal.FromFloat(prev.Total))
+	}
+	return v, nil
+}
+
+// change is how a position moved from amount0 at price0 to amount1 at
+// price1
+func change(amount0, price0, amount1 float64, price1 decimal.Decimal) *Change {
+	a0, a1, p0 := decimal.FromFloat(amount0), decimal.FromFloat(amount1), decimal.FromFloat(price0)
+	before := a0.Mul(p0)
+	diff := a1.Mul(price1).Sub(before)
+	return &Change{
+		Amount:       a1.Sub(a0).Float64(),
+		Value:        diff.Float64(),
+		Percent:      percent(diff, before),
+		PriceEffect:  a0.Mul(price1.Sub(p0)).Float64(),
+		AmountEffect: a1.Sub(a0).Mul(price1).Float64(),
+	}
+}
+
+// percent is diff as a percentage of base, to four decimals, or nil if
+// base is zero
+func percent(diff, base decimal.Decimal) *float64 {
+	if base.IsZero() {
+		return nil
+	}
+	p := diff.Quo(base).Mul(decimal.FromFloat(100)).Round(4).Float64()
+	return &p
+}
+
+// Record values the holdings, keeps the valuation and exports it
+func (s *Service) Record(ctx context.Context) (*Valuation, error) {
+	v, err := s.Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.valuations = append(s.valuations, v)
+	if len(s.valuations) > MaxValuations {
+		s.valuations = append([]*Valuation(nil), s.valuations[len(s.valuations)-MaxValuations:]...)
+	}
+	err = s.save()
+	s.mu.Unlock()
+	if err != nil {
+		log.Printf("Treasury: saving %s: %v", s.opts.File, err)
+	}
+
+	if s.opts.Store != nil {
+		if err := s.export(ctx, v); err != nil {
+			log.Printf("Treasury: export: %v", err)
+		}
+	}
+	return v, nil
+}
+
+// export writes a valuation to the store as JSON and CSV
+func (s *Service) export(ctx context.Context, v *Valuation) error {
+	name := s.opts.Prefix + "treasury-" + v.At.Format(keyLayout)
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := s.opts.Store.Put(ctx, name+".json", data); err != nil {
+		return fmt.Errorf("writing %s.json: %w", name, err)
+	}
+	if err := s.opts.Store.Put(ctx, name+".csv", v.CSV()); err != nil {
+		return fmt.Errorf("writing %s.csv: %w", name, err)
+	}
+	return nil
+}
+
+// save writes the valuations to the file, replacing it atomically. The
+// caller holds s.mu.
+func (s *Service) save() error {
+	if s.opts.File == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.valuations, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.opts.File), filepath.Base(s.opts.File)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.opts.File)
+}
+
+// Run records and delivers a valuation at every scheduled time until ctx
+// is done
+func (s *Service) Run(ctx context.Context) {
+	for {
+		now := time.Now()
+		timer := time.NewTimer(Next(s.opts.Period, s.opts.Hour, now).Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		v, err := s.Record(ctx)
+		if err != nil {
+			log.Printf("Treasury valuation failed: %v", err)
+			continue
+		}
+		log.Printf("Recorded %s treasury valuation: %s %s", v.Period, strconv.FormatFloat(v.Total, 'f', 2, 64), strings.ToUpper(v.Currency))
+		if s.opts.Deliver != nil {
+			s.opts.Deliver(v)
+		}
+	}
+}
+
+// Next returns the first scheduled valuation time after t, at hour:00
+// UTC: the next day for daily valuations, the next Monday for weekly and
+// the first of the next month for monthly
+func Next(period string, hour int, t time.Time) time.Time {
+	t = t.UTC()
+	next := time.Date(t.Year(), t.Month(), t.Day(), hour, 0, 0, 0, time.UTC)
+	switch period {
+	case Daily:
+		if !next.After(t) {
+			next = next.AddDate(0, 0, 1)
+		}
+	case Weekly:
+		next = next.AddDate(0, 0, (int(time.Monday)-int(next.Weekday())+7)%7)
+		if !next.After(t) {
+			next = next.AddDate(0, 0, 7)
+		}
+	default:
+		next = time.Date(t.Year(), t.Month(), 1, hour, 0, 0, 0, time.UTC)
+		if !next.After(t) {
+			next = next.AddDate(0, 1, 0)
+		}
+	}
+	return next
+}
+
+// CSVHeader names the columns of Rows
+var CSVHeader = []string{
+	"at", "currency", "token", "symbol", "amount", "price", "value", "weight",
+	"amount_change", "value_change", "value_change_percent", "price_effect", "amount_effect",
+}
+
+// Rows are the valuation's positions as CSV rows, then a total row
+func (v *Valuation) Rows() [][]string {
+	at := v.At.Format(time.RFC3339)
+	rows := make([][]string, 0, len(v.Positions)+1)
+	for _, p := range v.Positions {
+		row := []string{at, v.Currency, p.Token, p.Symbol, num(p.Amount), num(p.Price), num(p.Value), num(p.Weight)}
+		rows = append(rows, append(row, changeColumns(p.Change, true)...))
+	}
+	total := []string{at, v.Currency, "total", "", "", "", num(v.Total), "1"}
+	return append(rows, append(total, changeColumns(v.Change, false)...))
+}
+
+// changeColumns are the CSV columns of a change, empty if there is none
+func changeColumns(c *Change, amount bool) []string {
+	if c == nil {
+		return []string{"", "", "", "", ""}
+	}
+	cols := []string{"", num(c.Value), "", num(c.PriceEffect), num(c.AmountEffect)}
+	if amount {
+		cols[0] = num(c.Amount)
+	}
+	if c.Percent != nil {
+		cols[2] = num(*c.Percent)
+	}
+	return cols
+}
+
+// CSV writes the valuation as CSV with a header row
+func (v *Valuation) CSV() []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(CSVHeader)
+	w.WriteAll(v.Rows())
+	return buf.Bytes()
+}
+
+// num formats a number without exponent or trailing zeros
+func num(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// String summarizes a valuation for chat and email channels
+func (v *Valuation) String() string {
+	var b strings.Builder
+	currency := strings.ToUpper(v.Currency)
+	fmt.Fprintf(&b, "Treasury valuation %s: %s %s", v.At.Format("2006-01-02"), money(v.Total), currency)
+	if v.Change != nil {
+		fmt.Fprintf(&b, " (%s since %s", signed(v.Change.Value), v.Previous.Format("2006-01-02"))
+		if v.Change.Percent != nil {
+			fmt.Fprintf(&b, ", %+.2f%%", *v.Change.Percent)
+		}
+		fmt.Fprintf(&b, "; prices %s, holdings %s)", signed(v.Change.PriceEffect), signed(v.Change.AmountEffect))
+	}
+	for _, p := range v.Positions {
+		fmt.Fprintf(&b, "\n- %s: %s at %s = %s %s (%.2f%%)", strings.ToUpper(firstOf(p.Symbol, p.Token)), num(p.Amount), num(p.Price), money(p.Value), currency, p.Weight*100)
+	}
+	if len(v.Missing) > 0 {
+		fmt.Fprintf(&b, "\nUnpriced: %s", strings.Join(v.Missing, ", "))
+	}
+	return b.String()
+}
+
+// money formats an amount with two decimals
+func money(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+// signed formats an amount with two decimals and a sign
+func signed(f float64) string {
+	if f >= 0 {
+		return "+" + money(f)
+	}
+	return money(f)
+}
+
+func firstOf(a, b string) string {
+	if a != "" {
+		return a
+	}
+	return b
+}
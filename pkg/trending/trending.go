@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package trending serves trending tokens and top movers.
+package trending
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+// DefaultTTL is how long the trending list is reused
+const DefaultTTL = 10 * time.Minute
+
+// Coin is a trending token
+type Coin = wire.TrendingCoin
+
+// List is the trending tokens
+type List = wire.TrendingList
+
+// Fetcher fetches the trending list, e.g. CoinGecko.FetchTrending
+type Fetcher func(ctx context.Context) ([]providers.TrendingCoin, error)
+
+// Service caches the trending list fetched from an upstream
+type Service struct {
+	fetch Fetcher
+	ttl   time.Duration
+
+	mu   sync.Mutex
+	list *List
+}
+
+// NewService creates a service that refetches the trending list after ttl
+func NewService(fetch Fetcher, ttl time.Duration) *Service {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Service{fetch: fetch, ttl: ttl}
+}
+
+// Trending returns the trending tokens, from cache if they are fresh.
+// Stale data is returned if the refetch fails.
+func (s *Service) Trending(ctx context.Context) (*List, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.list != nil && time.Since(s.list.UpdatedAt) < s.ttl {
+		out := *s.list
+		out.Cached = true
+		return &out, nil
+	}
+
+	raw, err := s.fetch(ctx)
+	if err != nil {
+		if s.list != nil {
+			out := *s.list
+			out.Cached = true
+			return &out, nil
+		}
+		return nil, err
+	}
+
+	list := &List{Coins: make([]Coin, 0, len(raw)), UpdatedAt: time.Now().UTC()}
+	for _, c := range raw {
+		list.Coins = append(list.Coins, Coin{
+			ID:            c.ID,
+			Name:          c.Name,
+			Symbol:        c.Symbol,
+			MarketCapRank: c.MarketCapRank,
+			Rank:          c.Score + 1,
+			PriceUSD:      c.Data.Price,
+			Change24h:     c.Data.PriceChangePercentage24h["usd"],
+			Thumb:         c.Thumb,
+		})
+	}
+	s.list = list
+	out := *list
+	return &out, nil
+}
+
+// Movers are the largest gainers and losers over a window
+type Movers = wire.Movers
+
+// TopMovers ranks prices by 24h change and returns up to limit gainers,
+// largest rise first, and limit losers, largest fall first
+func TopMovers(prices []*cache.PriceResponse, currency string, limit int) *Movers {
+	sort.Slice(prices, func(i, j int) bool {
+		if prices[i].Change24h != prices[j].Change24h {
+			return prices[i].Change24h > prices[j].Change24h
+		}
+		return prices[i].ID < prices[j].ID
+	})
+
+	m := &Movers{
+		Window:    "24h",
+		Currency:  currency,
+		Gainers:   []*cache.PriceResponse{},
+		Losers:    []*cache.PriceResponse{},
+		Universe:  len(prices),
+		UpdatedAt: time.Now().UTC(),
+	}
+	for _, p := range prices {
+		if len(m.Gainers) == limit || p.Change24h <= 0 {
+			break
+		}
+		m.Gainers = append(m.Gainers, p)
+	}
+	for i := len(prices) - 1; i >= 0; i-- {
+		if len(m.Losers) == limit || prices[i].Change24h >= 0 {
+			break
+		}
+		m.Losers = append(m.Losers, prices[i])
+	}
+	return m
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package tvl serves DeFi protocol total value locked from DefiLlama.
+package tvl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/wire"
+)
+
+const (
+	// DefiLlamaURL is the DefiLlama API root
+	DefiLlamaURL = "https://api.llama.fi"
+
+	// DefaultTTL is how long the protocol list is reused
+	DefaultTTL = 10 * time.Minute
+)
+
+// ErrNotFound is returned for unknown protocols
+var ErrNotFound = errors.New("protocol not found")
+
+// Protocol is a DeFi protocol's TVL in USD
+type Protocol = wire.TVLProtocol
+
+// llamaProtocol is an entry of DefiLlama's /protocols
+type llamaProtocol struct {
+	Name      string             `json:"name"`
+	Slug      string             `json:"slug"`
+	Symbol    string             `json:"symbol"`
+	Category  string             `json:"category"`
+	GeckoID   *string            `json:"gecko_id"`
+	Chains    []string           `json:"chains"`
+	TVL       *float64           `json:"tvl"`
+	ChainTVLs map[string]float64 `json:"chainTvls"`
+	Change1d  *float64           `json:"change_1d"`
+	Change7d  *float64           `json:"change_7d"`
+}
+
+// Service fetches and caches the DefiLlama protocol list
+type Service struct {
+	// BaseURL is the API root, without a trailing slash
+	BaseURL string
+
+	client *http.Client
+	ttl    time.Duration
+
+	mu        sync.Mutex
+	bySlug    map[string]*Protocol
+	byToken   map[string]*Protocol
+	fetchedAt time.Time
+}
+
+// NewService creates a DefiLlama client that refetches after ttl. Requests
+// use client, or a default client if nil.
+func NewService(client *http.Client, ttl time.Duration) *Service {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Service{BaseURL: DefiLlamaURL, client: client, ttl: ttl}
+}
+
+// Protocol returns a protocol by DefiLlama slug (aave) or by the CoinGecko
+// id of its token (aave, lido-dao)
+func (s *Service) Protocol(ctx context.Context, id string) (*Protocol, error) {
+	id = strings.ToLower(id)
+	fetched, err := s.refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.bySlug[id]
+	if !ok {
+		p, ok = s.byToken[id]
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	out := *p
+	out.Cached = !fetched
+	return &out, nil
+}
+
+// TokenTVLs returns the TVL of the protocol behind each token that has
+// one, keyed by CoinGecko id
+func (s *Service) TokenTVLs(ctx context.Context, tokenIDs []string) (map[string]float64, error) {
+	if _, err := s.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tvls := make(map[string]float64)
+	for _, id := range tokenIDs {
+		if p, ok := s.byToken[strings.ToLower(id)]; ok {
+			tvls[id] = p.TVL
+		}
+	}
+	return tvls, nil
+}
+
+// refresh refetches the protocol list if it has expired and reports
+// whether it did. Stale data is kept if the refetch fails.
+func (s *Service) refresh(ctx context.Context) (bool, error) {
+	s.mu.Lock()
+	fresh := s.bySlug != nil && time.Since(s.fetchedAt) < s.ttl
+	have := s.bySlug != nil
+	s.mu.Unlock()
+	if fresh {
+		return false, nil
+	}
+
+	list, err := s.fetch(ctx)
+	if err != nil {
+		if have {
+			return false, nil
+		}
+		return false, err
+	}
+
+	now := time.Now().UTC()
+	bySlug := make(map[string]*Protocol, len(list))
+	byToken := make(map[string]*Protocol)
+	for _, lp := range list {
+		p := &Protocol{
+			Slug:      strings.ToLower(lp.Slug),
+			Name:      lp.Name,
+			Symbol:    strings.ToLower(lp.Symbol),
+			Category:  lp.Category,
+			Chains:    lp.Chains,
+			ChainTVLs: lp.ChainTVLs,
+			UpdatedAt: now,
+		}
+		if lp.TVL != nil {
+			p.TVL = *lp.TVL
+		}
+		if lp.Change1d != nil {
+			p.Change1d = *lp.Change1d
+		}
+		if lp.Change7d != nil {
+			p.Change7d = *lp.Change7d
+		}
+		bySlug[p.Slug] = p
+		// Several protocols can share a token (v2 and v3 of a DEX); the
+		// largest represents it
+		if lp.GeckoID != nil && *lp.GeckoID != "" {
+			p.TokenID = *lp.GeckoID
+			if other, dup := byToken[p.TokenID]; !dup || other.TVL < p.TVL {
+				byToken[p.TokenID] = p
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.bySlug, s.byToken, s.fetchedAt = bySlug, byToken, now
+	s.mu.Unlock()
+	return true, nil
+}
+
+// fetch downloads DefiLlama's protocol list
+func (s *Service) fetch(ctx context.Context) ([]llamaProtocol, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.BaseURL+"/protocols", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return nil, fmt.Errorf("DefiLlama API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var list []llamaProtocol
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package wire
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AlertChannel is where an alert is delivered: a Webhook, Slack or
+// Discord URL, a Telegram chat or an Email address
+type AlertChannel struct {
+	Type   string `json:"type"`
+	URL    string `json:"url,omitempty"`
+	ChatID string `json:"chat_id,omitempty"`
+	Email  string `json:"email,omitempty"`
+
+	// Attempts and Backoff retry failed deliveries: up to Attempts tries
+	// in all, waiting Backoff, e.g. "30s", before the first retry and
+	// twice as long before each next one. The notifier's defaults apply
+	// if unset. Email is not retried.
+	Attempts int    `json:"attempts,omitempty"`
+	Backoff  string `json:"backoff,omitempty"`
+}
+
+// AlertSpec is the client-supplied part of an alert
+type AlertSpec struct {
+	Token     string       `json:"token"`
+	Currency  string       `json:"currency"` // usd if empty
+	Direction string       `json:"direction"`
+	Threshold float64      `json:"threshold"`
+	Channel   AlertChannel `json:"channel"`
+
+	// Cooldown is the least time between firings, e.g. "30m"; the
+	// store's default if empty and none if "0s"
+	Cooldown string `json:"cooldown,omitempty"`
+}
+
+// Alert is a registered price alert. It fires once when the price crosses
+// the threshold and re-arms when the price moves back. A crossing during
+// the alert's cooldown or a mute window is held back and fires once
+// they end, if the price is still past the threshold.
+type Alert struct {
+	ID     string `json:"id"`
+	Tenant string `json:"tenant"`
+	AlertSpec
+	Triggered       bool       `json:"triggered"`
+	LastPrice       float64    `json:"last_price,omitempty"`
+	LastTriggeredAt *time.Time `json:"last_triggered_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// AlertFiring is a point at which a backtested alert would have fired
+type AlertFiring struct {
+	Time  time.Time `json:"time"`
+	Price float64   `json:"price"`
+}
+
+// AlertBacktest is how an alert condition would have behaved over a
+// token's price history
+type AlertBacktest struct {
+	Token     string        `json:"token"`
+	Currency  string        `json:"currency"`
+	Direction string        `json:"direction"`
+	Threshold float64       `json:"threshold"`
+	Cooldown  string        `json:"cooldown"`
+	Days      int           `json:"days"`
+	From      time.Time     `json:"from"`
+	To        time.Time     `json:"to"`
+	Points    int           `json:"points"` // prices checked
+	Firings   []AlertFiring `json:"firings"`
+	Triggered bool          `json:"triggered"` // whether the alert would be fired now
+}
+
+// DeliveryAttempt is one try of a delivery
+type DeliveryAttempt struct {
+	Time   time.Time `json:"time"`
+	Status int       `json:"status,omitempty"` // HTTP status, if the channel answered
+	Error  string    `json:"error,omitempty"`
+}
+
+// Delivery is a notification POSTed to a webhook, Slack, Discord or
+// Telegram channel, and how its attempts went
+type Delivery struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`            // e.g. "Alert 1f2e"
+	Alert     string            `json:"alert,omitempty"` // id of the alert that fired, if any
+	Channel   AlertChannel      `json:"channel"`
+	Status    string            `json:"status"`
+	Attempts  []DeliveryAttempt `json:"attempts"`
+	Payload   json.RawMessage   `json:"payload"` // body POSTed to the channel
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// AlertTestRequest is an alert condition to backtest over the last Days.
+// The channel, if given, is ignored.
+type AlertTestRequest struct {
+	AlertSpec
+	Days int `json:"days"` // 30 if zero
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package wire
+
+import "time"
+
+// Correlation is the pairwise correlation of tokens' daily returns
+type Correlation struct {
+	Currency string   `json:"currency"`
+	Days     int      `json:"days"`
+	IDs      []string `json:"ids"`
+
+	// Matrix[i][j] correlates IDs[i] with IDs[j]; null where a token's
+	// price did not move
+	Matrix       [][]*float64 `json:"matrix"`
+	Observations int          `json:"observations"` // daily returns common to every token
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// TrackingPoint is a token and its benchmark at a daily close, both
+// rebased to 100 at the start of the period
+type TrackingPoint struct {
+	Time      time.Time `json:"time"`
+	Token     float64   `json:"token"`
+	Benchmark float64   `json:"benchmark"`
+	Relative  float64   `json:"relative"` // 100 * Token / Benchmark
+}
+
+// RelativePerformance is a token's performance against a benchmark
+type RelativePerformance struct {
+	Token     string `json:"token"`
+	Benchmark string `json:"benchmark"`
+	Currency  string `json:"currency"`
+	Days      int    `json:"days"`
+
+	Return          float64 `json:"return"`           // token's percent return over the period
+	BenchmarkReturn float64 `json:"benchmark_return"` // benchmark's percent return
+	Excess          float64 `json:"excess"`           // Return - BenchmarkReturn
+
+	// Beta, alpha, correlation and tracking error come from daily
+	// returns; null if the benchmark never moved. Alpha is Jensen's
+	// alpha over the risk-free rate, annualized, as a fraction.
+	Beta          *float64 `json:"beta"`
+	Alpha         *float64 `json:"alpha"`
+	Correlation   *float64 `json:"correlation"`
+	TrackingError float64  `json:"tracking_error"` // annualized standard deviation of excess daily returns
+
+	Observations int             `json:"observations"` // daily returns
+	Series       []TrackingPoint `json:"series"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// RiskMetrics are risk metrics of a token's daily closes
+type RiskMetrics struct {
+	Token        string  `json:"token"`
+	Currency     string  `json:"currency"`
+	RiskFreeRate float64 `json:"risk_free_rate"` // annual, used by sharpe
+
+	// Metrics maps each requested metric to its value; null if the token
+	// has too little history or never moved in the window
+	Metrics   map[string]*float64 `json:"metrics"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+// Indicators are technical indicators of a token's daily closes
+type Indicators struct {
+	Token    string  `json:"token"`
+	Currency string  `json:"currency"`
+	Price    float64 `json:"price"` // latest close the indicators end at
+
+	// Indicators maps each requested indicator to its value; null if the
+	// token has too little history
+	Indicators map[string]*float64 `json:"indicators"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+}
+
+// Returns are a token's price changes over periods ending now
+type Returns struct {
+	Token    string  `json:"token"`
+	Currency string  `json:"currency"`
+	Price    float64 `json:"price"` // latest price the returns end at
+
+	// Returns maps each requested period to the percent change over it;
+	// null if history does not reach back that far
+	Returns   map[string]*float64 `json:"returns"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package wire
+
+import "time"
+
+// HistoryPoint is a token's price, market cap and volume at a point in time
+type HistoryPoint struct {
+	Time      time.Time `json:"time"`
+	Price     float64   `json:"price"`
+	MarketCap float64   `json:"market_cap"`
+	Volume    float64   `json:"volume"`
+}
+
+// HistorySeries is a token's history over the last Days, oldest point first
+type HistorySeries struct {
+	ID        string         `json:"id"`
+	Currency  string         `json:"currency"`
+	Days      int            `json:"days"`
+	Points    []HistoryPoint `json:"points"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	Cached    bool           `json:"cached"`
+}
+
+// PriceAverage is a token's time- and volume-weighted average price over a
+// window ending now
+type PriceAverage struct {
+	Token    string    `json:"token"`
+	Currency string    `json:"currency"`
+	Window   string    `json:"window"`
+	From     time.Time `json:"from"` // first tick, or the window start if a tick precedes it
+	To       time.Time `json:"to"`
+	TWAP     float64   `json:"twap"`
+	VWAP     *float64  `json:"vwap"` // null if no volume was reported
+	Spot     float64   `json:"spot"` // latest tick
+	Ticks    int       `json:"ticks"`
+}
+
+// DailyClose is a token's prices over one calendar day in a time zone
+type DailyClose struct {
+	Token    string    `json:"token"`
+	Currency string    `json:"currency"`
+	Date     string    `json:"date"` // YYYY-MM-DD in TimeZone
+	TimeZone string    `json:"tz"`
+	From     time.Time `json:"from"` // start of the day
+	To       time.Time `json:"to"`   // end of the day, exclusive
+	Open     float64   `json:"open"` // price at the start of the day, or its first tick
+	High     float64   `json:"high"`
+	Low      float64   `json:"low"`
+	Close    float64   `json:"close"`      // last price recorded in the day
+	CloseAt  time.Time `json:"close_time"` // when the close was recorded
+	Ticks    int       `json:"ticks"`      // ticks recorded in the day
+
+	// Final is false while the day is still running; Close is then the
+	// latest price so far
+	Final bool `json:"final"`
+}
+
+// PriceChanges are the prices of a currency changed since a cursor, oldest
+// change first
+type PriceChanges struct {
+	Currency string `json:"currency"`
+
+	// Cursor is passed as since on the next poll
+	Cursor string `json:"cursor"`
+
+	// Reset is true if since was empty, issued before a restart or too
+	// old to be answered from the journal: Prices then hold every price
+	// journaled, and clients should replace rather than patch their copy
+	Reset bool `json:"reset"`
+
+	// More is true if more changes than the limit were pending; poll again
+	// with Cursor right away for the rest
+	More bool `json:"more"`
+
+	Prices []PriceResponse `json:"prices"`
+}
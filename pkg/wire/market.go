@@ -0,0 +1,394 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package wire
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// SupplyFigure compares one supply figure between the provider and the chain
+type SupplyFigure struct {
+	Provider  float64 `json:"provider"`
+	OnChain   float64 `json:"on_chain"`
+	Deviation float64 `json:"deviation"` // provider / on_chain - 1
+	Flagged   bool    `json:"flagged"`   // deviation beyond the threshold
+}
+
+// SupplyCheck is a token's supply as reported and as read on chain
+type SupplyCheck struct {
+	Token       string       `json:"token"`
+	Chain       string       `json:"chain"`
+	Contract    string       `json:"contract"`
+	Total       SupplyFigure `json:"total_supply"`
+	Circulating SupplyFigure `json:"circulating_supply"` // on chain: total less locked balances
+	Locked      float64      `json:"locked"`
+	Discrepancy bool         `json:"discrepancy"` // either figure flagged
+	CheckedAt   time.Time    `json:"checked_at"`
+}
+
+// SupplyChange is a token's circulating supply on a day against the last day
+// it was seen before
+type SupplyChange struct {
+	Token    string    `json:"token"`
+	Date     string    `json:"date"`     // UTC day, e.g. 2025-01-02
+	Since    string    `json:"since"`    // day Previous was seen, usually the day before
+	Previous float64   `json:"previous"` // circulating supply on Since
+	Current  float64   `json:"current"`  // circulating supply last seen on Date
+	Change   float64   `json:"change"`   // current / previous - 1
+	Flagged  bool      `json:"flagged"`  // change beyond the threshold, e.g. an unlock or mint
+	Time     time.Time `json:"time"`     // when Current was seen
+}
+
+// String describes the change in one line
+func (c SupplyChange) String() string {
+	dir := "up"
+	if c.Change < 0 {
+		dir = "down"
+	}
+	return fmt.Sprintf("%s circulating supply %s %.2f%% on %s: %s -> %s",
+		c.Token, dir, math.Abs(c.Change)*100, c.Date, formatAmount(c.Previous), formatAmount(c.Current))
+}
+
+// formatAmount formats a supply with thousands separators and no decimals
+func formatAmount(v float64) string {
+	s := fmt.Sprintf("%.0f", v)
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// RiskTokenFlags are the flags of a token
+type RiskTokenFlags struct {
+	Token string     `json:"token"`
+	Flags []RiskFlag `json:"flags"`
+}
+
+// RiskFeed lists every flagged token
+type RiskFeed struct {
+	Tokens    []RiskTokenFlags `json:"tokens"`
+	CheckedAt time.Time        `json:"checked_at"` // last scan; zero before the first
+}
+
+// MarketAsset is a token's rank, price and market data
+type MarketAsset struct {
+	Rank      int       `json:"rank"`
+	ID        string    `json:"id"`
+	Symbol    string    `json:"symbol"`
+	Name      string    `json:"name"`
+	Price     float64   `json:"price"`
+	MarketCap float64   `json:"market_cap"`
+	Volume24h float64   `json:"volume_24h"`
+	Change24h float64   `json:"change_24h"` // percent
+	Change7d  float64   `json:"change_7d"`  // percent
+	UpdatedAt time.Time `json:"updated_at"`
+
+	CirculatingSupply float64      `json:"circulating_supply,omitempty"`
+	TotalSupply       float64      `json:"total_supply,omitempty"`
+	SupplyCheck       *SupplyCheck `json:"supply_check,omitempty"` // on-chain supply, for verified tokens
+
+	RiskFlags []RiskFlag `json:"risk_flags,omitempty"` // reasons to warn before a swap
+
+	Categories []string         `json:"categories,omitempty"` // e.g. defi, layer-1, if known
+	Formatted  *FormattedValues `json:"formatted,omitempty"`  // display strings, if requested
+
+	Decimals *int     `json:"decimals,omitempty"` // on-chain decimals, if overridden
+	Tags     []string `json:"tags,omitempty"`     // labels set by an override
+}
+
+// MarketList is the largest tokens by market cap, largest first
+type MarketList struct {
+	Currency  string        `json:"currency"`
+	Assets    []MarketAsset `json:"assets"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	Cached    bool          `json:"cached"`
+}
+
+// Category is a category, its parent and how many tokens it holds
+type Category struct {
+	Name   string `json:"name"`
+	Parent string `json:"parent,omitempty"`
+	Source string `json:"source,omitempty"` // provider category slug
+	Tokens int    `json:"tokens"`
+}
+
+// CategoryList is every category, sorted by name
+type CategoryList struct {
+	Categories []Category `json:"categories"`
+	UpdatedAt  time.Time  `json:"updated_at,omitempty"` // last refresh from the provider
+}
+
+// GlobalOverview is the total market in one quote currency
+type GlobalOverview struct {
+	Currency               string             `json:"currency"`
+	TotalMarketCap         float64            `json:"total_market_cap"`
+	TotalVolume24h         float64            `json:"total_volume_24h"`
+	MarketCapChange24h     float64            `json:"market_cap_change_24h"` // percent, in USD
+	BTCDominance           float64            `json:"btc_dominance"`         // percent of total market cap
+	ETHDominance           float64            `json:"eth_dominance"`
+	Dominance              map[string]float64 `json:"dominance"` // largest tokens by symbol
+	ActiveCryptocurrencies int                `json:"active_cryptocurrencies"`
+	Markets                int                `json:"markets"`
+	UpdatedAt              time.Time          `json:"updated_at"`
+	Cached                 bool               `json:"cached"`
+}
+
+// TrendingCoin is a trending token
+type TrendingCoin struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Symbol        string  `json:"symbol"`
+	MarketCapRank int     `json:"market_cap_rank"`
+	Rank          int     `json:"rank"` // position in the trending list, 1 first
+	PriceUSD      float64 `json:"price_usd"`
+	Change24h     float64 `json:"change_24h"` // percent, in USD
+	Thumb         string  `json:"thumb"`
+}
+
+// TrendingList is the trending tokens
+type TrendingList struct {
+	Coins     []TrendingCoin `json:"coins"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	Cached    bool           `json:"cached"`
+}
+
+// Movers are the largest gainers and losers over a window
+type Movers struct {
+	Window    string           `json:"window"`
+	Currency  string           `json:"currency"`
+	Gainers   []*PriceResponse `json:"gainers"`
+	Losers    []*PriceResponse `json:"losers"`
+	Universe  int              `json:"universe"` // number of tokens ranked
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// IndexConstituent is one token of an index and its share of the value
+type IndexConstituent struct {
+	Token        string  `json:"token"`
+	Weight       float64 `json:"weight"` // fraction of the index, summing to 1
+	Price        float64 `json:"price"`
+	Change24h    float64 `json:"change_24h"`   // percent
+	Contribution float64 `json:"contribution"` // Weight * Price
+}
+
+// IndexValue is an index's current value. The value is the weighted sum of its
+// constituents' prices; Change24h compares it with the same sum over
+// their prices 24 hours ago.
+type IndexValue struct {
+	Name         string             `json:"name"`
+	Currency     string             `json:"currency"`
+	Value        float64            `json:"value"`
+	Change24h    float64            `json:"change_24h"` // percent
+	Constituents []IndexConstituent `json:"constituents"`
+	UpdatedAt    time.Time          `json:"updated_at"` // oldest constituent price
+	Cached       bool               `json:"cached"`     // every constituent came from cache
+}
+
+// DerivativesVenue is one perpetual market for a token
+type DerivativesVenue struct {
+	Market       string    `json:"market"`
+	Symbol       string    `json:"symbol"`
+	Price        float64   `json:"price"`
+	IndexPrice   float64   `json:"index_price"`
+	Basis        float64   `json:"basis"`
+	FundingRate  float64   `json:"funding_rate"`  // percent per funding interval
+	OpenInterest float64   `json:"open_interest"` // USD
+	Volume24h    float64   `json:"volume_24h"`    // USD
+	LastTradedAt time.Time `json:"last_traded_at"`
+}
+
+// DerivativesSummary aggregates a token's perpetual markets. FundingRate is weighted by
+// open interest; venues are sorted by open interest, largest first.
+type DerivativesSummary struct {
+	Token        string             `json:"token"`
+	Symbol       string             `json:"symbol"`
+	FundingRate  float64            `json:"funding_rate"`
+	OpenInterest float64            `json:"open_interest"`
+	Volume24h    float64            `json:"volume_24h"`
+	Venues       []DerivativesVenue `json:"venues"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	Cached       bool               `json:"cached"`
+}
+
+// NFTAmount is a value in the collection's native currency and in USD
+type NFTAmount struct {
+	Native float64 `json:"native"`
+	USD    float64 `json:"usd"`
+}
+
+// NFTCollection is the floor price and activity of an NFT collection
+type NFTCollection struct {
+	ID                  string    `json:"id"`
+	Name                string    `json:"name"`
+	Symbol              string    `json:"symbol"`
+	Platform            string    `json:"platform"`
+	ContractAddress     string    `json:"contract_address"`
+	NativeCurrency      string    `json:"native_currency"`
+	FloorPrice          NFTAmount `json:"floor_price"`
+	FloorPriceChange24h float64   `json:"floor_price_change_24h"` // percent, in USD
+	Volume24h           NFTAmount `json:"volume_24h"`
+	Owners              int64     `json:"owners"`
+	TotalSupply         float64   `json:"total_supply"`
+	UpdatedAt           time.Time `json:"updated_at"`
+	Cached              bool      `json:"cached"`
+}
+
+// TVLProtocol is a DeFi protocol's TVL in USD
+type TVLProtocol struct {
+	Slug      string             `json:"protocol"`
+	Name      string             `json:"name"`
+	Symbol    string             `json:"symbol"`
+	Category  string             `json:"category"`
+	TokenID   string             `json:"token_id,omitempty"` // CoinGecko id of the protocol token
+	Chains    []string           `json:"chains"`
+	TVL       float64            `json:"tvl"`
+	ChainTVLs map[string]float64 `json:"chain_tvls"`
+	Change1d  float64            `json:"change_1d"` // percent
+	Change7d  float64            `json:"change_7d"` // percent
+	UpdatedAt time.Time          `json:"updated_at"`
+	Cached    bool               `json:"cached"`
+}
+
+// ContractPrice is a token contract's price
+type ContractPrice struct {
+	Chain     string    `json:"chain"`
+	Contract  string    `json:"contract"`
+	Currency  string    `json:"currency"`
+	Price     float64   `json:"price"`
+	MarketCap float64   `json:"market_cap,omitempty"`
+	Volume24h float64   `json:"volume_24h,omitempty"`
+	Change24h float64   `json:"change_24h,omitempty"` // percent
+	Source    string    `json:"source"`               // "coingecko" or "dex"
+	Pool      string    `json:"pool,omitempty"`       // DEX pair priced from
+	Liquidity float64   `json:"liquidity,omitempty"`  // USD value of the pool
+	UpdatedAt time.Time `json:"updated_at"`
+	Cached    bool      `json:"cached"`
+}
+
+// GasFees are priority fees, or max fees, at three confirmation speeds in gwei
+type GasFees struct {
+	Slow     float64 `json:"slow"`
+	Standard float64 `json:"standard"`
+	Fast     float64 `json:"fast"`
+}
+
+// GasEstimate is a chain's current fee estimate. EIP-1559 fields are zero on
+// chains without a base fee; GasPrice is set on every chain.
+type GasEstimate struct {
+	Chain       string    `json:"chain"`
+	ChainID     uint64    `json:"chain_id"`
+	Block       uint64    `json:"block"`
+	BaseFee     float64   `json:"base_fee_gwei"`
+	PriorityFee GasFees   `json:"priority_fee_gwei"`
+	MaxFee      GasFees   `json:"max_fee_gwei"`
+	GasPrice    float64   `json:"gas_price_gwei"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Cached      bool      `json:"cached"`
+}
+
+// PriceMark is a price and when it was seen
+type PriceMark struct {
+	Price float64   `json:"price"`
+	Time  time.Time `json:"time"`
+}
+
+// Extremes are a token's highs and lows
+type Extremes struct {
+	Token    string `json:"token"`
+	Currency string `json:"currency"`
+
+	// Tracked is false for tokens outside EXTREMES_TOKENS, whose extremes
+	// come from the last year of history only
+	Tracked bool      `json:"tracked"`
+	Since   time.Time `json:"since"` // earliest price the extremes cover
+
+	Price   float64   `json:"price"`    // latest price
+	FromATH float64   `json:"from_ath"` // percent below the all-time high
+	ATH     PriceMark `json:"ath"`
+	ATL     PriceMark `json:"atl"`
+	High52w PriceMark `json:"high_52w"`
+	Low52w  PriceMark `json:"low_52w"`
+	Updated time.Time `json:"updated_at"`
+}
+
+// ListedCoin is a listed coin
+type ListedCoin struct {
+	ID     string `json:"id"`
+	Symbol string `json:"symbol"`
+	Name   string `json:"name"`
+}
+
+// ListingChange is a coin listed or delisted between two checks
+type ListingChange struct {
+	Event      string    `json:"event"` // "listed" or "delisted"
+	ListedCoin           // as last listed for delistings
+	Time       time.Time `json:"time"` // when the check found it
+}
+
+// String describes the change in one line
+func (c ListingChange) String() string {
+	return fmt.Sprintf("%s (%s) %s: %s", c.Name, strings.ToUpper(c.Symbol), c.Event, c.ID)
+}
+
+// ListingFeed is the changes since a time
+type ListingFeed struct {
+	Since     time.Time       `json:"since"`
+	CheckedAt time.Time       `json:"checked_at"` // last successful check
+	Coins     int             `json:"coins"`      // currently listed
+	Changes   []ListingChange `json:"changes"`    // oldest first
+}
+
+// Stablecoin is a monitored stablecoin
+type Stablecoin struct {
+	ID     string  `json:"id"`     // token id, e.g. tether
+	Symbol string  `json:"symbol"` // e.g. usdt
+	Peg    float64 `json:"peg"`    // target USD price, 1 if zero
+}
+
+// PegSample is one observation of a coin's price
+type PegSample struct {
+	Time      time.Time `json:"time"`
+	Price     float64   `json:"price"`
+	Deviation float64   `json:"deviation"`
+}
+
+// StablecoinStatus is the current state of a coin
+type StablecoinStatus struct {
+	Stablecoin
+	Price        float64            `json:"price"`
+	Deviation    float64            `json:"deviation"` // (price - peg) / peg
+	Depegged     bool               `json:"depegged"`
+	Sources      map[string]float64 `json:"sources"`
+	MaxDeviation float64            `json:"max_deviation"` // largest absolute deviation in the history window
+	DepeggedAt   *time.Time         `json:"depegged_at,omitempty"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	History      []PegSample        `json:"history,omitempty"`
+}
+
+// MarketReport summarizes the market over a period
+type MarketReport struct {
+	Period      string             `json:"period"`
+	Currency    string             `json:"currency"`
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"`
+	Market      *GlobalOverview    `json:"market,omitempty"`
+	Gainers     []MarketAsset      `json:"gainers"` // by change over the period
+	Losers      []MarketAsset      `json:"losers"`
+	Universe    int                `json:"universe"` // tokens ranked for gainers and losers
+	Trending    []TrendingCoin     `json:"trending,omitempty"`
+	Stablecoins []StablecoinStatus `json:"stablecoins,omitempty"`
+}
+
+// StablecoinPegs lists the peg status of monitored stablecoins
+type StablecoinPegs struct {
+	Threshold   float64            `json:"threshold"`
+	Stablecoins []StablecoinStatus `json:"stablecoins"`
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package wire
+
+import "time"
+
+// PortfolioTrade is a purchase or sale of a holding's token
+type PortfolioTrade struct {
+	Time   time.Time `json:"time"`
+	Amount float64   `json:"amount"` // bought if positive, sold if negative
+	Price  float64   `json:"price"`  // per unit, in the request currency
+}
+
+// PortfolioHolding is an amount of a token held now. Trades, if given, are used
+// for the cost basis and realized PnL and to value the holding as it was
+// before them; any amount not accounted for by trades was held from the
+// start at CostBasis, and PnL is unknown if that is not set.
+type PortfolioHolding struct {
+	Token     string           `json:"token"`
+	Amount    float64          `json:"amount"`
+	CostBasis *float64         `json:"cost_basis,omitempty"` // average price paid per unit held before any trades
+	Trades    []PortfolioTrade `json:"trades,omitempty"`
+}
+
+// PortfolioRequest is a portfolio to evaluate
+type PortfolioRequest struct {
+	Currency string             `json:"currency"` // usd if empty
+	Days     int                `json:"days"`     // length of the value series, DefaultDays if zero
+	Holdings []PortfolioHolding `json:"holdings"`
+}
+
+// PortfolioPoint is the portfolio's value at a point in time
+type PortfolioPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// PortfolioPosition is a holding's current value and PnL. Cost and PnL are null
+// when part of the holding has no known cost.
+type PortfolioPosition struct {
+	Token         string   `json:"token"`
+	Amount        float64  `json:"amount"`
+	Price         float64  `json:"price"`
+	PriceStr      string   `json:"price_str"` // exact decimal of Price
+	Value         float64  `json:"value"`
+	ValueStr      string   `json:"value_str"`  // exact decimal of Value
+	CostBasis     *float64 `json:"cost_basis"` // average price paid per unit held
+	Cost          *float64 `json:"cost"`
+	UnrealizedPnL *float64 `json:"unrealized_pnl"`
+	RealizedPnL   *float64 `json:"realized_pnl"`
+}
+
+// PortfolioPerformance is a portfolio's value over time and its PnL. Totals of
+// cost and PnL cover the positions where they are known.
+type PortfolioPerformance struct {
+	Currency      string  `json:"currency"`
+	Days          int     `json:"days"`
+	Value         float64 `json:"value"`
+	ValueStr      string  `json:"value_str"` // exact decimal of Value
+	Cost          float64 `json:"cost"`
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+	RealizedPnL   float64 `json:"realized_pnl"`
+
+	// Returns are percent changes in value over each of ReturnWindows,
+	// net of trades in the window; null if the portfolio had no value
+	// at the start
+	Returns map[string]*float64 `json:"returns"`
+
+	Positions []PortfolioPosition `json:"positions"`
+	Points    []PortfolioPoint    `json:"points"` // daily at 00:00 UTC, then now
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+// AllocationHolding is an amount of a token held on a chain, staked or not
+type AllocationHolding struct {
+	Token  string  `json:"token"`
+	Amount float64 `json:"amount"`
+	Chain  string  `json:"chain,omitempty"` // e.g. ethereum
+	Staked bool    `json:"staked,omitempty"`
+}
+
+// AllocationRequest is a portfolio to break down
+type AllocationRequest struct {
+	Currency string              `json:"currency"` // usd if empty
+	Holdings []AllocationHolding `json:"holdings"`
+}
+
+// AllocationShare is the part of a portfolio's value in one group
+type AllocationShare struct {
+	Name    string  `json:"name"`
+	Value   float64 `json:"value"`
+	Percent float64 `json:"percent"` // of the portfolio's value
+}
+
+// Allocation breaks a portfolio's current value down by token, category,
+// chain, stability and staking. Shares are sorted by value, largest
+// first.
+type Allocation struct {
+	Currency string  `json:"currency"`
+	Value    float64 `json:"value"`
+	ValueStr string  `json:"value_str"` // exact decimal of Value
+
+	Tokens []AllocationShare `json:"tokens"`
+
+	// Categories count a token toward each of its categories, so they
+	// may add up to more than 100%
+	Categories []AllocationShare `json:"categories"`
+
+	Chains    []AllocationShare `json:"chains"`
+	Stability []AllocationShare `json:"stability"` // stablecoin and volatile
+	Staking   []AllocationShare `json:"staking"`   // staked and liquid
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// Lot is an acquisition of a token
+type Lot struct {
+	ID     string    `json:"id,omitempty"` // client reference, echoed back
+	Token  string    `json:"token"`
+	Amount float64   `json:"amount"`
+	Time   time.Time `json:"time"`
+}
+
+// TaxLotsRequest is a list of acquisitions to enrich
+type TaxLotsRequest struct {
+	Currency string `json:"currency"` // usd if empty
+	Lots     []Lot  `json:"lots"`
+}
+
+// TaxLot is a lot valued at acquisition and now. The acquisition price,
+// cost basis and gain are null for lots older than the price history.
+type TaxLot struct {
+	Lot
+	AcquisitionPrice *float64 `json:"acquisition_price"` // per unit
+	CostBasis        *float64 `json:"cost_basis"`
+	Price            float64  `json:"price"`     // current, per unit
+	PriceStr         string   `json:"price_str"` // exact decimal of Price
+	Value            float64  `json:"value"`
+	ValueStr         string   `json:"value_str"` // exact decimal of Value
+	Gain             *float64 `json:"gain"`
+	GainPercent      *float64 `json:"gain_percent"`
+	HoldingDays      int      `json:"holding_days"`
+	Term             string   `json:"term"`
+}
+
+// TaxLots are enriched tax lots and their totals
+type TaxLots struct {
+	Currency  string    `json:"currency"`
+	Lots      []TaxLot  `json:"lots"`
+	Value     float64   `json:"value"`      // of every lot
+	ValueStr  string    `json:"value_str"`  // exact decimal of Value
+	CostBasis float64   `json:"cost_basis"` // of lots with a known cost basis
+	Gain      float64   `json:"gain"`       // of lots with a known cost basis
+	UpdatedAt time.Time `json:"updated_at"`
+}
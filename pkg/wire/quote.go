@@ -0,0 +1,109 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package wire
+
+import (
+	"time"
+
+	"github.com/luxfi/pricing/pkg/decimal"
+)
+
+// BridgeRate is a pair's exchange rate. Rate and Inverse are rounded down,
+// so a conversion either way never credits more than the value received.
+type BridgeRate struct {
+	Pair       string          `json:"pair"`
+	Base       string          `json:"base"`
+	Quote      string          `json:"quote"`
+	Rate       decimal.Decimal `json:"rate"`        // quote per base
+	Inverse    decimal.Decimal `json:"inverse"`     // base per quote
+	BasePrice  decimal.Decimal `json:"base_price"`  // in the reference currency
+	QuotePrice decimal.Decimal `json:"quote_price"` // in the reference currency
+	UpdatedAt  time.Time       `json:"updated_at"`  // oldest price the rate is derived from
+	AgeSeconds int64           `json:"age_seconds"`
+}
+
+// BridgeRates are the requested pairs' rates, all no older than MaxAge
+type BridgeRates struct {
+	Currency  string       `json:"currency"`
+	MaxAge    int64        `json:"max_age_seconds"`
+	Decimals  int          `json:"decimals"`
+	Rates     []BridgeRate `json:"rates"`
+	UpdatedAt time.Time    `json:"updated_at"` // oldest rate
+	Time      time.Time    `json:"time"`
+}
+
+// OnrampQuote is one on-ramp provider's offer. EffectivePrice is what each
+// token costs once fees are paid, so offers compare on it directly.
+type OnrampQuote struct {
+	Provider       string          `json:"provider"`
+	FiatAmount     decimal.Decimal `json:"fiat_amount"`      // paid, fees included
+	CryptoAmount   decimal.Decimal `json:"crypto_amount"`    // received
+	Fees           decimal.Decimal `json:"fees"`             // in fiat
+	EffectivePrice decimal.Decimal `json:"effective_price"`  // FiatAmount / CryptoAmount
+	Markup         *float64        `json:"markup,omitempty"` // percent over the market price
+}
+
+// OnrampComparison is every on-ramp provider's offer for a purchase, best
+// first
+type OnrampComparison struct {
+	Token       string            `json:"token"`
+	Fiat        string            `json:"fiat"`
+	Amount      decimal.Decimal   `json:"amount"`
+	MarketPrice *decimal.Decimal  `json:"market_price,omitempty"`
+	Quotes      []OnrampQuote     `json:"quotes"`
+	Best        string            `json:"best,omitempty"`   // provider with the lowest effective price
+	Errors      map[string]string `json:"errors,omitempty"` // provider -> why it has no quote
+}
+
+// PriceQuote is a price quote as EIP-712 typed data, for contracts to
+// accept until Expiry after checking its signer with ecrecover. Price is
+// the price × 10^Decimals, as a decimal string since it can exceed what
+// JSON numbers hold exactly.
+type PriceQuote struct {
+	Token     string `json:"token"`
+	Currency  string `json:"currency"`
+	Price     string `json:"price"`
+	Decimals  uint8  `json:"decimals"`
+	Timestamp int64  `json:"timestamp"` // when the price was fetched, unix seconds
+	Expiry    int64  `json:"expiry"`    // unix seconds
+}
+
+// QuorumResult is what each provider quoted for a token and the price
+// enough of them agree on
+type QuorumResult struct {
+	Token     string             `json:"token"`
+	Currency  string             `json:"currency"`
+	Price     decimal.Decimal    `json:"price"`            // median of the agreeing quotes
+	Quotes    map[string]float64 `json:"quotes"`           // by provider
+	Errors    map[string]string  `json:"errors,omitempty"` // providers that failed to quote
+	Agreeing  []string           `json:"agreeing"`         // providers within tolerance of the median
+	Providers int                `json:"providers"`        // providers serving the token
+	Required  int                `json:"required"`
+	Tolerance float64            `json:"tolerance"`
+	CheckedAt time.Time          `json:"checked_at"`
+}
+
+// QuoteResponse is a price quote as EIP-712 typed data. The digest is the
+// hash the signature is over, for checking a contract's encoding against.
+type QuoteResponse struct {
+	Token     string        `json:"token"`
+	Currency  string        `json:"currency"`
+	PriceStr  string        `json:"price_str"` // exact decimal price
+	Source    string        `json:"source"`
+	Quote     PriceQuote    `json:"quote"`
+	Domain    EIP712Domain  `json:"domain"`
+	Digest    string        `json:"digest"`
+	Signer    string        `json:"signer,omitempty"`
+	Signature string        `json:"signature,omitempty"` // r ‖ s ‖ v, v 27 or 28
+	Quorum    *QuorumResult `json:"quorum,omitempty"`    // what each provider quoted, if a quorum is required
+}
+
+// FXRates lists exchange rates from one base currency
+type FXRates struct {
+	Base        string             `json:"base"`
+	Rates       map[string]float64 `json:"rates"`
+	Unsupported []string           `json:"unsupported,omitempty"`
+	Source      string             `json:"source"`
+	UpdatedAt   string             `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package wire holds the JSON shapes the API serves and accepts. The
+// server's packages alias them and the Go client encodes and decodes
+// them, so the two never drift and the client does not link the server.
+package wire
+
+import "time"
+
+// PriceSignature is attached to signed price responses. Payload is the
+// exact canonical message that was signed.
+type PriceSignature struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id"`
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+// FormattedValues are display-ready renderings of a token's figures
+type FormattedValues struct {
+	Price     string `json:"price"`
+	Change24h string `json:"change_24h,omitempty"`
+	MarketCap string `json:"market_cap,omitempty"`
+	Volume24h string `json:"volume_24h,omitempty"`
+}
+
+// RiskFlag is a reason to warn about a token
+type RiskFlag struct {
+	Type     string `json:"type"`
+	Severity string `json:"severity"`
+	Detail   string `json:"detail"`
+}
+
+// PriceResponse is the API response format
+type PriceResponse struct {
+	ID        string    `json:"id"`
+	Symbol    string    `json:"symbol"`
+	Name      string    `json:"name"`
+	Price     float64   `json:"price"`
+	PriceStr  string    `json:"price_str"` // exact decimal of Price
+	Source    string    `json:"source"`    // provider or configured source that quoted it
+	Currency  string    `json:"currency"`
+	Change24h float64   `json:"change_24h"`
+	MarketCap float64   `json:"market_cap"`
+	Volume24h float64   `json:"volume_24h"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Cached    bool      `json:"cached"`
+
+	Decimals *int     `json:"decimals,omitempty"` // on-chain decimals, if overridden
+	Tags     []string `json:"tags,omitempty"`     // labels set by an override
+
+	Signature *PriceSignature  `json:"signature,omitempty"`
+	Formatted *FormattedValues `json:"formatted,omitempty"`  // display strings, if requested
+	RiskFlags []RiskFlag       `json:"risk_flags,omitempty"` // reasons to warn before a swap
+}
+
+// Reasons a token in a batch has no fresh price
+const (
+	CodeNotFound      = "not_found"      // no provider knows the token
+	CodeUpstreamError = "upstream_error" // the fetch failed and nothing is cached
+	CodeStaleOnly     = "stale_only"     // no fresh price; prices has the expired cached one
+	CodeTooOld        = "too_old"        // the price is older than the token's or request's max age
+)
+
+// TokenError explains why a token in a batch has no fresh price
+type TokenError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// MultiPriceResponse for multiple tokens. Every requested token without a
+// fresh price has an entry in Errors; stale_only tokens are also in Prices.
+type MultiPriceResponse struct {
+	Prices    map[string]*PriceResponse `json:"prices"`
+	Errors    map[string]*TokenError    `json:"errors,omitempty"`
+	UpdatedAt time.Time                 `json:"updated_at"`
+}
+
+// UpstreamStatus is when prices were last refreshed from upstream and
+// whether refreshes have been failing since
+type UpstreamStatus struct {
+	LastRefresh  *time.Time `json:"last_refresh,omitempty"`
+	FailingSince *time.Time `json:"failing_since,omitempty"`
+
+	// Stale is set once refreshes have failed for longer than the stale
+	// bound, so the prices served are older than it
+	Stale bool `json:"stale"`
+}
+
+// Degradation is one reason the service is degraded
+type Degradation struct {
+	Code      string     `json:"code"`
+	Component string     `json:"component,omitempty"` // provider or host affected
+	Message   string     `json:"message"`
+	Since     *time.Time `json:"since,omitempty"`
+	Until     *time.Time `json:"until,omitempty"` // expected end, if known
+}
+
+// StatusResponse summarizes degradation for clients to show a banner
+// from: Banner is empty while the service is ok
+type StatusResponse struct {
+	Status      string         `json:"status"`
+	Delayed     bool           `json:"delayed"` // prices may be older than usual
+	Banner      string         `json:"banner,omitempty"`
+	Degradation []Degradation  `json:"degradation"`
+	Upstream    UpstreamStatus `json:"upstream"`
+	Time        time.Time      `json:"time"`
+}
+
+// HealthResponse is returned by /health
+type HealthResponse struct {
+	Status string `json:"status"`
+	Time   string `json:"time"`
+}
+
+// EIP712Domain separates EIP-712 quote signatures by application, chain
+// and verifying contract
+type EIP712Domain struct {
+	Name              string `json:"name"`
+	Version           string `json:"version"`
+	ChainID           int64  `json:"chainId"`
+	VerifyingContract string `json:"verifyingContract,omitempty"`
+}
+
+// SigningKey is a published response or quote signing key
+type SigningKey struct {
+	KeyID     string        `json:"key_id"`
+	Algorithm string        `json:"algorithm"`
+	PublicKey string        `json:"public_key,omitempty"`
+	Address   string        `json:"address,omitempty"` // EIP-712 quote signer
+	Domain    *EIP712Domain `json:"domain,omitempty"`
+}
+
+// TenantUsageSnapshot is a tenant's request counts
+type TenantUsageSnapshot struct {
+	Tenant      string           `json:"tenant"`
+	Requests    int64            `json:"requests"`
+	RateLimited int64            `json:"rate_limited"`
+	Forbidden   int64            `json:"forbidden"`
+	ByEndpoint  map[string]int64 `json:"by_endpoint"`
+}
+
+// AuditEntry records a single admin action
+type AuditEntry struct {
+	ID        int64             `json:"id"`
+	Actor     string            `json:"actor"`
+	Action    string            `json:"action"`
+	Params    map[string]string `json:"params,omitempty"`
+	RemoteIP  string            `json:"remote_ip,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// AuditFilter selects entries from the audit log
+type AuditFilter struct {
+	Actor  string
+	Action string
+	Since  time.Time
+	Limit  int
+}
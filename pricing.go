@@ -0,0 +1,1886 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+// Package pricing embeds the Lux pricing service in another program.
+//
+// An Engine owns the provider, cache and HTTP handler. Mount the handler
+// under your own mux and call GetPrice in-process; both share one cache.
+//
+//	cfg := pricing.DefaultConfig()
+//	cfg.CoinGecko.APIKey = os.Getenv("COINGECKO_API_KEY")
+//	engine, err := pricing.NewEngine(cfg)
+//	...
+//	mux.Handle("/pricing/", http.StripPrefix("/pricing", engine.Handler()))
+//	btc, err := engine.GetPrice(ctx, "bitcoin", "usd")
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/pricing/pkg/alerts"
+	"github.com/luxfi/pricing/pkg/aliases"
+	"github.com/luxfi/pricing/pkg/analytics"
+	"github.com/luxfi/pricing/pkg/api"
+	"github.com/luxfi/pricing/pkg/audit"
+	"github.com/luxfi/pricing/pkg/bridge"
+	"github.com/luxfi/pricing/pkg/cache"
+	"github.com/luxfi/pricing/pkg/categories"
+	"github.com/luxfi/pricing/pkg/changes"
+	"github.com/luxfi/pricing/pkg/config"
+	"github.com/luxfi/pricing/pkg/decimal"
+	"github.com/luxfi/pricing/pkg/derivatives"
+	"github.com/luxfi/pricing/pkg/deviation"
+	"github.com/luxfi/pricing/pkg/evm"
+	"github.com/luxfi/pricing/pkg/extremes"
+	"github.com/luxfi/pricing/pkg/format"
+	"github.com/luxfi/pricing/pkg/fx"
+	"github.com/luxfi/pricing/pkg/gas"
+	"github.com/luxfi/pricing/pkg/global"
+	"github.com/luxfi/pricing/pkg/history"
+	"github.com/luxfi/pricing/pkg/index"
+	"github.com/luxfi/pricing/pkg/listings"
+	"github.com/luxfi/pricing/pkg/logos"
+	"github.com/luxfi/pricing/pkg/markets"
+	"github.com/luxfi/pricing/pkg/nft"
+	"github.com/luxfi/pricing/pkg/onramp"
+	"github.com/luxfi/pricing/pkg/oracle"
+	"github.com/luxfi/pricing/pkg/overrides"
+	"github.com/luxfi/pricing/pkg/portfolio"
+	"github.com/luxfi/pricing/pkg/providers"
+	"github.com/luxfi/pricing/pkg/quorum"
+	"github.com/luxfi/pricing/pkg/replica"
+	"github.com/luxfi/pricing/pkg/report"
+	"github.com/luxfi/pricing/pkg/risk"
+	"github.com/luxfi/pricing/pkg/signing"
+	"github.com/luxfi/pricing/pkg/slo"
+	"github.com/luxfi/pricing/pkg/snapshot"
+	"github.com/luxfi/pricing/pkg/stablecoins"
+	"github.com/luxfi/pricing/pkg/stream"
+	"github.com/luxfi/pricing/pkg/supply"
+	"github.com/luxfi/pricing/pkg/ticks"
+	"github.com/luxfi/pricing/pkg/tokenprice"
+	"github.com/luxfi/pricing/pkg/treasury"
+	"github.com/luxfi/pricing/pkg/trending"
+	"github.com/luxfi/pricing/pkg/tvl"
+)
+
+// Config is the service configuration
+type Config = config.Config
+
+// Response types returned by an Engine
+type (
+	PriceResponse      = cache.PriceResponse
+	MultiPriceResponse = cache.MultiPriceResponse
+)
+
+// DefaultConfig returns the default configuration. CoinGecko.APIKey must
+// be set before it is used.
+func DefaultConfig() *Config {
+	return config.Default()
+}
+
+// Engine is an in-process pricing service
+type Engine struct {
+	mu     sync.Mutex // guards cfg, source and stop
+	cfg    *Config
+	source func() (*Config, error)
+	stop   []context.CancelFunc // cancels the jobs each Start ran
+	jobs   sync.WaitGroup
+
+	coingecko  *providers.CoinGecko
+	provider   providers.Provider
+	upstream   *replica.Upstream
+	breakers   []*providers.Breaker
+	balancer   *providers.Balancer
+	pool       *http.Transport
+	rateLimits *providers.RateLimitTransport
+	inFlight   *providers.LimitTransport
+	scheduler  *providers.SchedulerTransport
+	schemas    *providers.SchemaMonitor
+	fx         *fx.Converter
+	pegs       *stablecoins.Monitor
+	deviation  *deviation.Monitor
+	oracle     *oracle.Pusher
+	bridge     *bridge.Service
+	onramps    *onramp.Aggregator
+	gas        *gas.Oracle
+	tokens     *tokenprice.Service
+	derivs     *derivatives.Aggregator
+	nfts       *nft.Service
+	tvl        *tvl.Service
+	logos      *logos.Service
+	indices    *index.Service
+	global     *global.Service
+	trending   *trending.Service
+	markets    *markets.Service
+	history    *history.Service
+	portfolio  *portfolio.Service
+	analytics  *analytics.Service
+	ticks      *ticks.Store
+	changes    *changes.Journal
+	stream     *stream.Hub
+	relay      *stream.Relay
+	slo        *slo.Tracker
+	alerts     *alerts.Store
+	channels   *alerts.Channels
+	snapshots  *snapshot.Exporter
+	reports    *report.Generator
+	treasury   *treasury.Service
+	extremes   *extremes.Tracker
+	listings   *listings.Tracker
+	categories *categories.Service
+	supply     *supply.Verifier
+	issuance   *supply.ChangeTracker
+	risk       *risk.Checker
+	aliases    *aliases.Table
+	overrides  *overrides.Table
+	cache      *cache.PriceCache
+	auditLog   *audit.Log
+	signer     *signing.Signer
+	quotes     *signing.Quoter
+	quorum     *quorum.Checker
+	tenants    *api.TenantRegistry
+	server     *api.Server
+
+	handlerOnce sync.Once
+	handler     http.Handler
+}
+
+// NewEngine validates cfg and builds an engine from it
+func NewEngine(cfg *Config) (*Engine, error) {
+	if err := validateEngineConfig(cfg); err != nil {
+		return nil, err
+	}
+	rounding, err := format.ParsePolicy(cfg.Rounding.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("rounding.policy: %w", err)
+	}
+
+	e := &Engine{cfg: cfg}
+	transport, err := e.setupTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	adapters, derived, err := e.setupProviders(cfg, transport)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.setupMarketData(cfg, derived, transport); err != nil {
+		return nil, err
+	}
+	if err := e.setupMonitors(cfg, adapters, transport); err != nil {
+		return nil, err
+	}
+	if err := e.setupAlerts(cfg, transport); err != nil {
+		return nil, err
+	}
+	if err := e.setupFeeds(cfg); err != nil {
+		return nil, err
+	}
+	e.setupSLO(cfg)
+	if err := e.setupNotifications(cfg, transport); err != nil {
+		return nil, err
+	}
+	if err := e.setupSnapshots(cfg, transport); err != nil {
+		return nil, err
+	}
+	if err := e.setupSigning(cfg, transport); err != nil {
+		return nil, err
+	}
+	if e.tenants, err = api.NewTenantRegistry(cfg.TenantsFile); err != nil {
+		return nil, fmt.Errorf("tenants: %w", err)
+	}
+
+	opts, err := e.apply(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Audit log for admin operations (memory only if no path is set); opened
+	// last so earlier failures don't leak the file
+	if e.auditLog, err = audit.NewLog(cfg.Admin.AuditLogPath); err != nil {
+		return nil, fmt.Errorf("audit log: %w", err)
+	}
+
+	e.server = api.NewServer(e.serverOptions(cfg, opts, rounding))
+	return e, nil
+}
+
+// validateEngineConfig checks the route and feature names cfg refers to,
+// which the config package does not know
+func validateEngineConfig(cfg *Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	for route := range cfg.Upstream.RouteTimeouts {
+		if !api.HasRoute(route) {
+			return fmt.Errorf("upstream.route_timeouts: unknown route %s", route)
+		}
+	}
+	for _, route := range cfg.Upstream.HedgeRoutes {
+		if !api.HasRoute(route) {
+			return fmt.Errorf("upstream.hedge_routes: unknown route %s", route)
+		}
+	}
+	groups := api.FeatureGroups()
+	for _, name := range append(cfg.Features.Enabled, cfg.Features.Disabled...) {
+		if !slices.Contains(groups, name) {
+			return fmt.Errorf("features: unknown group %q; groups are %s", name, strings.Join(groups, ", "))
+		}
+	}
+	return nil
+}
+
+// setupTransport builds the transport shared by all upstream providers.
+// One pooled transport caps requests in flight, pauses rate-limited
+// hosts and retries transient failures.
+func (e *Engine) setupTransport(cfg *Config) (http.RoundTripper, error) {
+	e.pool = providers.NewTransport(transportConfig(cfg.Upstream))
+	var pooled http.RoundTripper = e.pool
+	switch {
+	case cfg.Upstream.Record != "":
+		rec, err := providers.NewRecordTransport(pooled, cfg.Upstream.Record)
+		if err != nil {
+			return nil, fmt.Errorf("upstream.record: %w", err)
+		}
+		pooled = rec
+	case cfg.Upstream.Replay != "":
+		replay, err := providers.NewReplayTransport(cfg.Upstream.Replay)
+		if err != nil {
+			return nil, fmt.Errorf("upstream.replay: %w", err)
+		}
+		pooled = replay
+	}
+	switch cfg.Provider {
+	case "mock":
+		pooled = providers.NewMockTransport(pooled, providers.NewMockHandler())
+	case "demo":
+		pooled = providers.NewMockTransport(pooled, providers.NewDemoHandler(int64(cfg.Demo.Seed), cfg.Demo.Tokens))
+	}
+	if cfg.Upstream.MaxInFlight > 0 {
+		e.inFlight = providers.NewLimitTransport(pooled, cfg.Upstream.MaxInFlight, cfg.Upstream.MaxQueued)
+		pooled = e.inFlight
+	}
+	if len(cfg.Upstream.RequestsPerMinute) > 0 {
+		e.scheduler = providers.NewSchedulerTransport(pooled, cfg.Upstream.RequestsPerMinute)
+		pooled = e.scheduler
+	}
+	e.rateLimits = providers.NewRateLimitTransport(pooled, cfg.Upstream.RateLimitPause.Duration)
+	return providers.NewRetryTransport(e.rateLimits, providers.RetryPolicy{
+		Attempts:   cfg.Upstream.RetryAttempts,
+		Backoff:    cfg.Upstream.RetryBackoff.Duration,
+		MaxBackoff: cfg.Upstream.RetryMaxBackoff.Duration,
+	}), nil
+}
+
+// setupProviders builds the provider chain the cache fetches through and
+// the cache itself. It returns the plugin adapters, which the monitors
+// compare with CoinGecko, and the FX provider deriving currencies, if any.
+func (e *Engine) setupProviders(cfg *Config, transport http.RoundTripper) ([]*providers.HTTPAdapter, *fx.Provider, error) {
+	e.coingecko = providers.NewCoinGecko(cfg.CoinGecko.APIKey, transport)
+	switch {
+	case cfg.Provider != "coingecko":
+		e.coingecko.BaseURL = providers.MockURL
+	case cfg.CoinGecko.BaseURL != "":
+		e.coingecko.BaseURL = strings.TrimRight(cfg.CoinGecko.BaseURL, "/")
+	}
+	e.coingecko.SetConcurrency(cfg.Upstream.Concurrency)
+	e.coingecko.SetTimeout(cfg.Upstream.Timeout.Duration)
+	e.schemas = providers.NewSchemaMonitor()
+	e.coingecko.SetSchemaMonitor(e.schemas)
+
+	// Each price provider fails fast behind its own circuit breaker while
+	// it is down
+	breaker := func(p providers.Provider) providers.Provider {
+		b := providers.NewBreaker(p, providers.BreakerPolicy{
+			Failures: cfg.Upstream.BreakerFailures,
+			Cooldown: cfg.Upstream.BreakerCooldown.Duration,
+		})
+		e.breakers = append(e.breakers, b)
+		return b
+	}
+	e.provider = breaker(e.coingecko)
+
+	// Quote and oracle prices must be agreed by several providers, each
+	// asked through the breaker it is routed through
+	if cfg.Quorum.Min > 0 {
+		e.quorum = quorum.NewChecker(quorum.Options{
+			Min:       cfg.Quorum.Min,
+			Tolerance: cfg.Quorum.Tolerance,
+			TTL:       cfg.Quorum.TTL.Duration,
+			Resolve:   func(id string) string { return e.aliases.Resolve(id) },
+		})
+	}
+	var countErr error
+	counted := func(p providers.Provider, ids ...string) providers.Provider {
+		if e.quorum != nil && (len(cfg.Quorum.Providers) == 0 || slices.Contains(cfg.Quorum.Providers, p.Name())) {
+			if err := e.quorum.AddProvider(p, ids...); err != nil && countErr == nil {
+				countErr = err
+			}
+		}
+		return p
+	}
+	counted(e.provider)
+
+	// Alternate plugins serve every token alongside CoinGecko; each call
+	// goes to whichever is currently fastest and healthy
+	adapters := make([]*providers.HTTPAdapter, len(cfg.Plugins))
+	for i, p := range cfg.Plugins {
+		adapters[i] = providers.NewHTTPAdapter(p.Name, p.URL, p.Timeout.Duration, transport)
+		adapters[i].SetSchemaMonitor(e.schemas)
+	}
+	alternates := []providers.Provider{e.provider}
+	for i, p := range cfg.Plugins {
+		if p.Mode == "alternate" {
+			alternates = append(alternates, counted(breaker(adapters[i])))
+		}
+	}
+	if len(alternates) > 1 {
+		e.balancer = providers.NewBalancer(cfg.Upstream.HedgeAfter.Duration, alternates...)
+		e.provider = e.balancer
+	}
+
+	// Plugins, override sources and the metals provider serve the tokens
+	// they claim; everything else uses CoinGecko, as do claimed tokens
+	// while their provider's circuit is open. Supplement sources serve
+	// their tokens only when CoinGecko has no price.
+	if len(cfg.Plugins) > 0 || len(cfg.Sources) > 0 || cfg.Metals.APIKey != "" {
+		router := providers.NewRouter(e.provider)
+		for i, p := range cfg.Plugins {
+			if p.Mode == "alternate" {
+				continue
+			}
+			if err := router.Route(counted(breaker(adapters[i]), p.Tokens...), p.Tokens...); err != nil {
+				return nil, nil, fmt.Errorf("plugins: %w", err)
+			}
+		}
+		for _, sc := range cfg.Sources {
+			src := newSource(sc, e.provider, transport)
+			tokens := src.Tokens()
+			route := router.Route
+			if sc.Mode == "supplement" {
+				route = router.Supplement
+			}
+			if err := route(counted(breaker(src), tokens...), tokens...); err != nil {
+				return nil, nil, fmt.Errorf("sources: %w", err)
+			}
+		}
+		if cfg.Metals.APIKey != "" {
+			metals := providers.NewMetals(cfg.Metals.APIKey, transport)
+			metals.SetTimeout(cfg.Upstream.Timeout.Duration)
+			if cfg.Metals.BaseURL != "" {
+				metals.BaseURL = strings.TrimRight(cfg.Metals.BaseURL, "/")
+			}
+			commodities := providers.CommodityIDs()
+			if err := router.Route(counted(breaker(metals), commodities...), commodities...); err != nil {
+				return nil, nil, fmt.Errorf("metals: %w", err)
+			}
+		}
+		e.provider = router
+	}
+	if countErr != nil {
+		return nil, nil, fmt.Errorf("quorum: %w", countErr)
+	}
+
+	// Currencies the providers don't quote are derived from USD prices
+	var derived *fx.Provider
+	if e.fx = fxConverter(cfg.FX, &http.Client{Timeout: cfg.Upstream.Timeout.Duration, Transport: transport}); e.fx != nil {
+		derived = fx.NewProvider(e.provider, e.fx, providers.CoinGeckoCurrencies)
+		derived.SetNormalize(cfg.FX.Normalize)
+		e.provider = derived
+	}
+
+	// Metadata overrides patch what providers return, keyed by the ids
+	// aliases resolve to
+	var err error
+	if e.overrides, err = overrides.NewTable(cfg.Overrides.File); err != nil {
+		return nil, nil, fmt.Errorf("overrides: %w", err)
+	}
+	e.provider = overrides.NewProvider(e.provider, e.overrides)
+
+	// Aliases are resolved before anything reaches a provider
+	if e.aliases, err = aliases.NewTable(cfg.Aliases.Tokens, cfg.Aliases.File); err != nil {
+		return nil, nil, fmt.Errorf("aliases: %w", err)
+	}
+	e.provider = aliases.NewProvider(e.provider, e.aliases)
+
+	// A secondary asks the primary for prices it has not been sent, so
+	// only the primary spends upstream quota on them
+	cached := e.provider
+	if cfg.Replica.Role == replica.Secondary {
+		e.upstream = replica.NewUpstream(cfg.Replica.PrimaryURL, cfg.Replica.APIKey, cfg.Upstream.Timeout.Duration, transport)
+		cached = breaker(e.upstream)
+	}
+	e.cache = cache.NewPriceCache(cached)
+	return adapters, derived, nil
+}
+
+// setupMarketData builds the services answering from CoinGecko market
+// data, history and on-chain reads: markets, categories, history,
+// portfolios, analytics, indices, TVL, logos, gas, token and supply
+// lookups. derived, if set, converts market lists to derived currencies.
+func (e *Engine) setupMarketData(cfg *Config, derived *fx.Provider, transport http.RoundTripper) error {
+	e.derivs = derivatives.NewAggregator(e.coingecko.FetchDerivatives, e.tokenSymbol, cfg.Derivatives.TTL.Duration)
+
+	e.nfts = nft.NewService(e.coingecko.FetchNFT, cfg.NFT.TTL.Duration)
+
+	e.global = global.NewService(e.coingecko.FetchGlobal, cfg.Global.TTL.Duration)
+
+	e.trending = trending.NewService(e.coingecko.FetchTrending, cfg.Trending.TTL.Duration)
+
+	topMarkets := e.coingecko.FetchTopMarkets
+	if derived != nil {
+		topMarkets = derived.Markets(topMarkets)
+	}
+	e.markets = markets.NewService(topMarkets, cfg.Markets.TTL.Duration)
+	if c := cfg.Categories; len(c.Sources) > 0 || len(c.Overrides) > 0 {
+		e.categories = categories.NewService(categories.Options{
+			Sources:   c.Sources,
+			Parents:   c.Parents,
+			Overrides: c.Overrides,
+		}, e.coingecko.FetchCategory)
+	}
+
+	e.history = history.NewService(aliases.Fetcher(e.coingecko.FetchMarketChart, e.aliases), cfg.History.TTL.Duration)
+	if cfg.History.Dir != "" {
+		store, err := history.NewStore(cfg.History.Dir)
+		if err != nil {
+			return fmt.Errorf("history store: %w", err)
+		}
+		e.history.SetStore(store)
+	}
+
+	// Portfolios are valued at cached prices and CoinGecko history
+	e.portfolio = portfolio.NewService(e.history.History, func(ctx context.Context, ids []string, currency string) (map[string]decimal.Decimal, error) {
+		resp, err := e.cache.GetMultiplePrices(ctx, ids, currency)
+		if err != nil {
+			return nil, err
+		}
+		prices := make(map[string]decimal.Decimal, len(resp.Prices))
+		for id, p := range resp.Prices {
+			price, err := decimal.Parse(p.PriceStr)
+			if err != nil {
+				price = decimal.FromFloat(p.Price)
+			}
+			prices[id] = price
+		}
+		return prices, nil
+	})
+
+	// Allocations group holdings by category, and count the monitored
+	// stablecoins and tokens in a stablecoins category as stable
+	stable := make(map[string]bool)
+	for _, coin := range cfg.Stablecoins.Coins {
+		stable[strings.ToLower(coin.ID)] = true
+	}
+	if len(stable) == 0 {
+		for _, coin := range stablecoins.DefaultCoins {
+			stable[coin.ID] = true
+		}
+	}
+	e.portfolio.Stablecoin = func(tokenID string) bool {
+		return stable[tokenID] || (e.categories != nil && slices.Contains(e.categories.Of(tokenID), "stablecoins"))
+	}
+	if e.categories != nil {
+		e.portfolio.Categories = e.categories.Of
+	}
+
+	e.tvl = tvl.NewService(&http.Client{Timeout: cfg.Upstream.Timeout.Duration, Transport: transport}, cfg.TVL.TTL.Duration)
+	if cfg.TVL.BaseURL != "" {
+		e.tvl.BaseURL = strings.TrimRight(cfg.TVL.BaseURL, "/")
+	}
+
+	// Logos are proxied from the image URLs CoinGecko lists for tokens,
+	// unless a metadata override sets one
+	e.logos = logos.NewService(logos.Options{
+		TTL:      cfg.Logos.TTL.Duration,
+		MaxBytes: int64(cfg.Logos.MaxBytes),
+		Hosts:    cfg.Logos.Hosts,
+	}, func(ctx context.Context, tokenID string) (string, error) {
+		if o, ok := e.overrides.Get(e.aliases.Resolve(tokenID)); ok && o.Logo != "" {
+			return o.Logo, nil
+		}
+		p, err := e.coingecko.FetchPrice(ctx, tokenID, "usd")
+		if errors.Is(err, providers.ErrTokenNotFound) {
+			return "", fmt.Errorf("%w: %s", logos.ErrNotFound, tokenID)
+		}
+		if err != nil {
+			return "", err
+		}
+		return p.Image, nil
+	}, &http.Client{Timeout: cfg.Upstream.Timeout.Duration, Transport: transport})
+
+	e.indices = index.NewService(cfg.Indices, e.cache.GetMultiplePrices)
+
+	// Indices serve as benchmarks for relative performance
+	e.analytics = analytics.NewService(e.history.History, cfg.Analytics.RiskFreeRate)
+	e.analytics.Indices = e.indices.Weights
+
+	rpcs := make(map[string]string)
+	for chain, url := range cfg.Gas.RPCs {
+		if url != "" {
+			rpcs[chain] = url
+		}
+	}
+	if len(rpcs) > 0 {
+		e.gas = gas.NewOracle(rpcs, cfg.Gas.TTL.Duration, &http.Client{Timeout: 10 * time.Second, Transport: transport})
+	}
+	e.tokens = contractPricer(cfg.TokenPrice, rpcs, e.coingecko, e.cache, transport)
+	if len(cfg.Supply.Tokens) > 0 {
+		e.supply = supplyVerifier(cfg.Supply, rpcs, e.coingecko, transport)
+	}
+	return nil
+}
+
+// setupMonitors builds the jobs watching prices and pushing them
+// elsewhere: stablecoin pegs, risk flags, plugin deviation, the on-chain
+// oracle, on-ramp quotes and bridge rates
+func (e *Engine) setupMonitors(cfg *Config, adapters []*providers.HTTPAdapter, transport http.RoundTripper) error {
+	// Stablecoin pegs are checked against CoinGecko and any plugin that
+	// serves the same coins
+	if cfg.Stablecoins.Interval.Duration > 0 {
+		e.pegs = pegMonitor(cfg.Stablecoins)
+		e.pegs.AddSource(e.coingecko)
+		for i, adapter := range adapters {
+			e.pegs.AddSource(adapter, cfg.Plugins[i].Tokens...)
+		}
+	}
+
+	// Risk flags are scanned from market data and history, with recent
+	// depegs from the stablecoin monitor
+	if cfg.Risk.Interval.Duration > 0 {
+		var err error
+		if e.risk, err = riskChecker(cfg.Risk, e.markets, e.analytics, e.pegs, transport); err != nil {
+			return fmt.Errorf("risk: %w", err)
+		}
+	}
+
+	// Plugins are compared with CoinGecko for the tokens they serve
+	if cfg.Deviation.Interval.Duration > 0 && len(adapters) > 0 {
+		e.deviation = deviationMonitor(cfg.Deviation, cfg.Plugins)
+		e.deviation.AddSource(e.coingecko)
+		for i, adapter := range adapters {
+			e.deviation.AddSource(adapter, cfg.Plugins[i].Tokens...)
+		}
+	}
+
+	// Configured feeds are pushed on-chain from the cache, or at prices the
+	// quorum agrees if one is required
+	if cfg.Oracle.Contract != "" && cfg.Features.On("oracle") {
+		e.oracle = oraclePusher(cfg.Oracle, e.cache, e.quorum, transport)
+	}
+
+	// On-ramp quotes are compared with cached market prices
+	if len(cfg.Onramps) > 0 {
+		e.onramps = onrampAggregator(cfg.Onramps, e.cache, transport)
+	}
+
+	// Bridge rates are derived from the cache, refetching prices that are
+	// half their allowed age
+	if len(cfg.Bridge.Pairs) > 0 {
+		e.bridge = bridgeRates(cfg.Bridge, e.cache)
+	}
+	return nil
+}
+
+// setupAlerts builds the alert channels and store. Alerts are checked
+// whenever the cache fetches prices.
+func (e *Engine) setupAlerts(cfg *Config, transport http.RoundTripper) error {
+	channels := alerts.NewChannels(cfg.Alerts.TelegramBotToken, &http.Client{Timeout: 10 * time.Second, Transport: transport})
+	channels.Retry = alerts.RetryPolicy{Attempts: cfg.Alerts.RetryAttempts, Backoff: cfg.Alerts.RetryBackoff.Duration}
+	var err error
+	if channels.Deliveries, err = alerts.NewDeliveryLog(cfg.Alerts.DeadLetterFile); err != nil {
+		return fmt.Errorf("alerts: %w", err)
+	}
+	e.channels = channels
+	if cfg.Email.SMTPAddr != "" {
+		var tmpl []byte
+		if cfg.Email.Template != "" {
+			if tmpl, err = os.ReadFile(cfg.Email.Template); err != nil {
+				return fmt.Errorf("email: %w", err)
+			}
+		}
+		if channels.Mailer, err = alerts.NewMailer(alerts.MailerOptions{
+			Addr:     cfg.Email.SMTPAddr,
+			Username: cfg.Email.SMTPUsername,
+			Password: cfg.Email.SMTPPassword,
+			From:     cfg.Email.From,
+			Template: string(tmpl),
+			Batch:    cfg.Email.Batch.Duration,
+		}); err != nil {
+			return fmt.Errorf("email: %w", err)
+		}
+	}
+	if e.alerts, err = alerts.NewStore(cfg.Alerts.File, channels); err != nil {
+		return fmt.Errorf("alerts: %w", err)
+	}
+	if cfg.Features.On("alerts") {
+		e.cache.OnRefresh(e.alerts.Evaluate)
+	}
+	return nil
+}
+
+// setupFeeds records refreshed prices as ticks and changes, fans them out
+// to streams and relays them to other replicas
+func (e *Engine) setupFeeds(cfg *Config) error {
+	// Every accepted price is recorded for TWAP and VWAP
+	e.ticks = ticks.NewStore(ticks.Options{
+		Retention:       cfg.Ticks.Retention.Duration,
+		HourlyRetention: cfg.Ticks.HourlyRetention.Duration,
+		DailyRetention:  cfg.Ticks.DailyRetention.Duration,
+		Tokens:          cfg.Ticks.Tokens,
+		Currencies:      cfg.Ticks.Currencies,
+	})
+	e.cache.OnRefresh(e.ticks.Record)
+
+	// Changed prices are journaled for pollers of /changes
+	e.changes = changes.NewJournal(0)
+	e.cache.OnRefresh(e.changes.Record)
+
+	// Refreshed prices are fanned out to /stream subscribers
+	if cfg.Features.On("stream") {
+		e.stream = stream.NewHub(stream.Options{MaxClients: cfg.Stream.MaxClients, MaxLag: cfg.Stream.MaxLag.Duration})
+		e.cache.OnRefresh(e.stream.Publish)
+	}
+
+	// Refreshes are relayed to the streams of other replicas and, when
+	// replicating, stored in their caches; the newest price of a token
+	// wins whatever order refreshes arrive in. Secondaries only receive.
+	if cfg.Stream.RedisURL != "" && (e.stream != nil || cfg.Replica.Role != "") {
+		deliver := func(currency string, prices []*cache.PriceResponse) {
+			if cfg.Replica.Role != "" {
+				if prices = e.cache.Apply(currency, prices); len(prices) == 0 {
+					return
+				}
+				e.changes.Record(currency, prices)
+			}
+			if e.stream != nil {
+				e.stream.Publish(currency, prices)
+			}
+		}
+		relay, err := stream.NewRelay(stream.RelayOptions{URL: cfg.Stream.RedisURL, Channel: cfg.Stream.RedisChannel}, deliver)
+		if err != nil {
+			return fmt.Errorf("stream: %w", err)
+		}
+		e.relay = relay
+		if cfg.Replica.Role != replica.Secondary {
+			e.cache.OnRefresh(e.relay.Publish)
+		}
+	}
+	return nil
+}
+
+// setupSLO measures every route against its service level objectives,
+// if a window is set
+func (e *Engine) setupSLO(cfg *Config) {
+	if cfg.SLO.Window.Duration <= 0 {
+		return
+	}
+	objective := func(o config.SLOObjective) slo.Objective {
+		obj := slo.Objective{Availability: o.Availability, Latency: cfg.SLO.Latency.Duration, LatencyTarget: o.LatencyTarget}
+		if obj.Availability == 0 {
+			obj.Availability = cfg.SLO.Availability
+		}
+		if o.Latency != nil {
+			obj.Latency = o.Latency.Duration
+		}
+		if obj.LatencyTarget == 0 {
+			obj.LatencyTarget = cfg.SLO.LatencyTarget
+		}
+		return obj
+	}
+	opts := slo.Options{
+		Objective:    objective(config.SLOObjective{}),
+		Routes:       make(map[string]slo.Objective, len(cfg.SLO.Routes)),
+		Window:       cfg.SLO.Window.Duration,
+		ShedBurnRate: cfg.SLO.ShedBurnRate,
+	}
+	for route, o := range cfg.SLO.Routes {
+		opts.Routes[route] = objective(o)
+	}
+	e.slo = slo.NewTracker(opts)
+}
+
+// setupNotifications builds the jobs announcing through the alert
+// channels: reports, treasury valuations, highs and lows, listings and
+// supply changes. setupAlerts must have run.
+func (e *Engine) setupNotifications(cfg *Config, transport http.RoundTripper) error {
+	channels := e.channels
+
+	// Reports are delivered through the same channels as alerts
+	reports := report.Options{
+		Period:   cfg.Reports.Period,
+		Hour:     cfg.Reports.Hour,
+		Currency: cfg.Reports.Currency,
+		Movers:   cfg.Reports.Movers,
+		Markets:  e.markets.Top,
+		Global:   e.global.Overview,
+		Trending: e.trending.Trending,
+	}
+	if e.pegs != nil {
+		reports.Stablecoins = func() []stablecoins.Status { return e.pegs.Snapshot(false) }
+	}
+	if len(cfg.Reports.Channels) > 0 {
+		targets, err := channelTargets(channels, cfg.Reports.Channels)
+		if err != nil {
+			return fmt.Errorf("reports: %w", err)
+		}
+		reports.Deliver = func(r *report.Report) {
+			text := report.Markdown(r)
+			for _, c := range targets {
+				channels.Send(c, "Report "+r.Period, text, r)
+			}
+		}
+	}
+	e.reports = report.NewGenerator(reports)
+
+	// Treasury valuations are delivered through the alert channels too
+	var err error
+	if len(cfg.Treasury.Holdings) > 0 {
+		valued := treasury.Options{
+			Holdings: cfg.Treasury.Holdings,
+			Currency: cfg.Treasury.Currency,
+			Period:   cfg.Treasury.Period,
+			Hour:     cfg.Treasury.Hour,
+			File:     cfg.Treasury.File,
+		}
+		if cfg.Treasury.ExportURL != "" {
+			if valued.Store, valued.Prefix, err = snapshot.Open(cfg.Treasury.ExportURL, snapshot.S3Options{
+				Endpoint:        cfg.Snapshot.Endpoint,
+				Region:          cfg.Snapshot.Region,
+				AccessKeyID:     cfg.Snapshot.AccessKeyID,
+				SecretAccessKey: cfg.Snapshot.SecretAccessKey,
+			}, &http.Client{Timeout: 60 * time.Second, Transport: transport}); err != nil {
+				return fmt.Errorf("treasury: %w", err)
+			}
+		}
+		if len(cfg.Treasury.Channels) > 0 {
+			targets, err := channelTargets(channels, cfg.Treasury.Channels)
+			if err != nil {
+				return fmt.Errorf("treasury: %w", err)
+			}
+			valued.Deliver = func(v *treasury.Valuation) {
+				text := v.String()
+				for _, c := range targets {
+					channels.Send(c, "Treasury valuation", text, v)
+				}
+			}
+		}
+		if e.treasury, err = treasury.NewService(valued, e.cache.GetMultiplePrices); err != nil {
+			return fmt.Errorf("treasury: %w", err)
+		}
+	}
+
+	// Highs and lows are tracked from accepted prices and announced
+	// through the same channels as alerts
+	tracked := extremes.Options{
+		File:       cfg.Extremes.File,
+		Tokens:     cfg.Extremes.Tokens,
+		Currencies: cfg.Extremes.Currencies,
+	}
+	if len(cfg.Extremes.Channels) > 0 {
+		targets, err := channelTargets(channels, cfg.Extremes.Channels)
+		if err != nil {
+			return fmt.Errorf("extremes: %w", err)
+		}
+		tracked.Notify = func(ev extremes.Event) {
+			text := ev.String()
+			for _, c := range targets {
+				channels.Send(c, "New "+ev.Kind, text, ev)
+			}
+		}
+	}
+	if e.extremes, err = extremes.NewTracker(tracked, e.history.History); err != nil {
+		return fmt.Errorf("extremes: %w", err)
+	}
+	e.cache.OnRefresh(e.extremes.Record)
+
+	// Listings and delistings are announced through the alert channels
+	if cfg.Listings.Interval.Duration > 0 {
+		tracked := listings.Options{File: cfg.Listings.File, Retention: cfg.Listings.Retention.Duration}
+		if len(cfg.Listings.Channels) > 0 {
+			targets, err := channelTargets(channels, cfg.Listings.Channels)
+			if err != nil {
+				return fmt.Errorf("listings: %w", err)
+			}
+			tracked.Notify = func(c listings.Change) {
+				text := c.String()
+				for _, t := range targets {
+					channels.Send(t, "Coin "+c.Event, text, c)
+				}
+			}
+		}
+		if e.listings, err = listings.NewTracker(tracked, e.coingecko.FetchCoinList); err != nil {
+			return fmt.Errorf("listings: %w", err)
+		}
+	}
+
+	// Supply jumps, such as unlocks and mints, are announced through the
+	// alert channels too
+	if c := cfg.Supply.Changes; c.Interval.Duration > 0 {
+		tracked := supply.ChangeOptions{File: c.File, Threshold: c.Threshold, Retention: c.Retention.Duration}
+		if len(c.Channels) > 0 {
+			targets, err := channelTargets(channels, c.Channels)
+			if err != nil {
+				return fmt.Errorf("supply changes: %w", err)
+			}
+			tracked.Notify = func(ch supply.Change) {
+				text := ch.String()
+				for _, t := range targets {
+					channels.Send(t, "Supply change", text, ch)
+				}
+			}
+		}
+		if e.issuance, err = supply.NewChangeTracker(tracked, circulatingSupply(e.markets, e.coingecko, c.Tokens)); err != nil {
+			return fmt.Errorf("supply changes: %w", err)
+		}
+	}
+	return nil
+}
+
+// setupSnapshots exports price snapshots to the configured store, if any
+func (e *Engine) setupSnapshots(cfg *Config, transport http.RoundTripper) error {
+	if cfg.Snapshot.URL == "" {
+		return nil
+	}
+	store, prefix, err := snapshot.Open(cfg.Snapshot.URL, snapshot.S3Options{
+		Endpoint:        cfg.Snapshot.Endpoint,
+		Region:          cfg.Snapshot.Region,
+		AccessKeyID:     cfg.Snapshot.AccessKeyID,
+		SecretAccessKey: cfg.Snapshot.SecretAccessKey,
+	}, &http.Client{Timeout: 60 * time.Second, Transport: transport})
+	if err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	e.snapshots = snapshot.NewExporter(store, prefix, e.snapshotDataset, cfg.Snapshot.Retention.Duration)
+	return nil
+}
+
+// setupSigning builds the Ed25519 signer of price responses, if a key is
+// set, and the EIP-712 quoter
+func (e *Engine) setupSigning(cfg *Config, transport http.RoundTripper) error {
+	if cfg.SigningKey != "" {
+		var err error
+		if e.signer, err = signing.NewSigner(cfg.SigningKey); err != nil {
+			return fmt.Errorf("signing key: %w", err)
+		}
+	}
+
+	// EIP-712 quotes are signed by an external signer holding the key
+	e.quotes = signing.NewQuoter(signing.QuoterOptions{
+		Domain: signing.EIP712Domain{
+			Name:              cfg.Quotes.DomainName,
+			Version:           cfg.Quotes.DomainVersion,
+			ChainID:           int64(cfg.Quotes.ChainID),
+			VerifyingContract: cfg.Quotes.VerifyingContract,
+		},
+		TTL:        cfg.Quotes.TTL.Duration,
+		Decimals:   uint8(cfg.Quotes.Decimals),
+		Signer:     cfg.Quotes.Signer,
+		SignerURL:  cfg.Quotes.SignerURL,
+		SignMethod: cfg.Quotes.SignMethod,
+		Transport:  transport,
+	})
+	return nil
+}
+
+// serverOptions completes the reloadable options apply returned with the
+// engine's services and the settings fixed at startup
+func (e *Engine) serverOptions(cfg *Config, opts api.Options, rounding format.Policy) api.Options {
+	opts.Cache = e.cache
+	opts.AuditLog = e.auditLog
+	opts.Signer = e.signer
+	opts.Quotes = e.quotes
+	opts.Quorum = e.quorum
+	opts.FX = e.fx
+	opts.Stablecoins = e.pegs
+	opts.Deviation = e.deviation
+	opts.Oracle = e.oracle
+	opts.Bridge = e.bridge
+	opts.Onramps = e.onramps
+	opts.Gas = e.gas
+	opts.TokenPrices = e.tokens
+	opts.Derivatives = e.derivs
+	opts.NFTs = e.nfts
+	opts.TVL = e.tvl
+	opts.Logos = e.logos
+	opts.Indices = e.indices
+	opts.Global = e.global
+	opts.Trending = e.trending
+	opts.Markets = e.markets
+	opts.Categories = e.categories
+	opts.Supply = e.supply
+	opts.SupplyChanges = e.issuance
+	opts.Risk = e.risk
+	opts.History = e.history
+	opts.Portfolio = e.portfolio
+	opts.Analytics = e.analytics
+	opts.Ticks = e.ticks
+	opts.Changes = e.changes
+	opts.Stream = e.stream
+	opts.Alerts = e.alerts
+	opts.Channels = e.channels
+	opts.Breakers = e.breakers
+	opts.Balancer = e.balancer
+	if e.balancer != nil {
+		opts.HedgeRoutes = cfg.Upstream.HedgeRoutes
+	}
+	opts.RateLimits = e.rateLimits
+	opts.InFlight = e.inFlight
+	opts.Scheduler = e.scheduler
+	opts.Schemas = e.schemas
+	opts.SLO = e.slo
+	opts.Shed = cfg.SLO.Shed
+	opts.Rounding = rounding
+	opts.RequestTimeout = cfg.Upstream.RequestTimeout.Duration
+	opts.RouteTimeouts = make(map[string]time.Duration, len(cfg.Upstream.RouteTimeouts))
+	for route, d := range cfg.Upstream.RouteTimeouts {
+		opts.RouteTimeouts[route] = d.Duration
+	}
+	opts.Reports = e.reports
+	opts.Treasury = e.treasury
+	opts.Extremes = e.extremes
+	opts.Listings = e.listings
+	opts.Aliases = e.aliases
+	opts.Overrides = e.overrides
+	opts.Tenants = e.tenants
+	opts.Reload = e.reloadFromSource
+	opts.Features = cfg.Features.On
+	return opts
+}
+
+// apply pushes the reloadable settings in cfg to the cache, tenants and
+// alerts and returns the matching server options
+func (e *Engine) apply(cfg *Config) (api.Options, error) {
+	policies, err := cachePolicies(cfg.Cache)
+	if err != nil {
+		return api.Options{}, fmt.Errorf("cache policy: %w", err)
+	}
+
+	mute, err := alerts.ParseSchedule(cfg.Alerts.Mute)
+	if err != nil {
+		return api.Options{}, fmt.Errorf("alerts: %w", err)
+	}
+
+	e.cache.SetTTL(cfg.Cache.TTL.Duration)
+	e.cache.SetMaxChange(cfg.Cache.MaxChange)
+	e.cache.SetNotFoundTTL(cfg.Cache.NotFoundTTL.Duration)
+	e.cache.SetStaleAfter(cfg.Cache.StaleAfter.Duration)
+	maxAges := make(map[string]time.Duration, len(cfg.Cache.MaxAges))
+	for token, d := range cfg.Cache.MaxAges {
+		maxAges[token] = d.Duration
+	}
+	e.cache.SetMaxAges(maxAges)
+	e.history.SetNotFoundTTL(cfg.Cache.NotFoundTTL.Duration)
+	e.alerts.SetPolicy(alerts.Policy{Cooldown: cfg.Alerts.Cooldown.Duration, Mute: mute})
+	e.tenants.SetDefaultRateLimit(api.RateLimit{
+		RequestsPerMinute: cfg.RateLimit.RequestsPerMinute,
+		Burst:             cfg.RateLimit.Burst,
+	})
+
+	// Admin keys are configured as actor -> key; the server looks up by key
+	adminKeys := make(map[string]string, len(cfg.Admin.APIKeys))
+	for actor, key := range cfg.Admin.APIKeys {
+		adminKeys[key] = actor
+	}
+
+	var accessLog *log.Logger
+	if cfg.AccessLog {
+		accessLog = log.New(os.Stdout, "access ", log.LstdFlags|log.LUTC)
+	}
+
+	legacySunset, _ := cfg.LegacySunsetTime()
+
+	return api.Options{
+		AdminKeys:     adminKeys,
+		CachePolicies: policies,
+		CORSOrigins:   cfg.CORS.AllowedOrigins,
+		LegacySunset:  legacySunset,
+		AccessLog:     accessLog,
+	}, nil
+}
+
+// Reload applies cfg to the running engine. Cache TTL, the price sanity
+// bound, Cache-Control policies, CORS origins, the default rate limit,
+// admin keys, the alert cooldown and mute schedule, access logging and the
+// legacy sunset date change in place; changes to anything else are
+// rejected with a *config.RestartError and nothing is applied.
+func (e *Engine) Reload(cfg *Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if fields := config.RestartRequired(e.cfg, cfg); len(fields) > 0 {
+		return &config.RestartError{Fields: fields}
+	}
+	opts, err := e.apply(cfg)
+	if err != nil {
+		return err
+	}
+	e.server.Reconfigure(opts)
+	e.cfg = cfg
+	return nil
+}
+
+// SetConfigSource sets how configuration is re-read by POST /admin/reload
+// and WatchConfig, typically a config.Loader's Load method
+func (e *Engine) SetConfigSource(load func() (*Config, error)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.source = load
+}
+
+// reloadFromSource re-reads configuration from the source and applies it
+func (e *Engine) reloadFromSource() error {
+	e.mu.Lock()
+	load := e.source
+	e.mu.Unlock()
+
+	if load == nil {
+		return errors.New("no configuration source")
+	}
+	cfg, err := load()
+	if err != nil {
+		return err
+	}
+	return e.Reload(cfg)
+}
+
+// WatchConfig polls path every interval and reloads when it changes, until
+// ctx is done. Failed reloads are logged and the running config is kept.
+func (e *Engine) WatchConfig(ctx context.Context, path string, interval time.Duration) {
+	modTime := func() time.Time {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}
+		}
+		return info.ModTime()
+	}
+
+	last := modTime()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := modTime()
+		if current.Equal(last) || current.IsZero() {
+			continue
+		}
+		last = current
+
+		if err := e.reloadFromSource(); err != nil {
+			log.Printf("Config reload from %s rejected: %v", path, err)
+			continue
+		}
+		log.Printf("Config reloaded from %s", path)
+	}
+}
+
+// Start runs the engine's background jobs until ctx is done. Without it
+// the engine still serves prices, but stablecoin pegs and provider
+// deviation are not monitored, oracle feeds are not pushed, snapshots are
+// not exported, reports are not delivered, the treasury is not valued,
+// tracked highs and lows are not seeded, coin listings, token categories
+// and circulating supply are not fetched, ticks are not downsampled and
+// alerts only fire on prices clients request. Close also stops the jobs.
+func (e *Engine) Start(ctx context.Context) {
+	cfg := e.Config()
+	ctx, cancel := context.WithCancel(ctx)
+	e.mu.Lock()
+	e.stop = append(e.stop, cancel)
+	e.mu.Unlock()
+	// Background jobs give way to API requests for upstream quota
+	ctx = providers.WithPriority(ctx, providers.PriorityBackground)
+	if e.pegs != nil {
+		e.run(func() { e.pegs.Run(ctx, cfg.Stablecoins.Interval.Duration) })
+	}
+	if e.deviation != nil {
+		e.run(func() { e.deviation.Run(ctx, cfg.Deviation.Interval.Duration) })
+	}
+	if e.oracle != nil {
+		e.run(func() { e.oracle.Run(ctx, cfg.Oracle.Interval.Duration) })
+	}
+	if e.snapshots != nil {
+		e.run(func() { e.snapshots.Run(ctx, cfg.Snapshot.Interval.Duration) })
+	}
+	if len(cfg.Reports.Channels) > 0 {
+		e.run(func() { e.reports.Run(ctx) })
+	}
+	if e.treasury != nil {
+		e.run(func() { e.treasury.Run(ctx) })
+	}
+	if len(cfg.Ticks.Tokens) > 0 {
+		interval := cfg.Ticks.Interval.Duration
+		refresh := func(ctx context.Context, tokenIDs []string, currency string) error {
+			_, err := e.cache.GetMultiplePrices(cache.WithTTL(ctx, interval), tokenIDs, currency)
+			return err
+		}
+		e.run(func() { e.ticks.Run(ctx, interval, refresh) })
+	}
+	if cfg.Ticks.HourlyRetention.Duration > 0 {
+		e.run(func() { e.ticks.RunCompaction(ctx, cfg.Ticks.CompactInterval.Duration) })
+	}
+	if len(cfg.Extremes.Tokens) > 0 {
+		interval := cfg.Extremes.Interval.Duration
+		refresh := func(ctx context.Context, tokenIDs []string, currency string) error {
+			_, err := e.cache.GetMultiplePrices(cache.WithTTL(ctx, interval), tokenIDs, currency)
+			return err
+		}
+		e.run(func() { e.extremes.Run(ctx, interval, refresh) })
+	}
+	if e.listings != nil {
+		e.run(func() { e.listings.Run(ctx, cfg.Listings.Interval.Duration) })
+	}
+	if e.categories != nil && len(cfg.Categories.Sources) > 0 && cfg.Categories.Interval.Duration > 0 {
+		e.run(func() { e.categories.Run(ctx, cfg.Categories.Interval.Duration) })
+	}
+	if e.issuance != nil {
+		e.run(func() { e.issuance.Run(ctx, cfg.Supply.Changes.Interval.Duration) })
+	}
+	if e.supply != nil {
+		e.run(func() { e.supply.Run(ctx, cfg.Supply.Interval.Duration) })
+	}
+	if e.risk != nil {
+		e.run(func() { e.risk.Run(ctx, cfg.Risk.Interval.Duration) })
+	}
+	if e.relay != nil {
+		e.run(func() { e.relay.Run(ctx) })
+	}
+	if e.stream != nil && cfg.Stream.Interval.Duration > 0 {
+		interval := cfg.Stream.Interval.Duration
+		refresh := func(ctx context.Context, tokenIDs []string, currency string) error {
+			_, err := e.cache.GetMultiplePrices(cache.WithTTL(ctx, interval), tokenIDs, currency)
+			return err
+		}
+		e.run(func() { e.stream.Run(ctx, interval, refresh) })
+	}
+	if cfg.Alerts.Interval.Duration > 0 && cfg.Features.On("alerts") {
+		refresh := func(ctx context.Context, tokenIDs []string, currency string) error {
+			_, err := e.cache.GetMultiplePrices(ctx, tokenIDs, currency)
+			return err
+		}
+		e.run(func() { e.alerts.Run(ctx, cfg.Alerts.Interval.Duration, refresh) })
+	}
+}
+
+// run runs a background job, which Close waits for
+func (e *Engine) run(job func()) {
+	e.jobs.Add(1)
+	go func() {
+		defer e.jobs.Done()
+		job()
+	}()
+}
+
+// NewHandler builds an engine from cfg and returns its HTTP handler. Use
+// NewEngine instead to also query prices in-process or close the engine.
+func NewHandler(cfg *Config) (http.Handler, error) {
+	e, err := NewEngine(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return e.Handler(), nil
+}
+
+// Handler returns the HTTP handler serving the pricing API. Mount it with
+// http.StripPrefix to serve it below a path.
+func (e *Engine) Handler() http.Handler {
+	e.handlerOnce.Do(func() {
+		e.handler = e.server.Handler()
+	})
+	return e.handler
+}
+
+// GetPrice returns the price of a token, served from the shared cache
+func (e *Engine) GetPrice(ctx context.Context, tokenID, currency string) (*PriceResponse, error) {
+	return e.cache.GetPrice(ctx, strings.ToLower(tokenID), strings.ToLower(currency))
+}
+
+// GetPrices returns prices for several tokens. On upstream failure the
+// prices that could be served are returned along with the error.
+func (e *Engine) GetPrices(ctx context.Context, tokenIDs []string, currency string) (*MultiPriceResponse, error) {
+	ids := make([]string, len(tokenIDs))
+	for i, id := range tokenIDs {
+		ids[i] = strings.ToLower(id)
+	}
+	return e.cache.GetMultiplePrices(ctx, ids, strings.ToLower(currency))
+}
+
+// Config returns the engine's current configuration
+func (e *Engine) Config() *Config {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.cfg
+}
+
+// Cache returns the shared price cache
+func (e *Engine) Cache() *cache.PriceCache {
+	return e.cache
+}
+
+// Provider returns the upstream price provider, including plugins and
+// FX-derived currencies
+func (e *Engine) Provider() providers.Provider {
+	return e.provider
+}
+
+// CoinGecko returns the CoinGecko provider
+func (e *Engine) CoinGecko() *providers.CoinGecko {
+	return e.coingecko
+}
+
+// FX returns the exchange rate converter, or nil if FX is disabled
+func (e *Engine) FX() *fx.Converter {
+	return e.fx
+}
+
+// Stablecoins returns the stablecoin peg monitor, or nil if monitoring is
+// disabled
+func (e *Engine) Stablecoins() *stablecoins.Monitor {
+	return e.pegs
+}
+
+// Breakers returns the circuit breaker of each price provider
+func (e *Engine) Breakers() []*providers.Breaker {
+	return e.breakers
+}
+
+// Balancer returns the latency balancer between CoinGecko and alternate
+// plugins, or nil if none are configured
+func (e *Engine) Balancer() *providers.Balancer {
+	return e.balancer
+}
+
+// RateLimits returns the transport that pauses rate-limited providers
+func (e *Engine) RateLimits() *providers.RateLimitTransport {
+	return e.rateLimits
+}
+
+// InFlight returns the transport capping concurrent upstream requests, or
+// nil if the cap is disabled
+func (e *Engine) InFlight() *providers.LimitTransport {
+	return e.inFlight
+}
+
+// Scheduler returns the transport pacing upstream requests to each host's
+// quota, or nil if no quotas are configured
+func (e *Engine) Scheduler() *providers.SchedulerTransport {
+	return e.scheduler
+}
+
+// Schemas returns the monitor counting upstream responses that do not
+// match their expected schema
+func (e *Engine) Schemas() *providers.SchemaMonitor {
+	return e.schemas
+}
+
+// Deviation returns the cross-provider deviation monitor, or nil if
+// fewer than two providers are configured or comparisons are disabled
+func (e *Engine) Deviation() *deviation.Monitor {
+	return e.deviation
+}
+
+// Oracle returns the on-chain oracle pusher, or nil if no contract is
+// configured
+func (e *Engine) Oracle() *oracle.Pusher {
+	return e.oracle
+}
+
+// Bridge returns the bridge rate service, or nil if no pairs are
+// configured
+func (e *Engine) Bridge() *bridge.Service {
+	return e.bridge
+}
+
+// Onramps returns the on-ramp quote aggregator, or nil if no on-ramps are
+// configured
+func (e *Engine) Onramps() *onramp.Aggregator {
+	return e.onramps
+}
+
+// Gas returns the gas fee oracle, or nil if no chains are configured
+func (e *Engine) Gas() *gas.Oracle {
+	return e.gas
+}
+
+// TokenPrices returns the prices of tokens by contract address
+func (e *Engine) TokenPrices() *tokenprice.Service {
+	return e.tokens
+}
+
+// Derivatives returns the perpetual funding and open interest aggregator
+func (e *Engine) Derivatives() *derivatives.Aggregator {
+	return e.derivs
+}
+
+// NFTs returns the NFT collection floor price service
+func (e *Engine) NFTs() *nft.Service {
+	return e.nfts
+}
+
+// TVL returns the DefiLlama protocol TVL service
+func (e *Engine) TVL() *tvl.Service {
+	return e.tvl
+}
+
+// Logos returns the token logo proxy
+func (e *Engine) Logos() *logos.Service {
+	return e.logos
+}
+
+// Indices returns the configured token basket indices
+func (e *Engine) Indices() *index.Service {
+	return e.indices
+}
+
+// Global returns the market overview service
+func (e *Engine) Global() *global.Service {
+	return e.global
+}
+
+// Trending returns the trending tokens service
+func (e *Engine) Trending() *trending.Service {
+	return e.trending
+}
+
+// Markets returns the market list service
+func (e *Engine) Markets() *markets.Service {
+	return e.markets
+}
+
+// History returns the price history service
+func (e *Engine) History() *history.Service {
+	return e.history
+}
+
+// Ticks returns the store of recorded prices
+func (e *Engine) Ticks() *ticks.Store {
+	return e.ticks
+}
+
+// Changes returns the journal of changed prices
+func (e *Engine) Changes() *changes.Journal {
+	return e.changes
+}
+
+// Stream returns the hub of price streams, or nil if streaming is disabled
+func (e *Engine) Stream() *stream.Hub {
+	return e.stream
+}
+
+// SLO returns the tracker of service level objectives per route, or nil
+// if tracking is disabled
+func (e *Engine) SLO() *slo.Tracker {
+	return e.slo
+}
+
+// Portfolio returns the portfolio service
+func (e *Engine) Portfolio() *portfolio.Service {
+	return e.portfolio
+}
+
+// Analytics returns the price history analytics service
+func (e *Engine) Analytics() *analytics.Service {
+	return e.analytics
+}
+
+// Reports returns the market report generator
+func (e *Engine) Reports() *report.Generator {
+	return e.reports
+}
+
+// Treasury returns the treasury valuations, or nil if no holdings are
+// configured
+func (e *Engine) Treasury() *treasury.Service {
+	return e.treasury
+}
+
+// Extremes returns the tracker of token highs and lows
+func (e *Engine) Extremes() *extremes.Tracker {
+	return e.extremes
+}
+
+// Listings returns the tracker of coin listings, or nil if tracking is
+// disabled
+func (e *Engine) Listings() *listings.Tracker {
+	return e.listings
+}
+
+// Categories returns the token categories, or nil if none are configured
+func (e *Engine) Categories() *categories.Service {
+	return e.categories
+}
+
+// Supply returns the token supply verifier, or nil if no tokens are
+// configured
+func (e *Engine) Supply() *supply.Verifier {
+	return e.supply
+}
+
+// SupplyChanges returns the tracker of circulating supply changes, or nil
+// if tracking is disabled
+func (e *Engine) SupplyChanges() *supply.ChangeTracker {
+	return e.issuance
+}
+
+// Risk returns the token risk checker, or nil if risk flags are disabled
+func (e *Engine) Risk() *risk.Checker {
+	return e.risk
+}
+
+// Aliases returns the token id alias table
+func (e *Engine) Aliases() *aliases.Table {
+	return e.aliases
+}
+
+// Overrides returns the token metadata override table
+func (e *Engine) Overrides() *overrides.Table {
+	return e.overrides
+}
+
+// Alerts returns the price alert store
+func (e *Engine) Alerts() *alerts.Store {
+	return e.alerts
+}
+
+// Snapshots returns the snapshot exporter, or nil if exports are disabled
+func (e *Engine) Snapshots() *snapshot.Exporter {
+	return e.snapshots
+}
+
+// snapshotDataset gathers the largest markets, the cached prices and the
+// stablecoin pegs for a snapshot
+func (e *Engine) snapshotDataset(ctx context.Context) (*snapshot.Dataset, error) {
+	c := e.Config().Snapshot
+	data := &snapshot.Dataset{
+		GeneratedAt: time.Now().UTC(),
+		Currency:    c.Currency,
+		Prices:      e.cache.Cached(c.Currency),
+	}
+	sort.Slice(data.Prices, func(i, j int) bool { return data.Prices[i].ID < data.Prices[j].ID })
+	if c.Markets > 0 {
+		markets, err := e.coingecko.FetchTopMarkets(ctx, c.Currency, c.Markets)
+		if err != nil {
+			return nil, fmt.Errorf("markets: %w", err)
+		}
+		data.Markets = markets
+	}
+	if e.pegs != nil {
+		data.Stablecoins = e.pegs.Snapshot(false)
+	}
+	return data, nil
+}
+
+// tokenSymbol looks up a token's ticker symbol on CoinGecko
+func (e *Engine) tokenSymbol(ctx context.Context, tokenID string) (string, error) {
+	price, err := e.coingecko.FetchPrice(ctx, tokenID, "usd")
+	if err != nil {
+		return "", err
+	}
+	return price.Symbol, nil
+}
+
+// Signer returns the response signer, or nil if signing is disabled
+func (e *Engine) Signer() *signing.Signer {
+	return e.signer
+}
+
+// Quotes returns the EIP-712 quote builder and signer
+func (e *Engine) Quotes() *signing.Quoter {
+	return e.quotes
+}
+
+// Quorum returns the checker quote and oracle prices are agreed by, or nil
+// if no quorum is required
+func (e *Engine) Quorum() *quorum.Checker {
+	return e.quorum
+}
+
+// Close stops the background jobs and waits for them to return, closes
+// the upstream connections, including a secondary's to its primary, and
+// closes the audit log
+func (e *Engine) Close() error {
+	e.mu.Lock()
+	stop := e.stop
+	e.stop = nil
+	e.mu.Unlock()
+	for _, cancel := range stop {
+		cancel()
+	}
+	e.jobs.Wait()
+
+	if e.upstream != nil {
+		e.upstream.Close()
+	}
+	e.pool.CloseIdleConnections()
+	return e.auditLog.Close()
+}
+
+// channelTargets converts configured channels, checking each can be
+// delivered to
+func channelTargets(channels *alerts.Channels, cfgs []config.ChannelConfig) ([]alerts.Channel, error) {
+	targets := make([]alerts.Channel, len(cfgs))
+	for i, c := range cfgs {
+		targets[i] = alerts.Channel{Type: c.Type, URL: c.URL, ChatID: c.ChatID, Email: c.Email}
+		if err := channels.Validate(targets[i]); err != nil {
+			return nil, err
+		}
+	}
+	return targets, nil
+}
+
+// transportConfig applies upstream settings to the transport defaults
+func transportConfig(u config.UpstreamConfig) providers.TransportConfig {
+	cfg := providers.DefaultTransportConfig()
+	cfg.MaxIdleConns = u.MaxIdleConns
+	cfg.MaxIdleConnsPerHost = u.MaxIdleConnsPerHost
+	cfg.MaxConnsPerHost = u.MaxConnsPerHost
+	cfg.IdleConnTimeout = u.IdleConnTimeout.Duration
+	cfg.HTTP2 = u.HTTP2
+	return cfg
+}
+
+// newSource builds a configured price source. Its quote asset is priced
+// by pricer.
+func newSource(c config.SourceConfig, pricer providers.Provider, transport http.RoundTripper) *providers.Source {
+	if c.Type == "dex_pool" {
+		pools := make(map[string]providers.Pool, len(c.Pools))
+		for id, p := range c.Pools {
+			pools[id] = providers.Pool(p)
+		}
+		return providers.NewDEXSource(c.Name, c.Quote, pricer, c.RPCURL, pools, c.Timeout.Duration, transport)
+	}
+	return providers.NewExchangeSource(c.Name, c.Quote, pricer, c.URL, c.Field, c.Symbols, c.Timeout.Duration, transport)
+}
+
+// oraclePusher builds the on-chain oracle pusher, reading prices from the
+// cache no older than the check interval, or from q if it is set
+func oraclePusher(c config.OracleConfig, pc *cache.PriceCache, q *quorum.Checker, transport http.RoundTripper) *oracle.Pusher {
+	opts := oracle.Options{
+		RPCURL:       c.RPCURL,
+		Contract:     c.Contract,
+		From:         c.From,
+		Method:       c.Method,
+		Decimals:     c.Decimals,
+		Deviation:    c.Deviation,
+		Heartbeat:    c.Heartbeat.Duration,
+		GasLimit:     uint64(c.GasLimit),
+		ReplaceAfter: c.ReplaceAfter.Duration,
+		Transport:    transport,
+	}
+	for _, f := range c.Feeds {
+		token, currency, _ := strings.Cut(f, "/")
+		opts.Feeds = append(opts.Feeds, oracle.Feed{Token: token, Currency: currency})
+	}
+	if c.MaxGasPriceGwei > 0 {
+		opts.MaxGasPrice = decimal.FromFloat(c.MaxGasPriceGwei).Mul(decimal.FromFloat(1e9)).Int()
+	}
+
+	interval := c.Interval.Duration
+	return oracle.NewPusher(opts, func(ctx context.Context, token, currency string) (decimal.Decimal, time.Time, error) {
+		if q != nil {
+			res, err := q.Check(ctx, token, currency)
+			if err != nil {
+				return decimal.Decimal{}, time.Time{}, err
+			}
+			return res.Price, res.CheckedAt, nil
+		}
+		p, err := pc.GetPrice(cache.WithTTL(ctx, interval), token, currency)
+		if err != nil {
+			return decimal.Decimal{}, time.Time{}, err
+		}
+		price, err := decimal.Parse(p.PriceStr)
+		if err != nil {
+			price = decimal.FromFloat(p.Price)
+		}
+		return price, p.UpdatedAt, nil
+	})
+}
+
+// contractPricer prices contracts on CoinGecko's platforms, falling back
+// to DEX pools read over each chain's RPC, or its gas RPC from rpcs
+func contractPricer(c config.TokenPriceConfig, rpcs map[string]string, cg *providers.CoinGecko, pc *cache.PriceCache, transport http.RoundTripper) *tokenprice.Service {
+	opts := tokenprice.Options{
+		Platforms:    make(map[string]string, len(c.Platforms)),
+		DEXes:        make(map[string]tokenprice.DEX, len(c.DEXes)),
+		MinLiquidity: c.MinLiquidity,
+		TTL:          c.TTL.Duration,
+	}
+	for chain, platform := range c.Platforms {
+		if platform != "" {
+			opts.Platforms[chain] = platform
+		}
+	}
+	for chain, d := range c.DEXes {
+		rpc := d.RPCURL
+		if rpc == "" {
+			rpc = rpcs[chain]
+		}
+		dex := tokenprice.DEX{RPC: evm.NewClient(rpc, 10*time.Second, transport), Factory: d.Factory}
+		for _, q := range d.Quotes {
+			dex.Quotes = append(dex.Quotes, tokenprice.Quote{Address: q.Address, TokenID: q.Token, Decimals: q.Decimals})
+		}
+		opts.DEXes[chain] = dex
+	}
+	return tokenprice.NewService(opts, cg.FetchTokenPrice, func(ctx context.Context, tokenID, currency string) (float64, error) {
+		p, err := pc.GetPrice(ctx, tokenID, currency)
+		if err != nil {
+			return 0, err
+		}
+		return p.Price, nil
+	})
+}
+
+// circulatingSupply reads the circulating supply of the largest tokens by
+// market cap from the markets list, and of tokens beyond it from CoinGecko
+func circulatingSupply(m *markets.Service, cg *providers.CoinGecko, tokens []string) supply.CirculatingFetcher {
+	return func(ctx context.Context) (map[string]float64, error) {
+		supplies := make(map[string]float64)
+		top, err := m.Top(ctx, "usd", markets.MaxLimit)
+		if top != nil {
+			for _, a := range top.Assets {
+				supplies[a.ID] = a.CirculatingSupply
+			}
+		}
+		var missing []string
+		for _, id := range tokens {
+			if _, ok := supplies[id]; !ok {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) == 0 {
+			return supplies, err
+		}
+		prices, fetchErr := cg.FetchPrices(ctx, missing, "usd")
+		for _, p := range prices {
+			supplies[p.ID] = p.CirculatingSupply
+		}
+		return supplies, errors.Join(err, fetchErr)
+	}
+}
+
+// supplyVerifier checks CoinGecko's supply of tokens against their
+// contracts, read over each token's RPC or its chain's gas RPC from rpcs
+func supplyVerifier(c config.SupplyConfig, rpcs map[string]string, cg *providers.CoinGecko, transport http.RoundTripper) *supply.Verifier {
+	opts := supply.Options{Tokens: make(map[string]supply.Token, len(c.Tokens)), Threshold: c.Threshold}
+	for id, t := range c.Tokens {
+		rpc := t.RPCURL
+		if rpc == "" {
+			rpc = rpcs[t.Chain]
+		}
+		opts.Tokens[id] = supply.Token{
+			Chain:    t.Chain,
+			RPC:      evm.NewClient(rpc, 10*time.Second, transport),
+			Contract: t.Contract,
+			Decimals: t.Decimals,
+			Locked:   t.Locked,
+		}
+	}
+	return supply.NewVerifier(opts, cg.FetchPrices)
+}
+
+// riskChecker scans the largest tokens by market cap and the configured
+// contracts for risks
+func riskChecker(c config.RiskConfig, m *markets.Service, a *analytics.Service, pegs *stablecoins.Monitor, transport http.RoundTripper) (*risk.Checker, error) {
+	opts := risk.Options{
+		Contracts:   make(map[string]risk.Contract, len(c.Contracts)),
+		SecurityURL: c.SecurityURL,
+		Volatility:  c.Volatility,
+		MinVolume:   c.MinVolume,
+		MaxTax:      c.MaxTax,
+		Pegs:        pegs,
+	}
+	for id, t := range c.Contracts {
+		contract := risk.Contract{Chain: t.Chain, Address: t.Contract}
+		if _, ok := contract.ChainID(); !ok {
+			return nil, fmt.Errorf("contracts.%s: unknown chain %q", id, t.Chain)
+		}
+		opts.Contracts[id] = contract
+	}
+
+	list := func(ctx context.Context) ([]risk.Listing, error) {
+		if c.Tokens == 0 {
+			return nil, nil
+		}
+		top, err := m.Top(ctx, "usd", c.Tokens)
+		if err != nil {
+			return nil, err
+		}
+		listings := make([]risk.Listing, len(top.Assets))
+		for i, asset := range top.Assets {
+			listings[i] = risk.Listing{ID: asset.ID, Volume24h: asset.Volume24h}
+		}
+		return listings, nil
+	}
+	volatility := analytics.Metric{Name: analytics.Volatility, Days: 30}
+	measure := func(ctx context.Context, tokenID string) (float64, bool, error) {
+		metrics, err := a.Metrics(ctx, tokenID, "usd", []analytics.Metric{volatility})
+		if err != nil {
+			return 0, false, err
+		}
+		v := metrics.Metrics[volatility.String()]
+		if v == nil {
+			return 0, false, nil
+		}
+		return *v, true, nil
+	}
+	return risk.NewChecker(opts, list, measure, &http.Client{Timeout: 30 * time.Second, Transport: transport}), nil
+}
+
+// onrampAggregator builds the configured on-ramp providers, waiting for
+// the slowest one's timeout
+func onrampAggregator(configs []config.OnrampConfig, pc *cache.PriceCache, transport http.RoundTripper) *onramp.Aggregator {
+	var (
+		ramps   []onramp.Provider
+		timeout time.Duration
+	)
+	for _, c := range configs {
+		t := c.Timeout.Duration
+		if t <= 0 {
+			t = 10 * time.Second
+		}
+		timeout = max(timeout, t)
+		opts := onramp.Options{
+			Name:          c.Name,
+			BaseURL:       c.URL,
+			APIKey:        c.APIKey,
+			Currencies:    c.Currencies,
+			Network:       c.Network,
+			PaymentMethod: c.PaymentMethod,
+			Client:        &http.Client{Timeout: t, Transport: transport},
+		}
+		if c.Type == "transak" {
+			ramps = append(ramps, onramp.NewTransak(opts))
+		} else {
+			ramps = append(ramps, onramp.NewMoonPay(opts))
+		}
+	}
+	return onramp.NewAggregator(ramps, func(ctx context.Context, tokenID, currency string) (decimal.Decimal, error) {
+		p, err := pc.GetPrice(ctx, tokenID, currency)
+		if err != nil {
+			return decimal.Decimal{}, err
+		}
+		price, err := decimal.Parse(p.PriceStr)
+		if err != nil {
+			price = decimal.FromFloat(p.Price)
+		}
+		return price, nil
+	}, timeout)
+}
+
+// bridgeRates builds the bridge rate service, reading prices from the
+// cache and refetching those older than half the age a rate allows
+func bridgeRates(c config.BridgeConfig, pc *cache.PriceCache) *bridge.Service {
+	opts := bridge.Options{
+		Currency: c.Currency,
+		MaxAge:   c.MaxAge.Duration,
+		Decimals: c.Decimals,
+	}
+	for _, p := range c.Pairs {
+		base, quote, _ := strings.Cut(p, "/")
+		opts.Pairs = append(opts.Pairs, bridge.Pair{Base: base, Quote: quote})
+	}
+	return bridge.NewService(opts, func(ctx context.Context, token, currency string, maxAge time.Duration) (decimal.Decimal, time.Time, error) {
+		p, err := pc.GetPrice(cache.WithTTL(ctx, maxAge/2), token, currency)
+		if err != nil {
+			return decimal.Decimal{}, time.Time{}, err
+		}
+		price, err := decimal.Parse(p.PriceStr)
+		if err != nil {
+			price = decimal.FromFloat(p.Price)
+		}
+		return price, p.UpdatedAt, nil
+	})
+}
+
+// fxConverter builds the configured exchange rate converter, or nil for
+// source "none"
+func fxConverter(c config.FXConfig, client *http.Client) *fx.Converter {
+	var source fx.Source
+	switch c.Source {
+	case "ecb":
+		ecb := fx.NewECB(client)
+		if c.BaseURL != "" {
+			ecb.URL = c.BaseURL
+		}
+		source = ecb
+	case "exchangerate.host":
+		host := fx.NewExchangeRateHost(c.APIKey, client)
+		if c.BaseURL != "" {
+			host.BaseURL = c.BaseURL
+		}
+		source = host
+	default:
+		return nil
+	}
+	return fx.NewConverter(source, c.TTL.Duration)
+}
+
+// deviationMonitor builds the cross-provider deviation monitor from its
+// configuration, comparing every plugin token if none are listed
+func deviationMonitor(c config.DeviationConfig, plugins []config.PluginConfig) *deviation.Monitor {
+	tokens := c.Tokens
+	if len(tokens) == 0 {
+		for _, p := range plugins {
+			tokens = append(tokens, p.Tokens...)
+		}
+	}
+	opts := deviation.Options{Tokens: tokens, Threshold: c.Threshold}
+	if len(c.Webhooks) > 0 {
+		opts.Notify = deviation.Webhooks(c.Webhooks, nil)
+	}
+	return deviation.NewMonitor(opts)
+}
+
+// pegMonitor builds the stablecoin monitor from its configuration
+func pegMonitor(c config.StablecoinsConfig) *stablecoins.Monitor {
+	var coins []stablecoins.Coin
+	for _, coin := range c.Coins {
+		coins = append(coins, stablecoins.Coin{ID: coin.ID, Symbol: coin.Symbol, Peg: coin.Peg})
+	}
+	opts := stablecoins.Options{
+		Coins:     coins,
+		Threshold: c.Threshold,
+		History:   c.History.Duration,
+	}
+	if len(c.Webhooks) > 0 {
+		opts.Notify = stablecoins.Webhooks(c.Webhooks, nil)
+	}
+	return stablecoins.NewMonitor(opts)
+}
+
+// cachePolicies builds per-endpoint Cache-Control policies. Endpoints
+// without an explicit policy allow caching for the configured TTL.
+func cachePolicies(c config.CacheConfig) (map[string]api.CachePolicy, error) {
+	policies := api.DefaultCachePolicies()
+	for endpoint, p := range policies {
+		p.MaxAge = c.TTL.Duration
+		policies[endpoint] = p
+	}
+	for endpoint, spec := range c.CacheControl {
+		p, err := api.ParseCachePolicy(spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", endpoint, err)
+		}
+		policies[endpoint] = p
+	}
+	return policies, nil
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Lux Partners Limited
+// SPDX-License-Identifier: MIT
+
+package pricing_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/luxfi/pricing"
+	"github.com/luxfi/pricing/internal/testutil"
+	"github.com/luxfi/pricing/pkg/config"
+)
+
+// TestCloseStopsReplicaPoll checks that closing a secondary stops it
+// polling its primary for streamed prices and closes its relay connections
+func TestCloseStopsReplicaPoll(t *testing.T) {
+	primary := testutil.NewServer(t)
+	primary.Provider.Set("bitcoin", 65000)
+	var polls atomic.Int64
+	api := primary.API.Handler()
+	counted := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls.Add(1)
+		api.ServeHTTP(w, r)
+	}))
+	defer counted.Close()
+
+	// Redis accepts the relay's connections and never answers
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	var open atomic.Int64
+	closed := make(chan struct{}, 2)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			open.Add(1)
+			go func() {
+				io.Copy(io.Discard, conn)
+				conn.Close()
+				closed <- struct{}{}
+			}()
+		}
+	}()
+
+	cfg := pricing.DefaultConfig()
+	cfg.CoinGecko.APIKey = "test"
+	cfg.Provider = "mock"
+	cfg.Replica.Role = "secondary"
+	cfg.Replica.PrimaryURL = counted.URL
+	cfg.Stream.RedisURL = "redis://" + ln.Addr().String()
+	cfg.Stream.Interval = config.Duration{Duration: 10 * time.Millisecond}
+	e, err := pricing.NewEngine(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Start(context.Background())
+	sub, err := e.Stream().Subscribe([]string{"bitcoin"}, "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for polls.Load() < 2 || open.Load() < 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("secondary polled %d times on %d relay connections", polls.Load(), open.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	after := polls.Load()
+	time.Sleep(50 * time.Millisecond)
+	if n := polls.Load(); n != after {
+		t.Errorf("secondary polled %d times after Close", n-after)
+	}
+	for i := open.Load(); i > 0; i-- {
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			t.Fatal("relay connection still open after Close")
+		}
+	}
+}